@@ -0,0 +1,186 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocklistcmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+	"github.com/trustbloc/orb/pkg/activitypub/blocklist"
+)
+
+const (
+	urlFlagName  = "url"
+	urlFlagUsage = "Blocklist url, e.g. https://orb.domain1.com/blocklist." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+	urlEnvKey = "ORB_CLI_URL"
+
+	actionFlagName  = "action"
+	actionFlagUsage = "Blocklist action (Get, Add, Remove)." +
+		" Alternatively, this can be set with the following environment variable: " + actionEnvKey
+	actionEnvKey = "ORB_CLI_ACTION"
+
+	entryFlagName  = "entry"
+	entryFlagUsage = "Actor IRI, domain (e.g. orb.domain1.com) or domain wildcard (e.g. *.domain1.com)" +
+		" to add to or remove from the blocklist. This flag can be repeated to specify multiple entries." +
+		" Alternatively, this can be set with the following environment variable (comma-separated): " + entryEnvKey
+	entryEnvKey = "ORB_CLI_ENTRY"
+
+	tlsSystemCertPoolFlagName  = "tls-systemcertpool"
+	tlsSystemCertPoolFlagUsage = "Use system certificate pool." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsSystemCertPoolEnvKey
+	tlsSystemCertPoolEnvKey = "ORB_CLI_TLS_SYSTEMCERTPOOL"
+
+	tlsCACertsFlagName  = "tls-cacerts"
+	tlsCACertsFlagUsage = "Comma-Separated list of ca certs path." +
+		" Alternatively, this can be set with the following environment variable: " + tlsCACertsEnvKey
+	tlsCACertsEnvKey = "ORB_CLI_TLS_CACERTS"
+
+	authTokenFlagName  = "auth-token"
+	authTokenFlagUsage = "Auth token." +
+		" Alternatively, this can be set with the following environment variable: " + authTokenEnvKey
+	authTokenEnvKey = "ORB_CLI_AUTH_TOKEN" //nolint:gosec
+)
+
+const (
+	getAction    = "Get"
+	addAction    = "Add"
+	removeAction = "Remove"
+)
+
+// GetCmd returns the Cobra blocklist command.
+func GetCmd() *cobra.Command {
+	createCmd := createCmd()
+
+	createFlags(createCmd)
+
+	return createCmd
+}
+
+func createCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "blocklist",
+		Short: "manage the blocklist",
+		Long:  "manage the list of ActivityPub actors and domains from which activities are refused",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootCAs, err := getRootCAs(cmd)
+			if err != nil {
+				return err
+			}
+
+			httpClient := &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						RootCAs:    rootCAs,
+						MinVersion: tls.VersionTLS12,
+					},
+				},
+			}
+
+			blocklistURL, err := cmdutils.GetUserSetVarFromString(cmd, urlFlagName, urlEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			action, err := cmdutils.GetUserSetVarFromString(cmd, actionFlagName, actionEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			authToken := cmdutils.GetUserSetOptionalVarFromString(cmd, authTokenFlagName, authTokenEnvKey)
+
+			headers := make(map[string]string)
+			if authToken != "" {
+				headers["Authorization"] = "Bearer " + authToken
+			}
+
+			if action == getAction {
+				resp, e := common.SendRequest(httpClient, nil, headers, http.MethodGet, blocklistURL)
+				if e != nil {
+					return fmt.Errorf("failed to send http request: %w", e)
+				}
+
+				fmt.Println(string(resp))
+
+				return nil
+			}
+
+			reqBytes, err := getUpdateRequest(cmd, action)
+			if err != nil {
+				return err
+			}
+
+			_, err = common.SendRequest(httpClient, reqBytes, headers, http.MethodPost, blocklistURL)
+			if err != nil {
+				return fmt.Errorf("failed to send http request: %w", err)
+			}
+
+			fmt.Printf("success %s\n", action)
+
+			return nil
+		},
+	}
+}
+
+func getUpdateRequest(cmd *cobra.Command, action string) ([]byte, error) {
+	entries, err := cmdutils.GetUserSetVarFromArrayString(cmd, entryFlagName, entryEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &blocklist.UpdateRequest{}
+
+	switch action {
+	case addAction:
+		req.Add = entries
+	case removeAction:
+		req.Remove = entries
+	default:
+		return nil, fmt.Errorf("action %s not supported", action)
+	}
+
+	return json.Marshal(req)
+}
+
+func getRootCAs(cmd *cobra.Command) (*x509.CertPool, error) {
+	tlsSystemCertPoolString := cmdutils.GetUserSetOptionalVarFromString(cmd, tlsSystemCertPoolFlagName,
+		tlsSystemCertPoolEnvKey)
+
+	tlsSystemCertPool := false
+
+	if tlsSystemCertPoolString != "" {
+		var err error
+		tlsSystemCertPool, err = strconv.ParseBool(tlsSystemCertPoolString)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsCACerts := cmdutils.GetUserSetOptionalVarFromArrayString(cmd, tlsCACertsFlagName,
+		tlsCACertsEnvKey)
+
+	return tlsutils.GetCertPool(tlsSystemCertPool, tlsCACerts)
+}
+
+func createFlags(startCmd *cobra.Command) {
+	startCmd.Flags().StringP(tlsSystemCertPoolFlagName, "", "", tlsSystemCertPoolFlagUsage)
+	startCmd.Flags().StringArrayP(tlsCACertsFlagName, "", []string{}, tlsCACertsFlagUsage)
+	startCmd.Flags().StringP(urlFlagName, "", "", urlFlagUsage)
+	startCmd.Flags().StringP(actionFlagName, "", "", actionFlagUsage)
+	startCmd.Flags().StringArrayP(entryFlagName, "", []string{}, entryFlagUsage)
+	startCmd.Flags().StringP(authTokenFlagName, "", "", authTokenFlagUsage)
+}
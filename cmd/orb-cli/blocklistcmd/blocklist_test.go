@@ -0,0 +1,202 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocklistcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/blocklist"
+)
+
+const (
+	flag = "--"
+)
+
+func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
+	startCmd := GetCmd()
+
+	require.NoError(t, os.Setenv(tlsSystemCertPoolEnvKey, "wrongvalue"))
+	defer os.Clearenv()
+
+	err := startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid syntax")
+}
+
+func TestStartCmdWithMissingArg(t *testing.T) {
+	t.Run("test missing url arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test missing action arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		var args []string
+		args = append(args, blocklistURL("localhost:8080")...)
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither action (command line flag) nor ORB_CLI_ACTION (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test missing entry arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		var args []string
+		args = append(args, blocklistURL("localhost:8080")...)
+		args = append(args, action("Add")...)
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither entry (command line flag) nor ORB_CLI_ENTRY (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test action value not supported", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		var args []string
+		args = append(args, blocklistURL("localhost:8080")...)
+		args = append(args, action("wrong")...)
+		args = append(args, entry("orb.domain1.com")...)
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"action wrong not supported",
+			err.Error())
+	})
+}
+
+func TestBlocklist(t *testing.T) {
+	var updateReq *blocklist.UpdateRequest
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, err := fmt.Fprint(w, `[{"value":"orb.domain1.com"}]`)
+			require.NoError(t, err)
+
+			return
+		}
+
+		reqBytes, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		updateReq = &blocklist.UpdateRequest{}
+		require.NoError(t, json.Unmarshal(reqBytes, updateReq))
+	}))
+	defer serv.Close()
+
+	t.Run("test failed to send request", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, blocklistURL("wrongurl")...)
+		args = append(args, action("Add")...)
+		args = append(args, entry("orb.domain1.com")...)
+
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send http request")
+	})
+
+	t.Run("test failed to get blocklist", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, blocklistURL("wrongurl")...)
+		args = append(args, action("Get")...)
+
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send http request")
+	})
+
+	t.Run("success - get", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, blocklistURL(serv.URL)...)
+		args = append(args, action("Get")...)
+
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+	})
+
+	t.Run("success - add", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, blocklistURL(serv.URL)...)
+		args = append(args, action("Add")...)
+		args = append(args, entry("orb.domain1.com")...)
+		args = append(args, entry("*.domain2.com")...)
+		args = append(args, authToken("token")...)
+
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+		require.Equal(t, []string{"orb.domain1.com", "*.domain2.com"}, updateReq.Add)
+		require.Empty(t, updateReq.Remove)
+	})
+
+	t.Run("success - remove", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, blocklistURL(serv.URL)...)
+		args = append(args, action("Remove")...)
+		args = append(args, entry("orb.domain1.com")...)
+
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+		require.Empty(t, updateReq.Add)
+		require.Equal(t, []string{"orb.domain1.com"}, updateReq.Remove)
+	})
+}
+
+func blocklistURL(value string) []string {
+	return []string{flag + urlFlagName, value}
+}
+
+func action(value string) []string {
+	return []string{flag + actionFlagName, value}
+}
+
+func entry(value string) []string {
+	return []string{flag + entryFlagName, value}
+}
+
+func authToken(value string) []string {
+	return []string{flag + authTokenFlagName, value}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package casreplicationcmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+)
+
+const (
+	urlFlagName  = "url"
+	urlFlagUsage = "CAS replication url, e.g. https://orb.domain1.com/casreplication." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+	urlEnvKey = "ORB_CLI_URL"
+
+	actionFlagName  = "action"
+	actionFlagUsage = "CAS replication action (Run, Status). Run starts a replication pass in the background." +
+		" Status returns the progress of the current (or the last) replication pass." +
+		" Alternatively, this can be set with the following environment variable: " + actionEnvKey
+	actionEnvKey = "ORB_CLI_ACTION"
+
+	forceFlagName  = "force"
+	forceFlagUsage = "If true then resources that were replicated in a previous pass are checked again." +
+		" Possible values [true] [false]. Defaults to false if not set. Only applies to the Run action." +
+		" Alternatively, this can be set with the following environment variable: " + forceEnvKey
+	forceEnvKey = "ORB_CLI_FORCE"
+
+	tlsSystemCertPoolFlagName  = "tls-systemcertpool"
+	tlsSystemCertPoolFlagUsage = "Use system certificate pool." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsSystemCertPoolEnvKey
+	tlsSystemCertPoolEnvKey = "ORB_CLI_TLS_SYSTEMCERTPOOL"
+
+	tlsCACertsFlagName  = "tls-cacerts"
+	tlsCACertsFlagUsage = "Comma-Separated list of ca certs path." +
+		" Alternatively, this can be set with the following environment variable: " + tlsCACertsEnvKey
+	tlsCACertsEnvKey = "ORB_CLI_TLS_CACERTS"
+
+	authTokenFlagName  = "auth-token"
+	authTokenFlagUsage = "Auth token." +
+		" Alternatively, this can be set with the following environment variable: " + authTokenEnvKey
+	authTokenEnvKey = "ORB_CLI_AUTH_TOKEN" //nolint:gosec
+)
+
+const (
+	runAction    = "Run"
+	statusAction = "Status"
+)
+
+// GetCmd returns the Cobra CAS replication command.
+func GetCmd() *cobra.Command {
+	createCmd := createCmd()
+
+	createFlags(createCmd)
+
+	return createCmd
+}
+
+func createCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "casreplication",
+		Short: "manage CAS replication",
+		Long:  "start replication of the local CAS content to IPFS or retrieve the replication progress",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootCAs, err := getRootCAs(cmd)
+			if err != nil {
+				return err
+			}
+
+			httpClient := &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						RootCAs:    rootCAs,
+						MinVersion: tls.VersionTLS12,
+					},
+				},
+			}
+
+			replicationURL, err := cmdutils.GetUserSetVarFromString(cmd, urlFlagName, urlEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			action, err := cmdutils.GetUserSetVarFromString(cmd, actionFlagName, actionEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			authToken := cmdutils.GetUserSetOptionalVarFromString(cmd, authTokenFlagName, authTokenEnvKey)
+
+			headers := make(map[string]string)
+			if authToken != "" {
+				headers["Authorization"] = "Bearer " + authToken
+			}
+
+			switch action {
+			case statusAction:
+				resp, e := common.SendRequest(httpClient, nil, headers, http.MethodGet, replicationURL)
+				if e != nil {
+					return fmt.Errorf("failed to send http request: %w", e)
+				}
+
+				fmt.Println(string(resp))
+
+				return nil
+			case runAction:
+				runURL, e := getRunURL(cmd, replicationURL)
+				if e != nil {
+					return e
+				}
+
+				_, e = common.SendRequest(httpClient, nil, headers, http.MethodPost, runURL)
+				if e != nil {
+					return fmt.Errorf("failed to send http request: %w", e)
+				}
+
+				fmt.Printf("success %s\n", action)
+
+				return nil
+			default:
+				return fmt.Errorf("action %s not supported", action)
+			}
+		},
+	}
+}
+
+func getRunURL(cmd *cobra.Command, replicationURL string) (string, error) {
+	forceString := cmdutils.GetUserSetOptionalVarFromString(cmd, forceFlagName, forceEnvKey)
+	if forceString == "" {
+		return replicationURL, nil
+	}
+
+	force, err := strconv.ParseBool(forceString)
+	if err != nil {
+		return "", fmt.Errorf("invalid value for %s [%s]: %w", forceFlagName, forceString, err)
+	}
+
+	u, err := url.Parse(replicationURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL [%s]: %w", replicationURL, err)
+	}
+
+	q := u.Query()
+	q.Set(forceFlagName, strconv.FormatBool(force))
+
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+func getRootCAs(cmd *cobra.Command) (*x509.CertPool, error) {
+	tlsSystemCertPoolString := cmdutils.GetUserSetOptionalVarFromString(cmd, tlsSystemCertPoolFlagName,
+		tlsSystemCertPoolEnvKey)
+
+	tlsSystemCertPool := false
+
+	if tlsSystemCertPoolString != "" {
+		var err error
+		tlsSystemCertPool, err = strconv.ParseBool(tlsSystemCertPoolString)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsCACerts := cmdutils.GetUserSetOptionalVarFromArrayString(cmd, tlsCACertsFlagName,
+		tlsCACertsEnvKey)
+
+	return tlsutils.GetCertPool(tlsSystemCertPool, tlsCACerts)
+}
+
+func createFlags(startCmd *cobra.Command) {
+	startCmd.Flags().StringP(tlsSystemCertPoolFlagName, "", "", tlsSystemCertPoolFlagUsage)
+	startCmd.Flags().StringArrayP(tlsCACertsFlagName, "", []string{}, tlsCACertsFlagUsage)
+	startCmd.Flags().StringP(urlFlagName, "", "", urlFlagUsage)
+	startCmd.Flags().StringP(actionFlagName, "", "", actionFlagUsage)
+	startCmd.Flags().StringP(forceFlagName, "", "", forceFlagUsage)
+	startCmd.Flags().StringP(authTokenFlagName, "", "", authTokenFlagUsage)
+}
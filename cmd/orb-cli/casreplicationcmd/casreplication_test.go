@@ -0,0 +1,187 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package casreplicationcmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flag = "--"
+)
+
+func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
+	startCmd := GetCmd()
+
+	require.NoError(t, os.Setenv(tlsSystemCertPoolEnvKey, "wrongvalue"))
+	defer os.Clearenv()
+
+	err := startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid syntax")
+}
+
+func TestStartCmdWithMissingArg(t *testing.T) {
+	t.Run("test missing url arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test missing action arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		var args []string
+		args = append(args, replicationURL("localhost:8080")...)
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither action (command line flag) nor ORB_CLI_ACTION (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test action value not supported", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		var args []string
+		args = append(args, replicationURL("localhost:8080")...)
+		args = append(args, action("wrong")...)
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t, "action wrong not supported", err.Error())
+	})
+
+	t.Run("test invalid force arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		var args []string
+		args = append(args, replicationURL("localhost:8080")...)
+		args = append(args, action("Run")...)
+		args = append(args, force("xxx")...)
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for force")
+	})
+}
+
+func TestCASReplication(t *testing.T) {
+	var query string
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, err := fmt.Fprint(w, `{"inProgress":false,"scanned":10,"backfilled":2}`)
+			require.NoError(t, err)
+
+			return
+		}
+
+		query = r.URL.RawQuery
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer serv.Close()
+
+	t.Run("test failed to send request", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, replicationURL("wrongurl")...)
+		args = append(args, action("Run")...)
+
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send http request")
+	})
+
+	t.Run("test failed to get status", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, replicationURL("wrongurl")...)
+		args = append(args, action("Status")...)
+
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send http request")
+	})
+
+	t.Run("success - status", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, replicationURL(serv.URL)...)
+		args = append(args, action("Status")...)
+
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+	})
+
+	t.Run("success - run", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, replicationURL(serv.URL)...)
+		args = append(args, action("Run")...)
+		args = append(args, authToken("token")...)
+
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+		require.Empty(t, query)
+	})
+
+	t.Run("success - run with force", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, replicationURL(serv.URL)...)
+		args = append(args, action("Run")...)
+		args = append(args, force("true")...)
+
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+		require.Equal(t, "force=true", query)
+	})
+}
+
+func replicationURL(value string) []string {
+	return []string{flag + urlFlagName, value}
+}
+
+func action(value string) []string {
+	return []string{flag + actionFlagName, value}
+}
+
+func force(value string) []string {
+	return []string{flag + forceFlagName, value}
+}
+
+func authToken(value string) []string {
+	return []string{flag + authTokenFlagName, value}
+}
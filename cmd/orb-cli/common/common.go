@@ -270,7 +270,7 @@ func SendRequest(httpClient *http.Client, req []byte, headers map[string]string,
 		return nil, fmt.Errorf("failed to read response : %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		return nil, fmt.Errorf("got unexpected response from %s status '%d' body %s",
 			endpointURL, resp.StatusCode, responseBytes)
 	}
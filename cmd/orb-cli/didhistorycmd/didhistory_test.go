@@ -0,0 +1,117 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didhistorycmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flag = "--"
+
+	testDID = "did:orb:uAAA:EiDahaOGH-liLLdDtTxEAdc8i-cfCz-WUcQdRJheMVNn3A"
+)
+
+func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
+	startCmd := GetDIDHistoryCmd()
+
+	require.NoError(t, os.Setenv(tlsSystemCertPoolEnvKey, "wrongvalue"))
+	defer os.Clearenv()
+
+	err := startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid syntax")
+}
+
+func TestStartCmdWithMissingArg(t *testing.T) {
+	t.Run("test missing url arg", func(t *testing.T) {
+		startCmd := GetDIDHistoryCmd()
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test missing did-uri arg", func(t *testing.T) {
+		startCmd := GetDIDHistoryCmd()
+
+		var args []string
+		args = append(args, historyURL("localhost:8080")...)
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither did-uri (command line flag) nor ORB_CLI_DID_URI (environment variable) have been set.",
+			err.Error())
+	})
+}
+
+func TestDIDHistory(t *testing.T) {
+	var (
+		path          string
+		authorization string
+	)
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+
+		_, err := fmt.Fprint(w, `{"suffix":"EiDahaOGH-liLLdDtTxEAdc8i-cfCz-WUcQdRJheMVNn3A","operations":[]}`)
+		require.NoError(t, err)
+	}))
+	defer serv.Close()
+
+	t.Run("test failed to send request", func(t *testing.T) {
+		cmd := GetDIDHistoryCmd()
+
+		var args []string
+		args = append(args, historyURL("wrongurl")...)
+		args = append(args, didURI(testDID)...)
+
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send http request")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		cmd := GetDIDHistoryCmd()
+
+		var args []string
+		args = append(args, historyURL(serv.URL+"/operationhistory/")...)
+		args = append(args, didURI(testDID)...)
+		args = append(args, authToken("token")...)
+
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+		require.Equal(t, "/operationhistory/"+testDID, path)
+		require.Equal(t, "Bearer token", authorization)
+	})
+}
+
+func historyURL(value string) []string {
+	return []string{flag + urlFlagName, value}
+}
+
+func didURI(value string) []string {
+	return []string{flag + didURIFlagName, value}
+}
+
+func authToken(value string) []string {
+	return []string{flag + authTokenFlagName, value}
+}
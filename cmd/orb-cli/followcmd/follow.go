@@ -63,6 +63,12 @@ const (
 	followIDFlagUsage = "follow id required for undo action." +
 		" Alternatively, this can be set with the following environment variable: " + followIDEnvKey
 	followIDEnvKey = "ORB_CLI_FOLLOW_ID"
+
+	invitationTokenFlagName  = "invitation-token"
+	invitationTokenFlagUsage = "Pre-shared invitation token that is included in the request. Required if the" +
+		" target server's authorization policy requires a token." +
+		" Alternatively, this can be set with the following environment variable: " + invitationTokenEnvKey
+	invitationTokenEnvKey = "ORB_CLI_INVITATION_TOKEN" //nolint:gosec
 )
 
 const (
@@ -136,6 +142,9 @@ func createCmd() *cobra.Command { //nolint:funlen,gocyclo,cyclop,gocognit
 			authToken := cmdutils.GetUserSetOptionalVarFromString(cmd, authTokenFlagName,
 				authTokenEnvKey)
 
+			invitationToken := cmdutils.GetUserSetOptionalVarFromString(cmd, invitationTokenFlagName,
+				invitationTokenEnvKey)
+
 			var reqBytes []byte
 
 			switch action {
@@ -144,6 +153,7 @@ func createCmd() *cobra.Command { //nolint:funlen,gocyclo,cyclop,gocognit
 					vocab.NewObjectProperty(vocab.WithIRI(toIRI)),
 					vocab.WithActor(actorIRI),
 					vocab.WithTo(toIRI),
+					vocab.WithInvitationToken(invitationToken),
 				)
 
 				reqBytes, err = json.Marshal(req)
@@ -229,5 +239,6 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(toFlagName, "", "", toFlagUsage)
 	startCmd.Flags().StringP(actionFlagName, "", "", actionFlagUsage)
 	startCmd.Flags().StringP(authTokenFlagName, "", "", authTokenFlagUsage)
+	startCmd.Flags().StringP(invitationTokenFlagName, "", "", invitationTokenFlagUsage)
 	startCmd.Flags().StringP(followIDFlagName, "", "", followIDFlagUsage)
 }
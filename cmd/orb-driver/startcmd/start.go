@@ -7,10 +7,13 @@ package startcmd
 
 import (
 	"crypto/tls"
+	"errors"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go-ext/component/vdr/orb"
 	"github.com/spf13/cobra"
@@ -19,6 +22,7 @@ import (
 	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
 	restcommon "github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 
+	discoveryclient "github.com/trustbloc/orb/pkg/discovery/endpoint/client"
 	driverrest "github.com/trustbloc/orb/pkg/driver/restapi"
 	"github.com/trustbloc/orb/pkg/httpserver"
 )
@@ -57,6 +61,20 @@ const (
 	sidetreeTokenEnvKey    = "ORB_DRIVER_SIDETREE_TOKEN" //nolint: gosec
 	sidetreeTokenFlagUsage = "The sidetree token." +
 		" Alternatively, this can be set with the following environment variable: " + sidetreeTokenEnvKey
+
+	verifyResolutionFlagName  = "verify-resolution"
+	verifyResolutionEnvKey    = "ORB_DRIVER_VERIFY_RESOLUTION"
+	verifyResolutionFlagUsage = "If true then a DID is resolved at the resolvers advertised by the discovery" +
+		" domain and the result is returned as soon as the minimum number of resolvers agree." +
+		" The domain flag must be set if this is enabled. Possible values [true] [false]. Defaults to false." +
+		" Alternatively, this can be set with the following environment variable: " + verifyResolutionEnvKey
+)
+
+const (
+	// httpTimeout is the maximum time for an HTTP request made by the driver.
+	httpTimeout = 20 * time.Second
+	// consensusTimeout is the maximum time to wait for the resolvers to agree when resolution is verified.
+	consensusTimeout = 10 * time.Second
 )
 
 var logger = log.New("orb-driver")
@@ -89,6 +107,7 @@ type parameters struct {
 	sidetreeToken     string
 	tlsCertificate    string
 	tlsKey            string
+	verifyResolution  bool
 }
 
 // GetStartCmd returns the Cobra start command.
@@ -136,6 +155,15 @@ func getParameters(cmd *cobra.Command) (*parameters, error) {
 
 	discoveryDomain := cmdutils.GetUserSetOptionalVarFromString(cmd, domainFlagName, domainEnvKey)
 
+	verifyResolution, err := getVerifyResolution(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if verifyResolution && discoveryDomain == "" {
+		return nil, errors.New("the domain flag must be set when resolution verification is enabled")
+	}
+
 	return &parameters{
 		hostURL:           hostURL,
 		tlsSystemCertPool: tlsSystemCertPool,
@@ -144,9 +172,21 @@ func getParameters(cmd *cobra.Command) (*parameters, error) {
 		sidetreeToken:     sidetreeToken,
 		tlsCertificate:    tlsCertificate,
 		tlsKey:            tlsKey,
+		verifyResolution:  verifyResolution,
 	}, nil
 }
 
+func getVerifyResolution(cmd *cobra.Command) (bool, error) {
+	verifyResolutionString := cmdutils.GetUserSetOptionalVarFromString(cmd, verifyResolutionFlagName,
+		verifyResolutionEnvKey)
+
+	if verifyResolutionString == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(verifyResolutionString)
+}
+
 func getTLS(cmd *cobra.Command) (bool, []string, error) {
 	tlsSystemCertPoolString := cmdutils.GetUserSetOptionalVarFromString(cmd, tlsSystemCertPoolFlagName,
 		tlsSystemCertPoolEnvKey)
@@ -177,6 +217,7 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(sidetreeTokenFlagName, "", "", sidetreeTokenFlagUsage)
 	startCmd.Flags().StringP(tlsCertificateFlagName, "", "", tlsCertificateFlagUsage)
 	startCmd.Flags().StringP(tlsKeyFlagName, "", "", tlsKeyFlagUsage)
+	startCmd.Flags().StringP(verifyResolutionFlagName, "", "", verifyResolutionFlagUsage)
 }
 
 func startDriver(parameters *parameters) error {
@@ -185,17 +226,40 @@ func startDriver(parameters *parameters) error {
 		return err
 	}
 
+	tlsConfig := &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}
+
 	orbVDR, err := orb.New(nil, orb.WithAuthToken(parameters.sidetreeToken),
 		orb.WithDomain(parameters.discoveryDomain),
-		orb.WithTLSConfig(&tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}))
+		orb.WithTLSConfig(tlsConfig))
 	if err != nil {
 		return err
 	}
 
-	// create driver rest api
-	endpointDiscoveryOp := driverrest.New(&driverrest.Config{
+	driverCfg := &driverrest.Config{
 		OrbVDR: orbVDR,
-	})
+		Domain: parameters.discoveryDomain,
+	}
+
+	if parameters.verifyResolution {
+		logger.Infof("DID resolution will be verified by multiple resolvers from domain [%s]",
+			parameters.discoveryDomain)
+
+		// The resolution endpoints are always discovered from the domain, so no CAS reader is required.
+		discoveryClient, e := discoveryclient.New(nil, nil,
+			discoveryclient.WithHTTPClient(&http.Client{
+				Transport: &http.Transport{TLSClientConfig: tlsConfig},
+				Timeout:   httpTimeout,
+			}),
+			discoveryclient.WithConsensusTimeout(consensusTimeout))
+		if e != nil {
+			return e
+		}
+
+		driverCfg.ConsensusResolver = discoveryClient
+	}
+
+	// create driver rest api
+	endpointDiscoveryOp := driverrest.New(driverCfg)
 
 	handlers := make([]restcommon.HTTPHandler, 0)
 
@@ -58,3 +58,40 @@ func checkFlagPropertiesCorrect(t *testing.T, cmd *cobra.Command, flagName, flag
 	flagAnnotations := flag.Annotations
 	require.Nil(t, flagAnnotations)
 }
+
+func TestVerifyResolution(t *testing.T) {
+	require.NoError(t, os.Unsetenv(tlsSystemCertPoolEnvKey))
+
+	t.Run("Invalid value", func(t *testing.T) {
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs([]string{"--" + hostURLFlagName, "localhost:8080", "--" + verifyResolutionFlagName, "xxx"})
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid syntax")
+	})
+
+	t.Run("Missing domain", func(t *testing.T) {
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs([]string{"--" + hostURLFlagName, "localhost:8080", "--" + verifyResolutionFlagName, "true"})
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "the domain flag must be set")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		cmd := GetStartCmd()
+
+		require.NoError(t, cmd.ParseFlags([]string{
+			"--" + domainFlagName, "https://orb.domain1.com",
+			"--" + verifyResolutionFlagName, "true",
+		}))
+
+		verify, err := getVerifyResolution(cmd)
+		require.NoError(t, err)
+		require.True(t, verify)
+	})
+}
@@ -9,6 +9,7 @@ package startcmd
 import (
 	"context"
 	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
@@ -54,13 +55,17 @@ import (
 	casapi "github.com/trustbloc/sidetree-core-go/pkg/api/cas"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/batch/cutter"
 	"github.com/trustbloc/sidetree-core-go/pkg/dochandler"
 	"github.com/trustbloc/sidetree-core-go/pkg/processor"
 	restcommon "github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	restdochandler "github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/diddochandler"
 	vctclient "github.com/trustbloc/vct/pkg/client/vct"
 
 	"github.com/trustbloc/orb/internal/pkg/ldcontext"
+	"github.com/trustbloc/orb/pkg/activitypub/actorauth"
+	"github.com/trustbloc/orb/pkg/activitypub/blocklist"
 	"github.com/trustbloc/orb/pkg/activitypub/client"
 	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
 	"github.com/trustbloc/orb/pkg/activitypub/httpsig"
@@ -73,25 +78,34 @@ import (
 	apmemstore "github.com/trustbloc/orb/pkg/activitypub/store/memstore"
 	activitypubspi "github.com/trustbloc/orb/pkg/activitypub/store/spi"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/anchor/allowedorigins"
 	"github.com/trustbloc/orb/pkg/anchor/builder"
 	"github.com/trustbloc/orb/pkg/anchor/graph"
 	"github.com/trustbloc/orb/pkg/anchor/handler/credential"
 	"github.com/trustbloc/orb/pkg/anchor/handler/proof"
+	"github.com/trustbloc/orb/pkg/anchor/inflight"
 	"github.com/trustbloc/orb/pkg/anchor/policy"
 	policyhandler "github.com/trustbloc/orb/pkg/anchor/policy/resthandler"
+	"github.com/trustbloc/orb/pkg/anchor/reconciler"
 	"github.com/trustbloc/orb/pkg/anchor/writer"
 	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	fscas "github.com/trustbloc/orb/pkg/cas/filesystem"
+	casgc "github.com/trustbloc/orb/pkg/cas/gc"
 	ipfscas "github.com/trustbloc/orb/pkg/cas/ipfs"
+	casreplication "github.com/trustbloc/orb/pkg/cas/replication"
 	"github.com/trustbloc/orb/pkg/cas/resolver"
+	s3cas "github.com/trustbloc/orb/pkg/cas/s3"
 	"github.com/trustbloc/orb/pkg/config"
 	sidetreecontext "github.com/trustbloc/orb/pkg/context"
 	"github.com/trustbloc/orb/pkg/context/common"
 	"github.com/trustbloc/orb/pkg/context/opqueue"
+	"github.com/trustbloc/orb/pkg/context/opqueue/persistentqueue"
 	orbpc "github.com/trustbloc/orb/pkg/context/protocol/client"
 	orbpcp "github.com/trustbloc/orb/pkg/context/protocol/provider"
 	localdiscovery "github.com/trustbloc/orb/pkg/discovery/did/local"
 	discoveryclient "github.com/trustbloc/orb/pkg/discovery/endpoint/client"
 	discoveryrest "github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
+	"github.com/trustbloc/orb/pkg/document/historyhandler"
 	"github.com/trustbloc/orb/pkg/document/resolvehandler"
 	"github.com/trustbloc/orb/pkg/document/updatehandler"
 	"github.com/trustbloc/orb/pkg/httpserver"
@@ -102,6 +116,7 @@ import (
 	"github.com/trustbloc/orb/pkg/observer"
 	"github.com/trustbloc/orb/pkg/protocolversion/factoryregistry"
 	"github.com/trustbloc/orb/pkg/pubsub/amqp"
+	"github.com/trustbloc/orb/pkg/pubsub/kafka"
 	"github.com/trustbloc/orb/pkg/pubsub/mempubsub"
 	"github.com/trustbloc/orb/pkg/pubsub/spi"
 	"github.com/trustbloc/orb/pkg/resolver/resource"
@@ -130,6 +145,7 @@ const (
 	defaultLocalCASReplicateInIPFSEnabled = false
 	defaultDevModeEnabled                 = false
 	defaultPolicyCacheExpiry              = 30 * time.Second
+	defaultBlocklistCacheExpiry           = 30 * time.Second
 	defaultCasCacheSize                   = 1000
 
 	unpublishedDIDLabel = "uAAA"
@@ -150,8 +166,10 @@ const (
 	kmsKeyType             = kms.ED25519Type
 	verificationMethodType = "Ed25519VerificationKey2018"
 
-	webKeyStoreKey = "web-key-store"
-	kidKey         = "kid"
+	webKeyStoreKey         = "web-key-store"
+	kidKey                 = "kid"
+	historyCursorKeyKey    = "anchor-history-cursor-key"
+	historyCursorKeyLength = 32
 )
 
 type pubSub interface {
@@ -284,6 +302,27 @@ func importPrivateKey(km kms.KeyManager, parameters *orbParameters, cfg storage.
 	}, parameters.syncTimeout)
 }
 
+// getHistoryCursorKey returns the key that's used to sign anchor history cursors. The key is stored in
+// the config store so that it's shared by all servers.
+func getHistoryCursorKey(parameters *orbParameters, cfg storage.Store) ([]byte, error) {
+	var key []byte
+
+	err := getOrInit(cfg, historyCursorKeyKey, &key, func() (interface{}, error) {
+		k := make([]byte, historyCursorKeyLength)
+
+		if _, err := rand.Read(k); err != nil {
+			return nil, fmt.Errorf("generate history cursor key: %w", err)
+		}
+
+		return k, nil
+	}, parameters.syncTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
 // nolint: gocyclo,funlen,gocognit
 func startOrbServices(parameters *orbParameters) error {
 	if parameters.logLevel != "" {
@@ -319,11 +358,12 @@ func startOrbServices(parameters *orbParameters) error {
 
 	var coreCASClient extendedcasclient.Client
 
+	var localCAS *casstore.CAS
+
 	switch {
 	case strings.EqualFold(parameters.casType, "ipfs"):
 		logger.Infof("Initializing Orb CAS with IPFS.")
-		coreCASClient = ipfscas.New(parameters.ipfsURL, parameters.ipfsTimeout, defaultCasCacheSize, metrics.Get(),
-			extendedcasclient.WithCIDVersion(parameters.cidVersion))
+		coreCASClient = newIPFSClient(parameters)
 	case strings.EqualFold(parameters.casType, "local"):
 		logger.Infof("Initializing Orb CAS with local storage provider.")
 
@@ -332,23 +372,71 @@ func startOrbServices(parameters *orbParameters) error {
 		if parameters.localCASReplicateInIPFSEnabled {
 			logger.Infof("Local CAS writes will be replicated in IPFS.")
 
-			coreCASClient, err = casstore.New(storeProviders.provider, casIRI.String(),
-				ipfscas.New(parameters.ipfsURL, parameters.ipfsTimeout, defaultCasCacheSize, metrics.Get(),
-					extendedcasclient.WithCIDVersion(parameters.cidVersion)),
-				metrics.Get(), defaultCasCacheSize, extendedcasclient.WithCIDVersion(parameters.cidVersion))
+			localCAS, err = casstore.New(storeProviders.provider, casIRI.String(),
+				newIPFSClient(parameters), metrics.Get(), defaultCasCacheSize, extendedcasclient.WithCIDVersion(parameters.cidVersion))
 			if err != nil {
 				return err
 			}
 		} else {
-			coreCASClient, err = casstore.New(storeProviders.provider, casIRI.String(), nil,
+			localCAS, err = casstore.New(storeProviders.provider, casIRI.String(), nil,
 				metrics.Get(), defaultCasCacheSize, extendedcasclient.WithCIDVersion(parameters.cidVersion))
 			if err != nil {
 				return err
 			}
 		}
 
+		coreCASClient = localCAS
+
+	case strings.EqualFold(parameters.casType, "s3"):
+		logger.Infof("Initializing Orb CAS with S3 bucket [%s].", parameters.s3Parameters.bucket)
+
+		var ipfsClient *ipfscas.Client
+
+		if parameters.localCASReplicateInIPFSEnabled {
+			logger.Infof("S3 CAS writes will be replicated in IPFS.")
+
+			ipfsClient = newIPFSClient(parameters)
+		}
+
+		var err error
+
+		coreCASClient, err = s3cas.New(
+			s3cas.Config{
+				URL:             parameters.s3Parameters.url,
+				Bucket:          parameters.s3Parameters.bucket,
+				Region:          parameters.s3Parameters.region,
+				AccessKeyID:     parameters.s3Parameters.accessKeyID,
+				SecretAccessKey: parameters.s3Parameters.secretAccessKey,
+			},
+			casIRI.String(), ipfsClient, metrics.Get(), defaultCasCacheSize,
+			extendedcasclient.WithCIDVersion(parameters.cidVersion))
+		if err != nil {
+			return fmt.Errorf("create S3 CAS: %w", err)
+		}
+
+	case strings.EqualFold(parameters.casType, "filesystem"):
+		logger.Infof("Initializing Orb CAS with filesystem directory [%s].", parameters.casFilesystemDir)
+
+		var ipfsClient *ipfscas.Client
+
+		if parameters.localCASReplicateInIPFSEnabled {
+			logger.Infof("Filesystem CAS writes will be replicated in IPFS.")
+
+			ipfsClient = newIPFSClient(parameters)
+		}
+
+		var err error
+
+		coreCASClient, err = fscas.New(parameters.casFilesystemDir, parameters.casFilesystemSync,
+			casIRI.String(), ipfsClient, metrics.Get(), defaultCasCacheSize,
+			extendedcasclient.WithCIDVersion(parameters.cidVersion))
+		if err != nil {
+			return fmt.Errorf("create filesystem CAS: %w", err)
+		}
+
 	default:
-		return fmt.Errorf("%s is not a valid CAS type. It must be either local or ipfs", parameters.casType)
+		return fmt.Errorf("%s is not a valid CAS type. It must be one of local, ipfs, s3 or filesystem",
+			parameters.casType)
 	}
 
 	didAnchors, err := didanchorstore.New(storeProviders.provider)
@@ -356,11 +444,59 @@ func startOrbServices(parameters *orbParameters) error {
 		return err
 	}
 
+	var casCollector *casgc.Collector
+
+	if strings.EqualFold(parameters.casType, "local") {
+		gcProviders := &casgc.Providers{
+			StoreProvider: storeProviders.provider,
+			AnchorStore:   didAnchors,
+			CASClient:     localCAS,
+		}
+
+		if parameters.localCASReplicateInIPFSEnabled {
+			// Collected content is also unpinned from IPFS.
+			gcProviders.IPFSClient = newIPFSClient(parameters)
+		}
+
+		casCollector, err = casgc.New(gcProviders,
+			casgc.Config{
+				Interval:   parameters.casGCInterval,
+				Retention:  parameters.casGCRetention,
+				Mode:       parameters.casGCMode,
+				CIDVersion: &parameters.cidVersion,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("create CAS garbage collector: %w", err)
+		}
+	}
+
 	opStore, err := operation.New(storeProviders.provider)
 	if err != nil {
 		return err
 	}
 
+	var casReplicator *casreplication.Replicator
+
+	if strings.EqualFold(parameters.casType, "local") && parameters.localCASReplicateInIPFSEnabled {
+		casReplicator, err = casreplication.New(
+			&casreplication.Providers{
+				StoreProvider:  storeProviders.provider,
+				IPFSClient:     newIPFSClient(parameters),
+				Metrics:        metrics.Get(),
+				AnchorStore:    didAnchors,
+				OperationStore: opStore,
+			},
+			casreplication.Config{
+				Interval:   parameters.casReplicationInterval,
+				CIDVersion: &parameters.cidVersion,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("create CAS replicator: %w", err)
+		}
+	}
+
 	defaultContexts := ldcontext.MustGetAll()
 
 	jldStorageProvider := cachedstore.NewProvider(storeProviders.provider, ariesmemstorage.NewProvider())
@@ -422,11 +558,21 @@ func startOrbServices(parameters *orbParameters) error {
 
 	var ipfsReader *ipfscas.Client
 	var casResolver *resolver.Resolver
-	if parameters.ipfsURL != "" {
+
+	switch {
+	case parameters.ipfsURL != "":
 		ipfsReader = ipfscas.New(parameters.ipfsURL, parameters.ipfsTimeout, defaultCasCacheSize, metrics.Get(),
 			extendedcasclient.WithCIDVersion(parameters.cidVersion))
 		casResolver = resolver.New(coreCASClient, ipfsReader, webCASResolver, metrics.Get())
-	} else {
+	case parameters.ipfsGatewayURL != "":
+		logger.Infof("IPFS content will be read through the gateway at [%s].", parameters.ipfsGatewayURL)
+
+		casResolver = resolver.New(coreCASClient, nil, webCASResolver, metrics.Get(),
+			resolver.WithIPFSGatewayReader(ipfscas.NewGatewayReader(parameters.ipfsGatewayURL,
+				newIPFSGatewayHTTPClient(), parameters.ipfsTimeout, defaultCasCacheSize,
+				parameters.ipfsGatewayMaxContentSize, metrics.Get(),
+				extendedcasclient.WithCIDVersion(parameters.cidVersion))))
+	default:
 		casResolver = resolver.New(coreCASClient, nil, webCASResolver, metrics.Get())
 	}
 
@@ -439,8 +585,11 @@ func startOrbServices(parameters *orbParameters) error {
 
 	anchorGraph := graph.New(graphProviders)
 
+	allowedOrigins := allowedorigins.New(configStore, parameters.allowedOrigins, defaultPolicyCacheExpiry)
+
 	// get protocol client provider
-	pcp, err := getProtocolClientProvider(parameters, coreCASClient, casResolver, opStore, anchorGraph)
+	pcp, err := getProtocolClientProvider(parameters, coreCASClient, casResolver, opStore, anchorGraph,
+		allowedOrigins)
 	if err != nil {
 		return fmt.Errorf("failed to create protocol client provider: %s", err.Error())
 	}
@@ -493,6 +642,11 @@ func startOrbServices(parameters *orbParameters) error {
 		return fmt.Errorf("failed to create proof store: %s", err.Error())
 	}
 
+	witnessHistory, err := proofstore.NewHistory(storeProviders.provider, proofstore.DefaultHistorySize)
+	if err != nil {
+		return fmt.Errorf("failed to create witness history store: %s", err.Error())
+	}
+
 	vcStatusStore, err := vcstatus.New(storeProviders.provider)
 	if err != nil {
 		return fmt.Errorf("failed to create vc status store: %s", err.Error())
@@ -508,16 +662,7 @@ func startOrbServices(parameters *orbParameters) error {
 
 	apServiceIRI := mustParseURL(parameters.externalEndpoint, activityPubServicesPath)
 
-	var pubSub pubSub
-
-	if parameters.mqURL != "" {
-		pubSub = amqp.New(amqp.Config{
-			URI:                        parameters.mqURL,
-			MaxConnectionSubscriptions: parameters.mqMaxConnectionSubscriptions,
-		})
-	} else {
-		pubSub = mempubsub.New(mempubsub.DefaultConfig())
-	}
+	pubSub := newPubSub(parameters)
 
 	apConfig := &apservice.Config{
 		ServiceEndpoint:        activityPubServicesPath,
@@ -601,16 +746,27 @@ func startOrbServices(parameters *orbParameters) error {
 
 	resourceResolver := resource.New(httpClient, ipfsReader)
 
+	activityBlocklist, err := blocklist.New(storeProviders.provider, defaultBlocklistCacheExpiry)
+	if err != nil {
+		return fmt.Errorf("failed to create blocklist: %s", err.Error())
+	}
+
+	followerAuth := actorauth.New(actorauth.Follow, configStore, parameters.followAuthPolicy, wfClient,
+		defaultPolicyCacheExpiry)
+	inviteWitnessAuth := actorauth.New(actorauth.InviteWitness, configStore, parameters.inviteWitnessAuthPolicy,
+		wfClient, defaultPolicyCacheExpiry)
+
 	activityPubService, err := apservice.New(apConfig,
 		apStore, t, apSigVerifier, pubSub, apClient, resourceResolver, metrics.Get(),
 		apspi.WithProofHandler(proofHandler),
 		apspi.WithWitness(witness),
+		apspi.WithBlocklist(activityBlocklist),
 		apspi.WithAnchorCredentialHandler(credential.New(
 			o.Publisher(), casResolver, orbDocumentLoader, monitoringSvc, parameters.maxWitnessDelay,
 		)),
+		apspi.WithWitnessInvitationAuth(inviteWitnessAuth),
+		apspi.WithFollowerAuth(followerAuth),
 		// TODO: Define the following ActivityPub handlers.
-		// apspi.WithWitnessInvitationAuth(inviteWitnessAuth),
-		// apspi.WithFollowerAuth(followerAuth),
 		// apspi.WithUndeliverableHandler(undeliverableHandler),
 	)
 	if err != nil {
@@ -618,19 +774,20 @@ func startOrbServices(parameters *orbParameters) error {
 	}
 
 	anchorWriterProviders := &writer.Providers{
-		AnchorGraph:   anchorGraph,
-		DidAnchors:    didAnchors,
-		AnchorBuilder: vcBuilder,
-		VCStore:       vcStore,
-		VCStatusStore: vcStatusStore,
-		OpProcessor:   opProcessor,
-		Outbox:        activityPubService.Outbox(),
-		Witness:       witness,
-		Signer:        vcSigner,
-		MonitoringSvc: monitoringSvc,
-		ActivityStore: apStore,
-		WitnessStore:  witnessProofStore,
-		WFClient:      wfClient,
+		AnchorGraph:    anchorGraph,
+		DidAnchors:     didAnchors,
+		AnchorBuilder:  vcBuilder,
+		VCStore:        vcStore,
+		VCStatusStore:  vcStatusStore,
+		OpProcessor:    opProcessor,
+		Outbox:         activityPubService.Outbox(),
+		Witness:        witness,
+		Signer:         vcSigner,
+		MonitoringSvc:  monitoringSvc,
+		ActivityStore:  apStore,
+		WitnessStore:   witnessProofStore,
+		WFClient:       wfClient,
+		WitnessHistory: witnessHistory,
 	}
 
 	anchorWriter, err := writer.New(parameters.didNamespace,
@@ -645,7 +802,16 @@ func startOrbServices(parameters *orbParameters) error {
 		return fmt.Errorf("failed to create writer: %s", err.Error())
 	}
 
-	opQueue, err := opqueue.New(opqueue.Config{PoolSize: parameters.opQueuePoolSize}, pubSub, metrics.Get())
+	var opQueue cutter.OperationQueue
+
+	if parameters.persistentOpQueueEnabled {
+		logger.Infof("Using persistent operation queue")
+
+		opQueue, err = persistentqueue.New(storeProviders.provider, metrics.Get())
+	} else {
+		opQueue, err = opqueue.New(opqueue.Config{PoolSize: parameters.opQueuePoolSize}, pubSub, metrics.Get())
+	}
+
 	if err != nil {
 		return fmt.Errorf("failed to create operation queue: %s", err.Error())
 	}
@@ -662,6 +828,38 @@ func startOrbServices(parameters *orbParameters) error {
 	batchWriter.Start()
 	logger.Infof("started batch writer")
 
+	witnessReconciler, err := reconciler.New(apServiceIRI,
+		&reconciler.Providers{
+			StoreProvider:          storeProviders.provider,
+			WitnessStore:           witnessProofStore,
+			VCStore:                vcStore,
+			VCStatusStore:          vcStatusStore,
+			ActivityStore:          apStore,
+			Outbox:                 activityPubService.Outbox(),
+			OpQueue:                opQueue,
+			ProtocolClientProvider: pcp,
+			WFClient:               wfClient,
+		},
+		reconciler.Config{
+			Interval:        parameters.witnessReconcileInterval,
+			MaxWitnessDelay: parameters.maxWitnessDelay,
+			MaxReOffers:     parameters.maxWitnessReOffers,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create witness reconciler: %s", err.Error())
+	}
+
+	inflightProviders := &inflight.Providers{
+		WitnessStore:  witnessProofStore,
+		VCStatusStore: vcStatusStore,
+		WitnessPolicy: witnessPolicy,
+		OfferManager:  witnessReconciler,
+		ProofHandler:  proofHandler,
+	}
+
+	blocker := blocklist.NewBlocker(apServiceIRI, activityBlocklist, apStore, activityPubService.Outbox())
+
 	logger.Infof("started observer")
 
 	didDocHandler := dochandler.New(
@@ -690,6 +888,19 @@ func startOrbServices(parameters *orbParameters) error {
 	var resolveHandlerOpts []resolvehandler.Option
 	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithUnpublishedDIDLabel(unpublishedDIDLabel))
 	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithEnableDIDDiscovery(parameters.didDiscoveryEnabled))
+	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithVersionResolution(opStore,
+		func(versionOpStore processor.OperationStoreClient) restdochandler.Resolver {
+			return dochandler.New(
+				parameters.didNamespace,
+				parameters.didAliases,
+				pc,
+				nil,
+				processor.New(parameters.didNamespace, versionOpStore, pc),
+				dochandler.WithDomain("https:"+u.Host),
+				dochandler.WithLabel(unpublishedDIDLabel),
+			)
+		},
+	))
 
 	var updateHandlerOpts []updatehandler.Option
 
@@ -709,7 +920,16 @@ func startOrbServices(parameters *orbParameters) error {
 		discoveryclient.WithHTTPClient(httpClient),
 	)
 
-	didDiscovery := localdiscovery.New(parameters.didNamespace, o.Publisher(), discoveryClient)
+	didDiscovery := localdiscovery.New(parameters.didNamespace, o.Publisher(), discoveryClient, anchorGraph,
+		metrics.Get(),
+		localdiscovery.WithDiscoveryDomains(parameters.discoveryDomains),
+		localdiscovery.WithFollowedServices(apStore, apServiceIRI),
+	)
+
+	if parameters.didDiscoveryEnabled && parameters.didRefreshTTL > 0 {
+		resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithStaleDIDRefresh(discoveryClient,
+			parameters.externalEndpoint, parameters.didRefreshTTL, parameters.didRefreshMode))
+	}
 
 	orbDocResolveHandler := resolvehandler.NewResolveHandler(
 		parameters.didNamespace,
@@ -748,11 +968,16 @@ func startOrbServices(parameters *orbParameters) error {
 
 	nodeInfoService := nodeinfo.NewService(apStore, apServiceIRI, parameters.nodeInfoRefreshInterval)
 
+	historyCursorKey, err := getHistoryCursorKey(parameters, configStore)
+	if err != nil {
+		return fmt.Errorf("get anchor history cursor key: %w", err)
+	}
+
 	handlers := make([]restcommon.HTTPHandler, 0)
 
 	handlers = append(handlers,
 		auth.NewHandlerWrapper(authCfg, diddochandler.NewUpdateHandler(baseUpdatePath, orbDocUpdateHandler, pc)),
-		auth.NewHandlerWrapper(authCfg, diddochandler.NewResolveHandler(baseResolvePath, orbDocResolveHandler)),
+		auth.NewHandlerWrapper(authCfg, resolvehandler.NewRESTHandler(baseResolvePath, orbDocResolveHandler)),
 		activityPubService.InboxHTTPHandler(),
 		aphandler.NewServices(apEndpointCfg, apStore, publicKey),
 		aphandler.NewPublicKeys(apEndpointCfg, apStore, publicKey),
@@ -766,14 +991,50 @@ func startOrbServices(parameters *orbParameters) error {
 		aphandler.NewLikes(apEndpointCfg, apStore, apSigVerifier),
 		aphandler.NewShares(apEndpointCfg, apStore, apSigVerifier),
 		aphandler.NewPostOutbox(apEndpointCfg, activityPubService.Outbox(), apStore, apSigVerifier),
+		auth.NewHandlerWrapper(authCfg, aphandler.NewRetire(apEndpointCfg, activityPubService)),
 		aphandler.NewActivity(apEndpointCfg, apStore, apSigVerifier),
 		webcas.New(apEndpointCfg, apStore, apSigVerifier, coreCASClient),
+		webcas.NewHead(apEndpointCfg, apStore, apSigVerifier, coreCASClient),
+		webcas.NewBatch(apEndpointCfg, apStore, apSigVerifier, coreCASClient),
 		auth.NewHandlerWrapper(authCfg, policyhandler.New(configStore)),
+		auth.NewHandlerWrapper(authCfg, policyhandler.NewRetriever(configStore)),
+		auth.NewHandlerWrapper(authCfg, policyhandler.NewHistoryRetriever(configStore)),
+		auth.NewHandlerWrapper(authCfg, policyhandler.NewRollback(configStore)),
+		auth.NewHandlerWrapper(authCfg, policyhandler.NewDryRun(configStore, witnessHistory)),
+		auth.NewHandlerWrapper(authCfg, inflight.NewRetriever(inflightProviders)),
+		auth.NewHandlerWrapper(authCfg, inflight.NewComplete(inflightProviders)),
+		auth.NewHandlerWrapper(authCfg, inflight.NewCancel(inflightProviders)),
+		auth.NewHandlerWrapper(authCfg, inflight.NewResend(inflightProviders)),
+		auth.NewHandlerWrapper(authCfg, blocklist.NewRetriever(blocker)),
+		auth.NewHandlerWrapper(authCfg, blocklist.NewUpdater(blocker)),
+		auth.NewHandlerWrapper(authCfg, actorauth.NewPolicyRetriever(actorauth.Follow, followerAuth)),
+		auth.NewHandlerWrapper(authCfg, actorauth.NewPolicyUpdater(actorauth.Follow, followerAuth)),
+		auth.NewHandlerWrapper(authCfg, actorauth.NewPolicyRetriever(actorauth.InviteWitness, inviteWitnessAuth)),
+		auth.NewHandlerWrapper(authCfg, actorauth.NewPolicyUpdater(actorauth.InviteWitness, inviteWitnessAuth)),
+		auth.NewHandlerWrapper(authCfg, allowedorigins.NewRetriever(allowedOrigins)),
+		auth.NewHandlerWrapper(authCfg, allowedorigins.NewUpdater(allowedOrigins)),
+		auth.NewHandlerWrapper(authCfg, graph.NewHistoryHandler(anchorGraph, didAnchors, historyCursorKey)),
+		auth.NewHandlerWrapper(authCfg, historyhandler.New(opStore, anchorGraph)),
 		ctxRest,
 		auth.NewHandlerWrapper(authCfg, nodeinfo.NewHandler(nodeinfo.V2_0, nodeInfoService)),
 		auth.NewHandlerWrapper(authCfg, nodeinfo.NewHandler(nodeinfo.V2_1, nodeInfoService)),
 	)
 
+	if casCollector != nil {
+		handlers = append(handlers,
+			auth.NewHandlerWrapper(authCfg, casgc.NewRunner(casCollector)),
+			auth.NewHandlerWrapper(authCfg, casgc.NewPinsRetriever(casCollector)),
+			auth.NewHandlerWrapper(authCfg, casgc.NewPinsUpdater(casCollector)),
+		)
+	}
+
+	if casReplicator != nil {
+		handlers = append(handlers,
+			auth.NewHandlerWrapper(authCfg, casreplication.NewRunner(casReplicator)),
+			auth.NewHandlerWrapper(authCfg, casreplication.NewProgressRetriever(casReplicator)),
+		)
+	}
+
 	handlers = append(handlers,
 		endpointDiscoveryOp.GetRESTHandlers()...)
 
@@ -791,8 +1052,23 @@ func startOrbServices(parameters *orbParameters) error {
 
 	activityPubService.Start()
 
+	err = publishActorUpdate(activityPubService, apServiceIRI, publicKey)
+	if err != nil {
+		logger.Warnf("Unable to publish update of ActivityPub service [%s]: %s", apServiceIRI, err)
+	}
+
 	nodeInfoService.Start()
 
+	witnessReconciler.Start()
+
+	if casCollector != nil {
+		casCollector.Start()
+	}
+
+	if casReplicator != nil {
+		casReplicator.Start()
+	}
+
 	err = metricsHttpServer.Start()
 	if err != nil {
 		return fmt.Errorf("start metrics HTTP server at %s: %w", parameters.hostMetricsURL, err)
@@ -809,6 +1085,16 @@ func startOrbServices(parameters *orbParameters) error {
 
 	nodeInfoService.Stop()
 
+	witnessReconciler.Stop()
+
+	if casCollector != nil {
+		casCollector.Stop()
+	}
+
+	if casReplicator != nil {
+		casReplicator.Stop()
+	}
+
 	batchWriter.Stop()
 
 	o.Stop()
@@ -824,13 +1110,13 @@ func startOrbServices(parameters *orbParameters) error {
 	return nil
 }
 
-func getProtocolClientProvider(parameters *orbParameters, casClient casapi.Client, casResolver common.CASResolver, opStore common.OperationStore, anchorGraph common.AnchorGraph) (*orbpcp.ClientProvider, error) {
+func getProtocolClientProvider(parameters *orbParameters, casClient casapi.Client, casResolver common.CASResolver, opStore common.OperationStore, anchorGraph common.AnchorGraph, allowedOrigins config.AllowedOrigins) (*orbpcp.ClientProvider, error) {
 	versions := []string{"1.0"}
 
 	sidetreeCfg := config.Sidetree{
-		MethodContext: parameters.methodContext,
-		EnableBase:    parameters.baseEnabled,
-		AnchorOrigins: parameters.allowedOrigins,
+		MethodContext:  parameters.methodContext,
+		EnableBase:     parameters.baseEnabled,
+		AllowedOrigins: allowedOrigins,
 	}
 
 	registry := factoryregistry.New()
@@ -851,6 +1137,30 @@ func getProtocolClientProvider(parameters *orbParameters, casClient casapi.Clien
 	return pcp, nil
 }
 
+// newIPFSGatewayHTTPClient returns the HTTP client that's used to read content from the IPFS gateway. Unlike the
+// shared HTTP client, this client verifies the gateway's TLS certificate since the gateway is typically a
+// third-party service. (Request timeouts are applied by the gateway reader.)
+func newIPFSGatewayHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+		},
+	}
+}
+
+// newIPFSClient returns an IPFS client that's used to write CAS content to the IPFS node.
+func newIPFSClient(parameters *orbParameters) *ipfscas.Client {
+	return ipfscas.NewWithConfig(
+		ipfscas.Config{
+			URL:     parameters.ipfsURL,
+			Timeout: parameters.ipfsTimeout,
+			Pin:     parameters.ipfsPinEnabled,
+			MFSDir:  parameters.ipfsMFSDir,
+		},
+		defaultCasCacheSize, metrics.Get(), extendedcasclient.WithCIDVersion(parameters.cidVersion))
+}
+
 func createActivityPubStore(parameters *orbParameters, serviceEndpoint string) (activitypubspi.Store, error) {
 	var apStore activitypubspi.Store
 
@@ -1070,6 +1380,35 @@ func mustParseURL(basePath, relativePath string) *url.URL {
 	return u
 }
 
+// newPubSub returns a Kafka publisher/subscriber if the MQ URL has the kafka:// scheme, an AMQP publisher/subscriber
+// for any other MQ URL, or an in-memory publisher/subscriber if no MQ URL is specified.
+func newPubSub(parameters *orbParameters) pubSub {
+	switch {
+	case strings.HasPrefix(parameters.mqURL, kafka.URIScheme):
+		return kafka.New(kafka.Config{
+			URI: parameters.mqURL,
+		})
+	case parameters.mqURL != "":
+		return amqp.New(amqp.Config{
+			URI:                        parameters.mqURL,
+			MaxConnectionSubscriptions: parameters.mqMaxConnectionSubscriptions,
+		})
+	default:
+		return mempubsub.New(mempubsub.DefaultConfig())
+	}
+}
+
+// publishActorUpdate notifies followers and witnesses if the ActivityPub service (actor), e.g. its public key,
+// has changed since the last time the server was started.
+func publishActorUpdate(apService *apservice.Service, apServiceIRI *url.URL, publicKey *vocab.PublicKeyType) error {
+	actor, err := aphandler.NewServiceActor(apServiceIRI, publicKey)
+	if err != nil {
+		return fmt.Errorf("create actor: %w", err)
+	}
+
+	return apService.UpdateActor(actor)
+}
+
 func getActivityPubPublicKey(pubKey []byte, apServiceIRI, apServicePublicKeyIRI *url.URL) (*vocab.PublicKeyType, error) {
 	pubDerKey, err := x509.MarshalPKIXPublicKey(ed25519.PublicKey(pubKey))
 	if err != nil {
@@ -5,6 +5,7 @@
 module github.com/trustbloc/orb
 
 require (
+	github.com/Shopify/sarama v1.29.0
 	github.com/ThreeDotsLabs/watermill v1.2.0-rc.4
 	github.com/ThreeDotsLabs/watermill-amqp v1.1.1
 	github.com/ThreeDotsLabs/watermill-http v1.1.3
@@ -24,6 +25,8 @@ require (
 	github.com/igor-pavlenko/httpsignatures-go v0.0.21
 	github.com/ipfs/go-cid v0.0.7
 	github.com/ipfs/go-ipfs-api v0.2.0
+	github.com/johannesboyne/gofakes3 v0.0.0-20210415062230-4b6b67a85d38
+	github.com/minio/minio-go/v7 v7.0.12
 	github.com/mr-tron/base58 v1.2.0
 	github.com/multiformats/go-multibase v0.0.3
 	github.com/multiformats/go-multihash v0.0.14
@@ -31,7 +34,7 @@ require (
 	github.com/piprate/json-gold v0.4.1-0.20210813112359-33b90c4ca86c
 	github.com/prometheus/client_golang v1.11.0
 	github.com/rs/cors v1.7.0
-	github.com/sirupsen/logrus v1.7.0
+	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.0
 	github.com/trustbloc/edge-core v0.1.7-0.20210812092729-6c61997fa9dd
 	github.com/trustbloc/sidetree-core-go v0.6.1-0.20210813104923-05c0f29c66ae
@@ -0,0 +1,218 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package actorauth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/activitypub/blocklist"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+var logger = log.New("actor-auth")
+
+// RequestType is the type of request that is authorized.
+type RequestType string
+
+const (
+	// Follow is the type for 'Follow' requests.
+	Follow RequestType = "follow"
+	// InviteWitness is the type for 'InviteWitness' requests.
+	InviteWitness RequestType = "invitewitness"
+)
+
+const defaultCacheSize = 10
+
+type ledgerTypeResolver interface {
+	HasSupportedLedgerType(domain string) (bool, error)
+}
+
+type gCache interface {
+	Get(key interface{}) (interface{}, error)
+	Remove(key interface{}) bool
+}
+
+// Authorizer decides whether or not to accept a 'Follow' or 'InviteWitness' request according to a policy.
+// The policy is kept in the config store so that it may be changed at runtime. The default policy (typically
+// provided by startup parameters) is used if no policy has been saved to the config store.
+type Authorizer struct {
+	requestType   RequestType
+	configStore   storage.Store
+	defaultPolicy *Policy
+	wfClient      ledgerTypeResolver
+	cache         gCache
+	cacheExpiry   time.Duration
+}
+
+// New returns a new Authorizer for the given request type.
+func New(requestType RequestType, configStore storage.Store, defaultPolicy *Policy, wfClient ledgerTypeResolver,
+	cacheExpiry time.Duration) *Authorizer {
+	if defaultPolicy == nil {
+		defaultPolicy = &Policy{}
+	}
+
+	a := &Authorizer{
+		requestType:   requestType,
+		configStore:   configStore,
+		defaultPolicy: defaultPolicy,
+		wfClient:      wfClient,
+		cacheExpiry:   cacheExpiry,
+	}
+
+	a.cache = gcache.New(defaultCacheSize).ARC().LoaderExpireFunc(a.loadPolicy).Build()
+
+	return a
+}
+
+// AuthorizeActor returns true if the request (activity) from the given actor satisfies the policy.
+func (a *Authorizer) AuthorizeActor(actor *vocab.ActorType, activity *vocab.ActivityType) (bool, error) {
+	policy, err := a.Policy()
+	if err != nil {
+		return false, orberrors.NewTransient(err)
+	}
+
+	if policy.RejectAll {
+		logger.Infof("[%s] Rejecting request from [%s] since all requests are rejected", a.requestType, actor.ID())
+
+		return false, nil
+	}
+
+	if len(policy.Actors) > 0 && !matchesAny(policy.Actors, actor) {
+		logger.Infof("[%s] Rejecting request from [%s] since the actor is not in the list of allowed actors",
+			a.requestType, actor.ID())
+
+		return false, nil
+	}
+
+	if len(policy.Tokens) > 0 && !containsToken(policy.Tokens, activity.InvitationToken()) {
+		logger.Infof("[%s] Rejecting request from [%s] since the activity does not contain a valid invitation token",
+			a.requestType, actor.ID())
+
+		return false, nil
+	}
+
+	if policy.RequireVCT {
+		return a.hasVCT(actor)
+	}
+
+	return true, nil
+}
+
+func (a *Authorizer) hasVCT(actor *vocab.ActorType) (bool, error) {
+	domain := fmt.Sprintf("%s://%s", actor.ID().URL().Scheme, actor.ID().URL().Host)
+
+	hasLog, err := a.wfClient.HasSupportedLedgerType(domain)
+	if err != nil {
+		return false, orberrors.NewTransient(fmt.Errorf("resolve ledger type for [%s]: %w", domain, err))
+	}
+
+	if !hasLog {
+		logger.Infof("[%s] Rejecting request from [%s] since domain [%s] does not advertise a VCT ledger",
+			a.requestType, actor.ID(), domain)
+	}
+
+	return hasLog, nil
+}
+
+// Policy returns the current policy.
+func (a *Authorizer) Policy() (*Policy, error) {
+	value, err := a.cache.Get(a.key())
+	if err != nil {
+		return nil, fmt.Errorf("retrieve %s auth policy from cache: %w", a.requestType, err)
+	}
+
+	policy, ok := value.(*Policy)
+	if !ok {
+		return nil, fmt.Errorf("unexpected interface '%T' for %s auth policy in cache", value, a.requestType)
+	}
+
+	return policy, nil
+}
+
+// SetPolicy validates the given policy and saves it to the config store.
+func (a *Authorizer) SetPolicy(policy *Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	policyBytes, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("marshal %s auth policy: %w", a.requestType, err)
+	}
+
+	err = a.configStore.Put(a.key(), policyBytes)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store %s auth policy: %w", a.requestType, err))
+	}
+
+	a.cache.Remove(a.key())
+
+	logger.Infof("[%s] Updated auth policy: %s", a.requestType, policy)
+
+	return nil
+}
+
+func (a *Authorizer) key() string {
+	return string(a.requestType) + "-auth-policy"
+}
+
+func (a *Authorizer) loadPolicy(key interface{}) (interface{}, *time.Duration, error) {
+	policyBytes, err := a.configStore.Get(key.(string))
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			logger.Debugf("[%s] Auth policy not found in config store. Using default policy.", a.requestType)
+
+			return a.defaultPolicy, &a.cacheExpiry, nil
+		}
+
+		return nil, nil, err
+	}
+
+	policy := &Policy{}
+
+	err = json.Unmarshal(policyBytes, policy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unmarshal %s auth policy: %w", a.requestType, err)
+	}
+
+	logger.Debugf("[%s] Loaded auth policy from store: %s", a.requestType, policy)
+
+	return policy, &a.cacheExpiry, nil
+}
+
+func matchesAny(values []string, actor *vocab.ActorType) bool {
+	for _, value := range values {
+		if blocklist.Matches(value, actor.ID().URL()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsToken(tokens []string, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,186 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package actorauth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mock"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+)
+
+const (
+	actor1 = "https://orb.domain1.com/services/orb"
+	actor2 = "https://orb.domain2.com/services/orb"
+)
+
+func TestAuthorizer_AuthorizeActor(t *testing.T) {
+	service1 := vocab.NewService(testutil.MustParseURL(actor1))
+	service2 := vocab.NewService(testutil.MustParseURL(actor2))
+
+	follow := vocab.NewFollowActivity(vocab.NewObjectProperty(vocab.WithIRI(testutil.MustParseURL(actor2))))
+	followWithToken := vocab.NewFollowActivity(vocab.NewObjectProperty(vocab.WithIRI(testutil.MustParseURL(actor2))),
+		vocab.WithInvitationToken("token1"))
+
+	t.Run("Default policy - accept all", func(t *testing.T) {
+		a := New(Follow, newConfigStore(t), nil, &mockWFClient{}, time.Minute)
+
+		accept, err := a.AuthorizeActor(service1, follow)
+		require.NoError(t, err)
+		require.True(t, accept)
+	})
+
+	t.Run("Reject all", func(t *testing.T) {
+		a := New(Follow, newConfigStore(t), &Policy{RejectAll: true}, &mockWFClient{}, time.Minute)
+
+		accept, err := a.AuthorizeActor(service1, follow)
+		require.NoError(t, err)
+		require.False(t, accept)
+	})
+
+	t.Run("Actors", func(t *testing.T) {
+		a := New(Follow, newConfigStore(t), &Policy{Actors: []string{"*.domain1.com"}}, &mockWFClient{}, time.Minute)
+
+		accept, err := a.AuthorizeActor(service1, follow)
+		require.NoError(t, err)
+		require.True(t, accept)
+
+		accept, err = a.AuthorizeActor(service2, follow)
+		require.NoError(t, err)
+		require.False(t, accept)
+	})
+
+	t.Run("Tokens", func(t *testing.T) {
+		a := New(Follow, newConfigStore(t), &Policy{Tokens: []string{"token0", "token1"}}, &mockWFClient{}, time.Minute)
+
+		accept, err := a.AuthorizeActor(service1, followWithToken)
+		require.NoError(t, err)
+		require.True(t, accept)
+
+		accept, err = a.AuthorizeActor(service1, follow)
+		require.NoError(t, err)
+		require.False(t, accept)
+	})
+
+	t.Run("VCT", func(t *testing.T) {
+		wfClient := &mockWFClient{hasVCT: true}
+
+		a := New(InviteWitness, newConfigStore(t), &Policy{RequireVCT: true}, wfClient, time.Minute)
+
+		accept, err := a.AuthorizeActor(service1, follow)
+		require.NoError(t, err)
+		require.True(t, accept)
+		require.Equal(t, "https://orb.domain1.com", wfClient.domain)
+
+		wfClient.hasVCT = false
+
+		accept, err = a.AuthorizeActor(service1, follow)
+		require.NoError(t, err)
+		require.False(t, accept)
+
+		wfClient.err = errors.New("injected webfinger error")
+
+		_, err = a.AuthorizeActor(service1, follow)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), wfClient.err.Error())
+	})
+
+	t.Run("Policy store error", func(t *testing.T) {
+		errExpected := errors.New("injected get error")
+
+		a := New(Follow, &mock.Store{ErrGet: errExpected}, nil, &mockWFClient{}, time.Minute)
+
+		_, err := a.AuthorizeActor(service1, follow)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestAuthorizer_SetPolicy(t *testing.T) {
+	service1 := vocab.NewService(testutil.MustParseURL(actor1))
+	follow := vocab.NewFollowActivity(vocab.NewObjectProperty(vocab.WithIRI(testutil.MustParseURL(actor2))))
+
+	t.Run("Success", func(t *testing.T) {
+		configStore := newConfigStore(t)
+
+		a := New(Follow, configStore, &Policy{RejectAll: true}, &mockWFClient{}, time.Minute)
+
+		accept, err := a.AuthorizeActor(service1, follow)
+		require.NoError(t, err)
+		require.False(t, accept)
+
+		require.NoError(t, a.SetPolicy(&Policy{Actors: []string{actor1}}))
+
+		policy, err := a.Policy()
+		require.NoError(t, err)
+		require.Equal(t, []string{actor1}, policy.Actors)
+
+		accept, err = a.AuthorizeActor(service1, follow)
+		require.NoError(t, err)
+		require.True(t, accept)
+
+		// The policy for other request types is not affected.
+		policy, err = New(InviteWitness, configStore, nil, &mockWFClient{}, time.Minute).Policy()
+		require.NoError(t, err)
+		require.Empty(t, policy.Actors)
+	})
+
+	t.Run("Invalid policy", func(t *testing.T) {
+		a := New(Follow, newConfigStore(t), nil, &mockWFClient{}, time.Minute)
+
+		err := a.SetPolicy(&Policy{Actors: []string{"https://*.domain1.com"}})
+		require.True(t, errors.Is(err, ErrInvalidPolicy))
+	})
+
+	t.Run("Store error", func(t *testing.T) {
+		errExpected := errors.New("injected put error")
+
+		a := New(Follow, &mock.Store{ErrPut: errExpected}, nil, &mockWFClient{}, time.Minute)
+
+		err := a.SetPolicy(&Policy{})
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Invalid stored policy", func(t *testing.T) {
+		configStore := newConfigStore(t)
+		require.NoError(t, configStore.Put("follow-auth-policy", []byte("{")))
+
+		_, err := New(Follow, configStore, nil, &mockWFClient{}, time.Minute).Policy()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal follow auth policy")
+	})
+}
+
+func newConfigStore(t *testing.T) storage.Store {
+	t.Helper()
+
+	s, err := mem.NewProvider().OpenStore("orb-config")
+	require.NoError(t, err)
+
+	return s
+}
+
+type mockWFClient struct {
+	hasVCT bool
+	err    error
+	domain string
+}
+
+func (m *mockWFClient) HasSupportedLedgerType(domain string) (bool, error) {
+	m.domain = domain
+
+	return m.hasVCT, m.err
+}
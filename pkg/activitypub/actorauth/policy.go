@@ -0,0 +1,139 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package actorauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/orb/pkg/activitypub/blocklist"
+)
+
+const (
+	ruleAcceptAll = "accept-all"
+	ruleRejectAll = "reject-all"
+	ruleVCT       = "vct"
+	ruleActor     = "actor"
+	ruleToken     = "token"
+
+	ruleSeparator = "="
+
+	// MaskedToken replaces each invitation token in a masked policy.
+	MaskedToken = "********"
+)
+
+// ErrInvalidPolicy is returned when an authorization policy is invalid.
+var ErrInvalidPolicy = errors.New("invalid actor authorization policy")
+
+// Policy contains the rules that a 'Follow' or 'InviteWitness' request must satisfy in order to be accepted.
+// All of the specified rules must be satisfied. An empty policy accepts all requests.
+type Policy struct {
+	// RejectAll indicates that all requests are rejected. The other rules are ignored if set.
+	RejectAll bool `json:"rejectAll,omitempty"`
+
+	// Actors, if not empty, contains the actor IRIs, domains (e.g. orb.domain1.com) and domain wildcards
+	// (e.g. *.domain1.com) that are allowed to make requests. The format is the same as for blocklist entries.
+	Actors []string `json:"actors,omitempty"`
+
+	// Tokens, if not empty, contains the pre-shared invitation tokens, one of which must be included in the
+	// 'invitationToken' field of the activity. The tokens are masked when the policy is retrieved.
+	Tokens []string `json:"tokens,omitempty"`
+
+	// RequireVCT indicates that the actor's domain must advertise a supported VCT ledger type in WebFinger.
+	RequireVCT bool `json:"requireVCT,omitempty"`
+}
+
+// Validate returns ErrInvalidPolicy if the policy is invalid.
+func (p *Policy) Validate() error {
+	for _, actor := range p.Actors {
+		if err := blocklist.ValidateEntry(actor); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidPolicy, err)
+		}
+	}
+
+	for _, token := range p.Tokens {
+		if token == "" {
+			return fmt.Errorf("%w: empty token", ErrInvalidPolicy)
+		}
+
+		if token == MaskedToken {
+			return fmt.Errorf("%w: masked token", ErrInvalidPolicy)
+		}
+	}
+
+	return nil
+}
+
+// Masked returns a copy of the policy in which each token is replaced with MaskedToken.
+func (p *Policy) Masked() *Policy {
+	masked := *p
+
+	if len(p.Tokens) > 0 {
+		masked.Tokens = make([]string, len(p.Tokens))
+
+		for i := range p.Tokens {
+			masked.Tokens[i] = MaskedToken
+		}
+	}
+
+	return &masked
+}
+
+// String returns the masked policy as JSON so that the tokens aren't logged.
+func (p *Policy) String() string {
+	policyBytes, err := json.Marshal(p.Masked())
+	if err != nil {
+		return err.Error()
+	}
+
+	return string(policyBytes)
+}
+
+// ParsePolicy parses the given rules into a policy. Each rule has one of the following formats:
+// - accept-all: accept all requests (the default if no rules are specified)
+// - reject-all: reject all requests
+// - vct: the actor's domain must advertise a supported VCT ledger type
+// - actor=<actor IRI|domain|*.domain>: the actor must match one of the specified 'actor' rules
+// - token=<token>: the activity must contain one of the specified invitation tokens
+func ParsePolicy(rules []string) (*Policy, error) {
+	policy := &Policy{}
+
+	for _, rule := range rules {
+		name, value := parseRule(rule)
+
+		switch name {
+		case ruleAcceptAll:
+		case ruleRejectAll:
+			policy.RejectAll = true
+		case ruleVCT:
+			policy.RequireVCT = true
+		case ruleActor:
+			policy.Actors = append(policy.Actors, value)
+		case ruleToken:
+			policy.Tokens = append(policy.Tokens, value)
+		default:
+			return nil, fmt.Errorf("%w: unsupported rule [%s]", ErrInvalidPolicy, rule)
+		}
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func parseRule(rule string) (string, string) {
+	parts := strings.SplitN(strings.TrimSpace(rule), ruleSeparator, 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
@@ -0,0 +1,75 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package actorauth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		policy, err := ParsePolicy(nil)
+		require.NoError(t, err)
+		require.Equal(t, &Policy{}, policy)
+
+		policy, err = ParsePolicy([]string{"accept-all"})
+		require.NoError(t, err)
+		require.Equal(t, &Policy{}, policy)
+	})
+
+	t.Run("Reject all", func(t *testing.T) {
+		policy, err := ParsePolicy([]string{"reject-all"})
+		require.NoError(t, err)
+		require.True(t, policy.RejectAll)
+	})
+
+	t.Run("Rules", func(t *testing.T) {
+		policy, err := ParsePolicy([]string{
+			"actor=" + actor1, "actor=*.domain2.com", "token=token1", " token=token2=abc", "vct",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{actor1, "*.domain2.com"}, policy.Actors)
+		require.Equal(t, []string{"token1", "token2=abc"}, policy.Tokens)
+		require.True(t, policy.RequireVCT)
+		require.False(t, policy.RejectAll)
+	})
+
+	t.Run("Unsupported rule", func(t *testing.T) {
+		_, err := ParsePolicy([]string{"domain=orb.domain1.com"})
+		require.True(t, errors.Is(err, ErrInvalidPolicy))
+		require.Contains(t, err.Error(), "unsupported rule")
+	})
+
+	t.Run("Invalid actor", func(t *testing.T) {
+		_, err := ParsePolicy([]string{"actor="})
+		require.True(t, errors.Is(err, ErrInvalidPolicy))
+	})
+
+	t.Run("Empty token", func(t *testing.T) {
+		_, err := ParsePolicy([]string{"token"})
+		require.True(t, errors.Is(err, ErrInvalidPolicy))
+		require.Contains(t, err.Error(), "empty token")
+	})
+}
+
+func TestPolicy_Masked(t *testing.T) {
+	p := &Policy{Actors: []string{"orb.domain1.com"}, Tokens: []string{"token1", "token2"}, RequireVCT: true}
+
+	masked := p.Masked()
+	require.Equal(t, []string{MaskedToken, MaskedToken}, masked.Tokens)
+	require.Equal(t, p.Actors, masked.Actors)
+	require.True(t, masked.RequireVCT)
+	require.Equal(t, []string{"token1", "token2"}, p.Tokens)
+
+	require.NotContains(t, p.String(), "token1")
+	require.Contains(t, p.String(), "orb.domain1.com")
+
+	require.Empty(t, (&Policy{}).Masked().Tokens)
+}
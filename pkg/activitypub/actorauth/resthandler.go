@@ -0,0 +1,174 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package actorauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+)
+
+const basePath = "/actorauth/"
+
+const (
+	badRequestResponse          = "Bad Request."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+type authorizer interface {
+	Policy() (*Policy, error)
+	SetPolicy(policy *Policy) error
+}
+
+// PolicyRetriever returns the authorization policy for a request type. The invitation tokens are masked since
+// they may only be set (using PolicyUpdater) and not retrieved.
+type PolicyRetriever struct {
+	authorizer authorizer
+	path       string
+	marshal    func(v interface{}) ([]byte, error)
+}
+
+// NewPolicyRetriever returns a new PolicyRetriever for the given request type.
+func NewPolicyRetriever(requestType RequestType, a authorizer) *PolicyRetriever {
+	return &PolicyRetriever{
+		authorizer: a,
+		path:       basePath + string(requestType),
+		marshal:    json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the PolicyRetriever service.
+func (pr *PolicyRetriever) Path() string {
+	return pr.path
+}
+
+// Method returns the HTTP REST method for the PolicyRetriever service.
+func (pr *PolicyRetriever) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the PolicyRetriever service.
+func (pr *PolicyRetriever) Handler() common.HTTPRequestHandler {
+	return pr.handle
+}
+
+func (pr *PolicyRetriever) handle(w http.ResponseWriter, _ *http.Request) {
+	policy, err := pr.authorizer.Policy()
+	if err != nil {
+		logger.Errorf("[%s] Error retrieving policy: %s", pr.path, err)
+
+		writeResponse(w, pr.path, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	policyBytes, err := pr.marshal(policy.Masked())
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling policy: %s", pr.path, err)
+
+		writeResponse(w, pr.path, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, pr.path, http.StatusOK, policyBytes)
+}
+
+// PolicyUpdater replaces the authorization policy for a request type with the policy in the request body. Since
+// the tokens of a retrieved policy are masked, the request must contain the actual tokens; masked tokens are rejected.
+type PolicyUpdater struct {
+	authorizer authorizer
+	path       string
+}
+
+// NewPolicyUpdater returns a new PolicyUpdater for the given request type.
+func NewPolicyUpdater(requestType RequestType, a authorizer) *PolicyUpdater {
+	return &PolicyUpdater{
+		authorizer: a,
+		path:       basePath + string(requestType),
+	}
+}
+
+// Path returns the HTTP REST endpoint for the PolicyUpdater service.
+func (pu *PolicyUpdater) Path() string {
+	return pu.path
+}
+
+// Method returns the HTTP REST method for the PolicyUpdater service.
+func (pu *PolicyUpdater) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the PolicyUpdater service.
+func (pu *PolicyUpdater) Handler() common.HTTPRequestHandler {
+	return pu.handle
+}
+
+func (pu *PolicyUpdater) handle(w http.ResponseWriter, req *http.Request) {
+	policyBytes, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		logger.Errorf("[%s] Error reading request body: %s", pu.path, err)
+
+		writeResponse(w, pu.path, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	policy := &Policy{}
+
+	err = json.Unmarshal(policyBytes, policy)
+	if err != nil {
+		logger.Infof("[%s] Invalid request: %s", pu.path, err)
+
+		writeResponse(w, pu.path, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	err = pu.authorizer.SetPolicy(policy)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPolicy) {
+			logger.Infof("[%s] Invalid policy: %s", pu.path, err)
+
+			writeResponse(w, pu.path, http.StatusBadRequest, []byte(fmt.Sprintf("%s %s", badRequestResponse, err)))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error updating policy: %s", pu.path, err)
+
+		writeResponse(w, pu.path, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	logger.Infof("[%s] Policy updated on behalf of [%s]", pu.path, auth.TokenIDFromContext(req.Context()))
+
+	writeResponse(w, pu.path, http.StatusOK, nil)
+}
+
+func writeResponse(w http.ResponseWriter, path string, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("[%s] Unable to write response: %s", path, err)
+
+			return
+		}
+
+		logger.Debugf("[%s] Wrote response: %s", path, body)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package actorauth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyRetriever(t *testing.T) {
+	a := New(Follow, newConfigStore(t), &Policy{Actors: []string{actor1}}, &mockWFClient{}, time.Minute)
+
+	r := NewPolicyRetriever(Follow, a)
+	require.Equal(t, "/actorauth/follow", r.Path())
+	require.Equal(t, http.MethodGet, r.Method())
+	require.NotNil(t, r.Handler())
+
+	t.Run("Success", func(t *testing.T) {
+		status, body := handle(t, r.handle, http.MethodGet, r.Path(), nil)
+		require.Equal(t, http.StatusOK, status)
+
+		policy := &Policy{}
+		require.NoError(t, json.Unmarshal(body, policy))
+		require.Equal(t, []string{actor1}, policy.Actors)
+	})
+
+	t.Run("Tokens are masked", func(t *testing.T) {
+		a := New(Follow, newConfigStore(t), &Policy{Tokens: []string{"token1", "token2"}}, &mockWFClient{}, time.Minute)
+
+		status, body := handle(t, NewPolicyRetriever(Follow, a).handle, http.MethodGet, r.Path(), nil)
+		require.Equal(t, http.StatusOK, status)
+		require.NotContains(t, string(body), "token1")
+
+		policy := &Policy{}
+		require.NoError(t, json.Unmarshal(body, policy))
+		require.Equal(t, []string{MaskedToken, MaskedToken}, policy.Tokens)
+
+		p, err := a.Policy()
+		require.NoError(t, err)
+		require.Equal(t, []string{"token1", "token2"}, p.Tokens)
+	})
+
+	t.Run("Policy error", func(t *testing.T) {
+		status, _ := handle(t, NewPolicyRetriever(Follow, &mockAuthorizer{err: errors.New("injected error")}).handle,
+			http.MethodGet, r.Path(), nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		r2 := NewPolicyRetriever(Follow, a)
+		r2.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		status, _ := handle(t, r2.handle, http.MethodGet, r.Path(), nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func TestPolicyUpdater(t *testing.T) {
+	a := New(InviteWitness, newConfigStore(t), nil, &mockWFClient{}, time.Minute)
+
+	u := NewPolicyUpdater(InviteWitness, a)
+	require.Equal(t, "/actorauth/invitewitness", u.Path())
+	require.Equal(t, http.MethodPost, u.Method())
+	require.NotNil(t, u.Handler())
+
+	t.Run("Success", func(t *testing.T) {
+		status, _ := handle(t, u.handle, http.MethodPost, u.Path(), []byte(`{"tokens":["token1"],"requireVCT":true}`))
+		require.Equal(t, http.StatusOK, status)
+
+		policy, err := a.Policy()
+		require.NoError(t, err)
+		require.Equal(t, []string{"token1"}, policy.Tokens)
+		require.True(t, policy.RequireVCT)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		status, _ := handle(t, u.handle, http.MethodPost, u.Path(), []byte(`{`))
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("Invalid policy", func(t *testing.T) {
+		status, body := handle(t, u.handle, http.MethodPost, u.Path(), []byte(`{"tokens":[""]}`))
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, string(body), "empty token")
+	})
+
+	t.Run("Masked token", func(t *testing.T) {
+		status, body := handle(t, u.handle, http.MethodPost, u.Path(),
+			[]byte(`{"tokens":["`+MaskedToken+`"]}`))
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, string(body), "masked token")
+
+		policy, err := a.Policy()
+		require.NoError(t, err)
+		require.Equal(t, []string{"token1"}, policy.Tokens)
+	})
+
+	t.Run("Update error", func(t *testing.T) {
+		status, _ := handle(t, NewPolicyUpdater(InviteWitness, &mockAuthorizer{err: errors.New("injected error")}).handle,
+			http.MethodPost, u.Path(), []byte(`{}`))
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func handle(t *testing.T, h func(w http.ResponseWriter, req *http.Request), method, path string,
+	body []byte) (int, []byte) {
+	t.Helper()
+
+	rw := httptest.NewRecorder()
+
+	h(rw, httptest.NewRequest(method, path, bytes.NewReader(body)))
+
+	result := rw.Result()
+
+	respBytes, err := ioutil.ReadAll(result.Body)
+	require.NoError(t, err)
+	require.NoError(t, result.Body.Close())
+
+	return result.StatusCode, respBytes
+}
+
+type mockAuthorizer struct {
+	err error
+}
+
+func (m *mockAuthorizer) Policy() (*Policy, error) {
+	return nil, m.err
+}
+
+func (m *mockAuthorizer) SetPolicy(*Policy) error {
+	return m.err
+}
@@ -0,0 +1,169 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocklist
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	store "github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/store/storeutil"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+type blocklist interface {
+	Add(values ...string) error
+	Remove(values ...string) error
+	Get() ([]*Entry, error)
+}
+
+type activityStore interface {
+	QueryReferences(refType store.ReferenceType, query *store.Criteria, opts ...store.QueryOpt) (store.ReferenceIterator, error) //nolint:lll
+	DeleteReference(refType store.ReferenceType, objectIRI *url.URL, referenceIRI *url.URL) error
+}
+
+type outbox interface {
+	Post(activity *vocab.ActivityType) (*url.URL, error)
+}
+
+// Blocker adds entries to and removes entries from the blocklist. When an entry is added, all follower, following,
+// witness and witnessing references to the matching actors are removed and a 'Block' activity is sent to each
+// of those actors.
+type Blocker struct {
+	blocklist     blocklist
+	activityStore activityStore
+	outbox        outbox
+	serviceIRI    *url.URL
+}
+
+// NewBlocker returns a new Blocker.
+func NewBlocker(serviceIRI *url.URL, bl blocklist, activityStore activityStore, ob outbox) *Blocker {
+	return &Blocker{
+		blocklist:     bl,
+		activityStore: activityStore,
+		outbox:        ob,
+		serviceIRI:    serviceIRI,
+	}
+}
+
+// Get returns all entries in the blocklist.
+func (b *Blocker) Get() ([]*Entry, error) {
+	return b.blocklist.Get()
+}
+
+// Block adds the given entries to the blocklist, removes all references to the actors that match the entries
+// and sends each of those actors a 'Block' activity.
+func (b *Blocker) Block(values ...string) error {
+	if err := b.blocklist.Add(values...); err != nil {
+		return err
+	}
+
+	actors, err := b.removeReferences(values)
+	if err != nil {
+		return err
+	}
+
+	// Actors that were explicitly blocked are notified even if there were no references to them.
+	for _, value := range values {
+		if IsActorIRI(value) {
+			actors[value] = struct{}{}
+		}
+	}
+
+	for _, actorIRI := range sortedKeys(actors) {
+		b.postBlock(actorIRI)
+	}
+
+	return nil
+}
+
+// Unblock removes the given entries from the blocklist.
+func (b *Blocker) Unblock(values ...string) error {
+	return b.blocklist.Remove(values...)
+}
+
+func (b *Blocker) removeReferences(values []string) (map[string]struct{}, error) {
+	actors := make(map[string]struct{})
+
+	for _, refType := range []store.ReferenceType{store.Follower, store.Following, store.Witness, store.Witnessing} {
+		it, err := b.activityStore.QueryReferences(refType, store.NewCriteria(store.WithObjectIRI(b.serviceIRI)))
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("query %s references: %w", refType, err))
+		}
+
+		refs, err := storeutil.ReadReferences(it, -1)
+		if err != nil {
+			return nil, fmt.Errorf("read %s references: %w", refType, err)
+		}
+
+		for _, ref := range refs {
+			if !matchesAny(values, ref) {
+				continue
+			}
+
+			err = b.activityStore.DeleteReference(refType, b.serviceIRI, ref)
+			if err != nil {
+				return nil, orberrors.NewTransient(fmt.Errorf("delete %s reference to [%s]: %w", refType, ref, err))
+			}
+
+			logger.Infof("[%s] Deleted %s reference to blocked actor [%s]", b.serviceIRI, refType, ref)
+
+			actors[ref.String()] = struct{}{}
+		}
+	}
+
+	return actors, nil
+}
+
+func (b *Blocker) postBlock(actor string) {
+	actorIRI, err := url.Parse(actor)
+	if err != nil {
+		logger.Warnf("[%s] Invalid actor IRI [%s]: %s", b.serviceIRI, actor, err)
+
+		return
+	}
+
+	block := vocab.NewBlockActivity(
+		vocab.NewObjectProperty(vocab.WithIRI(actorIRI)),
+		vocab.WithActor(b.serviceIRI),
+		vocab.WithTo(actorIRI),
+	)
+
+	activityID, err := b.outbox.Post(block)
+	if err != nil {
+		// The actor is already blocked so there's no point in failing the request.
+		logger.Warnf("[%s] Unable to post 'Block' activity to [%s]: %s", b.serviceIRI, actorIRI, err)
+
+		return
+	}
+
+	logger.Infof("[%s] Posted 'Block' activity [%s] to [%s]", b.serviceIRI, activityID, actorIRI)
+}
+
+func matchesAny(values []string, actorIRI *url.URL) bool {
+	for _, value := range values {
+		if Matches(value, actorIRI) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
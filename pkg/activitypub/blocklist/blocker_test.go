@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocklist
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	store "github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+)
+
+const serviceIRI = "https://orb.domain0.com/services/orb"
+
+func TestBlocker_Block(t *testing.T) {
+	service := testutil.MustParseURL(serviceIRI)
+
+	t.Run("Success", func(t *testing.T) {
+		s := memstore.New("")
+
+		require.NoError(t, s.AddReference(store.Follower, service, testutil.MustParseURL(actor2)))
+		require.NoError(t, s.AddReference(store.Witness, service, testutil.MustParseURL(actor2)))
+		require.NoError(t, s.AddReference(store.Witnessing, service, testutil.MustParseURL(actor3)))
+		require.NoError(t, s.AddReference(store.Following, service, testutil.MustParseURL(actor3)))
+
+		ob := mocks.NewOutbox()
+
+		b := NewBlocker(service, newBlocklist(t), s, ob)
+
+		require.NoError(t, b.Block(actor1, "orb.domain2.com"))
+
+		entries, err := b.Get()
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+
+		require.False(t, hasReference(t, s, store.Follower, actor2))
+		require.False(t, hasReference(t, s, store.Witness, actor2))
+		require.True(t, hasReference(t, s, store.Witnessing, actor3))
+		require.True(t, hasReference(t, s, store.Following, actor3))
+
+		activities := ob.Activities()
+		require.Len(t, activities, 2)
+
+		for i, actor := range []string{actor1, actor2} {
+			require.True(t, activities[i].Type().Is(vocab.TypeBlock))
+			require.Equal(t, serviceIRI, activities[i].Actor().String())
+			require.Equal(t, actor, activities[i].Object().IRI().String())
+		}
+
+		require.NoError(t, b.Unblock(actor1, "orb.domain2.com"))
+
+		entries, err = b.Get()
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("Invalid entry", func(t *testing.T) {
+		ob := mocks.NewOutbox()
+
+		err := NewBlocker(service, newBlocklist(t), memstore.New(""), ob).Block("https://*.domain1.com")
+		require.True(t, errors.Is(err, ErrInvalidEntry))
+		require.Empty(t, ob.Activities())
+	})
+
+	t.Run("Outbox error", func(t *testing.T) {
+		ob := mocks.NewOutbox().WithError(errors.New("injected outbox error"))
+
+		require.NoError(t, NewBlocker(service, newBlocklist(t), memstore.New(""), ob).Block(actor1))
+	})
+
+	t.Run("Query references error", func(t *testing.T) {
+		errExpected := errors.New("injected query error")
+
+		s := &mocks.ActivityStore{}
+		s.QueryReferencesReturns(nil, errExpected)
+
+		err := NewBlocker(service, newBlocklist(t), s, mocks.NewOutbox()).Block(actor1)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Delete reference error", func(t *testing.T) {
+		errExpected := errors.New("injected delete error")
+
+		s := &failingDeleteStore{Store: memstore.New(""), err: errExpected}
+
+		require.NoError(t, s.AddReference(store.Follower, service, testutil.MustParseURL(actor1)))
+
+		err := NewBlocker(service, newBlocklist(t), s, mocks.NewOutbox()).Block(actor1)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func newBlocklist(t *testing.T) *Blocklist {
+	t.Helper()
+
+	bl, err := New(mem.NewProvider(), time.Minute)
+	require.NoError(t, err)
+
+	return bl
+}
+
+func hasReference(t *testing.T, s store.Store, refType store.ReferenceType, actor string) bool {
+	t.Helper()
+
+	it, err := s.QueryReferences(refType, store.NewCriteria(store.WithObjectIRI(testutil.MustParseURL(serviceIRI))))
+	require.NoError(t, err)
+
+	for {
+		ref, err := it.Next()
+		if errors.Is(err, store.ErrNotFound) {
+			return false
+		}
+
+		require.NoError(t, err)
+
+		if ref.String() == actor {
+			return true
+		}
+	}
+}
+
+type failingDeleteStore struct {
+	*memstore.Store
+	err error
+}
+
+func (s *failingDeleteStore) DeleteReference(store.ReferenceType, *url.URL, *url.URL) error {
+	return s.err
+}
@@ -0,0 +1,300 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocklist
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	namespace = "blocklist"
+	tagName   = "entry"
+
+	wildcardPrefix = "*."
+)
+
+var logger = log.New("blocklist")
+
+// ErrInvalidEntry is returned when a blocklist entry is neither an actor IRI nor a domain.
+var ErrInvalidEntry = errors.New("invalid blocklist entry")
+
+// Entry is an entry in the blocklist. The value is one of:
+// - An actor IRI, e.g. https://orb.domain1.com/services/orb, which blocks the given actor.
+// - A domain, e.g. orb.domain1.com, which blocks all actors hosted at the given domain.
+// - A domain wildcard, e.g. *.domain1.com, which blocks all actors hosted at any subdomain of the given domain.
+type Entry struct {
+	Value   string    `json:"value"`
+	Created time.Time `json:"created"`
+}
+
+// Blocklist is a persistent list of blocked ActivityPub actors and domains.
+type Blocklist struct {
+	store       storage.Store
+	cacheExpiry time.Duration
+
+	mutex    sync.RWMutex
+	matchers []*matcher
+	expiry   time.Time
+}
+
+// New returns a new blocklist. The entries in the blocklist are cached for the given duration so that changes
+// made by other server instances are picked up after the cache expires.
+func New(provider storage.Provider, cacheExpiry time.Duration) (*Blocklist, error) {
+	store, err := provider.OpenStore(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blocklist store: %w", err)
+	}
+
+	err = provider.SetStoreConfig(namespace, storage.StoreConfiguration{TagNames: []string{tagName}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration: %w", err)
+	}
+
+	return &Blocklist{
+		store:       store,
+		cacheExpiry: cacheExpiry,
+	}, nil
+}
+
+// Add adds the given entries to the blocklist. ErrInvalidEntry is returned if any of the entries is invalid,
+// in which case none of the entries are added.
+func (b *Blocklist) Add(values ...string) error {
+	for _, value := range values {
+		if err := ValidateEntry(value); err != nil {
+			return err
+		}
+	}
+
+	for _, value := range values {
+		entryBytes, err := json.Marshal(&Entry{Value: value, Created: time.Now()})
+		if err != nil {
+			return fmt.Errorf("marshal blocklist entry [%s]: %w", value, err)
+		}
+
+		err = b.store.Put(toKey(value), entryBytes, storage.Tag{Name: tagName})
+		if err != nil {
+			return orberrors.NewTransient(fmt.Errorf("store blocklist entry [%s]: %w", value, err))
+		}
+
+		logger.Infof("Added [%s] to the blocklist", value)
+	}
+
+	b.invalidate()
+
+	return nil
+}
+
+// Remove removes the given entries from the blocklist. No error is returned if an entry is not in the blocklist.
+func (b *Blocklist) Remove(values ...string) error {
+	for _, value := range values {
+		if err := b.store.Delete(toKey(value)); err != nil {
+			return orberrors.NewTransient(fmt.Errorf("delete blocklist entry [%s]: %w", value, err))
+		}
+
+		logger.Infof("Removed [%s] from the blocklist", value)
+	}
+
+	b.invalidate()
+
+	return nil
+}
+
+// Get returns all entries in the blocklist, sorted by value.
+func (b *Blocklist) Get() ([]*Entry, error) {
+	iter, err := b.store.Query(tagName)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("query blocklist: %w", err))
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("Error closing blocklist iterator: %s", errClose)
+		}
+	}()
+
+	var entries []*Entry
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("blocklist iterator: %w", err))
+	}
+
+	for ok {
+		value, e := iter.Value()
+		if e != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("blocklist iterator value: %w", e))
+		}
+
+		entry := &Entry{}
+
+		e = json.Unmarshal(value, entry)
+		if e != nil {
+			return nil, fmt.Errorf("unmarshal blocklist entry: %w", e)
+		}
+
+		entries = append(entries, entry)
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("blocklist iterator: %w", err))
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Value < entries[j].Value
+	})
+
+	return entries, nil
+}
+
+// IsBlocked returns true if the given actor matches any of the entries in the blocklist.
+func (b *Blocklist) IsBlocked(actorIRI *url.URL) (bool, error) {
+	matchers, err := b.getMatchers()
+	if err != nil {
+		return false, err
+	}
+
+	for _, m := range matchers {
+		if m.matches(actorIRI) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Matches returns true if the given actor matches the given blocklist entry.
+func Matches(value string, actorIRI *url.URL) bool {
+	m, err := newMatcher(value)
+	if err != nil {
+		return false
+	}
+
+	return m.matches(actorIRI)
+}
+
+// ValidateEntry returns ErrInvalidEntry if the given value is not an actor IRI, a domain or a domain wildcard.
+func ValidateEntry(value string) error {
+	_, err := newMatcher(value)
+
+	return err
+}
+
+// IsActorIRI returns true if the given (valid) blocklist entry is an actor IRI as opposed to a domain.
+func IsActorIRI(value string) bool {
+	return strings.Contains(value, "://")
+}
+
+func (b *Blocklist) getMatchers() ([]*matcher, error) {
+	b.mutex.RLock()
+
+	if time.Now().Before(b.expiry) {
+		defer b.mutex.RUnlock()
+
+		return b.matchers, nil
+	}
+
+	b.mutex.RUnlock()
+
+	entries, err := b.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	var matchers []*matcher
+
+	for _, entry := range entries {
+		m, e := newMatcher(entry.Value)
+		if e != nil {
+			logger.Warnf("Ignoring invalid blocklist entry [%s]: %s", entry.Value, e)
+
+			continue
+		}
+
+		matchers = append(matchers, m)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.matchers = matchers
+	b.expiry = time.Now().Add(b.cacheExpiry)
+
+	return matchers, nil
+}
+
+func (b *Blocklist) invalidate() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.expiry = time.Time{}
+}
+
+type matcher struct {
+	actorIRI string
+	domain   string
+	wildcard bool
+}
+
+func newMatcher(value string) (*matcher, error) {
+	if IsActorIRI(value) {
+		u, err := url.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w [%s]: %s", ErrInvalidEntry, value, err)
+		}
+
+		if u.Host == "" || strings.Contains(u.Host, "*") {
+			return nil, fmt.Errorf("%w [%s]: actor IRI must have a host without wildcards", ErrInvalidEntry, value)
+		}
+
+		return &matcher{actorIRI: value}, nil
+	}
+
+	domain := strings.ToLower(value)
+
+	wildcard := strings.HasPrefix(domain, wildcardPrefix)
+	if wildcard {
+		domain = strings.TrimPrefix(domain, wildcardPrefix)
+	}
+
+	if domain == "" || strings.ContainsAny(domain, "*/?#@: ") {
+		return nil, fmt.Errorf("%w [%s]: expecting an actor IRI, a domain or a domain wildcard", ErrInvalidEntry, value)
+	}
+
+	return &matcher{domain: domain, wildcard: wildcard}, nil
+}
+
+func (m *matcher) matches(actorIRI *url.URL) bool {
+	if m.actorIRI != "" {
+		return actorIRI.String() == m.actorIRI
+	}
+
+	host := strings.ToLower(actorIRI.Hostname())
+
+	if m.wildcard {
+		return strings.HasSuffix(host, "."+m.domain)
+	}
+
+	return host == m.domain
+}
+
+func toKey(value string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocklist
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mock"
+	"github.com/stretchr/testify/require"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+)
+
+const (
+	actor1 = "https://orb.domain1.com/services/orb"
+	actor2 = "https://orb.domain2.com/services/orb"
+	actor3 = "https://orb.sub.domain3.com/services/orb"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		bl, err := New(mem.NewProvider(), time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, bl)
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		_, err := New(&mock.Provider{ErrOpenStore: errors.New("injected open error")}, time.Minute)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected open error")
+	})
+
+	t.Run("Set store config error", func(t *testing.T) {
+		_, err := New(&mock.Provider{ErrSetStoreConfig: errors.New("injected config error")}, time.Minute)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected config error")
+	})
+}
+
+func TestBlocklist(t *testing.T) {
+	bl, err := New(mem.NewProvider(), time.Minute)
+	require.NoError(t, err)
+
+	blocked, err := bl.IsBlocked(testutil.MustParseURL(actor1))
+	require.NoError(t, err)
+	require.False(t, blocked)
+
+	require.NoError(t, bl.Add(actor1, "*.domain3.com"))
+
+	entries, err := bl.Get()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "*.domain3.com", entries[0].Value)
+	require.Equal(t, actor1, entries[1].Value)
+	require.False(t, entries[1].Created.IsZero())
+
+	for _, tc := range []struct {
+		actor   string
+		blocked bool
+	}{
+		{actor1, true},
+		{actor2, false},
+		{actor3, true},
+		{"https://domain3.com/services/orb", false},
+	} {
+		blocked, err = bl.IsBlocked(testutil.MustParseURL(tc.actor))
+		require.NoError(t, err)
+		require.Equalf(t, tc.blocked, blocked, tc.actor)
+	}
+
+	require.NoError(t, bl.Remove(actor1, "unknown.com"))
+
+	blocked, err = bl.IsBlocked(testutil.MustParseURL(actor1))
+	require.NoError(t, err)
+	require.False(t, blocked)
+
+	t.Run("Invalid entry", func(t *testing.T) {
+		err := bl.Add(actor2, "https://*.domain2.com")
+		require.True(t, errors.Is(err, ErrInvalidEntry))
+
+		entries, err := bl.Get()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+}
+
+func TestBlocklist_StoreErrors(t *testing.T) {
+	errExpected := errors.New("injected storage error")
+
+	t.Run("Put error", func(t *testing.T) {
+		bl, err := New(&mock.Provider{OpenStoreReturn: &mock.Store{ErrPut: errExpected}}, time.Minute)
+		require.NoError(t, err)
+
+		err = bl.Add(actor1)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Delete error", func(t *testing.T) {
+		bl, err := New(&mock.Provider{OpenStoreReturn: &mock.Store{ErrDelete: errExpected}}, time.Minute)
+		require.NoError(t, err)
+
+		err = bl.Remove(actor1)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		bl, err := New(&mock.Provider{OpenStoreReturn: &mock.Store{ErrQuery: errExpected}}, time.Minute)
+		require.NoError(t, err)
+
+		_, err = bl.IsBlocked(testutil.MustParseURL(actor1))
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestMatches(t *testing.T) {
+	for _, tc := range []struct {
+		entry   string
+		actor   string
+		matches bool
+	}{
+		{actor1, actor1, true},
+		{actor1, actor1 + "/other", false},
+		{"orb.domain1.com", actor1, true},
+		{"ORB.Domain1.com", actor1, true},
+		{"domain1.com", actor1, false},
+		{"*.domain1.com", actor1, true},
+		{"*.domain1.com", "https://domain1.com/services/orb", false},
+		{"*.sub.domain3.com", actor3, true},
+		{"*.orb.sub.domain3.com", actor3, false},
+		{"*.domain3.com", actor3, true},
+		{"*.domain1.com", "https://orb.otherdomain1.com/services/orb", false},
+		{"orb.domain1.com", "https://orb.domain1.com:8443/services/orb", true},
+		{"", actor1, false},
+		{"*.", actor1, false},
+		{"orb.*.com", actor1, false},
+		{"orb.domain1.com/services", actor1, false},
+		{"https:///services/orb", actor1, false},
+	} {
+		require.Equalf(t, tc.matches, Matches(tc.entry, testutil.MustParseURL(tc.actor)),
+			"entry [%s], actor [%s]", tc.entry, tc.actor)
+	}
+}
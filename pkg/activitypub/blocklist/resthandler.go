@@ -0,0 +1,198 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocklist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+)
+
+const endpoint = "/blocklist"
+
+const (
+	badRequestResponse          = "Bad Request."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+type blocker interface {
+	Get() ([]*Entry, error)
+	Block(values ...string) error
+	Unblock(values ...string) error
+}
+
+// UpdateRequest is the request body of the blocklist update endpoint.
+type UpdateRequest struct {
+	// Add contains the actor IRIs, domains and domain wildcards to add to the blocklist.
+	Add []string `json:"add,omitempty"`
+	// Remove contains the entries to remove from the blocklist.
+	Remove []string `json:"remove,omitempty"`
+}
+
+// Retriever returns the entries in the blocklist.
+type Retriever struct {
+	blocker blocker
+	marshal func(v interface{}) ([]byte, error)
+}
+
+// NewRetriever returns a new blocklist Retriever.
+func NewRetriever(b blocker) *Retriever {
+	return &Retriever{
+		blocker: b,
+		marshal: json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the Retriever service.
+func (r *Retriever) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the Retriever service.
+func (r *Retriever) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the Retriever service.
+func (r *Retriever) Handler() common.HTTPRequestHandler {
+	return r.handle
+}
+
+func (r *Retriever) handle(w http.ResponseWriter, _ *http.Request) {
+	entries, err := r.blocker.Get()
+	if err != nil {
+		logger.Errorf("[%s] Error retrieving blocklist: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	if entries == nil {
+		entries = []*Entry{}
+	}
+
+	entriesBytes, err := r.marshal(entries)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling blocklist: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, entriesBytes)
+}
+
+// Updater adds entries to and removes entries from the blocklist.
+type Updater struct {
+	blocker blocker
+}
+
+// NewUpdater returns a new blocklist Updater.
+func NewUpdater(b blocker) *Updater {
+	return &Updater{
+		blocker: b,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the Updater service.
+func (u *Updater) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the Updater service.
+func (u *Updater) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the Updater service.
+func (u *Updater) Handler() common.HTTPRequestHandler {
+	return u.handle
+}
+
+func (u *Updater) handle(w http.ResponseWriter, req *http.Request) {
+	reqBytes, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		logger.Errorf("[%s] Error reading request body: %s", endpoint, err)
+
+		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	updateReq := &UpdateRequest{}
+
+	err = json.Unmarshal(reqBytes, updateReq)
+	if err != nil {
+		logger.Infof("[%s] Invalid request: %s", endpoint, err)
+
+		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	err = u.update(updateReq)
+	if err != nil {
+		if errors.Is(err, ErrInvalidEntry) {
+			logger.Infof("[%s] Invalid request: %s", endpoint, err)
+
+			writeResponse(w, http.StatusBadRequest, []byte(fmt.Sprintf("%s %s", badRequestResponse, err)))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error updating blocklist: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	logger.Infof("[%s] Blocklist updated on behalf of [%s] - Added: %s, Removed: %s",
+		endpoint, auth.TokenIDFromContext(req.Context()), updateReq.Add, updateReq.Remove)
+
+	writeResponse(w, http.StatusOK, nil)
+}
+
+func (u *Updater) update(req *UpdateRequest) error {
+	if len(req.Remove) > 0 {
+		if err := u.blocker.Unblock(req.Remove...); err != nil {
+			return err
+		}
+	}
+
+	if len(req.Add) > 0 {
+		if err := u.blocker.Block(req.Add...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("[%s] Unable to write response: %s", endpoint, err)
+
+			return
+		}
+
+		logger.Debugf("[%s] Wrote response: %s", endpoint, body)
+	}
+}
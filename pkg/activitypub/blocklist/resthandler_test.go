@@ -0,0 +1,147 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blocklist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+)
+
+func TestRetriever(t *testing.T) {
+	b := NewBlocker(testutil.MustParseURL(serviceIRI), newBlocklist(t), memstore.New(""), mocks.NewOutbox())
+
+	r := NewRetriever(b)
+	require.Equal(t, endpoint, r.Path())
+	require.Equal(t, http.MethodGet, r.Method())
+	require.NotNil(t, r.Handler())
+
+	t.Run("Empty", func(t *testing.T) {
+		status, body := handle(t, r.handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "[]", string(body))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		require.NoError(t, b.Block(actor1))
+
+		status, body := handle(t, r.handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		var entries []*Entry
+		require.NoError(t, json.Unmarshal(body, &entries))
+		require.Len(t, entries, 1)
+		require.Equal(t, actor1, entries[0].Value)
+	})
+
+	t.Run("Blocklist error", func(t *testing.T) {
+		status, _ := handle(t, NewRetriever(&mockBlocker{err: errors.New("injected error")}).handle,
+			http.MethodGet, nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		r2 := NewRetriever(b)
+		r2.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		status, _ := handle(t, r2.handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func TestUpdater(t *testing.T) {
+	b := NewBlocker(testutil.MustParseURL(serviceIRI), newBlocklist(t), memstore.New(""), mocks.NewOutbox())
+
+	u := NewUpdater(b)
+	require.Equal(t, endpoint, u.Path())
+	require.Equal(t, http.MethodPost, u.Method())
+	require.NotNil(t, u.Handler())
+
+	t.Run("Add", func(t *testing.T) {
+		status, _ := handle(t, u.handle, http.MethodPost, []byte(`{"add":["`+actor1+`","*.domain2.com"]}`))
+		require.Equal(t, http.StatusOK, status)
+
+		entries, err := b.Get()
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		status, _ := handle(t, u.handle, http.MethodPost, []byte(`{"remove":["*.domain2.com"]}`))
+		require.Equal(t, http.StatusOK, status)
+
+		entries, err := b.Get()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, actor1, entries[0].Value)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		status, _ := handle(t, u.handle, http.MethodPost, []byte(`{`))
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("Invalid entry", func(t *testing.T) {
+		status, body := handle(t, u.handle, http.MethodPost, []byte(`{"add":["https://*.domain2.com"]}`))
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, string(body), ErrInvalidEntry.Error())
+	})
+
+	t.Run("Block error", func(t *testing.T) {
+		status, _ := handle(t, NewUpdater(&mockBlocker{err: errors.New("injected error")}).handle,
+			http.MethodPost, []byte(`{"add":["`+actor1+`"]}`))
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+
+	t.Run("Unblock error", func(t *testing.T) {
+		status, _ := handle(t, NewUpdater(&mockBlocker{err: errors.New("injected error")}).handle,
+			http.MethodPost, []byte(`{"remove":["`+actor1+`"]}`))
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func handle(t *testing.T, h func(w http.ResponseWriter, req *http.Request), method string, body []byte) (int, []byte) {
+	t.Helper()
+
+	rw := httptest.NewRecorder()
+
+	h(rw, httptest.NewRequest(method, endpoint, bytes.NewReader(body)))
+
+	result := rw.Result()
+
+	respBytes, err := ioutil.ReadAll(result.Body)
+	require.NoError(t, err)
+	require.NoError(t, result.Body.Close())
+
+	return result.StatusCode, respBytes
+}
+
+type mockBlocker struct {
+	err error
+}
+
+func (m *mockBlocker) Get() ([]*Entry, error) {
+	return nil, m.err
+}
+
+func (m *mockBlocker) Block(...string) error {
+	return m.err
+}
+
+func (m *mockBlocker) Unblock(...string) error {
+	return m.err
+}
@@ -80,13 +80,13 @@ func New(cfg Config, t httpTransport) *Client {
 	c.actorCache = gcache.New(cacheSize).ARC().
 		Expiration(cacheExpiration).
 		LoaderFunc(func(i interface{}) (interface{}, error) {
-			return c.getActor(i.(*url.URL))
+			return c.getActor(i.(string))
 		}).Build()
 
 	c.publicKeyCache = gcache.New(cacheSize).ARC().
 		Expiration(cacheExpiration).
 		LoaderFunc(func(i interface{}) (interface{}, error) {
-			return c.getPublicKey(i.(*url.URL))
+			return c.getPublicKey(i.(string))
 		}).Build()
 
 	return c
@@ -95,7 +95,7 @@ func New(cfg Config, t httpTransport) *Client {
 // GetActor retrieves the actor at the given IRI.
 //nolint:interfacer
 func (c *Client) GetActor(actorIRI *url.URL) (*vocab.ActorType, error) {
-	result, err := c.actorCache.Get(actorIRI)
+	result, err := c.actorCache.Get(actorIRI.String())
 	if err != nil {
 		logger.Debugf("Got error retrieving actor from cache for IRI [%s]: %s", actorIRI, err)
 
@@ -105,7 +105,48 @@ func (c *Client) GetActor(actorIRI *url.URL) (*vocab.ActorType, error) {
 	return result.(*vocab.ActorType), nil
 }
 
-func (c *Client) getActor(actorIRI *url.URL) (*vocab.ActorType, error) {
+// InvalidateActor removes the actor at the given IRI, along with the actor's public key, from the cache
+// so that the latest version of the actor is retrieved on the next request.
+//nolint:interfacer
+func (c *Client) InvalidateActor(actorIRI *url.URL) {
+	result, err := c.actorCache.GetIFPresent(actorIRI.String())
+	if err == nil {
+		actor := result.(*vocab.ActorType)
+
+		if actor.PublicKey() != nil && actor.PublicKey().ID != nil {
+			c.publicKeyCache.Remove(actor.PublicKey().ID.String())
+		}
+	}
+
+	c.actorCache.Remove(actorIRI.String())
+
+	logger.Debugf("Invalidated actor [%s] in cache", actorIRI)
+}
+
+// InvalidatePublicKey removes the public key at the given IRI, along with the key's owner, from the cache
+// so that the latest version of the key is retrieved on the next request.
+//nolint:interfacer
+func (c *Client) InvalidatePublicKey(keyIRI *url.URL) {
+	result, err := c.publicKeyCache.GetIFPresent(keyIRI.String())
+	if err == nil {
+		publicKey := result.(*vocab.PublicKeyType)
+
+		if publicKey.Owner != nil {
+			c.actorCache.Remove(publicKey.Owner.String())
+		}
+	}
+
+	c.publicKeyCache.Remove(keyIRI.String())
+
+	logger.Debugf("Invalidated public key [%s] in cache", keyIRI)
+}
+
+func (c *Client) getActor(iri string) (*vocab.ActorType, error) {
+	actorIRI, err := url.Parse(iri)
+	if err != nil {
+		return nil, fmt.Errorf("parse actor IRI [%s]: %w", iri, err)
+	}
+
 	respBytes, err := c.get(actorIRI)
 	if err != nil {
 		return nil, fmt.Errorf("error reading response from %s: %w", actorIRI, err)
@@ -126,7 +167,7 @@ func (c *Client) getActor(actorIRI *url.URL) (*vocab.ActorType, error) {
 // GetPublicKey retrieves the public key at the given IRI.
 //nolint:interfacer
 func (c *Client) GetPublicKey(keyIRI *url.URL) (*vocab.PublicKeyType, error) {
-	result, err := c.publicKeyCache.Get(keyIRI)
+	result, err := c.publicKeyCache.Get(keyIRI.String())
 	if err != nil {
 		logger.Debugf("Got error retrieving public key from cache for IRI [%s]: %s", keyIRI, err)
 
@@ -136,7 +177,12 @@ func (c *Client) GetPublicKey(keyIRI *url.URL) (*vocab.PublicKeyType, error) {
 	return result.(*vocab.PublicKeyType), nil
 }
 
-func (c *Client) getPublicKey(keyIRI *url.URL) (*vocab.PublicKeyType, error) {
+func (c *Client) getPublicKey(iri string) (*vocab.PublicKeyType, error) {
+	keyIRI, err := url.Parse(iri)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key IRI [%s]: %w", iri, err)
+	}
+
 	respBytes, err := c.get(keyIRI)
 	if err != nil {
 		return nil, fmt.Errorf("error reading response from %s: %w", keyIRI, err)
@@ -25,8 +25,6 @@ import (
 	"github.com/trustbloc/orb/pkg/internal/testutil"
 )
 
-//go:generate counterfeiter -o ../mocks/httptransport.gen.go --fake-name HTTPTransport . httpTransport
-
 func TestClient_GetActor(t *testing.T) {
 	actorIRI := testutil.MustParseURL("https://example.com/services/service1")
 
@@ -589,3 +587,131 @@ func TestClient_GetPublicKey(t *testing.T) {
 		require.NoError(t, result.Body.Close())
 	})
 }
+
+func TestClient_InvalidateActor(t *testing.T) {
+	actorIRI := testutil.MustParseURL("https://example.com/services/service1")
+	keyIRI := testutil.NewMockID(actorIRI, "/keys/main-key")
+
+	actorBytes, err := json.Marshal(aptestutil.NewMockService(actorIRI))
+	require.NoError(t, err)
+
+	publicKeyBytes, err := json.Marshal(aptestutil.NewMockPublicKey(actorIRI))
+	require.NoError(t, err)
+
+	rw1 := httptest.NewRecorder()
+
+	_, err = rw1.Write(actorBytes)
+	require.NoError(t, err)
+
+	rw2 := httptest.NewRecorder()
+
+	_, err = rw2.Write(publicKeyBytes)
+	require.NoError(t, err)
+
+	result1 := rw1.Result()
+	result2 := rw2.Result()
+
+	errExpected := errors.New("injected HTTP client error")
+
+	httpClient := &mocks.HTTPTransport{}
+	httpClient.GetReturnsOnCall(0, result1, nil)
+	httpClient.GetReturnsOnCall(1, result2, nil)
+	httpClient.GetReturnsOnCall(2, nil, errExpected)
+	httpClient.GetReturnsOnCall(3, nil, errExpected)
+
+	c := New(Config{}, httpClient)
+	require.NotNil(t, t, c)
+
+	actor, err := c.GetActor(actorIRI)
+	require.NoError(t, err)
+	require.NotNil(t, actor)
+
+	publicKey, err := c.GetPublicKey(keyIRI)
+	require.NoError(t, err)
+	require.NotNil(t, publicKey)
+
+	// The actor and public key should be retrieved from the cache.
+	actor, err = c.GetActor(testutil.MustParseURL(actorIRI.String()))
+	require.NoError(t, err)
+	require.NotNil(t, actor)
+
+	publicKey, err = c.GetPublicKey(testutil.MustParseURL(keyIRI.String()))
+	require.NoError(t, err)
+	require.NotNil(t, publicKey)
+
+	require.Equal(t, 2, httpClient.GetCallCount())
+
+	c.InvalidateActor(actorIRI)
+
+	actor, err = c.GetActor(actorIRI)
+	require.True(t, errors.Is(err, errExpected))
+	require.Nil(t, actor)
+
+	publicKey, err = c.GetPublicKey(keyIRI)
+	require.True(t, errors.Is(err, errExpected))
+	require.Nil(t, publicKey)
+
+	// Invalidating an actor that isn't in the cache should be a no-op.
+	c.InvalidateActor(testutil.MustParseURL("https://example.com/services/service2"))
+
+	require.NoError(t, result1.Body.Close())
+	require.NoError(t, result2.Body.Close())
+}
+
+func TestClient_InvalidatePublicKey(t *testing.T) {
+	actorIRI := testutil.MustParseURL("https://example.com/services/service1")
+	keyIRI := testutil.NewMockID(actorIRI, "/keys/main-key")
+
+	actorBytes, err := json.Marshal(aptestutil.NewMockService(actorIRI))
+	require.NoError(t, err)
+
+	publicKeyBytes, err := json.Marshal(aptestutil.NewMockPublicKey(actorIRI))
+	require.NoError(t, err)
+
+	rw1 := httptest.NewRecorder()
+
+	_, err = rw1.Write(actorBytes)
+	require.NoError(t, err)
+
+	rw2 := httptest.NewRecorder()
+
+	_, err = rw2.Write(publicKeyBytes)
+	require.NoError(t, err)
+
+	result1 := rw1.Result()
+	result2 := rw2.Result()
+
+	errExpected := errors.New("injected HTTP client error")
+
+	httpClient := &mocks.HTTPTransport{}
+	httpClient.GetReturnsOnCall(0, result1, nil)
+	httpClient.GetReturnsOnCall(1, result2, nil)
+	httpClient.GetReturnsOnCall(2, nil, errExpected)
+	httpClient.GetReturnsOnCall(3, nil, errExpected)
+
+	c := New(Config{}, httpClient)
+	require.NotNil(t, t, c)
+
+	_, err = c.GetActor(actorIRI)
+	require.NoError(t, err)
+
+	_, err = c.GetPublicKey(keyIRI)
+	require.NoError(t, err)
+
+	c.InvalidatePublicKey(keyIRI)
+
+	// Both the public key and its owner should have been removed from the cache.
+	publicKey, err := c.GetPublicKey(keyIRI)
+	require.True(t, errors.Is(err, errExpected))
+	require.Nil(t, publicKey)
+
+	actor, err := c.GetActor(actorIRI)
+	require.True(t, errors.Is(err, errExpected))
+	require.Nil(t, actor)
+
+	// Invalidating a public key that isn't in the cache should be a no-op.
+	c.InvalidatePublicKey(testutil.MustParseURL("https://example.com/services/service2/keys/main-key"))
+
+	require.NoError(t, result1.Body.Close())
+	require.NoError(t, result2.Body.Close())
+}
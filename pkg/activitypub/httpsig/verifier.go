@@ -11,7 +11,10 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/bluele/gcache"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	httpsig "github.com/igor-pavlenko/httpsignatures-go"
@@ -19,6 +22,16 @@ import (
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
 )
 
+const (
+	// minKeyRefreshInterval is the minimum interval between refreshes of the same public key. The key ID is taken
+	// from the (unauthenticated) request, so refreshes are limited in order to prevent requests with invalid
+	// signatures from evicting cached keys and causing a fetch from the key owner's server on every request.
+	minKeyRefreshInterval = time.Minute
+
+	// maxKeyRefreshEntries is the maximum number of public keys whose last refresh is tracked.
+	maxKeyRefreshEntries = 10000
+)
+
 type publicKeyRetriever interface {
 	GetPublicKey(keyIRI *url.URL) (*vocab.PublicKeyType, error)
 }
@@ -27,6 +40,8 @@ type actorRetriever interface {
 	publicKeyRetriever
 
 	GetActor(actorIRI *url.URL) (*vocab.ActorType, error)
+	InvalidateActor(actorIRI *url.URL)
+	InvalidatePublicKey(keyIRI *url.URL)
 }
 
 type verifier interface {
@@ -37,6 +52,8 @@ type verifier interface {
 type Verifier struct {
 	actorRetriever actorRetriever
 	verifier       func() verifier
+	keyRefreshes   gcache.Cache
+	mutex          sync.Mutex
 }
 
 // NewVerifier returns a new HTTP signature verifier.
@@ -44,16 +61,21 @@ func NewVerifier(actorRetriever actorRetriever, cr crypto.Crypto, km kms.KeyMana
 	algo := NewVerifierAlgorithm(cr, km, NewKeyResolver(actorRetriever))
 	secretRetriever := &SecretRetriever{}
 
+	return newVerifier(actorRetriever, func() verifier {
+		// Return a new instance for each verification since the HTTP signature
+		// implementation is not thread safe.
+		hs := httpsig.NewHTTPSignatures(secretRetriever)
+		hs.SetSignatureHashAlgorithm(algo)
+
+		return hs
+	})
+}
+
+func newVerifier(actorRetriever actorRetriever, v func() verifier) *Verifier {
 	return &Verifier{
 		actorRetriever: actorRetriever,
-		verifier: func() verifier {
-			// Return a new instance for each verification since the HTTP signature
-			// implementation is not thread safe.
-			hs := httpsig.NewHTTPSignatures(secretRetriever)
-			hs.SetSignatureHashAlgorithm(algo)
-
-			return hs
-		},
+		verifier:       v,
+		keyRefreshes:   gcache.New(maxKeyRefreshEntries).LRU().Expiration(minKeyRefreshInterval).Build(),
 	}
 }
 
@@ -68,13 +90,6 @@ func NewVerifier(actorRetriever actorRetriever, cr crypto.Crypto, km kms.KeyMana
 func (v *Verifier) VerifyRequest(req *http.Request) (bool, *url.URL, error) {
 	logger.Debugf("Verifying request. Headers: %s", req.Header)
 
-	err := v.verifier().Verify(req)
-	if err != nil {
-		logger.Infof("Signature verification failed for request %s: %s", req.URL, err)
-
-		return false, nil, nil
-	}
-
 	keyID := getKeyIDFromSignatureHeader(req)
 	if keyID == "" {
 		logger.Debugf("'keyId' not found in Signature header in request %s", req.URL)
@@ -82,8 +97,6 @@ func (v *Verifier) VerifyRequest(req *http.Request) (bool, *url.URL, error) {
 		return false, nil, nil
 	}
 
-	logger.Debugf("Verifying keyId [%s] from signature header ...", keyID)
-
 	keyIRI, err := url.Parse(keyID)
 	if err != nil {
 		logger.Debugf("invalid public key ID [%s] in request %s: %s", keyID, req.URL, err)
@@ -91,36 +104,102 @@ func (v *Verifier) VerifyRequest(req *http.Request) (bool, *url.URL, error) {
 		return false, nil, nil
 	}
 
+	err = v.verifier().Verify(req)
+	if err != nil {
+		if !v.allowKeyRefresh(keyIRI) {
+			logger.Infof("Signature verification failed for request %s: %s. (Public key [%s] was refreshed "+
+				"recently so it is not refreshed again.)", req.URL, err, keyIRI)
+
+			return false, nil, nil
+		}
+
+		// The actor may have rotated its key, in which case the cached public key is stale. Refresh the
+		// public key (bypassing the cache) and try once more.
+		logger.Debugf("Signature verification failed for request %s using cached public key [%s]: %s. "+
+			"Refreshing the public key and retrying.", req.URL, keyIRI, err)
+
+		v.actorRetriever.InvalidatePublicKey(keyIRI)
+
+		err = v.verifier().Verify(req)
+		if err != nil {
+			logger.Infof("Signature verification failed for request %s: %s", req.URL, err)
+
+			return false, nil, nil
+		}
+	}
+
+	logger.Debugf("Verifying keyId [%s] from signature header ...", keyID)
+
 	publicKey, err := v.actorRetriever.GetPublicKey(keyIRI)
 	if err != nil {
 		return false, nil, fmt.Errorf("get public key [%s]: %w", keyIRI, err)
 	}
 
+	actor, ok, err := v.getKeyOwner(req, publicKey)
+	if err != nil || !ok {
+		return false, nil, err
+	}
+
+	logger.Debugf("Successfully verified signature in header. Actor [%s]", actor.ID())
+
+	return true, actor.ID().URL(), nil
+}
+
+// allowKeyRefresh returns true if the given public key may be refreshed, i.e. if it wasn't refreshed within
+// the last minKeyRefreshInterval. If true is returned then the refresh is recorded.
+func (v *Verifier) allowKeyRefresh(keyIRI *url.URL) bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if _, err := v.keyRefreshes.GetIFPresent(keyIRI.String()); err == nil {
+		return false
+	}
+
+	if err := v.keyRefreshes.Set(keyIRI.String(), struct{}{}); err != nil {
+		// This shouldn't be possible.
+		logger.Warnf("Error recording refresh of public key [%s]: %s", keyIRI, err)
+	}
+
+	return true
+}
+
+// getKeyOwner returns the owner of the given public key and ensures that the public key ID matches the key ID
+// of the owner. Otherwise it could be an attempt to impersonate an actor. If the key IDs don't match then the
+// owner is retrieved once more (bypassing the cache) since the actor may have rotated its key.
+func (v *Verifier) getKeyOwner(req *http.Request, publicKey *vocab.PublicKeyType) (*vocab.ActorType, bool, error) {
 	logger.Debugf("Retrieving actor for public key owner [%s]", publicKey.Owner)
 
-	// Ensure that the public key ID matches the key ID of the specified owner. Otherwise it could
-	// be an attempt to impersonate an actor.
 	actor, err := v.actorRetriever.GetActor(publicKey.Owner.URL())
 	if err != nil {
-		return false, nil, fmt.Errorf("get actor [%s]: %w", publicKey.Owner, err)
+		return nil, false, fmt.Errorf("get actor [%s]: %w", publicKey.Owner, err)
+	}
+
+	if actor.PublicKey() != nil && actor.PublicKey().ID.String() != publicKey.ID.String() {
+		logger.Debugf("Public key [%s] of cached actor [%s] does not match the provided public key ID [%s]. "+
+			"Refreshing the actor.", actor.PublicKey().ID, actor.ID(), publicKey.ID)
+
+		v.actorRetriever.InvalidateActor(publicKey.Owner.URL())
+
+		actor, err = v.actorRetriever.GetActor(publicKey.Owner.URL())
+		if err != nil {
+			return nil, false, fmt.Errorf("get actor [%s]: %w", publicKey.Owner, err)
+		}
 	}
 
 	if actor.PublicKey() == nil {
-		logger.Debugf("nil public key on actor [%s] in request %s: %s", actor.ID(), req.URL)
+		logger.Debugf("nil public key on actor [%s] in request %s", actor.ID(), req.URL)
 
-		return false, nil, nil
+		return nil, false, nil
 	}
 
 	if actor.PublicKey().ID.String() != publicKey.ID.String() {
 		logger.Debugf("public key [%s] of actor [%s] does not match the provided public key ID [%s] in request %s",
 			actor.PublicKey().ID, actor.ID(), publicKey.ID, req.URL)
 
-		return false, nil, nil
+		return nil, false, nil
 	}
 
-	logger.Debugf("Successfully verified signature in header. Actor [%s]", actor.ID())
-
-	return true, actor.ID().URL(), nil
+	return actor, true, nil
 }
 
 func getKeyIDFromSignatureHeader(req *http.Request) string {
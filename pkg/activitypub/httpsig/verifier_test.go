@@ -12,8 +12,10 @@ import (
 	"crypto/rand"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 
 	mockcrypto "github.com/hyperledger/aries-framework-go/pkg/mock/crypto"
@@ -81,10 +83,7 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 		WithActor(aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(publicKey)))
 
 	t.Run("Success", func(t *testing.T) {
-		v := &Verifier{
-			actorRetriever: retriever,
-			verifier:       func() verifier { return &mocks.HTTPSignatureVerifier{} },
-		}
+		v := newVerifier(retriever, func() verifier { return &mocks.HTTPSignatureVerifier{} })
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
 		require.NoError(t, err)
@@ -98,6 +97,79 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 		require.Equal(t, actorIRI.String(), actorID.String())
 	})
 
+	t.Run("Rotated key -> public key refreshed", func(t *testing.T) {
+		hsv := &mocks.HTTPSignatureVerifier{}
+		hsv.VerifyReturnsOnCall(0, errors.New("injected verification error"))
+
+		r := &refreshingRetriever{ActorRetriever: retriever}
+
+		v := newVerifier(r, func() verifier { return hsv })
+
+		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
+		require.NoError(t, err)
+
+		require.NoError(t, signer.SignRequest(publicKey.ID.String(), req))
+
+		ok, actorID, err := v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, actorIRI.String(), actorID.String())
+		require.Equal(t, 2, hsv.VerifyCallCount())
+		require.Equal(t, []string{publicKey.ID.String()}, r.invalidatedKeys)
+	})
+
+	t.Run("Repeated invalid signatures -> public key refreshed at most once", func(t *testing.T) {
+		hsv := &mocks.HTTPSignatureVerifier{}
+		hsv.VerifyReturns(errors.New("injected verification error"))
+
+		r := &refreshingRetriever{ActorRetriever: retriever}
+
+		v := newVerifier(r, func() verifier { return hsv })
+
+		for i := 0; i < 5; i++ {
+			req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
+			require.NoError(t, err)
+
+			require.NoError(t, signer.SignRequest(publicKey.ID.String(), req))
+
+			ok, actorID, err := v.VerifyRequest(req)
+			require.NoError(t, err)
+			require.False(t, ok)
+			require.Nil(t, actorID)
+		}
+
+		require.Equal(t, []string{publicKey.ID.String()}, r.invalidatedKeys)
+		require.Equal(t, 6, hsv.VerifyCallCount())
+	})
+
+	t.Run("Rotated key ID -> actor refreshed", func(t *testing.T) {
+		oldPublicKey := vocab.NewPublicKey(
+			vocab.WithID(testutil.NewMockID(actorIRI, "/keys/old-key")),
+			vocab.WithOwner(actorIRI),
+			vocab.WithPublicKeyPem(string(pubKeyPem)),
+		)
+
+		r := &refreshingRetriever{
+			ActorRetriever: servicemocks.NewActorRetriever().
+				WithPublicKey(publicKey).
+				WithActor(aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(oldPublicKey))),
+			refreshedActor: aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(publicKey)),
+		}
+
+		v := newVerifier(r, func() verifier { return &mocks.HTTPSignatureVerifier{} })
+
+		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
+		require.NoError(t, err)
+
+		require.NoError(t, signer.SignRequest(publicKey.ID.String(), req))
+
+		ok, actorID, err := v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, actorIRI.String(), actorID.String())
+		require.Equal(t, []string{actorIRI.String()}, r.invalidatedActors)
+	})
+
 	t.Run("Failed verification", func(t *testing.T) {
 		cr := &mockcrypto.Crypto{}
 		km := &mockkms.KeyManager{}
@@ -116,10 +188,7 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 	})
 
 	t.Run("Key ID not found in signature header", func(t *testing.T) {
-		v := &Verifier{
-			actorRetriever: retriever,
-			verifier:       func() verifier { return &mocks.HTTPSignatureVerifier{} },
-		}
+		v := newVerifier(retriever, func() verifier { return &mocks.HTTPSignatureVerifier{} })
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
 		require.NoError(t, err)
@@ -133,10 +202,7 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 	})
 
 	t.Run("Invalid key ID", func(t *testing.T) {
-		v := &Verifier{
-			actorRetriever: retriever,
-			verifier:       func() verifier { return &mocks.HTTPSignatureVerifier{} },
-		}
+		v := newVerifier(retriever, func() verifier { return &mocks.HTTPSignatureVerifier{} })
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
 		require.NoError(t, err)
@@ -150,10 +216,7 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 	})
 
 	t.Run("Public key not found -> error", func(t *testing.T) {
-		v := &Verifier{
-			actorRetriever: retriever,
-			verifier:       func() verifier { return &mocks.HTTPSignatureVerifier{} },
-		}
+		v := newVerifier(retriever, func() verifier { return &mocks.HTTPSignatureVerifier{} })
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
 		require.NoError(t, err)
@@ -168,10 +231,10 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 	})
 
 	t.Run("Actor not found -> error", func(t *testing.T) {
-		v := &Verifier{
-			actorRetriever: servicemocks.NewActorRetriever().WithPublicKey(publicKey),
-			verifier:       func() verifier { return &mocks.HTTPSignatureVerifier{} },
-		}
+		v := newVerifier(
+			servicemocks.NewActorRetriever().WithPublicKey(publicKey),
+			func() verifier { return &mocks.HTTPSignatureVerifier{} },
+		)
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
 		require.NoError(t, err)
@@ -186,12 +249,12 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 	})
 
 	t.Run("Actor nil public key -> error", func(t *testing.T) {
-		v := &Verifier{
-			actorRetriever: servicemocks.NewActorRetriever().
+		v := newVerifier(
+			servicemocks.NewActorRetriever().
 				WithPublicKey(publicKey).
 				WithActor(aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(nil))),
-			verifier: func() verifier { return &mocks.HTTPSignatureVerifier{} },
-		}
+			func() verifier { return &mocks.HTTPSignatureVerifier{} },
+		)
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
 		require.NoError(t, err)
@@ -211,12 +274,12 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 			vocab.WithPublicKeyPem(string(pubKeyPem)),
 		)
 
-		v := &Verifier{
-			actorRetriever: servicemocks.NewActorRetriever().
+		v := newVerifier(
+			servicemocks.NewActorRetriever().
 				WithPublicKey(publicKey).
 				WithActor(aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(actorPublicKey))),
-			verifier: func() verifier { return &mocks.HTTPSignatureVerifier{} },
-		}
+			func() verifier { return &mocks.HTTPSignatureVerifier{} },
+		)
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
 		require.NoError(t, err)
@@ -230,6 +293,32 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 	})
 }
 
+// refreshingRetriever records invalidations and, once the actor has been invalidated, returns the refreshed
+// actor (if set).
+type refreshingRetriever struct {
+	*servicemocks.ActorRetriever
+
+	refreshedActor    *vocab.ActorType
+	invalidatedKeys   []string
+	invalidatedActors []string
+}
+
+func (r *refreshingRetriever) GetActor(actorIRI *url.URL) (*vocab.ActorType, error) {
+	if r.refreshedActor != nil && len(r.invalidatedActors) > 0 {
+		return r.refreshedActor, nil
+	}
+
+	return r.ActorRetriever.GetActor(actorIRI)
+}
+
+func (r *refreshingRetriever) InvalidateActor(actorIRI *url.URL) {
+	r.invalidatedActors = append(r.invalidatedActors, actorIRI.String())
+}
+
+func (r *refreshingRetriever) InvalidatePublicKey(keyIRI *url.URL) {
+	r.invalidatedKeys = append(r.invalidatedKeys, keyIRI.String())
+}
+
 func getPublicKeyPem(pubKey interface{}) ([]byte, error) {
 	keyBytes, err := x509.MarshalPKIXPublicKey(pubKey)
 	if err != nil {
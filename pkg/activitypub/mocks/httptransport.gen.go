@@ -10,11 +10,11 @@ import (
 )
 
 type HTTPTransport struct {
-	GetStub        func(ctx context.Context, req *transport.Request) (*http.Response, error)
+	GetStub        func(context.Context, *transport.Request) (*http.Response, error)
 	getMutex       sync.RWMutex
 	getArgsForCall []struct {
-		ctx context.Context
-		req *transport.Request
+		arg1 context.Context
+		arg2 *transport.Request
 	}
 	getReturns struct {
 		result1 *http.Response
@@ -24,26 +24,43 @@ type HTTPTransport struct {
 		result1 *http.Response
 		result2 error
 	}
+	PostStub        func(context.Context, *transport.Request, []byte) (*http.Response, error)
+	postMutex       sync.RWMutex
+	postArgsForCall []struct {
+		arg1 context.Context
+		arg2 *transport.Request
+		arg3 []byte
+	}
+	postReturns struct {
+		result1 *http.Response
+		result2 error
+	}
+	postReturnsOnCall map[int]struct {
+		result1 *http.Response
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *HTTPTransport) Get(ctx context.Context, req *transport.Request) (*http.Response, error) {
+func (fake *HTTPTransport) Get(arg1 context.Context, arg2 *transport.Request) (*http.Response, error) {
 	fake.getMutex.Lock()
 	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
 	fake.getArgsForCall = append(fake.getArgsForCall, struct {
-		ctx context.Context
-		req *transport.Request
-	}{ctx, req})
-	fake.recordInvocation("Get", []interface{}{ctx, req})
+		arg1 context.Context
+		arg2 *transport.Request
+	}{arg1, arg2})
+	stub := fake.GetStub
+	fakeReturns := fake.getReturns
+	fake.recordInvocation("Get", []interface{}{arg1, arg2})
 	fake.getMutex.Unlock()
-	if fake.GetStub != nil {
-		return fake.GetStub(ctx, req)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.getReturns.result1, fake.getReturns.result2
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *HTTPTransport) GetCallCount() int {
@@ -52,13 +69,22 @@ func (fake *HTTPTransport) GetCallCount() int {
 	return len(fake.getArgsForCall)
 }
 
+func (fake *HTTPTransport) GetCalls(stub func(context.Context, *transport.Request) (*http.Response, error)) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = stub
+}
+
 func (fake *HTTPTransport) GetArgsForCall(i int) (context.Context, *transport.Request) {
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
-	return fake.getArgsForCall[i].ctx, fake.getArgsForCall[i].req
+	argsForCall := fake.getArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *HTTPTransport) GetReturns(result1 *http.Response, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
 	fake.GetStub = nil
 	fake.getReturns = struct {
 		result1 *http.Response
@@ -67,6 +93,8 @@ func (fake *HTTPTransport) GetReturns(result1 *http.Response, result2 error) {
 }
 
 func (fake *HTTPTransport) GetReturnsOnCall(i int, result1 *http.Response, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
 	fake.GetStub = nil
 	if fake.getReturnsOnCall == nil {
 		fake.getReturnsOnCall = make(map[int]struct {
@@ -80,11 +108,84 @@ func (fake *HTTPTransport) GetReturnsOnCall(i int, result1 *http.Response, resul
 	}{result1, result2}
 }
 
+func (fake *HTTPTransport) Post(arg1 context.Context, arg2 *transport.Request, arg3 []byte) (*http.Response, error) {
+	var arg3Copy []byte
+	if arg3 != nil {
+		arg3Copy = make([]byte, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.postMutex.Lock()
+	ret, specificReturn := fake.postReturnsOnCall[len(fake.postArgsForCall)]
+	fake.postArgsForCall = append(fake.postArgsForCall, struct {
+		arg1 context.Context
+		arg2 *transport.Request
+		arg3 []byte
+	}{arg1, arg2, arg3Copy})
+	stub := fake.PostStub
+	fakeReturns := fake.postReturns
+	fake.recordInvocation("Post", []interface{}{arg1, arg2, arg3Copy})
+	fake.postMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HTTPTransport) PostCallCount() int {
+	fake.postMutex.RLock()
+	defer fake.postMutex.RUnlock()
+	return len(fake.postArgsForCall)
+}
+
+func (fake *HTTPTransport) PostCalls(stub func(context.Context, *transport.Request, []byte) (*http.Response, error)) {
+	fake.postMutex.Lock()
+	defer fake.postMutex.Unlock()
+	fake.PostStub = stub
+}
+
+func (fake *HTTPTransport) PostArgsForCall(i int) (context.Context, *transport.Request, []byte) {
+	fake.postMutex.RLock()
+	defer fake.postMutex.RUnlock()
+	argsForCall := fake.postArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *HTTPTransport) PostReturns(result1 *http.Response, result2 error) {
+	fake.postMutex.Lock()
+	defer fake.postMutex.Unlock()
+	fake.PostStub = nil
+	fake.postReturns = struct {
+		result1 *http.Response
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HTTPTransport) PostReturnsOnCall(i int, result1 *http.Response, result2 error) {
+	fake.postMutex.Lock()
+	defer fake.postMutex.Unlock()
+	fake.PostStub = nil
+	if fake.postReturnsOnCall == nil {
+		fake.postReturnsOnCall = make(map[int]struct {
+			result1 *http.Response
+			result2 error
+		})
+	}
+	fake.postReturnsOnCall[i] = struct {
+		result1 *http.Response
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HTTPTransport) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
+	fake.postMutex.RLock()
+	defer fake.postMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -65,6 +65,11 @@ func NewAuthHandler(cfg *Config, endpoint, method string, s store.Store, verifie
 	return h
 }
 
+// AuthRequired returns true if the endpoint requires authorization.
+func (h *AuthHandler) AuthRequired() bool {
+	return h.tokenVerifier.Required()
+}
+
 // Authorize authorizes the request, first checking the required bearer token and then, if the bearer token was not
 // provided, the HTTP signature.
 func (h *AuthHandler) Authorize(req *http.Request) (bool, *url.URL, error) {
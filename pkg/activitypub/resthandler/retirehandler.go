@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+)
+
+// RetirePath specifies the endpoint that's used by an administrator to retire the service.
+const RetirePath = "/retire"
+
+type actorRetirer interface {
+	RetireActor() (*url.URL, error)
+}
+
+// Retire implements a REST handler that retires the service by posting a 'Delete' activity to the service's
+// followers and witnesses. This handler is meant to be invoked by an administrator (i.e. it must be wrapped
+// with an auth handler that requires an authorization token).
+type Retire struct {
+	endpoint string
+	retirer  actorRetirer
+	marshal  func(v interface{}) ([]byte, error)
+}
+
+// NewRetire returns a new REST handler that retires the service.
+func NewRetire(cfg *Config, retirer actorRetirer) *Retire {
+	return &Retire{
+		endpoint: fmt.Sprintf("%s%s", cfg.BasePath, RetirePath),
+		retirer:  retirer,
+		marshal:  json.Marshal,
+	}
+}
+
+// Method returns the HTTP method, which is always POST.
+func (h *Retire) Method() string {
+	return http.MethodPost
+}
+
+// Path returns the base path of the target URL for this handler.
+func (h *Retire) Path() string {
+	return h.endpoint
+}
+
+// Handler returns the handler that should be invoked when an HTTP POST is requested to the target endpoint.
+// This handler must be registered with an HTTP server.
+func (h *Retire) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *Retire) handle(w http.ResponseWriter, req *http.Request) {
+	activityID, err := h.retirer.RetireActor()
+	if err != nil {
+		logger.Errorf("[%s] Error retiring service: %s", h.endpoint, err)
+
+		h.writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	logger.Infof("[%s] Service retired on behalf of [%s]. 'Delete' activity: %s",
+		h.endpoint, auth.TokenIDFromContext(req.Context()), activityID)
+
+	activityIDBytes, err := h.marshal(activityID.String())
+	if err != nil {
+		logger.Errorf("[%s] Error marshaling activity ID: %s", h.endpoint, err)
+
+		h.writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	h.writeResponse(w, http.StatusOK, activityIDBytes)
+}
+
+func (h *Retire) writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if _, err := w.Write(body); err != nil {
+		logger.Warnf("[%s] Unable to write response: %s", h.endpoint, err)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+)
+
+func TestRetire_Handler(t *testing.T) {
+	cfg := &Config{
+		BasePath:  "/services/orb",
+		ObjectIRI: serviceIRI,
+	}
+
+	activityID := testutil.NewMockID(serviceIRI, "/activities/123456789")
+
+	t.Run("Success", func(t *testing.T) {
+		h := NewRetire(cfg, &mockRetirer{activityID: activityID})
+		require.NotNil(t, h.Handler())
+		require.Equal(t, http.MethodPost, h.Method())
+		require.Equal(t, "/services/orb/retire", h.Path())
+
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, h.Path(), nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, `"`+activityID.String()+`"`, rw.Body.String())
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Retire error", func(t *testing.T) {
+		h := NewRetire(cfg, &mockRetirer{err: errors.New("injected retire error")})
+
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, h.Path(), nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := NewRetire(cfg, &mockRetirer{activityID: activityID})
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, h.Path(), nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+type mockRetirer struct {
+	activityID *url.URL
+	err        error
+}
+
+func (m *mockRetirer) RetireActor() (*url.URL, error) {
+	return m.activityID, m.err
+}
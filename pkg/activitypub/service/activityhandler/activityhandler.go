@@ -48,6 +48,7 @@ type Config struct {
 
 type activityPubClient interface {
 	GetActor(iri *url.URL) (*vocab.ActorType, error)
+	InvalidateActor(iri *url.URL)
 }
 
 type undoFunc func(activity *vocab.ActivityType) error
@@ -179,6 +180,7 @@ func defaultOptions() *service.Handlers {
 		FollowerAuth:            &acceptAllActorsAuth{},
 		WitnessInvitationAuth:   &acceptAllActorsAuth{},
 		ProofHandler:            &noOpProofHandler{},
+		Blocklist:               &noBlocklist{},
 	}
 }
 
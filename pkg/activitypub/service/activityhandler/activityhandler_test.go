@@ -2588,6 +2588,422 @@ func TestHandler_HandleUndoInviteWitnessActivity(t *testing.T) {
 	})
 }
 
+func TestHandler_HandleUpdateActivity(t *testing.T) {
+	service1IRI := testutil.MustParseURL("http://localhost:8301/services/service1")
+	service2IRI := testutil.MustParseURL("http://localhost:8302/services/service2")
+	service3IRI := testutil.MustParseURL("http://localhost:8303/services/service3")
+
+	ibHandler, obHandler, ibSubscriber, _, stop := startInboxOutboxWithMocks(t, service1IRI, service2IRI)
+	defer stop()
+
+	actor2 := vocab.NewService(service2IRI,
+		vocab.WithPublicKey(vocab.NewPublicKey(
+			vocab.WithID(testutil.NewMockID(service2IRI, "/keys/main-key")),
+			vocab.WithOwner(service2IRI),
+			vocab.WithPublicKeyPem("-----BEGIN PUBLIC KEY-----\nMIIBIjANBgkqhki....."),
+		)),
+	)
+
+	t.Run("Inbox", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithActorObject(actor2)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			require.NoError(t, ibHandler.HandleActivity(update))
+
+			time.Sleep(50 * time.Millisecond)
+
+			require.NotNil(t, ibSubscriber.Activity(update.ID()))
+
+			a, err := ibHandler.store.GetActor(service2IRI)
+			require.NoError(t, err)
+			require.Equal(t, actor2.PublicKey().PublicKeyPem, a.PublicKey().PublicKeyPem)
+		})
+
+		t.Run("No actor in activity", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithActorObject(actor2)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := ibHandler.HandleActivity(update)
+			require.True(t, orberrors.IsBadRequest(err))
+			require.EqualError(t, err, "no actor specified in 'Update' activity")
+		})
+
+		t.Run("No actor in object", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := ibHandler.HandleActivity(update)
+			require.True(t, orberrors.IsBadRequest(err))
+			require.Contains(t, err.Error(), "no actor specified in the 'object' field")
+		})
+
+		t.Run("Actor mismatch", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithActorObject(actor2)),
+				vocab.WithID(newActivityID(service3IRI)),
+				vocab.WithActor(service3IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := ibHandler.HandleActivity(update)
+			require.True(t, orberrors.IsBadRequest(err))
+			require.Contains(t, err.Error(), "is not the same as the actor of the 'Update' activity")
+		})
+
+		t.Run("Store error", func(t *testing.T) {
+			s := &mocks.ActivityStore{}
+			s.PutActorReturns(errors.New("injected storage error"))
+
+			h := NewInbox(&Config{ServiceName: "inbox1", ServiceIRI: service1IRI}, s, mocks.NewOutbox(),
+				mocks.NewActorRetriever())
+
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithActorObject(actor2)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := h.HandleActivity(update)
+			require.True(t, orberrors.IsTransient(err))
+			require.Contains(t, err.Error(), "injected storage error")
+		})
+	})
+
+	t.Run("Outbox", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithActorObject(actor2)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			require.NoError(t, obHandler.HandleActivity(update))
+
+			a, err := obHandler.store.GetActor(service2IRI)
+			require.NoError(t, err)
+			require.Equal(t, actor2.PublicKey().PublicKeyPem, a.PublicKey().PublicKeyPem)
+		})
+
+		t.Run("No actor in object", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := obHandler.HandleActivity(update)
+			require.True(t, orberrors.IsBadRequest(err))
+			require.Contains(t, err.Error(), "no actor specified in the 'object' field")
+		})
+
+		t.Run("Not this service", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithActorObject(vocab.NewService(service3IRI))),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := obHandler.HandleActivity(update)
+			require.True(t, orberrors.IsBadRequest(err))
+			require.Contains(t, err.Error(), "is not this service")
+		})
+
+		t.Run("Store error", func(t *testing.T) {
+			s := &mocks.ActivityStore{}
+			s.PutActorReturns(errors.New("injected storage error"))
+
+			h := NewOutbox(&Config{ServiceName: "outbox1", ServiceIRI: service2IRI}, s, mocks.NewActorRetriever())
+
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithActorObject(actor2)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := h.HandleActivity(update)
+			require.True(t, orberrors.IsTransient(err))
+			require.Contains(t, err.Error(), "injected storage error")
+		})
+	})
+}
+
+func TestHandler_HandleDeleteActivity(t *testing.T) {
+	service1IRI := testutil.MustParseURL("http://localhost:8301/services/service1")
+	service2IRI := testutil.MustParseURL("http://localhost:8302/services/service2")
+	service3IRI := testutil.MustParseURL("http://localhost:8303/services/service3")
+
+	ibHandler, _, ibSubscriber, _, stop := startInboxOutboxWithMocks(t, service1IRI, service2IRI)
+	defer stop()
+
+	t.Run("Success", func(t *testing.T) {
+		require.NoError(t, ibHandler.store.PutActor(vocab.NewService(service2IRI)))
+		require.NoError(t, ibHandler.store.AddReference(store.Follower, service1IRI, service2IRI))
+		require.NoError(t, ibHandler.store.AddReference(store.Witnessing, service1IRI, service2IRI))
+		require.NoError(t, ibHandler.store.AddReference(store.Follower, service1IRI, service3IRI))
+
+		del := vocab.NewDeleteActivity(
+			vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+		)
+
+		require.NoError(t, ibHandler.HandleActivity(del))
+
+		time.Sleep(50 * time.Millisecond)
+
+		require.NotNil(t, ibSubscriber.Activity(del.ID()))
+
+		_, err := ibHandler.store.GetActor(service2IRI)
+		require.True(t, errors.Is(err, store.ErrNotFound))
+
+		hasRef, err := ibHandler.hasReference(service1IRI, service2IRI, store.Follower)
+		require.NoError(t, err)
+		require.False(t, hasRef)
+
+		hasRef, err = ibHandler.hasReference(service1IRI, service2IRI, store.Witnessing)
+		require.NoError(t, err)
+		require.False(t, hasRef)
+
+		hasRef, err = ibHandler.hasReference(service1IRI, service3IRI, store.Follower)
+		require.NoError(t, err)
+		require.True(t, hasRef)
+	})
+
+	t.Run("Embedded actor", func(t *testing.T) {
+		del := vocab.NewDeleteActivity(
+			vocab.NewObjectProperty(vocab.WithActorObject(vocab.NewService(service2IRI))),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+		)
+
+		require.NoError(t, ibHandler.HandleActivity(del))
+	})
+
+	t.Run("No actor in activity", func(t *testing.T) {
+		del := vocab.NewDeleteActivity(
+			vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithTo(service1IRI),
+		)
+
+		err := ibHandler.HandleActivity(del)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.EqualError(t, err, "no actor specified in 'Delete' activity")
+	})
+
+	t.Run("No object", func(t *testing.T) {
+		del := vocab.NewDeleteActivity(
+			vocab.NewObjectProperty(),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+		)
+
+		err := ibHandler.HandleActivity(del)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "no IRI specified in the 'object' field")
+	})
+
+	t.Run("Actor mismatch", func(t *testing.T) {
+		del := vocab.NewDeleteActivity(
+			vocab.NewObjectProperty(vocab.WithIRI(service3IRI)),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+		)
+
+		err := ibHandler.HandleActivity(del)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "is not the same as the actor")
+	})
+
+	t.Run("Store errors", func(t *testing.T) {
+		errExpected := errors.New("injected storage error")
+
+		del := vocab.NewDeleteActivity(
+			vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+		)
+
+		t.Run("Delete actor", func(t *testing.T) {
+			s := &mocks.ActivityStore{}
+			s.DeleteActorReturns(errExpected)
+
+			h := NewInbox(&Config{ServiceName: "inbox1", ServiceIRI: service1IRI}, s, mocks.NewOutbox(),
+				mocks.NewActorRetriever())
+
+			err := h.HandleActivity(del)
+			require.True(t, orberrors.IsTransient(err))
+			require.Contains(t, err.Error(), errExpected.Error())
+		})
+
+		t.Run("Delete reference", func(t *testing.T) {
+			s := &mocks.ActivityStore{}
+			s.DeleteReferenceReturns(errExpected)
+
+			h := NewInbox(&Config{ServiceName: "inbox1", ServiceIRI: service1IRI}, s, mocks.NewOutbox(),
+				mocks.NewActorRetriever())
+
+			err := h.HandleActivity(del)
+			require.True(t, orberrors.IsTransient(err))
+			require.Contains(t, err.Error(), errExpected.Error())
+		})
+	})
+}
+
+func TestHandler_HandleBlockActivity(t *testing.T) {
+	service1IRI := testutil.MustParseURL("http://localhost:8301/services/service1")
+	service2IRI := testutil.MustParseURL("http://localhost:8302/services/service2")
+	service3IRI := testutil.MustParseURL("http://localhost:8303/services/service3")
+
+	ibHandler, _, ibSubscriber, _, stop := startInboxOutboxWithMocks(t, service1IRI, service2IRI)
+	defer stop()
+
+	t.Run("Success", func(t *testing.T) {
+		require.NoError(t, ibHandler.store.AddReference(store.Following, service1IRI, service2IRI))
+		require.NoError(t, ibHandler.store.AddReference(store.Witness, service1IRI, service2IRI))
+		require.NoError(t, ibHandler.store.AddReference(store.Following, service1IRI, service3IRI))
+
+		block := vocab.NewBlockActivity(
+			vocab.NewObjectProperty(vocab.WithIRI(service1IRI)),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+		)
+
+		require.NoError(t, ibHandler.HandleActivity(block))
+
+		time.Sleep(50 * time.Millisecond)
+
+		require.NotNil(t, ibSubscriber.Activity(block.ID()))
+
+		hasRef, err := ibHandler.hasReference(service1IRI, service2IRI, store.Following)
+		require.NoError(t, err)
+		require.False(t, hasRef)
+
+		hasRef, err = ibHandler.hasReference(service1IRI, service2IRI, store.Witness)
+		require.NoError(t, err)
+		require.False(t, hasRef)
+
+		hasRef, err = ibHandler.hasReference(service1IRI, service3IRI, store.Following)
+		require.NoError(t, err)
+		require.True(t, hasRef)
+	})
+
+	t.Run("No actor in activity", func(t *testing.T) {
+		block := vocab.NewBlockActivity(
+			vocab.NewObjectProperty(vocab.WithIRI(service1IRI)),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithTo(service1IRI),
+		)
+
+		err := ibHandler.HandleActivity(block)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.EqualError(t, err, "no actor specified in 'Block' activity")
+	})
+
+	t.Run("No object", func(t *testing.T) {
+		block := vocab.NewBlockActivity(
+			vocab.NewObjectProperty(),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+		)
+
+		err := ibHandler.HandleActivity(block)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "no IRI specified in the 'object' field")
+	})
+
+	t.Run("Object is not this service", func(t *testing.T) {
+		block := vocab.NewBlockActivity(
+			vocab.NewObjectProperty(vocab.WithIRI(service3IRI)),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+		)
+
+		err := ibHandler.HandleActivity(block)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "is not this service")
+	})
+
+	t.Run("Delete reference error", func(t *testing.T) {
+		errExpected := errors.New("injected storage error")
+
+		s := &mocks.ActivityStore{}
+		s.DeleteReferenceReturns(errExpected)
+
+		h := NewInbox(&Config{ServiceName: "inbox1", ServiceIRI: service1IRI}, s, mocks.NewOutbox(),
+			mocks.NewActorRetriever())
+
+		block := vocab.NewBlockActivity(
+			vocab.NewObjectProperty(vocab.WithIRI(service1IRI)),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+		)
+
+		err := h.HandleActivity(block)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestHandler_Blocklist(t *testing.T) {
+	service1IRI := testutil.MustParseURL("http://localhost:8301/services/service1")
+	service2IRI := testutil.MustParseURL("http://localhost:8302/services/service2")
+
+	follow := vocab.NewFollowActivity(
+		vocab.NewObjectProperty(vocab.WithIRI(service1IRI)),
+		vocab.WithID(newActivityID(service2IRI)),
+		vocab.WithActor(service2IRI),
+		vocab.WithTo(service1IRI),
+	)
+
+	t.Run("Blocked", func(t *testing.T) {
+		h := NewInbox(&Config{ServiceName: "inbox1", ServiceIRI: service1IRI}, memstore.New(""), mocks.NewOutbox(),
+			mocks.NewActorRetriever(), spi.WithBlocklist(&mockBlocklist{blocked: true}))
+
+		err := h.HandleActivity(follow)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrActorBlocked))
+		require.False(t, orberrors.IsTransient(err))
+	})
+
+	t.Run("Blocklist error", func(t *testing.T) {
+		errExpected := errors.New("injected blocklist error")
+
+		h := NewInbox(&Config{ServiceName: "inbox1", ServiceIRI: service1IRI}, memstore.New(""), mocks.NewOutbox(),
+			mocks.NewActorRetriever(), spi.WithBlocklist(&mockBlocklist{err: errExpected}))
+
+		err := h.HandleActivity(follow)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
 func TestHandler_AnnounceAnchorCredential(t *testing.T) {
 	log.SetLevel("activitypub_service", log.DEBUG)
 
@@ -2711,6 +3127,15 @@ func TestHandler_AnnounceAnchorCredential(t *testing.T) {
 	})
 }
 
+type mockBlocklist struct {
+	blocked bool
+	err     error
+}
+
+func (m *mockBlocklist) IsBlocked(*url.URL) (bool, error) {
+	return m.blocked, m.err
+}
+
 type mockActivitySubscriber struct {
 	mutex        sync.RWMutex
 	activities   map[string]*vocab.ActivityType
@@ -23,6 +23,9 @@ import (
 
 var errDuplicateAnchorCredential = errors.New("anchor credential already handled")
 
+// ErrActorBlocked is returned when an activity is received from an actor in the blocklist.
+var ErrActorBlocked = errors.New("actor is blocked")
+
 // Inbox handles activities posted to the inbox.
 type Inbox struct {
 	*handler
@@ -71,6 +74,10 @@ func NewInbox(cfg *Config, s store.Store, outbox service.Outbox,
 
 // HandleActivity handles the ActivityPub activity in the inbox.
 func (h *Inbox) HandleActivity(activity *vocab.ActivityType) error {
+	if err := h.checkBlocked(activity); err != nil {
+		return err
+	}
+
 	typeProp := activity.Type()
 
 	switch {
@@ -90,11 +97,38 @@ func (h *Inbox) HandleActivity(activity *vocab.ActivityType) error {
 		return h.handleOfferActivity(activity)
 	case typeProp.Is(vocab.TypeUndo):
 		return h.handleUndoActivity(activity)
+	case typeProp.Is(vocab.TypeUpdate):
+		return h.handleUpdateActivity(activity)
+	case typeProp.Is(vocab.TypeDelete):
+		return h.handleDeleteActivity(activity)
+	case typeProp.Is(vocab.TypeBlock):
+		return h.handleBlockActivity(activity)
 	default:
 		return fmt.Errorf("unsupported activity type: %s", typeProp.Types())
 	}
 }
 
+func (h *Inbox) checkBlocked(activity *vocab.ActivityType) error {
+	actorIRI := activity.Actor()
+	if actorIRI == nil {
+		return nil
+	}
+
+	blocked, err := h.Blocklist.IsBlocked(actorIRI)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("check blocklist for actor [%s]: %w", actorIRI, err))
+	}
+
+	if blocked {
+		logger.Infof("[%s] Refusing '%s' activity [%s] from blocked actor [%s]",
+			h.ServiceName, activity.Type(), activity.ID(), actorIRI)
+
+		return fmt.Errorf("activity [%s] from actor [%s]: %w", activity.ID(), actorIRI, ErrActorBlocked)
+	}
+
+	return nil
+}
+
 func (h *Inbox) handleCreateActivity(create *vocab.ActivityType) error {
 	logger.Debugf("[%s] Handling 'Create' activity: %s", h.ServiceName, create.ID())
 
@@ -162,7 +196,7 @@ func (h *Inbox) handleReferenceActivity(activity *vocab.ActivityType, refType st
 		return fmt.Errorf("unable to retrieve actor [%s]: %w", actorIRI, err)
 	}
 
-	accept, err := auth.AuthorizeActor(actor)
+	accept, err := auth.AuthorizeActor(actor, activity)
 	if err != nil {
 		return fmt.Errorf("authorize actor [%s]: %w", actorIRI, err)
 	}
@@ -416,6 +450,124 @@ func (h *Inbox) hasReference(objectIRI, refIRI *url.URL, refType store.Reference
 	return true, nil
 }
 
+// handleUpdateActivity handles an 'Update' activity which is sent by an actor when its public key (or any other
+// property) has changed. The cached copy of the actor is invalidated and the new actor is stored.
+func (h *Inbox) handleUpdateActivity(update *vocab.ActivityType) error {
+	logger.Infof("[%s] Handling 'Update' activity: %s", h.ServiceName, update.ID())
+
+	actorIRI := update.Actor()
+	if actorIRI == nil {
+		return orberrors.NewBadRequest(fmt.Errorf("no actor specified in 'Update' activity"))
+	}
+
+	actor := update.Object().Actor()
+	if actor == nil {
+		return orberrors.NewBadRequest(fmt.Errorf("no actor specified in the 'object' field of the 'Update' activity"))
+	}
+
+	// An actor may only update itself.
+	if actor.ID().String() != actorIRI.String() {
+		return orberrors.NewBadRequest(
+			fmt.Errorf("the actor in the 'object' field [%s] is not the same as the actor of the 'Update' activity [%s]",
+				actor.ID(), actorIRI))
+	}
+
+	h.client.InvalidateActor(actorIRI)
+
+	if err := h.store.PutActor(actor); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store actor [%s]: %w", actorIRI, err))
+	}
+
+	logger.Infof("[%s] Actor [%s] was updated", h.ServiceName, actorIRI)
+
+	h.notify(update)
+
+	return nil
+}
+
+// handleDeleteActivity handles a 'Delete' activity which is sent by an actor when it's retired. The actor
+// is removed from storage along with any follower, following, witness and witnessing references to the actor.
+func (h *Inbox) handleDeleteActivity(del *vocab.ActivityType) error {
+	logger.Infof("[%s] Handling 'Delete' activity: %s", h.ServiceName, del.ID())
+
+	actorIRI := del.Actor()
+	if actorIRI == nil {
+		return orberrors.NewBadRequest(fmt.Errorf("no actor specified in 'Delete' activity"))
+	}
+
+	objectIRI := del.Object().IRI()
+	if objectIRI == nil && del.Object().Actor() != nil {
+		objectIRI = del.Object().Actor().ID().URL()
+	}
+
+	if objectIRI == nil {
+		return orberrors.NewBadRequest(fmt.Errorf("no IRI specified in the 'object' field of the 'Delete' activity"))
+	}
+
+	// An actor may only delete itself.
+	if objectIRI.String() != actorIRI.String() {
+		return orberrors.NewBadRequest(
+			fmt.Errorf("the object of the 'Delete' activity [%s] is not the same as the actor [%s]",
+				objectIRI, actorIRI))
+	}
+
+	h.client.InvalidateActor(actorIRI)
+
+	if err := h.store.DeleteActor(actorIRI); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("delete actor [%s]: %w", actorIRI, err))
+	}
+
+	if err := h.deleteActorReferences(actorIRI); err != nil {
+		return err
+	}
+
+	logger.Infof("[%s] Actor [%s] and its references were deleted", h.ServiceName, actorIRI)
+
+	h.notify(del)
+
+	return nil
+}
+
+// handleBlockActivity removes all references to an actor that has blocked this service.
+func (h *Inbox) handleBlockActivity(block *vocab.ActivityType) error {
+	logger.Infof("[%s] Handling 'Block' activity: %s", h.ServiceName, block.ID())
+
+	actorIRI := block.Actor()
+	if actorIRI == nil {
+		return orberrors.NewBadRequest(fmt.Errorf("no actor specified in 'Block' activity"))
+	}
+
+	objectIRI := block.Object().IRI()
+	if objectIRI == nil {
+		return orberrors.NewBadRequest(fmt.Errorf("no IRI specified in the 'object' field of the 'Block' activity"))
+	}
+
+	if objectIRI.String() != h.ServiceIRI.String() {
+		return orberrors.NewBadRequest(
+			fmt.Errorf("the object of the 'Block' activity [%s] is not this service", objectIRI))
+	}
+
+	if err := h.deleteActorReferences(actorIRI); err != nil {
+		return err
+	}
+
+	logger.Infof("[%s] This service was blocked by [%s]. References to the actor were deleted", h.ServiceName, actorIRI)
+
+	h.notify(block)
+
+	return nil
+}
+
+func (h *Inbox) deleteActorReferences(actorIRI *url.URL) error {
+	for _, refType := range []store.ReferenceType{store.Follower, store.Following, store.Witness, store.Witnessing} {
+		if err := h.store.DeleteReference(refType, h.ServiceIRI, actorIRI); err != nil {
+			return orberrors.NewTransient(fmt.Errorf("delete %s reference to actor [%s]: %w", refType, actorIRI, err))
+		}
+	}
+
+	return nil
+}
+
 func (h *Inbox) handleAnnounceActivity(announce *vocab.ActivityType) error {
 	logger.Infof("[%s] Handling 'Announce' activity: %s", h.ServiceName, announce.ID())
 
@@ -877,10 +1029,16 @@ func (p *noOpAnchorCredentialPublisher) HandleAnchorCredential(*url.URL, string,
 
 type acceptAllActorsAuth struct{}
 
-func (a *acceptAllActorsAuth) AuthorizeActor(*vocab.ActorType) (bool, error) {
+func (a *acceptAllActorsAuth) AuthorizeActor(*vocab.ActorType, *vocab.ActivityType) (bool, error) {
 	return true, nil
 }
 
+type noBlocklist struct{}
+
+func (b *noBlocklist) IsBlocked(*url.URL) (bool, error) {
+	return false, nil
+}
+
 type noOpProofHandler struct{}
 
 func (p *noOpProofHandler) HandleProof(witness *url.URL, anchorCredID string, endTime time.Time, proof []byte) error { //nolint:lll
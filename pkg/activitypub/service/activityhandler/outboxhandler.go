@@ -49,6 +49,8 @@ func (h *Outbox) HandleActivity(activity *vocab.ActivityType) error {
 		return h.handleCreateActivity(activity)
 	case typeProp.Is(vocab.TypeUndo):
 		return h.handleUndoActivity(activity)
+	case typeProp.Is(vocab.TypeUpdate):
+		return h.handleUpdateActivity(activity)
 	default:
 		// Nothing to do for activity.
 		return nil
@@ -83,6 +85,27 @@ func (h *handler) handleCreateActivity(create *vocab.ActivityType) error {
 	return nil
 }
 
+// handleUpdateActivity stores the actor that was sent in the 'Update' activity so that the service is able
+// to determine whether or not its actor has changed since it was last published.
+func (h *Outbox) handleUpdateActivity(update *vocab.ActivityType) error {
+	logger.Debugf("[%s] Handling 'Update' activity: %s", h.ServiceName, update.ID())
+
+	actor := update.Object().Actor()
+	if actor == nil {
+		return orberrors.NewBadRequest(fmt.Errorf("no actor specified in the 'object' field of the 'Update' activity"))
+	}
+
+	if actor.ID().String() != h.ServiceIRI.String() {
+		return orberrors.NewBadRequest(fmt.Errorf("the actor in the 'Update' activity is not this service"))
+	}
+
+	if err := h.store.PutActor(actor); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store actor: %w", err))
+	}
+
+	return nil
+}
+
 func (h *Outbox) undoAddReference(activity *vocab.ActivityType, refType store.ReferenceType,
 	getTargetIRI func() *url.URL) error {
 	if activity.Actor().String() != h.ServiceIRI.String() {
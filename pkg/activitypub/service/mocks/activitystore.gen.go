@@ -34,6 +34,17 @@ type ActivityStore struct {
 	addReferenceReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DeleteActorStub        func(*url.URL) error
+	deleteActorMutex       sync.RWMutex
+	deleteActorArgsForCall []struct {
+		arg1 *url.URL
+	}
+	deleteActorReturns struct {
+		result1 error
+	}
+	deleteActorReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DeleteReferenceStub        func(spi.ReferenceType, *url.URL, *url.URL) error
 	deleteReferenceMutex       sync.RWMutex
 	deleteReferenceArgsForCall []struct {
@@ -241,6 +252,67 @@ func (fake *ActivityStore) AddReferenceReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *ActivityStore) DeleteActor(arg1 *url.URL) error {
+	fake.deleteActorMutex.Lock()
+	ret, specificReturn := fake.deleteActorReturnsOnCall[len(fake.deleteActorArgsForCall)]
+	fake.deleteActorArgsForCall = append(fake.deleteActorArgsForCall, struct {
+		arg1 *url.URL
+	}{arg1})
+	stub := fake.DeleteActorStub
+	fakeReturns := fake.deleteActorReturns
+	fake.recordInvocation("DeleteActor", []interface{}{arg1})
+	fake.deleteActorMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *ActivityStore) DeleteActorCallCount() int {
+	fake.deleteActorMutex.RLock()
+	defer fake.deleteActorMutex.RUnlock()
+	return len(fake.deleteActorArgsForCall)
+}
+
+func (fake *ActivityStore) DeleteActorCalls(stub func(*url.URL) error) {
+	fake.deleteActorMutex.Lock()
+	defer fake.deleteActorMutex.Unlock()
+	fake.DeleteActorStub = stub
+}
+
+func (fake *ActivityStore) DeleteActorArgsForCall(i int) *url.URL {
+	fake.deleteActorMutex.RLock()
+	defer fake.deleteActorMutex.RUnlock()
+	argsForCall := fake.deleteActorArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ActivityStore) DeleteActorReturns(result1 error) {
+	fake.deleteActorMutex.Lock()
+	defer fake.deleteActorMutex.Unlock()
+	fake.DeleteActorStub = nil
+	fake.deleteActorReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *ActivityStore) DeleteActorReturnsOnCall(i int, result1 error) {
+	fake.deleteActorMutex.Lock()
+	defer fake.deleteActorMutex.Unlock()
+	fake.DeleteActorStub = nil
+	if fake.deleteActorReturnsOnCall == nil {
+		fake.deleteActorReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteActorReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *ActivityStore) DeleteReference(arg1 spi.ReferenceType, arg2 *url.URL, arg3 *url.URL) error {
 	fake.deleteReferenceMutex.Lock()
 	ret, specificReturn := fake.deleteReferenceReturnsOnCall[len(fake.deleteReferenceArgsForCall)]
@@ -631,6 +703,8 @@ func (fake *ActivityStore) Invocations() map[string][][]interface{} {
 	defer fake.addActivityMutex.RUnlock()
 	fake.addReferenceMutex.RLock()
 	defer fake.addReferenceMutex.RUnlock()
+	fake.deleteActorMutex.RLock()
+	defer fake.deleteActorMutex.RUnlock()
 	fake.deleteReferenceMutex.RLock()
 	defer fake.deleteReferenceMutex.RUnlock()
 	fake.getActivityMutex.RLock()
@@ -82,6 +82,14 @@ func (m *ActorRetriever) GetActor(actorIRI *url.URL) (*vocab.ActorType, error) {
 	return actor, nil
 }
 
+// InvalidateActor does nothing since the mock actor retriever doesn't cache actors.
+func (m *ActorRetriever) InvalidateActor(*url.URL) {
+}
+
+// InvalidatePublicKey does nothing since the mock actor retriever doesn't cache public keys.
+func (m *ActorRetriever) InvalidatePublicKey(*url.URL) {
+}
+
 // GetReferences simply returns an iterator that contains the IRI passed as an arg.
 func (m *ActorRetriever) GetReferences(iri *url.URL) (client.ReferenceIterator, error) {
 	if m.err != nil {
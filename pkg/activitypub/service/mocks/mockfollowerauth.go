@@ -43,6 +43,6 @@ func (m *ActorAuth) WithError(err error) *ActorAuth {
 }
 
 // AuthorizeActor is a mock implementation that returns the injected values.
-func (m *ActorAuth) AuthorizeActor(follower *vocab.ActorType) (bool, error) {
+func (m *ActorAuth) AuthorizeActor(*vocab.ActorType, *vocab.ActivityType) (bool, error) {
 	return m.accept, m.err
 }
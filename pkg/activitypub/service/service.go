@@ -7,13 +7,17 @@ SPDX-License-Identifier: Apache-2.0
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/trustbloc/edge-core/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 
 	"github.com/trustbloc/orb/pkg/activitypub/client"
@@ -29,6 +33,8 @@ import (
 	"github.com/trustbloc/orb/pkg/pubsub/redelivery"
 )
 
+var logger = log.New("activitypub_service")
+
 const (
 	inboxActivitiesTopic  = "inbox_activities"
 	outboxActivitiesTopic = "outbox_activities"
@@ -61,6 +67,8 @@ type Service struct {
 	inbox           *inbox.Inbox
 	outbox          *outbox.Outbox
 	activityHandler spi.ActivityHandler
+	activityStore   store.Store
+	serviceIRI      *url.URL
 }
 
 type httpTransport interface {
@@ -75,6 +83,7 @@ type signatureVerifier interface {
 type activityPubClient interface {
 	GetActor(iri *url.URL) (*vocab.ActorType, error)
 	GetReferences(iri *url.URL) (client.ReferenceIterator, error)
+	InvalidateActor(iri *url.URL)
 }
 
 type resourceResolver interface {
@@ -141,6 +150,8 @@ func New(cfg *Config, activityStore store.Store, t httpTransport, sigVerifier si
 		inbox:           ib,
 		outbox:          ob,
 		activityHandler: inboxHandler,
+		activityStore:   activityStore,
+		serviceIRI:      cfg.ServiceIRI,
 	}
 
 	s.Lifecycle = lifecycle.New(cfg.ServiceEndpoint,
@@ -168,6 +179,89 @@ func (s *Service) Outbox() spi.Outbox {
 	return s.outbox
 }
 
+// UpdateActor posts an 'Update' activity to the service's followers and witnesses if the given actor differs
+// from the actor that was last published (for example, if the service's public key was rotated) so that they
+// may refresh their copy of the actor. The service must be started before this function is called.
+func (s *Service) UpdateActor(actor *vocab.ActorType) error {
+	publishedActor, err := s.activityStore.GetActor(actor.ID().URL())
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("get actor [%s]: %w", actor.ID(), err)
+		}
+
+		// The actor has never been published so there's no need to notify anyone.
+		if err := s.activityStore.PutActor(actor); err != nil {
+			return fmt.Errorf("store actor [%s]: %w", actor.ID(), err)
+		}
+
+		return nil
+	}
+
+	changed, err := actorChanged(publishedActor, actor)
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		logger.Debugf("[%s] Actor has not changed. Not publishing 'Update' activity.", s.serviceIRI)
+
+		return nil
+	}
+
+	to, err := s.followersAndWitnesses()
+	if err != nil {
+		return err
+	}
+
+	activityID, err := s.outbox.Post(vocab.NewUpdateActivity(
+		vocab.NewObjectProperty(vocab.WithActorObject(actor)),
+		vocab.WithTo(to...),
+	))
+	if err != nil {
+		return fmt.Errorf("post 'Update' activity: %w", err)
+	}
+
+	logger.Infof("[%s] Actor has changed. Posted 'Update' activity [%s] to followers and witnesses.",
+		s.serviceIRI, activityID)
+
+	return nil
+}
+
+// RetireActor posts a 'Delete' activity to the service's followers and witnesses so that they remove their copy
+// of the actor (service) along with all references to it. This should be invoked when the service is retired.
+func (s *Service) RetireActor() (*url.URL, error) {
+	to, err := s.followersAndWitnesses()
+	if err != nil {
+		return nil, err
+	}
+
+	activityID, err := s.outbox.Post(vocab.NewDeleteActivity(
+		vocab.NewObjectProperty(vocab.WithIRI(s.serviceIRI)),
+		vocab.WithTo(to...),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("post 'Delete' activity: %w", err)
+	}
+
+	logger.Infof("[%s] Posted 'Delete' activity [%s] to followers and witnesses.", s.serviceIRI, activityID)
+
+	return activityID, nil
+}
+
+func (s *Service) followersAndWitnesses() ([]*url.URL, error) {
+	followersIRI, err := url.Parse(s.serviceIRI.String() + resthandler.FollowersPath)
+	if err != nil {
+		return nil, fmt.Errorf("parse followers IRI: %w", err)
+	}
+
+	witnessesIRI, err := url.Parse(s.serviceIRI.String() + resthandler.WitnessesPath)
+	if err != nil {
+		return nil, fmt.Errorf("parse witnesses IRI: %w", err)
+	}
+
+	return []*url.URL{followersIRI, witnessesIRI}, nil
+}
+
 // InboxHTTPHandler returns the HTTP handler for the inbox which is invoked by the HTTP server.
 // This handler must be registered with an HTTP server.
 func (s *Service) InboxHTTPHandler() common.HTTPHandler {
@@ -178,3 +272,17 @@ func (s *Service) InboxHTTPHandler() common.HTTPHandler {
 func (s *Service) Subscribe() <-chan *vocab.ActivityType {
 	return s.activityHandler.Subscribe()
 }
+
+func actorChanged(a1, a2 *vocab.ActorType) (bool, error) {
+	a1Bytes, err := json.Marshal(a1)
+	if err != nil {
+		return false, fmt.Errorf("marshal actor: %w", err)
+	}
+
+	a2Bytes, err := json.Marshal(a2)
+	if err != nil {
+		return false, fmt.Errorf("marshal actor: %w", err)
+	}
+
+	return !bytes.Equal(a1Bytes, a2Bytes), nil
+}
@@ -954,6 +954,102 @@ type mockProviders struct {
 	witnessHandler          *mocks.WitnessHandler
 }
 
+func TestService_UpdateActor(t *testing.T) {
+	log.SetLevel(wmlogger.Module, log.WARNING)
+
+	service1IRI := testutil.MustParseURL("http://localhost:8501/services/service1")
+	service2IRI := testutil.MustParseURL("http://localhost:8502/services/service2")
+
+	service1, store1, publicKey1, mockProviders1 := newServiceWithMocks(t, "/services/service1", service1IRI)
+
+	defer service1.Stop()
+
+	service2, store2, publicKey2, mockProviders2 := newServiceWithMocks(t, "/services/service2", service2IRI)
+
+	defer service2.Stop()
+
+	actor1 := aptestutil.NewMockService(service1IRI, aptestutil.WithPublicKey(publicKey1))
+	actor2 := aptestutil.NewMockService(service2IRI, aptestutil.WithPublicKey(publicKey2))
+
+	mockProviders1.actorRetriever.WithPublicKey(publicKey2).WithActor(actor2)
+	mockProviders2.actorRetriever.WithPublicKey(publicKey1).WithActor(actor1)
+
+	stop1 := startHTTPServer(t, ":8501", service1.InboxHTTPHandler())
+	defer stop1()
+
+	stop2 := startHTTPServer(t, ":8502", service2.InboxHTTPHandler())
+	defer stop2()
+
+	service1.Start()
+	service2.Start()
+
+	defer service1.Stop()
+	defer service2.Stop()
+
+	require.NoError(t, store1.AddReference(spi.Follower, service1IRI, service2IRI))
+	require.NoError(t, store2.AddReference(spi.Following, service2IRI, service1IRI))
+
+	subscriber2 := mocks.NewSubscriber(service2.Subscribe())
+
+	t.Run("Update", func(t *testing.T) {
+		// The first time the actor is stored but not published.
+		require.NoError(t, service1.UpdateActor(actor1))
+
+		a, err := store1.GetActor(service1IRI)
+		require.NoError(t, err)
+		require.Equal(t, actor1, a)
+
+		// The actor hasn't changed so nothing is published.
+		require.NoError(t, service1.UpdateActor(actor1))
+
+		time.Sleep(100 * time.Millisecond)
+
+		require.Empty(t, subscriber2.Activities())
+
+		publicKey := vocab.NewPublicKey(
+			vocab.WithID(publicKey1.ID.URL()),
+			vocab.WithOwner(service1IRI),
+			vocab.WithPublicKeyPem(publicKey1.PublicKeyPem+"\n"),
+		)
+
+		updatedActor1 := aptestutil.NewMockService(service1IRI, aptestutil.WithPublicKey(publicKey))
+
+		require.NoError(t, service1.UpdateActor(updatedActor1))
+
+		time.Sleep(500 * time.Millisecond)
+
+		a, err = store1.GetActor(service1IRI)
+		require.NoError(t, err)
+		require.Equal(t, publicKey.PublicKeyPem, a.PublicKey().PublicKeyPem)
+
+		a, err = store2.GetActor(service1IRI)
+		require.NoError(t, err)
+		require.Equal(t, publicKey.PublicKeyPem, a.PublicKey().PublicKeyPem)
+
+		activities := subscriber2.Activities()
+		require.Len(t, activities, 1)
+		require.True(t, activities[0].Type().Is(vocab.TypeUpdate))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		activityID, err := service1.RetireActor()
+		require.NoError(t, err)
+		require.NotNil(t, activityID)
+
+		time.Sleep(500 * time.Millisecond)
+
+		_, err = store2.GetActor(service1IRI)
+		require.True(t, errors.Is(err, spi.ErrNotFound))
+
+		it, err := store2.QueryReferences(spi.Following, spi.NewCriteria(spi.WithObjectIRI(service2IRI)))
+		require.NoError(t, err)
+
+		refs, err := storeutil.ReadReferences(it, -1)
+		require.NoError(t, err)
+		require.Empty(t, refs)
+	})
+}
+
 func newServiceWithMocks(t *testing.T, endpoint string,
 	serviceIRI *url.URL) (*Service, spi.Store, *vocab.PublicKeyType, *mockProviders) {
 	t.Helper()
@@ -42,10 +42,15 @@ type AnchorCredentialHandler interface {
 	HandleAnchorCredential(id *url.URL, cid string, anchorCred []byte) error
 }
 
-// ActorAuth makes the decision of whether or not a request by the given
+// ActorAuth makes the decision of whether or not a request (activity) by the given
 // actor should be accepted.
 type ActorAuth interface {
-	AuthorizeActor(actor *vocab.ActorType) (bool, error)
+	AuthorizeActor(actor *vocab.ActorType, activity *vocab.ActivityType) (bool, error)
+}
+
+// Blocklist decides whether or not activities from the given actor should be refused.
+type Blocklist interface {
+	IsBlocked(actorIRI *url.URL) (bool, error)
 }
 
 // WitnessHandler is a handler that witnesses an anchor credential.
@@ -82,6 +87,7 @@ type Handlers struct {
 	WitnessInvitationAuth   ActorAuth
 	Witness                 WitnessHandler
 	ProofHandler            ProofHandler
+	Blocklist               Blocklist
 }
 
 // HandlerOpt sets a specific handler.
@@ -128,3 +134,10 @@ func WithProofHandler(handler ProofHandler) HandlerOpt {
 		options.ProofHandler = handler
 	}
 }
+
+// WithBlocklist sets the blocklist that's checked before an activity in the inbox is processed.
+func WithBlocklist(blocklist Blocklist) HandlerOpt {
+	return func(options *Handlers) {
+		options.Blocklist = blocklist
+	}
+}
@@ -97,6 +97,18 @@ func (s *Provider) GetActor(iri *url.URL) (*vocab.ActorType, error) { //nolint:
 	return &actor, nil
 }
 
+// DeleteActor deletes the actor for the given IRI. No error is returned if the actor is not in the store.
+func (s *Provider) DeleteActor(iri *url.URL) error {
+	logger.Debugf("[%s] Deleting actor [%s]", s.serviceName, iri)
+
+	err := s.actorStore.Delete(iri.String())
+	if err != nil && !errors.Is(err, ariesstorage.ErrDataNotFound) {
+		return orberrors.NewTransient(fmt.Errorf("failed to delete actor: %w", err))
+	}
+
+	return nil
+}
+
 // AddActivity adds the given activity to the activity store.
 func (s *Provider) AddActivity(activity *vocab.ActivityType) error {
 	logger.Debugf("[%s] Storing activity - Type: %s, ID: %s",
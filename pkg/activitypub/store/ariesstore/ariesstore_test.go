@@ -416,6 +416,14 @@ func TestStore_Actors(t *testing.T) {
 		require.NoError(t, err)
 
 		require.Equal(t, string(expectedActor2Bytes), string(receivedActor2Bytes))
+
+		require.NoError(t, s.DeleteActor(actor1IRI))
+
+		a, err = s.GetActor(actor1IRI)
+		require.EqualError(t, err, spi.ErrNotFound.Error())
+		require.Nil(t, a)
+
+		require.NoError(t, s.DeleteActor(actor1IRI))
 	})
 	t.Run("Fail to put actor", func(t *testing.T) {
 		provider, err := ariesstore.New(&mock.Provider{
@@ -441,6 +449,18 @@ func TestStore_Actors(t *testing.T) {
 		_, err = provider.GetActor(testutil.MustParseURL("https://actor1"))
 		require.EqualError(t, err, "unexpected failure while getting actor from store: get error")
 	})
+	t.Run("Fail to delete actor", func(t *testing.T) {
+		provider, err := ariesstore.New(&mock.Provider{
+			OpenStoreReturn: &mock.Store{
+				ErrDelete: errors.New("delete error"),
+			},
+		},
+			"ServiceName")
+		require.NoError(t, err)
+
+		err = provider.DeleteActor(testutil.MustParseURL("https://actor1"))
+		require.EqualError(t, err, "failed to delete actor: delete error")
+	})
 }
 
 func TestStore_Reference(t *testing.T) {
@@ -79,6 +79,18 @@ func (s *Store) GetActor(iri *url.URL) (*vocab.ActorType, error) {
 	return a, nil
 }
 
+// DeleteActor deletes the actor for the given IRI. No error is returned if the actor is not in the store.
+func (s *Store) DeleteActor(iri *url.URL) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	logger.Debugf("[%s] Deleting actor [%s]", s.serviceName, iri)
+
+	delete(s.actorStore, iri.String())
+
+	return nil
+}
+
 // AddActivity adds the given activity to the activity store.
 func (s *Store) AddActivity(activity *vocab.ActivityType) error {
 	logger.Debugf("[%s] Storing activity - Type: %s, ID: %s",
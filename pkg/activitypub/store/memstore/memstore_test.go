@@ -184,6 +184,14 @@ func TestStore_Actors(t *testing.T) {
 	a, err = s.GetActor(actor2IRI)
 	require.NoError(t, err)
 	require.Equal(t, actor2, a)
+
+	require.NoError(t, s.DeleteActor(actor1IRI))
+
+	a, err = s.GetActor(actor1IRI)
+	require.EqualError(t, err, spi.ErrNotFound.Error())
+	require.Nil(t, a)
+
+	require.NoError(t, s.DeleteActor(actor1IRI))
 }
 
 func checkQueryResults(t *testing.T, it spi.ActivityIterator, expectedTypes ...*url.URL) {
@@ -56,6 +56,8 @@ type Store interface {
 	PutActor(actor *vocab.ActorType) error
 	// GetActor returns the actor for the given IRI. Returns an ErrNotFound error if the actor is not in the store.
 	GetActor(actorIRI *url.URL) (*vocab.ActorType, error)
+	// DeleteActor deletes the actor for the given IRI. No error is returned if the actor is not in the store.
+	DeleteActor(actorIRI *url.URL) error
 	// AddActivity adds the given activity to the activity store.
 	AddActivity(activity *vocab.ActivityType) error
 	// GetActivity returns the activity for the given ID from the given activity store
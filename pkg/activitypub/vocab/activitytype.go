@@ -22,6 +22,8 @@ type activityType struct {
 	Target *ObjectProperty `json:"target,omitempty"`
 	Object *ObjectProperty `json:"object,omitempty"`
 	Result *ObjectProperty `json:"result,omitempty"`
+
+	InvitationToken string `json:"invitationToken,omitempty"`
 }
 
 // Actor returns the actor for the activity.
@@ -65,6 +67,16 @@ func (t *ActivityType) Result() *ObjectProperty {
 	return t.activity.Result
 }
 
+// InvitationToken returns the (optional) pre-shared token that was included in a 'Follow'
+// or 'InviteWitness' activity.
+func (t *ActivityType) InvitationToken() string {
+	if t == nil || t.activity == nil {
+		return ""
+	}
+
+	return t.activity.InvitationToken
+}
+
 // MarshalJSON marshals the activity.
 func (t *ActivityType) MarshalJSON() ([]byte, error) {
 	return MarshalJSON(t.ObjectType, t.activity)
@@ -129,8 +141,9 @@ func NewFollowActivity(obj *ObjectProperty, opts ...Opt) *ActivityType {
 			WithTo(options.To...),
 		),
 		activity: &activityType{
-			Actor:  NewURLProperty(options.Actor),
-			Object: obj,
+			Actor:           NewURLProperty(options.Actor),
+			Object:          obj,
+			InvitationToken: options.InvitationToken,
 		},
 	}
 }
@@ -147,9 +160,10 @@ func NewInviteActivity(obj *ObjectProperty, opts ...Opt) *ActivityType {
 			WithTo(options.To...),
 		),
 		activity: &activityType{
-			Actor:  NewURLProperty(options.Actor),
-			Object: obj,
-			Target: options.Target,
+			Actor:           NewURLProperty(options.Actor),
+			Object:          obj,
+			Target:          options.Target,
+			InvitationToken: options.InvitationToken,
 		},
 	}
 }
@@ -250,3 +264,60 @@ func NewUndoActivity(obj *ObjectProperty, opts ...Opt) *ActivityType {
 		},
 	}
 }
+
+// NewUpdateActivity returns a new 'Update' activity.
+func NewUpdateActivity(obj *ObjectProperty, opts ...Opt) *ActivityType {
+	options := NewOptions(opts...)
+
+	return &ActivityType{
+		ObjectType: NewObject(
+			WithContext(getContexts(options, ContextActivityStreams)...),
+			WithID(options.ID),
+			WithType(TypeUpdate),
+			WithTo(options.To...),
+			WithPublishedTime(options.Published),
+		),
+		activity: &activityType{
+			Actor:  NewURLProperty(options.Actor),
+			Object: obj,
+		},
+	}
+}
+
+// NewDeleteActivity returns a new 'Delete' activity.
+func NewDeleteActivity(obj *ObjectProperty, opts ...Opt) *ActivityType {
+	options := NewOptions(opts...)
+
+	return &ActivityType{
+		ObjectType: NewObject(
+			WithContext(getContexts(options, ContextActivityStreams)...),
+			WithID(options.ID),
+			WithType(TypeDelete),
+			WithTo(options.To...),
+			WithPublishedTime(options.Published),
+		),
+		activity: &activityType{
+			Actor:  NewURLProperty(options.Actor),
+			Object: obj,
+		},
+	}
+}
+
+// NewBlockActivity returns a new 'Block' activity.
+func NewBlockActivity(obj *ObjectProperty, opts ...Opt) *ActivityType {
+	options := NewOptions(opts...)
+
+	return &ActivityType{
+		ObjectType: NewObject(
+			WithContext(getContexts(options, ContextActivityStreams)...),
+			WithID(options.ID),
+			WithType(TypeBlock),
+			WithTo(options.To...),
+			WithPublishedTime(options.Published),
+		),
+		activity: &activityType{
+			Actor:  NewURLProperty(options.Actor),
+			Object: obj,
+		},
+	}
+}
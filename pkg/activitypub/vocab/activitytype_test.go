@@ -31,6 +31,9 @@ var (
 	rejectActivityID  = newMockID(service1, "/activities/75b3d005-abb6-473d-a879-18bc1ee84979")
 	offerActivityID   = newMockID(service1, "/activities/65b3d005-6bb6-673d-6879-18bc1ee84976")
 	undoActivityID    = newMockID(service1, "/activities/77bcd005-abb6-433d-a889-18bc1ce64981")
+	updateActivityID  = newMockID(service1, "/activities/1b5cd005-abb6-433d-a889-18bc1ce64983")
+	deleteActivityID  = newMockID(service1, "/activities/2c6cd005-abb6-433d-a889-18bc1ce64984")
+	blockActivityID   = newMockID(service1, "/activities/3d7cd005-abb6-433d-a889-18bc1ce64985")
 	likeActivityID    = newMockID(witness1, "/likes/87bcd005-abb6-433d-a889-18bc1ce84988")
 )
 
@@ -553,6 +556,25 @@ func TestFollowTypeMarshal(t *testing.T) {
 		require.NotNil(t, objProp)
 		require.NotNil(t, objProp.IRI())
 		require.Equal(t, org2Service.String(), objProp.IRI().String())
+		require.Empty(t, a.InvitationToken())
+	})
+
+	t.Run("Invitation token", func(t *testing.T) {
+		follow := NewFollowActivity(
+			NewObjectProperty(WithIRI(org2Service)),
+			WithID(followActivityID),
+			WithActor(org1Service),
+			WithTo(org2Service),
+			WithInvitationToken("token1"),
+		)
+
+		bytes, err := json.Marshal(follow)
+		require.NoError(t, err)
+		require.Contains(t, string(bytes), `"invitationToken":"token1"`)
+
+		a := &ActivityType{}
+		require.NoError(t, json.Unmarshal(bytes, a))
+		require.Equal(t, "token1", a.InvitationToken())
 	})
 }
 
@@ -1052,6 +1074,103 @@ func TestUndoTypeMarshal(t *testing.T) {
 	})
 }
 
+func TestUpdateTypeMarshal(t *testing.T) {
+	published := getStaticTime()
+
+	t.Run("Marshal", func(t *testing.T) {
+		update := NewUpdateActivity(
+			NewObjectProperty(WithActorObject(newMockService(service1))),
+			WithID(updateActivityID),
+			WithActor(service1),
+			WithTo(witness1),
+			WithPublishedTime(&published),
+		)
+
+		bytes, err := canonicalizer.MarshalCanonical(update)
+		require.NoError(t, err)
+		t.Log(string(bytes))
+
+		require.Equal(t, testutil.GetCanonical(t, jsonUpdate), string(bytes))
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		a := &ActivityType{}
+		require.NoError(t, json.Unmarshal([]byte(jsonUpdate), a))
+		require.NotNil(t, a.Type())
+		require.True(t, a.Type().Is(TypeUpdate))
+		require.Equal(t, updateActivityID.String(), a.ID().String())
+		require.Equal(t, service1.String(), a.Actor().String())
+
+		require.True(t, a.Object().Type().Is(TypeService))
+
+		actor := a.Object().Actor()
+		require.NotNil(t, actor)
+		require.Equal(t, service1.String(), actor.ID().String())
+		require.NotNil(t, actor.PublicKey())
+		require.Equal(t, "-----BEGIN PUBLIC KEY-----\nMIIBIjANBgkqhki....", actor.PublicKey().PublicKeyPem)
+	})
+}
+
+func TestDeleteTypeMarshal(t *testing.T) {
+	published := getStaticTime()
+
+	t.Run("Marshal", func(t *testing.T) {
+		del := NewDeleteActivity(
+			NewObjectProperty(WithIRI(service1)),
+			WithID(deleteActivityID),
+			WithActor(service1),
+			WithTo(witness1),
+			WithPublishedTime(&published),
+		)
+
+		bytes, err := canonicalizer.MarshalCanonical(del)
+		require.NoError(t, err)
+		t.Log(string(bytes))
+
+		require.Equal(t, testutil.GetCanonical(t, jsonDelete), string(bytes))
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		a := &ActivityType{}
+		require.NoError(t, json.Unmarshal([]byte(jsonDelete), a))
+		require.NotNil(t, a.Type())
+		require.True(t, a.Type().Is(TypeDelete))
+		require.Equal(t, deleteActivityID.String(), a.ID().String())
+		require.Equal(t, service1.String(), a.Actor().String())
+		require.Equal(t, service1.String(), a.Object().IRI().String())
+	})
+}
+
+func TestBlockTypeMarshal(t *testing.T) {
+	published := getStaticTime()
+
+	t.Run("Marshal", func(t *testing.T) {
+		block := NewBlockActivity(
+			NewObjectProperty(WithIRI(witness1)),
+			WithID(blockActivityID),
+			WithActor(service1),
+			WithTo(witness1),
+			WithPublishedTime(&published),
+		)
+
+		bytes, err := canonicalizer.MarshalCanonical(block)
+		require.NoError(t, err)
+		t.Log(string(bytes))
+
+		require.Equal(t, testutil.GetCanonical(t, jsonBlock), string(bytes))
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		a := &ActivityType{}
+		require.NoError(t, json.Unmarshal([]byte(jsonBlock), a))
+		require.NotNil(t, a.Type())
+		require.True(t, a.Type().Is(TypeBlock))
+		require.Equal(t, blockActivityID.String(), a.ID().String())
+		require.Equal(t, service1.String(), a.Actor().String())
+		require.Equal(t, witness1.String(), a.Object().IRI().String())
+	})
+}
+
 func TestActivityType_Accessors(t *testing.T) {
 	a := &ActivityType{}
 
@@ -1070,6 +1189,26 @@ func TestActivityType_Accessors(t *testing.T) {
 	require.Nil(t, a.StartTime())
 	require.Nil(t, a.EndTime())
 	require.Nil(t, a.To())
+	require.Empty(t, a.InvitationToken())
+}
+
+func newMockService(serviceIRI *url.URL) *ActorType {
+	return NewService(serviceIRI,
+		WithPublicKey(NewPublicKey(
+			WithID(newMockID(serviceIRI, "/keys/main-key")),
+			WithOwner(serviceIRI),
+			WithPublicKeyPem("-----BEGIN PUBLIC KEY-----\nMIIBIjANBgkqhki...."),
+		)),
+		WithInbox(newMockID(serviceIRI, "/inbox")),
+		WithOutbox(newMockID(serviceIRI, "/outbox")),
+		WithFollowers(newMockID(serviceIRI, "/followers")),
+		WithFollowing(newMockID(serviceIRI, "/following")),
+		WithWitnesses(newMockID(serviceIRI, "/witnesses")),
+		WithWitnessing(newMockID(serviceIRI, "/witnessing")),
+		WithLiked(newMockID(serviceIRI, "/liked")),
+		WithLikes(newMockID(serviceIRI, "/likes")),
+		WithShares(newMockID(serviceIRI, "/shares")),
+	)
 }
 
 func newMockID(serviceIRI fmt.Stringer, path string) *url.URL {
@@ -1508,6 +1647,58 @@ const (
   "type": "Undo"
 }`
 
+	jsonUpdate = `{
+  "@context": "https://www.w3.org/ns/activitystreams",
+  "actor": "https://sally.example.com/services/orb",
+  "id": "https://sally.example.com/services/orb/activities/1b5cd005-abb6-433d-a889-18bc1ce64983",
+  "object": {
+    "@context": [
+      "https://www.w3.org/ns/activitystreams",
+      "https://w3id.org/security/v1",
+      "https://w3id.org/activityanchors/v1"
+    ],
+    "id": "https://sally.example.com/services/orb",
+    "type": "Service",
+    "publicKey": {
+      "id": "https://sally.example.com/services/orb/keys/main-key",
+      "owner": "https://sally.example.com/services/orb",
+      "publicKeyPem": "-----BEGIN PUBLIC KEY-----\nMIIBIjANBgkqhki...."
+    },
+    "inbox": "https://sally.example.com/services/orb/inbox",
+    "outbox": "https://sally.example.com/services/orb/outbox",
+    "followers": "https://sally.example.com/services/orb/followers",
+    "following": "https://sally.example.com/services/orb/following",
+    "witnesses": "https://sally.example.com/services/orb/witnesses",
+    "witnessing": "https://sally.example.com/services/orb/witnessing",
+    "liked": "https://sally.example.com/services/orb/liked",
+    "likes": "https://sally.example.com/services/orb/likes",
+    "shares": "https://sally.example.com/services/orb/shares"
+  },
+  "published": "2021-01-27T09:30:10Z",
+  "to": "https://witness1.example.com/services/orb",
+  "type": "Update"
+}`
+
+	jsonDelete = `{
+  "@context": "https://www.w3.org/ns/activitystreams",
+  "actor": "https://sally.example.com/services/orb",
+  "id": "https://sally.example.com/services/orb/activities/2c6cd005-abb6-433d-a889-18bc1ce64984",
+  "object": "https://sally.example.com/services/orb",
+  "published": "2021-01-27T09:30:10Z",
+  "to": "https://witness1.example.com/services/orb",
+  "type": "Delete"
+}`
+
+	jsonBlock = `{
+  "@context": "https://www.w3.org/ns/activitystreams",
+  "actor": "https://sally.example.com/services/orb",
+  "id": "https://sally.example.com/services/orb/activities/3d7cd005-abb6-433d-a889-18bc1ce64985",
+  "object": "https://witness1.example.com/services/orb",
+  "published": "2021-01-27T09:30:10Z",
+  "to": "https://witness1.example.com/services/orb",
+  "type": "Block"
+}`
+
 	jsonInviteWitness = `{
   "@context": [
     "https://www.w3.org/ns/activitystreams",
@@ -21,6 +21,7 @@ type ObjectProperty struct {
 	orderedColl   *OrderedCollectionType
 	activity      *ActivityType
 	anchorCredRef *AnchorCredentialReferenceType
+	actor         *ActorType
 }
 
 // NewObjectProperty returns a new 'object' property with the given options.
@@ -34,6 +35,7 @@ func NewObjectProperty(opts ...Opt) *ObjectProperty {
 		orderedColl:   options.OrderedCollection,
 		activity:      options.Activity,
 		anchorCredRef: options.AnchorCredRef,
+		actor:         options.ActorObject,
 	}
 }
 
@@ -64,6 +66,10 @@ func (p *ObjectProperty) Type() *TypeProperty {
 		return p.anchorCredRef.Type()
 	}
 
+	if p.actor != nil {
+		return p.actor.Type()
+	}
+
 	return nil
 }
 
@@ -122,6 +128,15 @@ func (p *ObjectProperty) AnchorCredentialReference() *AnchorCredentialReferenceT
 	return p.anchorCredRef
 }
 
+// Actor returns the actor or nil if the actor is not set.
+func (p *ObjectProperty) Actor() *ActorType {
+	if p == nil {
+		return nil
+	}
+
+	return p.actor
+}
+
 // MarshalJSON marshals the 'object' property.
 func (p *ObjectProperty) MarshalJSON() ([]byte, error) {
 	if p.iri != nil {
@@ -148,6 +163,10 @@ func (p *ObjectProperty) MarshalJSON() ([]byte, error) {
 		return json.Marshal(p.anchorCredRef)
 	}
 
+	if p.actor != nil {
+		return json.Marshal(p.actor)
+	}
+
 	return nil, fmt.Errorf("nil object property")
 }
 
@@ -188,6 +207,9 @@ func (p *ObjectProperty) UnmarshalJSON(bytes []byte) error {
 	case obj.object.Type.Is(TypeAnchorCredentialRef):
 		err = p.unmarshalAnchorCredentialReference(bytes)
 
+	case obj.object.Type.Is(TypeService):
+		err = p.unmarshalActor(bytes)
+
 	default:
 		p.obj = obj
 	}
@@ -242,3 +264,15 @@ func (p *ObjectProperty) unmarshalAnchorCredentialReference(bytes []byte) error
 
 	return nil
 }
+
+func (p *ObjectProperty) unmarshalActor(bytes []byte) error {
+	a := &ActorType{}
+
+	if err := json.Unmarshal(bytes, &a); err != nil {
+		return err
+	}
+
+	p.actor = a
+
+	return nil
+}
@@ -35,6 +35,7 @@ func TestNewObjectProperty(t *testing.T) {
 		require.Nil(t, p.OrderedCollection())
 		require.Nil(t, p.Activity())
 		require.Nil(t, p.AnchorCredentialReference())
+		require.Nil(t, p.Actor())
 	})
 
 	t.Run("Empty", func(t *testing.T) {
@@ -46,6 +47,7 @@ func TestNewObjectProperty(t *testing.T) {
 		require.Nil(t, p.OrderedCollection())
 		require.Nil(t, p.Activity())
 		require.Nil(t, p.AnchorCredentialReference())
+		require.Nil(t, p.Actor())
 	})
 
 	t.Run("WithIRI", func(t *testing.T) {
@@ -121,6 +123,20 @@ func TestNewObjectProperty(t *testing.T) {
 		require.NotNil(t, collContext)
 		require.True(t, collContext.Contains(ContextActivityStreams))
 	})
+
+	t.Run("WithActorObject", func(t *testing.T) {
+		p := NewObjectProperty(WithActorObject(newMockService(service1)))
+		require.NotNil(t, p)
+
+		typeProp := p.Type()
+		require.Nil(t, p.IRI())
+		require.NotNil(t, typeProp)
+		require.True(t, typeProp.Is(TypeService))
+
+		actor := p.Actor()
+		require.NotNil(t, actor)
+		require.Equal(t, service1.String(), actor.ID().String())
+	})
 }
 
 func TestObjectProperty_MarshalJSON(t *testing.T) {
@@ -183,6 +183,7 @@ type ObjectPropertyOptions struct {
 	OrderedCollection *OrderedCollectionType
 	Activity          *ActivityType
 	AnchorCredRef     *AnchorCredentialReferenceType
+	ActorObject       *ActorType
 }
 
 // WithIRI sets the 'object' property to an IRI.
@@ -227,11 +228,19 @@ func WithAnchorCredentialReference(ref *AnchorCredentialReferenceType) Opt {
 	}
 }
 
+// WithActorObject sets the 'object' property to an embedded actor.
+func WithActorObject(actor *ActorType) Opt {
+	return func(opts *Options) {
+		opts.ActorObject = actor
+	}
+}
+
 // ActivityOptions holds the options for an Activity.
 type ActivityOptions struct {
-	Result *ObjectProperty
-	Actor  *url.URL
-	Target *ObjectProperty
+	Result          *ObjectProperty
+	Actor           *url.URL
+	Target          *ObjectProperty
+	InvitationToken string
 }
 
 // WithActor sets the 'actor' property on the activity.
@@ -255,6 +264,13 @@ func WithResult(result *ObjectProperty) Opt {
 	}
 }
 
+// WithInvitationToken sets the pre-shared 'invitationToken' property on a 'Follow' or 'InviteWitness' activity.
+func WithInvitationToken(token string) Opt {
+	return func(opts *Options) {
+		opts.InvitationToken = token
+	}
+}
+
 // ActorOptions holds the options for an Activity.
 type ActorOptions struct {
 	PublicKey  *PublicKeyType
@@ -74,6 +74,12 @@ const (
 	TypeOffer Type = "Offer"
 	// TypeUndo specifies the "Undo" activity type.
 	TypeUndo Type = "Undo"
+	// TypeUpdate specifies the "Update" activity type.
+	TypeUpdate Type = "Update"
+	// TypeDelete specifies the "Delete" activity type.
+	TypeDelete Type = "Delete"
+	// TypeBlock specifies the "Block" activity type.
+	TypeBlock Type = "Block"
 )
 
 const (
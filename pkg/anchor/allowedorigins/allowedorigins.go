@@ -0,0 +1,194 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package allowedorigins
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/versions/1_0/operationparser/validators/anchororigin"
+)
+
+const (
+	// AllowedOriginsKey is the key of the allowed anchor origins in the config store.
+	AllowedOriginsKey = "allowed-origins"
+
+	defaultCacheSize = 10
+)
+
+var logger = log.New("allowed-origins")
+
+// ErrInvalidOrigin is returned when an allowed origin is invalid.
+var ErrInvalidOrigin = errors.New("invalid allowed origin")
+
+type gCache interface {
+	Get(key interface{}) (interface{}, error)
+	Remove(key interface{}) bool
+}
+
+// AllowedOrigins manages the list of allowed anchor origins. The list is persisted in the config store
+// and cached so that changes made by other server instances are picked up after the cache expires.
+type AllowedOrigins struct {
+	configStore    storage.Store
+	defaultOrigins []string
+	cacheExpiry    time.Duration
+	cache          gCache
+	mutex          sync.Mutex
+}
+
+// New returns a new allowed origins manager. The given default origins are used until the list of allowed
+// origins is updated in the config store.
+func New(configStore storage.Store, defaultOrigins []string, cacheExpiry time.Duration) *AllowedOrigins {
+	a := &AllowedOrigins{
+		configStore:    configStore,
+		defaultOrigins: defaultOrigins,
+		cacheExpiry:    cacheExpiry,
+	}
+
+	a.cache = gcache.New(defaultCacheSize).ARC().LoaderExpireFunc(a.load).Build()
+
+	return a
+}
+
+// IsAllowed returns true if the given anchor origin matches one of the allowed origins.
+func (a *AllowedOrigins) IsAllowed(origin string) (bool, error) {
+	origins, err := a.getCached()
+	if err != nil {
+		return false, err
+	}
+
+	return anchororigin.StaticOrigins(origins).IsAllowed(origin)
+}
+
+// Get returns the allowed origins, sorted.
+func (a *AllowedOrigins) Get() ([]string, error) {
+	origins, err := a.getCached()
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := append([]string{}, origins...)
+
+	sort.Strings(sorted)
+
+	return sorted, nil
+}
+
+// Update adds and removes the given origins to/from the list of allowed origins. ErrInvalidOrigin is returned
+// if any of the origins to add is invalid, in which case the list is not updated.
+func (a *AllowedOrigins) Update(add, remove []string) error {
+	for _, origin := range add {
+		if err := anchororigin.ValidatePattern(origin); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidOrigin, err)
+		}
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	// Load the origins from the store (not from the cache) in case they were changed by another instance.
+	origins, err := a.loadOrigins()
+	if err != nil {
+		return err
+	}
+
+	updated := merge(origins, add, remove)
+
+	originsBytes, err := json.Marshal(updated)
+	if err != nil {
+		return fmt.Errorf("marshal allowed origins: %w", err)
+	}
+
+	err = a.configStore.Put(AllowedOriginsKey, originsBytes)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store allowed origins: %w", err))
+	}
+
+	a.cache.Remove(AllowedOriginsKey)
+
+	logger.Infof("Updated allowed origins - Added: %s, Removed: %s", add, remove)
+
+	return nil
+}
+
+func (a *AllowedOrigins) getCached() ([]string, error) {
+	value, err := a.cache.Get(AllowedOriginsKey)
+	if err != nil {
+		return nil, fmt.Errorf("get allowed origins from cache: %w", err)
+	}
+
+	origins, ok := value.([]string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected interface '%T' for allowed origins in cache", value)
+	}
+
+	return origins, nil
+}
+
+func (a *AllowedOrigins) load(interface{}) (interface{}, *time.Duration, error) {
+	origins, err := a.loadOrigins()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger.Debugf("Loaded allowed origins: %s", origins)
+
+	return origins, &a.cacheExpiry, nil
+}
+
+func (a *AllowedOrigins) loadOrigins() ([]string, error) {
+	originsBytes, err := a.configStore.Get(AllowedOriginsKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return a.defaultOrigins, nil
+		}
+
+		return nil, orberrors.NewTransient(fmt.Errorf("load allowed origins: %w", err))
+	}
+
+	var origins []string
+
+	err = json.Unmarshal(originsBytes, &origins)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal allowed origins: %w", err)
+	}
+
+	return origins, nil
+}
+
+func merge(origins, add, remove []string) []string {
+	removed := make(map[string]bool)
+
+	for _, origin := range remove {
+		removed[origin] = true
+	}
+
+	exists := make(map[string]bool)
+
+	updated := []string{}
+
+	for _, origin := range append(append([]string{}, origins...), add...) {
+		if removed[origin] || exists[origin] {
+			continue
+		}
+
+		exists[origin] = true
+
+		updated = append(updated, origin)
+	}
+
+	return updated
+}
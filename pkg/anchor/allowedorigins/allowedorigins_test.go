@@ -0,0 +1,149 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package allowedorigins
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mock"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	origin1 = "https://orb.domain1.com"
+	origin2 = "https://orb.domain2.com"
+	origin3 = "https://orb.sub.domain3.com"
+)
+
+func TestAllowedOrigins(t *testing.T) {
+	t.Run("Default origins", func(t *testing.T) {
+		a := New(newConfigStore(t), []string{origin2, origin1}, time.Minute)
+
+		origins, err := a.Get()
+		require.NoError(t, err)
+		require.Equal(t, []string{origin1, origin2}, origins)
+
+		allowed, err := a.IsAllowed(origin1)
+		require.NoError(t, err)
+		require.True(t, allowed)
+
+		allowed, err = a.IsAllowed(origin3)
+		require.NoError(t, err)
+		require.False(t, allowed)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		configStore := newConfigStore(t)
+
+		a := New(configStore, []string{origin1, origin2}, time.Minute)
+
+		require.NoError(t, a.Update([]string{"*.domain3.com", origin1}, []string{origin2}))
+
+		origins, err := a.Get()
+		require.NoError(t, err)
+		require.Equal(t, []string{"*.domain3.com", origin1}, origins)
+
+		allowed, err := a.IsAllowed(origin3)
+		require.NoError(t, err)
+		require.True(t, allowed)
+
+		allowed, err = a.IsAllowed(origin2)
+		require.NoError(t, err)
+		require.False(t, allowed)
+
+		// Another instance picks up the persisted origins instead of its defaults.
+		origins, err = New(configStore, []string{origin2}, time.Minute).Get()
+		require.NoError(t, err)
+		require.Equal(t, []string{"*.domain3.com", origin1}, origins)
+
+		require.NoError(t, a.Update(nil, []string{"*.domain3.com", origin1}))
+
+		origins, err = a.Get()
+		require.NoError(t, err)
+		require.Empty(t, origins)
+	})
+
+	t.Run("Cache expiry", func(t *testing.T) {
+		configStore := newConfigStore(t)
+
+		a1 := New(configStore, nil, 50*time.Millisecond)
+		a2 := New(configStore, nil, 50*time.Millisecond)
+
+		allowed, err := a1.IsAllowed(origin1)
+		require.NoError(t, err)
+		require.False(t, allowed)
+
+		require.NoError(t, a2.Update([]string{origin1}, nil))
+
+		time.Sleep(100 * time.Millisecond)
+
+		allowed, err = a1.IsAllowed(origin1)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	})
+
+	t.Run("Invalid origin", func(t *testing.T) {
+		a := New(newConfigStore(t), nil, time.Minute)
+
+		err := a.Update([]string{origin1, "https://orb.*.com"}, nil)
+		require.True(t, errors.Is(err, ErrInvalidOrigin))
+
+		origins, err := a.Get()
+		require.NoError(t, err)
+		require.Empty(t, origins)
+	})
+
+	t.Run("Store get error", func(t *testing.T) {
+		errExpected := errors.New("injected get error")
+
+		a := New(&mock.Store{ErrGet: errExpected}, nil, time.Minute)
+
+		_, err := a.IsAllowed(origin1)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+
+		_, err = a.Get()
+		require.True(t, orberrors.IsTransient(err))
+
+		err = a.Update([]string{origin1}, nil)
+		require.True(t, orberrors.IsTransient(err))
+	})
+
+	t.Run("Store put error", func(t *testing.T) {
+		errExpected := errors.New("injected put error")
+
+		a := New(&mock.Store{ErrPut: errExpected, ErrGet: storage.ErrDataNotFound}, nil, time.Minute)
+
+		err := a.Update([]string{origin1}, nil)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Invalid stored origins", func(t *testing.T) {
+		configStore := newConfigStore(t)
+		require.NoError(t, configStore.Put(AllowedOriginsKey, []byte("{")))
+
+		_, err := New(configStore, nil, time.Minute).IsAllowed(origin1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal allowed origins")
+	})
+}
+
+func newConfigStore(t *testing.T) storage.Store {
+	t.Helper()
+
+	s, err := mem.NewProvider().OpenStore("orb-config")
+	require.NoError(t, err)
+
+	return s
+}
@@ -0,0 +1,177 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package allowedorigins
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+)
+
+const endpoint = "/allowedorigins"
+
+const (
+	badRequestResponse          = "Bad Request."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+type allowedOriginsManager interface {
+	Get() ([]string, error)
+	Update(add, remove []string) error
+}
+
+// UpdateRequest is the request body of the allowed origins update endpoint.
+type UpdateRequest struct {
+	// Add contains the origins, domain wildcards (e.g. *.domain1.com) or '*' to add to the allowed origins.
+	Add []string `json:"add,omitempty"`
+	// Remove contains the origins to remove from the allowed origins.
+	Remove []string `json:"remove,omitempty"`
+}
+
+// Retriever returns the allowed anchor origins.
+type Retriever struct {
+	manager allowedOriginsManager
+	marshal func(v interface{}) ([]byte, error)
+}
+
+// NewRetriever returns a new allowed origins Retriever.
+func NewRetriever(m allowedOriginsManager) *Retriever {
+	return &Retriever{
+		manager: m,
+		marshal: json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the Retriever service.
+func (r *Retriever) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the Retriever service.
+func (r *Retriever) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the Retriever service.
+func (r *Retriever) Handler() common.HTTPRequestHandler {
+	return r.handle
+}
+
+func (r *Retriever) handle(w http.ResponseWriter, _ *http.Request) {
+	origins, err := r.manager.Get()
+	if err != nil {
+		logger.Errorf("[%s] Error retrieving allowed origins: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	originsBytes, err := r.marshal(origins)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling allowed origins: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, originsBytes)
+}
+
+// Updater adds and removes allowed anchor origins.
+type Updater struct {
+	manager allowedOriginsManager
+}
+
+// NewUpdater returns a new allowed origins Updater.
+func NewUpdater(m allowedOriginsManager) *Updater {
+	return &Updater{
+		manager: m,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the Updater service.
+func (u *Updater) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the Updater service.
+func (u *Updater) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the Updater service.
+func (u *Updater) Handler() common.HTTPRequestHandler {
+	return u.handle
+}
+
+func (u *Updater) handle(w http.ResponseWriter, req *http.Request) {
+	reqBytes, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		logger.Errorf("[%s] Error reading request body: %s", endpoint, err)
+
+		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	request := &UpdateRequest{}
+
+	err = json.Unmarshal(reqBytes, request)
+	if err != nil {
+		logger.Infof("[%s] Invalid request: %s", endpoint, err)
+
+		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	err = u.manager.Update(request.Add, request.Remove)
+	if err != nil {
+		if errors.Is(err, ErrInvalidOrigin) {
+			logger.Infof("[%s] Invalid origin: %s", endpoint, err)
+
+			writeResponse(w, http.StatusBadRequest, []byte(fmt.Sprintf("%s %s", badRequestResponse, err)))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error updating allowed origins: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	logger.Infof("[%s] Allowed origins updated on behalf of [%s] - Added: %s, Removed: %s",
+		endpoint, auth.TokenIDFromContext(req.Context()), request.Add, request.Remove)
+
+	writeResponse(w, http.StatusOK, nil)
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("[%s] Unable to write response: %s", endpoint, err)
+
+			return
+		}
+
+		logger.Debugf("[%s] Wrote response: %s", endpoint, body)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package allowedorigins
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetriever(t *testing.T) {
+	a := New(newConfigStore(t), []string{origin1}, time.Minute)
+
+	r := NewRetriever(a)
+	require.Equal(t, "/allowedorigins", r.Path())
+	require.Equal(t, http.MethodGet, r.Method())
+	require.NotNil(t, r.Handler())
+
+	t.Run("Success", func(t *testing.T) {
+		status, body := handle(t, r.handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		var origins []string
+		require.NoError(t, json.Unmarshal(body, &origins))
+		require.Equal(t, []string{origin1}, origins)
+	})
+
+	t.Run("Get error", func(t *testing.T) {
+		status, _ := handle(t, NewRetriever(&mockManager{err: errors.New("injected error")}).handle,
+			http.MethodGet, nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		r2 := NewRetriever(a)
+		r2.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		status, _ := handle(t, r2.handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func TestUpdater(t *testing.T) {
+	a := New(newConfigStore(t), []string{origin1}, time.Minute)
+
+	u := NewUpdater(a)
+	require.Equal(t, "/allowedorigins", u.Path())
+	require.Equal(t, http.MethodPost, u.Method())
+	require.NotNil(t, u.Handler())
+
+	t.Run("Success", func(t *testing.T) {
+		reqBytes, err := json.Marshal(&UpdateRequest{Add: []string{origin2}, Remove: []string{origin1}})
+		require.NoError(t, err)
+
+		status, _ := handle(t, u.handle, http.MethodPost, reqBytes)
+		require.Equal(t, http.StatusOK, status)
+
+		origins, err := a.Get()
+		require.NoError(t, err)
+		require.Equal(t, []string{origin2}, origins)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		status, _ := handle(t, u.handle, http.MethodPost, []byte("{"))
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("Invalid origin", func(t *testing.T) {
+		status, body := handle(t, u.handle, http.MethodPost, []byte(`{"add":["*."]}`))
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, string(body), "invalid domain wildcard")
+	})
+
+	t.Run("Update error", func(t *testing.T) {
+		status, _ := handle(t, NewUpdater(&mockManager{err: errors.New("injected error")}).handle,
+			http.MethodPost, []byte(`{}`))
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func handle(t *testing.T, h func(w http.ResponseWriter, req *http.Request), method string,
+	body []byte) (int, []byte) {
+	t.Helper()
+
+	rw := httptest.NewRecorder()
+
+	h(rw, httptest.NewRequest(method, endpoint, bytes.NewReader(body)))
+
+	result := rw.Result()
+
+	respBytes, err := ioutil.ReadAll(result.Body)
+	require.NoError(t, err)
+	require.NoError(t, result.Body.Close())
+
+	return result.StatusCode, respBytes
+}
+
+type mockManager struct {
+	err error
+}
+
+func (m *mockManager) Get() ([]string, error) {
+	return nil, m.err
+}
+
+func (m *mockManager) Update([]string, []string) error {
+	return m.err
+}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package graph
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 
@@ -16,7 +17,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
 
 	"github.com/trustbloc/orb/pkg/anchor/util"
-	"github.com/trustbloc/orb/pkg/errors"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 )
 
 var logger = log.New("anchor-graph")
@@ -64,7 +65,7 @@ func (g *Graph) Add(vc *verifiable.Credential) (string, error) { //nolint:interf
 
 	hl, err := g.CasWriter.Write(canonicalBytes)
 	if err != nil {
-		return "", errors.NewTransient(fmt.Errorf("failed to add anchor to graph: %w", err))
+		return "", orberrors.NewTransient(fmt.Errorf("failed to add anchor to graph: %w", err))
 	}
 
 	logger.Debugf("added anchor[%s]: %s", hl, string(canonicalBytes))
@@ -98,31 +99,63 @@ func (g *Graph) GetDidAnchors(hl, suffix string) ([]Anchor, error) {
 
 	logger.Debugf("getting did anchors for hl[%s], suffix[%s]", hl, suffix)
 
-	cur := hl
-	ok := true
+	it := g.NewDidAnchorIterator(hl, suffix)
 
-	for ok {
-		node, err := g.Read(cur)
+	for {
+		anchor, err := it.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to read anchor[%s] for did[%s]: %w", cur, suffix, err)
+			if errors.Is(err, ErrNoMoreAnchors) {
+				break
+			}
+
+			return nil, err
 		}
 
-		refs = append(refs, Anchor{Info: node, CID: cur})
+		refs = append(refs, *anchor)
+	}
+
+	return reverseOrder(refs), nil
+}
+
+// IsPreviousAnchor returns true if the anchor with hashlink prev is found by following the previous anchors of the
+// DID suffix, starting at (and including) the anchor with hashlink hl. Hashlinks are compared by resource hash.
+// At most maxDepth anchors are traversed; if maxDepth is zero then there is no limit.
+func (g *Graph) IsPreviousAnchor(hl, prev, suffix string, maxDepth int) (bool, error) {
+	it := g.NewDidAnchorIterator(hl, suffix, WithMaxDepth(maxDepth))
+
+	prevRef := resourceHashOrHL(prev)
 
-		payload, err := util.GetAnchorSubject(node)
+	for {
+		anchor, err := it.Next()
 		if err != nil {
-			return nil, err
-		}
+			if errors.Is(err, ErrNoMoreAnchors) {
+				return false, nil
+			}
 
-		previousAnchors := payload.PreviousAnchors
+			return false, err
+		}
 
-		cur, ok = previousAnchors[suffix]
-		if ok && cur == "" { // create
-			break
+		if resourceHashOrHL(anchor.CID) == prevRef {
+			return true, nil
 		}
 	}
+}
 
-	return reverseOrder(refs), nil
+// readDidAnchor reads the anchor with the given hashlink and returns the anchor along with the hashlink of
+// the previous anchor for the given suffix. An empty string is returned for the previous anchor if the
+// anchor is the first anchor (create) of the DID.
+func (g *Graph) readDidAnchor(hl, suffix string) (*Anchor, string, error) {
+	node, err := g.Read(hl)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read anchor[%s] for did[%s]: %w", hl, suffix, err)
+	}
+
+	payload, err := util.GetAnchorSubject(node)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &Anchor{Info: node, CID: hl}, payload.PreviousAnchors[suffix], nil
 }
 
 func reverseOrder(original []Anchor) []Anchor {
@@ -320,6 +320,9 @@ func (m *metricsProvider) CASWriteTime(value time.Duration) {
 func (m *metricsProvider) CASResolveTime(value time.Duration) {
 }
 
+func (m *metricsProvider) CASResolveSourceTime(source string, success bool, value time.Duration) {
+}
+
 func (m *metricsProvider) CASIncrementCacheHitCount() {
 }
 
@@ -0,0 +1,206 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graph
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+// ErrNoMoreAnchors is returned by the iterator when there are no more anchors.
+var ErrNoMoreAnchors = errors.New("no more anchors")
+
+// IteratorOption is an option for the DID anchor iterator.
+type IteratorOption func(opts *iteratorOptions)
+
+type iteratorOptions struct {
+	maxDepth int
+	stopAt   string
+	prefetch int
+}
+
+// WithMaxDepth sets the maximum number of anchors returned by the iterator. If zero (the default) then
+// the iterator traverses the graph until the first anchor (create) of the DID is reached.
+func WithMaxDepth(value int) IteratorOption {
+	return func(opts *iteratorOptions) {
+		opts.maxDepth = value
+	}
+}
+
+// WithStopAt stops the iteration when the anchor with the given hashlink is reached. The anchor with the
+// given hashlink is not returned.
+func WithStopAt(hl string) IteratorOption {
+	return func(opts *iteratorOptions) {
+		opts.stopAt = hl
+	}
+}
+
+// WithPrefetch sets the number of anchors that are read ahead (in the background) of the consumer of the
+// iterator. If zero (the default) then anchors are read on demand.
+func WithPrefetch(value int) IteratorOption {
+	return func(opts *iteratorOptions) {
+		opts.prefetch = value
+	}
+}
+
+// DidAnchorIterator traverses the anchor graph of a DID, starting at the given anchor and following the
+// previous anchors of the DID, i.e. anchors are returned in reverse chronological order.
+type DidAnchorIterator struct {
+	graph     *Graph
+	suffix    string
+	opts      *iteratorOptions
+	stopAtRef string
+	next      string
+	depth     int
+	err       error
+	results   chan *iteratorResult
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type iteratorResult struct {
+	anchor *Anchor
+	next   string
+	err    error
+}
+
+// NewDidAnchorIterator returns an iterator over the anchors of the given DID suffix, starting at the anchor
+// with the given hashlink. If prefetch is enabled then Close must be called when the iterator is no longer
+// needed.
+func (g *Graph) NewDidAnchorIterator(hl, suffix string, opts ...IteratorOption) *DidAnchorIterator {
+	options := &iteratorOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	it := &DidAnchorIterator{
+		graph:  g,
+		suffix: suffix,
+		opts:   options,
+		next:   hl,
+		done:   make(chan struct{}),
+	}
+
+	if options.stopAt != "" {
+		it.stopAtRef = resourceHashOrHL(options.stopAt)
+	}
+
+	if options.prefetch > 0 {
+		it.results = make(chan *iteratorResult, options.prefetch)
+
+		go it.prefetch()
+	}
+
+	return it
+}
+
+// Next returns the next anchor or an ErrNoMoreAnchors error if there are no more anchors.
+func (it *DidAnchorIterator) Next() (*Anchor, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+
+	var result *iteratorResult
+
+	if it.results != nil {
+		r, ok := <-it.results
+		if !ok {
+			return nil, ErrNoMoreAnchors
+		}
+
+		result = r
+	} else {
+		if !it.hasMore(it.next, it.depth) {
+			return nil, ErrNoMoreAnchors
+		}
+
+		result = it.read(it.next)
+	}
+
+	if result.err != nil {
+		it.err = result.err
+
+		return nil, result.err
+	}
+
+	it.depth++
+	it.next = result.next
+
+	return result.anchor, nil
+}
+
+// NextHashLink returns the hashlink of the next anchor that has not yet been returned by the iterator. This
+// value may be used to continue the traversal with a new iterator, for example, when paging through the
+// anchors of a DID. An empty string is returned if the first anchor of the DID (or the stop anchor) was reached.
+func (it *DidAnchorIterator) NextHashLink() string {
+	if it.next == "" || it.isStopAnchor(it.next) {
+		return ""
+	}
+
+	return it.next
+}
+
+// Close stops the prefetching of anchors.
+func (it *DidAnchorIterator) Close() {
+	it.closeOnce.Do(func() {
+		close(it.done)
+	})
+}
+
+func (it *DidAnchorIterator) prefetch() {
+	defer close(it.results)
+
+	cur := it.next
+
+	for depth := 0; it.hasMore(cur, depth); depth++ {
+		result := it.read(cur)
+
+		select {
+		case it.results <- result:
+		case <-it.done:
+			return
+		}
+
+		if result.err != nil {
+			return
+		}
+
+		cur = result.next
+	}
+}
+
+func (it *DidAnchorIterator) read(hl string) *iteratorResult {
+	anchor, next, err := it.graph.readDidAnchor(hl, it.suffix)
+
+	return &iteratorResult{anchor: anchor, next: next, err: err}
+}
+
+func (it *DidAnchorIterator) hasMore(hl string, depth int) bool {
+	if hl == "" || it.isStopAnchor(hl) {
+		return false
+	}
+
+	return it.opts.maxDepth == 0 || depth < it.opts.maxDepth
+}
+
+func (it *DidAnchorIterator) isStopAnchor(hl string) bool {
+	return it.stopAtRef != "" && resourceHashOrHL(hl) == it.stopAtRef
+}
+
+// resourceHashOrHL returns the resource hash of the given hashlink so that hashlinks with different
+// metadata may be compared. The hashlink itself is returned if it can't be parsed.
+func resourceHashOrHL(hl string) string {
+	info, err := hashlink.New().ParseHashLink(hl)
+	if err != nil {
+		return hl
+	}
+
+	return info.ResourceHash
+}
@@ -0,0 +1,234 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	casresolver "github.com/trustbloc/orb/pkg/cas/resolver"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	"github.com/trustbloc/orb/pkg/store/cas"
+	webfingerclient "github.com/trustbloc/orb/pkg/webfinger/client"
+)
+
+func TestDidAnchorIterator(t *testing.T) {
+	graph := newTestGraph(t)
+
+	// hls contains the hashlinks of the anchors in chronological order.
+	hls := addTestAnchors(t, graph, 5)
+	latest := hls[len(hls)-1]
+
+	t.Run("all anchors", func(t *testing.T) {
+		it := graph.NewDidAnchorIterator(latest, testDID)
+		defer it.Close()
+
+		require.Equal(t, reversed(hls), collect(t, it))
+		require.Empty(t, it.NextHashLink())
+
+		_, err := it.Next()
+		require.True(t, errors.Is(err, ErrNoMoreAnchors))
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		it := graph.NewDidAnchorIterator(latest, testDID, WithMaxDepth(2))
+		defer it.Close()
+
+		require.Equal(t, []string{hls[4], hls[3]}, collect(t, it))
+		require.Equal(t, hls[2], it.NextHashLink())
+
+		// Continue from where the previous iterator left off.
+		it2 := graph.NewDidAnchorIterator(it.NextHashLink(), testDID, WithMaxDepth(2))
+		defer it2.Close()
+
+		require.Equal(t, []string{hls[2], hls[1]}, collect(t, it2))
+		require.Equal(t, hls[0], it2.NextHashLink())
+	})
+
+	t.Run("stop at", func(t *testing.T) {
+		it := graph.NewDidAnchorIterator(latest, testDID, WithStopAt(hls[1]))
+		defer it.Close()
+
+		require.Equal(t, []string{hls[4], hls[3], hls[2]}, collect(t, it))
+		require.Empty(t, it.NextHashLink())
+	})
+
+	t.Run("stop at and max depth", func(t *testing.T) {
+		it := graph.NewDidAnchorIterator(latest, testDID, WithStopAt(hls[2]), WithMaxDepth(2))
+		defer it.Close()
+
+		require.Equal(t, []string{hls[4], hls[3]}, collect(t, it))
+		require.Empty(t, it.NextHashLink())
+	})
+
+	t.Run("stop at start", func(t *testing.T) {
+		it := graph.NewDidAnchorIterator(latest, testDID, WithStopAt(latest))
+		defer it.Close()
+
+		require.Empty(t, collect(t, it))
+	})
+
+	t.Run("prefetch", func(t *testing.T) {
+		it := graph.NewDidAnchorIterator(latest, testDID, WithPrefetch(2))
+		defer it.Close()
+
+		require.Equal(t, reversed(hls), collect(t, it))
+		require.Empty(t, it.NextHashLink())
+	})
+
+	t.Run("prefetch with max depth", func(t *testing.T) {
+		it := graph.NewDidAnchorIterator(latest, testDID, WithPrefetch(10), WithMaxDepth(3))
+		defer it.Close()
+
+		require.Equal(t, []string{hls[4], hls[3], hls[2]}, collect(t, it))
+		require.Equal(t, hls[1], it.NextHashLink())
+	})
+
+	t.Run("prefetch - close before all anchors are read", func(t *testing.T) {
+		it := graph.NewDidAnchorIterator(latest, testDID, WithPrefetch(1))
+
+		anchor, err := it.Next()
+		require.NoError(t, err)
+		require.Equal(t, latest, anchor.CID)
+
+		it.Close()
+		it.Close()
+	})
+
+	t.Run("error - anchor not found", func(t *testing.T) {
+		for _, prefetch := range []int{0, 2} {
+			it := graph.NewDidAnchorIterator("hl:"+nonExistent, testDID, WithPrefetch(prefetch))
+
+			_, err := it.Next()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "failed to read anchor")
+
+			// The error is returned on subsequent calls.
+			_, err2 := it.Next()
+			require.Equal(t, err, err2)
+
+			it.Close()
+		}
+	})
+}
+
+func TestGraph_IsPreviousAnchor(t *testing.T) {
+	graph := newTestGraph(t)
+
+	hls := addTestAnchors(t, graph, 5)
+	latest := hls[len(hls)-1]
+
+	t.Run("found", func(t *testing.T) {
+		found, err := graph.IsPreviousAnchor(latest, hls[0], testDID, 0)
+		require.NoError(t, err)
+		require.True(t, found)
+
+		found, err = graph.IsPreviousAnchor(latest, latest, testDID, 1)
+		require.NoError(t, err)
+		require.True(t, found)
+	})
+
+	t.Run("not found within max depth", func(t *testing.T) {
+		found, err := graph.IsPreviousAnchor(latest, hls[0], testDID, 4)
+		require.NoError(t, err)
+		require.False(t, found)
+
+		found, err = graph.IsPreviousAnchor(latest, hls[1], testDID, 4)
+		require.NoError(t, err)
+		require.True(t, found)
+	})
+
+	t.Run("successor is not a previous anchor", func(t *testing.T) {
+		found, err := graph.IsPreviousAnchor(hls[1], latest, testDID, 0)
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+
+	t.Run("error - anchor not found", func(t *testing.T) {
+		_, err := graph.IsPreviousAnchor("hl:"+nonExistent, hls[0], testDID, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read anchor")
+	})
+}
+
+func newTestGraph(t *testing.T) *Graph {
+	t.Helper()
+
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &metricsProvider{}, 0)
+	require.NoError(t, err)
+
+	return New(&Providers{
+		CasWriter: casClient,
+		CasResolver: casresolver.New(casClient, nil,
+			casresolver.NewWebCASResolver(
+				transport.Default(), webfingerclient.New(), "https"),
+			&metricsProvider{}),
+		Pkf:       pubKeyFetcherFnc,
+		DocLoader: testutil.GetLoader(t),
+	})
+}
+
+// addTestAnchors adds a chain of anchors for the test DID and returns their hashlinks in chronological order.
+func addTestAnchors(t *testing.T, graph *Graph, n int) []string {
+	t.Helper()
+
+	var hls []string
+
+	previous := ""
+
+	for i := 0; i < n; i++ {
+		c, err := buildCredential(&subject.Payload{
+			OperationCount:  1,
+			CoreIndex:       fmt.Sprintf("coreIndex-%d", i),
+			Namespace:       testNS,
+			Version:         1,
+			PreviousAnchors: map[string]string{testDID: previous},
+		})
+		require.NoError(t, err)
+
+		hl, err := graph.Add(c)
+		require.NoError(t, err)
+
+		hls = append(hls, hl)
+		previous = hl
+	}
+
+	return hls
+}
+
+func collect(t *testing.T, it *DidAnchorIterator) []string {
+	t.Helper()
+
+	hls := []string{}
+
+	for {
+		anchor, err := it.Next()
+		if errors.Is(err, ErrNoMoreAnchors) {
+			return hls
+		}
+
+		require.NoError(t, err)
+
+		hls = append(hls, anchor.CID)
+	}
+}
+
+func reversed(values []string) []string {
+	var r []string
+
+	for i := len(values) - 1; i >= 0; i-- {
+		r = append(r, values[i])
+	}
+
+	return r
+}
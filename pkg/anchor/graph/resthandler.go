@@ -0,0 +1,347 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graph
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/didanchor"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	suffixPathVariable = "suffix"
+
+	historyEndpoint = "/anchorgraph/{" + suffixPathVariable + "}"
+
+	fromParam  = "from"
+	toParam    = "to"
+	limitParam = "limit"
+
+	defaultPageSize = 25
+	maxPageSize     = 100
+	prefetchSize    = 5
+
+	// maxSearchDepth is the maximum number of anchors that are traversed (starting at the latest anchor)
+	// when verifying that a 'from' hashlink (that isn't a cursor) belongs to the DID.
+	maxSearchDepth = 1000
+
+	cursorSeparator = "."
+)
+
+var errInvalidFrom = errors.New("anchor is not in the history of the DID")
+
+const (
+	badRequestResponse          = "Bad Request."
+	notFoundResponse            = "Not Found."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+type didAnchorStore interface {
+	Get(suffix string) (string, error)
+}
+
+// HistoryPage contains a page of the anchor history of a DID.
+type HistoryPage struct {
+	Suffix string `json:"suffix"`
+	// Anchors contains the anchors of the page in reverse chronological order.
+	Anchors []*HistoryEntry `json:"anchors"`
+	// Next is the cursor that is passed in the 'from' parameter to retrieve the next page. It's empty
+	// if this is the last page.
+	Next string `json:"next,omitempty"`
+}
+
+// HistoryEntry contains an anchor along with its hashlink.
+type HistoryEntry struct {
+	HashLink string                 `json:"hashlink"`
+	Anchor   *verifiable.Credential `json:"anchor"`
+}
+
+// HistoryHandler returns the anchor history of a DID page by page, starting at the latest anchor. The
+// following query parameters are supported:
+//   - from: The anchor at which to start (defaults to the latest anchor of the DID). The value of 'next' from
+//     the previous page is passed in this parameter in order to retrieve the next page. 'next' is a cursor that
+//     contains the hashlink of the anchor along with an HMAC over the suffix and the hashlink, so it's accepted
+//     by any server that shares the cursor key without traversing the graph. Otherwise, the value must be the
+//     hashlink of an anchor in the history of the DID, i.e. either the latest anchor or one that's reachable
+//     from it (within 1000 anchors), otherwise the request is rejected. Only the resource hash of such a
+//     hashlink is used; the links are taken from the DID's own anchors so that a client can't make the
+//     server fetch arbitrary URLs.
+//   - to: The hashlink of the anchor at which to stop (exclusive).
+//   - limit: The maximum number of anchors in the page (defaults to 25, maximum 100).
+type HistoryHandler struct {
+	graph          *Graph
+	didAnchors     didAnchorStore
+	marshal        func(v interface{}) ([]byte, error)
+	cursorKey      []byte
+	maxSearchDepth int
+}
+
+// NewHistoryHandler returns a new anchor history handler. The cursor key is used to sign the 'next' cursors
+// and must be the same on all servers of a domain.
+func NewHistoryHandler(graph *Graph, didAnchors didAnchorStore, cursorKey []byte) *HistoryHandler {
+	return &HistoryHandler{
+		graph:          graph,
+		didAnchors:     didAnchors,
+		marshal:        json.Marshal,
+		cursorKey:      cursorKey,
+		maxSearchDepth: maxSearchDepth,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the HistoryHandler service.
+func (h *HistoryHandler) Path() string {
+	return historyEndpoint
+}
+
+// Method returns the HTTP REST method for the HistoryHandler service.
+func (h *HistoryHandler) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the HistoryHandler service.
+func (h *HistoryHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *HistoryHandler) handle(w http.ResponseWriter, req *http.Request) {
+	suffix := mux.Vars(req)[suffixPathVariable]
+
+	limit, err := getLimit(req)
+	if err != nil {
+		logger.Infof("[%s] Invalid value for parameter [%s]: %s", historyEndpoint, limitParam, err)
+
+		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	latest, err := h.didAnchors.Get(suffix)
+	if err != nil {
+		if errors.Is(err, didanchor.ErrDataNotFound) {
+			writeResponse(w, http.StatusNotFound, []byte(notFoundResponse))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error retrieving latest anchor for suffix [%s]: %s", historyEndpoint, suffix, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	page, err := h.getHistory(suffix, latest, req.URL.Query().Get(fromParam), req.URL.Query().Get(toParam), limit)
+	if err != nil {
+		if errors.Is(err, errInvalidFrom) {
+			logger.Infof("[%s] Invalid value for parameter [%s] for suffix [%s]: %s",
+				historyEndpoint, fromParam, suffix, err)
+
+			writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+			return
+		}
+
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			writeResponse(w, http.StatusNotFound, []byte(notFoundResponse))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error retrieving anchor history for suffix [%s]: %s", historyEndpoint, suffix, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	respBytes, err := h.marshal(page)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling anchor history: %s", historyEndpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, respBytes)
+}
+
+func (h *HistoryHandler) getHistory(suffix, latest, from, to string, limit int) (*HistoryPage, error) {
+	start := latest
+
+	if from != "" {
+		var err error
+
+		start, err = h.resolveFrom(suffix, latest, from)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := h.getPage(suffix, start, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Next != "" {
+		page.Next = h.newCursor(suffix, page.Next)
+	}
+
+	return page, nil
+}
+
+// resolveFrom returns the DID's own hashlink for the given 'from' value. The value must either be a cursor
+// that was issued for the DID, the latest anchor of the DID, or an anchor that's reachable from the latest
+// anchor. Otherwise errInvalidFrom is returned.
+func (h *HistoryHandler) resolveFrom(suffix, latest, from string) (string, error) {
+	if hl, ok := h.parseCursor(suffix, from); ok {
+		return hl, nil
+	}
+
+	if resourceHashOrHL(from) == resourceHashOrHL(latest) {
+		return latest, nil
+	}
+
+	it := h.graph.NewDidAnchorIterator(latest, suffix,
+		WithMaxDepth(h.maxSearchDepth), WithPrefetch(prefetchSize))
+	defer it.Close()
+
+	ref := resourceHashOrHL(from)
+
+	for {
+		_, err := it.Next()
+		if err != nil {
+			if errors.Is(err, ErrNoMoreAnchors) {
+				return "", fmt.Errorf("%w: %s", errInvalidFrom, from)
+			}
+
+			return "", err
+		}
+
+		if next := it.NextHashLink(); next != "" && resourceHashOrHL(next) == ref {
+			return next, nil
+		}
+	}
+}
+
+func (h *HistoryHandler) getPage(suffix, from, to string, limit int) (*HistoryPage, error) {
+	it := h.graph.NewDidAnchorIterator(from, suffix,
+		WithMaxDepth(limit), WithStopAt(to), WithPrefetch(prefetchSize))
+	defer it.Close()
+
+	page := &HistoryPage{
+		Suffix:  suffix,
+		Anchors: []*HistoryEntry{},
+	}
+
+	for {
+		anchor, err := it.Next()
+		if err != nil {
+			if errors.Is(err, ErrNoMoreAnchors) {
+				break
+			}
+
+			return nil, err
+		}
+
+		page.Anchors = append(page.Anchors, &HistoryEntry{HashLink: anchor.CID, Anchor: anchor.Info})
+	}
+
+	page.Next = it.NextHashLink()
+
+	return page, nil
+}
+
+// newCursor returns a cursor for the given anchor of the DID which contains the hashlink and an HMAC over
+// the suffix and the hashlink.
+func (h *HistoryHandler) newCursor(suffix, hl string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(hl)) + cursorSeparator +
+		base64.RawURLEncoding.EncodeToString(h.cursorMAC(suffix, hl))
+}
+
+// parseCursor returns the hashlink in the given cursor. False is returned if the value isn't a cursor or if
+// it wasn't issued for the DID.
+func (h *HistoryHandler) parseCursor(suffix, cursor string) (string, bool) {
+	parts := strings.Split(cursor, cursorSeparator)
+
+	const numParts = 2
+	if len(parts) != numParts {
+		return "", false
+	}
+
+	hl, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", false
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", false
+	}
+
+	if !hmac.Equal(mac, h.cursorMAC(suffix, string(hl))) {
+		return "", false
+	}
+
+	return string(hl), true
+}
+
+func (h *HistoryHandler) cursorMAC(suffix, hl string) []byte {
+	mac := hmac.New(sha256.New, h.cursorKey)
+
+	// The suffix and hashlink can't contain a newline so the message is unambiguous.
+	mac.Write([]byte(suffix + "\n" + hl)) //nolint:errcheck
+
+	return mac.Sum(nil)
+}
+
+func getLimit(req *http.Request) (int, error) {
+	limitStr := req.URL.Query().Get(limitParam)
+	if limitStr == "" {
+		return defaultPageSize, nil
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		return 0, err
+	}
+
+	if limit <= 0 || limit > maxPageSize {
+		return 0, fmt.Errorf("value must be between 1 and %d", maxPageSize)
+	}
+
+	return limit, nil
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("[%s] Unable to write response: %s", historyEndpoint, err)
+
+			return
+		}
+
+		logger.Debugf("[%s] Wrote response: %s", historyEndpoint, body)
+	}
+}
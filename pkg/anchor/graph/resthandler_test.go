@@ -0,0 +1,320 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graph
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/didanchor"
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+func TestHistoryHandler(t *testing.T) {
+	graph := newTestGraph(t)
+
+	cursorKey := []byte("cursor-key")
+
+	hls := addTestAnchors(t, graph, 5)
+
+	didAnchors := &mockDIDAnchorStore{anchors: map[string]string{testDID: hls[len(hls)-1]}}
+
+	h := NewHistoryHandler(graph, didAnchors, cursorKey)
+	require.Equal(t, "/anchorgraph/{suffix}", h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+	require.NotNil(t, h.Handler())
+
+	t.Run("Success - paging", func(t *testing.T) {
+		var (
+			actual []string
+			from   string
+		)
+
+		for {
+			params := url.Values{limitParam: []string{"2"}}
+			if from != "" {
+				params.Set(fromParam, from)
+			}
+
+			status, body := handleHistory(t, h, testDID, params)
+			require.Equal(t, http.StatusOK, status)
+
+			page := &HistoryPage{}
+			require.NoError(t, json.Unmarshal(body, page))
+			require.Equal(t, testDID, page.Suffix)
+			require.True(t, len(page.Anchors) <= 2)
+
+			for _, entry := range page.Anchors {
+				require.NotNil(t, entry.Anchor)
+
+				actual = append(actual, entry.HashLink)
+			}
+
+			if page.Next == "" {
+				break
+			}
+
+			from = page.Next
+		}
+
+		require.Equal(t, reversed(hls), actual)
+	})
+
+	t.Run("Success - to", func(t *testing.T) {
+		status, body := handleHistory(t, h, testDID, url.Values{toParam: []string{hls[2]}})
+		require.Equal(t, http.StatusOK, status)
+
+		page := &HistoryPage{}
+		require.NoError(t, json.Unmarshal(body, page))
+		require.Len(t, page.Anchors, 2)
+		require.Empty(t, page.Next)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		for _, limit := range []string{"xxx", "0", "101"} {
+			status, _ := handleHistory(t, h, testDID, url.Values{limitParam: []string{limit}})
+			require.Equal(t, http.StatusBadRequest, status)
+		}
+	})
+
+	t.Run("Suffix not found", func(t *testing.T) {
+		status, _ := handleHistory(t, h, "unknown", nil)
+		require.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("DID anchor store error", func(t *testing.T) {
+		h := NewHistoryHandler(graph, &mockDIDAnchorStore{err: errors.New("injected error")}, cursorKey)
+
+		status, _ := handleHistory(t, h, testDID, nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+
+	t.Run("Success - from anchor that wasn't issued by this handler", func(t *testing.T) {
+		h := NewHistoryHandler(graph, didAnchors, cursorKey)
+
+		status, body := handleHistory(t, h, testDID, url.Values{fromParam: []string{hls[1]}})
+		require.Equal(t, http.StatusOK, status)
+
+		page := &HistoryPage{}
+		require.NoError(t, json.Unmarshal(body, page))
+		require.Equal(t, reversed(hls[:2]), entryHashLinks(page))
+	})
+
+	t.Run("Success - from with foreign links", func(t *testing.T) {
+		resourceHash, err := hashlink.GetResourceHashFromHashLink(hls[2])
+		require.NoError(t, err)
+
+		// The links in the given hashlink must be ignored in favour of the DID's own hashlink.
+		foreignHL, err := hashlink.New().CreateHashLink([]byte("xxx"),
+			[]string{"https://169.254.169.254/latest/meta-data"})
+		require.NoError(t, err)
+
+		parts := strings.Split(foreignHL, ":")
+		from := "hl:" + resourceHash + ":" + parts[len(parts)-1]
+
+		status, body := handleHistory(t, h, testDID, url.Values{fromParam: []string{from}})
+		require.Equal(t, http.StatusOK, status)
+
+		page := &HistoryPage{}
+		require.NoError(t, json.Unmarshal(body, page))
+		require.NotEmpty(t, page.Anchors)
+		require.Equal(t, hls[2], page.Anchors[0].HashLink)
+	})
+
+	t.Run("Foreign from -> bad request", func(t *testing.T) {
+		// An anchor that isn't in the history of the DID.
+		otherHLs := addTestAnchors(t, graph, 1)
+
+		for _, from := range []string{
+			"hl:" + nonExistent,
+			"hl:xxx",
+			"https://169.254.169.254/latest/meta-data",
+			"hl:" + nonExistent + ":" + "uoQ-BeEJpcGZzOi8vUW1jcTZKV0RVa3l4ZWhxN1JWWmtQM052aUU0SHFnQnVkdGMxb3FmN3dBUHBIdw",
+			otherHLs[0],
+		} {
+			status, _ := handleHistory(t, h, testDID, url.Values{fromParam: []string{from}})
+			require.Equalf(t, http.StatusBadRequest, status, "from: %s", from)
+		}
+	})
+
+	t.Run("From beyond search depth -> bad request", func(t *testing.T) {
+		h := NewHistoryHandler(graph, didAnchors, cursorKey)
+		h.maxSearchDepth = 2
+
+		status, _ := handleHistory(t, h, testDID, url.Values{fromParam: []string{hls[0]}})
+		require.Equal(t, http.StatusBadRequest, status)
+
+		// The anchor may still be reached by paging.
+		status, body := handleHistory(t, h, testDID, url.Values{limitParam: []string{"4"}})
+		require.Equal(t, http.StatusOK, status)
+
+		page := &HistoryPage{}
+		require.NoError(t, json.Unmarshal(body, page))
+		require.NotEmpty(t, page.Next)
+
+		status, body = handleHistory(t, h, testDID, url.Values{fromParam: []string{page.Next}})
+		require.Equal(t, http.StatusOK, status)
+
+		page = &HistoryPage{}
+		require.NoError(t, json.Unmarshal(body, page))
+		require.Equal(t, []string{hls[0]}, entryHashLinks(page))
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		h1 := NewHistoryHandler(graph, didAnchors, cursorKey)
+
+		status, body := handleHistory(t, h1, testDID, url.Values{limitParam: []string{"3"}})
+		require.Equal(t, http.StatusOK, status)
+
+		page := &HistoryPage{}
+		require.NoError(t, json.Unmarshal(body, page))
+		require.NotEmpty(t, page.Next)
+
+		cursor := page.Next
+
+		t.Run("Accepted by another instance without a search", func(t *testing.T) {
+			h2 := NewHistoryHandler(graph, didAnchors, cursorKey)
+			h2.maxSearchDepth = 0
+
+			status, body := handleHistory(t, h2, testDID, url.Values{fromParam: []string{cursor}})
+			require.Equal(t, http.StatusOK, status)
+
+			page := &HistoryPage{}
+			require.NoError(t, json.Unmarshal(body, page))
+			require.Equal(t, reversed(hls[:2]), entryHashLinks(page))
+		})
+
+		t.Run("Different key -> bad request", func(t *testing.T) {
+			h2 := NewHistoryHandler(graph, didAnchors, []byte("other-key"))
+			h2.maxSearchDepth = 0
+
+			status, _ := handleHistory(t, h2, testDID, url.Values{fromParam: []string{cursor}})
+			require.Equal(t, http.StatusBadRequest, status)
+		})
+
+		t.Run("Tampered cursor -> bad request", func(t *testing.T) {
+			parts := strings.Split(cursor, cursorSeparator)
+			require.Len(t, parts, 2)
+
+			for _, from := range []string{
+				base64.RawURLEncoding.EncodeToString([]byte(otherHL(t, graph))) + cursorSeparator + parts[1],
+				parts[0] + cursorSeparator + base64.RawURLEncoding.EncodeToString([]byte("xxx")),
+				parts[0] + cursorSeparator + "!!!",
+				"!!!" + cursorSeparator + parts[1],
+				cursor + cursorSeparator + parts[1],
+			} {
+				status, _ := handleHistory(t, h1, testDID, url.Values{fromParam: []string{from}})
+				require.Equalf(t, http.StatusBadRequest, status, "from: %s", from)
+			}
+		})
+
+		t.Run("Cursor for another DID -> bad request", func(t *testing.T) {
+			const otherDID = "otherDID"
+
+			h2 := NewHistoryHandler(graph, &mockDIDAnchorStore{anchors: map[string]string{
+				testDID:  hls[len(hls)-1],
+				otherDID: otherHL(t, graph),
+			}}, cursorKey)
+
+			status, _ := handleHistory(t, h2, otherDID, url.Values{fromParam: []string{cursor}})
+			require.Equal(t, http.StatusBadRequest, status)
+		})
+	})
+
+	t.Run("Anchor not found", func(t *testing.T) {
+		h := NewHistoryHandler(graph, &mockDIDAnchorStore{anchors: map[string]string{testDID: "hl:" + nonExistent}}, cursorKey)
+
+		status, _ := handleHistory(t, h, testDID, nil)
+		require.Equal(t, http.StatusNotFound, status)
+
+		status, _ = handleHistory(t, h, testDID, url.Values{fromParam: []string{hls[0]}})
+		require.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("Invalid anchor", func(t *testing.T) {
+		h := NewHistoryHandler(graph, &mockDIDAnchorStore{anchors: map[string]string{testDID: "hl:xxx"}}, cursorKey)
+
+		status, _ := handleHistory(t, h, testDID, nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := NewHistoryHandler(graph, didAnchors, cursorKey)
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		status, _ := handleHistory(t, h, testDID, nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func otherHL(t *testing.T, graph *Graph) string {
+	t.Helper()
+
+	return addTestAnchors(t, graph, 1)[0]
+}
+
+func entryHashLinks(page *HistoryPage) []string {
+	var hls []string
+
+	for _, entry := range page.Anchors {
+		hls = append(hls, entry.HashLink)
+	}
+
+	return hls
+}
+
+func handleHistory(t *testing.T, h *HistoryHandler, suffix string, params url.Values) (int, []byte) {
+	t.Helper()
+
+	target := "/anchorgraph/" + suffix
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req = mux.SetURLVars(req, map[string]string{suffixPathVariable: suffix})
+
+	rw := httptest.NewRecorder()
+
+	h.handle(rw, req)
+
+	result := rw.Result()
+
+	respBytes, err := ioutil.ReadAll(result.Body)
+	require.NoError(t, err)
+	require.NoError(t, result.Body.Close())
+
+	return result.StatusCode, respBytes
+}
+
+type mockDIDAnchorStore struct {
+	anchors map[string]string
+	err     error
+}
+
+func (m *mockDIDAnchorStore) Get(suffix string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+
+	hl, ok := m.anchors[suffix]
+	if !ok {
+		return "", didanchor.ErrDataNotFound
+	}
+
+	return hl, nil
+}
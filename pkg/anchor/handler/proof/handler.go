@@ -156,7 +156,7 @@ func (h *WitnessProofHandler) setupMonitoring(wp vct.Proof, vc *verifiable.Crede
 	return h.MonitoringSvc.Watch(vc, endTime, domain, createdTime)
 }
 
-func (h *WitnessProofHandler) handleWitnessPolicy(vc *verifiable.Credential) error { //nolint:funlen
+func (h *WitnessProofHandler) handleWitnessPolicy(vc *verifiable.Credential) error {
 	logger.Debugf("Handling witness policy for VC [%s]", vc.ID)
 
 	witnessProofs, err := h.WitnessStore.Get(vc.ID)
@@ -180,7 +180,29 @@ func (h *WitnessProofHandler) handleWitnessPolicy(vc *verifiable.Credential) err
 	// publish witnessed vc to batch writer channel for further processing
 	logger.Infof("Witness policy has been satisfied for VC [%s]", vc.ID)
 
-	vc, err = addProofs(vc, witnessProofs)
+	return h.complete(vc, witnessProofs)
+}
+
+// Complete adds the witness proofs that have been received so far to the anchor credential and publishes it,
+// regardless of whether or not the witness policy has been satisfied.
+func (h *WitnessProofHandler) Complete(anchorCredID string) error {
+	vc, err := h.VCStore.Get(anchorCredID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve anchor credential[%s]: %w", anchorCredID, err)
+	}
+
+	witnessProofs, err := h.WitnessStore.Get(anchorCredID)
+	if err != nil {
+		return fmt.Errorf("failed to get witness proofs for credential[%s]: %w", anchorCredID, err)
+	}
+
+	logger.Infof("Completing VC [%s] without evaluating the witness policy", anchorCredID)
+
+	return h.complete(vc, witnessProofs)
+}
+
+func (h *WitnessProofHandler) complete(vc *verifiable.Credential, witnessProofs []*proofapi.WitnessProof) error {
+	vc, err := addProofs(vc, witnessProofs)
 	if err != nil {
 		return fmt.Errorf("failed to add witness proofs: %w", err)
 	}
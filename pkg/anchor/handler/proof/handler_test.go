@@ -653,6 +653,90 @@ func TestWitnessProofHandler(t *testing.T) {
 	})
 }
 
+func TestWitnessProofHandler_Complete(t *testing.T) {
+	ps := mempubsub.New(mempubsub.Config{})
+	defer ps.Stop()
+
+	witnessIRI, outerErr := url.Parse(witnessURL)
+	require.NoError(t, outerErr)
+
+	t.Run("success - witness policy not satisfied", func(t *testing.T) {
+		vcStore, err := vcstore.New(mem.NewProvider(), testutil.GetLoader(t))
+		require.NoError(t, err)
+
+		anchorVC, err := verifiable.ParseCredential([]byte(anchorCred),
+			verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(testutil.GetLoader(t)),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, vcStore.Put(anchorVC))
+
+		vcStatusStore, err := vcstatus.New(mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, vcStatusStore.AddStatus(anchorVC.ID, proofapi.VCStatusInProcess))
+
+		witnessStore, err := witness.New(mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, witnessStore.Put(anchorVC.ID,
+			[]*proofapi.WitnessProof{{Type: proofapi.WitnessTypeSystem, Witness: witnessIRI.String()}}))
+
+		providers := &Providers{
+			VCStore:       vcStore,
+			VCStatusStore: vcStatusStore,
+			MonitoringSvc: &mocks.MonitoringService{},
+			WitnessStore:  witnessStore,
+			WitnessPolicy: &mockWitnessPolicy{eval: false},
+			Metrics:       &orbmocks.MetricsProvider{},
+		}
+
+		proofHandler := New(providers, ps)
+
+		require.NoError(t, proofHandler.Complete(anchorVC.ID))
+
+		status, err := vcStatusStore.GetStatus(anchorVC.ID)
+		require.NoError(t, err)
+		require.Equal(t, proofapi.VCStatusCompleted, status)
+	})
+
+	t.Run("error - anchor credential not found", func(t *testing.T) {
+		vcStore, err := vcstore.New(mem.NewProvider(), testutil.GetLoader(t))
+		require.NoError(t, err)
+
+		proofHandler := New(&Providers{VCStore: vcStore}, ps)
+
+		err = proofHandler.Complete(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to retrieve anchor credential")
+	})
+
+	t.Run("error - witness store error", func(t *testing.T) {
+		vcStore, err := vcstore.New(mem.NewProvider(), testutil.GetLoader(t))
+		require.NoError(t, err)
+
+		anchorVC, err := verifiable.ParseCredential([]byte(anchorCred),
+			verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(testutil.GetLoader(t)),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, vcStore.Put(anchorVC))
+
+		providers := &Providers{
+			VCStore:      vcStore,
+			WitnessStore: &mockWitnessStore{GetErr: fmt.Errorf("witness store error")},
+		}
+
+		proofHandler := New(providers, ps)
+
+		err = proofHandler.Complete(anchorVC.ID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "witness store error")
+	})
+}
+
 type mockWitnessStore struct {
 	WitnessProof []*proofapi.WitnessProof
 	AddProofErr  error
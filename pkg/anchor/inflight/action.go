@@ -0,0 +1,159 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package inflight
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/anchor/reconciler"
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+)
+
+// ResendRequest is the (optional) request body of the resend endpoint.
+type ResendRequest struct {
+	// Witnesses are the witnesses to which the offer is resent. If empty then the offer is resent to
+	// the witnesses that haven't provided a proof along with any new system witnesses.
+	Witnesses []string `json:"witnesses,omitempty"`
+}
+
+// Action performs an operation on the in-process anchor credential specified by the id query parameter.
+type Action struct {
+	*Providers
+	path    string
+	name    string
+	perform func(vcID string, req *http.Request) error
+}
+
+// NewComplete returns an Action that adds the witness proofs received so far to an in-process anchor credential
+// and completes it, regardless of whether or not the witness policy has been satisfied.
+func NewComplete(providers *Providers) *Action {
+	a := &Action{Providers: providers, path: completeEndpoint, name: "complete"}
+
+	a.perform = func(vcID string, _ *http.Request) error {
+		if _, err := a.getInProcessWitnesses(vcID); err != nil {
+			return err
+		}
+
+		return a.ProofHandler.Complete(vcID)
+	}
+
+	return a
+}
+
+// NewCancel returns an Action that abandons an in-process anchor credential and returns its operations
+// to the operation queue.
+func NewCancel(providers *Providers) *Action {
+	a := &Action{Providers: providers, path: cancelEndpoint, name: "cancel"}
+
+	a.perform = func(vcID string, _ *http.Request) error {
+		return a.OfferManager.Cancel(vcID)
+	}
+
+	return a
+}
+
+// NewResend returns an Action that resends the offer for an in-process anchor credential to the witnesses
+// in the request body (see ResendRequest).
+func NewResend(providers *Providers) *Action {
+	a := &Action{Providers: providers, path: resendEndpoint, name: "resend"}
+
+	a.perform = func(vcID string, req *http.Request) error {
+		resendReq, err := getResendRequest(req)
+		if err != nil {
+			return err
+		}
+
+		return a.OfferManager.ReOffer(vcID, resendReq.Witnesses...)
+	}
+
+	return a
+}
+
+// Path returns the HTTP REST endpoint for the Action service.
+func (a *Action) Path() string {
+	return a.path
+}
+
+// Method returns the HTTP REST method for the Action service.
+func (a *Action) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the Action service.
+func (a *Action) Handler() common.HTTPRequestHandler {
+	return a.handle
+}
+
+func (a *Action) handle(w http.ResponseWriter, req *http.Request) {
+	vcID := req.URL.Query().Get(idQueryParam)
+	if vcID == "" {
+		logger.Infof("[%s] Missing query parameter: %s", a.path, idQueryParam)
+
+		writeResponse(w, a.path, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	err := a.perform(vcID, req)
+	if err != nil {
+		a.writeError(w, vcID, err)
+
+		return
+	}
+
+	logger.Infof("[%s] Performed '%s' on anchor credential [%s] on behalf of [%s]",
+		a.path, a.name, vcID, auth.TokenIDFromContext(req.Context()))
+
+	writeResponse(w, a.path, http.StatusOK, nil)
+}
+
+func (a *Action) writeError(w http.ResponseWriter, vcID string, err error) {
+	switch {
+	case isNotFound(err):
+		logger.Infof("[%s] Anchor credential not found: %s", a.path, err)
+
+		writeResponse(w, a.path, http.StatusNotFound, []byte(notFoundResponse))
+
+	case errors.Is(err, reconciler.ErrWitnessNotFound), errors.Is(err, errInvalidRequest):
+		logger.Infof("[%s] Invalid request for anchor credential [%s]: %s", a.path, vcID, err)
+
+		writeResponse(w, a.path, http.StatusBadRequest, []byte(fmt.Sprintf("%s %s", badRequestResponse, err)))
+
+	default:
+		logger.Errorf("[%s] Error performing '%s' on anchor credential [%s]: %s", a.path, a.name, vcID, err)
+
+		writeResponse(w, a.path, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+	}
+}
+
+var errInvalidRequest = errors.New("invalid request")
+
+func getResendRequest(req *http.Request) (*ResendRequest, error) {
+	reqBytes, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+
+	resendReq := &ResendRequest{}
+
+	if len(reqBytes) == 0 {
+		return resendReq, nil
+	}
+
+	err = json.Unmarshal(reqBytes, resendReq)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidRequest, err)
+	}
+
+	return resendReq, nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package inflight
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	"github.com/trustbloc/orb/pkg/anchor/reconciler"
+)
+
+func TestComplete(t *testing.T) {
+	p := newTestProviders(t)
+
+	addCredential(t, p, vcID1, proof.VCStatusInProcess)
+	addCredential(t, p, vcID2, proof.VCStatusCompleted)
+
+	a := NewComplete(p)
+	require.Equal(t, completeEndpoint, a.Path())
+	require.Equal(t, http.MethodPost, a.Method())
+	require.NotNil(t, a.Handler())
+
+	t.Run("Success", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, handle(t, a, "?id="+vcID1, nil))
+	})
+
+	t.Run("Missing ID", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, handle(t, a, "", nil))
+	})
+
+	t.Run("Completed", func(t *testing.T) {
+		require.Equal(t, http.StatusNotFound, handle(t, a, "?id="+vcID2, nil))
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		require.Equal(t, http.StatusNotFound, handle(t, a, "?id=https://orb.domain1.com/vc/unknown", nil))
+	})
+
+	t.Run("Proof handler error", func(t *testing.T) {
+		p2 := *p
+		p2.ProofHandler = &mockProofHandler{err: errInjected}
+
+		require.Equal(t, http.StatusInternalServerError, handle(t, NewComplete(&p2), "?id="+vcID1, nil))
+	})
+}
+
+func TestCancel(t *testing.T) {
+	p := newTestProviders(t)
+
+	a := NewCancel(p)
+	require.Equal(t, cancelEndpoint, a.Path())
+
+	t.Run("Success", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, handle(t, a, "?id="+vcID1, nil))
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		p2 := *p
+		p2.OfferManager = &mockOfferManager{err: fmt.Errorf("vc: %w", reconciler.ErrNotFound)}
+
+		require.Equal(t, http.StatusNotFound, handle(t, NewCancel(&p2), "?id="+vcID1, nil))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		p2 := *p
+		p2.OfferManager = &mockOfferManager{err: errInjected}
+
+		require.Equal(t, http.StatusInternalServerError, handle(t, NewCancel(&p2), "?id="+vcID1, nil))
+	})
+}
+
+func TestResend(t *testing.T) {
+	t.Run("Success - pending witnesses", func(t *testing.T) {
+		om := &mockOfferManager{}
+
+		p := newTestProviders(t)
+		p.OfferManager = om
+
+		a := NewResend(p)
+		require.Equal(t, resendEndpoint, a.Path())
+
+		require.Equal(t, http.StatusOK, handle(t, a, "?id="+vcID1, nil))
+		require.Empty(t, om.witnesses)
+	})
+
+	t.Run("Success - selected witnesses", func(t *testing.T) {
+		om := &mockOfferManager{}
+
+		p := newTestProviders(t)
+		p.OfferManager = om
+
+		require.Equal(t, http.StatusOK,
+			handle(t, NewResend(p), "?id="+vcID1, []byte(`{"witnesses":["`+witness1+`"]}`)))
+		require.Equal(t, []string{witness1}, om.witnesses)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, handle(t, NewResend(newTestProviders(t)), "?id="+vcID1, []byte(`{`)))
+	})
+
+	t.Run("Witness not found", func(t *testing.T) {
+		p := newTestProviders(t)
+		p.OfferManager = &mockOfferManager{err: fmt.Errorf("w: %w", reconciler.ErrWitnessNotFound)}
+
+		require.Equal(t, http.StatusBadRequest,
+			handle(t, NewResend(p), "?id="+vcID1, []byte(`{"witnesses":["`+witness1+`"]}`)))
+	})
+}
+
+func handle(t *testing.T, a *Action, query string, body []byte) int {
+	t.Helper()
+
+	rw := httptest.NewRecorder()
+
+	a.handle(rw, httptest.NewRequest(http.MethodPost, a.Path()+query, bytes.NewReader(body)))
+
+	result := rw.Result()
+	require.NoError(t, result.Body.Close())
+
+	return result.StatusCode
+}
@@ -0,0 +1,115 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package inflight
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	"github.com/trustbloc/orb/pkg/anchor/reconciler"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	endpoint         = "/anchorcredentials"
+	completeEndpoint = endpoint + "/complete"
+	cancelEndpoint   = endpoint + "/cancel"
+	resendEndpoint   = endpoint + "/resend"
+
+	idQueryParam    = "id"
+	limitQueryParam = "limit"
+
+	defaultLimit = 100
+)
+
+const (
+	badRequestResponse          = "Bad Request."
+	notFoundResponse            = "Not Found."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+var logger = log.New("inflight-rest-handler")
+
+var errNotInProcess = errors.New("anchor credential is not in process")
+
+// Providers contains the providers required by the in-flight anchor credential handlers.
+type Providers struct {
+	WitnessStore  witnessStore
+	VCStatusStore vcStatusStore
+	WitnessPolicy witnessPolicy
+	OfferManager  offerManager
+	ProofHandler  proofHandler
+}
+
+type witnessStore interface {
+	GetVCIDs(maxItems int) ([]string, error)
+	Get(vcID string) ([]*proof.WitnessProof, error)
+}
+
+type vcStatusStore interface {
+	GetStatus(vcID string) (proof.VCStatus, error)
+}
+
+type witnessPolicy interface {
+	Evaluate(witnesses []*proof.WitnessProof) (bool, error)
+}
+
+type offerManager interface {
+	OfferStatus(vcID string) (*reconciler.OfferStatus, error)
+	ReOffer(vcID string, witnesses ...string) error
+	Cancel(vcID string) error
+}
+
+type proofHandler interface {
+	Complete(anchorCredID string) error
+}
+
+// getInProcessWitnesses returns the witnesses of the given anchor credential. errNotInProcess is returned
+// if the anchor credential isn't in the witness store or if its witness policy has already been satisfied.
+func (p *Providers) getInProcessWitnesses(vcID string) ([]*proof.WitnessProof, error) {
+	witnesses, err := p.WitnessStore.Get(vcID)
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			return nil, fmt.Errorf("%s: %w", vcID, errNotInProcess)
+		}
+
+		return nil, err
+	}
+
+	status, err := p.VCStatusStore.GetStatus(vcID)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == proof.VCStatusCompleted {
+		return nil, fmt.Errorf("%s has been completed: %w", vcID, errNotInProcess)
+	}
+
+	return witnesses, nil
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, errNotInProcess) || errors.Is(err, reconciler.ErrNotFound)
+}
+
+func writeResponse(w http.ResponseWriter, path string, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("[%s] Unable to write response: %s", path, err)
+
+			return
+		}
+
+		logger.Debugf("[%s] Wrote response: %s", path, body)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package inflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+)
+
+// Witness contains the status of a witness of an in-process anchor credential.
+type Witness struct {
+	IRI           string            `json:"iri"`
+	Type          proof.WitnessType `json:"type"`
+	HasLog        bool              `json:"hasLog"`
+	ProofReceived bool              `json:"proofReceived"`
+}
+
+// Credential contains the witnessing status of an in-process anchor credential.
+type Credential struct {
+	ID string `json:"id"`
+
+	// EndTime is the time after which the anchor credential is re-offered or its operations are returned
+	// to the operation queue.
+	EndTime       time.Time `json:"endTime"`
+	TimeRemaining string    `json:"timeRemaining"`
+	Expired       bool      `json:"expired"`
+	ReOffers      int       `json:"reOffers"`
+
+	// PolicySatisfied is true if the proofs that have been received satisfy the active witness policy.
+	PolicySatisfied bool       `json:"policySatisfied"`
+	Witnesses       []*Witness `json:"witnesses"`
+}
+
+// Retriever returns the in-process anchor credentials along with the proof status of each of their witnesses.
+// If the id query parameter is specified then only the given anchor credential is returned.
+type Retriever struct {
+	*Providers
+	marshal func(v interface{}) ([]byte, error)
+}
+
+// NewRetriever returns a new Retriever.
+func NewRetriever(providers *Providers) *Retriever {
+	return &Retriever{
+		Providers: providers,
+		marshal:   json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the Retriever service.
+func (r *Retriever) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the Retriever service.
+func (r *Retriever) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the Retriever service.
+func (r *Retriever) Handler() common.HTTPRequestHandler {
+	return r.handle
+}
+
+func (r *Retriever) handle(w http.ResponseWriter, req *http.Request) {
+	var (
+		resp interface{}
+		err  error
+	)
+
+	if vcID := req.URL.Query().Get(idQueryParam); vcID != "" {
+		resp, err = r.getCredential(vcID)
+	} else {
+		limit, e := getLimit(req)
+		if e != nil {
+			logger.Infof("[%s] Invalid limit: %s", endpoint, e)
+
+			writeResponse(w, endpoint, http.StatusBadRequest, []byte(badRequestResponse))
+
+			return
+		}
+
+		resp, err = r.getCredentials(limit)
+	}
+
+	if err != nil {
+		if isNotFound(err) {
+			logger.Debugf("[%s] Anchor credential not found: %s", endpoint, err)
+
+			writeResponse(w, endpoint, http.StatusNotFound, []byte(notFoundResponse))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error retrieving in-process anchor credentials: %s", endpoint, err)
+
+		writeResponse(w, endpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	respBytes, err := r.marshal(resp)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling response: %s", endpoint, err)
+
+		writeResponse(w, endpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, endpoint, http.StatusOK, respBytes)
+}
+
+func (r *Retriever) getCredentials(limit int) ([]*Credential, error) {
+	vcIDs, err := r.WitnessStore.GetVCIDs(limit)
+	if err != nil {
+		return nil, fmt.Errorf("get anchor credentials from witness store: %w", err)
+	}
+
+	credentials := []*Credential{}
+
+	for _, vcID := range vcIDs {
+		cred, e := r.getCredential(vcID)
+		if e != nil {
+			if isNotFound(e) {
+				// The anchor credential completed after the IDs were retrieved.
+				continue
+			}
+
+			return nil, e
+		}
+
+		credentials = append(credentials, cred)
+	}
+
+	return credentials, nil
+}
+
+func (r *Retriever) getCredential(vcID string) (*Credential, error) {
+	witnessProofs, err := r.getInProcessWitnesses(vcID)
+	if err != nil {
+		return nil, err
+	}
+
+	offerStatus, err := r.OfferManager.OfferStatus(vcID)
+	if err != nil {
+		return nil, fmt.Errorf("get offer status for anchor credential [%s]: %w", vcID, err)
+	}
+
+	satisfied, err := r.WitnessPolicy.Evaluate(witnessProofs)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate witness policy for anchor credential [%s]: %w", vcID, err)
+	}
+
+	remaining := time.Until(offerStatus.EndTime)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	cred := &Credential{
+		ID:              vcID,
+		EndTime:         offerStatus.EndTime,
+		TimeRemaining:   remaining.Round(time.Second).String(),
+		Expired:         remaining == 0,
+		ReOffers:        offerStatus.ReOffers,
+		PolicySatisfied: satisfied,
+	}
+
+	for _, wp := range witnessProofs {
+		cred.Witnesses = append(cred.Witnesses, &Witness{
+			IRI:           wp.Witness,
+			Type:          wp.Type,
+			HasLog:        wp.HasLog,
+			ProofReceived: wp.Proof != nil,
+		})
+	}
+
+	return cred, nil
+}
+
+func getLimit(req *http.Request) (int, error) {
+	limitStr := req.URL.Query().Get(limitQueryParam)
+	if limitStr == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("limit must be a positive integer: %s", limitStr)
+	}
+
+	return limit, nil
+}
@@ -0,0 +1,220 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package inflight
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	"github.com/trustbloc/orb/pkg/anchor/reconciler"
+	"github.com/trustbloc/orb/pkg/store/vcstatus"
+	"github.com/trustbloc/orb/pkg/store/witness"
+)
+
+const (
+	vcID1    = "https://orb.domain1.com/vc/1"
+	vcID2    = "https://orb.domain1.com/vc/2"
+	witness1 = "https://orb.domain2.com/services/orb"
+	witness2 = "https://orb.domain3.com/services/orb"
+)
+
+func TestRetriever(t *testing.T) {
+	p := newTestProviders(t)
+
+	addCredential(t, p, vcID1, proof.VCStatusInProcess)
+	addCredential(t, p, vcID2, proof.VCStatusCompleted)
+
+	require.NoError(t, p.WitnessStore.(*witness.Store).AddProof(vcID1, witness1, []byte(`{}`)))
+
+	r := NewRetriever(p)
+	require.Equal(t, endpoint, r.Path())
+	require.Equal(t, http.MethodGet, r.Method())
+	require.NotNil(t, r.Handler())
+
+	t.Run("Success - all", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		r.handle(rw, httptest.NewRequest(http.MethodGet, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		var creds []*Credential
+		require.NoError(t, json.Unmarshal(respBytes, &creds))
+		require.Len(t, creds, 1)
+
+		cred := creds[0]
+		require.Equal(t, vcID1, cred.ID)
+		require.False(t, cred.Expired)
+		require.Equal(t, 1, cred.ReOffers)
+		require.NotEmpty(t, cred.TimeRemaining)
+		require.Len(t, cred.Witnesses, 2)
+
+		for _, w := range cred.Witnesses {
+			require.Equal(t, w.IRI == witness1, w.ProofReceived)
+		}
+	})
+
+	t.Run("Success - by ID", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		r.handle(rw, httptest.NewRequest(http.MethodGet, endpoint+"?id="+vcID1, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		cred := &Credential{}
+		require.NoError(t, json.Unmarshal(respBytes, cred))
+		require.Equal(t, vcID1, cred.ID)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		r.handle(rw, httptest.NewRequest(http.MethodGet, endpoint+"?id="+vcID2, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusNotFound, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		r.handle(rw, httptest.NewRequest(http.MethodGet, endpoint+"?limit=-1", nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Offer status error", func(t *testing.T) {
+		p2 := *p
+		p2.OfferManager = &mockOfferManager{err: errors.New("injected offer status error")}
+
+		rw := httptest.NewRecorder()
+
+		NewRetriever(&p2).handle(rw, httptest.NewRequest(http.MethodGet, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Witness policy error", func(t *testing.T) {
+		p2 := *p
+		p2.WitnessPolicy = &mockWitnessPolicy{err: errors.New("injected policy error")}
+
+		rw := httptest.NewRecorder()
+
+		NewRetriever(&p2).handle(rw, httptest.NewRequest(http.MethodGet, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		r2 := NewRetriever(p)
+		r2.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		r2.handle(rw, httptest.NewRequest(http.MethodGet, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+func newTestProviders(t *testing.T) *Providers {
+	t.Helper()
+
+	ws, err := witness.New(mem.NewProvider())
+	require.NoError(t, err)
+
+	ss, err := vcstatus.New(mem.NewProvider())
+	require.NoError(t, err)
+
+	return &Providers{
+		WitnessStore:  ws,
+		VCStatusStore: ss,
+		WitnessPolicy: &mockWitnessPolicy{},
+		OfferManager:  &mockOfferManager{},
+		ProofHandler:  &mockProofHandler{},
+	}
+}
+
+func addCredential(t *testing.T, p *Providers, vcID string, status proof.VCStatus) {
+	t.Helper()
+
+	require.NoError(t, p.WitnessStore.(*witness.Store).Put(vcID, []*proof.WitnessProof{
+		{Type: proof.WitnessTypeBatch, Witness: witness1, HasLog: true},
+		{Type: proof.WitnessTypeSystem, Witness: witness2},
+	}))
+
+	require.NoError(t, p.VCStatusStore.(*vcstatus.Store).AddStatus(vcID, status))
+}
+
+type mockWitnessPolicy struct {
+	err error
+}
+
+func (m *mockWitnessPolicy) Evaluate([]*proof.WitnessProof) (bool, error) {
+	return false, m.err
+}
+
+type mockOfferManager struct {
+	err       error
+	witnesses []string
+}
+
+func (m *mockOfferManager) OfferStatus(string) (*reconciler.OfferStatus, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &reconciler.OfferStatus{EndTime: time.Now().Add(time.Minute), ReOffers: 1}, nil
+}
+
+func (m *mockOfferManager) ReOffer(vcID string, witnesses ...string) error {
+	m.witnesses = witnesses
+
+	return m.err
+}
+
+func (m *mockOfferManager) Cancel(vcID string) error {
+	return m.err
+}
+
+type mockProofHandler struct {
+	err error
+}
+
+func (m *mockProofHandler) Complete(anchorCredID string) error {
+	return m.err
+}
+
+var errInjected = errors.New("injected error")
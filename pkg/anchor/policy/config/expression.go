@@ -0,0 +1,240 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+)
+
+// Node is a node in the parsed witness policy expression tree.
+type Node interface {
+	// Evaluate returns true if the node is satisfied by the provided witness proofs.
+	Evaluate(witnesses []*proof.WitnessProof) bool
+	String() string
+}
+
+// Group is a named set of witnesses. A witness is a member of the group if its IRI
+// is listed in IRIs or if the host of its IRI is listed in Domains.
+type Group struct {
+	Name    string
+	Domains []string
+	IRIs    []string
+}
+
+func (g *Group) contains(witness string) bool {
+	for _, iri := range g.IRIs {
+		if iri == witness {
+			return true
+		}
+	}
+
+	host := hostOf(witness)
+	if host == "" {
+		return false
+	}
+
+	for _, domain := range g.Domains {
+		if strings.EqualFold(domain, host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Group) String() string {
+	var members []string
+
+	for _, d := range g.Domains {
+		members = append(members, domainPrefix+d)
+	}
+
+	members = append(members, g.IRIs...)
+
+	return fmt.Sprintf("%s(%s,%s)", GroupDefinition, g.Name, strings.Join(members, ","))
+}
+
+// evalOptions holds the policy-wide settings that are shared by all rules in an expression.
+type evalOptions struct {
+	logRequired bool
+	weights     map[string]int
+}
+
+// weightOf returns the weight of the given witness. The weight of a witness that is
+// configured by IRI takes precedence over a weight that is configured by domain.
+// Witnesses without a configured weight have a weight of 1.
+func (o *evalOptions) weightOf(witness string) int {
+	if w, ok := o.weights[witness]; ok {
+		return w
+	}
+
+	if w, ok := o.weights[domainPrefix+strings.ToLower(hostOf(witness))]; ok {
+		return w
+	}
+
+	return 1
+}
+
+type andNode struct {
+	nodes []Node
+}
+
+func (n *andNode) Evaluate(witnesses []*proof.WitnessProof) bool {
+	for _, node := range n.nodes {
+		if !node.Evaluate(witnesses) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (n *andNode) String() string {
+	return join(n.nodes, AND)
+}
+
+type orNode struct {
+	nodes []Node
+}
+
+func (n *orNode) Evaluate(witnesses []*proof.WitnessProof) bool {
+	for _, node := range n.nodes {
+		if node.Evaluate(witnesses) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *orNode) String() string {
+	return join(n.nodes, OR)
+}
+
+type ruleKind int
+
+const (
+	// thresholdRule is satisfied if at least minNumber witnesses or at least minPercent of the witnesses
+	// selected by the rule have provided a proof.
+	thresholdRule ruleKind = iota
+	// weightRule is satisfied if the sum of the weights of the selected witnesses that
+	// have provided a proof is at least minWeight.
+	weightRule
+)
+
+// ruleNode is a leaf of the expression tree that evaluates the witnesses selected by either
+// a role (batch or system) or a named group.
+type ruleNode struct {
+	kind       ruleKind
+	name       string
+	text       string
+	selector   string
+	role       proof.WitnessType
+	group      *Group
+	minNumber  int
+	minPercent int
+	minWeight  int
+	opts       *evalOptions
+}
+
+func (n *ruleNode) Evaluate(witnesses []*proof.WitnessProof) bool {
+	total, collected, collectedWeight := n.count(witnesses)
+
+	if n.kind == weightRule {
+		return collectedWeight >= n.minWeight
+	}
+
+	return evaluateThreshold(collected, total, n.minNumber, n.minPercent)
+}
+
+func (n *ruleNode) String() string {
+	return n.text
+}
+
+// count returns the number of selected witnesses, the number of selected witnesses which provided a proof,
+// and the total weight of the selected witnesses that provided a proof. A witness may appear more than once
+// in the list (e.g. as both a batch and a system witness) but is only counted once.
+func (n *ruleNode) count(witnesses []*proof.WitnessProof) (total, collected, collectedWeight int) {
+	selected := make(map[string]bool)
+
+	for _, w := range witnesses {
+		if !n.selects(w) {
+			continue
+		}
+
+		ok := w.Proof != nil && checkLog(n.opts.logRequired, w.HasLog)
+
+		hasProof, exists := selected[w.Witness]
+		if !exists {
+			total++
+		}
+
+		if ok && !hasProof {
+			collected++
+			collectedWeight += n.opts.weightOf(w.Witness)
+		}
+
+		selected[w.Witness] = hasProof || ok
+	}
+
+	return total, collected, collectedWeight
+}
+
+func (n *ruleNode) selects(w *proof.WitnessProof) bool {
+	if n.group != nil {
+		return n.group.contains(w.Witness)
+	}
+
+	return w.Type == n.role
+}
+
+func evaluateThreshold(collected, total, minNumber, minPercent int) bool {
+	percentCollected := float64(maxPercent)
+	if total != 0 {
+		percentCollected = float64(collected) / float64(total)
+	}
+
+	return (minNumber != 0 && collected >= minNumber) ||
+		percentCollected >= float64(minPercent)/maxPercent
+}
+
+func checkLog(logRequired, hasLog bool) bool {
+	if logRequired {
+		return hasLog
+	}
+
+	// log is not required, witness without log is counted for policy
+	return true
+}
+
+func join(nodes []Node, operator string) string {
+	parts := make([]string, len(nodes))
+
+	for i, node := range nodes {
+		switch node.(type) {
+		case *andNode, *orNode:
+			parts[i] = "(" + node.String() + ")"
+		default:
+			parts[i] = node.String()
+		}
+	}
+
+	return strings.Join(parts, " "+operator+" ")
+}
+
+func hostOf(iri string) string {
+	u, err := url.Parse(iri)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+)
+
+const (
+	orgWitness1 = "https://orb.domain1.com/services/orb"
+	orgWitness2 = "https://orb.domain2.com/services/orb"
+	extWitness  = "https://orb.domain3.com/services/orb"
+)
+
+func TestExpression_Evaluate(t *testing.T) {
+	const policy = `Group(org,domain:orb.domain1.com,https://orb.domain2.com/services/orb)
+		Group(ext,domain:orb.domain3.com)
+		(OutOf(2,org) AND OutOf(1,ext)) OR MinPercent(75,system)`
+
+	wp, err := Parse(policy)
+	require.NoError(t, err)
+
+	t.Run("satisfied by groups", func(t *testing.T) {
+		require.True(t, wp.Expression.Evaluate([]*proof.WitnessProof{
+			{Type: proof.WitnessTypeBatch, Witness: orgWitness1, Proof: []byte("proof")},
+			{Type: proof.WitnessTypeSystem, Witness: orgWitness2, Proof: []byte("proof")},
+			{Type: proof.WitnessTypeSystem, Witness: extWitness, Proof: []byte("proof")},
+			{Type: proof.WitnessTypeSystem, Witness: "https://orb.domain4.com/services/orb"},
+			{Type: proof.WitnessTypeSystem, Witness: "https://orb.domain5.com/services/orb"},
+		}))
+	})
+
+	t.Run("satisfied by system percentage", func(t *testing.T) {
+		require.True(t, wp.Expression.Evaluate([]*proof.WitnessProof{
+			{Type: proof.WitnessTypeBatch, Witness: orgWitness1},
+			{Type: proof.WitnessTypeSystem, Witness: orgWitness2, Proof: []byte("proof")},
+			{Type: proof.WitnessTypeSystem, Witness: extWitness, Proof: []byte("proof")},
+			{Type: proof.WitnessTypeSystem, Witness: "https://orb.domain4.com/services/orb", Proof: []byte("proof")},
+			{Type: proof.WitnessTypeSystem, Witness: "https://orb.domain5.com/services/orb"},
+		}))
+	})
+
+	t.Run("not satisfied", func(t *testing.T) {
+		require.False(t, wp.Expression.Evaluate([]*proof.WitnessProof{
+			{Type: proof.WitnessTypeBatch, Witness: orgWitness1, Proof: []byte("proof")},
+			{Type: proof.WitnessTypeSystem, Witness: orgWitness2, Proof: []byte("proof")},
+			{Type: proof.WitnessTypeSystem, Witness: extWitness},
+			{Type: proof.WitnessTypeSystem, Witness: "https://orb.domain4.com/services/orb"},
+			{Type: proof.WitnessTypeSystem, Witness: "https://orb.domain5.com/services/orb"},
+		}))
+	})
+
+	t.Run("witness listed as batch and system is counted once", func(t *testing.T) {
+		require.False(t, wp.Expression.Evaluate([]*proof.WitnessProof{
+			{Type: proof.WitnessTypeBatch, Witness: orgWitness1, Proof: []byte("proof")},
+			{Type: proof.WitnessTypeSystem, Witness: orgWitness1, Proof: []byte("proof")},
+			{Type: proof.WitnessTypeSystem, Witness: orgWitness2},
+			{Type: proof.WitnessTypeSystem, Witness: extWitness, Proof: []byte("proof")},
+		}))
+	})
+}
+
+func TestExpression_MinWeight(t *testing.T) {
+	wp, err := Parse(`Weight(https://orb.domain1.com/services/orb,3) Weight(domain:orb.domain2.com,2)
+		MinWeight(4,system) LogRequired`)
+	require.NoError(t, err)
+
+	t.Run("satisfied", func(t *testing.T) {
+		require.True(t, wp.Expression.Evaluate([]*proof.WitnessProof{
+			{Type: proof.WitnessTypeSystem, Witness: orgWitness1, Proof: []byte("proof"), HasLog: true},
+			{Type: proof.WitnessTypeSystem, Witness: extWitness, Proof: []byte("proof"), HasLog: true},
+		}))
+	})
+
+	t.Run("not satisfied - weight of witness without log is not counted", func(t *testing.T) {
+		require.False(t, wp.Expression.Evaluate([]*proof.WitnessProof{
+			{Type: proof.WitnessTypeSystem, Witness: orgWitness1, Proof: []byte("proof"), HasLog: true},
+			{Type: proof.WitnessTypeSystem, Witness: orgWitness2, Proof: []byte("proof"), HasLog: false},
+		}))
+	})
+
+	t.Run("satisfied - domain weight", func(t *testing.T) {
+		require.True(t, wp.Expression.Evaluate([]*proof.WitnessProof{
+			{Type: proof.WitnessTypeSystem, Witness: orgWitness2, Proof: []byte("proof"), HasLog: true},
+			{Type: proof.WitnessTypeSystem, Witness: extWitness, Proof: []byte("proof"), HasLog: true},
+			{Type: proof.WitnessTypeSystem, Witness: "https://orb.domain4.com/services/orb", Proof: []byte("proof"), HasLog: true},
+		}))
+	})
+}
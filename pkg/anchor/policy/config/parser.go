@@ -10,9 +10,35 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
 )
 
 // WitnessPolicyConfig parses witness policy.
+//
+// The policy is an expression made up of rules that are combined with AND and OR operators and
+// grouped with parentheses, for example:
+//
+//	Group(org,domain:orb.domain1.com,domain:orb.domain2.com)
+//	Weight(https://orb.domain1.com/services/orb,2)
+//	(OutOf(2,org) AND OutOf(1,batch)) OR MinPercent(75,system)
+//
+// The following rules are supported, where the second argument selects the witnesses by role
+// (batch or system) or by the name of a group:
+//
+//	OutOf(n,selector)      - proofs from at least n (or all) of the selected witnesses are required
+//	MinPercent(p,selector) - proofs from at least p percent of the selected witnesses are required
+//	MinWeight(n,selector)  - the total weight of the selected witnesses with a proof must be at least n
+//
+// Groups are defined with Group(name,member,...) where a member is either a witness IRI or
+// a domain prefixed with "domain:". Weights are assigned with Weight(member,n); witnesses without
+// a weight have a weight of 1. LogRequired means that only witnesses which have a log are counted.
+//
+// Flat policies which only contain batch and system rules joined by a single operator (e.g.
+// "OutOf(2,system) AND MinPercent(50,batch)") keep their original semantics: a role that is not
+// mentioned in the policy requires proofs from all of its witnesses. The MinNumber*, MinPercent* and
+// Operator fields are only populated for flat policies.
 type WitnessPolicyConfig struct {
 	MinNumberSystem int
 	MinNumberBatch  int
@@ -23,25 +49,42 @@ type WitnessPolicyConfig struct {
 	Operator operatorFnc
 
 	LogRequired bool
+
+	Groups  map[string]*Group
+	Weights map[string]int
+
+	// Expression is the parsed policy that is evaluated against witness proofs.
+	Expression Node
 }
 
 // Gate values.
 const (
 	OutOf       = "OutOf"
 	MinPercent  = "MinPercent"
+	MinWeight   = "MinWeight"
 	LogRequired = "LogRequired"
 
 	AND = "AND"
 	OR  = "OR"
 )
 
+// Definition values.
+const (
+	GroupDefinition  = "Group"
+	WeightDefinition = "Weight"
+)
+
 // Role values.
 const (
 	RoleBatch  = "batch"
 	RoleSystem = "system"
 )
 
-const maxPercent = 100
+const (
+	maxPercent = 100
+
+	domainPrefix = "domain:"
+)
 
 type operatorFnc func(a, b bool) bool
 
@@ -52,125 +95,563 @@ func Parse(policy string) (*WitnessPolicyConfig, error) {
 		MinPercentBatch:  maxPercent,
 		MinPercentSystem: maxPercent,
 		Operator:         and,
+		Groups:           make(map[string]*Group),
+		Weights:          make(map[string]int),
+	}
+
+	p := &parser{
+		policy: policy,
+		tokens: tokenize(policy),
+		cfg:    wp,
+		opts:   &evalOptions{weights: wp.Weights},
+	}
+
+	expr, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.resolveSelectors()
+	if err != nil {
+		return nil, err
+	}
+
+	wp.LogRequired = p.opts.logRequired
+
+	if expr == nil || p.isFlat(expr) {
+		expr = p.toFlatPolicy(expr)
+	}
+
+	wp.Expression = expr
+
+	return wp, nil
+}
+
+func (wp *WitnessPolicyConfig) String() string {
+	if wp.Expression == nil {
+		return fmt.Sprintf("minBatch:%d, minSystem:%d, percentBatch:%d, percentSystem:%d, log:%t",
+			wp.MinNumberBatch, wp.MinNumberSystem, wp.MinPercentBatch, wp.MinPercentSystem, wp.LogRequired)
+	}
+
+	return fmt.Sprintf("expression:%s, log:%t", wp.Expression, wp.LogRequired)
+}
+
+type tokenType int
+
+const (
+	tokenIdent tokenType = iota
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenEOF
+)
+
+type token struct {
+	typ   tokenType
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	if t.typ == tokenEOF {
+		return "end of policy"
+	}
+
+	return fmt.Sprintf("'%s'", t.value)
+}
+
+// tokenize splits the policy into parentheses, commas and identifiers. An identifier is any
+// run of characters other than white space, parentheses and commas, which allows IRIs and
+// domains to be used as arguments without quoting.
+func tokenize(policy string) []token {
+	var tokens []token
+
+	start := -1
+
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, token{typ: tokenIdent, value: policy[start:end], pos: start})
+			start = -1
+		}
+	}
+
+	for i, r := range policy {
+		switch {
+		case unicode.IsSpace(r):
+			flush(i)
+		case r == '(':
+			flush(i)
+			tokens = append(tokens, token{typ: tokenLParen, value: "(", pos: i})
+		case r == ')':
+			flush(i)
+			tokens = append(tokens, token{typ: tokenRParen, value: ")", pos: i})
+		case r == ',':
+			flush(i)
+			tokens = append(tokens, token{typ: tokenComma, value: ",", pos: i})
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+
+	flush(len(policy))
+
+	return append(tokens, token{typ: tokenEOF, pos: len(policy)})
+}
+
+type parser struct {
+	policy string
+	tokens []token
+	pos    int
+
+	cfg   *WitnessPolicyConfig
+	opts  *evalOptions
+	rules []*ruleNode
+
+	// nested is set if parentheses are used to group sub-expressions.
+	nested bool
+	// hasDefinitions is set if the policy contains group or weight definitions.
+	hasDefinitions bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+
+	if t.typ != tokenEOF {
+		p.pos++
 	}
 
-	if policy == "" {
-		return wp, nil
+	return t
+}
+
+func (p *parser) expect(typ tokenType, expected string) (token, error) {
+	t := p.next()
+	if t.typ != typ {
+		return t, fmt.Errorf("parse error at position %d: expected %s but got %s", t.pos, expected, t)
 	}
 
-	tokens := strings.Split(policy, " ")
+	return t, nil
+}
+
+// parse parses the policy:
+//
+//	policy     = { definition | "LogRequired" } [ expression ] { definition | "LogRequired" }
+//	expression = term { "OR" term }
+//	term       = factor { "AND" factor }
+//	factor     = "(" expression ")" | rule
+func (p *parser) parse() (Node, error) {
+	var expr Node
+
+	for p.peek().typ != tokenEOF {
+		t := p.peek()
+
+		switch {
+		case t.typ == tokenIdent && t.value == LogRequired:
+			p.next()
+
+			p.opts.logRequired = true
+		case t.typ == tokenIdent && t.value == GroupDefinition:
+			if err := p.parseGroup(); err != nil {
+				return nil, err
+			}
+		case t.typ == tokenIdent && t.value == WeightDefinition:
+			if err := p.parseWeight(); err != nil {
+				return nil, err
+			}
+		case expr != nil:
+			return nil, fmt.Errorf("parse error at position %d: unexpected %s after expression", t.pos, t)
+		default:
+			var err error
+
+			expr, err = p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return expr, nil
+}
+
+func (p *parser) parseOr() (Node, error) {
+	return p.parseOperator(OR, p.parseAnd, func(nodes []Node) Node { return &orNode{nodes: nodes} })
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	return p.parseOperator(AND, p.parseFactor, func(nodes []Node) Node { return &andNode{nodes: nodes} })
+}
+
+func (p *parser) parseOperator(operator string, parseOperand func() (Node, error),
+	newNode func([]Node) Node) (Node, error) {
+	node, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
 
-	for _, token := range tokens {
-		err := wp.processToken(token)
+	nodes := []Node{node}
+
+	for p.peek().typ == tokenIdent && p.peek().value == operator {
+		p.next()
+
+		node, err = parseOperand()
 		if err != nil {
 			return nil, err
 		}
+
+		nodes = append(nodes, node)
 	}
 
-	return wp, nil
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+
+	return newNode(nodes), nil
 }
 
-func (wp *WitnessPolicyConfig) processToken(token string) error {
-	switch t := token; {
-	case strings.HasPrefix(t, OutOf):
-		err := wp.processOutOf(token)
+func (p *parser) parseFactor() (Node, error) {
+	t := p.peek()
+
+	switch t.typ {
+	case tokenLParen:
+		p.next()
+
+		p.nested = true
+
+		expr, err := p.parseOr()
 		if err != nil {
-			return err
+			return nil, err
 		}
-	case strings.HasPrefix(t, MinPercent):
-		err := wp.processMinPercent(token)
-		if err != nil {
-			return err
+
+		if _, err := p.expect(tokenRParen, "')'"); err != nil {
+			return nil, err
 		}
-	case t == LogRequired:
-		wp.LogRequired = true
-	case t == AND:
-		wp.Operator = and
-	case t == OR:
-		wp.Operator = or
+
+		return expr, nil
+	case tokenIdent:
+		return p.parseRule()
+	default:
+		return nil, fmt.Errorf("parse error at position %d: expected rule or '(' but got %s", t.pos, t)
+	}
+}
+
+func (p *parser) parseRule() (Node, error) {
+	start := p.next()
+
+	switch start.value {
+	case OutOf, MinPercent, MinWeight:
 	default:
-		return fmt.Errorf("rule not supported: %s", token)
+		return nil, fmt.Errorf("rule not supported: %s", p.skipRule(start))
+	}
+
+	args, err := p.parseArgs(start.value)
+	if err != nil {
+		return nil, err
+	}
+
+	const ruleArgsNo = 2
+	if len(args) != ruleArgsNo {
+		return nil, fmt.Errorf("expected 2 but got %d arguments for %s policy", len(args), start.value)
+	}
+
+	rule := &ruleNode{
+		name:     start.value,
+		text:     p.policy[start.pos : p.tokens[p.pos-1].pos+1],
+		selector: args[1],
+		opts:     p.opts,
+	}
+
+	if err := rule.setValue(args[0]); err != nil {
+		return nil, err
+	}
+
+	p.rules = append(p.rules, rule)
+
+	return rule, nil
+}
+
+func (n *ruleNode) setValue(arg string) error {
+	value, err := strconv.Atoi(arg)
+
+	switch n.name {
+	case OutOf:
+		if err != nil {
+			return fmt.Errorf("first argument for OutOf policy must be an integer: %w", err)
+		}
+
+		// OutOf(0,...) is always satisfied
+		n.minNumber = value
+		if value != 0 {
+			n.minPercent = maxPercent
+		}
+	case MinPercent:
+		if err != nil {
+			return fmt.Errorf("first argument for OutOf policy must be an integer between 0 and 100: %w", err)
+		}
+
+		if value < 0 || value > 100 {
+			return fmt.Errorf("first argument for OutOf policy must be an integer between 0 and 100")
+		}
+
+		n.minPercent = value
+	case MinWeight:
+		if err != nil || value < 0 {
+			return fmt.Errorf("first argument for MinWeight policy must be a non-negative integer")
+		}
+
+		n.kind = weightRule
+		n.minWeight = value
 	}
 
 	return nil
 }
 
-// processOutOf rule (e.g. OutOf(2,system) rule means that proofs from at least 2 system witnesses are required.
-func (wp *WitnessPolicyConfig) processOutOf(token string) error {
-	insideBrackets := token[len(OutOf)+1 : len(token)-1]
+// parseArgs parses a parenthesized, comma separated list of arguments.
+func (p *parser) parseArgs(name string) ([]string, error) {
+	if _, err := p.expect(tokenLParen, fmt.Sprintf("'(' after %s", name)); err != nil {
+		return nil, err
+	}
 
-	outOfArgs := strings.Split(insideBrackets, ",")
+	var args []string
 
-	const outOfArgsNo = 2
-	if len(outOfArgs) != outOfArgsNo {
-		return fmt.Errorf("expected 2 but got %d arguments for OutOf policy", len(outOfArgs))
+	for {
+		t, err := p.expect(tokenIdent, fmt.Sprintf("argument for %s", name))
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, t.value)
+
+		t = p.next()
+
+		switch t.typ {
+		case tokenComma:
+			continue
+		case tokenRParen:
+			return args, nil
+		default:
+			return nil, fmt.Errorf("parse error at position %d: expected ',' or ')' in %s but got %s", t.pos, name, t)
+		}
 	}
+}
 
-	minNo, err := strconv.Atoi(outOfArgs[0])
-	if err != nil {
-		return fmt.Errorf("first argument for OutOf policy must be an integer: %w", err)
+// skipRule consumes the arguments (if any) of an unsupported rule and returns the text of the rule.
+func (p *parser) skipRule(start token) string {
+	end := start.pos + len(start.value)
+
+	if p.peek().typ != tokenLParen {
+		return p.policy[start.pos:end]
 	}
 
-	switch outOfArgs[1] {
-	case RoleSystem:
-		wp.MinNumberSystem = minNo
+	depth := 0
+
+	for p.peek().typ != tokenEOF {
+		t := p.next()
 
-		if wp.MinNumberSystem == 0 {
-			wp.MinPercentSystem = 0
+		switch t.typ { //nolint:exhaustive
+		case tokenLParen:
+			depth++
+		case tokenRParen:
+			depth--
 		}
 
-	case RoleBatch:
-		wp.MinNumberBatch = minNo
+		end = t.pos + len(t.value)
 
-		if wp.MinNumberBatch == 0 {
-			wp.MinPercentBatch = 0
+		if depth == 0 {
+			break
 		}
+	}
 
-	default:
-		return fmt.Errorf("role '%s' not supported for OutOf policy", outOfArgs[1])
+	return p.policy[start.pos:end]
+}
+
+func (p *parser) parseGroup() error {
+	p.next()
+
+	p.hasDefinitions = true
+
+	args, err := p.parseArgs(GroupDefinition)
+	if err != nil {
+		return err
+	}
+
+	const minGroupArgs = 2
+	if len(args) < minGroupArgs {
+		return fmt.Errorf("expected a name and at least one member for %s definition", GroupDefinition)
 	}
 
+	name := args[0]
+
+	if isReserved(name) {
+		return fmt.Errorf("'%s' is a reserved word and cannot be used as a group name", name)
+	}
+
+	if _, exists := p.cfg.Groups[name]; exists {
+		return fmt.Errorf("group '%s' is defined more than once", name)
+	}
+
+	group := &Group{Name: name}
+
+	for _, member := range args[1:] {
+		if strings.HasPrefix(member, domainPrefix) {
+			group.Domains = append(group.Domains, strings.TrimPrefix(member, domainPrefix))
+		} else {
+			group.IRIs = append(group.IRIs, member)
+		}
+	}
+
+	p.cfg.Groups[name] = group
+
 	return nil
 }
 
-// processMinPercent will process minimum percent rule.
-// e.g. MinPercent(0.2,system) rule means that proofs from at least 20% of system witnesses are required.
-func (wp *WitnessPolicyConfig) processMinPercent(token string) error {
-	insideBrackets := token[len(MinPercent)+1 : len(token)-1]
+func (p *parser) parseWeight() error {
+	p.next()
 
-	minPercentArgs := strings.Split(insideBrackets, ",")
+	p.hasDefinitions = true
 
-	const minPercentArgsNo = 2
-	if len(minPercentArgs) != minPercentArgsNo {
-		return fmt.Errorf("expected 2 but got %d arguments for MinPercent policy", len(minPercentArgs))
+	args, err := p.parseArgs(WeightDefinition)
+	if err != nil {
+		return err
 	}
 
-	minPercent, err := strconv.Atoi(minPercentArgs[0])
-	if err != nil {
-		return fmt.Errorf("first argument for OutOf policy must be an integer between 0 and 100: %w", err)
+	const weightArgsNo = 2
+	if len(args) != weightArgsNo {
+		return fmt.Errorf("expected 2 but got %d arguments for %s definition", len(args), WeightDefinition)
 	}
 
-	if minPercent < 0 || minPercent > 100 {
-		return fmt.Errorf("first argument for OutOf policy must be an integer between 0 and 100")
+	weight, err := strconv.Atoi(args[1])
+	if err != nil || weight < 0 {
+		return fmt.Errorf("second argument for %s definition must be a non-negative integer", WeightDefinition)
 	}
 
-	switch minPercentArgs[1] {
-	case RoleSystem:
-		wp.MinPercentSystem = minPercent
+	member := args[0]
+	if strings.HasPrefix(member, domainPrefix) {
+		member = strings.ToLower(member)
+	}
 
-	case RoleBatch:
-		wp.MinPercentBatch = minPercent
+	p.cfg.Weights[member] = weight
 
-	default:
-		return fmt.Errorf("role '%s' not supported for MinPercent policy", minPercentArgs[1])
+	return nil
+}
+
+// resolveSelectors resolves the role or group of each rule. This is done after the entire policy
+// has been parsed so that groups may be defined anywhere in the policy.
+func (p *parser) resolveSelectors() error {
+	for _, rule := range p.rules {
+		switch rule.selector {
+		case RoleBatch:
+			rule.role = proof.WitnessTypeBatch
+		case RoleSystem:
+			rule.role = proof.WitnessTypeSystem
+		default:
+			group, ok := p.cfg.Groups[rule.selector]
+			if !ok {
+				return fmt.Errorf("role '%s' not supported for %s policy: it is neither a role nor a defined group",
+					rule.selector, rule.name)
+			}
+
+			rule.group = group
+		}
 	}
 
 	return nil
 }
 
-func (wp *WitnessPolicyConfig) String() string {
-	return fmt.Sprintf("minBatch:%d, minSystem:%d, percentBatch:%d, percentSystem:%d, log:%t",
-		wp.MinNumberBatch, wp.MinNumberSystem, wp.MinPercentBatch, wp.MinPercentSystem, wp.LogRequired)
+// isFlat returns true if the expression is a single rule or a list of rules joined by one operator,
+// where all rules select witnesses by role.
+func (p *parser) isFlat(expr Node) bool {
+	if p.nested || p.hasDefinitions {
+		return false
+	}
+
+	for _, rule := range p.rules {
+		if rule.kind != thresholdRule || rule.group != nil {
+			return false
+		}
+	}
+
+	switch n := expr.(type) {
+	case *ruleNode:
+		return true
+	case *andNode:
+		return allRules(n.nodes)
+	case *orNode:
+		return allRules(n.nodes)
+	default:
+		return false
+	}
+}
+
+// toFlatPolicy converts a flat expression into an expression that evaluates the batch
+// and system witnesses with the original semantics of the policy, i.e. a role that is not
+// mentioned in the policy requires proofs from all of its witnesses.
+func (p *parser) toFlatPolicy(expr Node) Node {
+	wp := p.cfg
+
+	if _, ok := expr.(*orNode); ok {
+		wp.Operator = or
+	}
+
+	for _, rule := range p.rules {
+		if rule.role == proof.WitnessTypeSystem {
+			rule.applyTo(&wp.MinNumberSystem, &wp.MinPercentSystem)
+		} else {
+			rule.applyTo(&wp.MinNumberBatch, &wp.MinPercentBatch)
+		}
+	}
+
+	batch := &ruleNode{
+		text: fmt.Sprintf("%s(%d,%d%%,%s)", OutOf, wp.MinNumberBatch, wp.MinPercentBatch, RoleBatch),
+		role: proof.WitnessTypeBatch, minNumber: wp.MinNumberBatch, minPercent: wp.MinPercentBatch, opts: p.opts,
+	}
+
+	system := &ruleNode{
+		text: fmt.Sprintf("%s(%d,%d%%,%s)", OutOf, wp.MinNumberSystem, wp.MinPercentSystem, RoleSystem),
+		role: proof.WitnessTypeSystem, minNumber: wp.MinNumberSystem, minPercent: wp.MinPercentSystem, opts: p.opts,
+	}
+
+	if _, ok := expr.(*orNode); ok {
+		return &orNode{nodes: []Node{batch, system}}
+	}
+
+	return &andNode{nodes: []Node{batch, system}}
+}
+
+// applyTo applies a flat OutOf or MinPercent rule to the minimum number and minimum percent of a role.
+func (n *ruleNode) applyTo(minNumber, minPercent *int) {
+	switch {
+	case n.name == OutOf && n.minNumber == 0:
+		*minNumber = 0
+		*minPercent = 0
+	case n.name == OutOf:
+		*minNumber = n.minNumber
+	default:
+		*minPercent = n.minPercent
+	}
+}
+
+func allRules(nodes []Node) bool {
+	for _, node := range nodes {
+		if _, ok := node.(*ruleNode); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isReserved(name string) bool {
+	switch name {
+	case OutOf, MinPercent, MinWeight, LogRequired, AND, OR, GroupDefinition, WeightDefinition, RoleBatch, RoleSystem:
+		return true
+	default:
+		return false
+	}
 }
 
 func and(a, b bool) bool {
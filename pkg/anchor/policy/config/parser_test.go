@@ -163,3 +163,62 @@ func TestParse_LogRequired(t *testing.T) {
 		require.Equal(t, and(true, false), wp.Operator(true, false))
 	})
 }
+
+func TestParse_Expression(t *testing.T) {
+	t.Run("success - nested expression with groups and weights", func(t *testing.T) {
+		wp, err := Parse(`Group(org,domain:orb.domain1.com,https://orb.domain2.com/services/orb)
+			Weight(https://orb.domain1.com/services/orb,2)
+			(OutOf(2,org) AND MinWeight(1,batch)) OR MinPercent(75,system) LogRequired`)
+		require.NoError(t, err)
+		require.NotNil(t, wp)
+
+		require.True(t, wp.LogRequired)
+		require.Len(t, wp.Groups, 1)
+		require.Equal(t, []string{"orb.domain1.com"}, wp.Groups["org"].Domains)
+		require.Equal(t, []string{"https://orb.domain2.com/services/orb"}, wp.Groups["org"].IRIs)
+		require.Equal(t, 2, wp.Weights["https://orb.domain1.com/services/orb"])
+		require.Equal(t, "(OutOf(2,org) AND MinWeight(1,batch)) OR MinPercent(75,system)", wp.Expression.String())
+		require.NotEmpty(t, wp.String())
+		require.NotEmpty(t, wp.Groups["org"].String())
+	})
+
+	t.Run("success - AND takes precedence over OR", func(t *testing.T) {
+		wp, err := Parse("OutOf(1,system) OR OutOf(1,batch) AND MinPercent(50,batch)")
+		require.NoError(t, err)
+		require.Equal(t, "OutOf(1,system) OR (OutOf(1,batch) AND MinPercent(50,batch))", wp.Expression.String())
+	})
+
+	t.Run("success - group may be defined after it is used", func(t *testing.T) {
+		wp, err := Parse("OutOf(1,ext) Group(ext,domain:orb.domain3.com)")
+		require.NoError(t, err)
+		require.Equal(t, "OutOf(1,ext)", wp.Expression.String())
+	})
+
+	t.Run("error - syntax errors", func(t *testing.T) {
+		tests := map[string]string{
+			"(OutOf(1,system)":                    "parse error at position 16: expected ')' but got end of policy",
+			"OutOf(1,system) OutOf(1,batch)":      "parse error at position 16: unexpected 'OutOf' after expression",
+			"OutOf(1,system) AND":                 "parse error at position 19: expected rule or '(' but got end of policy",
+			"OutOf 1,system":                      "parse error at position 6: expected '(' after OutOf but got '1'",
+			"OutOf(1 system)":                     "parse error at position 8: expected ',' or ')' in OutOf but got 'system'",
+			"OutOf(1,)":                           "parse error at position 8: expected argument for OutOf but got ')'",
+			"OutOf(1,org)":                        "role 'org' not supported for OutOf policy",
+			"MinWeight(-1,system)":                "first argument for MinWeight policy must be a non-negative integer",
+			"Group(org)":                          "expected a name and at least one member for Group definition",
+			"Group(system,domain:orb.domain.com)": "'system' is a reserved word and cannot be used as a group name",
+			"Group(a,x) Group(a,y)":               "group 'a' is defined more than once",
+			"Weight(https://orb.domain1.com)":     "expected 2 but got 1 arguments for Weight definition",
+			"Weight(https://orb.domain1.com,x)":   "second argument for Weight definition must be a non-negative integer",
+			"Group(a,x":                           "parse error at position 9: expected ',' or ')' in Group but got end of policy",
+			"Weight(x,1":                          "parse error at position 10: expected ',' or ')' in Weight but got end of policy",
+			"(Test(1,(2)) AND OutOf(1,system))":   "rule not supported: Test(1,(2))",
+		}
+
+		for policy, expectedErr := range tests {
+			wp, err := Parse(policy)
+			require.Errorf(t, err, "policy: %s", policy)
+			require.Nil(t, wp)
+			require.Contains(t, err.Error(), expectedErr)
+		}
+	})
+}
@@ -30,8 +30,6 @@ const (
 	// WitnessPolicyKey is witness policy key in config store.
 	WitnessPolicyKey = "witness-policy"
 
-	maxPercent = 100
-
 	defaultCacheSize = 10
 )
 
@@ -71,39 +69,9 @@ func (wp *WitnessPolicy) Evaluate(witnesses []*proof.WitnessProof) (bool, error)
 		return false, err
 	}
 
-	totalSystemWitnesses := 0
-	collectedSystemWitnesses := 0
-
-	totalBatchWitnesses := 0
-	collectedBatchWitnesses := 0
-
-	for _, w := range witnesses {
-		logOK := checkLog(cfg.LogRequired, w.HasLog)
-
-		switch w.Type {
-		case proof.WitnessTypeBatch:
-			totalBatchWitnesses++
-
-			if logOK && w.Proof != nil {
-				collectedBatchWitnesses++
-			}
-
-		case proof.WitnessTypeSystem:
-			totalSystemWitnesses++
-
-			if logOK && w.Proof != nil {
-				collectedSystemWitnesses++
-			}
-		}
-	}
-
-	batchCondition := evaluate(collectedBatchWitnesses, totalBatchWitnesses, cfg.MinNumberBatch, cfg.MinPercentBatch)
-	systemCondition := evaluate(collectedSystemWitnesses, totalSystemWitnesses, cfg.MinNumberSystem, cfg.MinPercentSystem)
+	evaluated := cfg.Expression.Evaluate(witnesses)
 
-	evaluated := cfg.Operator(batchCondition, systemCondition)
-
-	logger.Debugf("witness policy[%s] evaluated to[%t] with batch[%t] and system[%t] for witnesses: %s",
-		cfg, evaluated, batchCondition, systemCondition, witnesses)
+	logger.Debugf("witness policy[%s] evaluated to[%t] for witnesses: %s", cfg, evaluated, witnesses)
 
 	return evaluated, nil
 }
@@ -146,22 +114,3 @@ func (wp *WitnessPolicy) getWitnessPolicyConfig() (*config.WitnessPolicyConfig,
 
 	return policyCfg, nil
 }
-
-func evaluate(collected, total, minNumber, minPercent int) bool {
-	percentCollected := float64(maxPercent)
-	if total != 0 {
-		percentCollected = float64(collected) / float64(total)
-	}
-
-	return (minNumber != 0 && collected >= minNumber) ||
-		percentCollected >= float64(minPercent)/maxPercent
-}
-
-func checkLog(logRequired, hasLog bool) bool {
-	if logRequired {
-		return hasLog
-	}
-
-	// log is not required, witness without log is counted for policy
-	return true
-}
@@ -651,6 +651,46 @@ func TestEvaluate(t *testing.T) {
 		require.Equal(t, true, ok)
 	})
 
+	t.Run("success - expression policy with groups", func(t *testing.T) {
+		configStore, err := mem.NewProvider().OpenStore(configStoreName)
+		require.NoError(t, err)
+
+		err = configStore.Put(WitnessPolicyKey, []byte(
+			"Group(org,domain:orb.domain1.com) (OutOf(1,org) AND OutOf(1,batch)) OR MinPercent(100,system)"))
+		require.NoError(t, err)
+
+		wp, err := New(configStore, defaultPolicyCacheExpiry)
+		require.NoError(t, err)
+		require.NotNil(t, wp)
+
+		witnessProofs := []*proof.WitnessProof{
+			{
+				Type:    proof.WitnessTypeBatch,
+				Witness: "https://orb.domain2.com/services/orb",
+				Proof:   []byte("proof"),
+			},
+			{
+				Type:    proof.WitnessTypeSystem,
+				Witness: "https://orb.domain1.com/services/orb",
+				Proof:   []byte("proof"),
+			},
+			{
+				Type:    proof.WitnessTypeSystem,
+				Witness: "https://orb.domain3.com/services/orb",
+			},
+		}
+
+		ok, err := wp.Evaluate(witnessProofs)
+		require.NoError(t, err)
+		require.Equal(t, true, ok)
+
+		witnessProofs[0].Proof = nil
+
+		ok, err = wp.Evaluate(witnessProofs)
+		require.NoError(t, err)
+		require.Equal(t, false, ok)
+	})
+
 	t.Run("error - get policy from cache error", func(t *testing.T) {
 		configStore, err := mem.NewProvider().OpenStore(configStoreName)
 		require.NoError(t, err)
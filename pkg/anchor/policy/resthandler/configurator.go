@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package resthandler
 
 import (
+	"fmt"
 	"io/ioutil"
 	"net/http"
 
@@ -14,14 +15,22 @@ import (
 	"github.com/trustbloc/edge-core/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 
-	"github.com/trustbloc/orb/pkg/anchor/policy"
 	"github.com/trustbloc/orb/pkg/anchor/policy/config"
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
 )
 
-const endpoint = "/policy"
+const (
+	endpoint         = "/policy"
+	historyEndpoint  = endpoint + "/history"
+	rollbackEndpoint = endpoint + "/rollback/{" + versionPathVariable + "}"
+	dryRunEndpoint   = endpoint + "/dryrun"
+
+	versionPathVariable = "version"
+)
 
 const (
 	badRequestResponse          = "Bad Request."
+	notFoundResponse            = "Not Found."
 	internalServerErrorResponse = "Internal Server Error."
 )
 
@@ -30,7 +39,7 @@ var logger = log.New("policy-rest-handler")
 // PolicyConfigurator updates witness policy in config store.
 type PolicyConfigurator struct {
 	VerifyActorInSignature bool
-	configStore            storage.Store
+	store                  *policyStore
 }
 
 // Path returns the HTTP REST endpoint for the PolicyConfigurator service.
@@ -51,7 +60,7 @@ func (pc *PolicyConfigurator) Handler() common.HTTPRequestHandler {
 // New returns a new PolicyConfigurator.
 func New(cfgStore storage.Store) *PolicyConfigurator {
 	h := &PolicyConfigurator{
-		configStore: cfgStore,
+		store: newPolicyStore(cfgStore),
 	}
 
 	return h
@@ -62,7 +71,7 @@ func (pc *PolicyConfigurator) handle(w http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		logger.Errorf("[%s] Error reading request body: %s", endpoint, err)
 
-		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+		writeResponse(w, endpoint, http.StatusBadRequest, []byte(badRequestResponse))
 
 		return
 	}
@@ -71,35 +80,35 @@ func (pc *PolicyConfigurator) handle(w http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		logger.Errorf("[%s] Invalid witness policy: %s", endpoint, err)
 
-		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+		writeResponse(w, endpoint, http.StatusBadRequest, []byte(fmt.Sprintf("%s Invalid witness policy: %s", badRequestResponse, err)))
 
 		return
 	}
 
-	err = pc.configStore.Put(policy.WitnessPolicyKey, policyBytes)
+	entry, err := pc.store.Put(string(policyBytes), auth.TokenIDFromContext(req.Context()))
 	if err != nil {
 		logger.Errorf("[%s] Error storing witness policy: %s", endpoint, err)
 
-		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+		writeResponse(w, endpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
 
 		return
 	}
 
-	logger.Debugf("[%s] Stored witness policy %s", endpoint, string(policyBytes))
+	logger.Debugf("[%s] Stored witness policy version %d: %s", endpoint, entry.Version, entry.Policy)
 
-	writeResponse(w, http.StatusOK, nil)
+	writeResponse(w, endpoint, http.StatusOK, nil)
 }
 
-func writeResponse(w http.ResponseWriter, status int, body []byte) {
+func writeResponse(w http.ResponseWriter, path string, status int, body []byte) {
 	w.WriteHeader(status)
 
 	if len(body) > 0 {
 		if _, err := w.Write(body); err != nil {
-			logger.Warnf("[%s] Unable to write response: %s", endpoint, err)
+			logger.Warnf("[%s] Unable to write response: %s", path, err)
 
 			return
 		}
 
-		logger.Debugf("[%s] Wrote response: %s", endpoint, body)
+		logger.Debugf("[%s] Wrote response: %s", path, body)
 	}
 }
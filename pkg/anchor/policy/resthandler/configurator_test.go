@@ -15,8 +15,11 @@ import (
 	"testing"
 
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/orb/pkg/anchor/policy"
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
 	storemocks "github.com/trustbloc/orb/pkg/store/mocks"
 )
 
@@ -57,6 +60,32 @@ func TestHandler(t *testing.T) {
 		require.NoError(t, err)
 		require.Empty(t, respBytes)
 		require.NoError(t, result.Body.Close())
+
+		policyBytes, err := configStore.Get(policy.WitnessPolicyKey)
+		require.NoError(t, err)
+		require.Equal(t, testPolicy, string(policyBytes))
+	})
+
+	t.Run("success - token ID recorded in history", func(t *testing.T) {
+		configStore, err := mem.NewProvider().OpenStore(configStoreName)
+		require.NoError(t, err)
+
+		policyConfigurator := New(configStore)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer([]byte(testPolicy)))
+		req = req.WithContext(auth.ContextWithTokenID(req.Context(), "admin"))
+
+		policyConfigurator.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+
+		history, err := newPolicyStore(configStore).History()
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		require.Equal(t, "admin", history[0].ChangedBy)
 	})
 
 	t.Run("error - reader error", func(t *testing.T) {
@@ -99,13 +128,36 @@ func TestHandler(t *testing.T) {
 
 		respBytes, err := ioutil.ReadAll(result.Body)
 		require.NoError(t, err)
-		require.Equal(t, []byte(badRequestResponse), respBytes)
+		require.Equal(t, "Bad Request. Invalid witness policy: rule not supported: InvalidPolicy", string(respBytes))
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - policy expression syntax error", func(t *testing.T) {
+		configStore, err := mem.NewProvider().OpenStore(configStoreName)
+		require.NoError(t, err)
+
+		policyConfigurator := New(configStore)
+		require.NotNil(t, policyConfigurator)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, endpoint,
+			bytes.NewBuffer([]byte("(OutOf(1,system) AND OutOf(1,batch)")))
+
+		policyConfigurator.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(respBytes), "parse error at position 35: expected ')' but got end of policy")
 		require.NoError(t, result.Body.Close())
 	})
 
 	t.Run("error - config store error", func(t *testing.T) {
 		configStore := &storemocks.Store{}
-		configStore.PutReturns(fmt.Errorf("put error"))
+		configStore.GetReturns(nil, storage.ErrDataNotFound)
+		configStore.BatchReturns(fmt.Errorf("batch error"))
 
 		policyConfigurator := New(configStore)
 		require.NotNil(t, policyConfigurator)
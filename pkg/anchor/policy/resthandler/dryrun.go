@@ -0,0 +1,203 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/anchor/policy/config"
+	"github.com/trustbloc/orb/pkg/store/witness"
+)
+
+const (
+	limitQueryParam = "limit"
+
+	defaultDryRunLimit = 100
+)
+
+type witnessHistory interface {
+	GetRecent(maxItems int) ([]*witness.CompletedAnchor, error)
+}
+
+// DryRunResult contains the result of evaluating a witness policy for an anchor credential.
+type DryRunResult struct {
+	AnchorCredential string    `json:"anchorCredential"`
+	CompletedAt      time.Time `json:"completedAt"`
+	Witnesses        int       `json:"witnesses"`
+	Proofs           int       `json:"proofs"`
+
+	// Completed is true if the candidate policy is satisfied by the witness proofs.
+	Completed bool `json:"completed"`
+	// CompletedWithActivePolicy is true if the active policy is satisfied by the witness proofs.
+	CompletedWithActivePolicy bool `json:"completedWithActivePolicy"`
+}
+
+// DryRunResponse is the response of the policy dry-run endpoint.
+type DryRunResponse struct {
+	Policy       string          `json:"policy"`
+	ActivePolicy string          `json:"activePolicy"`
+	Evaluated    int             `json:"evaluated"`
+	Completed    int             `json:"completed"`
+	Results      []*DryRunResult `json:"results"`
+}
+
+// PolicyDryRun evaluates a candidate witness policy against the witness proofs of the most recently
+// completed anchor credentials (newest first) and reports which of them would have completed.
+// The candidate policy is not stored.
+type PolicyDryRun struct {
+	store          *policyStore
+	witnessHistory witnessHistory
+	marshal        func(v interface{}) ([]byte, error)
+}
+
+// NewDryRun returns a new PolicyDryRun.
+func NewDryRun(cfgStore storage.Store, witnessHistory witnessHistory) *PolicyDryRun {
+	return &PolicyDryRun{
+		store:          newPolicyStore(cfgStore),
+		witnessHistory: witnessHistory,
+		marshal:        json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the PolicyDryRun service.
+func (pd *PolicyDryRun) Path() string {
+	return dryRunEndpoint
+}
+
+// Method returns the HTTP REST method for the PolicyDryRun service.
+func (pd *PolicyDryRun) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the PolicyDryRun service.
+func (pd *PolicyDryRun) Handler() common.HTTPRequestHandler {
+	return pd.handle
+}
+
+func (pd *PolicyDryRun) handle(w http.ResponseWriter, req *http.Request) {
+	policyBytes, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		logger.Errorf("[%s] Error reading request body: %s", dryRunEndpoint, err)
+
+		writeResponse(w, dryRunEndpoint, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	limit, err := getLimit(req)
+	if err != nil {
+		logger.Infof("[%s] Invalid limit: %s", dryRunEndpoint, err)
+
+		writeResponse(w, dryRunEndpoint, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	candidate, err := config.Parse(string(policyBytes))
+	if err != nil {
+		logger.Infof("[%s] Invalid witness policy: %s", dryRunEndpoint, err)
+
+		writeResponse(w, dryRunEndpoint, http.StatusBadRequest,
+			[]byte(fmt.Sprintf("%s Invalid witness policy: %s", badRequestResponse, err)))
+
+		return
+	}
+
+	resp, err := pd.evaluate(candidate, string(policyBytes), limit)
+	if err != nil {
+		logger.Errorf("[%s] Error evaluating witness policy: %s", dryRunEndpoint, err)
+
+		writeResponse(w, dryRunEndpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	respBytes, err := pd.marshal(resp)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling response: %s", dryRunEndpoint, err)
+
+		writeResponse(w, dryRunEndpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, dryRunEndpoint, http.StatusOK, respBytes)
+}
+
+func (pd *PolicyDryRun) evaluate(candidate *config.WitnessPolicyConfig, candidatePolicy string,
+	limit int) (*DryRunResponse, error) {
+	activePolicy, err := pd.store.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := config.Parse(activePolicy)
+	if err != nil {
+		return nil, fmt.Errorf("parse active witness policy: %w", err)
+	}
+
+	completedAnchors, err := pd.witnessHistory.GetRecent(limit)
+	if err != nil {
+		return nil, fmt.Errorf("get completed anchor credentials from witness history: %w", err)
+	}
+
+	resp := &DryRunResponse{
+		Policy:       candidatePolicy,
+		ActivePolicy: activePolicy,
+		Results:      []*DryRunResult{},
+	}
+
+	for _, anchor := range completedAnchors {
+		result := &DryRunResult{
+			AnchorCredential:          anchor.VCID,
+			CompletedAt:               anchor.Completed,
+			Witnesses:                 len(anchor.Witnesses),
+			Completed:                 candidate.Expression.Evaluate(anchor.Witnesses),
+			CompletedWithActivePolicy: active.Expression.Evaluate(anchor.Witnesses),
+		}
+
+		for _, wp := range anchor.Witnesses {
+			if wp.Proof != nil {
+				result.Proofs++
+			}
+		}
+
+		if result.Completed {
+			resp.Completed++
+		}
+
+		resp.Results = append(resp.Results, result)
+	}
+
+	resp.Evaluated = len(resp.Results)
+
+	return resp, nil
+}
+
+func getLimit(req *http.Request) (int, error) {
+	limitStr := req.URL.Query().Get(limitQueryParam)
+	if limitStr == "" {
+		return defaultDryRunLimit, nil
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("limit must be a positive integer: %s", limitStr)
+	}
+
+	return limit, nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	storemocks "github.com/trustbloc/orb/pkg/store/mocks"
+	"github.com/trustbloc/orb/pkg/store/witness"
+)
+
+func TestPolicyDryRun(t *testing.T) {
+	provider := mem.NewProvider()
+
+	configStore, err := provider.OpenStore(configStoreName)
+	require.NoError(t, err)
+
+	witnessHistory, err := witness.NewHistory(provider, 0)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	require.NoError(t, witnessHistory.Put("vc-1", []*proof.WitnessProof{
+		{Type: proof.WitnessTypeBatch, Witness: "https://orb.domain1.com/services/orb", Proof: []byte("proof")},
+		{Type: proof.WitnessTypeSystem, Witness: "https://orb.domain2.com/services/orb"},
+	}, now.Add(-time.Minute)))
+
+	require.NoError(t, witnessHistory.Put("vc-2", []*proof.WitnessProof{
+		{Type: proof.WitnessTypeBatch, Witness: "https://orb.domain1.com/services/orb"},
+		{Type: proof.WitnessTypeSystem, Witness: "https://orb.domain2.com/services/orb"},
+	}, now))
+
+	dryRun := NewDryRun(configStore, witnessHistory)
+	require.Equal(t, dryRunEndpoint, dryRun.Path())
+	require.Equal(t, http.MethodPost, dryRun.Method())
+	require.NotNil(t, dryRun.Handler())
+
+	t.Run("success", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		dryRun.handle(rw, httptest.NewRequest(http.MethodPost, dryRunEndpoint,
+			bytes.NewBufferString("OutOf(1,batch) OR OutOf(1,system)")))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		resp := &DryRunResponse{}
+		require.NoError(t, json.NewDecoder(result.Body).Decode(resp))
+		require.NoError(t, result.Body.Close())
+
+		require.Equal(t, 2, resp.Evaluated)
+		require.Equal(t, 1, resp.Completed)
+
+		// The most recently completed anchor credential is evaluated first.
+		require.Equal(t, "vc-2", resp.Results[0].AnchorCredential)
+		require.Equal(t, "vc-1", resp.Results[1].AnchorCredential)
+
+		for _, r := range resp.Results {
+			require.False(t, r.CompletedWithActivePolicy)
+			require.Equal(t, 2, r.Witnesses)
+			require.Equal(t, r.AnchorCredential == "vc-1", r.Completed)
+		}
+	})
+
+	t.Run("success - limit", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		dryRun.handle(rw, httptest.NewRequest(http.MethodPost, dryRunEndpoint+"?limit=1",
+			bytes.NewBufferString("OutOf(1,batch)")))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		resp := &DryRunResponse{}
+		require.NoError(t, json.NewDecoder(result.Body).Decode(resp))
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, 1, resp.Evaluated)
+		require.Equal(t, "vc-2", resp.Results[0].AnchorCredential)
+	})
+
+	t.Run("error - invalid limit", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		dryRun.handle(rw, httptest.NewRequest(http.MethodPost, dryRunEndpoint+"?limit=-1",
+			bytes.NewBufferString("OutOf(1,batch)")))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - invalid policy", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		dryRun.handle(rw, httptest.NewRequest(http.MethodPost, dryRunEndpoint, bytes.NewBufferString("OutOf(1")))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(respBytes), "Invalid witness policy: parse error")
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - reader error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		dryRun.handle(rw, httptest.NewRequest(http.MethodPost, dryRunEndpoint, errReader(0)))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - witness history error", func(t *testing.T) {
+		s := &storemocks.Store{}
+		s.QueryReturns(nil, fmt.Errorf("query error"))
+
+		p := &storemocks.Provider{}
+		p.OpenStoreReturns(s, nil)
+
+		errWitnessHistory, err := witness.NewHistory(p, 0)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+
+		NewDryRun(configStore, errWitnessHistory).handle(rw,
+			httptest.NewRequest(http.MethodPost, dryRunEndpoint, bytes.NewBufferString("OutOf(1,batch)")))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - marshal error", func(t *testing.T) {
+		d := NewDryRun(configStore, witnessHistory)
+		d.marshal = func(v interface{}) ([]byte, error) { return nil, fmt.Errorf("marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		d.handle(rw, httptest.NewRequest(http.MethodPost, dryRunEndpoint, bytes.NewBufferString("OutOf(1,batch)")))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
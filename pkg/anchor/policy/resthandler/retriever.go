@@ -0,0 +1,114 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+// PolicyRetriever retrieves the active witness policy from the config store.
+type PolicyRetriever struct {
+	store *policyStore
+}
+
+// NewRetriever returns a new PolicyRetriever.
+func NewRetriever(cfgStore storage.Store) *PolicyRetriever {
+	return &PolicyRetriever{
+		store: newPolicyStore(cfgStore),
+	}
+}
+
+// Path returns the HTTP REST endpoint for the PolicyRetriever service.
+func (pr *PolicyRetriever) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the PolicyRetriever service.
+func (pr *PolicyRetriever) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the PolicyRetriever service.
+func (pr *PolicyRetriever) Handler() common.HTTPRequestHandler {
+	return pr.handle
+}
+
+func (pr *PolicyRetriever) handle(w http.ResponseWriter, _ *http.Request) {
+	witnessPolicy, err := pr.store.Get()
+	if err != nil {
+		logger.Errorf("[%s] Error retrieving witness policy: %s", endpoint, err)
+
+		writeResponse(w, endpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+
+	writeResponse(w, endpoint, http.StatusOK, []byte(witnessPolicy))
+}
+
+// HistoryRetriever retrieves the witness policy history from the config store.
+type HistoryRetriever struct {
+	store   *policyStore
+	marshal func(v interface{}) ([]byte, error)
+}
+
+// NewHistoryRetriever returns a new HistoryRetriever.
+func NewHistoryRetriever(cfgStore storage.Store) *HistoryRetriever {
+	return &HistoryRetriever{
+		store:   newPolicyStore(cfgStore),
+		marshal: json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the HistoryRetriever service.
+func (hr *HistoryRetriever) Path() string {
+	return historyEndpoint
+}
+
+// Method returns the HTTP REST method for the HistoryRetriever service.
+func (hr *HistoryRetriever) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the HistoryRetriever service.
+func (hr *HistoryRetriever) Handler() common.HTTPRequestHandler {
+	return hr.handle
+}
+
+func (hr *HistoryRetriever) handle(w http.ResponseWriter, _ *http.Request) {
+	history, err := hr.store.History()
+	if err != nil {
+		logger.Errorf("[%s] Error retrieving witness policy history: %s", historyEndpoint, err)
+
+		writeResponse(w, historyEndpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	if history == nil {
+		history = []*PolicyEntry{}
+	}
+
+	historyBytes, err := hr.marshal(history)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling witness policy history: %s", historyEndpoint, err)
+
+		writeResponse(w, historyEndpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, historyEndpoint, http.StatusOK, historyBytes)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	storemocks "github.com/trustbloc/orb/pkg/store/mocks"
+)
+
+func TestPolicyRetriever(t *testing.T) {
+	configStore, err := mem.NewProvider().OpenStore(configStoreName)
+	require.NoError(t, err)
+
+	retriever := NewRetriever(configStore)
+	require.Equal(t, endpoint, retriever.Path())
+	require.Equal(t, http.MethodGet, retriever.Method())
+	require.NotNil(t, retriever.Handler())
+
+	t.Run("success - no policy", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		retriever.handle(rw, httptest.NewRequest(http.MethodGet, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.Empty(t, respBytes)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		_, err := newPolicyStore(configStore).Put(testPolicy, "admin")
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+
+		retriever.handle(rw, httptest.NewRequest(http.MethodGet, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.Equal(t, testPolicy, string(respBytes))
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - store error", func(t *testing.T) {
+		s := &storemocks.Store{}
+		s.GetReturns(nil, fmt.Errorf("get error"))
+
+		rw := httptest.NewRecorder()
+
+		NewRetriever(s).handle(rw, httptest.NewRequest(http.MethodGet, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+func TestHistoryRetriever(t *testing.T) {
+	configStore, err := mem.NewProvider().OpenStore(configStoreName)
+	require.NoError(t, err)
+
+	retriever := NewHistoryRetriever(configStore)
+	require.Equal(t, historyEndpoint, retriever.Path())
+	require.Equal(t, http.MethodGet, retriever.Method())
+	require.NotNil(t, retriever.Handler())
+
+	t.Run("success - empty history", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		retriever.handle(rw, httptest.NewRequest(http.MethodGet, historyEndpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.Equal(t, "[]", string(respBytes))
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		_, err := newPolicyStore(configStore).Put(testPolicy, "admin")
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+
+		retriever.handle(rw, httptest.NewRequest(http.MethodGet, historyEndpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		var history []*PolicyEntry
+		require.NoError(t, json.NewDecoder(result.Body).Decode(&history))
+		require.Len(t, history, 1)
+		require.Equal(t, testPolicy, history[0].Policy)
+		require.Equal(t, "admin", history[0].ChangedBy)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - store error", func(t *testing.T) {
+		s := &storemocks.Store{}
+		s.GetReturns(nil, fmt.Errorf("get error"))
+
+		rw := httptest.NewRecorder()
+
+		NewHistoryRetriever(s).handle(rw, httptest.NewRequest(http.MethodGet, historyEndpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - marshal error", func(t *testing.T) {
+		r := NewHistoryRetriever(configStore)
+		r.marshal = func(v interface{}) ([]byte, error) { return nil, fmt.Errorf("marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		r.handle(rw, httptest.NewRequest(http.MethodGet, historyEndpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
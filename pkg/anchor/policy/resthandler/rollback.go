@@ -0,0 +1,79 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+)
+
+// PolicyRollback restores a previous version of the witness policy from the policy history.
+type PolicyRollback struct {
+	store *policyStore
+}
+
+// NewRollback returns a new PolicyRollback.
+func NewRollback(cfgStore storage.Store) *PolicyRollback {
+	return &PolicyRollback{
+		store: newPolicyStore(cfgStore),
+	}
+}
+
+// Path returns the HTTP REST endpoint for the PolicyRollback service.
+func (pr *PolicyRollback) Path() string {
+	return rollbackEndpoint
+}
+
+// Method returns the HTTP REST method for the PolicyRollback service.
+func (pr *PolicyRollback) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the PolicyRollback service.
+func (pr *PolicyRollback) Handler() common.HTTPRequestHandler {
+	return pr.handle
+}
+
+func (pr *PolicyRollback) handle(w http.ResponseWriter, req *http.Request) {
+	version, err := strconv.Atoi(mux.Vars(req)[versionPathVariable])
+	if err != nil {
+		logger.Infof("[%s] Invalid witness policy version: %s", rollbackEndpoint, err)
+
+		writeResponse(w, rollbackEndpoint, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	entry, err := pr.store.Rollback(version, auth.TokenIDFromContext(req.Context()))
+	if err != nil {
+		if errors.Is(err, errVersionNotFound) {
+			logger.Infof("[%s] Witness policy version %d not found", rollbackEndpoint, version)
+
+			writeResponse(w, rollbackEndpoint, http.StatusNotFound, []byte(notFoundResponse))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error rolling back witness policy to version %d: %s", rollbackEndpoint, version, err)
+
+		writeResponse(w, rollbackEndpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	logger.Infof("[%s] Rolled back witness policy to version %d (new version %d): %s",
+		rollbackEndpoint, version, entry.Version, entry.Policy)
+
+	writeResponse(w, rollbackEndpoint, http.StatusOK, nil)
+}
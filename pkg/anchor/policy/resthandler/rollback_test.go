@@ -0,0 +1,91 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	storemocks "github.com/trustbloc/orb/pkg/store/mocks"
+)
+
+func TestPolicyRollback(t *testing.T) {
+	configStore, err := mem.NewProvider().OpenStore(configStoreName)
+	require.NoError(t, err)
+
+	s := newPolicyStore(configStore)
+
+	_, err = s.Put("OutOf(1,system)", "admin")
+	require.NoError(t, err)
+
+	_, err = s.Put(testPolicy, "admin")
+	require.NoError(t, err)
+
+	rollback := NewRollback(configStore)
+	require.Equal(t, rollbackEndpoint, rollback.Path())
+	require.Equal(t, http.MethodPost, rollback.Method())
+	require.NotNil(t, rollback.Handler())
+
+	t.Run("success", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		rollback.handle(rw, newRollbackRequest("1"))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+
+		p, err := s.Get()
+		require.NoError(t, err)
+		require.Equal(t, "OutOf(1,system)", p)
+	})
+
+	t.Run("error - invalid version", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		rollback.handle(rw, newRollbackRequest("invalid"))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - version not found", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		rollback.handle(rw, newRollbackRequest("10"))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusNotFound, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - store error", func(t *testing.T) {
+		errStore := &storemocks.Store{}
+		errStore.GetReturns(nil, fmt.Errorf("get error"))
+
+		rw := httptest.NewRecorder()
+
+		NewRollback(errStore).handle(rw, newRollbackRequest("1"))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+func newRollbackRequest(version string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, endpoint+"/rollback/"+version, nil)
+
+	return mux.SetURLVars(req, map[string]string{versionPathVariable: version})
+}
@@ -0,0 +1,174 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/orb/pkg/anchor/policy"
+)
+
+const (
+	// WitnessPolicyHistoryKey is the key of the witness policy history in the config store.
+	WitnessPolicyHistoryKey = "witness-policy-history"
+
+	maxHistorySize = 100
+)
+
+var errVersionNotFound = errors.New("version not found")
+
+// policyMutex serializes updates to the witness policy and its history. The handlers each have their
+// own policyStore over the same config store so the lock is shared by all instances.
+//
+// The lock is process-local and the config store doesn't support conditional writes, so the history is only
+// consistent if a single server updates the witness policy at a time. If several servers share the config
+// store then concurrent updates (or rollbacks) on different servers may lose history entries; the active
+// policy is always the one that was written last.
+var policyMutex sync.Mutex
+
+// PolicyEntry is a version of the witness policy.
+type PolicyEntry struct {
+	Version   int       `json:"version"`
+	Policy    string    `json:"policy"`
+	ChangedBy string    `json:"changedBy,omitempty"`
+	ChangedAt time.Time `json:"changedAt"`
+
+	// RollbackOf is set to the restored version if this entry was created by a rollback.
+	RollbackOf int `json:"rollbackOf,omitempty"`
+}
+
+// policyStore saves the witness policy in the config store and maintains a history of the
+// previous policies. The history holds up to maxHistorySize entries; the oldest entries are
+// dropped first.
+type policyStore struct {
+	configStore storage.Store
+}
+
+func newPolicyStore(configStore storage.Store) *policyStore {
+	return &policyStore{configStore: configStore}
+}
+
+// Get returns the active witness policy or an empty string if no policy has been set.
+func (s *policyStore) Get() (string, error) {
+	policyBytes, err := s.configStore.Get(policy.WitnessPolicyKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("get witness policy: %w", err)
+	}
+
+	return string(policyBytes), nil
+}
+
+// Put sets the active witness policy and adds it to the history.
+func (s *policyStore) Put(witnessPolicy, changedBy string) (*PolicyEntry, error) {
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+
+	return s.put(witnessPolicy, changedBy, 0)
+}
+
+// Rollback sets the policy of the given version as the active witness policy.
+func (s *policyStore) Rollback(version int, changedBy string) (*PolicyEntry, error) {
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+
+	history, err := s.History()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range history {
+		if entry.Version == version {
+			return s.put(entry.Policy, changedBy, version)
+		}
+	}
+
+	return nil, fmt.Errorf("witness policy version %d: %w", version, errVersionNotFound)
+}
+
+// History returns the witness policy history, oldest first.
+func (s *policyStore) History() ([]*PolicyEntry, error) {
+	historyBytes, err := s.configStore.Get(WitnessPolicyHistoryKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("get witness policy history: %w", err)
+	}
+
+	var history []*PolicyEntry
+
+	err = json.Unmarshal(historyBytes, &history)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal witness policy history: %w", err)
+	}
+
+	return history, nil
+}
+
+// put must be called while holding policyMutex since it does a read-modify-write of the history.
+func (s *policyStore) put(witnessPolicy, changedBy string, rollbackOf int) (*PolicyEntry, error) {
+	history, err := s.History()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(history) == 0 {
+		// Record the policy that was set before history was maintained so that it's possible to roll back to it.
+		current, e := s.Get()
+		if e != nil {
+			return nil, e
+		}
+
+		if current != "" {
+			history = append(history, &PolicyEntry{Version: 1, Policy: current})
+		}
+	}
+
+	entry := &PolicyEntry{
+		Version:    1,
+		Policy:     witnessPolicy,
+		ChangedBy:  changedBy,
+		ChangedAt:  time.Now().UTC(),
+		RollbackOf: rollbackOf,
+	}
+
+	if len(history) > 0 {
+		entry.Version = history[len(history)-1].Version + 1
+	}
+
+	history = append(history, entry)
+
+	if len(history) > maxHistorySize {
+		history = history[len(history)-maxHistorySize:]
+	}
+
+	historyBytes, err := json.Marshal(history)
+	if err != nil {
+		return nil, fmt.Errorf("marshal witness policy history: %w", err)
+	}
+
+	err = s.configStore.Batch([]storage.Operation{
+		{Key: policy.WitnessPolicyKey, Value: []byte(witnessPolicy)},
+		{Key: WitnessPolicyHistoryKey, Value: historyBytes},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("store witness policy: %w", err)
+	}
+
+	return entry, nil
+}
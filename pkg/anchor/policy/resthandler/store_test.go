@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/policy"
+	storemocks "github.com/trustbloc/orb/pkg/store/mocks"
+)
+
+func TestPolicyStore(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		configStore, err := mem.NewProvider().OpenStore(configStoreName)
+		require.NoError(t, err)
+
+		s := newPolicyStore(configStore)
+
+		p, err := s.Get()
+		require.NoError(t, err)
+		require.Empty(t, p)
+
+		history, err := s.History()
+		require.NoError(t, err)
+		require.Empty(t, history)
+
+		entry, err := s.Put("OutOf(1,system)", "admin")
+		require.NoError(t, err)
+		require.Equal(t, 1, entry.Version)
+
+		entry, err = s.Put("OutOf(2,system)", "admin")
+		require.NoError(t, err)
+		require.Equal(t, 2, entry.Version)
+
+		entry, err = s.Rollback(1, "other")
+		require.NoError(t, err)
+		require.Equal(t, 3, entry.Version)
+		require.Equal(t, 1, entry.RollbackOf)
+		require.Equal(t, "other", entry.ChangedBy)
+
+		p, err = s.Get()
+		require.NoError(t, err)
+		require.Equal(t, "OutOf(1,system)", p)
+
+		history, err = s.History()
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+
+		_, err = s.Rollback(10, "admin")
+		require.True(t, errors.Is(err, errVersionNotFound))
+	})
+
+	t.Run("success - policy set before history is recorded", func(t *testing.T) {
+		configStore, err := mem.NewProvider().OpenStore(configStoreName)
+		require.NoError(t, err)
+
+		require.NoError(t, configStore.Put(policy.WitnessPolicyKey, []byte("OutOf(1,batch)")))
+
+		s := newPolicyStore(configStore)
+
+		entry, err := s.Put("OutOf(1,system)", "admin")
+		require.NoError(t, err)
+		require.Equal(t, 2, entry.Version)
+
+		history, err := s.History()
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, "OutOf(1,batch)", history[0].Policy)
+	})
+
+	t.Run("success - history is truncated", func(t *testing.T) {
+		configStore, err := mem.NewProvider().OpenStore(configStoreName)
+		require.NoError(t, err)
+
+		s := newPolicyStore(configStore)
+
+		for i := 0; i < maxHistorySize+5; i++ {
+			_, err = s.Put(fmt.Sprintf("OutOf(%d,system)", i), "admin")
+			require.NoError(t, err)
+		}
+
+		history, err := s.History()
+		require.NoError(t, err)
+		require.Len(t, history, maxHistorySize)
+		require.Equal(t, 6, history[0].Version)
+	})
+
+	t.Run("success - concurrent updates", func(t *testing.T) {
+		configStore, err := mem.NewProvider().OpenStore(configStoreName)
+		require.NoError(t, err)
+
+		const n = 20
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				// Each handler has its own policy store instance.
+				_, e := newPolicyStore(configStore).Put(fmt.Sprintf("OutOf(%d,system)", i), "admin")
+				require.NoError(t, e)
+			}(i)
+		}
+
+		wg.Wait()
+
+		history, err := newPolicyStore(configStore).History()
+		require.NoError(t, err)
+		require.Len(t, history, n)
+
+		for i, entry := range history {
+			require.Equal(t, i+1, entry.Version)
+		}
+	})
+
+	t.Run("error - store errors", func(t *testing.T) {
+		configStore := &storemocks.Store{}
+		configStore.GetReturns(nil, fmt.Errorf("get error"))
+
+		s := newPolicyStore(configStore)
+
+		_, err := s.Get()
+		require.EqualError(t, err, "get witness policy: get error")
+
+		_, err = s.History()
+		require.EqualError(t, err, "get witness policy history: get error")
+
+		_, err = s.Put("OutOf(1,system)", "admin")
+		require.EqualError(t, err, "get witness policy history: get error")
+
+		_, err = s.Rollback(1, "admin")
+		require.EqualError(t, err, "get witness policy history: get error")
+	})
+
+	t.Run("error - invalid history", func(t *testing.T) {
+		configStore := &storemocks.Store{}
+		configStore.GetReturns([]byte("{"), nil)
+
+		_, err := newPolicyStore(configStore).History()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal witness policy history")
+	})
+
+	t.Run("error - get current policy", func(t *testing.T) {
+		configStore := &storemocks.Store{}
+		configStore.GetReturnsOnCall(0, nil, storage.ErrDataNotFound)
+		configStore.GetReturnsOnCall(1, nil, fmt.Errorf("get error"))
+
+		_, err := newPolicyStore(configStore).Put("OutOf(1,system)", "admin")
+		require.EqualError(t, err, "get witness policy: get error")
+	})
+}
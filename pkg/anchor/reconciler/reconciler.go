@@ -0,0 +1,687 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reconciler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	txnapi "github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+
+	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/store/storeutil"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	"github.com/trustbloc/orb/pkg/anchor/util"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+)
+
+var logger = log.New("witness-reconciler")
+
+var (
+	// ErrNotFound indicates that the anchor credential is not in process.
+	ErrNotFound = errors.New("in-process anchor credential not found")
+
+	// ErrWitnessNotFound indicates that the witness is not one of the anchor credential's witnesses.
+	ErrWitnessNotFound = errors.New("witness not found")
+)
+
+const (
+	namespace = "witness-reconciler"
+
+	defaultInterval = 30 * time.Second
+)
+
+// Config holds the configuration for the reconciler.
+type Config struct {
+	// Interval is the interval at which in-process anchor credentials are checked.
+	Interval time.Duration
+	// MaxWitnessDelay is the time that witnesses have to respond to an offer.
+	MaxWitnessDelay time.Duration
+	// MaxReOffers is the maximum number of times that an anchor credential is re-offered to witnesses
+	// before its operations are returned to the operation queue.
+	MaxReOffers int
+}
+
+// Providers contains all of the providers required by the reconciler.
+type Providers struct {
+	StoreProvider          storage.Provider
+	WitnessStore           witnessStore
+	VCStore                vcStore
+	VCStatusStore          vcStatusStore
+	ActivityStore          activityStore
+	Outbox                 outbox
+	OpQueue                opQueue
+	ProtocolClientProvider protocolClientProvider
+	WFClient               webfingerClient
+}
+
+type witnessStore interface {
+	GetVCIDs(maxItems int) ([]string, error)
+	Get(vcID string) ([]*proof.WitnessProof, error)
+	Put(vcID string, witnesses []*proof.WitnessProof) error
+	Delete(vcID string) error
+}
+
+type vcStore interface {
+	Get(id string) (*verifiable.Credential, error)
+}
+
+type vcStatusStore interface {
+	GetStatus(vcID string) (proof.VCStatus, error)
+}
+
+type activityStore interface {
+	QueryReferences(refType spi.ReferenceType, query *spi.Criteria, opts ...spi.QueryOpt) (spi.ReferenceIterator, error)
+}
+
+type outbox interface {
+	Post(activity *vocab.ActivityType) (*url.URL, error)
+}
+
+type opQueue interface {
+	Add(op *operation.QueuedOperation, protocolGenesisTime uint64) (uint, error)
+}
+
+type protocolClientProvider interface {
+	ForNamespace(namespace string) (protocol.Client, error)
+}
+
+type webfingerClient interface {
+	HasSupportedLedgerType(domain string) (bool, error)
+}
+
+// OfferStatus contains the status of the offer of an in-process anchor credential.
+type OfferStatus struct {
+	// EndTime is the time after which the anchor credential is either re-offered or its operations
+	// are returned to the operation queue.
+	EndTime time.Time
+	// ReOffers is the number of times that the anchor credential has been re-offered.
+	ReOffers int
+}
+
+// offerRecord tracks the re-offers of an in-process anchor credential.
+type offerRecord struct {
+	ReOffers int       `json:"reOffers"`
+	EndTime  time.Time `json:"endTime"`
+}
+
+// Reconciler periodically checks for anchor credentials whose witness policy wasn't satisfied before the
+// offer expired. An expired anchor credential is offered again (with a new end time) to the witnesses that
+// haven't responded along with any system witnesses that were added since the previous offer. After
+// MaxReOffers attempts the anchor credential is abandoned and its operations are returned to the
+// operation queue so that they're included in a new batch.
+type Reconciler struct {
+	*Providers
+	*lifecycle.Lifecycle
+
+	serviceIRI      *url.URL
+	store           storage.Store
+	done            chan struct{}
+	interval        time.Duration
+	maxWitnessDelay time.Duration
+	maxReOffers     int
+}
+
+// New returns a new witness reconciler.
+func New(serviceIRI *url.URL, providers *Providers, cfg Config) (*Reconciler, error) {
+	store, err := providers.StoreProvider.OpenStore(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", namespace, err)
+	}
+
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = defaultInterval
+	}
+
+	r := &Reconciler{
+		Providers:       providers,
+		serviceIRI:      serviceIRI,
+		store:           store,
+		done:            make(chan struct{}),
+		interval:        interval,
+		maxWitnessDelay: cfg.MaxWitnessDelay,
+		maxReOffers:     cfg.MaxReOffers,
+	}
+
+	r.Lifecycle = lifecycle.New("witness-reconciler",
+		lifecycle.WithStart(r.start),
+		lifecycle.WithStop(r.stop))
+
+	return r, nil
+}
+
+func (r *Reconciler) start() {
+	go r.run()
+
+	logger.Infof("Started witness reconciler")
+}
+
+func (r *Reconciler) stop() {
+	close(r.done)
+
+	logger.Infof("Stopped witness reconciler")
+}
+
+func (r *Reconciler) run() {
+	for {
+		select {
+		case <-time.After(r.interval):
+			r.reconcile()
+		case <-r.done:
+			logger.Debugf("Exiting witness reconciler.")
+
+			return
+		}
+	}
+}
+
+func (r *Reconciler) reconcile() {
+	vcIDs, err := r.WitnessStore.GetVCIDs(0)
+	if err != nil {
+		logger.Errorf("Error retrieving in-process anchor credentials: %s", err)
+
+		return
+	}
+
+	for _, vcID := range vcIDs {
+		err = r.reconcileCredential(vcID)
+		if err != nil {
+			logger.Warnf("Error reconciling anchor credential [%s]: %s", vcID, err)
+		}
+	}
+}
+
+func (r *Reconciler) reconcileCredential(vcID string) error {
+	status, err := r.VCStatusStore.GetStatus(vcID)
+	if err != nil {
+		return fmt.Errorf("get status: %w", err)
+	}
+
+	if status == proof.VCStatusCompleted {
+		// The witness policy was satisfied. The witnesses are deleted by the anchor writer.
+		return r.deleteRecord(vcID)
+	}
+
+	vc, err := r.VCStore.Get(vcID)
+	if err != nil {
+		return fmt.Errorf("get anchor credential: %w", err)
+	}
+
+	record, err := r.getRecord(vc)
+	if err != nil {
+		return err
+	}
+
+	if time.Now().Before(record.EndTime) {
+		return nil
+	}
+
+	if record.ReOffers < r.maxReOffers {
+		offered, e := r.reOffer(vc, record)
+		if e != nil {
+			return fmt.Errorf("re-offer: %w", e)
+		}
+
+		if offered {
+			return nil
+		}
+
+		logger.Infof("No witnesses are available for anchor credential [%s]", vcID)
+	}
+
+	return r.requeue(vc)
+}
+
+// OfferStatus returns the offer status of the given in-process anchor credential.
+func (r *Reconciler) OfferStatus(vcID string) (*OfferStatus, error) {
+	vc, _, err := r.getInProcess(vcID)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := r.getRecord(vc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OfferStatus{EndTime: record.EndTime, ReOffers: record.ReOffers}, nil
+}
+
+// ReOffer posts a new offer for the given in-process anchor credential to the given witnesses. If no witnesses
+// are provided then the offer is posted to the witnesses that haven't provided a proof along with any new
+// system witnesses. Manual re-offers extend the end time of the offer but are not counted against MaxReOffers.
+func (r *Reconciler) ReOffer(vcID string, witnesses ...string) error {
+	vc, stored, err := r.getInProcess(vcID)
+	if err != nil {
+		return err
+	}
+
+	var targets []*url.URL
+
+	if len(witnesses) == 0 {
+		targets, err = r.getPendingWitnesses(vcID, stored)
+		if err != nil {
+			return err
+		}
+
+		if len(targets) == 0 {
+			return fmt.Errorf("all witnesses have provided a proof: %w", ErrWitnessNotFound)
+		}
+	} else {
+		targets, err = selectWitnesses(stored, witnesses)
+		if err != nil {
+			return err
+		}
+	}
+
+	record, err := r.getRecord(vc)
+	if err != nil {
+		return err
+	}
+
+	err = r.offer(vc, record, targets)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Re-offered anchor credential [%s] to witnesses %s on request", vcID, targets)
+
+	return nil
+}
+
+// Cancel abandons the given in-process anchor credential and returns its operations to the operation queue.
+func (r *Reconciler) Cancel(vcID string) error {
+	vc, _, err := r.getInProcess(vcID)
+	if err != nil {
+		return err
+	}
+
+	return r.requeue(vc)
+}
+
+// getInProcess returns the anchor credential and its witnesses. ErrNotFound is returned if the anchor
+// credential has no witnesses or if the witness policy has already been satisfied.
+func (r *Reconciler) getInProcess(vcID string) (*verifiable.Credential, []*proof.WitnessProof, error) {
+	witnesses, err := r.WitnessStore.Get(vcID)
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			return nil, nil, fmt.Errorf("%s: %w", vcID, ErrNotFound)
+		}
+
+		return nil, nil, fmt.Errorf("get witnesses: %w", err)
+	}
+
+	status, err := r.VCStatusStore.GetStatus(vcID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get status: %w", err)
+	}
+
+	if status == proof.VCStatusCompleted {
+		return nil, nil, fmt.Errorf("%s has been completed: %w", vcID, ErrNotFound)
+	}
+
+	vc, err := r.VCStore.Get(vcID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get anchor credential: %w", err)
+	}
+
+	return vc, witnesses, nil
+}
+
+// reOffer posts a new offer for the given anchor credential to the witnesses that haven't provided a
+// proof. False is returned if there are no such witnesses.
+func (r *Reconciler) reOffer(vc *verifiable.Credential, record *offerRecord) (bool, error) {
+	witnesses, err := r.WitnessStore.Get(vc.ID)
+	if err != nil {
+		return false, fmt.Errorf("get witnesses: %w", err)
+	}
+
+	pending, err := r.getPendingWitnesses(vc.ID, witnesses)
+	if err != nil {
+		return false, err
+	}
+
+	if len(pending) == 0 {
+		return false, nil
+	}
+
+	record.ReOffers++
+
+	err = r.offer(vc, record, pending)
+	if err != nil {
+		return false, err
+	}
+
+	logger.Infof("Re-offered anchor credential [%s] to witnesses %s (attempt %d of %d)",
+		vc.ID, pending, record.ReOffers, r.maxReOffers)
+
+	return true, nil
+}
+
+// getPendingWitnesses adds any new system witnesses to the witness store and returns the IRIs of the
+// witnesses that haven't provided a proof.
+func (r *Reconciler) getPendingWitnesses(vcID string, witnesses []*proof.WitnessProof) ([]*url.URL, error) {
+	added, err := r.addSystemWitnesses(vcID, witnesses)
+	if err != nil {
+		return nil, err
+	}
+
+	return pendingWitnesses(append(witnesses, added...)), nil
+}
+
+// offer posts an offer with a new end time to the given witnesses.
+func (r *Reconciler) offer(vc *verifiable.Credential, record *offerRecord, witnesses []*url.URL) error {
+	startTime := time.Now()
+	endTime := startTime.Add(r.maxWitnessDelay)
+
+	record.EndTime = endTime
+
+	// Save the record before posting the offer so that a failure to save doesn't result in
+	// an unlimited number of offers.
+	err := r.putRecord(vc.ID, record)
+	if err != nil {
+		return err
+	}
+
+	return r.postOffer(vc, witnesses, startTime, endTime)
+}
+
+// addSystemWitnesses adds the current system witnesses that aren't in the given list of witnesses
+// to the witness store and returns the added witnesses.
+func (r *Reconciler) addSystemWitnesses(vcID string, witnesses []*proof.WitnessProof) ([]*proof.WitnessProof, error) {
+	systemWitnesses, err := r.getSystemWitnesses()
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool)
+
+	for _, w := range witnesses {
+		existing[w.Witness] = true
+	}
+
+	var added []*proof.WitnessProof
+
+	for _, w := range systemWitnesses {
+		if existing[w.String()] {
+			continue
+		}
+
+		hasLog, e := r.WFClient.HasSupportedLedgerType(fmt.Sprintf("%s://%s", w.Scheme, w.Host))
+		if e != nil {
+			return nil, fmt.Errorf("resolve ledger type for witness [%s]: %w", w, e)
+		}
+
+		added = append(added, &proof.WitnessProof{
+			Type:    proof.WitnessTypeSystem,
+			Witness: w.String(),
+			HasLog:  hasLog,
+		})
+	}
+
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	err = r.WitnessStore.Put(vcID, added)
+	if err != nil {
+		return nil, fmt.Errorf("store witnesses: %w", err)
+	}
+
+	return added, nil
+}
+
+func (r *Reconciler) postOffer(vc *verifiable.Credential, witnesses []*url.URL, startTime, endTime time.Time) error {
+	bytes, err := vc.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("marshal anchor credential: %w", err)
+	}
+
+	obj, err := vocab.NewObjectWithDocument(vocab.MustUnmarshalToDoc(bytes))
+	if err != nil {
+		return fmt.Errorf("create new object with document: %w", err)
+	}
+
+	offer := vocab.NewOfferActivity(
+		vocab.NewObjectProperty(vocab.WithObject(obj)),
+		vocab.WithTo(append(witnesses, vocab.PublicIRI)...),
+		vocab.WithStartTime(&startTime),
+		vocab.WithEndTime(&endTime),
+		vocab.WithTarget(vocab.NewObjectProperty(vocab.WithIRI(vocab.AnchorWitnessTargetIRI))),
+	)
+
+	_, err = r.Outbox.Post(offer)
+	if err != nil {
+		return fmt.Errorf("post offer: %w", err)
+	}
+
+	return nil
+}
+
+// requeue adds the operations of the given anchor credential back to the operation queue and
+// removes the anchor credential's witnesses so that late proofs are no longer accepted.
+func (r *Reconciler) requeue(vc *verifiable.Credential) error {
+	payload, err := util.GetAnchorSubject(vc)
+	if err != nil {
+		return fmt.Errorf("get anchor subject: %w", err)
+	}
+
+	ops, err := r.getOperations(vc, payload.Namespace, payload.Version, payload.CoreIndex, payload.OperationCount)
+	if err != nil {
+		return err
+	}
+
+	for _, op := range ops {
+		_, err = r.OpQueue.Add(
+			&operation.QueuedOperation{
+				OperationBuffer: op.OperationBuffer,
+				UniqueSuffix:    op.UniqueSuffix,
+				Namespace:       payload.Namespace,
+			},
+			payload.Version,
+		)
+		if err != nil {
+			return fmt.Errorf("add operation for suffix [%s] to queue: %w", op.UniqueSuffix, err)
+		}
+	}
+
+	err = r.WitnessStore.Delete(vc.ID)
+	if err != nil {
+		return fmt.Errorf("delete witnesses: %w", err)
+	}
+
+	logger.Warnf("Witness policy was not satisfied for anchor credential [%s]. Returned %d operation(s) to the queue.",
+		vc.ID, len(ops))
+
+	return r.deleteRecord(vc.ID)
+}
+
+func (r *Reconciler) getOperations(vc *verifiable.Credential, ns string, version uint64, coreIndex string,
+	opCount uint64) ([]*operation.AnchoredOperation, error) {
+	pc, err := r.ProtocolClientProvider.ForNamespace(ns)
+	if err != nil {
+		return nil, fmt.Errorf("get protocol client for namespace [%s]: %w", ns, err)
+	}
+
+	v, err := pc.Get(version)
+	if err != nil {
+		return nil, fmt.Errorf("get protocol version [%d]: %w", version, err)
+	}
+
+	ad := &util.AnchorData{OperationCount: opCount, CoreIndexFileURI: coreIndex}
+
+	sidetreeTxn := &txnapi.SidetreeTxn{
+		AnchorString:        ad.GetAnchorString(),
+		Namespace:           ns,
+		ProtocolGenesisTime: version,
+	}
+
+	if vc.Issued != nil {
+		sidetreeTxn.TransactionTime = uint64(vc.Issued.Unix())
+	}
+
+	ops, err := v.OperationProvider().GetTxnOperations(sidetreeTxn)
+	if err != nil {
+		return nil, fmt.Errorf("get operations for anchor string [%s]: %w", sidetreeTxn.AnchorString, err)
+	}
+
+	return ops, nil
+}
+
+func (r *Reconciler) getSystemWitnesses() ([]*url.URL, error) {
+	it, err := r.ActivityStore.QueryReferences(spi.Witness,
+		spi.NewCriteria(
+			spi.WithObjectIRI(r.serviceIRI),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query references for system witnesses: %w", err)
+	}
+
+	defer func() {
+		if e := it.Close(); e != nil {
+			logger.Errorf("failed to close iterator: %s", e)
+		}
+	}()
+
+	systemWitnesses, err := storeutil.ReadReferences(it, -1)
+	if err != nil {
+		return nil, fmt.Errorf("read system witnesses from iterator: %w", err)
+	}
+
+	return systemWitnesses, nil
+}
+
+// getRecord returns the offer record for the given anchor credential. If no record exists then
+// the end time of the original offer is derived from the issue date of the anchor credential.
+func (r *Reconciler) getRecord(vc *verifiable.Credential) (*offerRecord, error) {
+	recordBytes, err := r.store.Get(vc.ID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrDataNotFound) {
+			return nil, fmt.Errorf("get offer record: %w", err)
+		}
+
+		record := &offerRecord{}
+
+		if vc.Issued != nil {
+			record.EndTime = vc.Issued.Add(r.maxWitnessDelay)
+		} else {
+			// The issue date is unknown so start the clock now.
+			record.EndTime = time.Now().Add(r.maxWitnessDelay)
+
+			if err = r.putRecord(vc.ID, record); err != nil {
+				return nil, err
+			}
+		}
+
+		return record, nil
+	}
+
+	record := &offerRecord{}
+
+	err = json.Unmarshal(recordBytes, record)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal offer record: %w", err)
+	}
+
+	return record, nil
+}
+
+func (r *Reconciler) putRecord(vcID string, record *offerRecord) error {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal offer record: %w", err)
+	}
+
+	err = r.store.Put(vcID, recordBytes)
+	if err != nil {
+		return fmt.Errorf("store offer record: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Reconciler) deleteRecord(vcID string) error {
+	err := r.store.Delete(vcID)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("delete offer record: %w", err)
+	}
+
+	return nil
+}
+
+// selectWitnesses returns the IRIs of the requested witnesses. An error is returned if a requested witness
+// is not one of the given witnesses.
+func selectWitnesses(witnesses []*proof.WitnessProof, requested []string) ([]*url.URL, error) {
+	exists := make(map[string]bool)
+
+	for _, w := range witnesses {
+		exists[w.Witness] = true
+	}
+
+	var selected []*url.URL
+
+	for _, w := range requested {
+		if !exists[w] {
+			return nil, fmt.Errorf("%s: %w", w, ErrWitnessNotFound)
+		}
+
+		iri, err := url.Parse(w)
+		if err != nil {
+			return nil, fmt.Errorf("parse witness IRI [%s]: %w", w, err)
+		}
+
+		selected = append(selected, iri)
+	}
+
+	return selected, nil
+}
+
+// pendingWitnesses returns the IRIs of the witnesses that haven't provided a proof.
+func pendingWitnesses(witnesses []*proof.WitnessProof) []*url.URL {
+	proved := make(map[string]bool)
+
+	for _, w := range witnesses {
+		if w.Proof != nil {
+			proved[w.Witness] = true
+		}
+	}
+
+	var pending []*url.URL
+
+	added := make(map[string]bool)
+
+	for _, w := range witnesses {
+		if proved[w.Witness] || added[w.Witness] {
+			continue
+		}
+
+		iri, err := url.Parse(w.Witness)
+		if err != nil {
+			logger.Warnf("Invalid witness IRI [%s]: %s", w.Witness, err)
+
+			continue
+		}
+
+		added[w.Witness] = true
+
+		pending = append(pending, iri)
+	}
+
+	return pending
+}
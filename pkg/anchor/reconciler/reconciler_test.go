@@ -0,0 +1,647 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reconciler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	coremocks "github.com/trustbloc/sidetree-core-go/pkg/mocks"
+
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/anchor/activity"
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	storemocks "github.com/trustbloc/orb/pkg/store/mocks"
+	"github.com/trustbloc/orb/pkg/store/vcstatus"
+	"github.com/trustbloc/orb/pkg/store/witness"
+)
+
+const (
+	namespace1 = "did:orb"
+	vcID       = "https://orb.domain1.com/vc/1234"
+
+	serviceURL = "https://orb.domain1.com/services/orb"
+	witness1   = "https://orb.domain2.com/services/orb"
+	witness2   = "https://orb.domain3.com/services/orb"
+	witness3   = "https://orb.domain4.com/services/orb"
+
+	maxWitnessDelay = 10 * time.Minute
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		r, err := New(mustParseURL(serviceURL), &Providers{StoreProvider: mem.NewProvider()}, Config{})
+		require.NoError(t, err)
+		require.NotNil(t, r)
+		require.Equal(t, defaultInterval, r.interval)
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		errExpected := errors.New("injected open store error")
+
+		p := &storemocks.Provider{}
+		p.OpenStoreReturns(nil, errExpected)
+
+		_, err := New(mustParseURL(serviceURL), &Providers{StoreProvider: p}, Config{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestReconciler_StartStop(t *testing.T) {
+	p := newMockProviders(t)
+
+	r, err := New(mustParseURL(serviceURL), p.providers(),
+		Config{Interval: 10 * time.Millisecond, MaxWitnessDelay: time.Millisecond, MaxReOffers: 1})
+	require.NoError(t, err)
+
+	p.addCredential(t, time.Now().Add(-time.Minute), witness1)
+
+	r.Start()
+	defer r.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	require.NotEmpty(t, p.outbox.activities())
+	require.Len(t, p.opQueue.ops(), 1)
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	t.Run("Not expired", func(t *testing.T) {
+		p := newMockProviders(t)
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay, MaxReOffers: 1})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now(), witness1)
+
+		r.reconcile()
+
+		require.Empty(t, p.outbox.activities())
+		require.Empty(t, p.opQueue.ops())
+	})
+
+	t.Run("Completed", func(t *testing.T) {
+		p := newMockProviders(t)
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay, MaxReOffers: 1})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now().Add(-time.Hour), witness1)
+
+		require.NoError(t, p.vcStatusStore.AddStatus(vcID, proof.VCStatusCompleted))
+
+		r.reconcile()
+
+		require.Empty(t, p.outbox.activities())
+		require.Empty(t, p.opQueue.ops())
+	})
+
+	t.Run("Re-offer then requeue", func(t *testing.T) {
+		p := newMockProviders(t)
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay, MaxReOffers: 1})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now().Add(-time.Hour), witness1, witness2)
+
+		require.NoError(t, p.witnessStore.AddProof(vcID, witness1, []byte(`{}`)))
+
+		// A new system witness was added after the original offer.
+		require.NoError(t, p.apStore.AddReference(spi.Witness, mustParseURL(serviceURL), mustParseURL(witness3)))
+
+		r.reconcile()
+
+		activities := p.outbox.activities()
+		require.Len(t, activities, 1)
+		require.Equal(t, vocab.TypeOffer, activities[0].Type().Types()[0])
+		require.True(t, activities[0].EndTime().After(time.Now()))
+
+		var to []string
+		for _, iri := range activities[0].To() {
+			to = append(to, iri.String())
+		}
+
+		require.ElementsMatch(t, []string{witness2, witness3, vocab.PublicIRI.String()}, to)
+
+		witnesses, err := p.witnessStore.Get(vcID)
+		require.NoError(t, err)
+		require.Len(t, witnesses, 3)
+
+		// Not expired yet.
+		r.reconcile()
+
+		require.Len(t, p.outbox.activities(), 1)
+		require.Empty(t, p.opQueue.ops())
+
+		// Expire the re-offer.
+		require.NoError(t, r.putRecord(vcID, &offerRecord{ReOffers: 1, EndTime: time.Now().Add(-time.Second)}))
+
+		r.reconcile()
+
+		require.Len(t, p.outbox.activities(), 1)
+
+		ops := p.opQueue.ops()
+		require.Len(t, ops, 1)
+		require.Equal(t, "suffix1", ops[0].UniqueSuffix)
+		require.Equal(t, namespace1, ops[0].Namespace)
+
+		_, err = p.witnessStore.Get(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+
+		vcIDs, err := p.witnessStore.GetVCIDs(0)
+		require.NoError(t, err)
+		require.Empty(t, vcIDs)
+	})
+
+	t.Run("No pending witnesses -> requeue", func(t *testing.T) {
+		p := newMockProviders(t)
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay, MaxReOffers: 1})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now().Add(-time.Hour), witness1)
+
+		require.NoError(t, p.witnessStore.AddProof(vcID, witness1, []byte(`{}`)))
+
+		r.reconcile()
+
+		require.Empty(t, p.outbox.activities())
+		require.Len(t, p.opQueue.ops(), 1)
+	})
+
+	t.Run("Re-offers disabled -> requeue", func(t *testing.T) {
+		p := newMockProviders(t)
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now().Add(-time.Hour), witness1)
+
+		r.reconcile()
+
+		require.Empty(t, p.outbox.activities())
+		require.Len(t, p.opQueue.ops(), 1)
+	})
+
+	t.Run("No issue date", func(t *testing.T) {
+		p := newMockProviders(t)
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay, MaxReOffers: 1})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Time{}, witness1)
+
+		r.reconcile()
+
+		require.Empty(t, p.outbox.activities())
+
+		record, err := r.getRecord(p.vcStore.vcs[vcID])
+		require.NoError(t, err)
+		require.True(t, record.EndTime.After(time.Now()))
+	})
+}
+
+func TestReconciler_OfferStatus(t *testing.T) {
+	p := newMockProviders(t)
+
+	r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay, MaxReOffers: 1})
+	require.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		issued := time.Now()
+
+		p.addCredential(t, issued, witness1)
+
+		status, err := r.OfferStatus(vcID)
+		require.NoError(t, err)
+		require.Equal(t, 0, status.ReOffers)
+		require.True(t, issued.Add(maxWitnessDelay).Equal(status.EndTime))
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := r.OfferStatus("https://orb.domain1.com/vc/unknown")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("Completed", func(t *testing.T) {
+		require.NoError(t, p.vcStatusStore.AddStatus(vcID, proof.VCStatusCompleted))
+
+		_, err := r.OfferStatus(vcID)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+}
+
+func TestReconciler_ReOffer(t *testing.T) {
+	t.Run("Pending witnesses", func(t *testing.T) {
+		p := newMockProviders(t)
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay, MaxReOffers: 1})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now().Add(-time.Hour), witness1, witness2)
+
+		require.NoError(t, p.witnessStore.AddProof(vcID, witness1, []byte(`{}`)))
+
+		require.NoError(t, r.ReOffer(vcID))
+
+		activities := p.outbox.activities()
+		require.Len(t, activities, 1)
+		require.Len(t, activities[0].To(), 2)
+		require.Equal(t, witness2, activities[0].To()[0].String())
+
+		status, err := r.OfferStatus(vcID)
+		require.NoError(t, err)
+		require.Equal(t, 0, status.ReOffers)
+		require.True(t, status.EndTime.After(time.Now()))
+	})
+
+	t.Run("Selected witnesses", func(t *testing.T) {
+		p := newMockProviders(t)
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now().Add(-time.Hour), witness1, witness2)
+
+		require.NoError(t, r.ReOffer(vcID, witness1))
+
+		activities := p.outbox.activities()
+		require.Len(t, activities, 1)
+		require.Len(t, activities[0].To(), 2)
+		require.Equal(t, witness1, activities[0].To()[0].String())
+
+		err = r.ReOffer(vcID, witness3)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrWitnessNotFound))
+	})
+
+	t.Run("No pending witnesses", func(t *testing.T) {
+		p := newMockProviders(t)
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now(), witness1)
+
+		require.NoError(t, p.witnessStore.AddProof(vcID, witness1, []byte(`{}`)))
+
+		err = r.ReOffer(vcID)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrWitnessNotFound))
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		p := newMockProviders(t)
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay})
+		require.NoError(t, err)
+
+		err = r.ReOffer(vcID)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+}
+
+func TestReconciler_Cancel(t *testing.T) {
+	p := newMockProviders(t)
+
+	r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay})
+	require.NoError(t, err)
+
+	p.addCredential(t, time.Now(), witness1)
+
+	require.NoError(t, r.Cancel(vcID))
+	require.Len(t, p.opQueue.ops(), 1)
+
+	err = r.Cancel(vcID)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestReconciler_Errors(t *testing.T) {
+	t.Run("Get VC IDs error", func(t *testing.T) {
+		errExpected := errors.New("injected query error")
+
+		s := &storemocks.Store{}
+		s.QueryReturns(nil, errExpected)
+
+		sp := &storemocks.Provider{}
+		sp.OpenStoreReturns(s, nil)
+
+		ws, err := witness.New(sp)
+		require.NoError(t, err)
+
+		p := newMockProviders(t)
+
+		providers := p.providers()
+		providers.WitnessStore = ws
+
+		r, err := New(mustParseURL(serviceURL), providers, Config{MaxWitnessDelay: maxWitnessDelay})
+		require.NoError(t, err)
+
+		require.NotPanics(t, r.reconcile)
+	})
+
+	t.Run("VC not found", func(t *testing.T) {
+		p := newMockProviders(t)
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now().Add(-time.Hour), witness1)
+
+		delete(p.vcStore.vcs, vcID)
+
+		err = r.reconcileCredential(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get anchor credential")
+	})
+
+	t.Run("Post offer error", func(t *testing.T) {
+		p := newMockProviders(t)
+		p.outbox.err = errors.New("injected post error")
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay, MaxReOffers: 1})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now().Add(-time.Hour), witness1)
+
+		err = r.reconcileCredential(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected post error")
+	})
+
+	t.Run("Get operations error", func(t *testing.T) {
+		p := newMockProviders(t)
+		p.opProvider.GetTxnOperationsReturns(nil, errors.New("injected operations error"))
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now().Add(-time.Hour), witness1)
+
+		err = r.reconcileCredential(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected operations error")
+
+		// The witnesses must remain so that the operations are requeued on the next attempt.
+		_, err = p.witnessStore.Get(vcID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Add to queue error", func(t *testing.T) {
+		p := newMockProviders(t)
+		p.opQueue.err = errors.New("injected queue error")
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now().Add(-time.Hour), witness1)
+
+		err = r.reconcileCredential(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected queue error")
+	})
+
+	t.Run("Webfinger error", func(t *testing.T) {
+		p := newMockProviders(t)
+		p.wfClient.err = errors.New("injected webfinger error")
+
+		r, err := New(mustParseURL(serviceURL), p.providers(), Config{MaxWitnessDelay: maxWitnessDelay, MaxReOffers: 1})
+		require.NoError(t, err)
+
+		p.addCredential(t, time.Now().Add(-time.Hour), witness1)
+
+		require.NoError(t, p.apStore.AddReference(spi.Witness, mustParseURL(serviceURL), mustParseURL(witness3)))
+
+		err = r.reconcileCredential(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected webfinger error")
+	})
+}
+
+type mockProviders struct {
+	witnessStore  *witness.Store
+	vcStatusStore *vcstatus.Store
+	vcStore       *mockVCStore
+	apStore       *memstore.Store
+	outbox        *mockOutbox
+	opQueue       *mockOpQueue
+	opProvider    *coremocks.OperationProvider
+	wfClient      *mockWFClient
+}
+
+func newMockProviders(t *testing.T) *mockProviders {
+	t.Helper()
+
+	storeProvider := mem.NewProvider()
+
+	ws, err := witness.New(storeProvider)
+	require.NoError(t, err)
+
+	ss, err := vcstatus.New(storeProvider)
+	require.NoError(t, err)
+
+	opProvider := &coremocks.OperationProvider{}
+	opProvider.GetTxnOperationsReturns([]*operation.AnchoredOperation{
+		{Type: operation.TypeCreate, UniqueSuffix: "suffix1", OperationBuffer: []byte(`{}`)},
+	}, nil)
+
+	return &mockProviders{
+		witnessStore:  ws,
+		vcStatusStore: ss,
+		vcStore:       &mockVCStore{vcs: make(map[string]*verifiable.Credential)},
+		apStore:       memstore.New("service1"),
+		outbox:        &mockOutbox{},
+		opQueue:       &mockOpQueue{},
+		opProvider:    opProvider,
+		wfClient:      &mockWFClient{},
+	}
+}
+
+func (p *mockProviders) providers() *Providers {
+	pv := &coremocks.ProtocolVersion{}
+	pv.OperationProviderReturns(p.opProvider)
+
+	return &Providers{
+		StoreProvider:          mem.NewProvider(),
+		WitnessStore:           p.witnessStore,
+		VCStore:                p.vcStore,
+		VCStatusStore:          p.vcStatusStore,
+		ActivityStore:          p.apStore,
+		Outbox:                 p.outbox,
+		OpQueue:                p.opQueue,
+		ProtocolClientProvider: &mockProtocolClientProvider{version: pv},
+		WFClient:               p.wfClient,
+	}
+}
+
+func (p *mockProviders) addCredential(t *testing.T, issued time.Time, witnesses ...string) {
+	t.Helper()
+
+	act, err := activity.BuildActivityFromPayload(&subject.Payload{
+		OperationCount:  1,
+		CoreIndex:       "coreIndex",
+		Namespace:       namespace1,
+		Version:         0,
+		PreviousAnchors: map[string]string{"suffix1": ""},
+	})
+	require.NoError(t, err)
+
+	vc := &verifiable.Credential{
+		ID:      vcID,
+		Types:   []string{"VerifiableCredential"},
+		Context: []string{"https://www.w3.org/2018/credentials/v1"},
+		Subject: act,
+		Issuer:  verifiable.Issuer{ID: serviceURL},
+		Issued:  &util.TimeWithTrailingZeroMsec{Time: issued},
+	}
+
+	vcBytes, err := vc.MarshalJSON()
+	require.NoError(t, err)
+
+	p.vcStore.vcs[vcID], err = verifiable.ParseCredential(vcBytes,
+		verifiable.WithDisabledProofCheck(),
+		verifiable.WithJSONLDDocumentLoader(testutil.GetLoader(t)),
+	)
+	require.NoError(t, err)
+
+	if issued.IsZero() {
+		p.vcStore.vcs[vcID].Issued = nil
+	}
+
+	var witnessProofs []*proof.WitnessProof
+
+	for _, w := range witnesses {
+		require.NoError(t, p.apStore.AddReference(spi.Witness, mustParseURL(serviceURL), mustParseURL(w)))
+
+		witnessProofs = append(witnessProofs, &proof.WitnessProof{Type: proof.WitnessTypeSystem, Witness: w})
+	}
+
+	require.NoError(t, p.witnessStore.Put(vcID, witnessProofs))
+	require.NoError(t, p.vcStatusStore.AddStatus(vcID, proof.VCStatusInProcess))
+}
+
+type mockVCStore struct {
+	vcs map[string]*verifiable.Credential
+}
+
+func (m *mockVCStore) Get(id string) (*verifiable.Credential, error) {
+	vc, ok := m.vcs[id]
+	if !ok {
+		return nil, fmt.Errorf("vc [%s] not found", id)
+	}
+
+	return vc, nil
+}
+
+type mockOutbox struct {
+	mutex sync.Mutex
+	acts  []*vocab.ActivityType
+	err   error
+}
+
+func (m *mockOutbox) Post(activity *vocab.ActivityType) (*url.URL, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.acts = append(m.acts, activity)
+
+	return mustParseURL(serviceURL + "/activities/1"), nil
+}
+
+func (m *mockOutbox) activities() []*vocab.ActivityType {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.acts
+}
+
+type mockOpQueue struct {
+	mutex  sync.Mutex
+	queued []*operation.QueuedOperation
+	err    error
+}
+
+func (m *mockOpQueue) Add(op *operation.QueuedOperation, _ uint64) (uint, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.queued = append(m.queued, op)
+
+	return uint(len(m.queued)), nil
+}
+
+func (m *mockOpQueue) ops() []*operation.QueuedOperation {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.queued
+}
+
+type mockProtocolClientProvider struct {
+	version protocol.Version
+}
+
+func (m *mockProtocolClientProvider) ForNamespace(string) (protocol.Client, error) {
+	return &mockProtocolClient{version: m.version}, nil
+}
+
+type mockProtocolClient struct {
+	version protocol.Version
+}
+
+func (m *mockProtocolClient) Current() (protocol.Version, error) {
+	return m.version, nil
+}
+
+func (m *mockProtocolClient) Get(uint64) (protocol.Version, error) {
+	return m.version, nil
+}
+
+type mockWFClient struct {
+	err error
+}
+
+func (m *mockWFClient) HasSupportedLedgerType(string) (bool, error) {
+	return true, m.err
+}
+
+func mustParseURL(raw string) *url.URL {
+	u, err := url.Parse(raw)
+	if err != nil {
+		panic(err)
+	}
+
+	return u
+}
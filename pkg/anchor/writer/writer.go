@@ -85,6 +85,9 @@ type Providers struct {
 	WitnessStore  witnessStore
 	ActivityStore activityStore
 	WFClient      webfingerClient
+
+	// WitnessHistory is optional. If set, the witness proofs of completed anchor credentials are recorded.
+	WitnessHistory witnessHistory
 }
 
 type webfingerClient interface {
@@ -97,9 +100,14 @@ type activityStore interface {
 
 type witnessStore interface {
 	Put(vcID string, witnesses []*proof.WitnessProof) error
+	Get(vcID string) ([]*proof.WitnessProof, error)
 	Delete(vcID string) error
 }
 
+type witnessHistory interface {
+	Put(vcID string, witnesses []*proof.WitnessProof, completed time.Time) error
+}
+
 type witness interface {
 	Witness(anchorCred []byte) ([]byte, error)
 }
@@ -453,6 +461,8 @@ func (c *Writer) handle(vc *verifiable.Credential) error {
 		return fmt.Errorf("post create activity for hl[%s]: %w", hl, err)
 	}
 
+	c.addToWitnessHistory(vc.ID)
+
 	err = c.WitnessStore.Delete(vc.ID)
 	if err != nil {
 		// this is a clean-up task so no harm if there was an error
@@ -462,6 +472,27 @@ func (c *Writer) handle(vc *verifiable.Credential) error {
 	return nil
 }
 
+// addToWitnessHistory records the witness proofs of the completed anchor credential so that
+// they may be replayed later (e.g. by the witness policy dry-run).
+func (c *Writer) addToWitnessHistory(vcID string) {
+	if c.WitnessHistory == nil {
+		return
+	}
+
+	witnesses, err := c.WitnessStore.Get(vcID)
+	if err != nil {
+		logger.Warnf("failed to get witnesses for vc[%s]: %s", vcID, err.Error())
+
+		return
+	}
+
+	err = c.WitnessHistory.Put(vcID, witnesses, time.Now())
+	if err != nil {
+		// the history is informational so no harm if there was an error
+		logger.Warnf("failed to add witnesses for vc[%s] to history: %s", vcID, err.Error())
+	}
+}
+
 // postCreateActivity creates and posts create activity (announces anchor credential to followers).
 func (c *Writer) postCreateActivity(vc *verifiable.Credential, hl string) error { //nolint: interfacer
 	resourceHash, err := hashlink.GetResourceHashFromHashLink(hl)
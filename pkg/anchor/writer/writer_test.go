@@ -47,6 +47,7 @@ import (
 	"github.com/trustbloc/orb/pkg/store/cas"
 	"github.com/trustbloc/orb/pkg/store/vcstatus"
 	vcstore "github.com/trustbloc/orb/pkg/store/verifiable"
+	witnessstore "github.com/trustbloc/orb/pkg/store/witness"
 	"github.com/trustbloc/orb/pkg/vcsigner"
 	wfclient "github.com/trustbloc/orb/pkg/webfinger/client"
 )
@@ -922,6 +923,48 @@ func TestWriter_handle(t *testing.T) {
 		require.NoError(t, c.handle(anchorVC))
 	})
 
+	t.Run("success - witness history", func(t *testing.T) {
+		vcStore, err := vcstore.New(mem.NewProvider(), testutil.GetLoader(t))
+		require.NoError(t, err)
+
+		history, err := witnessstore.NewHistory(mem.NewProvider(), 0)
+		require.NoError(t, err)
+
+		providers := &Providers{
+			AnchorGraph:    anchorGraph,
+			DidAnchors:     memdidanchor.New(),
+			AnchorBuilder:  &mockTxnBuilder{},
+			Outbox:         &mockOutbox{},
+			Signer:         &mockSigner{},
+			VCStore:        vcStore,
+			WitnessStore:   &mockWitnessStore{},
+			WitnessHistory: history,
+		}
+
+		c, err := New(namespace, apServiceIRI, casIRI, providers, &anchormocks.AnchorPublisher{}, ps,
+			testMaxWitnessDelay, signWithLocalWitness, testutil.GetLoader(t), nil, &mocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		anchorVC, err := verifiable.ParseCredential([]byte(anchorCred),
+			verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(testutil.GetLoader(t)),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, c.handle(anchorVC))
+
+		completed, err := history.GetRecent(0)
+		require.NoError(t, err)
+		require.Len(t, completed, 1)
+		require.Equal(t, anchorVC.ID, completed[0].VCID)
+		require.Len(t, completed[0].Witnesses, 1)
+
+		// An error getting the witnesses shouldn't fail the handler.
+		c.WitnessStore = &mockWitnessStore{GetErr: errors.New("injected get error")}
+
+		require.NoError(t, c.handle(anchorVC))
+	})
+
 	t.Run("error - save anchor credential to store error", func(t *testing.T) {
 		storeProviderWithErr := &mockstore.Provider{
 			OpenStoreReturn: &mockstore.Store{ErrPut: fmt.Errorf("error put")},
@@ -1630,9 +1673,18 @@ func (a *mockActivityStore) QueryReferences(refType spi.ReferenceType, query *sp
 
 type mockWitnessStore struct {
 	PutErr    error
+	GetErr    error
 	DeleteErr error
 }
 
+func (w *mockWitnessStore) Get(vcID string) ([]*proof.WitnessProof, error) {
+	if w.GetErr != nil {
+		return nil, w.GetErr
+	}
+
+	return []*proof.WitnessProof{{Type: proof.WitnessTypeBatch, Witness: "https://witness.example.com"}}, nil
+}
+
 func (w *mockWitnessStore) Put(vcID string, witnesses []*proof.WitnessProof) error {
 	if w.PutErr != nil {
 		return w.PutErr
@@ -0,0 +1,341 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filesystem
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	"github.com/trustbloc/orb/pkg/cas/ipfs"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+var logger = log.New("cas-filesystem")
+
+const (
+	defaultCacheSize = 1000
+	casType          = "filesystem"
+
+	objectsDir   = "objects"
+	tmpDir       = "tmp"
+	corruptedDir = "corrupted"
+
+	dirPermissions = 0o750
+)
+
+// SyncMode specifies how writes are flushed to stable storage.
+type SyncMode string
+
+const (
+	// SyncNone leaves flushing to the operating system.
+	SyncNone SyncMode = "none"
+	// SyncFile flushes the content of each file before it is renamed into place.
+	SyncFile SyncMode = "file"
+	// SyncFull flushes the content of each file as well as the directory that it's renamed into.
+	SyncFull SyncMode = "full"
+)
+
+// ParseSyncMode parses the given string into a SyncMode.
+func ParseSyncMode(mode string) (SyncMode, error) {
+	switch m := SyncMode(strings.ToLower(mode)); m {
+	case SyncNone, SyncFile, SyncFull:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid sync mode [%s] - valid modes are %s, %s and %s",
+			mode, SyncNone, SyncFile, SyncFull)
+	}
+}
+
+// validAddress matches the characters of base58 and base64url encoded resource hashes and CIDs. Anything else is
+// rejected so that an address can never be used to escape the CAS directory.
+var validAddress = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type metricsProvider interface {
+	CASIncrementCacheHitCount()
+	CASReadTime(casType string, value time.Duration)
+}
+
+// Client stores CAS content as files in a local directory. Each file is named after the resource hash of its
+// content and files are sharded into sub-directories (derived from a hash of the file name) so that no single
+// directory grows too large. Files are written atomically (to a temporary file which is then renamed) and the
+// hash of the content is verified on every read from disk. Content that fails verification is moved to the
+// "corrupted" directory and reported as not found so that it's retrieved again from another source.
+type Client struct {
+	dir        string
+	syncMode   SyncMode
+	ipfsClient *ipfs.Client
+	opts       []extendedcasclient.CIDFormatOption
+	cache      gcache.Cache
+	metrics    metricsProvider
+	casLink    string
+	hl         *hashlink.HashLink
+}
+
+// New returns a new filesystem CAS client that stores content under the given directory (which is created if it
+// doesn't exist). If syncMode is empty then SyncFile is used.
+// ipfsClient is optional, but if provided (not nil), then writes will go to IPFS in addition to the filesystem.
+// Reads are always done on only the filesystem.
+// If no CID version is specified, then v1 will be used by default.
+func New(dir string, syncMode SyncMode, casLink string, ipfsClient *ipfs.Client, metrics metricsProvider,
+	cacheSize int, opts ...extendedcasclient.CIDFormatOption) (*Client, error) {
+	if dir == "" {
+		return nil, errors.New("directory is required")
+	}
+
+	for _, d := range []string{objectsDir, tmpDir, corruptedDir} {
+		if err := os.MkdirAll(filepath.Join(dir, d), dirPermissions); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	if syncMode == "" {
+		syncMode = SyncFile
+	}
+
+	if cacheSize == 0 {
+		cacheSize = defaultCacheSize
+	}
+
+	c := &Client{
+		dir:        dir,
+		syncMode:   syncMode,
+		ipfsClient: ipfsClient,
+		opts:       opts,
+		metrics:    metrics,
+		hl:         hashlink.New(),
+		casLink:    casLink,
+	}
+
+	c.cache = gcache.New(cacheSize).ARC().
+		LoaderFunc(func(key interface{}) (interface{}, error) {
+			content, err := c.get(key.(string))
+			if err != nil {
+				return nil, err
+			}
+
+			logger.Debugf("Cached content for CID [%s]", key)
+
+			return content, nil
+		},
+		).Build()
+
+	return c, nil
+}
+
+// Write writes the given content to the filesystem (and IPFS if configured) using this client's default CID
+// version.
+// Returns the address of the content.
+func (c *Client) Write(content []byte) (string, error) {
+	return c.WriteWithCIDFormat(content, c.opts...)
+}
+
+// WriteWithCIDFormat writes the given content to the filesystem (and IPFS if configured) using the CID format
+// specified by opts.
+// Returns the address of the content.
+func (c *Client) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFormatOption) (string, error) {
+	resourceHash, err := c.hl.CreateResourceHash(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to create resource hash from content: %w", err)
+	}
+
+	if err = c.put(resourceHash, content); err != nil {
+		return "", orberrors.NewTransient(fmt.Errorf("failed to write content to the filesystem: %w", err))
+	}
+
+	// add cas link
+	links := []string{c.casLink + "/" + resourceHash}
+
+	if c.ipfsClient != nil {
+		cid, writeErr := c.ipfsClient.WriteWithCIDFormat(content, opts...)
+		if writeErr != nil {
+			return "", orberrors.NewTransient(fmt.Errorf("failed to put content into IPFS (but it was "+
+				"successfully stored in the filesystem): %w", writeErr))
+		}
+
+		// add ipfs link
+		links = append(links, "ipfs://"+cid)
+	}
+
+	if err = c.cache.Set(resourceHash, content); err != nil {
+		// This shouldn't be possible.
+		logger.Warnf("Error caching content for resource hash[%s]: %s", resourceHash, err)
+	} else {
+		logger.Debugf("Cached content for resource hash [%s]", resourceHash)
+	}
+
+	metadata, err := c.hl.CreateMetadataFromLinks(links)
+	if err != nil {
+		return "", fmt.Errorf("failed to create resource hash from content: %w", err)
+	}
+
+	return hashlink.GetHashLink(resourceHash, metadata), nil
+}
+
+// GetPrimaryWriterType returns primary writer type.
+func (c *Client) GetPrimaryWriterType() string {
+	return casType
+}
+
+// Read reads the content of the given address from the filesystem.
+// Returns the content at the given address.
+func (c *Client) Read(address string) ([]byte, error) {
+	if c.cache.Has(address) {
+		c.metrics.CASIncrementCacheHitCount()
+	}
+
+	content, err := c.cache.Get(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return content.([]byte), nil
+}
+
+func (c *Client) get(address string) ([]byte, error) {
+	startTime := time.Now()
+
+	defer func() {
+		c.metrics.CASReadTime(casType, time.Since(startTime))
+	}()
+
+	if !validAddress.MatchString(address) {
+		return nil, fmt.Errorf("invalid address [%s]", address)
+	}
+
+	path := c.objectPath(address)
+
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, orberrors.ErrContentNotFound
+		}
+
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to read content from the filesystem: %w", err))
+	}
+
+	if err = c.hl.VerifyResourceHash(address, content); err != nil {
+		logger.Errorf("Content of file [%s] failed verification: %s. The file will be moved to the %s directory.",
+			path, err, corruptedDir)
+
+		c.quarantine(path, address)
+
+		return nil, orberrors.ErrContentNotFound
+	}
+
+	return content, nil
+}
+
+func (c *Client) put(resourceHash string, content []byte) error {
+	path := c.objectPath(resourceHash)
+
+	if _, err := os.Stat(path); err == nil {
+		// The content is addressed by its hash so there's no need to write it again.
+		return nil
+	}
+
+	dir := filepath.Dir(path)
+
+	if err := os.MkdirAll(dir, dirPermissions); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	tmpPath, err := c.writeTemp(resourceHash, content)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmpPath, path); err != nil {
+		removeFile(tmpPath)
+
+		return fmt.Errorf("rename temporary file: %w", err)
+	}
+
+	if c.syncMode == SyncFull {
+		if err = syncDir(dir); err != nil {
+			return fmt.Errorf("sync directory: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeTemp writes the content to a new file in the temporary directory and returns the path of the file.
+func (c *Client) writeTemp(resourceHash string, content []byte) (string, error) {
+	f, err := ioutil.TempFile(filepath.Join(c.dir, tmpDir), resourceHash+".*")
+	if err != nil {
+		return "", fmt.Errorf("create temporary file: %w", err)
+	}
+
+	_, err = f.Write(content)
+	if err == nil && c.syncMode != SyncNone {
+		err = f.Sync()
+	}
+
+	if e := f.Close(); err == nil {
+		err = e
+	}
+
+	if err != nil {
+		removeFile(f.Name())
+
+		return "", fmt.Errorf("write temporary file: %w", err)
+	}
+
+	return f.Name(), nil
+}
+
+// objectPath returns the path of the file for the given address. The two levels of sub-directories are taken from
+// the hex-encoded hash of the address (rather than from the address itself) so that files are evenly distributed
+// and directory names don't depend on case sensitivity.
+func (c *Client) objectPath(address string) string {
+	h := sha256.Sum256([]byte(address))
+	shard := hex.EncodeToString(h[:2])
+
+	return filepath.Join(c.dir, objectsDir, shard[:2], shard[2:], address)
+}
+
+func (c *Client) quarantine(path, address string) {
+	dest := filepath.Join(c.dir, corruptedDir, fmt.Sprintf("%s.%d", address, time.Now().UnixNano()))
+
+	if err := os.Rename(path, dest); err != nil {
+		logger.Errorf("Error moving corrupted file [%s] to [%s]: %s", path, dest, err)
+	}
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	err = d.Sync()
+
+	if e := d.Close(); err == nil {
+		err = e
+	}
+
+	return err
+}
+
+func removeFile(path string) {
+	if err := os.Remove(path); err != nil {
+		logger.Warnf("Error removing file [%s]: %s", path, err)
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	"github.com/trustbloc/orb/pkg/cas/ipfs"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+)
+
+const casLink = "https://domain.com/cas"
+
+func TestParseSyncMode(t *testing.T) {
+	for _, mode := range []SyncMode{SyncNone, SyncFile, SyncFull} {
+		m, err := ParseSyncMode(string(mode))
+		require.NoError(t, err)
+		require.Equal(t, mode, m)
+	}
+
+	m, err := ParseSyncMode("FULL")
+	require.NoError(t, err)
+	require.Equal(t, SyncFull, m)
+
+	_, err = ParseSyncMode("xxx")
+	require.EqualError(t, err, "invalid sync mode [xxx] - valid modes are none, file and full")
+}
+
+func TestNew(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "cas")
+
+		c, err := New(dir, "", casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		require.Equal(t, SyncFile, c.syncMode)
+		require.Equal(t, "filesystem", c.GetPrimaryWriterType())
+
+		for _, d := range []string{objectsDir, tmpDir, corruptedDir} {
+			require.DirExists(t, filepath.Join(dir, d))
+		}
+	})
+
+	t.Run("No directory", func(t *testing.T) {
+		c, err := New("", SyncNone, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.EqualError(t, err, "directory is required")
+		require.Nil(t, c)
+	})
+
+	t.Run("Create directory error", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, ioutil.WriteFile(file, []byte("content"), 0o600))
+
+		c, err := New(file, SyncNone, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create directory")
+		require.Nil(t, c)
+	})
+}
+
+func TestClient_Write_Read(t *testing.T) {
+	for _, syncMode := range []SyncMode{SyncNone, SyncFile, SyncFull} {
+		syncMode := syncMode
+
+		t.Run(fmt.Sprintf("Sync mode %s", syncMode), func(t *testing.T) {
+			dir := t.TempDir()
+
+			c, err := New(dir, syncMode, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+			require.NoError(t, err)
+
+			hl, err := c.Write([]byte("content"))
+			require.NoError(t, err)
+
+			info, err := hashlink.New().ParseHashLink(hl)
+			require.NoError(t, err)
+			require.Equal(t, []string{casLink + "/" + info.ResourceHash}, info.Links)
+			require.FileExists(t, c.objectPath(info.ResourceHash))
+
+			// Writing the same content again is a no-op.
+			hl2, err := c.Write([]byte("content"))
+			require.NoError(t, err)
+			require.Equal(t, hl, hl2)
+
+			// Read from the cache.
+			content, err := c.Read(info.ResourceHash)
+			require.NoError(t, err)
+			require.Equal(t, "content", string(content))
+
+			// Read from the filesystem using a new client (with an empty cache).
+			c2, err := New(dir, syncMode, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+			require.NoError(t, err)
+
+			content, err = c2.Read(info.ResourceHash)
+			require.NoError(t, err)
+			require.Equal(t, "content", string(content))
+
+			files, err := ioutil.ReadDir(filepath.Join(dir, tmpDir))
+			require.NoError(t, err)
+			require.Empty(t, files)
+		})
+	}
+
+	t.Run("Replicated in IPFS", func(t *testing.T) {
+		ipfsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, err := fmt.Fprint(w, `{"Hash":"bafkreihnoabliopjvscf6irvpwbcxlauirzq7pnwafwt5skdekl3t3e7om"}`)
+			require.NoError(t, err)
+		}))
+		defer ipfsServer.Close()
+
+		c, err := New(t.TempDir(), SyncNone, casLink,
+			ipfs.New(ipfsServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}),
+			&orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		hl, err := c.WriteWithCIDFormat([]byte("content"), extendedcasclient.WithCIDVersion(1))
+		require.NoError(t, err)
+
+		info, err := hashlink.New().ParseHashLink(hl)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			casLink + "/" + info.ResourceHash,
+			"ipfs://bafkreihnoabliopjvscf6irvpwbcxlauirzq7pnwafwt5skdekl3t3e7om",
+		}, info.Links)
+	})
+
+	t.Run("IPFS error", func(t *testing.T) {
+		ipfsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ipfsServer.Close()
+
+		c, err := New(t.TempDir(), SyncNone, casLink,
+			ipfs.New(ipfsServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}),
+			&orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		hl, err := c.Write([]byte("content"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "failed to put content into IPFS")
+		require.Empty(t, hl)
+	})
+
+	t.Run("Content not found", func(t *testing.T) {
+		c, err := New(t.TempDir(), SyncNone, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		content, err := c.Read("uEiB2QWzbZ9ceQ4eoWaxK2hEVtIYNYc9cnudaQR54m3bEDA")
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+		require.Nil(t, content)
+	})
+
+	t.Run("Invalid address", func(t *testing.T) {
+		c, err := New(t.TempDir(), SyncNone, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		for _, address := range []string{"../../etc/passwd", "a/b", "", ".."} {
+			content, err := c.Read(address)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid address")
+			require.Nil(t, content)
+		}
+	})
+}
+
+func TestClient_Corrupted(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := New(dir, SyncNone, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	resourceHash, err := hashlink.New().CreateResourceHash([]byte("content"))
+	require.NoError(t, err)
+
+	path := c.objectPath(resourceHash)
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), dirPermissions))
+	require.NoError(t, ioutil.WriteFile(path, []byte("corrupted content"), 0o600))
+
+	content, err := c.Read(resourceHash)
+	require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+	require.Nil(t, content)
+
+	require.NoFileExists(t, path)
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, corruptedDir))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// The content can be written again.
+	_, err = c.Write([]byte("content"))
+	require.NoError(t, err)
+	require.FileExists(t, path)
+}
+
+func TestClient_Errors(t *testing.T) {
+	t.Run("Write error", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c, err := New(dir, SyncNone, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		require.NoError(t, os.RemoveAll(filepath.Join(dir, tmpDir)))
+
+		hl, err := c.Write([]byte("content"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "create temporary file")
+		require.Empty(t, hl)
+	})
+
+	t.Run("Create shard directory error", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c, err := New(dir, SyncNone, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		resourceHash, err := hashlink.New().CreateResourceHash([]byte("content"))
+		require.NoError(t, err)
+
+		// A file in place of the shard directory.
+		shardDir := filepath.Dir(filepath.Dir(c.objectPath(resourceHash)))
+		require.NoError(t, ioutil.WriteFile(shardDir, []byte("x"), 0o600))
+
+		hl, err := c.Write([]byte("content"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create directory")
+		require.Empty(t, hl)
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		c, err := New(t.TempDir(), SyncNone, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		resourceHash, err := hashlink.New().CreateResourceHash([]byte("content"))
+		require.NoError(t, err)
+
+		// A directory in place of the file.
+		require.NoError(t, os.MkdirAll(c.objectPath(resourceHash), dirPermissions))
+
+		content, err := c.Read(resourceHash)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "failed to read content from the filesystem")
+		require.Nil(t, content)
+	})
+}
@@ -0,0 +1,679 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/orb/pkg/anchor/activity"
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+	casstore "github.com/trustbloc/orb/pkg/store/cas"
+)
+
+var logger = log.New("cas-gc")
+
+// ErrInProgress is returned when a collection is requested while another collection is in progress.
+var ErrInProgress = errors.New("CAS garbage collection is already in progress")
+
+const (
+	archiveStoreName = "cas_archive"
+
+	defaultRetention            = 7 * 24 * time.Hour
+	defaultCompressionAlgorithm = "GZIP"
+	defaultCIDVersion           = 1
+
+	ipfsPrefix = "ipfs://"
+)
+
+// Mode specifies what is done with CAS content that is no longer reachable from the anchor graph.
+type Mode string
+
+const (
+	// ModeDryRun reports the unreachable content without modifying the CAS.
+	ModeDryRun Mode = "dry-run"
+	// ModeArchive moves the unreachable content to the CAS archive store.
+	ModeArchive Mode = "archive"
+	// ModeDelete deletes the unreachable content.
+	ModeDelete Mode = "delete"
+)
+
+// ParseMode parses the given string into a garbage collection Mode.
+func ParseMode(mode string) (Mode, error) {
+	switch m := Mode(strings.ToLower(mode)); m {
+	case ModeDryRun, ModeArchive, ModeDelete:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid CAS garbage collection mode [%s] - valid modes are %s, %s and %s",
+			mode, ModeDryRun, ModeArchive, ModeDelete)
+	}
+}
+
+// Config holds the configuration for the garbage collector.
+type Config struct {
+	// Interval is the interval at which garbage collection is performed. If zero then garbage
+	// collection is only performed on request.
+	Interval time.Duration
+	// Retention is the minimum age of unreachable content before it is collected.
+	Retention time.Duration
+	// Mode specifies what is done with the unreachable content. Defaults to dry-run.
+	Mode Mode
+	// CompressionAlgorithm is the algorithm used to compress the Sidetree batch files.
+	CompressionAlgorithm string
+	// CIDVersion is the version of the CIDs of the content that was written to IPFS. It's used to unpin
+	// collected content from IPFS. Defaults to 1 if nil.
+	CIDVersion *int
+}
+
+// Providers contains all of the providers required by the garbage collector.
+type Providers struct {
+	StoreProvider storage.Provider
+	AnchorStore   anchorStore
+	// CASClient is used to delete the collected content from the local CAS so that it's also evicted from
+	// the CAS cache.
+	CASClient casClient
+	// IPFSClient is used to unpin collected content from IPFS (when local CAS writes are replicated in IPFS).
+	// If nil then collected content is not unpinned.
+	IPFSClient ipfsClient
+}
+
+type anchorStore interface {
+	GetAllAnchors() ([]string, error)
+}
+
+type casClient interface {
+	Delete(resourceHashes ...string) error
+}
+
+type ipfsClient interface {
+	GetCID(content []byte, opts ...extendedcasclient.CIDFormatOption) (string, error)
+	Unpin(cid string) error
+}
+
+type decompressor interface {
+	Decompress(alg string, data []byte) ([]byte, error)
+}
+
+// Report contains the results of a garbage collection run.
+type Report struct {
+	Mode      Mode      `json:"mode"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	// Anchors is the number of anchors that were traversed.
+	Anchors int `json:"anchors"`
+	// Live is the number of resources that are reachable from the latest anchors.
+	Live int `json:"live"`
+	// Scanned is the number of resources in the CAS that were considered for collection.
+	Scanned int `json:"scanned"`
+	// Pinned is the number of unreachable resources that were not collected since they're pinned.
+	Pinned int `json:"pinned"`
+	// Retained is the number of unreachable resources that were not collected since they're
+	// within the retention window.
+	Retained int `json:"retained"`
+	// Missing contains the reachable resources that were not found in the CAS. If any resources are missing
+	// then the content that they reference can't be marked, so the run is performed in dry-run mode.
+	Missing []string `json:"missing,omitempty"`
+	// Collected contains the resources that were (or, in dry-run mode, would have been) collected.
+	Collected []string `json:"collected,omitempty"`
+	// Unpinned is the number of collected resources that were unpinned from IPFS.
+	Unpinned int `json:"unpinned,omitempty"`
+}
+
+// Collector periodically traverses the anchor graph, starting from the latest anchor of each DID, in order to
+// mark the anchors and Sidetree batch files that are still reachable. Content in the local CAS that is not
+// reachable, is not pinned, and is older than the retention window is then reported, archived or deleted
+// depending on the configured mode. If an IPFS client is provided then archived or deleted content is also
+// unpinned from IPFS so that it may be garbage collected by the IPFS node.
+//
+// Only content that has a creation time tag is considered for collection. Legacy content (written before
+// creation times were recorded) is never collected and isn't included in the report. If local CAS content is
+// replicated in IPFS then the CAS replicator tags the legacy content that's reachable from the latest anchors (so
+// that it may be collected once it's no longer reachable), but legacy content that was already unreachable
+// remains untagged and must be removed manually.
+type Collector struct {
+	*Providers
+	*lifecycle.Lifecycle
+
+	casStore     storage.Store
+	archiveStore storage.Store
+	pinStore     storage.Store
+	compression  decompressor
+	done         chan struct{}
+	interval     time.Duration
+	retention    time.Duration
+	mode         Mode
+	compAlg      string
+	cidOpts      []extendedcasclient.CIDFormatOption
+	inProgress   int32
+}
+
+// New returns a new CAS garbage collector.
+func New(providers *Providers, cfg Config) (*Collector, error) {
+	casStore, err := providers.StoreProvider.OpenStore(casstore.StoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", casstore.StoreName, err)
+	}
+
+	archiveStore, err := providers.StoreProvider.OpenStore(archiveStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", archiveStoreName, err)
+	}
+
+	pinStore, err := openPinStore(providers.StoreProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	cidVersion := defaultCIDVersion
+	if cfg.CIDVersion != nil {
+		cidVersion = *cfg.CIDVersion
+	}
+
+	c := &Collector{
+		Providers:    providers,
+		casStore:     casStore,
+		archiveStore: archiveStore,
+		pinStore:     pinStore,
+		compression:  compression.New(compression.WithDefaultAlgorithms()),
+		done:         make(chan struct{}),
+		interval:     cfg.Interval,
+		retention:    cfg.Retention,
+		mode:         cfg.Mode,
+		compAlg:      cfg.CompressionAlgorithm,
+		cidOpts:      []extendedcasclient.CIDFormatOption{extendedcasclient.WithCIDVersion(cidVersion)},
+	}
+
+	if c.retention == 0 {
+		c.retention = defaultRetention
+	}
+
+	if c.mode == "" {
+		c.mode = ModeDryRun
+	}
+
+	if c.compAlg == "" {
+		c.compAlg = defaultCompressionAlgorithm
+	}
+
+	c.Lifecycle = lifecycle.New("cas-gc",
+		lifecycle.WithStart(c.start),
+		lifecycle.WithStop(c.stop))
+
+	return c, nil
+}
+
+// Mode returns the configured garbage collection mode.
+func (c *Collector) Mode() Mode {
+	return c.mode
+}
+
+func (c *Collector) start() {
+	if c.interval == 0 {
+		logger.Infof("Periodic CAS garbage collection is disabled")
+
+		return
+	}
+
+	go c.run()
+
+	logger.Infof("Started CAS garbage collector - Interval: %s, Retention: %s, Mode: %s",
+		c.interval, c.retention, c.mode)
+}
+
+func (c *Collector) stop() {
+	close(c.done)
+
+	logger.Infof("Stopped CAS garbage collector")
+}
+
+func (c *Collector) run() {
+	for {
+		select {
+		case <-time.After(c.interval):
+			if _, err := c.Collect(false); err != nil {
+				logger.Errorf("Error collecting CAS garbage: %s", err)
+			}
+		case <-c.done:
+			logger.Debugf("Exiting CAS garbage collector.")
+
+			return
+		}
+	}
+}
+
+// Collect traverses the anchor graph and collects the unreachable CAS content according to the configured
+// mode. If dryRun is true then the unreachable content is only reported, regardless of the configured mode.
+// ErrInProgress is returned if a collection is already in progress.
+func (c *Collector) Collect(dryRun bool) (*Report, error) {
+	if !atomic.CompareAndSwapInt32(&c.inProgress, 0, 1) {
+		return nil, ErrInProgress
+	}
+
+	defer atomic.StoreInt32(&c.inProgress, 0)
+
+	mode := c.mode
+	if dryRun {
+		mode = ModeDryRun
+	}
+
+	report := &Report{Mode: mode, StartTime: time.Now()}
+
+	pinned, err := c.getPinnedResources()
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := c.mark(report)
+	if err != nil {
+		return nil, fmt.Errorf("mark: %w", err)
+	}
+
+	if len(report.Missing) > 0 && report.Mode != ModeDryRun {
+		// The content that is only reachable through a missing resource can't be marked, so it would be collected.
+		logger.Warnf("%d reachable resources are missing from the CAS so unreachable content will only be "+
+			"reported: %s", len(report.Missing), report.Missing)
+
+		report.Mode = ModeDryRun
+	}
+
+	err = c.sweep(live, pinned, report)
+	if err != nil {
+		return nil, fmt.Errorf("sweep: %w", err)
+	}
+
+	report.EndTime = time.Now()
+
+	logger.Infof("CAS garbage collection completed in %s - Mode: %s, Anchors: %d, Live: %d, Scanned: %d, "+
+		"Pinned: %d, Retained: %d, Missing: %d, Collected: %d, Unpinned: %d", report.EndTime.Sub(report.StartTime),
+		report.Mode, report.Anchors, report.Live, report.Scanned, report.Pinned, report.Retained, len(report.Missing),
+		len(report.Collected), report.Unpinned)
+
+	return report, nil
+}
+
+// Reachable traverses the anchor graph, starting from the latest anchors in the given anchor store, and returns
+// the resource hashes of the anchors and Sidetree batch files that are reachable and exist in the given CAS store.
+func Reachable(casStore storage.Store, anchorStore anchorStore, compressionAlgorithm string) (map[string]struct{}, error) { //nolint:lll
+	if compressionAlgorithm == "" {
+		compressionAlgorithm = defaultCompressionAlgorithm
+	}
+
+	c := &Collector{
+		Providers:   &Providers{AnchorStore: anchorStore},
+		casStore:    casStore,
+		compression: compression.New(compression.WithDefaultAlgorithms()),
+		compAlg:     compressionAlgorithm,
+	}
+
+	report := &Report{}
+
+	live, err := c.mark(report)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resourceHash := range report.Missing {
+		delete(live, resourceHash)
+	}
+
+	return live, nil
+}
+
+// mark traverses the anchor graph starting from the latest anchors and returns the resource hashes of all of
+// the reachable anchors and Sidetree batch files.
+func (c *Collector) mark(report *Report) (map[string]struct{}, error) {
+	anchors, err := c.AnchorStore.GetAllAnchors()
+	if err != nil {
+		return nil, fmt.Errorf("get latest anchors: %w", err)
+	}
+
+	live := make(map[string]struct{})
+
+	for len(anchors) > 0 {
+		hl := anchors[0]
+		anchors = anchors[1:]
+
+		anchorBytes, e := c.markAndRead(hl, live, report)
+		if e != nil {
+			return nil, e
+		}
+
+		if anchorBytes == nil {
+			continue
+		}
+
+		report.Anchors++
+
+		act, e := parseAnchor(anchorBytes)
+		if e != nil {
+			return nil, fmt.Errorf("parse anchor [%s]: %w", hl, e)
+		}
+
+		payload, e := activity.GetPayloadFromActivity(act)
+		if e != nil {
+			return nil, fmt.Errorf("get payload from anchor [%s]: %w", hl, e)
+		}
+
+		if e := c.markBatchFiles(payload.CoreIndex, live, report); e != nil {
+			return nil, fmt.Errorf("mark batch files of anchor [%s]: %w", hl, e)
+		}
+
+		for _, previous := range payload.PreviousAnchors {
+			if previous != "" {
+				anchors = append(anchors, previous)
+			}
+		}
+	}
+
+	report.Live = len(live)
+
+	return live, nil
+}
+
+// markBatchFiles marks the given core index file and all of the files that it references.
+func (c *Collector) markBatchFiles(coreIndexURI string, live map[string]struct{}, report *Report) error {
+	coreIndexBytes, err := c.markAndReadFile(coreIndexURI, live, report)
+	if err != nil || coreIndexBytes == nil {
+		return err
+	}
+
+	coreIndex, err := models.ParseCoreIndexFile(coreIndexBytes)
+	if err != nil {
+		return fmt.Errorf("parse core index file [%s]: %w", coreIndexURI, err)
+	}
+
+	c.markURI(coreIndex.CoreProofFileURI, live)
+
+	if coreIndex.ProvisionalIndexFileURI == "" {
+		return nil
+	}
+
+	provisionalIndexBytes, err := c.markAndReadFile(coreIndex.ProvisionalIndexFileURI, live, report)
+	if err != nil || provisionalIndexBytes == nil {
+		return err
+	}
+
+	provisionalIndex, err := models.ParseProvisionalIndexFile(provisionalIndexBytes)
+	if err != nil {
+		return fmt.Errorf("parse provisional index file [%s]: %w", coreIndex.ProvisionalIndexFileURI, err)
+	}
+
+	c.markURI(provisionalIndex.ProvisionalProofFileURI, live)
+
+	for _, chunk := range provisionalIndex.Chunks {
+		c.markURI(chunk.ChunkFileURI, live)
+	}
+
+	return nil
+}
+
+// markAndReadFile marks the given Sidetree batch file and returns its decompressed content. Nil is returned if
+// the file was already marked or if it doesn't exist.
+func (c *Collector) markAndReadFile(uri string, live map[string]struct{}, report *Report) ([]byte, error) {
+	content, err := c.markAndRead(uri, live, report)
+	if err != nil || content == nil {
+		return nil, err
+	}
+
+	decompressed, err := c.compression.Decompress(c.compAlg, content)
+	if err != nil {
+		return nil, fmt.Errorf("decompress [%s]: %w", uri, err)
+	}
+
+	return decompressed, nil
+}
+
+// markAndRead marks the given resource and returns its content. Nil is returned if the resource was already
+// marked or if it doesn't exist, in which case it is added to the report's missing resources.
+func (c *Collector) markAndRead(uri string, live map[string]struct{}, report *Report) ([]byte, error) {
+	resourceHash, marked := c.markURI(uri, live)
+	if !marked {
+		return nil, nil
+	}
+
+	content, err := c.casStore.Get(resourceHash)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			logger.Debugf("Reachable resource [%s] not found in the CAS", uri)
+
+			report.Missing = append(report.Missing, resourceHash)
+
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("get resource [%s]: %w", uri, err)
+	}
+
+	return content, nil
+}
+
+// markURI marks the resource of the given URI as live. False is returned if the URI is empty or if
+// the resource was already marked.
+func (c *Collector) markURI(uri string, live map[string]struct{}) (string, bool) {
+	if uri == "" {
+		return "", false
+	}
+
+	resourceHash := resourceHashFromURI(uri)
+
+	if _, ok := live[resourceHash]; ok {
+		return resourceHash, false
+	}
+
+	live[resourceHash] = struct{}{}
+
+	return resourceHash, true
+}
+
+// sweep scans the CAS for content that isn't live, isn't pinned and is older than the retention
+// window, and then collects the content according to the report's mode.
+func (c *Collector) sweep(live, pinned map[string]struct{}, report *Report) error {
+	iter, err := c.casStore.Query(casstore.CreatedTagName)
+	if err != nil {
+		return fmt.Errorf("query CAS: %w", err)
+	}
+
+	defer func() {
+		if e := iter.Close(); e != nil {
+			logger.Warnf("Failed to close iterator: %s", e)
+		}
+	}()
+
+	cutoff := time.Now().Add(-c.retention)
+
+	ok, err := iter.Next()
+	if err != nil {
+		return fmt.Errorf("get next CAS entry: %w", err)
+	}
+
+	for ok {
+		resourceHash, created, e := getEntry(iter)
+		if e != nil {
+			return e
+		}
+
+		report.Scanned++
+
+		if _, isLive := live[resourceHash]; !isLive {
+			if _, isPinned := pinned[resourceHash]; isPinned {
+				report.Pinned++
+			} else if created.After(cutoff) {
+				report.Retained++
+			} else {
+				report.Collected = append(report.Collected, resourceHash)
+			}
+		}
+
+		ok, err = iter.Next()
+		if err != nil {
+			return fmt.Errorf("get next CAS entry: %w", err)
+		}
+	}
+
+	return c.collect(report)
+}
+
+func (c *Collector) collect(report *Report) error {
+	if report.Mode == ModeDryRun || len(report.Collected) == 0 {
+		return nil
+	}
+
+	cids := c.getIPFSCIDs(report.Collected)
+
+	if report.Mode == ModeArchive {
+		for _, resourceHash := range report.Collected {
+			if err := c.archive(resourceHash); err != nil {
+				return err
+			}
+		}
+	}
+
+	err := c.CASClient.Delete(report.Collected...)
+	if err != nil {
+		return fmt.Errorf("delete %d resources from CAS: %w", len(report.Collected), err)
+	}
+
+	report.Unpinned = c.unpin(cids)
+
+	return nil
+}
+
+// getIPFSCIDs returns the IPFS CIDs of the given resources. The CIDs are computed before the resources are
+// collected since the content is needed to compute the CID. Resources whose CID can't be computed are skipped.
+func (c *Collector) getIPFSCIDs(resourceHashes []string) []string {
+	if c.IPFSClient == nil {
+		return nil
+	}
+
+	var cids []string
+
+	for _, resourceHash := range resourceHashes {
+		content, err := c.casStore.Get(resourceHash)
+		if err != nil {
+			logger.Warnf("Failed to get resource [%s] from CAS in order to unpin it from IPFS: %s", resourceHash, err)
+
+			continue
+		}
+
+		cid, err := c.IPFSClient.GetCID(content, c.cidOpts...)
+		if err != nil {
+			logger.Warnf("Failed to get IPFS CID of resource [%s]: %s", resourceHash, err)
+
+			continue
+		}
+
+		cids = append(cids, cid)
+	}
+
+	return cids
+}
+
+// unpin unpins the given CIDs from IPFS and returns the number of CIDs that were unpinned. Errors are logged
+// since the content has already been collected from the local CAS.
+func (c *Collector) unpin(cids []string) int {
+	var unpinned int
+
+	for _, cid := range cids {
+		if err := c.IPFSClient.Unpin(cid); err != nil {
+			logger.Warnf("Failed to unpin CID [%s] from IPFS: %s", cid, err)
+
+			continue
+		}
+
+		unpinned++
+	}
+
+	return unpinned
+}
+
+func (c *Collector) archive(resourceHash string) error {
+	content, err := c.casStore.Get(resourceHash)
+	if err != nil {
+		return fmt.Errorf("get resource [%s] from CAS: %w", resourceHash, err)
+	}
+
+	err = c.archiveStore.Put(resourceHash, content,
+		storage.Tag{Name: casstore.CreatedTagName, Value: strconv.FormatInt(time.Now().Unix(), 10)})
+	if err != nil {
+		return fmt.Errorf("archive resource [%s]: %w", resourceHash, err)
+	}
+
+	return nil
+}
+
+func getEntry(iter storage.Iterator) (string, time.Time, error) {
+	resourceHash, err := iter.Key()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("get CAS entry key: %w", err)
+	}
+
+	tags, err := iter.Tags()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("get CAS entry tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		if tag.Name != casstore.CreatedTagName {
+			continue
+		}
+
+		created, e := strconv.ParseInt(tag.Value, 10, 64)
+		if e != nil {
+			return "", time.Time{}, fmt.Errorf("invalid creation time [%s] for CAS entry [%s]: %w",
+				tag.Value, resourceHash, e)
+		}
+
+		return resourceHash, time.Unix(created, 0), nil
+	}
+
+	return "", time.Time{}, fmt.Errorf("creation time not found for CAS entry [%s]", resourceHash)
+}
+
+func parseAnchor(anchorBytes []byte) (*activity.Activity, error) {
+	anchor := &struct {
+		CredentialSubject json.RawMessage `json:"credentialSubject"`
+	}{}
+
+	err := json.Unmarshal(anchorBytes, anchor)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal anchor: %w", err)
+	}
+
+	act := &activity.Activity{}
+
+	err = json.Unmarshal(anchor.CredentialSubject, act)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal credential subject: %w", err)
+	}
+
+	return act, nil
+}
+
+// resourceHashFromURI returns the resource hash (i.e. the key in the local CAS) of the given URI
+// which may be a hashlink, an IPFS URI or a resource hash.
+func resourceHashFromURI(uri string) string {
+	if strings.HasPrefix(uri, hashlink.HLPrefix) {
+		resourceHash, err := hashlink.GetResourceHashFromHashLink(uri)
+		if err == nil {
+			return resourceHash
+		}
+	}
+
+	return strings.TrimPrefix(uri, ipfsPrefix)
+}
@@ -0,0 +1,815 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/orb/pkg/anchor/activity"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	casstore "github.com/trustbloc/orb/pkg/store/cas"
+	didanchorstore "github.com/trustbloc/orb/pkg/store/didanchor"
+	"github.com/trustbloc/orb/pkg/store/mocks"
+)
+
+const (
+	casLink = "https://domain.com/cas"
+
+	suffix1 = "EiA329wd6Aj36YRmp7NGkeB5ADnVt8ARdMZMPzfXsjwTJA"
+	suffix2 = "EiCKbfEWkKt3uKH1zWMJJmQ8dwn8MqPXtLX3GMXcoTBbBw"
+)
+
+func TestParseMode(t *testing.T) {
+	for _, m := range []Mode{ModeDryRun, ModeArchive, ModeDelete} {
+		mode, err := ParseMode(string(m))
+		require.NoError(t, err)
+		require.Equal(t, m, mode)
+	}
+
+	mode, err := ParseMode("DELETE")
+	require.NoError(t, err)
+	require.Equal(t, ModeDelete, mode)
+
+	_, err = ParseMode("invalid")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid CAS garbage collection mode [invalid]")
+}
+
+func TestNew(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		c, err := New(&Providers{StoreProvider: mem.NewProvider()}, Config{})
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		require.Equal(t, ModeDryRun, c.Mode())
+		require.Equal(t, defaultRetention, c.retention)
+		require.Equal(t, defaultCompressionAlgorithm, c.compAlg)
+	})
+
+	t.Run("Open CAS store error", func(t *testing.T) {
+		p := &mocks.Provider{}
+		p.OpenStoreReturns(nil, errors.New("injected open error"))
+
+		_, err := New(&Providers{StoreProvider: p}, Config{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open store [cas_store]: injected open error")
+	})
+
+	t.Run("Open archive store error", func(t *testing.T) {
+		p := &mocks.Provider{}
+		p.OpenStoreReturnsOnCall(1, nil, errors.New("injected open error"))
+
+		_, err := New(&Providers{StoreProvider: p}, Config{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open store [cas_archive]: injected open error")
+	})
+
+	t.Run("Open pin store error", func(t *testing.T) {
+		p := &mocks.Provider{}
+		p.OpenStoreReturnsOnCall(2, nil, errors.New("injected open error"))
+
+		_, err := New(&Providers{StoreProvider: p}, Config{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open store [cas_pins]: injected open error")
+	})
+
+	t.Run("Set pin store config error", func(t *testing.T) {
+		p := &mocks.Provider{}
+		p.SetStoreConfigReturns(errors.New("injected config error"))
+
+		_, err := New(&Providers{StoreProvider: p}, Config{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "set store configuration for [cas_pins]: injected config error")
+	})
+}
+
+func TestCollector_Collect(t *testing.T) {
+	t.Run("Dry run", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		c := env.newCollector(t, Config{Retention: time.Nanosecond, Mode: ModeDelete})
+
+		report, err := c.Collect(true)
+		require.NoError(t, err)
+		require.Equal(t, ModeDryRun, report.Mode)
+		require.Equal(t, 2, report.Anchors)
+		require.Len(t, env.live, report.Live)
+		require.Equal(t, len(env.live)+len(env.garbage), report.Scanned)
+		require.Zero(t, report.Pinned)
+		require.Zero(t, report.Retained)
+		require.Empty(t, report.Missing)
+		require.ElementsMatch(t, env.garbage, report.Collected)
+
+		env.requireExists(t, env.live...)
+		env.requireExists(t, env.garbage...)
+	})
+
+	t.Run("Retention window", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		c := env.newCollector(t, Config{Mode: ModeDelete})
+
+		report, err := c.Collect(false)
+		require.NoError(t, err)
+		require.Equal(t, ModeDelete, report.Mode)
+		require.Equal(t, len(env.garbage), report.Retained)
+		require.Empty(t, report.Collected)
+
+		env.requireExists(t, env.garbage...)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		c := env.newCollector(t, Config{Retention: time.Nanosecond, Mode: ModeDelete})
+
+		pinned := env.garbage[0]
+
+		require.NoError(t, c.UpdatePins([]string{hashlink.GetHashLinkFromResourceHash(pinned)}, nil))
+
+		report, err := c.Collect(false)
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Pinned)
+		require.ElementsMatch(t, env.garbage[1:], report.Collected)
+
+		env.requireExists(t, env.live...)
+		env.requireExists(t, pinned)
+		env.requireNotExists(t, env.garbage[1:]...)
+
+		// Nothing left to collect.
+		report, err = c.Collect(false)
+		require.NoError(t, err)
+		require.Empty(t, report.Collected)
+	})
+
+	t.Run("Delete - evicted from CAS cache", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		// Read the garbage through the CAS client so that it's cached.
+		for _, resourceHash := range env.garbage {
+			_, err := env.cas.Read(resourceHash)
+			require.NoError(t, err)
+		}
+
+		c := env.newCollector(t, Config{Retention: time.Nanosecond, Mode: ModeDelete})
+
+		report, err := c.Collect(false)
+		require.NoError(t, err)
+		require.ElementsMatch(t, env.garbage, report.Collected)
+
+		for _, resourceHash := range env.garbage {
+			_, err := env.cas.Read(resourceHash)
+			require.Truef(t, errors.Is(err, orberrors.ErrContentNotFound), "resource [%s] should not be cached",
+				resourceHash)
+		}
+	})
+
+	t.Run("Archive", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		c := env.newCollector(t, Config{Retention: time.Nanosecond, Mode: ModeArchive})
+
+		report, err := c.Collect(false)
+		require.NoError(t, err)
+		require.Equal(t, ModeArchive, report.Mode)
+		require.ElementsMatch(t, env.garbage, report.Collected)
+
+		env.requireExists(t, env.live...)
+		env.requireNotExists(t, env.garbage...)
+
+		archiveStore, err := env.provider.OpenStore(archiveStoreName)
+		require.NoError(t, err)
+
+		for _, resourceHash := range env.garbage {
+			_, err = archiveStore.Get(resourceHash)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("Unpin from IPFS", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		ipfsClient := &mockIPFSClient{}
+
+		c, err := New(&Providers{
+			StoreProvider: env.provider,
+			AnchorStore:   env.anchorStore,
+			CASClient:     env.cas,
+			IPFSClient:    ipfsClient,
+		}, Config{Retention: time.Nanosecond, Mode: ModeDelete})
+		require.NoError(t, err)
+
+		report, err := c.Collect(false)
+		require.NoError(t, err)
+		require.ElementsMatch(t, env.garbage, report.Collected)
+		require.Equal(t, len(env.garbage), report.Unpinned)
+
+		var expectedCIDs []string
+
+		for _, resourceHash := range env.garbage {
+			expectedCIDs = append(expectedCIDs, "cid-"+resourceHash)
+		}
+
+		require.ElementsMatch(t, expectedCIDs, ipfsClient.unpinned)
+
+		env.requireNotExists(t, env.garbage...)
+	})
+
+	t.Run("Unpin from IPFS - dry run", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		ipfsClient := &mockIPFSClient{}
+
+		c, err := New(&Providers{
+			StoreProvider: env.provider,
+			AnchorStore:   env.anchorStore,
+			CASClient:     env.cas,
+			IPFSClient:    ipfsClient,
+		}, Config{Retention: time.Nanosecond, Mode: ModeDelete})
+		require.NoError(t, err)
+
+		report, err := c.Collect(true)
+		require.NoError(t, err)
+		require.Zero(t, report.Unpinned)
+		require.Empty(t, ipfsClient.unpinned)
+	})
+
+	t.Run("Unpin from IPFS - errors", func(t *testing.T) {
+		for _, ipfsClient := range []*mockIPFSClient{
+			{errGetCID: errors.New("injected GetCID error")},
+			{errUnpin: errors.New("injected Unpin error")},
+		} {
+			env := newTestEnv(t)
+
+			c, err := New(&Providers{
+				StoreProvider: env.provider,
+				AnchorStore:   env.anchorStore,
+				CASClient:     env.cas,
+				IPFSClient:    ipfsClient,
+			}, Config{Retention: time.Nanosecond, Mode: ModeArchive})
+			require.NoError(t, err)
+
+			// Content is collected even though it couldn't be unpinned from IPFS.
+			report, err := c.Collect(false)
+			require.NoError(t, err)
+			require.ElementsMatch(t, env.garbage, report.Collected)
+			require.Zero(t, report.Unpinned)
+
+			env.requireNotExists(t, env.garbage...)
+		}
+	})
+
+	t.Run("Missing content", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		missingHL := hashlink.GetHashLinkFromResourceHash("uEiDmissing")
+
+		require.NoError(t, env.anchorStore.PutBulk([]string{suffix2}, missingHL))
+
+		c := env.newCollector(t, Config{Retention: time.Nanosecond})
+
+		report, err := c.Collect(false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"uEiDmissing"}, report.Missing)
+	})
+
+	t.Run("Missing anchor -> dry run", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		// Everything behind the missing anchor looks unreachable.
+		missing := env.live[len(env.live)-1]
+
+		require.NoError(t, env.casStore.Delete(missing))
+
+		c := env.newCollector(t, Config{Retention: time.Nanosecond, Mode: ModeDelete})
+
+		report, err := c.Collect(false)
+		require.NoError(t, err)
+		require.Equal(t, ModeDryRun, report.Mode)
+		require.Equal(t, []string{missing}, report.Missing)
+		require.NotEmpty(t, report.Collected)
+
+		env.requireExists(t, env.live[:len(env.live)-1]...)
+		env.requireExists(t, env.garbage...)
+	})
+
+	t.Run("Untagged content is not collected", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		require.NoError(t, env.casStore.Put("uEiDuntagged", []byte("untagged")))
+
+		c := env.newCollector(t, Config{Retention: time.Nanosecond, Mode: ModeDelete})
+
+		report, err := c.Collect(false)
+		require.NoError(t, err)
+		require.NotContains(t, report.Collected, "uEiDuntagged")
+
+		env.requireExists(t, "uEiDuntagged")
+	})
+
+	t.Run("In progress", func(t *testing.T) {
+		c := newTestEnv(t).newCollector(t, Config{})
+		c.inProgress = 1
+
+		_, err := c.Collect(false)
+		require.True(t, errors.Is(err, ErrInProgress))
+	})
+}
+
+func TestCollector_CollectErrors(t *testing.T) {
+	errExpected := errors.New("injected error")
+
+	t.Run("Get anchors error", func(t *testing.T) {
+		c := newTestEnv(t).newCollector(t, Config{})
+		c.AnchorStore = &mockAnchorStore{err: errExpected}
+
+		_, err := c.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Get pins error", func(t *testing.T) {
+		c := newTestEnv(t).newCollector(t, Config{})
+		c.pinStore = &errStore{Store: c.pinStore, errQuery: errExpected}
+
+		_, err := c.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Get content error", func(t *testing.T) {
+		c := newTestEnv(t).newCollector(t, Config{})
+		c.casStore = &errStore{Store: c.casStore, errGet: errExpected}
+
+		_, err := c.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Invalid anchor", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		hl, err := env.cas.Write([]byte("{"))
+		require.NoError(t, err)
+		require.NoError(t, env.anchorStore.PutBulk([]string{suffix2}, hl))
+
+		_, err = env.newCollector(t, Config{}).Collect(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse anchor")
+	})
+
+	t.Run("Invalid anchor payload", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		hl, err := env.cas.Write([]byte(`{"credentialSubject":{}}`))
+		require.NoError(t, err)
+		require.NoError(t, env.anchorStore.PutBulk([]string{suffix2}, hl))
+
+		_, err = env.newCollector(t, Config{}).Collect(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get payload from anchor")
+	})
+
+	t.Run("Invalid compression", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		_, err := env.newCollector(t, Config{CompressionAlgorithm: "invalid"}).Collect(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decompress")
+	})
+
+	t.Run("Invalid core index file", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.writeAnchor(t, suffix2, env.writeCompressed(t, []byte("{")), "")
+
+		_, err := env.newCollector(t, Config{}).Collect(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse core index file")
+	})
+
+	t.Run("Invalid provisional index file", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		coreIndex := env.writeJSON(t, &models.CoreIndexFile{
+			ProvisionalIndexFileURI: env.writeCompressed(t, []byte("{")),
+		})
+
+		env.writeAnchor(t, suffix2, coreIndex, "")
+
+		_, err := env.newCollector(t, Config{}).Collect(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse provisional index file")
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		c := newTestEnv(t).newCollector(t, Config{})
+		c.casStore = &errStore{Store: c.casStore, errQuery: errExpected}
+
+		_, err := c.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Iterator errors", func(t *testing.T) {
+		c := newTestEnv(t).newCollector(t, Config{})
+
+		iter := &mocks.Iterator{}
+		iter.NextReturns(false, errExpected)
+
+		c.casStore = &errStore{Store: c.casStore, iter: iter}
+
+		_, err := c.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+
+		iter = &mocks.Iterator{}
+		iter.NextReturns(true, nil)
+		iter.KeyReturns("", errExpected)
+
+		c.casStore = &errStore{Store: c.casStore, iter: iter}
+
+		_, err = c.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+
+		iter = &mocks.Iterator{}
+		iter.NextReturns(true, nil)
+		iter.TagsReturns(nil, errExpected)
+
+		c.casStore = &errStore{Store: c.casStore, iter: iter}
+
+		_, err = c.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+
+		iter = &mocks.Iterator{}
+		iter.NextReturns(true, nil)
+		iter.KeyReturns("key", nil)
+		iter.TagsReturns([]storage.Tag{{Name: casstore.CreatedTagName, Value: "invalid"}}, nil)
+
+		c.casStore = &errStore{Store: c.casStore, iter: iter}
+
+		_, err = c.Collect(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid creation time [invalid] for CAS entry [key]")
+
+		iter = &mocks.Iterator{}
+		iter.NextReturns(true, nil)
+		iter.KeyReturns("key", nil)
+
+		c.casStore = &errStore{Store: c.casStore, iter: iter}
+
+		_, err = c.Collect(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "creation time not found for CAS entry [key]")
+
+		iter = &mocks.Iterator{}
+		iter.NextReturnsOnCall(0, true, nil)
+		iter.NextReturnsOnCall(1, false, errExpected)
+		iter.KeyReturns("key", nil)
+		iter.TagsReturns([]storage.Tag{{Name: casstore.CreatedTagName, Value: "0"}}, nil)
+		iter.CloseReturns(errors.New("injected close error"))
+
+		c.casStore = &errStore{Store: c.casStore, iter: iter}
+
+		_, err = c.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Archive error", func(t *testing.T) {
+		c := newTestEnv(t).newCollector(t, Config{Retention: time.Nanosecond, Mode: ModeArchive})
+		c.archiveStore = &errStore{Store: c.archiveStore, errPut: errExpected}
+
+		_, err := c.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Delete error", func(t *testing.T) {
+		c := newTestEnv(t).newCollector(t, Config{Retention: time.Nanosecond, Mode: ModeDelete})
+		c.CASClient = &mockCASClient{err: errExpected}
+
+		_, err := c.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+	})
+}
+
+func TestReachable(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		missing := env.writeAnchor(t, suffix2, "", "")
+		require.NoError(t, env.casStore.Delete(resourceHashFromURI(missing)))
+
+		live, err := Reachable(env.casStore, env.anchorStore, "")
+		require.NoError(t, err)
+		require.Len(t, live, len(env.live))
+
+		for _, resourceHash := range env.live {
+			require.Contains(t, live, resourceHash)
+		}
+
+		require.NotContains(t, live, resourceHashFromURI(missing))
+	})
+
+	t.Run("Anchor store error", func(t *testing.T) {
+		_, err := Reachable(newTestEnv(t).casStore, &mockAnchorStore{err: errors.New("injected error")}, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected error")
+	})
+}
+
+func TestCollector_StartStop(t *testing.T) {
+	t.Run("Periodic", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		c := env.newCollector(t, Config{Interval: 10 * time.Millisecond, Retention: time.Nanosecond, Mode: ModeDelete})
+
+		c.Start()
+		defer c.Stop()
+
+		require.Eventually(t, func() bool {
+			_, err := env.casStore.Get(env.garbage[0])
+
+			return errors.Is(err, storage.ErrDataNotFound)
+		}, time.Second, 10*time.Millisecond)
+
+		env.requireExists(t, env.live...)
+	})
+
+	t.Run("Periodic error", func(t *testing.T) {
+		c := newTestEnv(t).newCollector(t, Config{Interval: 10 * time.Millisecond})
+		c.AnchorStore = &mockAnchorStore{err: errors.New("injected error")}
+
+		c.Start()
+		time.Sleep(50 * time.Millisecond)
+		c.Stop()
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		c := newTestEnv(t).newCollector(t, Config{})
+
+		c.Start()
+		c.Stop()
+	})
+}
+
+func TestResourceHashFromURI(t *testing.T) {
+	require.Equal(t, "uEiAbc", resourceHashFromURI("hl:uEiAbc:uoQ-BeEJodHRwczovL2RvbWFpbi5jb20vY2FzL3VFaUFiYw"))
+	require.Equal(t, "uEiAbc", resourceHashFromURI("hl:uEiAbc"))
+	require.Equal(t, "QmAbc", resourceHashFromURI("ipfs://QmAbc"))
+	require.Equal(t, "uEiAbc", resourceHashFromURI("uEiAbc"))
+}
+
+// testEnv contains an anchor graph with two anchors for suffix1 (which are live) and an anchor for suffix2 which
+// is no longer referenced by the DID anchor store. The unreferenced anchor, its batch files and some unrelated
+// content are garbage.
+type testEnv struct {
+	provider    storage.Provider
+	casStore    storage.Store
+	cas         *casstore.CAS
+	anchorStore *didanchorstore.Store
+	compression *compression.Registry
+	live        []string
+	garbage     []string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	provider := mem.NewProvider()
+
+	cas, err := casstore.New(provider, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	casStore, err := provider.OpenStore(casstore.StoreName)
+	require.NoError(t, err)
+
+	anchorStore, err := didanchorstore.New(provider)
+	require.NoError(t, err)
+
+	env := &testEnv{
+		provider:    provider,
+		casStore:    casStore,
+		cas:         cas,
+		anchorStore: anchorStore,
+		compression: compression.New(compression.WithDefaultAlgorithms()),
+	}
+
+	coreIndex1, files := env.writeBatch(t, "batch1")
+	anchor1 := env.writeAnchor(t, suffix1, coreIndex1, "")
+
+	env.live = append(env.live, files...)
+	env.live = append(env.live, resourceHashFromURI(anchor1))
+
+	coreIndex2, files := env.writeBatch(t, "batch2")
+	anchor2 := env.writeAnchor(t, suffix1, coreIndex2, anchor1)
+
+	env.live = append(env.live, files...)
+	env.live = append(env.live, resourceHashFromURI(anchor2))
+
+	coreIndex3, files := env.writeBatch(t, "batch3")
+	anchor3 := env.writeAnchor(t, suffix2, coreIndex3, "")
+
+	env.garbage = append(env.garbage, files...)
+	env.garbage = append(env.garbage, resourceHashFromURI(anchor3))
+
+	orphan, err := cas.Write([]byte("orphan"))
+	require.NoError(t, err)
+
+	env.garbage = append(env.garbage, resourceHashFromURI(orphan))
+
+	// Replace the latest anchor for suffix2 with the latest anchor for suffix1 so that anchor3 is unreachable.
+	require.NoError(t, anchorStore.PutBulk([]string{suffix2}, anchor2))
+
+	return env
+}
+
+func (env *testEnv) newCollector(t *testing.T, cfg Config) *Collector {
+	t.Helper()
+
+	c, err := New(&Providers{StoreProvider: env.provider, AnchorStore: env.anchorStore, CASClient: env.cas}, cfg)
+	require.NoError(t, err)
+
+	return c
+}
+
+// writeBatch writes a core index file along with the files that it references and returns the hashlink of
+// the core index file and the resource hashes of all of the files.
+func (env *testEnv) writeBatch(t *testing.T, name string) (string, []string) {
+	t.Helper()
+
+	chunk := env.writeCompressed(t, []byte(name+"-chunk"))
+	provisionalProof := env.writeCompressed(t, []byte(name+"-provisional-proof"))
+	coreProof := env.writeCompressed(t, []byte(name+"-core-proof"))
+
+	provisionalIndex := env.writeJSON(t, models.CreateProvisionalIndexFile([]string{chunk}, provisionalProof, nil))
+	coreIndex := env.writeJSON(t, models.CreateCoreIndexFile(coreProof, provisionalIndex, &models.SortedOperations{}))
+
+	return coreIndex, []string{
+		resourceHashFromURI(chunk),
+		resourceHashFromURI(provisionalProof),
+		resourceHashFromURI(coreProof),
+		resourceHashFromURI(provisionalIndex),
+		resourceHashFromURI(coreIndex),
+	}
+}
+
+func (env *testEnv) writeAnchor(t *testing.T, suffix, coreIndex, previous string) string {
+	t.Helper()
+
+	act, err := activity.BuildActivityFromPayload(&subject.Payload{
+		Namespace:       "did:orb",
+		CoreIndex:       coreIndex,
+		PreviousAnchors: map[string]string{suffix: previous},
+	})
+	require.NoError(t, err)
+
+	anchorBytes, err := json.Marshal(map[string]interface{}{"credentialSubject": act})
+	require.NoError(t, err)
+
+	hl, err := env.cas.Write(anchorBytes)
+	require.NoError(t, err)
+
+	require.NoError(t, env.anchorStore.PutBulk([]string{suffix}, hl))
+
+	return hl
+}
+
+func (env *testEnv) writeJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	content, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return env.writeCompressed(t, content)
+}
+
+func (env *testEnv) writeCompressed(t *testing.T, content []byte) string {
+	t.Helper()
+
+	compressed, err := env.compression.Compress(defaultCompressionAlgorithm, content)
+	require.NoError(t, err)
+
+	hl, err := env.cas.Write(compressed)
+	require.NoError(t, err)
+
+	return hl
+}
+
+func (env *testEnv) requireExists(t *testing.T, resourceHashes ...string) {
+	t.Helper()
+
+	for _, resourceHash := range resourceHashes {
+		_, err := env.casStore.Get(resourceHash)
+		require.NoErrorf(t, err, "resource [%s] should exist", resourceHash)
+	}
+}
+
+func (env *testEnv) requireNotExists(t *testing.T, resourceHashes ...string) {
+	t.Helper()
+
+	for _, resourceHash := range resourceHashes {
+		_, err := env.casStore.Get(resourceHash)
+		require.Truef(t, errors.Is(err, storage.ErrDataNotFound), "resource [%s] should not exist", resourceHash)
+	}
+}
+
+type mockAnchorStore struct {
+	err error
+}
+
+func (m *mockAnchorStore) GetAllAnchors() ([]string, error) {
+	return nil, m.err
+}
+
+type mockCASClient struct {
+	err error
+}
+
+func (m *mockCASClient) Delete(...string) error {
+	return m.err
+}
+
+type mockIPFSClient struct {
+	unpinned  []string
+	errGetCID error
+	errUnpin  error
+}
+
+func (m *mockIPFSClient) GetCID(content []byte, _ ...extendedcasclient.CIDFormatOption) (string, error) {
+	if m.errGetCID != nil {
+		return "", m.errGetCID
+	}
+
+	resourceHash, err := hashlink.New().CreateResourceHash(content)
+	if err != nil {
+		return "", err
+	}
+
+	return "cid-" + resourceHash, nil
+}
+
+func (m *mockIPFSClient) Unpin(cid string) error {
+	if m.errUnpin != nil {
+		return m.errUnpin
+	}
+
+	m.unpinned = append(m.unpinned, cid)
+
+	return nil
+}
+
+type errStore struct {
+	storage.Store
+
+	iter     storage.Iterator
+	errQuery error
+	errGet   error
+	errPut   error
+	errBatch error
+}
+
+func (s *errStore) Query(expression string, options ...storage.QueryOption) (storage.Iterator, error) {
+	if s.errQuery != nil {
+		return nil, s.errQuery
+	}
+
+	if s.iter != nil {
+		return s.iter, nil
+	}
+
+	return s.Store.Query(expression, options...)
+}
+
+func (s *errStore) Get(key string) ([]byte, error) {
+	if s.errGet != nil {
+		return nil, s.errGet
+	}
+
+	return s.Store.Get(key)
+}
+
+func (s *errStore) Put(key string, value []byte, tags ...storage.Tag) error {
+	if s.errPut != nil {
+		return s.errPut
+	}
+
+	return s.Store.Put(key, value, tags...)
+}
+
+func (s *errStore) Batch(operations []storage.Operation) error {
+	if s.errBatch != nil {
+		return s.errBatch
+	}
+
+	return s.Store.Batch(operations)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	pinStoreName = "cas_pins"
+	pinTagName   = "pin"
+)
+
+// ErrInvalidPin is returned when a pinned resource is neither a hashlink nor a resource hash.
+var ErrInvalidPin = errors.New("invalid pin")
+
+// Pin exempts a CAS resource from garbage collection.
+type Pin struct {
+	ResourceHash string    `json:"resourceHash"`
+	Created      time.Time `json:"created"`
+}
+
+func openPinStore(provider storage.Provider) (storage.Store, error) {
+	store, err := provider.OpenStore(pinStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", pinStoreName, err)
+	}
+
+	err = provider.SetStoreConfig(pinStoreName, storage.StoreConfiguration{TagNames: []string{pinTagName}})
+	if err != nil {
+		return nil, fmt.Errorf("set store configuration for [%s]: %w", pinStoreName, err)
+	}
+
+	return store, nil
+}
+
+// GetPins returns the pinned resources, sorted by resource hash.
+func (c *Collector) GetPins() ([]*Pin, error) {
+	iter, err := c.pinStore.Query(pinTagName)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("query pins: %w", err))
+	}
+
+	defer func() {
+		if e := iter.Close(); e != nil {
+			logger.Warnf("Failed to close iterator: %s", e)
+		}
+	}()
+
+	pins := []*Pin{}
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("get next pin: %w", err))
+	}
+
+	for ok {
+		pinBytes, e := iter.Value()
+		if e != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("get pin value: %w", e))
+		}
+
+		pin := &Pin{}
+
+		if e := json.Unmarshal(pinBytes, pin); e != nil {
+			return nil, fmt.Errorf("unmarshal pin: %w", e)
+		}
+
+		pins = append(pins, pin)
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("get next pin: %w", err))
+		}
+	}
+
+	sort.Slice(pins, func(i, j int) bool {
+		return pins[i].ResourceHash < pins[j].ResourceHash
+	})
+
+	return pins, nil
+}
+
+// UpdatePins pins and unpins the given resources, each of which may be specified as a hashlink or a
+// resource hash. ErrInvalidPin is returned if any of the resources is invalid, in which case the
+// pins are not updated.
+func (c *Collector) UpdatePins(add, remove []string) error {
+	addHashes, err := toResourceHashes(add)
+	if err != nil {
+		return err
+	}
+
+	removeHashes, err := toResourceHashes(remove)
+	if err != nil {
+		return err
+	}
+
+	var operations []storage.Operation
+
+	for _, resourceHash := range removeHashes {
+		operations = append(operations, storage.Operation{Key: resourceHash})
+	}
+
+	for _, resourceHash := range addHashes {
+		pinBytes, e := json.Marshal(&Pin{ResourceHash: resourceHash, Created: time.Now()})
+		if e != nil {
+			return fmt.Errorf("marshal pin: %w", e)
+		}
+
+		operations = append(operations, storage.Operation{
+			Key:   resourceHash,
+			Value: pinBytes,
+			Tags:  []storage.Tag{{Name: pinTagName}},
+		})
+	}
+
+	if len(operations) == 0 {
+		return nil
+	}
+
+	err = c.pinStore.Batch(operations)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store pins: %w", err))
+	}
+
+	logger.Infof("Updated CAS pins - Added: %s, Removed: %s", addHashes, removeHashes)
+
+	return nil
+}
+
+func (c *Collector) getPinnedResources() (map[string]struct{}, error) {
+	pins, err := c.GetPins()
+	if err != nil {
+		return nil, fmt.Errorf("get pins: %w", err)
+	}
+
+	pinned := make(map[string]struct{}, len(pins))
+
+	for _, pin := range pins {
+		pinned[pin.ResourceHash] = struct{}{}
+	}
+
+	return pinned, nil
+}
+
+func toResourceHashes(resources []string) ([]string, error) {
+	resourceHashes := make([]string, len(resources))
+
+	for i, resource := range resources {
+		resourceHash := resourceHashFromURI(strings.TrimSpace(resource))
+
+		if resourceHash == "" || strings.ContainsAny(resourceHash, ":/") {
+			return nil, fmt.Errorf("%w: [%s] is neither a hashlink nor a resource hash", ErrInvalidPin, resource)
+		}
+
+		resourceHashes[i] = resourceHash
+	}
+
+	return resourceHashes, nil
+}
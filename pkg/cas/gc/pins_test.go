@@ -0,0 +1,130 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/store/mocks"
+)
+
+const (
+	resourceHash1 = "uEiAPnWB5kdFlN1rsDCy2WEm8hXrPjbNnDt2lZjcsBtpqjA"
+	resourceHash2 = "uEiBhv2sjMQKj5K6dvVZ3dzPZrHgUVNKSYCifU6aHEHtiuQ"
+	resourceHash3 = "uEiCNmfpLqcKH2Ow9mc8FB2vvC5F8dlh7hZ4NcqYbpzylrA"
+)
+
+func TestCollector_Pins(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		c, err := New(&Providers{StoreProvider: mem.NewProvider()}, Config{})
+		require.NoError(t, err)
+
+		pins, err := c.GetPins()
+		require.NoError(t, err)
+		require.Empty(t, pins)
+
+		require.NoError(t, c.UpdatePins(nil, nil))
+
+		require.NoError(t, c.UpdatePins(
+			[]string{
+				"hl:" + resourceHash2 + ":uoQ-BeEJodHRwczovL2RvbWFpbi5jb20vY2Fz",
+				resourceHash1,
+				"ipfs://" + resourceHash3,
+			}, nil,
+		))
+
+		pins, err = c.GetPins()
+		require.NoError(t, err)
+		require.Len(t, pins, 3)
+		require.Equal(t, resourceHash1, pins[0].ResourceHash)
+		require.Equal(t, resourceHash2, pins[1].ResourceHash)
+		require.Equal(t, resourceHash3, pins[2].ResourceHash)
+		require.False(t, pins[0].Created.IsZero())
+
+		require.NoError(t, c.UpdatePins(nil, []string{resourceHash1, "hl:" + resourceHash3}))
+
+		pins, err = c.GetPins()
+		require.NoError(t, err)
+		require.Len(t, pins, 1)
+		require.Equal(t, resourceHash2, pins[0].ResourceHash)
+	})
+
+	t.Run("Invalid pin", func(t *testing.T) {
+		c, err := New(&Providers{StoreProvider: mem.NewProvider()}, Config{})
+		require.NoError(t, err)
+
+		for _, pin := range []string{"", "hl:", "https://domain.com/cas/" + resourceHash1} {
+			err = c.UpdatePins([]string{pin}, nil)
+			require.Truef(t, errors.Is(err, ErrInvalidPin), "expecting error for pin [%s]", pin)
+
+			err = c.UpdatePins(nil, []string{pin})
+			require.Truef(t, errors.Is(err, ErrInvalidPin), "expecting error for pin [%s]", pin)
+		}
+	})
+
+	t.Run("Store errors", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+
+		c, err := New(&Providers{StoreProvider: mem.NewProvider()}, Config{})
+		require.NoError(t, err)
+
+		c.pinStore = &errStore{Store: c.pinStore, errBatch: errExpected}
+
+		err = c.UpdatePins([]string{resourceHash1}, nil)
+		require.True(t, errors.Is(err, errExpected))
+		require.True(t, orberrors.IsTransient(err))
+
+		c.pinStore = &errStore{Store: c.pinStore, errQuery: errExpected}
+
+		_, err = c.GetPins()
+		require.True(t, errors.Is(err, errExpected))
+		require.True(t, orberrors.IsTransient(err))
+
+		iter := &mocks.Iterator{}
+		iter.NextReturns(false, errExpected)
+
+		c.pinStore = &errStore{Store: c.pinStore, iter: iter}
+
+		_, err = c.GetPins()
+		require.True(t, errors.Is(err, errExpected))
+
+		iter = &mocks.Iterator{}
+		iter.NextReturns(true, nil)
+		iter.ValueReturns(nil, errExpected)
+
+		c.pinStore = &errStore{Store: c.pinStore, iter: iter}
+
+		_, err = c.GetPins()
+		require.True(t, errors.Is(err, errExpected))
+
+		iter = &mocks.Iterator{}
+		iter.NextReturns(true, nil)
+		iter.ValueReturns([]byte("{"), nil)
+		iter.CloseReturns(errors.New("injected close error"))
+
+		c.pinStore = &errStore{Store: c.pinStore, iter: iter}
+
+		_, err = c.GetPins()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal pin")
+
+		iter = &mocks.Iterator{}
+		iter.NextReturnsOnCall(0, true, nil)
+		iter.NextReturnsOnCall(1, false, errExpected)
+		iter.ValueReturns([]byte("{}"), nil)
+
+		c.pinStore = &errStore{Store: c.pinStore, iter: iter}
+
+		_, err = c.GetPins()
+		require.True(t, errors.Is(err, errExpected))
+	})
+}
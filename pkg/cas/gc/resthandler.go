@@ -0,0 +1,260 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+)
+
+const (
+	collectEndpoint = "/casgc"
+	pinsEndpoint    = "/casgc/pins"
+
+	dryRunParam = "dryrun"
+)
+
+const (
+	badRequestResponse          = "Bad Request."
+	conflictResponse            = "Conflict."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+type collector interface {
+	Collect(dryRun bool) (*Report, error)
+}
+
+type pinManager interface {
+	GetPins() ([]*Pin, error)
+	UpdatePins(add, remove []string) error
+}
+
+// UpdateRequest is the request body of the pins update endpoint.
+type UpdateRequest struct {
+	// Add contains the hashlinks or resource hashes of the resources to pin.
+	Add []string `json:"add,omitempty"`
+	// Remove contains the hashlinks or resource hashes of the resources to unpin.
+	Remove []string `json:"remove,omitempty"`
+}
+
+// Runner runs garbage collection on request and returns the report. If the "dryrun" query parameter is
+// true then the unreachable content is only reported, regardless of the configured mode.
+type Runner struct {
+	collector collector
+	marshal   func(v interface{}) ([]byte, error)
+}
+
+// NewRunner returns a new garbage collection Runner.
+func NewRunner(c collector) *Runner {
+	return &Runner{
+		collector: c,
+		marshal:   json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the Runner service.
+func (r *Runner) Path() string {
+	return collectEndpoint
+}
+
+// Method returns the HTTP REST method for the Runner service.
+func (r *Runner) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the Runner service.
+func (r *Runner) Handler() common.HTTPRequestHandler {
+	return r.handle
+}
+
+func (r *Runner) handle(w http.ResponseWriter, req *http.Request) {
+	dryRun := false
+
+	if value := req.URL.Query().Get(dryRunParam); value != "" {
+		var err error
+
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			logger.Infof("[%s] Invalid value for parameter [%s]: %s", collectEndpoint, dryRunParam, err)
+
+			writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+			return
+		}
+	}
+
+	logger.Infof("[%s] CAS garbage collection requested by [%s] - Dry run: %t",
+		collectEndpoint, auth.TokenIDFromContext(req.Context()), dryRun)
+
+	report, err := r.collector.Collect(dryRun)
+	if err != nil {
+		if errors.Is(err, ErrInProgress) {
+			writeResponse(w, http.StatusConflict, []byte(fmt.Sprintf("%s %s", conflictResponse, err)))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error collecting CAS garbage: %s", collectEndpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeJSONResponse(w, collectEndpoint, report, r.marshal)
+}
+
+// PinsRetriever returns the pinned CAS resources.
+type PinsRetriever struct {
+	manager pinManager
+	marshal func(v interface{}) ([]byte, error)
+}
+
+// NewPinsRetriever returns a new PinsRetriever.
+func NewPinsRetriever(m pinManager) *PinsRetriever {
+	return &PinsRetriever{
+		manager: m,
+		marshal: json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the PinsRetriever service.
+func (r *PinsRetriever) Path() string {
+	return pinsEndpoint
+}
+
+// Method returns the HTTP REST method for the PinsRetriever service.
+func (r *PinsRetriever) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the PinsRetriever service.
+func (r *PinsRetriever) Handler() common.HTTPRequestHandler {
+	return r.handle
+}
+
+func (r *PinsRetriever) handle(w http.ResponseWriter, _ *http.Request) {
+	pins, err := r.manager.GetPins()
+	if err != nil {
+		logger.Errorf("[%s] Error retrieving pins: %s", pinsEndpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeJSONResponse(w, pinsEndpoint, pins, r.marshal)
+}
+
+// PinsUpdater pins and unpins CAS resources.
+type PinsUpdater struct {
+	manager pinManager
+}
+
+// NewPinsUpdater returns a new PinsUpdater.
+func NewPinsUpdater(m pinManager) *PinsUpdater {
+	return &PinsUpdater{
+		manager: m,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the PinsUpdater service.
+func (u *PinsUpdater) Path() string {
+	return pinsEndpoint
+}
+
+// Method returns the HTTP REST method for the PinsUpdater service.
+func (u *PinsUpdater) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the PinsUpdater service.
+func (u *PinsUpdater) Handler() common.HTTPRequestHandler {
+	return u.handle
+}
+
+func (u *PinsUpdater) handle(w http.ResponseWriter, req *http.Request) {
+	reqBytes, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		logger.Errorf("[%s] Error reading request body: %s", pinsEndpoint, err)
+
+		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	request := &UpdateRequest{}
+
+	err = json.Unmarshal(reqBytes, request)
+	if err != nil {
+		logger.Infof("[%s] Invalid request: %s", pinsEndpoint, err)
+
+		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	err = u.manager.UpdatePins(request.Add, request.Remove)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPin) {
+			logger.Infof("[%s] Invalid pin: %s", pinsEndpoint, err)
+
+			writeResponse(w, http.StatusBadRequest, []byte(fmt.Sprintf("%s %s", badRequestResponse, err)))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error updating pins: %s", pinsEndpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	logger.Infof("[%s] CAS pins updated on behalf of [%s] - Added: %s, Removed: %s",
+		pinsEndpoint, auth.TokenIDFromContext(req.Context()), request.Add, request.Remove)
+
+	writeResponse(w, http.StatusOK, nil)
+}
+
+func writeJSONResponse(w http.ResponseWriter, endpoint string, v interface{},
+	marshal func(v interface{}) ([]byte, error)) {
+	respBytes, err := marshal(v)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling response: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, respBytes)
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("Unable to write response: %s", err)
+
+			return
+		}
+
+		logger.Debugf("Wrote response: %s", body)
+	}
+}
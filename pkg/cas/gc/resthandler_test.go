@@ -0,0 +1,194 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner(t *testing.T) {
+	env := newTestEnv(t)
+
+	c := env.newCollector(t, Config{Retention: time.Nanosecond, Mode: ModeDelete})
+
+	r := NewRunner(c)
+	require.Equal(t, "/casgc", r.Path())
+	require.Equal(t, http.MethodPost, r.Method())
+	require.NotNil(t, r.Handler())
+
+	t.Run("Dry run", func(t *testing.T) {
+		status, body := handle(t, r.handle, http.MethodPost, "/casgc?dryrun=true", nil)
+		require.Equal(t, http.StatusOK, status)
+
+		report := &Report{}
+		require.NoError(t, json.Unmarshal(body, report))
+		require.Equal(t, ModeDryRun, report.Mode)
+		require.ElementsMatch(t, env.garbage, report.Collected)
+
+		env.requireExists(t, env.garbage...)
+	})
+
+	t.Run("Configured mode", func(t *testing.T) {
+		status, body := handle(t, r.handle, http.MethodPost, "/casgc", nil)
+		require.Equal(t, http.StatusOK, status)
+
+		report := &Report{}
+		require.NoError(t, json.Unmarshal(body, report))
+		require.Equal(t, ModeDelete, report.Mode)
+		require.ElementsMatch(t, env.garbage, report.Collected)
+
+		env.requireNotExists(t, env.garbage...)
+	})
+
+	t.Run("Invalid dry run parameter", func(t *testing.T) {
+		status, _ := handle(t, r.handle, http.MethodPost, "/casgc?dryrun=xxx", nil)
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("In progress", func(t *testing.T) {
+		status, body := handle(t, NewRunner(&mockCollector{err: ErrInProgress}).handle,
+			http.MethodPost, "/casgc", nil)
+		require.Equal(t, http.StatusConflict, status)
+		require.Contains(t, string(body), ErrInProgress.Error())
+	})
+
+	t.Run("Collect error", func(t *testing.T) {
+		status, _ := handle(t, NewRunner(&mockCollector{err: errors.New("injected error")}).handle,
+			http.MethodPost, "/casgc", nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		r2 := NewRunner(&mockCollector{})
+		r2.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		status, _ := handle(t, r2.handle, http.MethodPost, "/casgc", nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func TestPinsRetriever(t *testing.T) {
+	c := newTestEnv(t).newCollector(t, Config{})
+
+	require.NoError(t, c.UpdatePins([]string{resourceHash1}, nil))
+
+	r := NewPinsRetriever(c)
+	require.Equal(t, "/casgc/pins", r.Path())
+	require.Equal(t, http.MethodGet, r.Method())
+	require.NotNil(t, r.Handler())
+
+	t.Run("Success", func(t *testing.T) {
+		status, body := handle(t, r.handle, http.MethodGet, pinsEndpoint, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		var pins []*Pin
+		require.NoError(t, json.Unmarshal(body, &pins))
+		require.Len(t, pins, 1)
+		require.Equal(t, resourceHash1, pins[0].ResourceHash)
+	})
+
+	t.Run("Get error", func(t *testing.T) {
+		status, _ := handle(t, NewPinsRetriever(&mockPinManager{err: errors.New("injected error")}).handle,
+			http.MethodGet, pinsEndpoint, nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func TestPinsUpdater(t *testing.T) {
+	c := newTestEnv(t).newCollector(t, Config{})
+
+	u := NewPinsUpdater(c)
+	require.Equal(t, "/casgc/pins", u.Path())
+	require.Equal(t, http.MethodPost, u.Method())
+	require.NotNil(t, u.Handler())
+
+	t.Run("Success", func(t *testing.T) {
+		reqBytes, err := json.Marshal(&UpdateRequest{Add: []string{resourceHash1, resourceHash2}})
+		require.NoError(t, err)
+
+		status, _ := handle(t, u.handle, http.MethodPost, pinsEndpoint, reqBytes)
+		require.Equal(t, http.StatusOK, status)
+
+		reqBytes, err = json.Marshal(&UpdateRequest{Remove: []string{resourceHash1}})
+		require.NoError(t, err)
+
+		status, _ = handle(t, u.handle, http.MethodPost, pinsEndpoint, reqBytes)
+		require.Equal(t, http.StatusOK, status)
+
+		pins, err := c.GetPins()
+		require.NoError(t, err)
+		require.Len(t, pins, 1)
+		require.Equal(t, resourceHash2, pins[0].ResourceHash)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		status, _ := handle(t, u.handle, http.MethodPost, pinsEndpoint, []byte("{"))
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("Invalid pin", func(t *testing.T) {
+		status, body := handle(t, u.handle, http.MethodPost, pinsEndpoint, []byte(`{"add":["hl:"]}`))
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, string(body), ErrInvalidPin.Error())
+	})
+
+	t.Run("Update error", func(t *testing.T) {
+		status, _ := handle(t, NewPinsUpdater(&mockPinManager{err: errors.New("injected error")}).handle,
+			http.MethodPost, pinsEndpoint, []byte(`{}`))
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func handle(t *testing.T, h func(w http.ResponseWriter, req *http.Request), method, target string,
+	body []byte) (int, []byte) {
+	t.Helper()
+
+	rw := httptest.NewRecorder()
+
+	h(rw, httptest.NewRequest(method, target, bytes.NewReader(body)))
+
+	result := rw.Result()
+
+	respBytes, err := ioutil.ReadAll(result.Body)
+	require.NoError(t, err)
+	require.NoError(t, result.Body.Close())
+
+	return result.StatusCode, respBytes
+}
+
+type mockCollector struct {
+	err error
+}
+
+func (m *mockCollector) Collect(bool) (*Report, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &Report{}, nil
+}
+
+type mockPinManager struct {
+	err error
+}
+
+func (m *mockPinManager) GetPins() ([]*Pin, error) {
+	return nil, m.err
+}
+
+func (m *mockPinManager) UpdatePins([]string, []string) error {
+	return m.err
+}
@@ -0,0 +1,161 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bluele/gcache"
+
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+const (
+	gatewayType = "ipfs-gateway"
+
+	// defaultMaxGatewayContentSize is the default maximum size (in bytes) of the content that's read from
+	// the gateway.
+	defaultMaxGatewayContentSize = 10 * 1024 * 1024
+)
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// GatewayReader reads content from IPFS through a (public or private) HTTP gateway, e.g. https://ipfs.io. It's
+// read-only and may be used when no IPFS node with an HTTP API is available.
+type GatewayReader struct {
+	gatewayURL     string
+	httpClient     httpClient
+	timeout        time.Duration
+	maxContentSize int64
+	opts           []extendedcasclient.CIDFormatOption
+	hl             *hashlink.HashLink
+	cache          gcache.Cache
+	metrics        metricsProvider
+}
+
+// NewGatewayReader returns a new IPFS gateway reader. Content is retrieved from <gatewayURL>/ipfs/<cid>.
+// Content that's larger than maxContentSize bytes is rejected (if zero then a default of 10MB is used). Since the
+// gateway is typically a third-party service, the given HTTP client should verify the gateway's TLS certificate.
+// If no CID version is specified, then v1 will be used by default when converting a hash to a CID.
+func NewGatewayReader(gatewayURL string, client httpClient, timeout time.Duration, cacheSize int,
+	maxContentSize int64, metrics metricsProvider, opts ...extendedcasclient.CIDFormatOption) *GatewayReader {
+	if cacheSize == 0 {
+		cacheSize = defaultCacheSize
+	}
+
+	if maxContentSize == 0 {
+		maxContentSize = defaultMaxGatewayContentSize
+	}
+
+	r := &GatewayReader{
+		gatewayURL:     strings.TrimSuffix(gatewayURL, "/"),
+		httpClient:     client,
+		timeout:        timeout,
+		maxContentSize: maxContentSize,
+		opts:           opts,
+		hl:             hashlink.New(),
+		metrics:        metrics,
+	}
+
+	r.cache = gcache.New(cacheSize).LoaderFunc(func(key interface{}) (interface{}, error) {
+		content, err := r.get(key.(string))
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Debugf("Cached content for CID [%s]", key)
+
+		return content, nil
+	}).Build()
+
+	return r
+}
+
+// Read reads the content for the given CID (or hash, which is converted to a CID) from the IPFS gateway.
+func (r *GatewayReader) Read(cidOrHash string) ([]byte, error) {
+	cid, err := getCID(r.hl, cidOrHash, r.opts)
+	if err != nil {
+		return nil, fmt.Errorf("value[%s] passed to ipfs gateway reader is not CID and cannot be converted to CID: %w",
+			cidOrHash, err)
+	}
+
+	if r.cache.Has(cid) {
+		r.metrics.CASIncrementCacheHitCount()
+	}
+
+	content, err := r.cache.Get(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	return content.([]byte), nil
+}
+
+func (r *GatewayReader) get(cid string) ([]byte, error) {
+	startTime := time.Now()
+
+	defer func() {
+		r.metrics.CASReadTime(gatewayType, time.Since(startTime))
+	}()
+
+	ctx, cancel := r.newContext()
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.gatewayURL+"/ipfs/"+cid, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get cid[%s] from ipfs gateway: %w", cid, err))
+	}
+
+	defer closeAndLog(resp.Body)
+
+	// Read one byte more than the maximum so that content that exceeds the maximum size may be detected.
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, r.maxContentSize+1))
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to read response from ipfs gateway: %w", err))
+	}
+
+	if int64(len(content)) > r.maxContentSize {
+		return nil, fmt.Errorf("content for cid[%s] from ipfs gateway exceeds the maximum size of %d bytes",
+			cid, r.maxContentSize)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return content, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, orberrors.ErrContentNotFound
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, orberrors.NewTransient(fmt.Errorf("ipfs gateway returned status code %d for cid[%s]: %s",
+			resp.StatusCode, cid, content))
+	default:
+		return nil, fmt.Errorf("ipfs gateway returned status code %d for cid[%s]: %s",
+			resp.StatusCode, cid, content)
+	}
+}
+
+func (r *GatewayReader) newContext() (context.Context, context.CancelFunc) {
+	if r.timeout == 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithTimeout(context.Background(), r.timeout)
+}
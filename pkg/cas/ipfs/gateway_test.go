@@ -0,0 +1,136 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ipfs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+)
+
+func TestGatewayReader_Read(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var requests int32
+
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+
+			require.Equal(t, "/ipfs/"+testCID, r.URL.Path)
+
+			fmt.Fprint(w, "content")
+		}))
+		defer gateway.Close()
+
+		r := NewGatewayReader(gateway.URL+"/", http.DefaultClient, 5*time.Second, 0, 0, &orbmocks.MetricsProvider{})
+
+		content, err := r.Read(testCID)
+		require.NoError(t, err)
+		require.Equal(t, "content", string(content))
+
+		// The hash of the content is converted to a CID and the content is read from the cache.
+		content, err = r.Read("uEiDtcAK0OemshF8iNX2CK6wURHMPvbYBbT7JQyKXueyfcw")
+		require.NoError(t, err)
+		require.Equal(t, "content", string(content))
+		require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer gateway.Close()
+
+		r := NewGatewayReader(gateway.URL, http.DefaultClient, 0, 0, 0, &orbmocks.MetricsProvider{})
+
+		content, err := r.Read(testCID)
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+		require.Nil(t, content)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer gateway.Close()
+
+		r := NewGatewayReader(gateway.URL, http.DefaultClient, time.Second, 0, 0, &orbmocks.MetricsProvider{})
+
+		content, err := r.Read(testCID)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "ipfs gateway returned status code 502")
+		require.Nil(t, content)
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer gateway.Close()
+
+		r := NewGatewayReader(gateway.URL, http.DefaultClient, time.Second, 0, 0, &orbmocks.MetricsProvider{})
+
+		content, err := r.Read(testCID)
+		require.Error(t, err)
+		require.False(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "ipfs gateway returned status code 400")
+		require.Nil(t, content)
+	})
+
+	t.Run("content too large", func(t *testing.T) {
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, err := w.Write([]byte("0123456789"))
+			require.NoError(t, err)
+		}))
+		defer gateway.Close()
+
+		r := NewGatewayReader(gateway.URL, http.DefaultClient, time.Second, 0, 9, &orbmocks.MetricsProvider{})
+
+		content, err := r.Read(testCID)
+		require.Error(t, err)
+		require.False(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "exceeds the maximum size of 9 bytes")
+		require.Nil(t, content)
+
+		r = NewGatewayReader(gateway.URL, http.DefaultClient, time.Second, 0, 10, &orbmocks.MetricsProvider{})
+
+		content, err = r.Read(testCID)
+		require.NoError(t, err)
+		require.Equal(t, []byte("0123456789"), content)
+	})
+
+	t.Run("HTTP client error", func(t *testing.T) {
+		r := NewGatewayReader("http://localhost:1", http.DefaultClient, time.Second, 0, 0,
+			&orbmocks.MetricsProvider{})
+
+		content, err := r.Read(testCID)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "failed to get cid")
+		require.Nil(t, content)
+	})
+
+	t.Run("invalid CID", func(t *testing.T) {
+		r := NewGatewayReader("http://localhost:1", http.DefaultClient, time.Second, 0, 0,
+			&orbmocks.MetricsProvider{}, extendedcasclient.WithCIDVersion(2))
+
+		content, err := r.Read("hash")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "passed to ipfs gateway reader is not CID")
+		require.Nil(t, content)
+	})
+}
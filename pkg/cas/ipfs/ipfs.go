@@ -8,6 +8,7 @@ package ipfs
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -36,6 +37,21 @@ type metricsProvider interface {
 	CASReadTime(casType string, value time.Duration)
 }
 
+// Config holds the configuration of the IPFS client.
+type Config struct {
+	// URL is the URL of the IPFS node's HTTP API.
+	URL string
+	// Timeout is the timeout for requests to the IPFS node.
+	Timeout time.Duration
+	// Pin indicates that content should be explicitly pinned after it's added and that the pin should be verified
+	// before the write is reported as successful. (The content is otherwise only retained for as long as the node's
+	// default pinning policy allows.)
+	Pin bool
+	// MFSDir is an optional directory in the node's Mutable File System (MFS) into which written content is copied
+	// (using the CID as the file name) so that it may be browsed. The directory is created if it doesn't exist.
+	MFSDir string
+}
+
 // Client will write new documents to IPFS and read existing documents from IPFS based on CID.
 // It implements Sidetree CAS interface.
 type Client struct {
@@ -44,21 +60,37 @@ type Client struct {
 	hl      *hashlink.HashLink
 	cache   gcache.Cache
 	metrics metricsProvider
+	pin     bool
+	mfsDir  string
 }
 
 // New creates cas client.
 // If no CID version is specified, then v1 will be used by default.
 func New(url string, timeout time.Duration, cacheSize int, metrics metricsProvider,
 	opts ...extendedcasclient.CIDFormatOption) *Client {
-	ipfs := shell.NewShell(url)
+	return NewWithConfig(Config{URL: url, Timeout: timeout}, cacheSize, metrics, opts...)
+}
 
-	ipfs.SetTimeout(timeout)
+// NewWithConfig creates cas client using the given configuration.
+// If no CID version is specified, then v1 will be used by default.
+func NewWithConfig(cfg Config, cacheSize int, metrics metricsProvider,
+	opts ...extendedcasclient.CIDFormatOption) *Client {
+	ipfs := shell.NewShell(cfg.URL)
+
+	ipfs.SetTimeout(cfg.Timeout)
 
 	if cacheSize == 0 {
 		cacheSize = defaultCacheSize
 	}
 
-	c := &Client{ipfs: ipfs, opts: opts, hl: hashlink.New(), metrics: metrics}
+	c := &Client{
+		ipfs:    ipfs,
+		opts:    opts,
+		hl:      hashlink.New(),
+		metrics: metrics,
+		pin:     cfg.Pin,
+		mfsDir:  strings.TrimSuffix(cfg.MFSDir, "/"),
+	}
 
 	c.cache = gcache.New(cacheSize).LoaderFunc(func(key interface{}) (interface{}, error) {
 		cid, err := c.get(key.(string))
@@ -118,9 +150,131 @@ func (m *Client) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CI
 
 	logger.Debugf("ipfs added content returned cid: %s", cid)
 
+	if m.pin {
+		if err = m.pinAndVerify(cid); err != nil {
+			return "", err
+		}
+	}
+
+	if m.mfsDir != "" {
+		if err = m.copyToMFS(cid); err != nil {
+			return "", err
+		}
+	}
+
+	return cid, nil
+}
+
+// GetCID returns the CID that the given content would have if it were written to IPFS using the provided CID format
+// options. The content is not stored by the IPFS node.
+func (m *Client) GetCID(content []byte, opts ...extendedcasclient.CIDFormatOption) (string, error) {
+	options, err := getOptions(opts)
+	if err != nil {
+		return "", err
+	}
+
+	addOpts := []shell.AddOpts{shell.OnlyHash(true)}
+
+	if options.CIDVersion == 1 {
+		addOpts = append(addOpts, shell.CidVersion(1))
+	}
+
+	cid, err := m.ipfs.Add(bytes.NewReader(content), addOpts...)
+	if err != nil {
+		return "", orberrors.NewTransient(fmt.Errorf("failed to compute cid: %w", err))
+	}
+
 	return cid, nil
 }
 
+// Pin pins the content with the given CID (recursively) so that it's retained by the IPFS node.
+func (m *Client) Pin(cid string) error {
+	if err := m.ipfs.Pin(cid); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to pin cid[%s]: %w", cid, err))
+	}
+
+	logger.Debugf("Pinned cid[%s]", cid)
+
+	return nil
+}
+
+// Unpin removes the (recursive) pin of the content with the given CID so that it may be garbage collected by
+// the IPFS node.
+func (m *Client) Unpin(cid string) error {
+	if err := m.ipfs.Unpin(cid); err != nil {
+		if isNotPinnedError(err) {
+			logger.Debugf("Cid[%s] was not pinned", cid)
+
+			return nil
+		}
+
+		return orberrors.NewTransient(fmt.Errorf("failed to unpin cid[%s]: %w", cid, err))
+	}
+
+	logger.Debugf("Unpinned cid[%s]", cid)
+
+	return nil
+}
+
+// IsPinned returns true if the content with the given CID is pinned (either directly, recursively, or indirectly
+// as part of another pinned object) by the IPFS node.
+func (m *Client) IsPinned(cid string) (bool, error) {
+	var pins struct{ Keys map[string]shell.PinInfo }
+
+	err := m.ipfs.Request("pin/ls", cid).Exec(context.Background(), &pins)
+	if err != nil {
+		if isNotPinnedError(err) {
+			return false, nil
+		}
+
+		return false, orberrors.NewTransient(fmt.Errorf("failed to get pin status of cid[%s]: %w", cid, err))
+	}
+
+	return len(pins.Keys) > 0, nil
+}
+
+func (m *Client) pinAndVerify(cid string) error {
+	if err := m.Pin(cid); err != nil {
+		return err
+	}
+
+	pinned, err := m.IsPinned(cid)
+	if err != nil {
+		return err
+	}
+
+	if !pinned {
+		return orberrors.NewTransient(fmt.Errorf("cid[%s] is not pinned after it was added", cid))
+	}
+
+	return nil
+}
+
+// copyToMFS copies the content with the given CID into the MFS directory. The content is not copied again if a
+// file with the same name already exists in the directory.
+func (m *Client) copyToMFS(cid string) error {
+	ctx := context.Background()
+	path := m.mfsDir + "/" + cid
+
+	if _, err := m.ipfs.FilesStat(ctx, path); err == nil {
+		logger.Debugf("Cid[%s] already exists in MFS at [%s]", cid, path)
+
+		return nil
+	}
+
+	if err := m.ipfs.FilesMkdir(ctx, m.mfsDir, shell.FilesMkdir.Parents(true)); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to create MFS directory [%s]: %w", m.mfsDir, err))
+	}
+
+	if err := m.ipfs.FilesCp(ctx, "/ipfs/"+cid, path); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to copy cid[%s] to MFS [%s]: %w", cid, path, err))
+	}
+
+	logger.Debugf("Copied cid[%s] to MFS at [%s]", cid, path)
+
+	return nil
+}
+
 // GetPrimaryWriterType returns primary writer type.
 func (m *Client) GetPrimaryWriterType() string {
 	return "ipfs"
@@ -131,7 +285,7 @@ func (m *Client) GetPrimaryWriterType() string {
 func (m *Client) Read(cidOrHash string) ([]byte, error) {
 	logger.Debugf("read cid or hash from ipfs: %s", cidOrHash)
 
-	cid, err := m.getCID(cidOrHash)
+	cid, err := getCID(m.hl, cidOrHash, m.opts)
 	if err != nil {
 		return nil, fmt.Errorf("value[%s] passed to ipfs reader is not CID and cannot be converted to CID: %w", cidOrHash, err) //nolint:lll
 	}
@@ -169,11 +323,11 @@ func (m *Client) get(cid string) ([]byte, error) {
 	return ioutil.ReadAll(reader)
 }
 
-func (m *Client) getCID(cidOrHash string) (string, error) {
+func getCID(hl *hashlink.HashLink, cidOrHash string, opts []extendedcasclient.CIDFormatOption) (string, error) {
 	cid := cidOrHash
 
 	if strings.HasPrefix(cidOrHash, hashlink.HLPrefix) {
-		hashlinkInfo, err := m.hl.ParseHashLink(cidOrHash)
+		hashlinkInfo, err := hl.ParseHashLink(cidOrHash)
 		if err != nil {
 			return "", fmt.Errorf("failed to parse hash link in ipfs client: %w", err)
 		}
@@ -184,7 +338,7 @@ func (m *Client) getCID(cidOrHash string) (string, error) {
 	if !multihash.IsValidCID(cid) {
 		var err error
 
-		cid, err = m.getCIDFromHash(cid)
+		cid, err = getCIDFromHash(cid, opts)
 		if err != nil {
 			return "", fmt.Errorf("failed to get cid in ipfs reader: %w", err)
 		}
@@ -195,8 +349,8 @@ func (m *Client) getCID(cidOrHash string) (string, error) {
 	return cid, nil
 }
 
-func (m *Client) getCIDFromHash(hash string) (string, error) {
-	options, err := getOptions(m.opts)
+func getCIDFromHash(hash string, opts []extendedcasclient.CIDFormatOption) (string, error) {
+	options, err := getOptions(opts)
 	if err != nil {
 		return "", err
 	}
@@ -239,6 +393,10 @@ func getOptions(opts []extendedcasclient.CIDFormatOption) (
 	return options, nil
 }
 
+func isNotPinnedError(err error) bool {
+	return strings.Contains(err.Error(), "not pinned")
+}
+
 func closeAndLog(rc io.Closer) {
 	if err := rc.Close(); err != nil {
 		logger.Warnf("failed to close reader: %s", err.Error())
@@ -7,9 +7,11 @@ SPDX-License-Identifier: Apache-2.0
 package ipfs
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -19,9 +21,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 	orbmocks "github.com/trustbloc/orb/pkg/mocks"
 )
 
+const testCID = "bafkreihnoabliopjvscf6irvpwbcxlauirzq7pnwafwt5skdekl3t3e7om"
+
 func TestNew(t *testing.T) {
 	c := New("ipfs:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{})
 	require.NotNil(t, c)
@@ -203,6 +208,297 @@ func TestRead(t *testing.T) {
 	})
 }
 
+func TestPin(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		node := newMockNode()
+
+		server := httptest.NewServer(node)
+		defer server.Close()
+
+		cas := New(server.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+
+		pinned, err := cas.IsPinned(testCID)
+		require.NoError(t, err)
+		require.False(t, pinned)
+
+		require.NoError(t, cas.Pin(testCID))
+
+		pinned, err = cas.IsPinned(testCID)
+		require.NoError(t, err)
+		require.True(t, pinned)
+
+		require.NoError(t, cas.Unpin(testCID))
+
+		pinned, err = cas.IsPinned(testCID)
+		require.NoError(t, err)
+		require.False(t, pinned)
+
+		// Unpinning content that isn't pinned is not an error.
+		require.NoError(t, cas.Unpin(testCID))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			writeIPFSError(w, "internal error")
+		}))
+		defer server.Close()
+
+		cas := New(server.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+
+		err := cas.Pin(testCID)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "failed to pin cid")
+
+		err = cas.Unpin(testCID)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "failed to unpin cid")
+
+		_, err = cas.IsPinned(testCID)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "failed to get pin status of cid")
+	})
+}
+
+func TestGetCID(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "true", r.URL.Query().Get("only-hash"))
+
+			fmt.Fprintf(w, `{"Hash":"%s"}`, testCID)
+		}))
+		defer server.Close()
+
+		cas := New(server.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+
+		cid, err := cas.GetCID([]byte("content"))
+		require.NoError(t, err)
+		require.Equal(t, testCID, cid)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			writeIPFSError(w, "internal error")
+		}))
+		defer server.Close()
+
+		cas := New(server.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+
+		cid, err := cas.GetCID([]byte("content"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "failed to compute cid")
+		require.Empty(t, cid)
+
+		cid, err = cas.GetCID([]byte("content"), extendedcasclient.WithCIDVersion(2))
+		require.EqualError(t, err, "2 is not a supported CID version. It must be either 0 or 1")
+		require.Empty(t, cid)
+	})
+}
+
+func TestWriteWithConfig(t *testing.T) {
+	t.Run("success - pin and MFS", func(t *testing.T) {
+		node := newMockNode()
+
+		server := httptest.NewServer(node)
+		defer server.Close()
+
+		cas := NewWithConfig(Config{URL: server.URL, Timeout: 5 * time.Second, Pin: true, MFSDir: "/orb/cas/"},
+			0, &orbmocks.MetricsProvider{})
+
+		cid, err := cas.WriteWithCIDFormat([]byte("content"))
+		require.NoError(t, err)
+		require.Equal(t, testCID, cid)
+
+		pinned, err := cas.IsPinned(cid)
+		require.NoError(t, err)
+		require.True(t, pinned)
+
+		require.True(t, node.hasMFSFile("/orb/cas/"+cid))
+
+		// Writing again doesn't copy the file to MFS again.
+		_, err = cas.WriteWithCIDFormat([]byte("content"))
+		require.NoError(t, err)
+		require.Equal(t, 1, node.copyCount())
+	})
+
+	t.Run("error - pin", func(t *testing.T) {
+		node := newMockNode()
+		node.pinErr = errors.New("pin error")
+
+		server := httptest.NewServer(node)
+		defer server.Close()
+
+		cas := NewWithConfig(Config{URL: server.URL, Timeout: 5 * time.Second, Pin: true},
+			0, &orbmocks.MetricsProvider{})
+
+		cid, err := cas.WriteWithCIDFormat([]byte("content"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "pin error")
+		require.Empty(t, cid)
+	})
+
+	t.Run("error - not pinned after pin", func(t *testing.T) {
+		node := newMockNode()
+		node.ignorePins = true
+
+		server := httptest.NewServer(node)
+		defer server.Close()
+
+		cas := NewWithConfig(Config{URL: server.URL, Timeout: 5 * time.Second, Pin: true},
+			0, &orbmocks.MetricsProvider{})
+
+		cid, err := cas.WriteWithCIDFormat([]byte("content"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "is not pinned after it was added")
+		require.Empty(t, cid)
+	})
+
+	t.Run("error - MFS copy", func(t *testing.T) {
+		node := newMockNode()
+		node.cpErr = errors.New("copy error")
+
+		server := httptest.NewServer(node)
+		defer server.Close()
+
+		cas := NewWithConfig(Config{URL: server.URL, Timeout: 5 * time.Second, MFSDir: "/orb"},
+			0, &orbmocks.MetricsProvider{})
+
+		cid, err := cas.WriteWithCIDFormat([]byte("content"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "failed to copy cid")
+		require.Empty(t, cid)
+	})
+
+	t.Run("error - MFS mkdir", func(t *testing.T) {
+		node := newMockNode()
+		node.mkdirErr = errors.New("mkdir error")
+
+		server := httptest.NewServer(node)
+		defer server.Close()
+
+		cas := NewWithConfig(Config{URL: server.URL, Timeout: 5 * time.Second, MFSDir: "/orb"},
+			0, &orbmocks.MetricsProvider{})
+
+		cid, err := cas.WriteWithCIDFormat([]byte("content"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create MFS directory")
+		require.Empty(t, cid)
+	})
+}
+
+// mockNode is a minimal implementation of the IPFS HTTP API that supports the add, pin and files commands.
+type mockNode struct {
+	mutex      sync.Mutex
+	pins       map[string]bool
+	mfsFiles   map[string]bool
+	copies     int
+	ignorePins bool
+	pinErr     error
+	mkdirErr   error
+	cpErr      error
+}
+
+func newMockNode() *mockNode {
+	return &mockNode{
+		pins:     make(map[string]bool),
+		mfsFiles: make(map[string]bool),
+	}
+}
+
+func (n *mockNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	arg := r.URL.Query()["arg"]
+
+	switch r.URL.Path {
+	case "/api/v0/add":
+		fmt.Fprintf(w, `{"Hash":"%s"}`, testCID)
+	case "/api/v0/pin/add":
+		if n.pinErr != nil {
+			writeIPFSError(w, n.pinErr.Error())
+
+			return
+		}
+
+		if !n.ignorePins {
+			n.pins[arg[0]] = true
+		}
+
+		fmt.Fprintf(w, `{"Pins":["%s"]}`, arg[0])
+	case "/api/v0/pin/rm":
+		if !n.pins[arg[0]] {
+			writeIPFSError(w, "not pinned or pinned indirectly")
+
+			return
+		}
+
+		delete(n.pins, arg[0])
+
+		fmt.Fprintf(w, `{"Pins":["%s"]}`, arg[0])
+	case "/api/v0/pin/ls":
+		if !n.pins[arg[0]] {
+			writeIPFSError(w, fmt.Sprintf("path '%s' is not pinned", arg[0]))
+
+			return
+		}
+
+		fmt.Fprintf(w, `{"Keys":{"%s":{"Type":"recursive"}}}`, arg[0])
+	case "/api/v0/files/stat":
+		if !n.mfsFiles[arg[0]] {
+			writeIPFSError(w, "file does not exist")
+
+			return
+		}
+
+		fmt.Fprint(w, `{}`)
+	case "/api/v0/files/mkdir":
+		if n.mkdirErr != nil {
+			writeIPFSError(w, n.mkdirErr.Error())
+
+			return
+		}
+	case "/api/v0/files/cp":
+		if n.cpErr != nil {
+			writeIPFSError(w, n.cpErr.Error())
+
+			return
+		}
+
+		n.mfsFiles[arg[1]] = true
+		n.copies++
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (n *mockNode) hasMFSFile(path string) bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	return n.mfsFiles[path]
+}
+
+func (n *mockNode) copyCount() int {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	return n.copies
+}
+
+func writeIPFSError(w http.ResponseWriter, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+
+	fmt.Fprintf(w, `{"Message":"%s","Code":0,"Type":"error"}`, msg)
+}
+
 func startIPFSDockerContainer(t *testing.T) (*dctest.Pool, *dctest.Resource) {
 	t.Helper()
 
@@ -0,0 +1,694 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	"github.com/trustbloc/orb/pkg/cas/gc"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+	casstore "github.com/trustbloc/orb/pkg/store/cas"
+)
+
+var logger = log.New("cas-replication")
+
+// ErrInProgress is returned when a replication pass is requested while another pass is in progress.
+var ErrInProgress = errors.New("CAS replication is already in progress")
+
+// errStopped is returned when a pass is interrupted because the replicator was stopped.
+var errStopped = errors.New("CAS replicator stopped")
+
+const (
+	storeName = "cas_replication"
+
+	// progressKey is the key of the persisted progress. Resource hashes are multibase-encoded so they
+	// never start with an underscore.
+	progressKey = "_progress"
+
+	// legacyTaggedKey is the key of the record that indicates that the legacy (untagged) content was tagged.
+	legacyTaggedKey = "_legacy_tagged"
+
+	defaultCheckpointInterval = 100
+	defaultCIDVersion         = 1
+
+	// legacyAnchorBatchSize is the number of DID anchor entries that are tagged in a single batch.
+	legacyAnchorBatchSize = 1000
+)
+
+const (
+	resultVerified   = "verified"
+	resultBackfilled = "backfilled"
+	resultFailed     = "failed"
+)
+
+// Config holds the configuration for the replicator.
+type Config struct {
+	// Interval is the interval at which a replication pass is performed. If zero then replication is only
+	// performed on request.
+	Interval time.Duration
+	// CIDVersion is the version of the CIDs of the content written to IPFS. Defaults to 1 if nil.
+	CIDVersion *int
+	// CheckpointInterval is the number of resources that are processed before the progress is persisted.
+	CheckpointInterval int
+	// CompressionAlgorithm is the algorithm used to compress the Sidetree batch files. It's used to traverse
+	// the anchor graph when tagging legacy content.
+	CompressionAlgorithm string
+}
+
+// Providers contains all of the providers required by the replicator.
+type Providers struct {
+	StoreProvider storage.Provider
+	IPFSClient    ipfsClient
+	Metrics       metricsProvider
+	// AnchorStore is used to find the legacy (untagged) content in the local CAS. If nil then legacy
+	// content is not replicated.
+	AnchorStore anchorStore
+	// OperationStore provides the suffixes of all DIDs so that the DID anchor entries that were written
+	// before they were tagged may be tagged. If nil then legacy content is not replicated.
+	OperationStore operationStore
+}
+
+type anchorStore interface {
+	GetAllAnchors() ([]string, error)
+	TagAnchors(suffixes []string) (int, error)
+}
+
+type operationStore interface {
+	GetSuffixes() ([]string, error)
+}
+
+type ipfsClient interface {
+	GetCID(content []byte, opts ...extendedcasclient.CIDFormatOption) (string, error)
+	IsPinned(cid string) (bool, error)
+	WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFormatOption) (string, error)
+}
+
+type metricsProvider interface {
+	CASReplicationIncrementCount(result string)
+	CASReplicationPassTime(value time.Duration)
+}
+
+// Progress contains the progress of the current (or the last) replication pass.
+type Progress struct {
+	InProgress bool      `json:"inProgress"`
+	Force      bool      `json:"force,omitempty"`
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+	// Scanned is the number of resources in the local CAS that were considered for replication.
+	Scanned int `json:"scanned"`
+	// Skipped is the number of resources that were replicated in a previous pass.
+	Skipped int `json:"skipped"`
+	// Verified is the number of resources that were found to be already pinned in IPFS.
+	Verified int `json:"verified"`
+	// Backfilled is the number of resources that were missing from IPFS and were added.
+	Backfilled int `json:"backfilled"`
+	// Failed is the number of resources that could not be replicated. They're retried on the next pass.
+	Failed int `json:"failed"`
+	// LastError is the last error that occurred during the pass.
+	LastError string `json:"lastError,omitempty"`
+	// Cursor is the position of the last resource that was processed. An interrupted pass is resumed after
+	// this position.
+	Cursor *Cursor `json:"cursor,omitempty"`
+}
+
+// Cursor is a position in the scan of the local CAS. Resources are scanned in the order of their creation
+// time and then their resource hash.
+type Cursor struct {
+	Created      int64  `json:"created"`
+	ResourceHash string `json:"resourceHash"`
+}
+
+func (c *Cursor) isAfter(e *entry) bool {
+	if c.Created != e.created {
+		return c.Created > e.created
+	}
+
+	return c.ResourceHash >= e.resourceHash
+}
+
+type entry struct {
+	resourceHash string
+	created      int64
+}
+
+type replicated struct {
+	CID  string    `json:"cid"`
+	Time time.Time `json:"time"`
+}
+
+// Replicator ensures that the content of the local CAS is also stored in IPFS. This is needed when IPFS
+// was enabled after content was written to the local CAS, or when a write to IPFS failed. Each pass scans
+// the local CAS, computes the expected CID of each resource and checks whether the CID is pinned by the IPFS
+// node. Missing content is added to IPFS. Resources that are known to be in IPFS are recorded so that an
+// interrupted pass may be resumed without checking them again (unless a forced pass is requested). A pass
+// that was interrupted by a shutdown is resumed from its persisted cursor when the replicator is started.
+//
+// Resources are found by querying the creation time tag, which is only set on content written after creation
+// times were introduced. Before the first pass, the legacy (untagged) content that's reachable from the anchor
+// graph is tagged so that it's also replicated. Legacy content that's not reachable from the latest anchors is
+// not replicated.
+type Replicator struct {
+	*Providers
+	*lifecycle.Lifecycle
+
+	casStore           storage.Store
+	store              storage.Store
+	done               chan struct{}
+	interval           time.Duration
+	cidOpts            []extendedcasclient.CIDFormatOption
+	checkpointInterval int
+	compAlg            string
+	inProgress         int32
+}
+
+// New returns a new CAS replicator.
+func New(providers *Providers, cfg Config) (*Replicator, error) {
+	casStore, err := providers.StoreProvider.OpenStore(casstore.StoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", casstore.StoreName, err)
+	}
+
+	store, err := providers.StoreProvider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", storeName, err)
+	}
+
+	cidVersion := defaultCIDVersion
+	if cfg.CIDVersion != nil {
+		cidVersion = *cfg.CIDVersion
+	}
+
+	r := &Replicator{
+		Providers:          providers,
+		casStore:           casStore,
+		store:              store,
+		done:               make(chan struct{}),
+		interval:           cfg.Interval,
+		cidOpts:            []extendedcasclient.CIDFormatOption{extendedcasclient.WithCIDVersion(cidVersion)},
+		checkpointInterval: cfg.CheckpointInterval,
+		compAlg:            cfg.CompressionAlgorithm,
+	}
+
+	if r.checkpointInterval == 0 {
+		r.checkpointInterval = defaultCheckpointInterval
+	}
+
+	r.Lifecycle = lifecycle.New("cas-replication",
+		lifecycle.WithStart(r.start),
+		lifecycle.WithStop(r.stop))
+
+	return r, nil
+}
+
+func (r *Replicator) start() {
+	progress, err := r.Progress()
+	if err != nil {
+		logger.Warnf("Unable to load CAS replication progress: %s", err)
+	} else if progress.InProgress {
+		logger.Infof("Resuming interrupted CAS replication pass that was started at %s", progress.StartTime)
+
+		if e := r.trigger(progress); e != nil {
+			logger.Warnf("Unable to resume CAS replication: %s", e)
+		}
+	}
+
+	if r.interval == 0 {
+		logger.Infof("Periodic CAS replication is disabled")
+
+		return
+	}
+
+	go r.run()
+
+	logger.Infof("Started CAS replicator - Interval: %s", r.interval)
+}
+
+func (r *Replicator) stop() {
+	close(r.done)
+
+	logger.Infof("Stopped CAS replicator")
+}
+
+func (r *Replicator) run() {
+	for {
+		select {
+		case <-time.After(r.interval):
+			_, err := r.Replicate(false)
+			if err != nil && !errors.Is(err, ErrInProgress) && !errors.Is(err, errStopped) {
+				logger.Errorf("Error replicating CAS content: %s", err)
+			}
+		case <-r.done:
+			logger.Debugf("Exiting CAS replicator.")
+
+			return
+		}
+	}
+}
+
+// Trigger starts a replication pass in the background. If force is true then resources that were replicated
+// in a previous pass are checked again. ErrInProgress is returned if a pass is already in progress.
+func (r *Replicator) Trigger(force bool) error {
+	return r.trigger(newProgress(force))
+}
+
+func (r *Replicator) trigger(progress *Progress) error {
+	if !atomic.CompareAndSwapInt32(&r.inProgress, 0, 1) {
+		return ErrInProgress
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&r.inProgress, 0)
+
+		if _, err := r.replicate(progress); err != nil && !errors.Is(err, errStopped) {
+			logger.Errorf("Error replicating CAS content: %s", err)
+		}
+	}()
+
+	return nil
+}
+
+// Replicate performs a replication pass and returns the progress once the pass has completed. If force is
+// true then resources that were replicated in a previous pass are checked again. ErrInProgress is returned
+// if a pass is already in progress.
+func (r *Replicator) Replicate(force bool) (*Progress, error) {
+	if !atomic.CompareAndSwapInt32(&r.inProgress, 0, 1) {
+		return nil, ErrInProgress
+	}
+
+	defer atomic.StoreInt32(&r.inProgress, 0)
+
+	return r.replicate(newProgress(force))
+}
+
+// Progress returns the progress of the current (or the last) replication pass.
+func (r *Replicator) Progress() (*Progress, error) {
+	progressBytes, err := r.store.Get(progressKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return &Progress{}, nil
+		}
+
+		return nil, fmt.Errorf("get progress: %w", err)
+	}
+
+	progress := &Progress{}
+
+	if err = json.Unmarshal(progressBytes, progress); err != nil {
+		return nil, fmt.Errorf("unmarshal progress: %w", err)
+	}
+
+	return progress, nil
+}
+
+func newProgress(force bool) *Progress {
+	return &Progress{InProgress: true, Force: force, StartTime: time.Now()}
+}
+
+func (r *Replicator) replicate(progress *Progress) (*Progress, error) {
+	logger.Infof("Starting CAS replication pass - Force: %t, Cursor: %+v", progress.Force, progress.Cursor)
+
+	if err := r.saveProgress(progress); err != nil {
+		return nil, err
+	}
+
+	if err := r.tagLegacyContent(); err != nil {
+		return nil, err
+	}
+
+	err := r.scan(progress)
+	if errors.Is(err, errStopped) {
+		// Save the progress with the in-progress flag set so that the pass is resumed on the next start.
+		if e := r.saveProgress(progress); e != nil {
+			logger.Warnf("Unable to save CAS replication progress: %s", e)
+		}
+
+		logger.Infof("CAS replication pass was interrupted after scanning %d resources", progress.Scanned)
+
+		return nil, err
+	}
+
+	if err != nil {
+		progress.LastError = err.Error()
+	}
+
+	progress.InProgress = false
+	progress.EndTime = time.Now()
+	progress.Cursor = nil
+
+	if e := r.saveProgress(progress); e != nil {
+		logger.Warnf("Unable to save CAS replication progress: %s", e)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.Metrics.CASReplicationPassTime(progress.EndTime.Sub(progress.StartTime))
+
+	logger.Infof("CAS replication pass completed in %s - Scanned: %d, Skipped: %d, Verified: %d, "+
+		"Backfilled: %d, Failed: %d", progress.EndTime.Sub(progress.StartTime), progress.Scanned, progress.Skipped,
+		progress.Verified, progress.Backfilled, progress.Failed)
+
+	return progress, nil
+}
+
+// scan processes the resources in the local CAS in the order of their creation time, starting after the
+// progress cursor (if any). The cursor is persisted at every checkpoint.
+func (r *Replicator) scan(progress *Progress) error {
+	entries, err := r.getEntries()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if progress.Cursor != nil && progress.Cursor.isAfter(e) {
+			continue
+		}
+
+		select {
+		case <-r.done:
+			return errStopped
+		default:
+		}
+
+		if err = r.process(e.resourceHash, progress); err != nil {
+			return err
+		}
+
+		progress.Cursor = &Cursor{Created: e.created, ResourceHash: e.resourceHash}
+
+		if progress.Scanned%r.checkpointInterval == 0 {
+			if err = r.saveProgress(progress); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getEntries returns the resource hashes and creation times of the tagged resources in the local CAS, sorted
+// by creation time and then resource hash.
+func (r *Replicator) getEntries() ([]*entry, error) {
+	iter, err := r.casStore.Query(casstore.CreatedTagName)
+	if err != nil {
+		return nil, fmt.Errorf("query CAS: %w", err)
+	}
+
+	defer func() {
+		if e := iter.Close(); e != nil {
+			logger.Warnf("Failed to close iterator: %s", e)
+		}
+	}()
+
+	var entries []*entry
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, fmt.Errorf("get next CAS entry: %w", err)
+	}
+
+	for ok {
+		ent, e := getEntry(iter)
+		if e != nil {
+			return nil, e
+		}
+
+		entries = append(entries, ent)
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("get next CAS entry: %w", err)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].created != entries[j].created {
+			return entries[i].created < entries[j].created
+		}
+
+		return entries[i].resourceHash < entries[j].resourceHash
+	})
+
+	return entries, nil
+}
+
+func getEntry(iter storage.Iterator) (*entry, error) {
+	resourceHash, err := iter.Key()
+	if err != nil {
+		return nil, fmt.Errorf("get CAS entry key: %w", err)
+	}
+
+	tags, err := iter.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("get CAS entry tags: %w", err)
+	}
+
+	e := &entry{resourceHash: resourceHash}
+
+	for _, tag := range tags {
+		if tag.Name != casstore.CreatedTagName {
+			continue
+		}
+
+		// An invalid creation time simply puts the resource at the start of the scan.
+		e.created, _ = strconv.ParseInt(tag.Value, 10, 64)
+	}
+
+	return e, nil
+}
+
+// tagLegacyContent performs a one-time migration that tags the content that was written to the local CAS
+// before creation times were recorded, so that it's found by subsequent scans. Untagged content can't be
+// queried from the store, so the legacy content is found by traversing the anchor graph from the latest anchors
+// of all DIDs. The DID anchor entries that were written before they were tagged are tagged first, since
+// otherwise their anchors wouldn't be found.
+func (r *Replicator) tagLegacyContent() error {
+	if r.AnchorStore == nil || r.OperationStore == nil {
+		return nil
+	}
+
+	_, err := r.store.Get(legacyTaggedKey)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("get legacy tagging status: %w", err)
+	}
+
+	if err = r.tagLegacyAnchors(); err != nil {
+		return err
+	}
+
+	reachable, err := gc.Reachable(r.casStore, r.AnchorStore, r.compAlg)
+	if err != nil {
+		return fmt.Errorf("find legacy content: %w", err)
+	}
+
+	createdTag := storage.Tag{Name: casstore.CreatedTagName, Value: strconv.FormatInt(time.Now().Unix(), 10)}
+
+	tagged := 0
+
+	for resourceHash := range reachable {
+		isTagged, e := r.tagIfUntagged(resourceHash, createdTag)
+		if e != nil {
+			return e
+		}
+
+		if isTagged {
+			tagged++
+		}
+	}
+
+	if err = r.store.Put(legacyTaggedKey, []byte(createdTag.Value)); err != nil {
+		return fmt.Errorf("store legacy tagging status: %w", err)
+	}
+
+	logger.Infof("Tagged %d legacy resources (out of %d reachable resources) in the local CAS",
+		tagged, len(reachable))
+
+	return nil
+}
+
+func (r *Replicator) tagLegacyAnchors() error {
+	suffixes, err := r.OperationStore.GetSuffixes()
+	if err != nil {
+		return fmt.Errorf("get DID suffixes: %w", err)
+	}
+
+	tagged := 0
+
+	for start := 0; start < len(suffixes); start += legacyAnchorBatchSize {
+		end := start + legacyAnchorBatchSize
+		if end > len(suffixes) {
+			end = len(suffixes)
+		}
+
+		n, e := r.AnchorStore.TagAnchors(suffixes[start:end])
+		if e != nil {
+			return fmt.Errorf("tag DID anchors: %w", e)
+		}
+
+		tagged += n
+	}
+
+	logger.Infof("Tagged %d DID anchor entries (out of %d DIDs)", tagged, len(suffixes))
+
+	return nil
+}
+
+func (r *Replicator) tagIfUntagged(resourceHash string, createdTag storage.Tag) (bool, error) {
+	tags, err := r.casStore.GetTags(resourceHash)
+	if err != nil {
+		return false, fmt.Errorf("get tags of CAS resource [%s]: %w", resourceHash, err)
+	}
+
+	for _, tag := range tags {
+		if tag.Name == casstore.CreatedTagName {
+			return false, nil
+		}
+	}
+
+	content, err := r.casStore.Get(resourceHash)
+	if err != nil {
+		return false, fmt.Errorf("get CAS resource [%s]: %w", resourceHash, err)
+	}
+
+	if err = r.casStore.Put(resourceHash, content, append(tags, createdTag)...); err != nil {
+		return false, fmt.Errorf("tag CAS resource [%s]: %w", resourceHash, err)
+	}
+
+	return true, nil
+}
+
+// process replicates the given resource. An error is only returned if the replication store is inaccessible;
+// failures to replicate the resource are recorded in the progress.
+func (r *Replicator) process(resourceHash string, progress *Progress) error {
+	progress.Scanned++
+
+	if !progress.Force {
+		isReplicated, err := r.isReplicated(resourceHash)
+		if err != nil {
+			return err
+		}
+
+		if isReplicated {
+			progress.Skipped++
+
+			return nil
+		}
+	}
+
+	cid, result, err := r.replicateResource(resourceHash)
+	if err != nil {
+		logger.Warnf("Failed to replicate CAS resource [%s] in IPFS: %s", resourceHash, err)
+
+		progress.Failed++
+		progress.LastError = fmt.Sprintf("resource [%s]: %s", resourceHash, err)
+
+		r.Metrics.CASReplicationIncrementCount(resultFailed)
+
+		return nil
+	}
+
+	if result == resultBackfilled {
+		progress.Backfilled++
+	} else {
+		progress.Verified++
+	}
+
+	r.Metrics.CASReplicationIncrementCount(result)
+
+	return r.markReplicated(resourceHash, cid)
+}
+
+func (r *Replicator) replicateResource(resourceHash string) (string, string, error) {
+	content, err := r.casStore.Get(resourceHash)
+	if err != nil {
+		return "", "", fmt.Errorf("get content: %w", err)
+	}
+
+	cid, err := r.IPFSClient.GetCID(content, r.cidOpts...)
+	if err != nil {
+		return "", "", fmt.Errorf("get CID: %w", err)
+	}
+
+	pinned, err := r.IPFSClient.IsPinned(cid)
+	if err != nil {
+		return "", "", fmt.Errorf("check pin status of CID [%s]: %w", cid, err)
+	}
+
+	if pinned {
+		logger.Debugf("CAS resource [%s] is already in IPFS with CID [%s]", resourceHash, cid)
+
+		return cid, resultVerified, nil
+	}
+
+	writtenCID, err := r.IPFSClient.WriteWithCIDFormat(content, r.cidOpts...)
+	if err != nil {
+		return "", "", fmt.Errorf("write content to IPFS: %w", err)
+	}
+
+	if writtenCID != cid {
+		logger.Warnf("CID [%s] of CAS resource [%s] written to IPFS differs from the expected CID [%s]",
+			writtenCID, resourceHash, cid)
+	}
+
+	logger.Debugf("CAS resource [%s] was added to IPFS with CID [%s]", resourceHash, writtenCID)
+
+	return writtenCID, resultBackfilled, nil
+}
+
+func (r *Replicator) isReplicated(resourceHash string) (bool, error) {
+	_, err := r.store.Get(resourceHash)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("get replication status of [%s]: %w", resourceHash, err)
+	}
+
+	return true, nil
+}
+
+func (r *Replicator) markReplicated(resourceHash, cid string) error {
+	valueBytes, err := json.Marshal(&replicated{CID: cid, Time: time.Now()})
+	if err != nil {
+		return fmt.Errorf("marshal replication status: %w", err)
+	}
+
+	if err = r.store.Put(resourceHash, valueBytes); err != nil {
+		return fmt.Errorf("store replication status of [%s]: %w", resourceHash, err)
+	}
+
+	return nil
+}
+
+func (r *Replicator) saveProgress(progress *Progress) error {
+	progressBytes, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("marshal progress: %w", err)
+	}
+
+	if err = r.store.Put(progressKey, progressBytes); err != nil {
+		return fmt.Errorf("store progress: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,685 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+	"github.com/trustbloc/orb/pkg/anchor/activity"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	casstore "github.com/trustbloc/orb/pkg/store/cas"
+	didanchorstore "github.com/trustbloc/orb/pkg/store/didanchor"
+	"github.com/trustbloc/orb/pkg/store/mocks"
+	operationstore "github.com/trustbloc/orb/pkg/store/operation"
+)
+
+const casLink = "https://domain.com/cas"
+
+func TestNew(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		r, err := New(&Providers{StoreProvider: mem.NewProvider()}, Config{})
+		require.NoError(t, err)
+		require.NotNil(t, r)
+		require.Equal(t, defaultCheckpointInterval, r.checkpointInterval)
+		require.Len(t, r.cidOpts, 1)
+		require.Equal(t, defaultCIDVersion, cidVersionOf(r))
+	})
+
+	t.Run("CID version 0", func(t *testing.T) {
+		cidVersion := 0
+
+		r, err := New(&Providers{StoreProvider: mem.NewProvider()}, Config{CIDVersion: &cidVersion})
+		require.NoError(t, err)
+		require.Equal(t, 0, cidVersionOf(r))
+	})
+
+	t.Run("Open CAS store error", func(t *testing.T) {
+		p := &mocks.Provider{}
+		p.OpenStoreReturns(nil, errors.New("injected open error"))
+
+		_, err := New(&Providers{StoreProvider: p}, Config{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open store [cas_store]: injected open error")
+	})
+
+	t.Run("Open replication store error", func(t *testing.T) {
+		p := &mocks.Provider{}
+		p.OpenStoreReturnsOnCall(1, nil, errors.New("injected open error"))
+
+		_, err := New(&Providers{StoreProvider: p}, Config{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open store [cas_replication]: injected open error")
+	})
+}
+
+func TestReplicator_Replicate(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		env := newTestEnv(t, "content1", "content2", "content3")
+
+		// content1 is already in IPFS.
+		env.ipfs.pin(t, "content1")
+
+		r := env.newReplicator(t, Config{CheckpointInterval: 2})
+
+		progress, err := r.Replicate(false)
+		require.NoError(t, err)
+		require.False(t, progress.InProgress)
+		require.Equal(t, 3, progress.Scanned)
+		require.Equal(t, 1, progress.Verified)
+		require.Equal(t, 2, progress.Backfilled)
+		require.Zero(t, progress.Skipped)
+		require.Zero(t, progress.Failed)
+		require.Equal(t, 2, env.ipfs.writeCount())
+
+		p, err := r.Progress()
+		require.NoError(t, err)
+		require.Equal(t, progress.Scanned, p.Scanned)
+		require.False(t, p.InProgress)
+
+		// All of the resources are skipped on the next pass.
+		progress, err = r.Replicate(false)
+		require.NoError(t, err)
+		require.Equal(t, 3, progress.Scanned)
+		require.Equal(t, 3, progress.Skipped)
+		require.Equal(t, 2, env.ipfs.writeCount())
+
+		// All of the resources are verified on a forced pass.
+		progress, err = r.Replicate(true)
+		require.NoError(t, err)
+		require.Equal(t, 3, progress.Scanned)
+		require.Equal(t, 3, progress.Verified)
+		require.Zero(t, progress.Skipped)
+		require.Equal(t, 2, env.ipfs.writeCount())
+	})
+
+	t.Run("IPFS errors", func(t *testing.T) {
+		env := newTestEnv(t, "content1", "content2")
+
+		r := env.newReplicator(t, Config{})
+
+		env.ipfs.setErrors(errors.New("injected CID error"), nil, nil)
+
+		progress, err := r.Replicate(false)
+		require.NoError(t, err)
+		require.Equal(t, 2, progress.Failed)
+		require.Contains(t, progress.LastError, "injected CID error")
+
+		env.ipfs.setErrors(nil, errors.New("injected pin error"), nil)
+
+		progress, err = r.Replicate(false)
+		require.NoError(t, err)
+		require.Equal(t, 2, progress.Failed)
+		require.Contains(t, progress.LastError, "injected pin error")
+
+		env.ipfs.setErrors(nil, nil, errors.New("injected write error"))
+
+		progress, err = r.Replicate(false)
+		require.NoError(t, err)
+		require.Equal(t, 2, progress.Failed)
+		require.Contains(t, progress.LastError, "injected write error")
+
+		// Failed resources are retried on the next pass.
+		env.ipfs.setErrors(nil, nil, nil)
+
+		progress, err = r.Replicate(false)
+		require.NoError(t, err)
+		require.Zero(t, progress.Failed)
+		require.Equal(t, 2, progress.Backfilled)
+	})
+
+	t.Run("Unexpected CID", func(t *testing.T) {
+		env := newTestEnv(t, "content1")
+		env.ipfs.writtenCID = "other-cid"
+
+		progress, err := env.newReplicator(t, Config{}).Replicate(false)
+		require.NoError(t, err)
+		require.Equal(t, 1, progress.Backfilled)
+	})
+
+	t.Run("In progress", func(t *testing.T) {
+		r := newTestEnv(t).newReplicator(t, Config{})
+		r.inProgress = 1
+
+		_, err := r.Replicate(false)
+		require.True(t, errors.Is(err, ErrInProgress))
+		require.True(t, errors.Is(r.Trigger(false), ErrInProgress))
+	})
+
+	t.Run("CAS query error", func(t *testing.T) {
+		s := &mocks.Store{}
+		s.QueryReturns(nil, errors.New("injected query error"))
+
+		r := newReplicatorWithStores(t, s, &mocks.Store{})
+
+		_, err := r.Replicate(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected query error")
+	})
+
+	t.Run("CAS iterator errors", func(t *testing.T) {
+		iter := &mocks.Iterator{}
+		iter.NextReturns(false, errors.New("injected next error"))
+
+		s := &mocks.Store{}
+		s.QueryReturns(iter, nil)
+
+		_, err := newReplicatorWithStores(t, s, &mocks.Store{}).Replicate(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected next error")
+
+		iter = &mocks.Iterator{}
+		iter.NextReturns(true, nil)
+		iter.KeyReturns("", errors.New("injected key error"))
+
+		s.QueryReturns(iter, nil)
+
+		_, err = newReplicatorWithStores(t, s, &mocks.Store{}).Replicate(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected key error")
+	})
+
+	t.Run("Replication store errors", func(t *testing.T) {
+		env := newTestEnv(t, "content1")
+
+		casStore, err := env.provider.OpenStore(casstore.StoreName)
+		require.NoError(t, err)
+
+		s := &mocks.Store{}
+		s.PutReturns(errors.New("injected put error"))
+
+		_, err = newReplicatorWithStores(t, casStore, s).Replicate(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "store progress: injected put error")
+
+		s = &mocks.Store{}
+		s.GetReturns(nil, errors.New("injected get error"))
+
+		_, err = newReplicatorWithStores(t, casStore, s).Replicate(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get replication status")
+
+		s = &mocks.Store{}
+		s.GetReturns(nil, storage.ErrDataNotFound)
+		s.PutReturnsOnCall(1, errors.New("injected put error"))
+
+		_, err = newReplicatorWithStores(t, casStore, s).Replicate(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "store replication status")
+	})
+}
+
+func TestReplicator_Progress(t *testing.T) {
+	t.Run("No progress", func(t *testing.T) {
+		p, err := newTestEnv(t).newReplicator(t, Config{}).Progress()
+		require.NoError(t, err)
+		require.Equal(t, &Progress{}, p)
+	})
+
+	t.Run("Get error", func(t *testing.T) {
+		s := &mocks.Store{}
+		s.GetReturns(nil, errors.New("injected get error"))
+
+		_, err := newReplicatorWithStores(t, &mocks.Store{}, s).Progress()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected get error")
+	})
+
+	t.Run("Unmarshal error", func(t *testing.T) {
+		s := &mocks.Store{}
+		s.GetReturns([]byte("{"), nil)
+
+		_, err := newReplicatorWithStores(t, &mocks.Store{}, s).Progress()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal progress")
+	})
+}
+
+func TestReplicator_StartStop(t *testing.T) {
+	t.Run("Periodic", func(t *testing.T) {
+		env := newTestEnv(t, "content1")
+
+		r := env.newReplicator(t, Config{Interval: 10 * time.Millisecond})
+
+		r.Start()
+		defer r.Stop()
+
+		require.Eventually(t, func() bool {
+			return env.ipfs.writeCount() == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Resume interrupted pass", func(t *testing.T) {
+		env := newTestEnv(t, "content1", "content2")
+
+		r := env.newReplicator(t, Config{})
+
+		require.NoError(t, r.saveProgress(&Progress{InProgress: true, StartTime: time.Now()}))
+
+		r.Start()
+		defer r.Stop()
+
+		require.Eventually(t, func() bool {
+			p, err := r.Progress()
+			require.NoError(t, err)
+
+			return !p.InProgress && p.Backfilled == 2
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Resume from cursor", func(t *testing.T) {
+		env := newTestEnv(t, "content1", "content2", "content3")
+
+		r := env.newReplicator(t, Config{})
+
+		entries, err := r.getEntries()
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+
+		// The first resource was processed before the pass was interrupted.
+		require.NoError(t, r.saveProgress(&Progress{
+			InProgress: true,
+			StartTime:  time.Now(),
+			Scanned:    1,
+			Backfilled: 1,
+			Cursor:     &Cursor{Created: entries[0].created, ResourceHash: entries[0].resourceHash},
+		}))
+
+		r.Start()
+		defer r.Stop()
+
+		require.Eventually(t, func() bool {
+			p, e := r.Progress()
+			require.NoError(t, e)
+
+			return !p.InProgress
+		}, time.Second, 10*time.Millisecond)
+
+		p, err := r.Progress()
+		require.NoError(t, err)
+		require.Equal(t, 3, p.Scanned)
+		require.Equal(t, 3, p.Backfilled)
+		require.Nil(t, p.Cursor)
+
+		// Only the resources after the cursor were written.
+		require.Equal(t, 2, env.ipfs.writeCount())
+	})
+
+	t.Run("Interrupted by stop", func(t *testing.T) {
+		env := newTestEnv(t, "content1", "content2")
+
+		r := env.newReplicator(t, Config{})
+
+		r.Start()
+		r.Stop()
+
+		_, err := r.Replicate(false)
+		require.True(t, errors.Is(err, errStopped))
+
+		p, err := r.Progress()
+		require.NoError(t, err)
+		require.True(t, p.InProgress)
+		require.Zero(t, env.ipfs.writeCount())
+	})
+
+	t.Run("Load progress error", func(t *testing.T) {
+		s := &mocks.Store{}
+		s.GetReturns(nil, errors.New("injected get error"))
+
+		r := newReplicatorWithStores(t, &mocks.Store{}, s)
+
+		r.Start()
+		r.Stop()
+	})
+}
+
+func TestReplicator_LegacyContent(t *testing.T) {
+	env := newTestEnv(t, "content1")
+
+	casStore, err := env.provider.OpenStore(casstore.StoreName)
+	require.NoError(t, err)
+
+	anchorStore, err := didanchorstore.New(env.provider)
+	require.NoError(t, err)
+
+	opStore, err := operationstore.New(env.provider)
+	require.NoError(t, err)
+
+	// Legacy DID anchor entries were written without the anchor tag.
+	didAnchorStore, err := env.provider.OpenStore("didanchor")
+	require.NoError(t, err)
+
+	// The anchor's core index doesn't exist, which shouldn't prevent the anchor from being tagged.
+	missingRH, err := hashlink.New().CreateResourceHash([]byte("missing"))
+	require.NoError(t, err)
+
+	// Write a legacy (untagged) anchor that's reachable from the anchor store and a legacy orphan.
+	act, err := activity.BuildActivityFromPayload(&subject.Payload{
+		Namespace:       "did:orb",
+		CoreIndex:       hashlink.GetHashLinkFromResourceHash(missingRH),
+		PreviousAnchors: map[string]string{"suffix": ""},
+	})
+	require.NoError(t, err)
+
+	anchorBytes, err := json.Marshal(map[string]interface{}{"credentialSubject": act})
+	require.NoError(t, err)
+
+	anchorRH, err := hashlink.New().CreateResourceHash(anchorBytes)
+	require.NoError(t, err)
+
+	require.NoError(t, casStore.Put(anchorRH, anchorBytes))
+	require.NoError(t, casStore.Put("orphan", []byte("orphan")))
+	require.NoError(t, didAnchorStore.Put("suffix", []byte(hashlink.GetHashLinkFromResourceHash(anchorRH))))
+	require.NoError(t, opStore.Put([]*operation.AnchoredOperation{{UniqueSuffix: "suffix"}}))
+
+	anchors, err := anchorStore.GetAllAnchors()
+	require.NoError(t, err)
+	require.Empty(t, anchors)
+
+	r, err := New(&Providers{
+		StoreProvider:  env.provider,
+		IPFSClient:     env.ipfs,
+		Metrics:        &orbmocks.MetricsProvider{},
+		AnchorStore:    anchorStore,
+		OperationStore: opStore,
+	}, Config{})
+	require.NoError(t, err)
+
+	progress, err := r.Replicate(false)
+	require.NoError(t, err)
+	require.Equal(t, 2, progress.Scanned)
+	require.Equal(t, 2, progress.Backfilled)
+
+	anchors, err = anchorStore.GetAllAnchors()
+	require.NoError(t, err)
+	require.Equal(t, []string{hashlink.GetHashLinkFromResourceHash(anchorRH)}, anchors)
+
+	tags, err := casStore.GetTags(anchorRH)
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	require.Equal(t, casstore.CreatedTagName, tags[0].Name)
+
+	// The tagging is only done once.
+	_, err = r.store.Get(legacyTaggedKey)
+	require.NoError(t, err)
+
+	require.NoError(t, casStore.Put(anchorRH, anchorBytes))
+
+	progress, err = r.Replicate(false)
+	require.NoError(t, err)
+	require.Equal(t, 1, progress.Scanned)
+
+	t.Run("Anchor store error", func(t *testing.T) {
+		r, err := New(&Providers{
+			StoreProvider:  mem.NewProvider(),
+			IPFSClient:     env.ipfs,
+			Metrics:        &orbmocks.MetricsProvider{},
+			AnchorStore:    &mockAnchorStore{err: errors.New("injected anchor store error")},
+			OperationStore: &mockOperationStore{},
+		}, Config{})
+		require.NoError(t, err)
+
+		_, err = r.Replicate(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected anchor store error")
+	})
+
+	t.Run("Tag anchors error -> legacy content not marked as tagged", func(t *testing.T) {
+		r, err := New(&Providers{
+			StoreProvider:  mem.NewProvider(),
+			IPFSClient:     env.ipfs,
+			Metrics:        &orbmocks.MetricsProvider{},
+			AnchorStore:    &mockAnchorStore{tagErr: errors.New("injected tag error")},
+			OperationStore: &mockOperationStore{suffixes: []string{"suffix"}},
+		}, Config{})
+		require.NoError(t, err)
+
+		_, err = r.Replicate(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected tag error")
+
+		_, err = r.store.Get(legacyTaggedKey)
+		require.ErrorIs(t, err, storage.ErrDataNotFound)
+	})
+
+	t.Run("Operation store error -> legacy content not marked as tagged", func(t *testing.T) {
+		r, err := New(&Providers{
+			StoreProvider:  mem.NewProvider(),
+			IPFSClient:     env.ipfs,
+			Metrics:        &orbmocks.MetricsProvider{},
+			AnchorStore:    &mockAnchorStore{},
+			OperationStore: &mockOperationStore{err: errors.New("injected operation store error")},
+		}, Config{})
+		require.NoError(t, err)
+
+		_, err = r.Replicate(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected operation store error")
+
+		_, err = r.store.Get(legacyTaggedKey)
+		require.ErrorIs(t, err, storage.ErrDataNotFound)
+	})
+
+	t.Run("Suffixes tagged in batches", func(t *testing.T) {
+		suffixes := make([]string, legacyAnchorBatchSize+1)
+
+		for i := range suffixes {
+			suffixes[i] = fmt.Sprintf("suffix-%d", i)
+		}
+
+		anchorStore := &mockAnchorStore{}
+
+		r, err := New(&Providers{
+			StoreProvider:  mem.NewProvider(),
+			IPFSClient:     env.ipfs,
+			Metrics:        &orbmocks.MetricsProvider{},
+			AnchorStore:    anchorStore,
+			OperationStore: &mockOperationStore{suffixes: suffixes},
+		}, Config{})
+		require.NoError(t, err)
+
+		_, err = r.Replicate(false)
+		require.NoError(t, err)
+		require.Equal(t, []int{legacyAnchorBatchSize, 1}, anchorStore.batches)
+
+		_, err = r.store.Get(legacyTaggedKey)
+		require.NoError(t, err)
+	})
+}
+
+type mockAnchorStore struct {
+	err     error
+	tagErr  error
+	batches []int
+}
+
+func (m *mockAnchorStore) GetAllAnchors() ([]string, error) {
+	return nil, m.err
+}
+
+func (m *mockAnchorStore) TagAnchors(suffixes []string) (int, error) {
+	if m.tagErr != nil {
+		return 0, m.tagErr
+	}
+
+	m.batches = append(m.batches, len(suffixes))
+
+	return len(suffixes), nil
+}
+
+type mockOperationStore struct {
+	suffixes []string
+	err      error
+}
+
+func (m *mockOperationStore) GetSuffixes() ([]string, error) {
+	return m.suffixes, m.err
+}
+
+func cidVersionOf(r *Replicator) int {
+	opts := &extendedcasclient.CIDFormatOptions{}
+
+	for _, opt := range r.cidOpts {
+		opt(opts)
+	}
+
+	return opts.CIDVersion
+}
+
+type testEnv struct {
+	provider *mem.Provider
+	ipfs     *mockIPFSClient
+}
+
+func newTestEnv(t *testing.T, contents ...string) *testEnv {
+	t.Helper()
+
+	provider := mem.NewProvider()
+
+	cas, err := casstore.New(provider, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	for _, content := range contents {
+		_, err = cas.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	return &testEnv{
+		provider: provider,
+		ipfs:     newMockIPFSClient(),
+	}
+}
+
+func (env *testEnv) newReplicator(t *testing.T, cfg Config) *Replicator {
+	t.Helper()
+
+	r, err := New(&Providers{
+		StoreProvider: env.provider,
+		IPFSClient:    env.ipfs,
+		Metrics:       &orbmocks.MetricsProvider{},
+	}, cfg)
+	require.NoError(t, err)
+
+	return r
+}
+
+func newReplicatorWithStores(t *testing.T, casStore, store storage.Store) *Replicator {
+	t.Helper()
+
+	p := &mocks.Provider{}
+	p.OpenStoreReturnsOnCall(0, casStore, nil)
+	p.OpenStoreReturnsOnCall(1, store, nil)
+
+	r, err := New(&Providers{
+		StoreProvider: p,
+		IPFSClient:    newMockIPFSClient(),
+		Metrics:       &orbmocks.MetricsProvider{},
+	}, Config{})
+	require.NoError(t, err)
+
+	return r
+}
+
+// mockIPFSClient uses the resource hash of the content as the CID.
+type mockIPFSClient struct {
+	mutex      sync.Mutex
+	pinned     map[string]bool
+	writes     int
+	writtenCID string
+	cidErr     error
+	pinErr     error
+	writeErr   error
+}
+
+func newMockIPFSClient() *mockIPFSClient {
+	return &mockIPFSClient{pinned: make(map[string]bool)}
+}
+
+func (m *mockIPFSClient) GetCID(content []byte, _ ...extendedcasclient.CIDFormatOption) (string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.cidErr != nil {
+		return "", m.cidErr
+	}
+
+	return hashlink.New().CreateResourceHash(content)
+}
+
+func (m *mockIPFSClient) IsPinned(cid string) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.pinErr != nil {
+		return false, m.pinErr
+	}
+
+	return m.pinned[cid], nil
+}
+
+func (m *mockIPFSClient) WriteWithCIDFormat(content []byte, _ ...extendedcasclient.CIDFormatOption) (string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.writeErr != nil {
+		return "", m.writeErr
+	}
+
+	cid, err := hashlink.New().CreateResourceHash(content)
+	if err != nil {
+		return "", err
+	}
+
+	m.writes++
+	m.pinned[cid] = true
+
+	if m.writtenCID != "" {
+		return m.writtenCID, nil
+	}
+
+	return cid, nil
+}
+
+func (m *mockIPFSClient) pin(t *testing.T, content string) {
+	t.Helper()
+
+	cid, err := hashlink.New().CreateResourceHash([]byte(content))
+	require.NoError(t, err)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.pinned[cid] = true
+}
+
+func (m *mockIPFSClient) setErrors(cidErr, pinErr, writeErr error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.cidErr = cidErr
+	m.pinErr = pinErr
+	m.writeErr = writeErr
+}
+
+func (m *mockIPFSClient) writeCount() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.writes
+}
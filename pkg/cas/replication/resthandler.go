@@ -0,0 +1,168 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+)
+
+const (
+	endpoint = "/casreplication"
+
+	forceParam = "force"
+)
+
+const (
+	badRequestResponse          = "Bad Request."
+	conflictResponse            = "Conflict."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+type replicator interface {
+	Trigger(force bool) error
+	Progress() (*Progress, error)
+}
+
+// Runner starts a replication pass in the background and returns immediately with status 202 (Accepted). If
+// the "force" query parameter is true then resources that were replicated in a previous pass are checked again.
+type Runner struct {
+	replicator replicator
+}
+
+// NewRunner returns a new replication Runner.
+func NewRunner(r replicator) *Runner {
+	return &Runner{
+		replicator: r,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the Runner service.
+func (r *Runner) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the Runner service.
+func (r *Runner) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the Runner service.
+func (r *Runner) Handler() common.HTTPRequestHandler {
+	return r.handle
+}
+
+func (r *Runner) handle(w http.ResponseWriter, req *http.Request) {
+	force := false
+
+	if value := req.URL.Query().Get(forceParam); value != "" {
+		var err error
+
+		force, err = strconv.ParseBool(value)
+		if err != nil {
+			logger.Infof("[%s] Invalid value for parameter [%s]: %s", endpoint, forceParam, err)
+
+			writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+			return
+		}
+	}
+
+	logger.Infof("[%s] CAS replication requested by [%s] - Force: %t",
+		endpoint, auth.TokenIDFromContext(req.Context()), force)
+
+	err := r.replicator.Trigger(force)
+	if err != nil {
+		if errors.Is(err, ErrInProgress) {
+			writeResponse(w, http.StatusConflict, []byte(fmt.Sprintf("%s %s", conflictResponse, err)))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error starting CAS replication: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeResponse(w, http.StatusAccepted, nil)
+}
+
+// ProgressRetriever returns the progress of the current (or the last) replication pass.
+type ProgressRetriever struct {
+	replicator replicator
+	marshal    func(v interface{}) ([]byte, error)
+}
+
+// NewProgressRetriever returns a new ProgressRetriever.
+func NewProgressRetriever(r replicator) *ProgressRetriever {
+	return &ProgressRetriever{
+		replicator: r,
+		marshal:    json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the ProgressRetriever service.
+func (r *ProgressRetriever) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the ProgressRetriever service.
+func (r *ProgressRetriever) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the ProgressRetriever service.
+func (r *ProgressRetriever) Handler() common.HTTPRequestHandler {
+	return r.handle
+}
+
+func (r *ProgressRetriever) handle(w http.ResponseWriter, _ *http.Request) {
+	progress, err := r.replicator.Progress()
+	if err != nil {
+		logger.Errorf("[%s] Error retrieving CAS replication progress: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	respBytes, err := r.marshal(progress)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling response: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, respBytes)
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("Unable to write response: %s", err)
+
+			return
+		}
+
+		logger.Debugf("Wrote response: %s", body)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replication
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner(t *testing.T) {
+	env := newTestEnv(t, "content1", "content2")
+
+	r := env.newReplicator(t, Config{})
+
+	runner := NewRunner(r)
+	require.Equal(t, "/casreplication", runner.Path())
+	require.Equal(t, http.MethodPost, runner.Method())
+	require.NotNil(t, runner.Handler())
+
+	t.Run("Success", func(t *testing.T) {
+		status, _ := handle(t, runner.handle, http.MethodPost, "/casreplication?force=true", nil)
+		require.Equal(t, http.StatusAccepted, status)
+
+		require.Eventually(t, func() bool {
+			p, err := r.Progress()
+			require.NoError(t, err)
+
+			return !p.InProgress && p.Force && p.Backfilled == 2
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Invalid force parameter", func(t *testing.T) {
+		status, _ := handle(t, runner.handle, http.MethodPost, "/casreplication?force=xxx", nil)
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("In progress", func(t *testing.T) {
+		status, body := handle(t, NewRunner(&mockReplicator{err: ErrInProgress}).handle,
+			http.MethodPost, "/casreplication", nil)
+		require.Equal(t, http.StatusConflict, status)
+		require.Contains(t, string(body), ErrInProgress.Error())
+	})
+
+	t.Run("Trigger error", func(t *testing.T) {
+		status, _ := handle(t, NewRunner(&mockReplicator{err: errors.New("injected error")}).handle,
+			http.MethodPost, "/casreplication", nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func TestProgressRetriever(t *testing.T) {
+	env := newTestEnv(t, "content1")
+
+	r := env.newReplicator(t, Config{})
+
+	_, err := r.Replicate(false)
+	require.NoError(t, err)
+
+	retriever := NewProgressRetriever(r)
+	require.Equal(t, "/casreplication", retriever.Path())
+	require.Equal(t, http.MethodGet, retriever.Method())
+	require.NotNil(t, retriever.Handler())
+
+	t.Run("Success", func(t *testing.T) {
+		status, body := handle(t, retriever.handle, http.MethodGet, "/casreplication", nil)
+		require.Equal(t, http.StatusOK, status)
+
+		progress := &Progress{}
+		require.NoError(t, json.Unmarshal(body, progress))
+		require.Equal(t, 1, progress.Scanned)
+		require.Equal(t, 1, progress.Backfilled)
+	})
+
+	t.Run("Progress error", func(t *testing.T) {
+		status, _ := handle(t, NewProgressRetriever(&mockReplicator{err: errors.New("injected error")}).handle,
+			http.MethodGet, "/casreplication", nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		r2 := NewProgressRetriever(&mockReplicator{})
+		r2.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		status, _ := handle(t, r2.handle, http.MethodGet, "/casreplication", nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func handle(t *testing.T, h func(w http.ResponseWriter, req *http.Request), method, target string,
+	body []byte) (int, []byte) {
+	t.Helper()
+
+	rw := httptest.NewRecorder()
+
+	h(rw, httptest.NewRequest(method, target, bytes.NewReader(body)))
+
+	result := rw.Result()
+
+	respBytes, err := ioutil.ReadAll(result.Body)
+	require.NoError(t, err)
+	require.NoError(t, result.Body.Close())
+
+	return result.StatusCode, respBytes
+}
+
+type mockReplicator struct {
+	err error
+}
+
+func (m *mockReplicator) Trigger(bool) error {
+	return m.err
+}
+
+func (m *mockReplicator) Progress() (*Progress, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &Progress{}, nil
+}
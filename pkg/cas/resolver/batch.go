@@ -0,0 +1,290 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
+	"github.com/trustbloc/orb/pkg/webcas"
+	"github.com/trustbloc/orb/pkg/webfinger/model"
+)
+
+const (
+	contentTypeHeader = "Content-Type"
+
+	// defaultBatchTimeout is the maximum time to wait for a batch response, including the time
+	// it takes to read the response body.
+	defaultBatchTimeout = time.Minute
+
+	// defaultMaxBatchResponseSize is the maximum size (in bytes) of a batch response body. Any content
+	// after the limit is ignored.
+	defaultMaxBatchResponseSize = 64 * 1024 * 1024
+)
+
+// ErrBatchNotSupported indicates that a domain doesn't advertise a WebCAS batch endpoint.
+var ErrBatchNotSupported = errors.New("WebCAS batch endpoint not supported")
+
+var errBatchResponseTooLarge = errors.New("batch response is too large")
+
+// ResolveBatch resolves the given CIDs (with possible hints), returning the data in the same order as the CIDs.
+// CIDs that aren't in the local CAS are grouped by the domain of their WebCAS link (or hint) and, if the domain
+// advertises a WebCAS batch endpoint, each group is retrieved in a single round trip. Anything that can't be
+// retrieved in a batch is resolved individually using Resolve.
+func (h *Resolver) ResolveBatch(hashesWithPossibleHints []string) ([][]byte, error) {
+	results := make([][]byte, len(hashesWithPossibleHints))
+
+	if h.localCAS.GetPrimaryWriterType() != "ipfs" {
+		if err := h.resolveBatchFromDomains(hashesWithPossibleHints, results); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, hashWithPossibleHint := range hashesWithPossibleHints {
+		if results[i] != nil {
+			continue
+		}
+
+		data, err := h.Resolve(nil, hashWithPossibleHint, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		results[i] = data
+	}
+
+	return results, nil
+}
+
+// PrefetchBatch retrieves the given CIDs (with possible hints) from WebCAS batch endpoints in the same way as
+// ResolveBatch, except that resources that can't be retrieved in a batch are not resolved individually. The
+// returned slice is in the same order as the CIDs and contains nil for each resource that wasn't retrieved, which
+// allows the caller to resolve those resources (once) when they're actually needed.
+func (h *Resolver) PrefetchBatch(hashesWithPossibleHints []string) ([][]byte, error) {
+	results := make([][]byte, len(hashesWithPossibleHints))
+
+	if h.localCAS.GetPrimaryWriterType() == "ipfs" {
+		return results, nil
+	}
+
+	if err := h.resolveBatchFromDomains(hashesWithPossibleHints, results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (h *Resolver) resolveBatchFromDomains(hashesWithPossibleHints []string, results [][]byte) error {
+	resourceHashes := make([]string, len(hashesWithPossibleHints))
+	pending := make(map[string][]int)
+
+	for i, hashWithPossibleHint := range hashesWithPossibleHints {
+		resourceHash, domain, links, err := h.getResourceHashWithPossibleDomainAndLinks(hashWithPossibleHint)
+		if err != nil {
+			return fmt.Errorf("failed to get resource hash from[%s]: %w", hashWithPossibleHint, err)
+		}
+
+		resourceHashes[i] = resourceHash
+
+		if data, e := h.localCAS.Read(resourceHash); e == nil {
+			results[i] = data
+
+			continue
+		}
+
+		if domainWithScheme := h.batchDomain(domain, links); domainWithScheme != "" {
+			pending[domainWithScheme] = append(pending[domainWithScheme], i)
+		}
+	}
+
+	for domainWithScheme, indexes := range pending {
+		h.resolveBatchFromDomain(domainWithScheme, indexes, resourceHashes, results)
+	}
+
+	return nil
+}
+
+// resolveBatchFromDomain retrieves the resources at the given indexes from the WebCAS batch endpoint of the domain
+// and stores them in the local CAS. Resources that can't be retrieved are left for individual resolution.
+func (h *Resolver) resolveBatchFromDomain(domainWithScheme string, indexes []int, resourceHashes []string,
+	results [][]byte) {
+	hashes := make([]string, len(indexes))
+
+	for i, index := range indexes {
+		hashes[i] = resourceHashes[index]
+	}
+
+	dataFromRemote, err := h.webCASResolver.ResolveBatch(domainWithScheme, hashes)
+	if err != nil {
+		logger.Debugf("Unable to retrieve %d resources in a batch from [%s]: %s", len(hashes), domainWithScheme, err)
+
+		return
+	}
+
+	for _, index := range indexes {
+		data, ok := dataFromRemote[resourceHashes[index]]
+		if !ok {
+			continue
+		}
+
+		if e := h.storeLocallyAndVerifyHash(data, resourceHashes[index]); e != nil {
+			logger.Warnf("Data for resource hash [%s] retrieved in a batch from [%s] was rejected: %s",
+				resourceHashes[index], domainWithScheme, e)
+
+			continue
+		}
+
+		results[index] = data
+	}
+
+	logger.Debugf("Retrieved %d of %d resources in a batch from [%s]", len(dataFromRemote), len(hashes),
+		domainWithScheme)
+}
+
+// batchDomain returns the scheme and host of the domain from which the resource may be retrieved in a batch,
+// or an empty string if the resource doesn't have a WebCAS link or a domain hint.
+func (h *Resolver) batchDomain(domain string, links []string) string {
+	if domain != "" {
+		return fmt.Sprintf("%s://%s", h.webCASResolver.webFingerURIScheme, domain)
+	}
+
+	webCASLinks, _ := separateLinks(links)
+
+	for _, link := range webCASLinks {
+		if u, err := url.Parse(link); err == nil && u.Host != "" {
+			return fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+		}
+	}
+
+	return ""
+}
+
+// ResolveBatch retrieves the data for the given resource hashes from the WebCAS batch endpoint that's advertised
+// by the given domain. ErrBatchNotSupported is returned if the domain doesn't advertise a batch endpoint. The
+// returned map is keyed by resource hash and doesn't contain the resources that couldn't be retrieved.
+// Note that the hashes of the returned data are not verified.
+func (w *WebCASResolver) ResolveBatch(domainWithScheme string, resourceHashes []string) (map[string][]byte, error) {
+	if w.webFingerClient == nil {
+		return nil, ErrBatchNotSupported
+	}
+
+	batchURL, err := w.webFingerClient.GetWebCASBatchURL(domainWithScheme)
+	if err != nil {
+		if errors.Is(err, model.ErrResourceNotFound) {
+			return nil, ErrBatchNotSupported
+		}
+
+		return nil, fmt.Errorf("failed to determine WebCAS batch URL via host-meta: %w", err)
+	}
+
+	results := make(map[string][]byte, len(resourceHashes))
+
+	for start := 0; start < len(resourceHashes); start += webcas.MaxBatchSize {
+		end := start + webcas.MaxBatchSize
+		if end > len(resourceHashes) {
+			end = len(resourceHashes)
+		}
+
+		if e := w.getBatch(batchURL, resourceHashes[start:end], results); e != nil {
+			// Any data received before the error is still returned.
+			logger.Warnf("Error retrieving a batch from %s: %s", batchURL, e)
+		}
+	}
+
+	return results, nil
+}
+
+// getBatch posts a batch request and adds the content in the JSON-lines response to the given results. The
+// request is cancelled after the batch timeout and the response body is limited to the maximum batch response size.
+func (w *WebCASResolver) getBatch(batchURL *url.URL, resourceHashes []string, results map[string][]byte) error {
+	reqBytes, err := json.Marshal(&webcas.BatchRequest{CIDs: resourceHashes})
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.batchTimeout)
+	defer cancel()
+
+	resp, err := w.httpClient.Post(ctx,
+		transport.NewRequest(batchURL,
+			transport.WithHeader(contentTypeHeader, "application/json"),
+			transport.WithHeader(transport.AcceptHeader, webcas.JSONLinesContentType),
+		), reqBytes)
+	if err != nil {
+		return fmt.Errorf("failed to execute POST call on %s: %w", batchURL, err)
+	}
+
+	defer closeResponseBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		responseBody, e := ioutil.ReadAll(&limitedReader{reader: resp.Body, remaining: w.maxBatchResponseSize})
+		if e != nil {
+			return fmt.Errorf("failed to read response body from remote WebCAS batch endpoint: %w", e)
+		}
+
+		return fmt.Errorf("failed to retrieve batch from %s. Response status code: %d. Response body: %s",
+			batchURL, resp.StatusCode, string(responseBody))
+	}
+
+	decoder := json.NewDecoder(&limitedReader{reader: resp.Body, remaining: w.maxBatchResponseSize})
+
+	for {
+		item := &webcas.BatchItem{}
+
+		if e := decoder.Decode(item); e != nil {
+			if errors.Is(e, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("failed to decode batch response from %s: %w", batchURL, e)
+		}
+
+		if item.Error != "" {
+			logger.Debugf("Resource hash [%s] was not returned by %s: %s", item.CID, batchURL, item.Error)
+
+			continue
+		}
+
+		results[item.CID] = item.Content
+	}
+}
+
+// limitedReader returns errBatchResponseTooLarge if more than the given number of bytes are read
+// from the underlying reader.
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, errBatchResponseTooLarge
+	}
+
+	// Allow one more byte than remaining in order to detect whether or not the limit was exceeded.
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+
+	n, err := r.reader.Read(p)
+
+	r.remaining -= int64(n)
+
+	if r.remaining < 0 {
+		return n, errBatchResponseTooLarge
+	}
+
+	return n, err
+}
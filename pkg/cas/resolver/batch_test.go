@@ -0,0 +1,446 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
+	"github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	"github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/webcas"
+	webfingerclient "github.com/trustbloc/orb/pkg/webfinger/client"
+)
+
+func TestResolver_ResolveBatch(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		remote := newRemoteServer(t, true)
+		defer remote.Close()
+
+		hl1 := remote.write(t, "content1")
+		hl2 := remote.write(t, "content2")
+		hl3 := remote.write(t, "content3")
+
+		localCAS := createInMemoryCAS(t)
+
+		// The third resource is already in the local CAS.
+		_, err := localCAS.Write([]byte("content3"))
+		require.NoError(t, err)
+
+		resolver := createNewResolver(t, localCAS, nil)
+
+		results, err := resolver.ResolveBatch([]string{hl1, hl2, hl3})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		require.Equal(t, "content1", string(results[0]))
+		require.Equal(t, "content2", string(results[1]))
+		require.Equal(t, "content3", string(results[2]))
+
+		require.Equal(t, 1, remote.count(webcas.BatchPath))
+		require.Equal(t, 0, remote.count("/cas/"))
+
+		// The data should now be in the local CAS.
+		data, err := resolver.Resolve(nil, hl1, nil)
+		require.NoError(t, err)
+		require.Equal(t, "content1", string(data))
+
+		require.Equal(t, 0, remote.count("/cas/"))
+	})
+
+	t.Run("Domain hint -> success", func(t *testing.T) {
+		remote := newRemoteServer(t, true)
+		defer remote.Close()
+
+		rh1 := remote.writeResourceHash(t, "content1")
+		rh2 := remote.writeResourceHash(t, "content2")
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+		resolver.webCASResolver.webFingerURIScheme = httpScheme
+
+		results, err := resolver.ResolveBatch([]string{remote.hint(t, rh1), remote.hint(t, rh2)})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.Equal(t, "content1", string(results[0]))
+		require.Equal(t, "content2", string(results[1]))
+
+		require.Equal(t, 1, remote.count(webcas.BatchPath))
+	})
+
+	t.Run("Batch not supported -> resolved individually", func(t *testing.T) {
+		remote := newRemoteServer(t, false)
+		defer remote.Close()
+
+		hl1 := remote.write(t, "content1")
+		hl2 := remote.write(t, "content2")
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		results, err := resolver.ResolveBatch([]string{hl1, hl2})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.Equal(t, "content1", string(results[0]))
+		require.Equal(t, "content2", string(results[1]))
+
+		require.Equal(t, 0, remote.count(webcas.BatchPath))
+		require.Equal(t, 2, remote.count("/cas/"))
+	})
+
+	t.Run("Resource not found", func(t *testing.T) {
+		remote := newRemoteServer(t, true)
+		defer remote.Close()
+
+		hl1 := remote.write(t, "content1")
+
+		hl2, err := createInMemoryCASWithLink(t, remote.URL+"/cas").Write([]byte("content2"))
+		require.NoError(t, err)
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		_, err = resolver.ResolveBatch([]string{hl1, hl2})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to retrieve data")
+
+		// The first resource should have been stored locally even though the second failed.
+		require.Equal(t, 1, remote.count(webcas.BatchPath))
+
+		data, err := resolver.Resolve(nil, hl1, nil)
+		require.NoError(t, err)
+		require.Equal(t, "content1", string(data))
+	})
+
+	t.Run("Invalid hint", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		_, err := resolver.ResolveBatch([]string{"xxx:yyy"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "hint 'xxx' not supported")
+	})
+}
+
+func TestResolver_PrefetchBatch(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		remote := newRemoteServer(t, true)
+		defer remote.Close()
+
+		hl1 := remote.write(t, "content1")
+
+		hl2, err := createInMemoryCASWithLink(t, remote.URL+"/cas").Write([]byte("content2"))
+		require.NoError(t, err)
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		results, err := resolver.PrefetchBatch([]string{hl1, hl2})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.Equal(t, "content1", string(results[0]))
+		require.Nil(t, results[1])
+
+		// The resource that wasn't in the batch must not be resolved individually.
+		require.Equal(t, 1, remote.count(webcas.BatchPath))
+		require.Equal(t, 0, remote.count("/cas/"))
+	})
+
+	t.Run("Batch not supported", func(t *testing.T) {
+		remote := newRemoteServer(t, false)
+		defer remote.Close()
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		results, err := resolver.PrefetchBatch([]string{remote.write(t, "content1")})
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{nil}, results)
+		require.Equal(t, 0, remote.count("/cas/"))
+	})
+
+	t.Run("Invalid hint", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		_, err := resolver.PrefetchBatch([]string{"xxx:yyy"})
+		require.Error(t, err)
+	})
+}
+
+func TestWebCASResolver_ResolveBatch(t *testing.T) {
+	const domain = "https://orb.domain1.com"
+
+	hostMeta := fmt.Sprintf(`{"links":[{"rel":"%s","href":"%s/cas/batch"}]}`, restapi.WebCASBatchRelation, domain)
+
+	newResolver := func(client httpClient, hostMeta string) *WebCASResolver {
+		r := NewWebCASResolver(client,
+			webfingerclient.New(webfingerclient.WithHTTPClient(
+				httpDoFunc(func(req *http.Request) (*http.Response, error) {
+					if hostMeta == "" {
+						return &http.Response{
+							StatusCode: http.StatusNotFound,
+							Body:       ioutil.NopCloser(&bytes.Buffer{}),
+						}, nil
+					}
+
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(strings.NewReader(hostMeta)),
+					}, nil
+				}),
+			)),
+			"https")
+
+		return &r
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, nil,
+				`{"cid":"cid1","content":"Y29udGVudDE="}`+"\n"+`{"cid":"cid2","error":"not found"}`+"\n", nil),
+		}}
+
+		results, err := newResolver(client, hostMeta).ResolveBatch(domain, []string{"cid1", "cid2"})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Equal(t, "content1", string(results["cid1"]))
+
+		require.Len(t, client.requests, 1)
+		require.Equal(t, domain+"/cas/batch", client.requests[0].URL.String())
+		require.Equal(t, webcas.JSONLinesContentType, client.requests[0].Header.Get("Accept"))
+	})
+
+	t.Run("Multiple batches", func(t *testing.T) {
+		resourceHashes := make([]string, webcas.MaxBatchSize+1)
+
+		for i := range resourceHashes {
+			resourceHashes[i] = fmt.Sprintf("cid%d", i)
+		}
+
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, nil, `{"cid":"cid0","content":"Y29udGVudDE="}`, nil),
+			newResponse(http.StatusOK, nil, fmt.Sprintf(`{"cid":"cid%d","content":"Y29udGVudDE="}`,
+				webcas.MaxBatchSize), nil),
+		}}
+
+		results, err := newResolver(client, hostMeta).ResolveBatch(domain, resourceHashes)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.Len(t, client.requests, 2)
+	})
+
+	t.Run("Interrupted response -> partial results", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, nil, `{"cid":"cid1","content":"Y29udGVudDE="}`+"\n"+`{"cid":"ci`,
+				errors.New("interrupted")),
+		}}
+
+		results, err := newResolver(client, hostMeta).ResolveBatch(domain, []string{"cid1", "cid2"})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+	})
+
+	t.Run("Error status -> no results", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusInternalServerError, nil, "server error", nil),
+		}}
+
+		results, err := newResolver(client, hostMeta).ResolveBatch(domain, []string{"cid1"})
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+
+	t.Run("POST error -> no results", func(t *testing.T) {
+		client := &mockHTTPClient{err: errors.New("injected POST error")}
+
+		results, err := newResolver(client, hostMeta).ResolveBatch(domain, []string{"cid1"})
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+
+	t.Run("Response too large -> partial results", func(t *testing.T) {
+		const item1 = `{"cid":"cid1","content":"Y29udGVudDE="}` + "\n"
+
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, nil, item1+`{"cid":"cid2","content":"Y29udGVudDI="}`+"\n", nil),
+		}}
+
+		r := newResolver(client, hostMeta)
+		r.maxBatchResponseSize = int64(len(item1) + 10)
+
+		results, err := r.ResolveBatch(domain, []string{"cid1", "cid2"})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Equal(t, "content1", string(results["cid1"]))
+	})
+
+	t.Run("Timeout -> no results", func(t *testing.T) {
+		var deadline time.Time
+
+		client := postFunc(func(ctx context.Context) (*http.Response, error) {
+			deadline, _ = ctx.Deadline()
+
+			<-ctx.Done()
+
+			return nil, ctx.Err()
+		})
+
+		r := newResolver(client, hostMeta)
+		r.batchTimeout = 10 * time.Millisecond
+
+		results, err := r.ResolveBatch(domain, []string{"cid1"})
+		require.NoError(t, err)
+		require.Empty(t, results)
+		require.False(t, deadline.IsZero())
+	})
+
+	t.Run("Not supported", func(t *testing.T) {
+		_, err := newResolver(&mockHTTPClient{}, `{"links":[]}`).ResolveBatch(domain, []string{"cid1"})
+		require.True(t, errors.Is(err, ErrBatchNotSupported))
+
+		_, err = newResolver(&mockHTTPClient{}, "").ResolveBatch(domain, []string{"cid1"})
+		require.True(t, errors.Is(err, ErrBatchNotSupported))
+
+		r := NewWebCASResolver(&mockHTTPClient{}, nil, "https")
+
+		_, err = r.ResolveBatch(domain, []string{"cid1"})
+		require.True(t, errors.Is(err, ErrBatchNotSupported))
+	})
+
+	t.Run("Host-meta error", func(t *testing.T) {
+		_, err := newResolver(&mockHTTPClient{}, "{").ResolveBatch(domain, []string{"cid1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to determine WebCAS batch URL via host-meta")
+	})
+}
+
+type postFunc func(ctx context.Context) (*http.Response, error)
+
+func (f postFunc) Get(ctx context.Context, _ *transport.Request) (*http.Response, error) {
+	return f(ctx)
+}
+
+func (f postFunc) Post(ctx context.Context, _ *transport.Request, _ []byte) (*http.Response, error) {
+	return f(ctx)
+}
+
+type remoteServer struct {
+	*httptest.Server
+
+	casClient extendedcasclient.Client
+	mutex     sync.Mutex
+	requests  map[string]int
+}
+
+// newRemoteServer returns a test server that acts as a remote Orb server with WebCAS, WebFinger and
+// host-meta endpoints. The WebCAS batch endpoint is only registered if batch is true.
+func newRemoteServer(t *testing.T, batch bool) *remoteServer {
+	t.Helper()
+
+	s := &remoteServer{requests: make(map[string]int)}
+
+	router := mux.NewRouter()
+
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.mutex.Lock()
+
+			if strings.HasPrefix(r.URL.Path, webcas.BatchPath) {
+				s.requests[webcas.BatchPath]++
+			} else if strings.HasPrefix(r.URL.Path, "/cas/") {
+				s.requests["/cas/"]++
+			}
+
+			s.mutex.Unlock()
+
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	s.Server = httptest.NewServer(router)
+
+	s.casClient = createInMemoryCASWithLink(t, s.URL+"/cas")
+
+	operations, err := restapi.New(&restapi.Config{BaseURL: s.URL, WebCASPath: "/cas"})
+	require.NoError(t, err)
+
+	for _, h := range operations.GetRESTHandlers() {
+		h := h
+
+		if h.Path() == restapi.HostMetaJSONEndpoint && !batch {
+			router.HandleFunc(h.Path(), func(w http.ResponseWriter, _ *http.Request) {
+				_, e := w.Write([]byte(`{"links":[]}`))
+				require.NoError(t, e)
+			})
+
+			continue
+		}
+
+		router.HandleFunc(h.Path(), h.Handler())
+	}
+
+	if batch {
+		b := webcas.NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, s.casClient)
+		router.HandleFunc(b.Path(), b.Handler()).Methods(b.Method())
+	}
+
+	webCAS := webcas.New(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, s.casClient)
+	router.HandleFunc(webCAS.Path(), webCAS.Handler()).Methods(webCAS.Method())
+
+	return s
+}
+
+func (s *remoteServer) write(t *testing.T, content string) string {
+	t.Helper()
+
+	hl, err := s.casClient.Write([]byte(content))
+	require.NoError(t, err)
+
+	return hl
+}
+
+func (s *remoteServer) writeResourceHash(t *testing.T, content string) string {
+	t.Helper()
+
+	rh, err := hashlink.GetResourceHashFromHashLink(s.write(t, content))
+	require.NoError(t, err)
+
+	return rh
+}
+
+func (s *remoteServer) hint(t *testing.T, resourceHash string) string {
+	t.Helper()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+
+	return "https:" + u.Hostname() + ":" + u.Port() + ":" + resourceHash
+}
+
+func (s *remoteServer) count(path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests[path]
+}
+
+type httpDoFunc func(req *http.Request) (*http.Response, error)
+
+func (f httpDoFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -13,9 +13,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
 	"time"
 
+	"github.com/bluele/gcache"
 	"github.com/trustbloc/edge-core/pkg/log"
 
 	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
@@ -31,54 +33,131 @@ const (
 	ipfsPrefix  = "ipfs://"
 
 	cidWithPossibleHintNumPartsWithDomainPort = 4
+
+	defaultMaxConcurrentSources = 3
+	defaultSourceTimeout        = 10 * time.Second
+	defaultUnhealthyCooldown    = time.Minute
+
+	// maxUnhealthySources is the maximum number of unhealthy sources that are tracked. Sources come from the links
+	// in remote hashlinks, so the number of tracked sources must be bounded.
+	maxUnhealthySources = 1000
+
+	maxResumeAttempts = 3
+
+	etagHeader         = "ETag"
+	acceptRangesHeader = "Accept-Ranges"
+	rangeHeader        = "Range"
+	ifRangeHeader      = "If-Range"
+	contentRangeHeader = "Content-Range"
 )
 
+var errUnexpectedRangeResponse = errors.New("unexpected response to range request")
+
 var logger = log.New("cas-resolver")
 
 type httpClient interface {
 	Get(ctx context.Context, req *transport.Request) (*http.Response, error)
+	Post(ctx context.Context, req *transport.Request, payload []byte) (*http.Response, error)
 }
 
 type metricsProvider interface {
 	CASResolveTime(value time.Duration)
+	CASResolveSourceTime(source string, success bool, value time.Duration)
 }
 
 // Resolver represents a resolver that can resolve data in a CAS based on a CID (with possible hint) and a WebCAS URL.
 type Resolver struct {
-	localCAS       extendedcasclient.Client
-	ipfsReader     ipfsReader
-	webCASResolver WebCASResolver
-	metrics        metricsProvider
-	hl             *hashlink.HashLink
+	localCAS          extendedcasclient.Client
+	ipfsReader        ipfsReader
+	ipfsGatewayReader ipfsReader
+	webCASResolver    WebCASResolver
+	metrics           metricsProvider
+	hl                *hashlink.HashLink
+
+	maxConcurrentSources int
+	sourceTimeout        time.Duration
+	unhealthyCooldown    time.Duration
+	unhealthySources     gcache.Cache
+}
+
+// Option is a resolver option.
+type Option func(r *Resolver)
+
+// WithMaxConcurrentSources sets the maximum number of sources (hashlink links) that are queried concurrently.
+func WithMaxConcurrentSources(value int) Option {
+	return func(r *Resolver) {
+		r.maxConcurrentSources = value
+	}
+}
+
+// WithSourceTimeout sets the maximum time to wait for a response from a single source.
+func WithSourceTimeout(value time.Duration) Option {
+	return func(r *Resolver) {
+		r.sourceTimeout = value
+	}
+}
+
+// WithUnhealthySourceCooldown sets the period of time during which a source that failed is skipped, unless
+// resolution fails from all other sources.
+func WithUnhealthySourceCooldown(value time.Duration) Option {
+	return func(r *Resolver) {
+		r.unhealthyCooldown = value
+	}
+}
+
+// WithIPFSGatewayReader sets a reader that retrieves IPFS content through an HTTP gateway. The gateway is only used
+// if no IPFS reader (node) was provided and the hashlink contains only IPFS links.
+func WithIPFSGatewayReader(reader ipfsReader) Option {
+	return func(r *Resolver) {
+		r.ipfsGatewayReader = reader
+	}
 }
 
 type ipfsReader interface {
 	Read(address string) ([]byte, error)
 }
 
+// resourceHashWriter is implemented by a local CAS that's able to store content under a resource hash that
+// was created with a different hash algorithm than its own.
+type resourceHashWriter interface {
+	WriteWithResourceHash(content []byte, resourceHash string) (string, error)
+}
+
 // New returns a new Resolver.
-// ipfsReader is optional. If not provided (is nil), CIDs with IPFS hints won't be resolvable.
+// ipfsReader is optional. If not provided (is nil), CIDs with IPFS hints won't be resolvable unless an IPFS gateway
+// reader is provided (see WithIPFSGatewayReader).
 func New(casClient extendedcasclient.Client, ipfsReader ipfsReader, webCASResolver WebCASResolver,
-	metrics metricsProvider) *Resolver {
-	return &Resolver{
-		localCAS:       casClient,
-		ipfsReader:     ipfsReader,
-		webCASResolver: webCASResolver,
-		metrics:        metrics,
-		hl:             hashlink.New(),
+	metrics metricsProvider, opts ...Option) *Resolver {
+	r := &Resolver{
+		localCAS:             casClient,
+		ipfsReader:           ipfsReader,
+		webCASResolver:       webCASResolver,
+		metrics:              metrics,
+		hl:                   hashlink.New(),
+		maxConcurrentSources: defaultMaxConcurrentSources,
+		sourceTimeout:        defaultSourceTimeout,
+		unhealthyCooldown:    defaultUnhealthyCooldown,
+	}
+
+	for _, opt := range opts {
+		opt(r)
 	}
+
+	r.unhealthySources = gcache.New(maxUnhealthySources).LRU().Expiration(r.unhealthyCooldown).Build()
+
+	return r
 }
 
 // Resolve does the following:
 // 1. If data is provided (not nil), then it will be stored via the local CAS. That data passed in will then simply be
 //    returned back to the caller.
 // 2. If data is not provided (is nil), then the local CAS will be checked to see if it has data at the cid provided.
-//    If it does, then it is returned. If it doesn't, and a webCASURL is provided, then the data will be retrieved by
-//    querying the webCASURL. This data will then get stored in the local CAS.
-//    Finally, the data is returned to the caller.
+//    If it does, then it is returned. If it doesn't, then the data will be retrieved from the WebCAS and IPFS links
+//    in the hashlink (or from the WebCAS of the domain in the hint). The links are queried concurrently and the first
+//    response whose hash matches is stored in the local CAS. Finally, the data is returned to the caller.
 // In both cases above, the CID produced by the local CAS will be checked against the cid passed in to ensure they are
 // the same.
-func (h *Resolver) Resolve(_ *url.URL, hashWithPossibleHint string, data []byte) ([]byte, error) { //nolint:gocyclo,cyclop,lll
+func (h *Resolver) Resolve(_ *url.URL, hashWithPossibleHint string, data []byte) ([]byte, error) {
 	startTime := time.Now()
 
 	defer func() {
@@ -110,35 +189,21 @@ func (h *Resolver) Resolve(_ *url.URL, hashWithPossibleHint string, data []byte)
 
 	// Ensure we have the data stored in the local CAS.
 	dataFromLocal, err := h.localCAS.Read(resourceHash)
-	if err != nil { //nolint: nestif // Breaking this up seems worse than leaving the nested ifs
-		if errors.Is(err, orberrors.ErrContentNotFound) {
-			if len(casLinks) > 0 {
-				// TODO: Add support for multiple links (issue-672)
-				dataFromRemote, errGetAndStoreRemoteData := h.getAndStoreDataFromWebCASEndpoint(casLinks[0], resourceHash) //nolint:lll
-				if errGetAndStoreRemoteData != nil {
-					return nil, fmt.Errorf("failure while getting and storing data from the remote "+
-						"WebCAS endpoint: %w", errGetAndStoreRemoteData)
-				}
-
-				return dataFromRemote, nil
-			}
-
-			if h.ipfsReader != nil && len(ipfsLinks) > 0 {
-				// TODO: Add support for multiple links if it makes sense for ipfs (issue-672)
-				cid := ipfsLinks[0][len(ipfsPrefix):]
-
-				return h.getAndStoreDataFromIPFS(cid, resourceHash)
-			}
-
-			if domain != "" {
-				return h.getAndStoreDataFromDomain(domain, resourceHash)
-			}
+	if err == nil {
+		return dataFromLocal, nil
+	}
+
+	if errors.Is(err, orberrors.ErrContentNotFound) {
+		if sources := h.newSources(casLinks, ipfsLinks); len(sources) > 0 {
+			return h.getAndStoreDataFromSources(sources, resourceHash)
 		}
 
-		return nil, fmt.Errorf("failed to get data stored at %s from the local CAS: %w", resourceHash, err)
+		if domain != "" {
+			return h.getAndStoreDataFromDomain(domain, resourceHash)
+		}
 	}
 
-	return dataFromLocal, nil
+	return nil, fmt.Errorf("failed to get data stored at %s from the local CAS: %w", resourceHash, err)
 }
 
 func (h *Resolver) getResourceHashWithPossibleDomainAndLinks(hashWithPossibleHint string) (string, string, []string, error) { //nolint:lll
@@ -217,43 +282,23 @@ func (h *Resolver) getAndStoreDataFromDomain(domain, resourceHash string) ([]byt
 	return dataFromRemote, nil
 }
 
-func (h *Resolver) getAndStoreDataFromWebCASEndpoint(webCASEndpoint, cid string) ([]byte, error) {
-	webCASEndpointLink, err := url.Parse(webCASEndpoint)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse webcas endpoint: %w", err)
-	}
-
-	dataFromRemote, err := h.webCASResolver.GetDataViaWebCASEndpoint(webCASEndpointLink)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get data via WebCAS endpoint: %w", err)
-	}
-
-	errStoreLocallyAndVerifyCID := h.storeLocallyAndVerifyHash(dataFromRemote, cid)
-	if errStoreLocallyAndVerifyCID != nil {
-		return nil, fmt.Errorf("failure while storing data retrieved from the remote "+
-			"WebCAS endpoint locally: %w", errStoreLocallyAndVerifyCID)
+func (h *Resolver) storeLocallyAndVerifyHash(data []byte, resourceHash string) error {
+	if err := h.hl.VerifyResourceHash(resourceHash, data); err != nil {
+		return &hashMismatchError{resourceHash: resourceHash, err: err}
 	}
 
-	return dataFromRemote, nil
-}
+	var newHLFromLocalCAS string
 
-func (h *Resolver) getAndStoreDataFromIPFS(cid, resourceHash string) ([]byte, error) {
-	resp, err := h.ipfsReader.Read(cid)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read cid[%s] from ipfs: %w", cid, err)
-	}
+	var err error
 
-	err = h.storeLocallyAndVerifyHash(resp, resourceHash)
-	if err != nil {
-		return nil, fmt.Errorf("failure while storing data retrieved from the ipfs: %w",
-			err)
+	// Store the content under the requested resource hash so that it may subsequently be read from the local CAS
+	// using the same resource hash, which is not the case if the requested resource hash used a different algorithm.
+	if w, ok := h.localCAS.(resourceHashWriter); ok {
+		newHLFromLocalCAS, err = w.WriteWithResourceHash(data, resourceHash)
+	} else {
+		newHLFromLocalCAS, err = h.localCAS.Write(data)
 	}
 
-	return resp, nil
-}
-
-func (h *Resolver) storeLocallyAndVerifyHash(data []byte, resourceHash string) error {
-	newHLFromLocalCAS, err := h.localCAS.Write(data)
 	if err != nil {
 		return fmt.Errorf("failed to write data to CAS "+
 			"(and calculate CID in the process of doing so): %w", err)
@@ -263,26 +308,31 @@ func (h *Resolver) storeLocallyAndVerifyHash(data []byte, resourceHash string) e
 		"resource hash as determined by local store [%s], Data: %s", resourceHash, newHLFromLocalCAS,
 		string(data))
 
-	newResourceHash, err := hashlink.GetResourceHashFromHashLink(newHLFromLocalCAS)
-	if err != nil {
-		return fmt.Errorf("failed to write data to CAS "+
-			"(and get resource hash in the process of doing so): %w", err)
-	}
+	return nil
+}
 
-	if newResourceHash != resourceHash {
-		return fmt.Errorf("successfully stored data into the local CAS, but the resource hash produced by "+
-			"the local CAS (%s) does not match the resource hash from the original request (%s)",
-			newResourceHash, resourceHash)
-	}
+// hashMismatchError indicates that the data could not be verified against the requested resource hash.
+type hashMismatchError struct {
+	resourceHash string
+	err          error
+}
 
-	return nil
+func (e *hashMismatchError) Error() string {
+	return fmt.Sprintf("the data does not match the resource hash from the original request (%s): %s",
+		e.resourceHash, e.err)
+}
+
+func (e *hashMismatchError) Unwrap() error {
+	return e.err
 }
 
 // WebCASResolver is used to resolve data from another Orb server's CAS.
 type WebCASResolver struct {
-	httpClient         httpClient
-	webFingerClient    *webfingerclient.Client
-	webFingerURIScheme string
+	httpClient           httpClient
+	webFingerClient      *webfingerclient.Client
+	webFingerURIScheme   string
+	batchTimeout         time.Duration
+	maxBatchResponseSize int64
 }
 
 // NewWebCASResolver returns a new WebCASResolver.
@@ -290,6 +340,7 @@ func NewWebCASResolver(httpClient httpClient, webFingerClient *webfingerclient.C
 	webFingerURIScheme string) WebCASResolver {
 	return WebCASResolver{
 		httpClient: httpClient, webFingerClient: webFingerClient, webFingerURIScheme: webFingerURIScheme,
+		batchTimeout: defaultBatchTimeout, maxBatchResponseSize: defaultMaxBatchResponseSize,
 	}
 }
 
@@ -315,28 +366,115 @@ func (w *WebCASResolver) Resolve(domain, cid string) ([]byte, error) {
 
 // GetDataViaWebCASEndpoint retrieves data from the given webCASEndpoint and returns it.
 func (w *WebCASResolver) GetDataViaWebCASEndpoint(webCASEndpoint *url.URL) ([]byte, error) {
-	resp, err := w.httpClient.Get(context.Background(), transport.NewRequest(webCASEndpoint,
-		transport.WithHeader(transport.AcceptHeader, transport.LDPlusJSONContentType)))
+	return w.getDataViaWebCASEndpoint(context.Background(), webCASEndpoint)
+}
+
+// getDataViaWebCASEndpoint retrieves the data from the given WebCAS endpoint. If the response body is interrupted,
+// the endpoint supports byte ranges and the endpoint returned a strong ETag matching the resource hash in the
+// endpoint URL, then the remaining data is requested using a conditional (If-Range) range request. A weak or
+// mismatched ETag is ignored (the content is verified against the requested hash by the caller anyway).
+// Note that If-None-Match isn't sent since the content is only requested when it isn't available locally.
+func (w *WebCASResolver) getDataViaWebCASEndpoint(ctx context.Context, webCASEndpoint *url.URL) ([]byte, error) {
+	resp, err := w.httpClient.Get(ctx, newWebCASRequest(webCASEndpoint))
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute GET call on %s: %w", webCASEndpoint.String(), err)
 	}
 
-	defer func() {
-		errClose := resp.Body.Close()
-		if errClose != nil {
-			logger.Errorf("failed to close response body from WebCAS endpoint: %s", errClose.Error())
+	defer closeResponseBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		responseBody, e := ioutil.ReadAll(resp.Body)
+		if e != nil {
+			return nil, fmt.Errorf("failed to read response body from remote WebCAS endpoint: %w", e)
 		}
-	}()
+
+		return nil, fmt.Errorf("failed to retrieve data from %s. Response status code: %d. Response body: %s",
+			webCASEndpoint.String(), resp.StatusCode, string(responseBody))
+	}
+
+	etag := resp.Header.Get(etagHeader)
+
+	if resourceHash := path.Base(webCASEndpoint.Path); etag != "" && etag != fmt.Sprintf("%q", resourceHash) {
+		logger.Debugf("Ignoring ETag %s returned by %s since it isn't a strong ETag for resource hash [%s]",
+			etag, webCASEndpoint, resourceHash)
+
+		etag = ""
+	}
 
 	responseBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body from remote WebCAS endpoint: %w", err)
-	}
+		if etag == "" || resp.Header.Get(acceptRangesHeader) != "bytes" {
+			return nil, fmt.Errorf("failed to read response body from remote WebCAS endpoint: %w", err)
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to retrieve data from %s. Response status code: %d. Response body: %s",
-			webCASEndpoint.String(), resp.StatusCode, string(responseBody))
+		return w.resume(ctx, webCASEndpoint, etag, responseBody, err)
 	}
 
 	return responseBody, nil
 }
+
+// resume requests the remainder of the data (starting after the data that was already received) using a range
+// request. The If-Range header ensures that the full content is returned if, for whatever reason, the ETag no
+// longer matches.
+func (w *WebCASResolver) resume(ctx context.Context, webCASEndpoint *url.URL, etag string, data []byte,
+	readErr error) ([]byte, error) {
+	for attempt := 1; attempt <= maxResumeAttempts; attempt++ {
+		logger.Debugf("Response body from [%s] was interrupted after %d bytes: %s. Resuming (attempt %d of %d)",
+			webCASEndpoint, len(data), readErr, attempt, maxResumeAttempts)
+
+		resp, err := w.httpClient.Get(ctx, newWebCASRequest(webCASEndpoint,
+			transport.WithHeader(rangeHeader, fmt.Sprintf("bytes=%d-", len(data))),
+			transport.WithHeader(ifRangeHeader, etag)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute range request on %s: %w", webCASEndpoint, err)
+		}
+
+		data, readErr = readRemaining(resp, data)
+
+		closeResponseBody(resp)
+
+		if readErr == nil {
+			return data, nil
+		}
+
+		if errors.Is(readErr, errUnexpectedRangeResponse) {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("failed to read response body from remote WebCAS endpoint: %w", readErr)
+}
+
+// readRemaining appends the content of a partial response to the given data or, if the full content
+// was returned, replaces the data with the full content. The data that was read is returned along with
+// any error.
+func readRemaining(resp *http.Response, data []byte) ([]byte, error) {
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if !strings.HasPrefix(resp.Header.Get(contentRangeHeader), fmt.Sprintf("bytes %d-", len(data))) {
+			return data, fmt.Errorf("%w: Content-Range [%s]", errUnexpectedRangeResponse,
+				resp.Header.Get(contentRangeHeader))
+		}
+
+		remaining, err := ioutil.ReadAll(resp.Body)
+
+		return append(data, remaining...), err
+	case http.StatusOK:
+		return ioutil.ReadAll(resp.Body)
+	default:
+		return data, fmt.Errorf("%w: status code %d", errUnexpectedRangeResponse, resp.StatusCode)
+	}
+}
+
+func newWebCASRequest(webCASEndpoint *url.URL, opts ...transport.Option) *transport.Request {
+	return transport.NewRequest(webCASEndpoint,
+		append([]transport.Option{transport.WithHeader(transport.AcceptHeader, transport.LDPlusJSONContentType)},
+			opts...)...,
+	)
+}
+
+func closeResponseBody(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		logger.Errorf("failed to close response body from WebCAS endpoint: %s", err.Error())
+	}
+}
@@ -7,19 +7,26 @@ SPDX-License-Identifier: Apache-2.0
 package resolver
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mock"
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
@@ -38,6 +45,8 @@ import (
 	webfingerclient "github.com/trustbloc/orb/pkg/webfinger/client"
 )
 
+//go:generate counterfeiter -o ../../activitypub/mocks/httptransport.gen.go --fake-name HTTPTransport . httpClient
+
 const (
 	sampleData = `{
   "@context": [
@@ -302,12 +311,55 @@ func TestResolver_Resolve(t *testing.T) {
 		cid := "bafkrwihwsnuregfeqh263vgdathcprnbvatyat6h6mu7ipjhhodcdbyhoy" // Not a match
 
 		data, err := resolver.Resolve(nil, cid, []byte(sampleData))
-		require.EqualError(t, err, "failed to store the data in the local CAS: "+
-			"successfully stored data into the local CAS, but the resource hash produced by the local CAS "+
-			"(uEiA1t3VICW2jRlU-m3o7-3f1lI5hbLTrPkefScZMEFwbEQ) does not match the resource hash from the original request "+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to store the data in the local CAS: "+
+			"the data does not match the resource hash from the original request "+
 			"(bafkrwihwsnuregfeqh263vgdathcprnbvatyat6h6mu7ipjhhodcdbyhoy)")
 		require.Nil(t, data)
 	})
+	t.Run("Resource hash doesn't match the provided data", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		rh, err := hashlink.New().CreateResourceHash([]byte("other data"))
+		require.NoError(t, err)
+
+		data, err := resolver.Resolve(nil, rh, []byte(sampleData))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, hashlink.ErrHashMismatch))
+		require.Nil(t, data)
+	})
+	t.Run("Resource hash using a different algorithm -> success", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		rh, err := hashlink.New(hashlink.WithMultihashCode(multihash.SHA2_512)).
+			CreateResourceHash([]byte(sampleData))
+		require.NoError(t, err)
+
+		data, err := resolver.Resolve(nil, rh, []byte(sampleData))
+		require.NoError(t, err)
+		require.Equal(t, sampleData, string(data))
+	})
+	t.Run("Resource hash using a different algorithm -> stored under requested hash", func(t *testing.T) {
+		localCAS := createInMemoryCAS(t)
+
+		resolver := createNewResolver(t, localCAS, nil)
+
+		rh, err := hashlink.New(hashlink.WithMultihashCode(multihash.SHA2_512)).
+			CreateResourceHash([]byte(sampleData))
+		require.NoError(t, err)
+
+		_, err = resolver.Resolve(nil, rh, []byte(sampleData))
+		require.NoError(t, err)
+
+		// The content is available locally under the requested resource hash.
+		data, err := localCAS.Read(rh)
+		require.NoError(t, err)
+		require.Equal(t, sampleData, string(data))
+
+		data, err = resolver.Resolve(nil, rh, nil)
+		require.NoError(t, err)
+		require.Equal(t, sampleData, string(data))
+	})
 	t.Run("Neither local nor remote CAS has the data", func(t *testing.T) {
 		webCAS := webcas.New(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, createInMemoryCAS(t))
 		require.NotNil(t, webCAS)
@@ -567,7 +619,377 @@ func TestResolver_Resolve(t *testing.T) {
 	})
 }
 
-func createNewResolver(t *testing.T, casClient extendedcasclient.Client, ipfsReader ipfsReader) *Resolver {
+func TestResolver_ResolveFromMultipleSources(t *testing.T) {
+	rh, err := hashlink.New().CreateResourceHash([]byte(sampleData))
+	require.NoError(t, err)
+
+	goodServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, sampleData)
+	}))
+	defer goodServer.Close()
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failingServer.Close()
+
+	badDataServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "invalid data")
+	}))
+	defer badDataServer.Close()
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+
+		fmt.Fprint(w, sampleData)
+	}))
+	defer slowServer.Close()
+
+	newHashLink := func(servers ...*httptest.Server) string {
+		links := make([]string, len(servers))
+
+		for i, s := range servers {
+			links[i] = fmt.Sprintf("%s/cas/%s", s.URL, rh)
+		}
+
+		md, e := hashlink.New().CreateMetadataFromLinks(links)
+		require.NoError(t, e)
+
+		return hashlink.GetHashLink(rh, md)
+	}
+
+	t.Run("First source fails -> success from second source", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		data, err := resolver.Resolve(nil, newHashLink(failingServer, goodServer), nil)
+		require.NoError(t, err)
+		require.Equal(t, sampleData, string(data))
+
+		healthy, unhealthy := resolver.partitionSources(resolver.newSources(
+			[]string{failingServer.URL, goodServer.URL}, nil))
+		require.Len(t, healthy, 1)
+		require.Equal(t, goodServer.URL, healthy[0].link)
+		require.Len(t, unhealthy, 1)
+		require.Equal(t, failingServer.URL, unhealthy[0].link)
+	})
+
+	t.Run("Hash mismatch from first source -> success from second source", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithMaxConcurrentSources(1))
+
+		data, err := resolver.Resolve(nil, newHashLink(badDataServer, goodServer), nil)
+		require.NoError(t, err)
+		require.Equal(t, sampleData, string(data))
+	})
+
+	t.Run("Slow source -> success from faster source", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		data, err := resolver.Resolve(nil, newHashLink(slowServer, goodServer), nil)
+		require.NoError(t, err)
+		require.Equal(t, sampleData, string(data))
+	})
+
+	t.Run("Source timeout", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithSourceTimeout(50*time.Millisecond))
+
+		data, err := resolver.Resolve(nil, newHashLink(slowServer), nil)
+		require.Error(t, err)
+		require.Nil(t, data)
+		require.Contains(t, err.Error(), "no response from")
+		require.True(t, orberrors.IsTransient(err))
+	})
+
+	t.Run("Unhealthy source is tried if all healthy sources fail", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		resolver.setUnhealthy(resolver.newWebCASSource(goodServer.URL))
+
+		data, err := resolver.Resolve(nil, newHashLink(failingServer, goodServer), nil)
+		require.NoError(t, err)
+		require.Equal(t, sampleData, string(data))
+
+		// The source is healthy again after a successful response.
+		healthy, unhealthy := resolver.partitionSources(resolver.newSources([]string{goodServer.URL}, nil))
+		require.Len(t, healthy, 1)
+		require.Empty(t, unhealthy)
+	})
+
+	t.Run("Unhealthy source cooldown expired", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithUnhealthySourceCooldown(time.Millisecond))
+
+		resolver.setUnhealthy(resolver.newWebCASSource(failingServer.URL))
+
+		time.Sleep(5 * time.Millisecond)
+
+		healthy, unhealthy := resolver.partitionSources(resolver.newSources([]string{failingServer.URL}, nil))
+		require.Len(t, healthy, 1)
+		require.Empty(t, unhealthy)
+	})
+
+	t.Run("Unhealthy sources are bounded", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		for i := 0; i < maxUnhealthySources+10; i++ {
+			resolver.setUnhealthy(resolver.newWebCASSource(fmt.Sprintf("https://domain%d.com/cas/%s", i, rh)))
+		}
+
+		require.Equal(t, maxUnhealthySources, resolver.unhealthySources.Len(false))
+	})
+
+	t.Run("Source metrics use the source type as the label", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		metrics := &sourceMetrics{}
+		resolver.metrics = metrics
+
+		data, err := resolver.Resolve(nil, newHashLink(failingServer, goodServer), nil)
+		require.NoError(t, err)
+		require.Equal(t, sampleData, string(data))
+
+		require.NotEmpty(t, metrics.getSources())
+
+		for _, src := range metrics.getSources() {
+			require.Equal(t, webCASSourceName, src)
+		}
+	})
+
+	t.Run("All sources fail", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		data, err := resolver.Resolve(nil, newHashLink(failingServer, badDataServer), nil)
+		require.Error(t, err)
+		require.Nil(t, data)
+		require.Contains(t, err.Error(), "failed to retrieve data from all 2 sources")
+		require.Contains(t, err.Error(), "Response status code: 500")
+		require.Contains(t, err.Error(), "does not match the resource hash from the original request")
+	})
+
+	t.Run("WebCAS and IPFS sources", func(t *testing.T) {
+		ipfsClient := ipfs.New(goodServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+
+		hl, err := hashlink.New().CreateHashLink([]byte(sampleData),
+			[]string{fmt.Sprintf("%s/cas/%s", failingServer.URL, rh), "ipfs://" + sampleDataCIDv1})
+		require.NoError(t, err)
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), ipfsClient)
+
+		data, err := resolver.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, sampleData, string(data))
+	})
+
+	t.Run("IPFS gateway - only IPFS links", func(t *testing.T) {
+		gateway := ipfs.NewGatewayReader(goodServer.URL, http.DefaultClient, 5*time.Second, 0, 0,
+			&orbmocks.MetricsProvider{})
+
+		hl, err := hashlink.New().CreateHashLink([]byte(sampleData), []string{"ipfs://" + sampleDataCIDv1})
+		require.NoError(t, err)
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithIPFSGatewayReader(gateway))
+
+		data, err := resolver.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, sampleData, string(data))
+	})
+
+	t.Run("IPFS gateway - not used with WebCAS links", func(t *testing.T) {
+		gateway := ipfs.NewGatewayReader(goodServer.URL, http.DefaultClient, 5*time.Second, 0, 0,
+			&orbmocks.MetricsProvider{})
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithIPFSGatewayReader(gateway))
+
+		sources := resolver.newSources([]string{goodServer.URL}, []string{"ipfs://" + sampleDataCIDv1})
+		require.Len(t, sources, 1)
+		require.Equal(t, goodServer.URL, sources[0].link)
+	})
+
+	t.Run("IPFS gateway - not used with IPFS node", func(t *testing.T) {
+		ipfsClient := ipfs.New(goodServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		gateway := ipfs.NewGatewayReader(goodServer.URL, http.DefaultClient, 5*time.Second, 0, 0,
+			&orbmocks.MetricsProvider{})
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), ipfsClient, WithIPFSGatewayReader(gateway))
+
+		sources := resolver.newSources(nil, []string{"ipfs://" + sampleDataCIDv1})
+		require.Len(t, sources, 1)
+		require.Equal(t, ipfsSourceName, sources[0].name)
+	})
+
+	t.Run("Invalid WebCAS link", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+		result := resolver.fetch(context.Background(), resolver.newWebCASSource("http://%"))
+		require.Error(t, result.err)
+		require.Contains(t, result.err.Error(), "failed to parse webcas endpoint")
+	})
+}
+
+func TestWebCASResolver_GetDataViaWebCASEndpoint(t *testing.T) {
+	const (
+		resourceHash = "uEiAPnWB5kdFlN1rsDCy2WEm8hXrPjbNnDt2lZjcsBtpqjA"
+		content      = "some content"
+		etag         = `"` + resourceHash + `"`
+	)
+
+	endpoint, err := url.Parse("https://orb.domain1.com/cas/" + resourceHash)
+	require.NoError(t, err)
+
+	getData := func(client httpClient) ([]byte, error) {
+		r := NewWebCASResolver(client, nil, "https")
+
+		return r.GetDataViaWebCASEndpoint(endpoint)
+	}
+
+	rangeHeaders := http.Header{etagHeader: []string{etag}, acceptRangesHeader: []string{"bytes"}}
+
+	t.Run("Matching ETag -> success", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{newResponse(http.StatusOK, rangeHeaders, content, nil)}}
+
+		data, err := getData(client)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+
+	t.Run("ETag mismatch -> ignored", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, http.Header{etagHeader: []string{`"xxx"`}}, content, nil),
+		}}
+
+		data, err := getData(client)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+
+	t.Run("Weak ETag -> ignored", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, http.Header{etagHeader: []string{"W/" + etag}}, content, nil),
+		}}
+
+		data, err := getData(client)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+
+	t.Run("Interrupted with weak ETag -> not resumed", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, http.Header{
+				etagHeader:         []string{"W/" + etag},
+				acceptRangesHeader: []string{"bytes"},
+			}, content[:4], errors.New("interrupted")),
+		}}
+
+		_, err := getData(client)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "interrupted")
+		require.Len(t, client.requests, 1)
+	})
+
+	t.Run("Interrupted -> resumed with partial content", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, rangeHeaders, content[:4], errors.New("interrupted")),
+			newResponse(http.StatusPartialContent,
+				http.Header{contentRangeHeader: []string{"bytes 4-11/12"}}, content[4:8], errors.New("interrupted")),
+			newResponse(http.StatusPartialContent,
+				http.Header{contentRangeHeader: []string{"bytes 8-11/12"}}, content[8:], nil),
+		}}
+
+		data, err := getData(client)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+
+		require.Len(t, client.requests, 3)
+		require.Empty(t, client.requests[0].Header.Get(rangeHeader))
+		require.Equal(t, "bytes=4-", client.requests[1].Header.Get(rangeHeader))
+		require.Equal(t, etag, client.requests[1].Header.Get(ifRangeHeader))
+		require.Equal(t, "bytes=8-", client.requests[2].Header.Get(rangeHeader))
+	})
+
+	t.Run("Interrupted -> resumed with full content", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, rangeHeaders, content[:4], errors.New("interrupted")),
+			newResponse(http.StatusOK, rangeHeaders, content, nil),
+		}}
+
+		data, err := getData(client)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+
+	t.Run("Interrupted without range support -> error", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, http.Header{etagHeader: []string{etag}}, content[:4], errors.New("interrupted")),
+		}}
+
+		_, err := getData(client)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "interrupted")
+		require.Len(t, client.requests, 1)
+	})
+
+	t.Run("Unexpected Content-Range -> error", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, rangeHeaders, content[:4], errors.New("interrupted")),
+			newResponse(http.StatusPartialContent,
+				http.Header{contentRangeHeader: []string{"bytes 0-11/12"}}, content, nil),
+		}}
+
+		_, err := getData(client)
+		require.True(t, errors.Is(err, errUnexpectedRangeResponse))
+	})
+
+	t.Run("Range not satisfiable -> error", func(t *testing.T) {
+		client := &mockHTTPClient{responses: []*http.Response{
+			newResponse(http.StatusOK, rangeHeaders, content[:4], errors.New("interrupted")),
+			newResponse(http.StatusRequestedRangeNotSatisfiable, nil, "", nil),
+		}}
+
+		_, err := getData(client)
+		require.True(t, errors.Is(err, errUnexpectedRangeResponse))
+	})
+
+	t.Run("Range request error -> error", func(t *testing.T) {
+		client := &mockHTTPClient{
+			responses: []*http.Response{newResponse(http.StatusOK, rangeHeaders, content[:4], errors.New("interrupted"))},
+			err:       errors.New("injected GET error"),
+		}
+
+		_, err := getData(client)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected GET error")
+	})
+
+	t.Run("Too many interruptions -> error", func(t *testing.T) {
+		responses := []*http.Response{newResponse(http.StatusOK, rangeHeaders, "", errors.New("interrupted"))}
+
+		for i := 0; i < maxResumeAttempts; i++ {
+			responses = append(responses, newResponse(http.StatusOK, rangeHeaders, "", errors.New("interrupted")))
+		}
+
+		client := &mockHTTPClient{responses: responses}
+
+		_, err := getData(client)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "interrupted")
+		require.Len(t, client.requests, maxResumeAttempts+1)
+	})
+}
+
+func TestCombineErrors(t *testing.T) {
+	errTransient := orberrors.NewTransient(errors.New("transient error"))
+	errPersistent := errors.New("persistent error")
+
+	require.Equal(t, errPersistent, combineErrors([]error{errPersistent}))
+
+	err := combineErrors([]error{errPersistent, errTransient})
+	require.EqualError(t, err, "failed to retrieve data from all 2 sources: persistent error; transient error")
+	require.True(t, orberrors.IsTransient(err))
+
+	err = combineErrors([]error{errPersistent, errPersistent})
+	require.False(t, orberrors.IsTransient(err))
+}
+
+func createNewResolver(t *testing.T, casClient extendedcasclient.Client, ipfsReader ipfsReader,
+	opts ...Option) *Resolver {
 	t.Helper()
 
 	webFingerResolver := webfingerclient.New()
@@ -579,7 +1001,7 @@ func createNewResolver(t *testing.T, casClient extendedcasclient.Client, ipfsRea
 		webFingerResolver,
 		"http")
 
-	casResolver := New(casClient, ipfsReader, webCASResolver, &orbmocks.MetricsProvider{})
+	casResolver := New(casClient, ipfsReader, webCASResolver, &orbmocks.MetricsProvider{}, opts...)
 	require.NotNil(t, casResolver)
 
 	return casResolver
@@ -599,3 +1021,63 @@ func createInMemoryCASWithLink(t *testing.T, casLink string) extendedcasclient.C
 
 	return casClient
 }
+
+type mockHTTPClient struct {
+	responses []*http.Response
+	requests  []*transport.Request
+	err       error
+}
+
+// Get returns the next configured response. Once all of the responses have been returned, err is returned.
+func (m *mockHTTPClient) Get(_ context.Context, req *transport.Request) (*http.Response, error) {
+	m.requests = append(m.requests, req)
+
+	if len(m.requests) > len(m.responses) {
+		return nil, m.err
+	}
+
+	return m.responses[len(m.requests)-1], nil
+}
+
+// Post returns the next configured response, in the same way as Get.
+func (m *mockHTTPClient) Post(ctx context.Context, req *transport.Request, _ []byte) (*http.Response, error) {
+	return m.Get(ctx, req)
+}
+
+// newResponse returns a response whose body returns the given content followed by the given read error (if any).
+func newResponse(status int, header http.Header, content string, readErr error) *http.Response {
+	h := http.Header{}
+
+	for name, values := range header {
+		h.Set(name, values[0])
+	}
+
+	var body io.Reader = strings.NewReader(content)
+
+	if readErr != nil {
+		body = io.MultiReader(body, iotest.ErrReader(readErr))
+	}
+
+	return &http.Response{StatusCode: status, Header: h, Body: ioutil.NopCloser(body)}
+}
+
+type sourceMetrics struct {
+	orbmocks.MetricsProvider
+
+	mutex   sync.Mutex
+	sources []string
+}
+
+func (m *sourceMetrics) CASResolveSourceTime(source string, _ bool, _ time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.sources = append(m.sources, source)
+}
+
+func (m *sourceMetrics) getSources() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.sources
+}
@@ -0,0 +1,298 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	webCASSourceName      = "webcas"
+	ipfsSourceName        = "ipfs"
+	ipfsGatewaySourceName = "ipfs-gateway"
+)
+
+// source is a remote location (hashlink link) from which data may be retrieved.
+type source struct {
+	// name is the type of the source (webcas, ipfs or ipfs-gateway) which is used as the metrics label.
+	name string
+	// healthKey identifies the source for health tracking (the host of a WebCAS link or the name of an IPFS source).
+	healthKey  string
+	link       string
+	fetch      func(ctx context.Context) ([]byte, error)
+	storeError func(err error) error
+}
+
+type sourceResult struct {
+	source   *source
+	data     []byte
+	err      error
+	duration time.Duration
+}
+
+func (h *Resolver) newSources(webCASLinks, ipfsLinks []string) []*source {
+	var sources []*source
+
+	for _, link := range webCASLinks {
+		sources = append(sources, h.newWebCASSource(link))
+	}
+
+	switch {
+	case h.ipfsReader != nil:
+		for _, link := range ipfsLinks {
+			sources = append(sources, h.newIPFSSource(ipfsSourceName, h.ipfsReader, link))
+		}
+	case h.ipfsGatewayReader != nil && len(webCASLinks) == 0:
+		// Without an IPFS node, the (typically slower) gateway is only used if there's no other way to get the data.
+		for _, link := range ipfsLinks {
+			sources = append(sources, h.newIPFSSource(ipfsGatewaySourceName, h.ipfsGatewayReader, link))
+		}
+	}
+
+	return sources
+}
+
+func (h *Resolver) newWebCASSource(link string) *source {
+	healthKey := link
+
+	if u, err := url.Parse(link); err == nil && u.Host != "" {
+		healthKey = u.Host
+	}
+
+	return &source{
+		name:      webCASSourceName,
+		healthKey: healthKey,
+		link:      link,
+		fetch: func(ctx context.Context) ([]byte, error) {
+			webCASEndpoint, err := url.Parse(link)
+			if err != nil {
+				return nil, newWebCASError(fmt.Errorf("failed to parse webcas endpoint: %w", err))
+			}
+
+			data, err := h.webCASResolver.getDataViaWebCASEndpoint(ctx, webCASEndpoint)
+			if err != nil {
+				return nil, newWebCASError(fmt.Errorf("failed to get data via WebCAS endpoint: %w", err))
+			}
+
+			return data, nil
+		},
+		storeError: func(err error) error {
+			return newWebCASError(fmt.Errorf("failure while storing data retrieved from the remote "+
+				"WebCAS endpoint locally: %w", err))
+		},
+	}
+}
+
+func (h *Resolver) newIPFSSource(name string, reader ipfsReader, link string) *source {
+	cid := link[len(ipfsPrefix):]
+
+	return &source{
+		name:      name,
+		healthKey: name,
+		link:      link,
+		fetch: func(context.Context) ([]byte, error) {
+			data, err := reader.Read(cid)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read cid[%s] from ipfs: %w", cid, err)
+			}
+
+			return data, nil
+		},
+		storeError: func(err error) error {
+			return fmt.Errorf("failure while storing data retrieved from the ipfs: %w", err)
+		},
+	}
+}
+
+// getAndStoreDataFromSources retrieves the data from the given sources and stores it in the local CAS. Sources
+// that recently failed are only queried if the data could not be retrieved from any of the healthy sources.
+func (h *Resolver) getAndStoreDataFromSources(sources []*source, resourceHash string) ([]byte, error) {
+	healthy, unhealthy := h.partitionSources(sources)
+
+	var errs []error
+
+	for _, group := range [][]*source{healthy, unhealthy} {
+		if len(group) == 0 {
+			continue
+		}
+
+		data, groupErrs, err := h.race(group, resourceHash)
+		if err != nil {
+			return nil, err
+		}
+
+		if data != nil {
+			return data, nil
+		}
+
+		errs = append(errs, groupErrs...)
+	}
+
+	return nil, combineErrors(errs)
+}
+
+// race queries the given sources concurrently (up to the maximum number of concurrent sources) and stores the first
+// response whose hash matches the resource hash. If none of the sources return valid data then the errors from
+// all of the sources are returned. An error is returned (as the last return value) only if the data could not be
+// stored in the local CAS, in which case there's no point in querying the remaining sources.
+func (h *Resolver) race(sources []*source, resourceHash string) ([]byte, []error, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	maxConcurrent := h.maxConcurrentSources
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+
+	results := make(chan *sourceResult, len(sources))
+	semaphore := make(chan struct{}, maxConcurrent)
+
+	go func() {
+		for _, src := range sources {
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			go func(src *source) {
+				defer func() { <-semaphore }()
+
+				results <- h.fetch(ctx, src)
+			}(src)
+		}
+	}()
+
+	var errs []error
+
+	for range sources {
+		r := <-results
+
+		if r.err == nil {
+			if err := h.storeLocallyAndVerifyHash(r.data, resourceHash); err != nil {
+				var mismatchErr *hashMismatchError
+				if !errors.As(err, &mismatchErr) {
+					return nil, nil, r.source.storeError(err)
+				}
+
+				r.err = r.source.storeError(err)
+			}
+		}
+
+		h.metrics.CASResolveSourceTime(r.source.name, r.err == nil, r.duration)
+
+		if r.err != nil {
+			logger.Debugf("failed to retrieve resource hash[%s] from [%s]: %s", resourceHash, r.source.link, r.err)
+
+			h.setUnhealthy(r.source)
+
+			errs = append(errs, r.err)
+
+			continue
+		}
+
+		h.setHealthy(r.source)
+
+		logger.Debugf("successfully retrieved data for resource hash[%s] from [%s] in %s",
+			resourceHash, r.source.link, r.duration)
+
+		return r.data, nil, nil
+	}
+
+	return nil, errs, nil
+}
+
+// fetch retrieves the data from the given source, giving up after the source timeout.
+func (h *Resolver) fetch(ctx context.Context, src *source) *sourceResult {
+	startTime := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, h.sourceTimeout)
+	defer cancel()
+
+	resultChan := make(chan *sourceResult, 1)
+
+	go func() {
+		data, err := src.fetch(ctx)
+
+		resultChan <- &sourceResult{source: src, data: data, err: err}
+	}()
+
+	select {
+	case r := <-resultChan:
+		r.duration = time.Since(startTime)
+
+		return r
+	case <-ctx.Done():
+		return &sourceResult{
+			source:   src,
+			err:      orberrors.NewTransient(fmt.Errorf("no response from [%s]: %w", src.link, ctx.Err())),
+			duration: time.Since(startTime),
+		}
+	}
+}
+
+func (h *Resolver) partitionSources(sources []*source) (healthy, unhealthy []*source) {
+	for _, src := range sources {
+		if _, err := h.unhealthySources.GetIFPresent(src.healthKey); err == nil {
+			unhealthy = append(unhealthy, src)
+
+			continue
+		}
+
+		healthy = append(healthy, src)
+	}
+
+	return healthy, unhealthy
+}
+
+func (h *Resolver) setUnhealthy(src *source) {
+	if err := h.unhealthySources.Set(src.healthKey, struct{}{}); err != nil {
+		logger.Warnf("Failed to mark source [%s] as unhealthy: %s", src.healthKey, err)
+	}
+}
+
+func (h *Resolver) setHealthy(src *source) {
+	h.unhealthySources.Remove(src.healthKey)
+}
+
+func newWebCASError(err error) error {
+	return fmt.Errorf("failure while getting and storing data from the remote WebCAS endpoint: %w", err)
+}
+
+// combineErrors returns a single error from the given errors. The returned error is transient
+// if any of the errors is transient.
+func combineErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	msgs := make([]string, len(errs))
+	transient := false
+
+	for i, err := range errs {
+		msgs[i] = err.Error()
+
+		if orberrors.IsTransient(err) {
+			transient = true
+		}
+	}
+
+	err := fmt.Errorf("failed to retrieve data from all %d sources: %s", len(errs), strings.Join(msgs, "; "))
+
+	if transient {
+		return orberrors.NewTransient(err)
+	}
+
+	return err
+}
@@ -0,0 +1,288 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	"github.com/trustbloc/orb/pkg/cas/ipfs"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+var logger = log.New("cas-s3")
+
+const (
+	defaultCacheSize = 1000
+	defaultTimeout   = 20 * time.Second
+	casType          = "s3"
+
+	contentType     = "application/octet-stream"
+	errNoSuchKey    = "NoSuchKey"
+	schemeHTTPS     = "https"
+	schemeHTTP      = "http"
+	createdMetadata = "Created"
+)
+
+type metricsProvider interface {
+	CASIncrementCacheHitCount()
+	CASReadTime(casType string, value time.Duration)
+}
+
+// Config holds the configuration of the S3-compatible object store (e.g. AWS S3 or MinIO).
+type Config struct {
+	// URL is the endpoint of the object store, e.g. https://s3.amazonaws.com or http://minio:9000.
+	URL string
+	// Bucket is the name of the bucket that holds the CAS content. The bucket is created if it doesn't exist.
+	Bucket string
+	// Region is the region of the bucket (optional).
+	Region string
+	// AccessKeyID and SecretAccessKey are the credentials used to access the object store. If either is
+	// empty then requests are made anonymously.
+	AccessKeyID     string
+	SecretAccessKey string
+	// Timeout is the timeout for requests to the object store. If not set then a default timeout is used.
+	Timeout time.Duration
+}
+
+// Client stores CAS content as objects in an S3-compatible bucket. The object name is the resource hash of
+// the content.
+type Client struct {
+	s3         *minio.Client
+	bucket     string
+	timeout    time.Duration
+	ipfsClient *ipfs.Client
+	opts       []extendedcasclient.CIDFormatOption
+	cache      gcache.Cache
+	metrics    metricsProvider
+	casLink    string
+	hl         *hashlink.HashLink
+}
+
+// New returns a new S3 CAS client. The bucket is created if it doesn't already exist.
+// ipfsClient is optional, but if provided (not nil), then writes will go to IPFS in addition to the bucket.
+// Reads are always done on only the bucket.
+// If no CID version is specified, then v1 will be used by default.
+func New(cfg Config, casLink string, ipfsClient *ipfs.Client, metrics metricsProvider, cacheSize int,
+	opts ...extendedcasclient.CIDFormatOption) (*Client, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("bucket name is required")
+	}
+
+	s3Client, err := newS3Client(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheSize == 0 {
+		cacheSize = defaultCacheSize
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	c := &Client{
+		s3:         s3Client,
+		bucket:     cfg.Bucket,
+		timeout:    timeout,
+		ipfsClient: ipfsClient,
+		opts:       opts,
+		metrics:    metrics,
+		hl:         hashlink.New(),
+		casLink:    casLink,
+	}
+
+	if err = c.ensureBucket(cfg.Region); err != nil {
+		return nil, err
+	}
+
+	c.cache = gcache.New(cacheSize).ARC().
+		LoaderFunc(func(key interface{}) (interface{}, error) {
+			content, e := c.get(key.(string))
+			if e != nil {
+				return nil, e
+			}
+
+			logger.Debugf("Cached content for CID [%s]", key)
+
+			return content, nil
+		},
+		).Build()
+
+	return c, nil
+}
+
+// Write writes the given content to the bucket (and IPFS if configured) using this client's default CID version.
+// Returns the address of the content.
+func (c *Client) Write(content []byte) (string, error) {
+	return c.WriteWithCIDFormat(content, c.opts...)
+}
+
+// WriteWithCIDFormat writes the given content to the bucket (and IPFS if configured) using the CID format
+// specified by opts.
+// Returns the address of the content.
+func (c *Client) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFormatOption) (string, error) {
+	resourceHash, err := c.hl.CreateResourceHash(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to create resource hash from content: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	_, err = c.s3.PutObject(ctx, c.bucket, resourceHash, bytes.NewReader(content), int64(len(content)),
+		minio.PutObjectOptions{
+			ContentType:  contentType,
+			UserMetadata: map[string]string{createdMetadata: time.Now().UTC().Format(time.RFC3339)},
+		},
+	)
+	if err != nil {
+		return "", orberrors.NewTransient(fmt.Errorf("failed to put content into bucket [%s]: %w", c.bucket, err))
+	}
+
+	// add cas link
+	links := []string{c.casLink + "/" + resourceHash}
+
+	if c.ipfsClient != nil {
+		cid, writeErr := c.ipfsClient.WriteWithCIDFormat(content, opts...)
+		if writeErr != nil {
+			return "", orberrors.NewTransient(fmt.Errorf("failed to put content into IPFS (but it was "+
+				"successfully stored in the S3 bucket): %w", writeErr))
+		}
+
+		// add ipfs link
+		links = append(links, "ipfs://"+cid)
+	}
+
+	if err = c.cache.Set(resourceHash, content); err != nil {
+		// This shouldn't be possible.
+		logger.Warnf("Error caching content for resource hash[%s]: %s", resourceHash, err)
+	} else {
+		logger.Debugf("Cached content for resource hash [%s]", resourceHash)
+	}
+
+	metadata, err := c.hl.CreateMetadataFromLinks(links)
+	if err != nil {
+		return "", fmt.Errorf("failed to create resource hash from content: %w", err)
+	}
+
+	return hashlink.GetHashLink(resourceHash, metadata), nil
+}
+
+// GetPrimaryWriterType returns primary writer type.
+func (c *Client) GetPrimaryWriterType() string {
+	return casType
+}
+
+// Read reads the content of the given address from the bucket.
+// Returns the content at the given address.
+func (c *Client) Read(address string) ([]byte, error) {
+	if c.cache.Has(address) {
+		c.metrics.CASIncrementCacheHitCount()
+	}
+
+	content, err := c.cache.Get(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return content.([]byte), nil
+}
+
+func (c *Client) get(address string) ([]byte, error) {
+	startTime := time.Now()
+
+	defer func() {
+		c.metrics.CASReadTime(casType, time.Since(startTime))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	obj, err := c.s3.GetObject(ctx, c.bucket, address, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get content from bucket [%s]: %w", c.bucket, err))
+	}
+
+	defer func() {
+		if e := obj.Close(); e != nil {
+			logger.Warnf("Error closing object [%s]: %s", address, e)
+		}
+	}()
+
+	// The object is retrieved lazily so a missing object is only reported on the first read.
+	content, err := ioutil.ReadAll(obj)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == errNoSuchKey {
+			return nil, orberrors.ErrContentNotFound
+		}
+
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to read content from bucket [%s]: %w", c.bucket, err))
+	}
+
+	return content, nil
+}
+
+func (c *Client) ensureBucket(region string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	exists, err := c.s3.BucketExists(ctx, c.bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check if bucket [%s] exists: %w", c.bucket, err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	logger.Infof("Creating bucket [%s]", c.bucket)
+
+	err = c.s3.MakeBucket(ctx, c.bucket, minio.MakeBucketOptions{Region: region})
+	if err != nil {
+		return fmt.Errorf("failed to create bucket [%s]: %w", c.bucket, err)
+	}
+
+	return nil
+}
+
+func newS3Client(cfg Config) (*minio.Client, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse S3 URL: %w", err)
+	}
+
+	if u.Scheme != schemeHTTPS && u.Scheme != schemeHTTP {
+		return nil, fmt.Errorf("invalid S3 URL [%s]: scheme must be http or https", cfg.URL)
+	}
+
+	s3Client, err := minio.New(u.Host, &minio.Options{
+		// Requests are anonymous if the access key ID or secret access key is empty.
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: u.Scheme == schemeHTTPS,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	return s3Client, nil
+}
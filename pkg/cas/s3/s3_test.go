@@ -0,0 +1,248 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package s3_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	"github.com/trustbloc/orb/pkg/cas/ipfs"
+	"github.com/trustbloc/orb/pkg/cas/s3"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+)
+
+const (
+	casLink = "https://domain.com/cas"
+	bucket  = "orb-cas"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Success - bucket created", func(t *testing.T) {
+		backend := s3mem.New()
+
+		server := httptest.NewServer(gofakes3.New(backend).Server())
+		defer server.Close()
+
+		c, err := s3.New(newConfig(server.URL), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		require.Equal(t, "s3", c.GetPrimaryWriterType())
+
+		exists, err := backend.BucketExists(bucket)
+		require.NoError(t, err)
+		require.True(t, exists)
+	})
+
+	t.Run("Success - existing bucket", func(t *testing.T) {
+		backend := s3mem.New()
+		require.NoError(t, backend.CreateBucket(bucket))
+
+		server := httptest.NewServer(gofakes3.New(backend).Server())
+		defer server.Close()
+
+		c, err := s3.New(newConfig(server.URL), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+	})
+
+	t.Run("No bucket", func(t *testing.T) {
+		cfg := newConfig("http://localhost:9000")
+		cfg.Bucket = ""
+
+		c, err := s3.New(cfg, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.EqualError(t, err, "bucket name is required")
+		require.Nil(t, c)
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		c, err := s3.New(newConfig(":invalid"), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse S3 URL")
+		require.Nil(t, c)
+	})
+
+	t.Run("Invalid scheme", func(t *testing.T) {
+		c, err := s3.New(newConfig("ftp://localhost:9000"), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "scheme must be http or https")
+		require.Nil(t, c)
+	})
+
+	t.Run("Bucket check error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		c, err := s3.New(newConfig(server.URL), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to check if bucket [orb-cas] exists")
+		require.Nil(t, c)
+	})
+
+	t.Run("Create bucket error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		c, err := s3.New(newConfig(server.URL), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create bucket [orb-cas]")
+		require.Nil(t, c)
+	})
+}
+
+func TestClient_Write_Read(t *testing.T) {
+	server := httptest.NewServer(gofakes3.New(s3mem.New()).Server())
+	defer server.Close()
+
+	t.Run("Success", func(t *testing.T) {
+		c, err := s3.New(newConfig(server.URL), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		hl, err := c.Write([]byte("content"))
+		require.NoError(t, err)
+
+		info, err := hashlink.New().ParseHashLink(hl)
+		require.NoError(t, err)
+		require.Equal(t, []string{casLink + "/" + info.ResourceHash}, info.Links)
+
+		// Read from the cache.
+		content, err := c.Read(info.ResourceHash)
+		require.NoError(t, err)
+		require.Equal(t, "content", string(content))
+
+		// Read from the bucket using a new client (with an empty cache).
+		c2, err := s3.New(newConfig(server.URL), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		content, err = c2.Read(info.ResourceHash)
+		require.NoError(t, err)
+		require.Equal(t, "content", string(content))
+	})
+
+	t.Run("Success - replicated in IPFS", func(t *testing.T) {
+		ipfsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, err := fmt.Fprint(w, `{"Hash":"bafkreihnoabliopjvscf6irvpwbcxlauirzq7pnwafwt5skdekl3t3e7om"}`)
+			require.NoError(t, err)
+		}))
+		defer ipfsServer.Close()
+
+		c, err := s3.New(newConfig(server.URL), casLink,
+			ipfs.New(ipfsServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}),
+			&orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		hl, err := c.WriteWithCIDFormat([]byte("content"), extendedcasclient.WithCIDVersion(1))
+		require.NoError(t, err)
+
+		info, err := hashlink.New().ParseHashLink(hl)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			casLink + "/" + info.ResourceHash,
+			"ipfs://bafkreihnoabliopjvscf6irvpwbcxlauirzq7pnwafwt5skdekl3t3e7om",
+		}, info.Links)
+	})
+
+	t.Run("IPFS error", func(t *testing.T) {
+		ipfsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ipfsServer.Close()
+
+		c, err := s3.New(newConfig(server.URL), casLink,
+			ipfs.New(ipfsServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}),
+			&orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		hl, err := c.Write([]byte("content"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "failed to put content into IPFS")
+		require.Empty(t, hl)
+	})
+
+	t.Run("Content not found", func(t *testing.T) {
+		c, err := s3.New(newConfig(server.URL), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		content, err := c.Read("uEiB2QWzbZ9ceQ4eoWaxK2hEVtIYNYc9cnudaQR54m3bEDA")
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+		require.Nil(t, content)
+	})
+}
+
+func TestClient_Errors(t *testing.T) {
+	backend := s3mem.New()
+	fakeS3 := gofakes3.New(backend).Server()
+
+	var fail int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		fakeS3.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	cfg := newConfig(server.URL)
+	cfg.Timeout = time.Second
+
+	c, err := s3.New(cfg, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	atomic.StoreInt32(&fail, 1)
+
+	t.Run("Write error", func(t *testing.T) {
+		hl, err := c.Write([]byte("content"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "failed to put content into bucket [orb-cas]")
+		require.Empty(t, hl)
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		content, err := c.Read("uEiB2QWzbZ9ceQ4eoWaxK2hEVtIYNYc9cnudaQR54m3bEDA")
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "failed to read content from bucket [orb-cas]")
+		require.Nil(t, content)
+	})
+}
+
+func newConfig(serverURL string) s3.Config {
+	return s3.Config{
+		URL:             serverURL,
+		Bucket:          bucket,
+		Region:          "us-east-1",
+		AccessKeyID:     "access-key",
+		SecretAccessKey: "secret-key",
+	}
+}
@@ -6,9 +6,14 @@ SPDX-License-Identifier: Apache-2.0
 
 package config
 
+// AllowedOrigins determines whether or not an anchor origin is allowed.
+type AllowedOrigins interface {
+	IsAllowed(origin string) (bool, error)
+}
+
 // Sidetree holds global Sidetree configuration.
 type Sidetree struct {
-	MethodContext []string
-	EnableBase    bool
-	AnchorOrigins []string
+	MethodContext  []string
+	EnableBase     bool
+	AllowedOrigins AllowedOrigins
 }
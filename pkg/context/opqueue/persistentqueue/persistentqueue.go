@@ -0,0 +1,314 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package persistentqueue
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+)
+
+var logger = log.New("sidetree_context")
+
+const (
+	storeName = "operation-queue"
+	tagName   = "queuedOp"
+)
+
+type metricsProvider interface {
+	AddOperationTime(value time.Duration)
+	BatchCutTime(value time.Duration)
+	BatchRollbackTime(value time.Duration)
+	BatchAckTime(value time.Duration)
+	BatchNackTime(value time.Duration)
+	BatchSize(value float64)
+}
+
+// queuedOperation is the operation as persisted in the store.
+type queuedOperation struct {
+	ID        string                           `json:"id"`
+	Operation *operation.QueuedOperationAtTime `json:"operation"`
+	TimeAdded time.Time                        `json:"timeAdded"`
+}
+
+// Queue implements an operation queue which persists the queued operations to a storage provider so that
+// operations which have been added but not yet cut into a batch survive a restart. Each operation is kept in the
+// store until the batch containing the operation is acknowledged. On startup, all operations in the store are
+// loaded into the queue.
+//
+// This queue is intended for single-node deployments (i.e. where a durable message queue is not used) since
+// all operations in the store are loaded on startup, regardless of which server instance added them.
+type Queue struct {
+	*lifecycle.Lifecycle
+
+	store         storage.Store
+	mutex         sync.RWMutex
+	pending       []*queuedOperation
+	jsonMarshal   func(interface{}) ([]byte, error)
+	jsonUnmarshal func(data []byte, v interface{}) error
+	metrics       metricsProvider
+}
+
+// New returns a new persistent operation queue which is populated with the operations found in the store.
+func New(provider storage.Provider, metrics metricsProvider) (*Queue, error) {
+	store, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", storeName, err)
+	}
+
+	err = provider.SetStoreConfig(storeName, storage.StoreConfiguration{TagNames: []string{tagName}})
+	if err != nil {
+		return nil, fmt.Errorf("set store configuration for [%s]: %w", storeName, err)
+	}
+
+	q := &Queue{
+		store:         store,
+		jsonMarshal:   json.Marshal,
+		jsonUnmarshal: json.Unmarshal,
+		metrics:       metrics,
+	}
+
+	pending, err := q.load()
+	if err != nil {
+		return nil, fmt.Errorf("load queued operations: %w", err)
+	}
+
+	q.pending = pending
+
+	q.Lifecycle = lifecycle.New("persistent-operation-queue",
+		lifecycle.WithStart(q.start),
+	)
+
+	q.Start()
+
+	return q, nil
+}
+
+// Add persists the given operation and adds it to the tail of the queue.
+func (q *Queue) Add(op *operation.QueuedOperation, protocolGenesisTime uint64) (uint, error) {
+	if q.State() != lifecycle.StateStarted {
+		return 0, lifecycle.ErrNotStarted
+	}
+
+	startTime := time.Now()
+
+	defer func() {
+		q.metrics.AddOperationTime(time.Since(startTime))
+	}()
+
+	qop := &queuedOperation{
+		ID: watermill.NewUUID(),
+		Operation: &operation.QueuedOperationAtTime{
+			QueuedOperation:     *op,
+			ProtocolGenesisTime: protocolGenesisTime,
+		},
+		TimeAdded: time.Now(),
+	}
+
+	b, err := q.jsonMarshal(qop)
+	if err != nil {
+		return 0, fmt.Errorf("marshall queued operation: %w", err)
+	}
+
+	logger.Debugf("Storing queued operation [%s] - DID [%s]", qop.ID, op.UniqueSuffix)
+
+	err = q.store.Put(qop.ID, b, storage.Tag{Name: tagName})
+	if err != nil {
+		return 0, orberrors.NewTransient(fmt.Errorf("store queued operation: %w", err))
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.pending = append(q.pending, qop)
+
+	return uint(len(q.pending)), nil
+}
+
+// Peek returns (up to) the given number of operations from the head of the queue but does not remove them.
+func (q *Queue) Peek(num uint) (operation.QueuedOperationsAtTime, error) {
+	if q.State() != lifecycle.StateStarted {
+		return nil, lifecycle.ErrNotStarted
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	n := int(num)
+	if len(q.pending) < n {
+		n = len(q.pending)
+	}
+
+	return asQueuedOperations(q.pending[0:n]), nil
+}
+
+// Remove removes (up to) the given number of items from the head of the queue.
+// Returns the actual number of items that were removed and the new length of the queue.
+// The removed operations are deleted from the store when ack is called. If nack is called then the
+// operations are returned to the head of the queue so that they may be retried.
+func (q *Queue) Remove(num uint) (ops operation.QueuedOperationsAtTime, ack func() uint, nack func(), err error) {
+	if q.State() != lifecycle.StateStarted {
+		return nil, nil, nil, lifecycle.ErrNotStarted
+	}
+
+	startTime := time.Now()
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	n := int(num)
+	if len(q.pending) < n {
+		n = len(q.pending)
+	}
+
+	if n == 0 {
+		return nil,
+			func() uint { return 0 },
+			func() {}, nil
+	}
+
+	items := q.pending[0:n]
+	q.pending = q.pending[n:]
+
+	return asQueuedOperations(items), q.newAckFunc(items, startTime), q.newNackFunc(items, startTime), nil
+}
+
+// Len returns the length of the pending queue.
+func (q *Queue) Len() uint {
+	if q.State() != lifecycle.StateStarted {
+		return 0
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return uint(len(q.pending))
+}
+
+func (q *Queue) start() {
+	logger.Infof("Started persistent operation queue with %d pending operations", len(q.pending))
+}
+
+func (q *Queue) load() ([]*queuedOperation, error) {
+	iter, err := q.store.Query(tagName)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("query store: %w", err))
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("Error closing iterator: %s", errClose)
+		}
+	}()
+
+	var ops []*queuedOperation
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("iterator: %w", err))
+	}
+
+	for ok {
+		value, e := iter.Value()
+		if e != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator value: %w", e))
+		}
+
+		qop := &queuedOperation{}
+
+		e = q.jsonUnmarshal(value, qop)
+		if e != nil {
+			return nil, fmt.Errorf("unmarshal queued operation: %w", e)
+		}
+
+		logger.Debugf("Loaded queued operation [%s] - DID [%s]", qop.ID, qop.Operation.UniqueSuffix)
+
+		ops = append(ops, qop)
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator: %w", err))
+		}
+	}
+
+	// Replay the operations in the order in which they were added.
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].TimeAdded.Before(ops[j].TimeAdded)
+	})
+
+	return ops, nil
+}
+
+func (q *Queue) newAckFunc(items []*queuedOperation, startTime time.Time) func() uint {
+	return func() uint {
+		logger.Infof("Acking %d queued operations...", len(items))
+
+		batch := make([]storage.Operation, len(items))
+
+		for i, qop := range items {
+			// A nil value results in a delete.
+			batch[i] = storage.Operation{Key: qop.ID}
+		}
+
+		if err := q.store.Batch(batch); err != nil {
+			// The operations have already been anchored but they will be replayed on the next startup.
+			logger.Errorf("Error deleting %d acknowledged operations from store: %s", len(items), err)
+		}
+
+		// Batch cut time is the time since the first operation was added (which is the oldest operation in the batch).
+		q.metrics.BatchCutTime(time.Since(items[0].TimeAdded))
+
+		// Batch Ack time is the time it took to delete the operations from the store.
+		q.metrics.BatchAckTime(time.Since(startTime))
+
+		q.metrics.BatchSize(float64(len(items)))
+
+		q.mutex.RLock()
+		defer q.mutex.RUnlock()
+
+		return uint(len(q.pending))
+	}
+}
+
+func (q *Queue) newNackFunc(items []*queuedOperation, startTime time.Time) func() {
+	return func() {
+		logger.Infof("Nacking %d queued operations...", len(items))
+
+		q.mutex.Lock()
+
+		// Return the operations to the head of the queue so that they are retried in the same order.
+		q.pending = append(append([]*queuedOperation{}, items...), q.pending...)
+
+		q.mutex.Unlock()
+
+		// Batch rollback time is the time since the first operation was added (which is the oldest operation in the batch).
+		q.metrics.BatchRollbackTime(time.Since(items[0].TimeAdded))
+
+		// Batch Nack time is the time it took to return the operations to the queue.
+		q.metrics.BatchNackTime(time.Since(startTime))
+	}
+}
+
+func asQueuedOperations(qops []*queuedOperation) []*operation.QueuedOperationAtTime {
+	ops := make([]*operation.QueuedOperationAtTime, len(qops))
+
+	for i, qop := range qops {
+		ops[i] = qop.Operation
+	}
+
+	return ops
+}
@@ -0,0 +1,231 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package persistentqueue
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mock"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/mocks"
+)
+
+func TestQueue(t *testing.T) {
+	provider := mem.NewProvider()
+
+	q, err := New(provider, &mocks.MetricsProvider{})
+	require.NoError(t, err)
+	require.NotNil(t, q)
+
+	require.Zero(t, q.Len())
+
+	ops, err := q.Peek(2)
+	require.NoError(t, err)
+	require.Empty(t, ops)
+
+	removedOps, ack, nack, err := q.Remove(2)
+	require.NoError(t, err)
+	require.Empty(t, removedOps)
+	require.Zero(t, ack())
+	require.NotPanics(t, nack)
+
+	for _, suffix := range []string{"op1", "op2", "op3", "op4"} {
+		_, err = q.Add(&operation.QueuedOperation{UniqueSuffix: suffix}, 100)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, uint(4), q.Len())
+
+	ops, err = q.Peek(2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"op1", "op2"}, suffixes(ops))
+	require.Equal(t, uint64(100), ops[0].ProtocolGenesisTime)
+
+	t.Run("Nack", func(t *testing.T) {
+		removedOps, _, nack, err := q.Remove(2)
+		require.NoError(t, err)
+		require.Equal(t, []string{"op1", "op2"}, suffixes(removedOps))
+		require.Equal(t, uint(2), q.Len())
+
+		nack()
+
+		// The operations are returned to the head of the queue.
+		require.Equal(t, uint(4), q.Len())
+
+		ops, err := q.Peek(4)
+		require.NoError(t, err)
+		require.Equal(t, []string{"op1", "op2", "op3", "op4"}, suffixes(ops))
+	})
+
+	t.Run("Ack", func(t *testing.T) {
+		removedOps, ack, _, err := q.Remove(1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"op1"}, suffixes(removedOps))
+		require.Equal(t, uint(3), ack())
+	})
+
+	t.Run("Replay on restart", func(t *testing.T) {
+		// Remove an operation but don't ack it (e.g. the server crashes while cutting the batch).
+		removedOps, _, _, err := q.Remove(1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"op2"}, suffixes(removedOps))
+
+		q.Stop()
+
+		q2, err := New(provider, &mocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		defer q2.Stop()
+
+		require.Equal(t, uint(3), q2.Len())
+
+		ops, err := q2.Peek(10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"op2", "op3", "op4"}, suffixes(ops))
+	})
+}
+
+func TestQueue_Error(t *testing.T) {
+	op1 := &operation.QueuedOperation{UniqueSuffix: "op1"}
+
+	t.Run("Not started error", func(t *testing.T) {
+		q, err := New(mem.NewProvider(), &mocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		q.Stop()
+
+		_, err = q.Add(op1, 100)
+		require.True(t, errors.Is(err, lifecycle.ErrNotStarted))
+
+		_, err = q.Peek(1)
+		require.True(t, errors.Is(err, lifecycle.ErrNotStarted))
+
+		_, _, _, err = q.Remove(1)
+		require.True(t, errors.Is(err, lifecycle.ErrNotStarted))
+
+		require.Zero(t, q.Len())
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		errExpected := errors.New("injected open store error")
+
+		_, err := New(&mock.Provider{ErrOpenStore: errExpected}, &mocks.MetricsProvider{})
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Set store config error", func(t *testing.T) {
+		errExpected := errors.New("injected set config error")
+
+		_, err := New(&mock.Provider{OpenStoreReturn: &mock.Store{}, ErrSetStoreConfig: errExpected},
+			&mocks.MetricsProvider{})
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("injected query error")
+
+		_, err := New(&mock.Provider{OpenStoreReturn: &mock.Store{ErrQuery: errExpected}}, &mocks.MetricsProvider{})
+		require.True(t, errors.Is(err, errExpected))
+		require.True(t, orberrors.IsTransient(err))
+	})
+
+	t.Run("Unmarshal error", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := provider.OpenStore(storeName)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put("op1", []byte("{"), storage.Tag{Name: tagName}))
+
+		_, err = New(provider, &mocks.MetricsProvider{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal queued operation")
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		q, err := New(mem.NewProvider(), &mocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		errExpected := errors.New("injected marshal error")
+
+		q.jsonMarshal = func(i interface{}) ([]byte, error) {
+			return nil, errExpected
+		}
+
+		_, err = q.Add(op1, 100)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Store error", func(t *testing.T) {
+		errExpected := errors.New("injected store error")
+
+		memStore, err := mem.NewProvider().OpenStore(storeName)
+		require.NoError(t, err)
+
+		s := &failingStore{Store: memStore}
+
+		q, err := New(&mock.Provider{OpenStoreReturn: s}, &mocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		s.errPut = errExpected
+
+		_, err = q.Add(op1, 100)
+		require.True(t, errors.Is(err, errExpected))
+		require.True(t, orberrors.IsTransient(err))
+		require.Zero(t, q.Len())
+
+		s.errPut = nil
+		s.errBatch = errors.New("injected batch error")
+
+		_, err = q.Add(op1, 100)
+		require.NoError(t, err)
+
+		_, ack, _, err := q.Remove(1)
+		require.NoError(t, err)
+		require.Zero(t, ack())
+	})
+}
+
+type failingStore struct {
+	storage.Store
+
+	errPut   error
+	errBatch error
+}
+
+func (s *failingStore) Put(key string, value []byte, tags ...storage.Tag) error {
+	if s.errPut != nil {
+		return s.errPut
+	}
+
+	return s.Store.Put(key, value, tags...)
+}
+
+func (s *failingStore) Batch(operations []storage.Operation) error {
+	if s.errBatch != nil {
+		return s.errBatch
+	}
+
+	return s.Store.Batch(operations)
+}
+
+func suffixes(ops operation.QueuedOperationsAtTime) []string {
+	s := make([]string, len(ops))
+
+	for i, op := range ops {
+		s[i] = op.UniqueSuffix
+	}
+
+	return s
+}
@@ -8,48 +8,144 @@ package local
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/trustbloc/edge-core/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 
+	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/store/storeutil"
 	"github.com/trustbloc/orb/pkg/discovery/endpoint/client/models"
+	"github.com/trustbloc/orb/pkg/hashlink"
 )
 
 var logger = log.New("local-discovery")
 
+// Sources that are queried for the latest anchor of a DID.
+const (
+	sourceAnchorOrigin    = "anchor-origin"
+	sourceDiscoveryDomain = "discovery-domain"
+	sourceFollowedService = "followed-service"
+)
+
+// maxSuccessorDepth is the maximum number of previous anchors that are traversed in the anchor graph when
+// determining whether one anchor is the successor of another.
+const maxSuccessorDepth = 100
+
+// Results of a discovery request.
+const (
+	resultPublished = "published"
+	resultFailed    = "failed"
+)
+
 type didPublisher interface {
 	PublishDID(dids string) error
 }
 
 type endpointClient interface {
 	GetEndpointFromAnchorOrigin(did string) (*models.Endpoint, error)
+	GetEndpointFromDomain(domain, did string) (*models.Endpoint, error)
+}
+
+type anchorGraph interface {
+	IsPreviousAnchor(hl, prev, suffix string, maxDepth int) (bool, error)
+}
+
+type activityStore interface {
+	QueryReferences(refType spi.ReferenceType, query *spi.Criteria, opts ...spi.QueryOpt) (spi.ReferenceIterator, error)
+}
+
+type metricsProvider interface {
+	DIDDiscoveryIncrementCount(result string)
+	DIDDiscoveryQueryIncrementCount(source string, success bool)
+}
+
+// Option is a local discovery option.
+type Option func(d *Discovery)
+
+// WithDiscoveryDomains sets the domains that are queried for the latest anchor of a DID if the anchor
+// origin of the DID can't be reached.
+func WithDiscoveryDomains(domains []string) Option {
+	return func(d *Discovery) {
+		d.discoveryDomains = domains
+	}
+}
+
+// WithFollowedServices causes the services that are followed by the given service to be queried for the
+// latest anchor of a DID if the anchor origin of the DID can't be reached.
+func WithFollowedServices(store activityStore, serviceIRI *url.URL) Option {
+	return func(d *Discovery) {
+		d.activityStore = store
+		d.serviceIRI = serviceIRI
+	}
 }
 
 // New creates new local discovery.
-func New(namespace string, didPublisher didPublisher, client endpointClient) *Discovery {
-	return &Discovery{
+func New(namespace string, didPublisher didPublisher, client endpointClient, anchorGraph anchorGraph,
+	metrics metricsProvider, opts ...Option) *Discovery {
+	d := &Discovery{
 		namespace:      namespace,
 		publisher:      didPublisher,
 		endpointClient: client,
+		anchorGraph:    anchorGraph,
+		metrics:        metrics,
 	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
 }
 
-// Discovery implements local did discovery.
+// Discovery implements local did discovery. The anchor origin of the DID is queried first and, if it responds,
+// its anchor is published to the observer. Only if the anchor origin can't be reached are the configured discovery
+// domains and the followed services queried, in which case the most recent of the discovered anchors (determined
+// by following the previous anchors of the DID in the anchor graph) is published.
 type Discovery struct {
-	namespace      string
-	publisher      didPublisher
-	endpointClient endpointClient
+	namespace        string
+	publisher        didPublisher
+	endpointClient   endpointClient
+	anchorGraph      anchorGraph
+	metrics          metricsProvider
+	discoveryDomains []string
+	activityStore    activityStore
+	serviceIRI       *url.URL
+}
+
+type source struct {
+	sourceType string
+	domain     string
+}
+
+type queryResult struct {
+	anchor string
+	err    error
 }
 
 // RequestDiscovery requests did discovery.
 func (d *Discovery) RequestDiscovery(did string) error {
+	err := d.requestDiscovery(did)
+	if err != nil {
+		d.metrics.DIDDiscoveryIncrementCount(resultFailed)
+
+		return err
+	}
+
+	d.metrics.DIDDiscoveryIncrementCount(resultPublished)
+
+	return nil
+}
+
+func (d *Discovery) requestDiscovery(did string) error {
 	suffix, err := d.getSuffix(did)
 	if err != nil {
 		return err
 	}
 
-	latestCID, err := d.discoverLatestCID(did)
+	latestCID, err := d.discoverLatestCID(did, suffix)
 	if err != nil {
 		return fmt.Errorf("failed to discover latest CID for did[%s]: %w", did, err)
 	}
@@ -57,15 +153,168 @@ func (d *Discovery) RequestDiscovery(did string) error {
 	return d.publisher.PublishDID(latestCID + docutil.NamespaceDelimiter + suffix)
 }
 
-func (d *Discovery) discoverLatestCID(did string) (string, error) {
-	endpoint, err := d.endpointClient.GetEndpointFromAnchorOrigin(did)
+func (d *Discovery) discoverLatestCID(did, suffix string) (string, error) {
+	result := d.query(&source{sourceType: sourceAnchorOrigin}, did)
+	if result.err == nil {
+		logger.Debugf("discovered latest CID for did[%s] from anchor origin: %s", did, result.anchor)
+
+		return result.anchor, nil
+	}
+
+	sources := d.getDomainSources()
+
+	logger.Debugf("Anchor origin of did[%s] failed; querying %d fallback source(s)", did, len(sources))
+
+	results := make([]*queryResult, len(sources))
+
+	var wg sync.WaitGroup
+
+	for i, src := range sources {
+		wg.Add(1)
+
+		go func(i int, src *source) {
+			defer wg.Done()
+
+			results[i] = d.query(src, did)
+		}(i, src)
+	}
+
+	wg.Wait()
+
+	var anchors []string
+
+	for _, r := range results {
+		if r.err != nil {
+			continue
+		}
+
+		anchors = append(anchors, r.anchor)
+	}
+
+	if len(anchors) == 0 {
+		return "", fmt.Errorf("failed to get endpoints: %w", result.err)
+	}
+
+	latest := d.getLatestAnchor(suffix, anchors)
+
+	logger.Debugf("discovered latest CID for did[%s] from %d fallback source(s): %s", did, len(anchors), latest)
+
+	return latest, nil
+}
+
+func (d *Discovery) query(src *source, did string) *queryResult {
+	var endpoint *models.Endpoint
+
+	var err error
+
+	if src.sourceType == sourceAnchorOrigin {
+		endpoint, err = d.endpointClient.GetEndpointFromAnchorOrigin(did)
+	} else {
+		endpoint, err = d.endpointClient.GetEndpointFromDomain(src.domain, did)
+	}
+
+	if err == nil && endpoint.AnchorURI == "" {
+		err = fmt.Errorf("anchor URI not returned")
+	}
+
+	d.metrics.DIDDiscoveryQueryIncrementCount(src.sourceType, err == nil)
+
+	if err != nil {
+		logger.Debugf("Error querying %s [%s] for the latest anchor of did[%s]: %s", src.sourceType, src.domain, did, err)
+
+		return &queryResult{err: err}
+	}
+
+	return &queryResult{anchor: endpoint.AnchorURI}
+}
+
+// getDomainSources returns the configured discovery domains followed by the domains of the followed services.
+// Duplicate domains are removed.
+func (d *Discovery) getDomainSources() []*source {
+	var sources []*source
+
+	added := make(map[string]struct{})
+
+	add := func(sourceType, domain string) {
+		if _, exists := added[getHost(domain)]; exists {
+			return
+		}
+
+		added[getHost(domain)] = struct{}{}
+
+		sources = append(sources, &source{sourceType: sourceType, domain: domain})
+	}
+
+	for _, domain := range d.discoveryDomains {
+		add(sourceDiscoveryDomain, domain)
+	}
+
+	for _, service := range d.getFollowedServices() {
+		add(sourceFollowedService, fmt.Sprintf("%s://%s", service.Scheme, service.Host))
+	}
+
+	return sources
+}
+
+func (d *Discovery) getFollowedServices() []*url.URL {
+	if d.activityStore == nil {
+		return nil
+	}
+
+	it, err := d.activityStore.QueryReferences(spi.Following, spi.NewCriteria(spi.WithObjectIRI(d.serviceIRI)))
 	if err != nil {
-		return "", fmt.Errorf("failed to get endpoints: %w", err)
+		logger.Warnf("Error querying followed services: %s", err)
+
+		return nil
+	}
+
+	defer func() {
+		if e := it.Close(); e != nil {
+			logger.Errorf("failed to close iterator: %s", e)
+		}
+	}()
+
+	services, err := storeutil.ReadReferences(it, -1)
+	if err != nil {
+		logger.Warnf("Error reading followed services: %s", err)
+
+		return nil
 	}
 
-	logger.Debugf("discovered latest CID for did[%s]: +v", did, endpoint)
+	return services
+}
+
+// getLatestAnchor returns the most recent of the given anchors. An anchor is more recent than another if the
+// other anchor is found by following the previous anchors of the DID. If the anchors can't be ordered (for
+// example, if an anchor can't be read) then the anchor from the source with the higher priority is chosen.
+func (d *Discovery) getLatestAnchor(suffix string, anchors []string) string {
+	latest := anchors[0]
 
-	return endpoint.AnchorURI, nil
+	for _, anchor := range anchors[1:] {
+		if resourceHashOrHL(anchor) == resourceHashOrHL(latest) {
+			continue
+		}
+
+		if d.isSuccessor(suffix, anchor, latest) {
+			latest = anchor
+		}
+	}
+
+	return latest
+}
+
+// isSuccessor returns true if the given predecessor is one of the previous anchors of the given anchor. At most
+// maxSuccessorDepth previous anchors are traversed so that a source can't force an arbitrarily long walk of
+// the anchor graph; if the predecessor isn't found within that depth then false is returned.
+func (d *Discovery) isSuccessor(suffix, anchor, predecessor string) bool {
+	found, err := d.anchorGraph.IsPreviousAnchor(anchor, predecessor, suffix, maxSuccessorDepth)
+	if err != nil {
+		logger.Warnf("Error reading previous anchors of [%s] for suffix [%s]: %s", anchor, suffix, err)
+
+		return false
+	}
+
+	return found
 }
 
 // getOrbSuffix fetches unique portion of ID which is string after namespace.
@@ -80,3 +329,23 @@ func (d *Discovery) getSuffix(did string) (string, error) {
 
 	return parts[len(parts)-1], nil
 }
+
+// resourceHashOrHL returns the resource hash of the given hashlink so that hashlinks with different
+// metadata may be compared. The value itself is returned if it isn't a hashlink.
+func resourceHashOrHL(hl string) string {
+	info, err := hashlink.New().ParseHashLink(hl)
+	if err != nil {
+		return hl
+	}
+
+	return info.ResourceHash
+}
+
+func getHost(domain string) string {
+	u, err := url.Parse(domain)
+	if err != nil || u.Host == "" {
+		return domain
+	}
+
+	return u.Host
+}
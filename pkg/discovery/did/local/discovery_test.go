@@ -7,20 +7,37 @@ SPDX-License-Identifier: Apache-2.0
 package local
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
 	"github.com/trustbloc/orb/pkg/discovery/did/mocks"
 	"github.com/trustbloc/orb/pkg/discovery/endpoint/client/models"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
 )
 
 //go:generate counterfeiter -o ../mocks/didPublisher.gen.go --fake-name DIDPublisher . didPublisher
 //go:generate counterfeiter -o ../mocks/endpointClient.gen.go --fake-name EndpointClient . endpointClient
+//go:generate counterfeiter -o ../mocks/anchorGraph.gen.go --fake-name AnchorGraph . anchorGraph
 
 const (
 	testNS = "did:orb"
+
+	suffix  = "EiDahaOGH-liLLdDtTxEAdc8i-cfCz-WUcQdRJheMVNn3A"
+	anchor1 = "uEiAK4KusHyrEyiNE2fdYuOJQG8t55w6XqFdloCdKW-0jnA"
+	anchor2 = "uEiBsdo4Gkx7wJPpnNMNbybP_FqZwS3vSSP6iN3A4ym1ILA"
+
+	testDID = testNS + ":" + anchor1 + ":" + suffix
+
+	domain1 = "https://orb.domain1.com"
+	domain2 = "https://orb.domain2.com"
+	domain3 = "https://orb.domain3.com"
 )
 
 func TestDiscovery_RequestDiscovery(t *testing.T) {
@@ -29,7 +46,7 @@ func TestDiscovery_RequestDiscovery(t *testing.T) {
 		retValue := &models.Endpoint{AnchorURI: "anchorURI", AnchorOrigin: "anchorOriginURI"}
 		endpointClient.GetEndpointFromAnchorOriginReturns(retValue, nil)
 
-		d := New(testNS, &mocks.DIDPublisher{}, endpointClient)
+		d := New(testNS, &mocks.DIDPublisher{}, endpointClient, &mocks.AnchorGraph{}, &orbmocks.MetricsProvider{})
 
 		err := d.RequestDiscovery("did:orb:ipfs:cid:suffix")
 		require.NoError(t, err)
@@ -40,7 +57,7 @@ func TestDiscovery_RequestDiscovery(t *testing.T) {
 		retValue := &models.Endpoint{AnchorURI: "anchorURI", AnchorOrigin: "anchorOriginURI"}
 		endpointClient.GetEndpointFromAnchorOriginReturns(retValue, nil)
 
-		d := New(testNS, &mocks.DIDPublisher{}, endpointClient)
+		d := New(testNS, &mocks.DIDPublisher{}, endpointClient, &mocks.AnchorGraph{}, &orbmocks.MetricsProvider{})
 
 		err := d.RequestDiscovery("did:orb:webcas:domain.com:cid:suffix")
 		require.NoError(t, err)
@@ -51,7 +68,7 @@ func TestDiscovery_RequestDiscovery(t *testing.T) {
 		retValue := &models.Endpoint{AnchorURI: "anchorURI", AnchorOrigin: "anchorOriginURI"}
 		endpointClient.GetEndpointFromAnchorOriginReturns(retValue, nil)
 
-		d := New(testNS, &mocks.DIDPublisher{}, endpointClient)
+		d := New(testNS, &mocks.DIDPublisher{}, endpointClient, &mocks.AnchorGraph{}, &orbmocks.MetricsProvider{})
 
 		err := d.RequestDiscovery("did:orb:cid:suffix")
 		require.NoError(t, err)
@@ -62,7 +79,7 @@ func TestDiscovery_RequestDiscovery(t *testing.T) {
 		retValue := &models.Endpoint{AnchorURI: "anchorURI", AnchorOrigin: "anchorOriginURI"}
 		endpointClient.GetEndpointFromAnchorOriginReturns(retValue, nil)
 
-		d := New(testNS, &mocks.DIDPublisher{}, endpointClient)
+		d := New(testNS, &mocks.DIDPublisher{}, endpointClient, &mocks.AnchorGraph{}, &orbmocks.MetricsProvider{})
 
 		err := d.RequestDiscovery("did:orb:cid")
 		require.Error(t, err)
@@ -73,7 +90,7 @@ func TestDiscovery_RequestDiscovery(t *testing.T) {
 		endpointClient := &mocks.EndpointClient{}
 		endpointClient.GetEndpointFromAnchorOriginReturns(nil, fmt.Errorf("endpoint error"))
 
-		d := New(testNS, &mocks.DIDPublisher{}, endpointClient)
+		d := New(testNS, &mocks.DIDPublisher{}, endpointClient, &mocks.AnchorGraph{}, &orbmocks.MetricsProvider{})
 
 		err := d.RequestDiscovery("did:orb:ipfs:abc:123")
 		require.Error(t, err)
@@ -81,3 +98,238 @@ func TestDiscovery_RequestDiscovery(t *testing.T) {
 			"failed to discover latest CID for did[did:orb:ipfs:abc:123]: failed to get endpoints: endpoint error")
 	})
 }
+
+func TestDiscovery_MultipleSources(t *testing.T) {
+	anchorGraph := &mocks.AnchorGraph{}
+	anchorGraph.IsPreviousAnchorStub = func(hl, prev, suffix string, maxDepth int) (bool, error) {
+		switch hl {
+		case "hl:" + anchor2:
+			return prev == "hl:"+anchor1 || prev == "hl:"+anchor2, nil
+		case "hl:" + anchor1:
+			return prev == "hl:"+anchor1, nil
+		default:
+			return false, errors.New("not found")
+		}
+	}
+
+	t.Run("anchor origin offline", func(t *testing.T) {
+		endpointClient := newMockEndpointClient(errors.New("anchor origin offline"), map[string]string{
+			domain2: "hl:" + anchor1,
+		})
+
+		publisher := &mocks.DIDPublisher{}
+		metrics := &mockMetrics{}
+
+		d := New(testNS, publisher, endpointClient, anchorGraph, metrics, WithDiscoveryDomains([]string{domain2}))
+
+		require.NoError(t, d.RequestDiscovery(testDID))
+		require.Equal(t, 1, publisher.PublishDIDCallCount())
+		require.Equal(t, "hl:"+anchor1+":"+suffix, publisher.PublishDIDArgsForCall(0))
+
+		require.Equal(t, 1, metrics.counts[resultPublished])
+		require.Equal(t, 1, metrics.queries[sourceAnchorOrigin+":false"])
+		require.Equal(t, 1, metrics.queries[sourceDiscoveryDomain+":true"])
+	})
+
+	t.Run("anchor origin responds - fallbacks not queried", func(t *testing.T) {
+		endpointClient := newMockEndpointClient(&models.Endpoint{AnchorURI: "hl:" + anchor1}, map[string]string{
+			domain2: "hl:" + anchor2,
+			domain3: "hl:" + anchor1,
+		})
+
+		publisher := &mocks.DIDPublisher{}
+		metrics := &mockMetrics{}
+
+		d := New(testNS, publisher, endpointClient, anchorGraph, metrics,
+			WithDiscoveryDomains([]string{domain2, domain3}))
+
+		require.NoError(t, d.RequestDiscovery(testDID))
+		require.Equal(t, "hl:"+anchor1+":"+suffix, publisher.PublishDIDArgsForCall(0))
+		require.Equal(t, 0, endpointClient.GetEndpointFromDomainCallCount())
+		require.Equal(t, 1, metrics.queries[sourceAnchorOrigin+":true"])
+	})
+
+	t.Run("anchor origin offline - discovery domain has a newer anchor", func(t *testing.T) {
+		endpointClient := newMockEndpointClient(errors.New("anchor origin offline"), map[string]string{
+			domain2: "hl:" + anchor1,
+			domain3: "hl:" + anchor2,
+		})
+
+		publisher := &mocks.DIDPublisher{}
+
+		d := New(testNS, publisher, endpointClient, anchorGraph, &mockMetrics{},
+			WithDiscoveryDomains([]string{domain2, domain3}))
+
+		require.NoError(t, d.RequestDiscovery(testDID))
+		require.Equal(t, "hl:"+anchor2+":"+suffix, publisher.PublishDIDArgsForCall(0))
+		require.Equal(t, 2, endpointClient.GetEndpointFromDomainCallCount())
+
+		_, prev, _, maxDepth := anchorGraph.IsPreviousAnchorArgsForCall(anchorGraph.IsPreviousAnchorCallCount() - 1)
+		require.Equal(t, "hl:"+anchor1, prev)
+		require.Equal(t, maxSuccessorDepth, maxDepth)
+	})
+
+	t.Run("anchor origin offline - anchors can't be ordered", func(t *testing.T) {
+		endpointClient := newMockEndpointClient(errors.New("anchor origin offline"), map[string]string{
+			domain2: "hl:" + anchor1,
+			domain3: "hl:unknown",
+		})
+
+		publisher := &mocks.DIDPublisher{}
+
+		d := New(testNS, publisher, endpointClient, anchorGraph, &mockMetrics{},
+			WithDiscoveryDomains([]string{domain2, domain3}))
+
+		require.NoError(t, d.RequestDiscovery(testDID))
+		require.Equal(t, "hl:"+anchor1+":"+suffix, publisher.PublishDIDArgsForCall(0))
+	})
+
+	t.Run("anchor origin offline - predecessor not found within max depth", func(t *testing.T) {
+		ag := &mocks.AnchorGraph{}
+		ag.IsPreviousAnchorReturns(false, nil)
+
+		endpointClient := newMockEndpointClient(errors.New("anchor origin offline"), map[string]string{
+			domain2: "hl:" + anchor1,
+			domain3: "hl:" + anchor2,
+		})
+
+		publisher := &mocks.DIDPublisher{}
+
+		d := New(testNS, publisher, endpointClient, ag, &mockMetrics{},
+			WithDiscoveryDomains([]string{domain2, domain3}))
+
+		require.NoError(t, d.RequestDiscovery(testDID))
+		require.Equal(t, "hl:"+anchor1+":"+suffix, publisher.PublishDIDArgsForCall(0))
+	})
+
+	t.Run("followed services", func(t *testing.T) {
+		serviceIRI := mustParseURL(domain1 + "/services/orb")
+
+		apStore := memstore.New("")
+		require.NoError(t, apStore.AddReference(spi.Following, serviceIRI, mustParseURL(domain2+"/services/orb")))
+		require.NoError(t, apStore.AddReference(spi.Following, serviceIRI, mustParseURL(domain3+"/services/orb")))
+
+		endpointClient := newMockEndpointClient(errors.New("anchor origin offline"), map[string]string{
+			domain2: "hl:" + anchor1,
+			domain3: "hl:" + anchor2,
+		})
+
+		publisher := &mocks.DIDPublisher{}
+		metrics := &mockMetrics{}
+
+		d := New(testNS, publisher, endpointClient, anchorGraph, metrics,
+			WithDiscoveryDomains([]string{domain2}),
+			WithFollowedServices(apStore, serviceIRI),
+		)
+
+		require.NoError(t, d.RequestDiscovery(testDID))
+		require.Equal(t, "hl:"+anchor2+":"+suffix, publisher.PublishDIDArgsForCall(0))
+
+		// domain2 is both a discovery domain and a followed service, so it's only queried once.
+		require.Equal(t, 2, endpointClient.GetEndpointFromDomainCallCount())
+		require.Equal(t, 1, metrics.queries[sourceDiscoveryDomain+":true"])
+		require.Equal(t, 1, metrics.queries[sourceFollowedService+":true"])
+	})
+
+	t.Run("activity store error", func(t *testing.T) {
+		apStore := &mockActivityStore{err: errors.New("injected query error")}
+
+		endpointClient := newMockEndpointClient(errors.New("anchor origin offline"), nil)
+
+		d := New(testNS, &mocks.DIDPublisher{}, endpointClient, anchorGraph, &mockMetrics{},
+			WithFollowedServices(apStore, mustParseURL(domain1+"/services/orb")))
+
+		err := d.RequestDiscovery(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get endpoints: anchor origin offline")
+		require.Equal(t, 0, endpointClient.GetEndpointFromDomainCallCount())
+	})
+
+	t.Run("all sources failed", func(t *testing.T) {
+		endpointClient := newMockEndpointClient(errors.New("anchor origin offline"), map[string]string{
+			domain2: "",
+		})
+
+		publisher := &mocks.DIDPublisher{}
+		metrics := &mockMetrics{}
+
+		d := New(testNS, publisher, endpointClient, anchorGraph, metrics, WithDiscoveryDomains([]string{domain2}))
+
+		err := d.RequestDiscovery(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get endpoints: anchor origin offline")
+		require.Equal(t, 0, publisher.PublishDIDCallCount())
+		require.Equal(t, 1, metrics.counts[resultFailed])
+		require.Equal(t, 1, metrics.queries[sourceDiscoveryDomain+":false"])
+	})
+}
+
+// newMockEndpointClient returns an endpoint client that returns the given result (endpoint or error) for the
+// anchor origin and the given anchor URIs for the domains. An error is returned for unknown domains.
+func newMockEndpointClient(anchorOriginResult interface{}, domainAnchors map[string]string) *mocks.EndpointClient {
+	endpointClient := &mocks.EndpointClient{}
+
+	switch r := anchorOriginResult.(type) {
+	case error:
+		endpointClient.GetEndpointFromAnchorOriginReturns(nil, r)
+	case *models.Endpoint:
+		endpointClient.GetEndpointFromAnchorOriginReturns(r, nil)
+	}
+
+	endpointClient.GetEndpointFromDomainStub = func(domain, did string) (*models.Endpoint, error) {
+		anchor, ok := domainAnchors[domain]
+		if !ok {
+			return nil, fmt.Errorf("domain [%s] not found", domain)
+		}
+
+		return &models.Endpoint{AnchorURI: anchor}, nil
+	}
+
+	return endpointClient
+}
+
+func mustParseURL(raw string) *url.URL {
+	u, err := url.Parse(raw)
+	if err != nil {
+		panic(err)
+	}
+
+	return u
+}
+
+type mockMetrics struct {
+	mutex   sync.Mutex
+	counts  map[string]int
+	queries map[string]int
+}
+
+func (m *mockMetrics) DIDDiscoveryIncrementCount(result string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+
+	m.counts[result]++
+}
+
+func (m *mockMetrics) DIDDiscoveryQueryIncrementCount(source string, success bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.queries == nil {
+		m.queries = make(map[string]int)
+	}
+
+	m.queries[fmt.Sprintf("%s:%t", source, success)]++
+}
+
+type mockActivityStore struct {
+	err error
+}
+
+func (m *mockActivityStore) QueryReferences(spi.ReferenceType, *spi.Criteria,
+	...spi.QueryOpt) (spi.ReferenceIterator, error) {
+	return nil, m.err
+}
@@ -0,0 +1,118 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"sync"
+)
+
+type AnchorGraph struct {
+	IsPreviousAnchorStub        func(string, string, string, int) (bool, error)
+	isPreviousAnchorMutex       sync.RWMutex
+	isPreviousAnchorArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 int
+	}
+	isPreviousAnchorReturns struct {
+		result1 bool
+		result2 error
+	}
+	isPreviousAnchorReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *AnchorGraph) IsPreviousAnchor(arg1 string, arg2 string, arg3 string, arg4 int) (bool, error) {
+	fake.isPreviousAnchorMutex.Lock()
+	ret, specificReturn := fake.isPreviousAnchorReturnsOnCall[len(fake.isPreviousAnchorArgsForCall)]
+	fake.isPreviousAnchorArgsForCall = append(fake.isPreviousAnchorArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.IsPreviousAnchorStub
+	fakeReturns := fake.isPreviousAnchorReturns
+	fake.recordInvocation("IsPreviousAnchor", []interface{}{arg1, arg2, arg3, arg4})
+	fake.isPreviousAnchorMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *AnchorGraph) IsPreviousAnchorCallCount() int {
+	fake.isPreviousAnchorMutex.RLock()
+	defer fake.isPreviousAnchorMutex.RUnlock()
+	return len(fake.isPreviousAnchorArgsForCall)
+}
+
+func (fake *AnchorGraph) IsPreviousAnchorCalls(stub func(string, string, string, int) (bool, error)) {
+	fake.isPreviousAnchorMutex.Lock()
+	defer fake.isPreviousAnchorMutex.Unlock()
+	fake.IsPreviousAnchorStub = stub
+}
+
+func (fake *AnchorGraph) IsPreviousAnchorArgsForCall(i int) (string, string, string, int) {
+	fake.isPreviousAnchorMutex.RLock()
+	defer fake.isPreviousAnchorMutex.RUnlock()
+	argsForCall := fake.isPreviousAnchorArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *AnchorGraph) IsPreviousAnchorReturns(result1 bool, result2 error) {
+	fake.isPreviousAnchorMutex.Lock()
+	defer fake.isPreviousAnchorMutex.Unlock()
+	fake.IsPreviousAnchorStub = nil
+	fake.isPreviousAnchorReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *AnchorGraph) IsPreviousAnchorReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.isPreviousAnchorMutex.Lock()
+	defer fake.isPreviousAnchorMutex.Unlock()
+	fake.IsPreviousAnchorStub = nil
+	if fake.isPreviousAnchorReturnsOnCall == nil {
+		fake.isPreviousAnchorReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.isPreviousAnchorReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *AnchorGraph) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.isPreviousAnchorMutex.RLock()
+	defer fake.isPreviousAnchorMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *AnchorGraph) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
@@ -21,6 +21,20 @@ type EndpointClient struct {
 		result1 *models.Endpoint
 		result2 error
 	}
+	GetEndpointFromDomainStub        func(string, string) (*models.Endpoint, error)
+	getEndpointFromDomainMutex       sync.RWMutex
+	getEndpointFromDomainArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getEndpointFromDomainReturns struct {
+		result1 *models.Endpoint
+		result2 error
+	}
+	getEndpointFromDomainReturnsOnCall map[int]struct {
+		result1 *models.Endpoint
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -31,15 +45,16 @@ func (fake *EndpointClient) GetEndpointFromAnchorOrigin(arg1 string) (*models.En
 	fake.getEndpointFromAnchorOriginArgsForCall = append(fake.getEndpointFromAnchorOriginArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.GetEndpointFromAnchorOriginStub
+	fakeReturns := fake.getEndpointFromAnchorOriginReturns
 	fake.recordInvocation("GetEndpointFromAnchorOrigin", []interface{}{arg1})
 	fake.getEndpointFromAnchorOriginMutex.Unlock()
-	if fake.GetEndpointFromAnchorOriginStub != nil {
-		return fake.GetEndpointFromAnchorOriginStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getEndpointFromAnchorOriginReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -88,11 +103,78 @@ func (fake *EndpointClient) GetEndpointFromAnchorOriginReturnsOnCall(i int, resu
 	}{result1, result2}
 }
 
+func (fake *EndpointClient) GetEndpointFromDomain(arg1 string, arg2 string) (*models.Endpoint, error) {
+	fake.getEndpointFromDomainMutex.Lock()
+	ret, specificReturn := fake.getEndpointFromDomainReturnsOnCall[len(fake.getEndpointFromDomainArgsForCall)]
+	fake.getEndpointFromDomainArgsForCall = append(fake.getEndpointFromDomainArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetEndpointFromDomainStub
+	fakeReturns := fake.getEndpointFromDomainReturns
+	fake.recordInvocation("GetEndpointFromDomain", []interface{}{arg1, arg2})
+	fake.getEndpointFromDomainMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *EndpointClient) GetEndpointFromDomainCallCount() int {
+	fake.getEndpointFromDomainMutex.RLock()
+	defer fake.getEndpointFromDomainMutex.RUnlock()
+	return len(fake.getEndpointFromDomainArgsForCall)
+}
+
+func (fake *EndpointClient) GetEndpointFromDomainCalls(stub func(string, string) (*models.Endpoint, error)) {
+	fake.getEndpointFromDomainMutex.Lock()
+	defer fake.getEndpointFromDomainMutex.Unlock()
+	fake.GetEndpointFromDomainStub = stub
+}
+
+func (fake *EndpointClient) GetEndpointFromDomainArgsForCall(i int) (string, string) {
+	fake.getEndpointFromDomainMutex.RLock()
+	defer fake.getEndpointFromDomainMutex.RUnlock()
+	argsForCall := fake.getEndpointFromDomainArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *EndpointClient) GetEndpointFromDomainReturns(result1 *models.Endpoint, result2 error) {
+	fake.getEndpointFromDomainMutex.Lock()
+	defer fake.getEndpointFromDomainMutex.Unlock()
+	fake.GetEndpointFromDomainStub = nil
+	fake.getEndpointFromDomainReturns = struct {
+		result1 *models.Endpoint
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EndpointClient) GetEndpointFromDomainReturnsOnCall(i int, result1 *models.Endpoint, result2 error) {
+	fake.getEndpointFromDomainMutex.Lock()
+	defer fake.getEndpointFromDomainMutex.Unlock()
+	fake.GetEndpointFromDomainStub = nil
+	if fake.getEndpointFromDomainReturnsOnCall == nil {
+		fake.getEndpointFromDomainReturnsOnCall = make(map[int]struct {
+			result1 *models.Endpoint
+			result2 error
+		})
+	}
+	fake.getEndpointFromDomainReturnsOnCall[i] = struct {
+		result1 *models.Endpoint
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *EndpointClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.getEndpointFromAnchorOriginMutex.RLock()
 	defer fake.getEndpointFromAnchorOriginMutex.RUnlock()
+	fake.getEndpointFromDomainMutex.RLock()
+	defer fake.getEndpointFromDomainMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -44,6 +44,8 @@ const (
 
 	namespace  = "did:orb"
 	ipfsGlobal = "https://ipfs.io"
+
+	defaultConsensusTimeout = 10 * time.Second
 )
 
 type httpClient interface {
@@ -69,6 +71,7 @@ type Client struct {
 	disableProofCheck          bool
 	docLoader                  ld.DocumentLoader
 	orbClient                  orbClient
+	consensusTimeout           time.Duration
 }
 
 type req struct {
@@ -90,7 +93,7 @@ func (d *defaultHTTPClient) Get(context.Context, *transport.Request) (*http.Resp
 func New(docLoader ld.DocumentLoader, casReader casReader, opts ...Option) (*Client, error) {
 	configService := &Client{
 		namespace: namespace, docLoader: docLoader, casReader: casReader,
-		httpClient: &defaultHTTPClient{},
+		httpClient: &defaultHTTPClient{}, consensusTimeout: defaultConsensusTimeout,
 	}
 
 	for _, opt := range opts {
@@ -112,12 +115,14 @@ func New(docLoader ld.DocumentLoader, casReader casReader, opts ...Option) (*Cli
 			)).PublicKeyFetcher()))
 	}
 
-	orbClient, err := orbclient.New(configService.namespace, configService.casReader, orbClientOpts...)
-	if err != nil {
-		return nil, err
-	}
+	if configService.orbClient == nil {
+		orbClient, err := orbclient.New(configService.namespace, configService.casReader, orbClientOpts...)
+		if err != nil {
+			return nil, err
+		}
 
-	configService.orbClient = orbClient
+		configService.orbClient = orbClient
+	}
 
 	configService.endpointsCache = makeCache(
 		configService.getNewCacheable(func(did, domain string) (cacheable, error) {
@@ -186,10 +191,11 @@ func (cs *Client) GetEndpoint(domain string) (*models.Endpoint, error) {
 	return endpoint.(*models.Endpoint), nil
 }
 
-// GetEndpointFromAnchorOrigin fetches endpoints from anchor origin, caching the value.
+// GetEndpointFromAnchorOrigin fetches endpoints from anchor origin, caching the value. If the given DID is
+// a DID URL then its parameters are ignored.
 func (cs *Client) GetEndpointFromAnchorOrigin(didURI string) (*models.Endpoint, error) {
 	endpoint, err := getEntryHelper(cs.endpointsAnchorOriginCache, req{
-		did: didURI,
+		did: getDID(didURI),
 	}, "endpointAnchorOrigin")
 	if err != nil {
 		return nil, err
@@ -198,6 +204,23 @@ func (cs *Client) GetEndpointFromAnchorOrigin(didURI string) (*models.Endpoint,
 	return endpoint.(*models.Endpoint), nil
 }
 
+// GetEndpointFromDomain fetches the endpoints of the given DID from the given domain. Unlike
+// GetEndpointFromAnchorOrigin, the anchor origin isn't followed and the value isn't cached, so the
+// returned AnchorURI is the latest anchor of the DID that is currently known to the domain.
+func (cs *Client) GetEndpointFromDomain(domain, didURI string) (*models.Endpoint, error) {
+	if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") &&
+		!strings.HasPrefix(domain, "ipns://") {
+		domain = "https://" + domain
+	}
+
+	jrd, err := cs.getLatestAnchorOrigin(domain, didURI)
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.populateAnchorResolutionEndpoint(jrd)
+}
+
 func (cs *Client) getEndpoint(domain string) (*models.Endpoint, error) {
 	var wellKnownResponse restapi.WellKnownResponse
 
@@ -410,6 +433,15 @@ func (cs *Client) getCIDAndSuffix(didURI string) (string, string, error) {
 	return cidWithHintAndSuffix[:adjustedPos-1], cidWithHintAndSuffix[adjustedPos:], nil
 }
 
+// getDID returns the DID of the given DID URL, i.e. without its parameters and fragment.
+func getDID(didURI string) string {
+	if i := strings.IndexAny(didURI, "?#"); i >= 0 {
+		return didURI[:i]
+	}
+
+	return didURI
+}
+
 func (cs *Client) getWebFingerURL(anchorOrigin string) (string, error) {
 	if strings.HasPrefix(anchorOrigin, "ipns://") {
 		anchorOriginSplit := strings.Split(anchorOrigin, "ipns://")
@@ -466,18 +498,22 @@ func (cs *Client) getLatestAnchorOrigin(anchorOrigin, didURI string) (*restapi.J
 }
 
 func (cs *Client) send(req []byte, method, endpointURL string) ([]byte, error) {
+	return cs.sendWithContext(context.Background(), req, method, endpointURL)
+}
+
+func (cs *Client) sendWithContext(ctx context.Context, req []byte, method, endpointURL string) ([]byte, error) {
 	var httpReq *http.Request
 
 	var err error
 
 	if len(req) == 0 {
-		httpReq, err = http.NewRequestWithContext(context.Background(),
+		httpReq, err = http.NewRequestWithContext(ctx,
 			method, endpointURL, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create http request: %w", err)
 		}
 	} else {
-		httpReq, err = http.NewRequestWithContext(context.Background(),
+		httpReq, err = http.NewRequestWithContext(ctx,
 			method, endpointURL, bytes.NewBuffer(req))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create http request: %w", err)
@@ -532,6 +568,22 @@ func WithHTTPClient(httpClient httpClient) Option {
 	}
 }
 
+// WithConsensusTimeout sets the maximum time to wait for the resolvers to reach consensus
+// in ResolveDIDWithConsensus.
+func WithConsensusTimeout(timeout time.Duration) Option {
+	return func(opts *Client) {
+		opts.consensusTimeout = timeout
+	}
+}
+
+// WithOrbClient sets the client that retrieves the anchor origin of a DID. By default, the anchor origin is
+// read from the anchor in the CAS.
+func WithOrbClient(orbClient orbClient) Option {
+	return func(opts *Client) {
+		opts.orbClient = orbClient
+	}
+}
+
 // WithAuthToken add auth token.
 func WithAuthToken(authToken string) Option {
 	return func(opts *Client) {
@@ -253,6 +253,65 @@ func TestConfigService_GetEndpointAnchorOrigin(t *testing.T) {
 	})
 }
 
+func TestConfigService_GetEndpointFromDomain(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		cs, err := New(nil, &mocks.CasClient{})
+		require.NoError(t, err)
+
+		var hostMetaURL string
+
+		cs.httpClient = &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			var resp interface{}
+
+			if strings.Contains(req.URL.Path, ".well-known/host-meta.json") {
+				hostMetaURL = req.URL.String()
+
+				resp = restapi.JRD{Links: []restapi.Link{{
+					Rel:      "self",
+					Template: "https://orb.domain2.com/.well-known/webfinger?resource={uri}",
+					Type:     "application/jrd+json",
+				}}}
+			} else {
+				resp = restapi.JRD{
+					Properties: map[string]interface{}{
+						minResolvers:         float64(1),
+						anchorOriginProperty: "https://orb.domain1.com",
+					},
+					Links: []restapi.Link{
+						{Href: "https://orb.domain2.com/sidetree/v1/identifiers/did:orb:a:123", Type: "application/did+ld+json"},
+						{Href: "hl:latest", Rel: "via", Type: "application/ld+json"},
+					},
+				}
+			}
+
+			b, errMarshal := json.Marshal(resp)
+			require.NoError(t, errMarshal)
+
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(b))}, nil
+		}}
+
+		endpoint, err := cs.GetEndpointFromDomain("orb.domain2.com", "did:orb:a:123")
+		require.NoError(t, err)
+		require.Equal(t, "https://orb.domain2.com/.well-known/host-meta.json", hostMetaURL)
+		require.Equal(t, "hl:latest", endpoint.AnchorURI)
+		require.Equal(t, "https://orb.domain1.com", endpoint.AnchorOrigin)
+		require.Equal(t, []string{"https://orb.domain2.com/sidetree/v1/identifiers"}, endpoint.ResolutionEndpoints)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		cs, err := New(nil, &mocks.CasClient{})
+		require.NoError(t, err)
+
+		cs.httpClient = &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, fmt.Errorf("injected error")
+		}}
+
+		_, err = cs.GetEndpointFromDomain("https://orb.domain2.com", "did:orb:a:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected error")
+	})
+}
+
 func TestConfigService_GetEndpoint(t *testing.T) { //nolint: gocyclo,gocognit,cyclop
 	t.Run("success", func(t *testing.T) {
 		cs, err := New(nil, &referenceCASReaderImplementation{}, WithAuthToken("t1"), WithHTTPClient(
@@ -600,6 +659,12 @@ func TestConfigService_GetEndpoint(t *testing.T) { //nolint: gocyclo,gocognit,cy
 	})
 }
 
+func TestGetDID(t *testing.T) {
+	require.Equal(t, "did:orb:ipfs:a:123", getDID("did:orb:ipfs:a:123"))
+	require.Equal(t, "did:orb:ipfs:a:123", getDID("did:orb:ipfs:a:123?versionId=1"))
+	require.Equal(t, "did:orb:ipfs:a:123", getDID("did:orb:ipfs:a:123#key1"))
+}
+
 func TestDefaultCASReader(t *testing.T) {
 	t.Run("success - no hint", func(t *testing.T) {
 		cs, err := New(nil, &referenceCASReaderImplementation{}, WithAuthToken("t1"), WithHTTPClient(
@@ -0,0 +1,248 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
+
+	"github.com/trustbloc/orb/pkg/discovery/endpoint/client/models"
+)
+
+// ConsensusError is returned by ResolveDIDWithConsensus when fewer than the required number of resolvers
+// returned the same result.
+type ConsensusError struct {
+	DID string
+	// MinResolvers is the number of resolvers that must agree.
+	MinResolvers int
+	// Agreed is the number of resolvers that returned the most common result.
+	Agreed int
+	// Divergent contains the resolution endpoints that returned a result that differs from the most common result.
+	Divergent []string
+	// Failed contains the resolution endpoints that failed to return a result, along with the error.
+	Failed map[string]string
+}
+
+// Error returns the error message.
+func (e *ConsensusError) Error() string {
+	var failed []string
+
+	for endpoint, errMsg := range e.Failed {
+		failed = append(failed, fmt.Sprintf("%s (%s)", endpoint, errMsg))
+	}
+
+	sort.Strings(failed)
+
+	return fmt.Sprintf("resolution consensus not reached for DID [%s] - agreed: %d, required: %d, "+
+		"divergent nodes: %s, failed nodes: %s", e.DID, e.Agreed, e.MinResolvers, e.Divergent, failed)
+}
+
+type resolutionResult struct {
+	endpoint   string
+	resolution *did.DocResolution
+	key        string
+	err        error
+}
+
+// ResolveDIDWithConsensus resolves the given DID at all of the resolution endpoints advertised by the given
+// domain (or, if the domain is empty, the anchor origin of the DID) and compares the canonical DID documents and
+// method metadata that are returned. The agreed result is returned as soon as MinResolvers resolvers have returned
+// the same result; the outstanding requests are then cancelled. So a divergent or unresponsive resolver only
+// prevents consensus if MinResolvers requires all of the resolvers to agree. A ConsensusError is returned if
+// consensus isn't reached before all resolvers have responded or the consensus timeout expires.
+// The given DID may be a DID URL with resolution parameters (e.g. versionId). The parameters are only passed to
+// the resolution endpoints; the endpoints are discovered from the DID alone.
+func (cs *Client) ResolveDIDWithConsensus(domain, didURI string) (*did.DocResolution, error) {
+	endpoint, err := cs.getResolutionEndpoint(domain, getDID(didURI))
+	if err != nil {
+		return nil, err
+	}
+
+	minResolvers := endpoint.MinResolvers
+	if minResolvers < 1 {
+		minResolvers = 1
+	}
+
+	if len(endpoint.ResolutionEndpoints) < minResolvers {
+		return nil, fmt.Errorf("%d resolution endpoints are required for consensus but only %d are available",
+			minResolvers, len(endpoint.ResolutionEndpoints))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cs.consensusTimeout)
+	defer cancel()
+
+	return waitForConsensus(didURI, minResolvers, len(endpoint.ResolutionEndpoints),
+		cs.resolveAll(ctx, endpoint.ResolutionEndpoints, didURI))
+}
+
+func (cs *Client) getResolutionEndpoint(domain, didURI string) (*models.Endpoint, error) {
+	if domain == "" {
+		return cs.GetEndpointFromAnchorOrigin(didURI)
+	}
+
+	return cs.GetEndpoint(domain)
+}
+
+// resolveAll resolves the DID at all of the given endpoints concurrently. The results are written to the returned
+// channel as they arrive. The channel is buffered so that the requests may complete after the caller stops reading.
+func (cs *Client) resolveAll(ctx context.Context, endpoints []string, didURI string) <-chan *resolutionResult {
+	results := make(chan *resolutionResult, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		go func(endpoint string) {
+			results <- cs.resolve(ctx, endpoint, didURI)
+		}(endpoint)
+	}
+
+	return results
+}
+
+func (cs *Client) resolve(ctx context.Context, endpoint, didURI string) *resolutionResult {
+	result := &resolutionResult{endpoint: endpoint}
+
+	respBytes, err := cs.sendWithContext(ctx, nil, http.MethodGet,
+		fmt.Sprintf("%s/%s", strings.TrimSuffix(endpoint, "/"), didURI))
+	if err != nil {
+		logger.Debugf("Error resolving DID [%s] at [%s]: %s", didURI, endpoint, err)
+
+		result.err = err
+
+		return result
+	}
+
+	result.key, err = getConsensusKey(respBytes)
+	if err != nil {
+		result.err = err
+
+		return result
+	}
+
+	result.resolution, err = did.ParseDocumentResolution(respBytes)
+	if err != nil {
+		result.err = fmt.Errorf("parse document resolution: %w", err)
+
+		return result
+	}
+
+	return result
+}
+
+// getConsensusKey returns the value that is compared between resolvers, i.e. the canonical DID document and
+// the canonical method metadata.
+func getConsensusKey(respBytes []byte) (string, error) {
+	resolution := &struct {
+		DIDDocument json.RawMessage `json:"didDocument"`
+		Metadata    struct {
+			Method json.RawMessage `json:"method"`
+		} `json:"didDocumentMetadata"`
+	}{}
+
+	if err := json.Unmarshal(respBytes, resolution); err != nil {
+		return "", fmt.Errorf("unmarshal document resolution: %w", err)
+	}
+
+	if len(resolution.DIDDocument) == 0 {
+		return "", errors.New("document resolution doesn't contain a DID document")
+	}
+
+	docBytes, err := canonicalizer.MarshalCanonical([]byte(resolution.DIDDocument))
+	if err != nil {
+		return "", fmt.Errorf("canonicalize DID document: %w", err)
+	}
+
+	var methodBytes []byte
+
+	if len(resolution.Metadata.Method) > 0 {
+		methodBytes, err = canonicalizer.MarshalCanonical([]byte(resolution.Metadata.Method))
+		if err != nil {
+			return "", fmt.Errorf("canonicalize method metadata: %w", err)
+		}
+	}
+
+	return string(docBytes) + "\n" + string(methodBytes), nil
+}
+
+// waitForConsensus reads the given number of results and returns the resolution as soon as minResolvers
+// resolvers agree. Otherwise a ConsensusError is returned.
+func waitForConsensus(didURI string, minResolvers, total int,
+	results <-chan *resolutionResult) (*did.DocResolution, error) {
+	groups := make(map[string][]*resolutionResult)
+	failed := make(map[string]string)
+
+	for i := 0; i < total; i++ {
+		result := <-results
+
+		if result.err != nil {
+			failed[result.endpoint] = result.err.Error()
+
+			continue
+		}
+
+		groups[result.key] = append(groups[result.key], result)
+
+		if len(groups[result.key]) < minResolvers {
+			continue
+		}
+
+		if divergent := getDivergent(groups, result.key); len(divergent) > 0 {
+			logger.Warnf("Resolution consensus reached for DID [%s] but the following nodes diverged: %s",
+				didURI, divergent)
+		}
+
+		logger.Debugf("Resolution consensus reached for DID [%s] - Agreed: %d, Failed: %d",
+			didURI, len(groups[result.key]), len(failed))
+
+		return result.resolution, nil
+	}
+
+	var (
+		agreedKey string
+		agreed    []*resolutionResult
+	)
+
+	for key, group := range groups {
+		if len(group) > len(agreed) {
+			agreedKey = key
+			agreed = group
+		}
+	}
+
+	return nil, &ConsensusError{
+		DID:          didURI,
+		MinResolvers: minResolvers,
+		Agreed:       len(agreed),
+		Divergent:    getDivergent(groups, agreedKey),
+		Failed:       failed,
+	}
+}
+
+// getDivergent returns the endpoints that returned a result other than the one with the given key.
+func getDivergent(groups map[string][]*resolutionResult, agreedKey string) []string {
+	var divergent []string
+
+	for key, group := range groups {
+		if key == agreedKey {
+			continue
+		}
+
+		for _, result := range group {
+			divergent = append(divergent, result.endpoint)
+		}
+	}
+
+	sort.Strings(divergent)
+
+	return divergent
+}
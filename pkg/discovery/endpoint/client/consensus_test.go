@@ -0,0 +1,266 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/discovery/endpoint/client/models"
+)
+
+const (
+	testDomain = "https://orb.domain1.com"
+	testDID    = "did:orb:uAAA:EiDahaOGH-liLLdDtTxEAdc8i-cfCz-WUcQdRJheMVNn3A"
+
+	node1 = "https://orb.domain1.com/sidetree/v1/identifiers"
+	node2 = "https://orb.domain2.com/sidetree/v1/identifiers"
+	node3 = "https://orb.domain3.com/sidetree/v1/identifiers"
+
+	docResolution = `{"didDocument":{"id":"%s","@context":["https://www.w3.org/ns/did/v1"]},` +
+		`"didDocumentMetadata":{"method":{"published":true,"anchorOrigin":"%s"}}}`
+
+	// docResolutionReordered is equivalent to docResolution but with the fields in a different order.
+	docResolutionReordered = `{"didDocumentMetadata":{"method":{"anchorOrigin":"%s","published":true}},` +
+		`"didDocument":{"@context":["https://www.w3.org/ns/did/v1"],"id":"%s"}}`
+)
+
+func TestClient_ResolveDIDWithConsensus(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 2, map[string]string{
+			node1: fmt.Sprintf(docResolution, testDID, testDomain),
+			node2: fmt.Sprintf(docResolutionReordered, testDomain, testDID),
+			node3: fmt.Sprintf(docResolution, testDID, testDomain),
+		}, node1, node2, node3)
+
+		resolution, err := cs.ResolveDIDWithConsensus(testDomain, testDID)
+		require.NoError(t, err)
+		require.NotNil(t, resolution)
+		require.Equal(t, testDID, resolution.DIDDocument.ID)
+	})
+
+	t.Run("success - one node failed", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 2, map[string]string{
+			node1: fmt.Sprintf(docResolution, testDID, testDomain),
+			node2: fmt.Sprintf(docResolution, testDID, testDomain),
+		}, node1, node2, node3)
+
+		resolution, err := cs.ResolveDIDWithConsensus(testDomain, testDID)
+		require.NoError(t, err)
+		require.NotNil(t, resolution)
+	})
+
+	t.Run("success - divergent node is outvoted", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 2, map[string]string{
+			node1: fmt.Sprintf(docResolution, testDID, testDomain),
+			node2: fmt.Sprintf(docResolution, testDID, testDomain),
+			node3: fmt.Sprintf(docResolution, testDID+"x", testDomain),
+		}, node1, node2, node3)
+
+		resolution, err := cs.ResolveDIDWithConsensus(testDomain, testDID)
+		require.NoError(t, err)
+		require.Equal(t, testDID, resolution.DIDDocument.ID)
+	})
+
+	t.Run("success - unresponsive node doesn't delay the result", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 2, map[string]string{
+			node1: fmt.Sprintf(docResolution, testDID, testDomain),
+			node2: fmt.Sprintf(docResolution, testDID, testDomain),
+		}, node1, node2, node3)
+
+		cs.consensusTimeout = time.Minute
+
+		unblocked := make(chan struct{})
+
+		cs.httpClient = &hangingHTTPClient{
+			httpClient: cs.httpClient,
+			endpoint:   node3,
+			unblocked:  unblocked,
+		}
+
+		resolution, err := cs.ResolveDIDWithConsensus(testDomain, testDID)
+		require.NoError(t, err)
+		require.Equal(t, testDID, resolution.DIDDocument.ID)
+
+		// The request to the unresponsive node is cancelled once consensus is reached.
+		select {
+		case <-unblocked:
+		case <-time.After(time.Second):
+			t.Fatal("request to unresponsive node wasn't cancelled")
+		}
+	})
+
+	t.Run("divergent document with unanimity required", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 3, map[string]string{
+			node1: fmt.Sprintf(docResolution, testDID, testDomain),
+			node2: fmt.Sprintf(docResolution, testDID, testDomain),
+			node3: fmt.Sprintf(docResolution, testDID+"x", testDomain),
+		}, node1, node2, node3)
+
+		_, err := cs.ResolveDIDWithConsensus(testDomain, testDID)
+		require.Error(t, err)
+
+		consensusErr := &ConsensusError{}
+		require.True(t, errors.As(err, &consensusErr))
+		require.Equal(t, 2, consensusErr.Agreed)
+		require.Equal(t, 3, consensusErr.MinResolvers)
+		require.Equal(t, []string{node3}, consensusErr.Divergent)
+		require.Empty(t, consensusErr.Failed)
+		require.Contains(t, err.Error(), "divergent nodes: ["+node3+"]")
+	})
+
+	t.Run("unresponsive node with unanimity required", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 3, map[string]string{
+			node1: fmt.Sprintf(docResolution, testDID, testDomain),
+			node2: fmt.Sprintf(docResolution, testDID, testDomain),
+		}, node1, node2, node3)
+
+		WithConsensusTimeout(50 * time.Millisecond)(cs)
+
+		cs.httpClient = &hangingHTTPClient{
+			httpClient: cs.httpClient,
+			endpoint:   node3,
+			unblocked:  make(chan struct{}),
+		}
+
+		_, err := cs.ResolveDIDWithConsensus(testDomain, testDID)
+		require.Error(t, err)
+
+		consensusErr := &ConsensusError{}
+		require.True(t, errors.As(err, &consensusErr))
+		require.Equal(t, 2, consensusErr.Agreed)
+		require.Contains(t, consensusErr.Failed[node3], context.DeadlineExceeded.Error())
+	})
+
+	t.Run("divergent method metadata", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 2, map[string]string{
+			node1: fmt.Sprintf(docResolution, testDID, testDomain),
+			node2: fmt.Sprintf(docResolution, testDID, "https://orb.domain2.com"),
+		}, node1, node2)
+
+		_, err := cs.ResolveDIDWithConsensus(testDomain, testDID)
+		require.Error(t, err)
+
+		consensusErr := &ConsensusError{}
+		require.True(t, errors.As(err, &consensusErr))
+		require.Len(t, consensusErr.Divergent, 1)
+	})
+
+	t.Run("not enough resolvers agree", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 2, map[string]string{
+			node1: fmt.Sprintf(docResolution, testDID, testDomain),
+			node2: `{"didDocumentMetadata":{}}`,
+			node3: "{",
+		}, node1, node2, node3)
+
+		_, err := cs.ResolveDIDWithConsensus(testDomain, testDID)
+		require.Error(t, err)
+
+		consensusErr := &ConsensusError{}
+		require.True(t, errors.As(err, &consensusErr))
+		require.Equal(t, 1, consensusErr.Agreed)
+		require.Empty(t, consensusErr.Divergent)
+		require.Len(t, consensusErr.Failed, 2)
+		require.Contains(t, consensusErr.Failed[node2], "doesn't contain a DID document")
+		require.Contains(t, consensusErr.Failed[node3], "unmarshal document resolution")
+	})
+
+	t.Run("all resolvers failed", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 1, nil, node1)
+
+		_, err := cs.ResolveDIDWithConsensus(testDomain, testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "agreed: 0, required: 1")
+	})
+
+	t.Run("invalid document resolution", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 1, map[string]string{
+			node1: `{"didDocument":{"id":"did:orb:123"}}`,
+		}, node1)
+
+		_, err := cs.ResolveDIDWithConsensus(testDomain, testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse document resolution")
+	})
+
+	t.Run("not enough resolution endpoints", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 3, nil, node1, node2)
+
+		_, err := cs.ResolveDIDWithConsensus(testDomain, testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "3 resolution endpoints are required for consensus but only 2 are available")
+	})
+
+	t.Run("get endpoint error", func(t *testing.T) {
+		cs := newConsensusTestClient(t, 1, nil)
+
+		_, err := cs.ResolveDIDWithConsensus("https://orb.domain4.com", testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "getting endpoint from cache")
+
+		_, err = cs.ResolveDIDWithConsensus("", "did:other:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "must start with configured namespace")
+	})
+}
+
+// hangingHTTPClient blocks requests to the given endpoint until the request is cancelled.
+type hangingHTTPClient struct {
+	httpClient
+	endpoint  string
+	unblocked chan struct{}
+}
+
+func (c *hangingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.URL.String(), c.endpoint+"/") {
+		return c.httpClient.Do(req)
+	}
+
+	<-req.Context().Done()
+
+	close(c.unblocked)
+
+	return nil, req.Context().Err()
+}
+
+func newConsensusTestClient(t *testing.T, minResolvers int, responses map[string]string,
+	endpoints ...string) *Client {
+	t.Helper()
+
+	cs, err := New(nil, &referenceCASReaderImplementation{})
+	require.NoError(t, err)
+
+	cs.httpClient = &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+		for endpoint, resp := range responses {
+			if strings.HasPrefix(req.URL.String(), endpoint+"/") {
+				require.Equal(t, endpoint+"/"+testDID, req.URL.String())
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte(resp))),
+				}, nil
+			}
+		}
+
+		return nil, fmt.Errorf("connection refused")
+	}}
+
+	require.NoError(t, cs.endpointsCache.Set(req{domain: testDomain}, &models.Endpoint{
+		ResolutionEndpoints: endpoints,
+		MinResolvers:        minResolvers,
+	}))
+
+	return cs
+}
@@ -41,6 +41,12 @@ const (
 	didLDJSONType = "application/did+ld+json"
 	// ActivityJSONType represents a link type that points to an ActivityPub endpoint.
 	ActivityJSONType = "application/activity+json"
+	// JSONLinesType represents a link type that points to an endpoint that returns JSON lines.
+	JSONLinesType = "application/x-ndjson"
+
+	// WebCASBatchRelation is the host-meta link relation of the WebCAS batch endpoint. A domain advertises
+	// that it supports batch CAS reads by including a link with this relation.
+	WebCASBatchRelation = "https://trustbloc.dev/ns/webcas-batch"
 
 	nodeInfoV2_0Schema = "http://nodeinfo.diaspora.software/ns/schema/2.0"
 	nodeInfoV2_1Schema = "http://nodeinfo.diaspora.software/ns/schema/2.1"
@@ -382,6 +388,12 @@ func (o *Operation) respondWithHostMetaJSON(rw http.ResponseWriter) {
 		})
 	}
 
+	resp.Links = append(resp.Links, Link{
+		Rel:  WebCASBatchRelation,
+		Type: JSONLinesType,
+		Href: fmt.Sprintf("%s%s/batch", o.baseURL, o.webCASPath),
+	})
+
 	writeResponse(rw, resp, http.StatusOK)
 }
 
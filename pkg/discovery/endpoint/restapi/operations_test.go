@@ -253,7 +253,7 @@ func TestHostMeta(t *testing.T) {
 			var w restapi.JRD
 
 			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &w))
-			require.Len(t, w.Links, 4)
+			require.Len(t, w.Links, 5)
 			require.Equal(t, "self", w.Links[0].Rel)
 			require.Equal(t, "application/jrd+json", w.Links[0].Type)
 			require.Equal(t, "http://base/.well-known/webfinger?resource={uri}", w.Links[0].Template)
@@ -269,6 +269,10 @@ func TestHostMeta(t *testing.T) {
 			require.Equal(t, "alternate", w.Links[3].Rel)
 			require.Equal(t, restapi.ActivityJSONType, w.Links[3].Type)
 			require.Equal(t, "http://domain1/services/orb", w.Links[3].Href)
+
+			require.Equal(t, restapi.WebCASBatchRelation, w.Links[4].Rel)
+			require.Equal(t, restapi.JSONLinesType, w.Links[4].Type)
+			require.Equal(t, "http://base/cas/batch", w.Links[4].Href)
 		})
 		t.Run("via /.well.known/host-meta.json endpoint", func(t *testing.T) {
 			c, err := restapi.New(&restapi.Config{
@@ -292,7 +296,7 @@ func TestHostMeta(t *testing.T) {
 			var w restapi.JRD
 
 			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &w))
-			require.Len(t, w.Links, 4)
+			require.Len(t, w.Links, 5)
 			require.Equal(t, "self", w.Links[0].Rel)
 			require.Equal(t, "application/jrd+json", w.Links[0].Type)
 			require.Equal(t, "http://base/.well-known/webfinger?resource={uri}", w.Links[0].Template)
@@ -308,6 +312,10 @@ func TestHostMeta(t *testing.T) {
 			require.Equal(t, "alternate", w.Links[3].Rel)
 			require.Equal(t, restapi.ActivityJSONType, w.Links[3].Type)
 			require.Equal(t, "http://domain1/services/orb", w.Links[3].Href)
+
+			require.Equal(t, restapi.WebCASBatchRelation, w.Links[4].Rel)
+			require.Equal(t, restapi.JSONLinesType, w.Links[4].Type)
+			require.Equal(t, "http://base/cas/batch", w.Links[4].Href)
 		})
 	})
 	t.Run("Accept header missing", func(t *testing.T) {
@@ -0,0 +1,210 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package historyhandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+var logger = log.New("operation-history")
+
+const (
+	idPathVariable = "id"
+
+	historyEndpoint = "/operationhistory/{" + idPathVariable + "}"
+)
+
+const (
+	notFoundResponse            = "Not Found."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+type operationStore interface {
+	Get(suffix string) ([]*operation.AnchoredOperation, error)
+}
+
+type anchorGraph interface {
+	Read(hl string) (*verifiable.Credential, error)
+}
+
+// History contains the operations that were applied to a DID in chronological order.
+type History struct {
+	Suffix     string            `json:"suffix"`
+	Operations []*OperationEntry `json:"operations"`
+}
+
+// OperationEntry contains an operation along with the provenance of the anchor in which the operation was published.
+type OperationEntry struct {
+	Type              operation.Type `json:"type"`
+	TransactionTime   string         `json:"transactionTime"`
+	TransactionNumber uint64         `json:"transactionNumber"`
+	AnchorHashLink    string         `json:"anchorHashlink"`
+	AnchorOrigin      interface{}    `json:"anchorOrigin,omitempty"`
+	// Issuer is the issuer of the anchor credential.
+	Issuer string `json:"issuer,omitempty"`
+	// Proofs contains the proofs of the anchor credential, i.e. the proof of the issuing server along with
+	// the proofs of the witnesses.
+	Proofs []verifiable.Proof `json:"proofs,omitempty"`
+	// Error contains the reason why the anchor credential could not be retrieved. The operation is still
+	// returned so that the history is complete.
+	Error string `json:"error,omitempty"`
+}
+
+// Handler returns the operations that were applied to a DID along with the anchor credential (issuer,
+// witness proofs and timestamps) behind each one. The ID in the request path may either be the DID or the
+// unique suffix of the DID.
+type Handler struct {
+	opStore     operationStore
+	anchorGraph anchorGraph
+	marshal     func(v interface{}) ([]byte, error)
+}
+
+// New returns a new operation history handler.
+func New(opStore operationStore, anchorGraph anchorGraph) *Handler {
+	return &Handler{
+		opStore:     opStore,
+		anchorGraph: anchorGraph,
+		marshal:     json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the operation history service.
+func (h *Handler) Path() string {
+	return historyEndpoint
+}
+
+// Method returns the HTTP REST method for the operation history service.
+func (h *Handler) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the operation history service.
+func (h *Handler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *Handler) handle(w http.ResponseWriter, req *http.Request) {
+	suffix := getSuffix(mux.Vars(req)[idPathVariable])
+
+	ops, err := h.opStore.Get(suffix)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeResponse(w, http.StatusNotFound, []byte(notFoundResponse))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error retrieving operations for suffix [%s]: %s", historyEndpoint, suffix, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	sortOperations(ops)
+
+	history := &History{
+		Suffix:     suffix,
+		Operations: make([]*OperationEntry, len(ops)),
+	}
+
+	for i, op := range ops {
+		history.Operations[i] = h.newOperationEntry(op)
+	}
+
+	respBytes, err := h.marshal(history)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling operation history: %s", historyEndpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, respBytes)
+}
+
+func (h *Handler) newOperationEntry(op *operation.AnchoredOperation) *OperationEntry {
+	entry := &OperationEntry{
+		Type:              op.Type,
+		TransactionTime:   time.Unix(int64(op.TransactionTime), 0).UTC().Format(time.RFC3339),
+		TransactionNumber: op.TransactionNumber,
+		AnchorHashLink:    getAnchorHashLink(op),
+		AnchorOrigin:      op.AnchorOrigin,
+	}
+
+	vc, err := h.anchorGraph.Read(entry.AnchorHashLink)
+	if err != nil {
+		logger.Warnf("[%s] Error reading anchor [%s] for suffix [%s]: %s", historyEndpoint,
+			entry.AnchorHashLink, op.UniqueSuffix, err)
+
+		entry.Error = err.Error()
+
+		return entry
+	}
+
+	entry.Issuer = vc.Issuer.ID
+	entry.Proofs = vc.Proofs
+
+	return entry
+}
+
+// getAnchorHashLink returns the hashlink of the anchor from the equivalent references of the operation (which
+// includes the links to the anchor) or, if not found, the hashlink is created from the canonical reference.
+func getAnchorHashLink(op *operation.AnchoredOperation) string {
+	for _, ref := range op.EquivalentReferences {
+		if strings.HasPrefix(ref, hashlink.HLPrefix) {
+			return ref
+		}
+	}
+
+	return hashlink.GetHashLinkFromResourceHash(op.CanonicalReference)
+}
+
+// getSuffix returns the unique suffix of the given ID, which may either be a DID or a suffix.
+func getSuffix(id string) string {
+	return id[strings.LastIndex(id, docutil.NamespaceDelimiter)+1:]
+}
+
+func sortOperations(ops []*operation.AnchoredOperation) {
+	sort.SliceStable(ops, func(i, j int) bool {
+		if ops[i].TransactionTime != ops[j].TransactionTime {
+			return ops[i].TransactionTime < ops[j].TransactionTime
+		}
+
+		return ops[i].TransactionNumber < ops[j].TransactionNumber
+	})
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("[%s] Unable to write response: %s", historyEndpoint, err)
+
+			return
+		}
+
+		logger.Debugf("[%s] Wrote response: %s", historyEndpoint, body)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package historyhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+	"github.com/trustbloc/orb/pkg/hashlink"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+)
+
+const (
+	suffix = "EiDahaOGH-liLLdDtTxEAdc8i-cfCz-WUcQdRJheMVNn3A"
+	did    = "did:orb:uEiAK4KusHyrEyiNE2fdYuOJQG8t55w6XqFdloCdKW-0jnA:" + suffix
+
+	anchor1 = "uEiAK4KusHyrEyiNE2fdYuOJQG8t55w6XqFdloCdKW-0jnA"
+	anchor2 = "uEiBsdo4Gkx7wJPpnNMNbybP_FqZwS3vSSP6iN3A4ym1ILA"
+
+	anchor2HL = "hl:" + anchor2 + ":uoQ-BeDVpcGZzOi8vYmFma3JlaWRndXp4Zw"
+)
+
+func TestHandler(t *testing.T) {
+	opStore := orbmocks.NewMockOperationStore()
+
+	require.NoError(t, opStore.Put([]*operation.AnchoredOperation{
+		{
+			Type:                 operation.TypeUpdate,
+			UniqueSuffix:         suffix,
+			TransactionTime:      1628100000,
+			CanonicalReference:   anchor2,
+			EquivalentReferences: []string{"ipfs://bafkreidguzxg", anchor2HL},
+			AnchorOrigin:         "https://orb.domain2.com",
+		},
+		{
+			Type:               operation.TypeCreate,
+			UniqueSuffix:       suffix,
+			TransactionTime:    1628000000,
+			CanonicalReference: anchor1,
+			AnchorOrigin:       "https://orb.domain1.com",
+		},
+	}))
+
+	vc := &verifiable.Credential{
+		Issuer: verifiable.Issuer{ID: "https://orb.domain1.com"},
+		Proofs: []verifiable.Proof{
+			{"domain": "https://orb.domain1.com", "created": "2021-08-03T14:13:20Z"},
+			{"domain": "https://witness.domain.com", "created": "2021-08-03T14:13:21Z"},
+		},
+	}
+
+	anchorGraph := &orbmocks.AnchorGraph{}
+	anchorGraph.ReadReturns(vc, nil)
+
+	h := New(opStore, anchorGraph)
+	require.Equal(t, "/operationhistory/{id}", h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+	require.NotNil(t, h.Handler())
+
+	t.Run("success", func(t *testing.T) {
+		for _, id := range []string{suffix, did} {
+			status, body := handle(t, h, id)
+			require.Equal(t, http.StatusOK, status)
+
+			history := &History{}
+			require.NoError(t, json.Unmarshal(body, history))
+			require.Equal(t, suffix, history.Suffix)
+			require.Len(t, history.Operations, 2)
+
+			create := history.Operations[0]
+			require.Equal(t, operation.TypeCreate, create.Type)
+			require.Equal(t, "2021-08-03T14:13:20Z", create.TransactionTime)
+			require.Equal(t, hashlink.GetHashLinkFromResourceHash(anchor1), create.AnchorHashLink)
+			require.Equal(t, "https://orb.domain1.com", create.AnchorOrigin)
+			require.Equal(t, "https://orb.domain1.com", create.Issuer)
+			require.Len(t, create.Proofs, 2)
+			require.Empty(t, create.Error)
+
+			update := history.Operations[1]
+			require.Equal(t, operation.TypeUpdate, update.Type)
+			require.Equal(t, anchor2HL, update.AnchorHashLink)
+			require.Equal(t, "https://orb.domain2.com", update.AnchorOrigin)
+		}
+	})
+
+	t.Run("anchor graph error", func(t *testing.T) {
+		anchorGraph := &orbmocks.AnchorGraph{}
+		anchorGraph.ReadReturnsOnCall(0, vc, nil)
+		anchorGraph.ReadReturnsOnCall(1, nil, errors.New("content not found"))
+
+		status, body := handle(t, New(opStore, anchorGraph), suffix)
+		require.Equal(t, http.StatusOK, status)
+
+		history := &History{}
+		require.NoError(t, json.Unmarshal(body, history))
+		require.Len(t, history.Operations, 2)
+		require.Empty(t, history.Operations[0].Error)
+		require.Equal(t, "content not found", history.Operations[1].Error)
+		require.Empty(t, history.Operations[1].Proofs)
+		require.Equal(t, anchor2HL, history.Operations[1].AnchorHashLink)
+	})
+
+	t.Run("DID not found", func(t *testing.T) {
+		status, _ := handle(t, h, "unknown")
+		require.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("operation store error", func(t *testing.T) {
+		h := New(&mockOperationStore{err: errors.New("injected error")}, anchorGraph)
+
+		status, _ := handle(t, h, suffix)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		h := New(opStore, anchorGraph)
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		status, _ := handle(t, h, suffix)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func handle(t *testing.T, h *Handler, id string) (int, []byte) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/operationhistory/"+id, nil)
+	req = mux.SetURLVars(req, map[string]string{idPathVariable: id})
+
+	rw := httptest.NewRecorder()
+
+	h.handle(rw, req)
+
+	result := rw.Result()
+
+	respBytes, err := ioutil.ReadAll(result.Body)
+	require.NoError(t, err)
+	require.NoError(t, result.Body.Close())
+
+	return result.StatusCode, respBytes
+}
+
+type mockOperationStore struct {
+	err error
+}
+
+func (m *mockOperationStore) Get(string) ([]*operation.AnchoredOperation, error) {
+	return nil, m.err
+}
@@ -0,0 +1,239 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolvehandler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/pkg/discovery/endpoint/client/models"
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+// RefreshMode specifies how a DID that was anchored by another domain is checked against its anchor origin.
+type RefreshMode string
+
+const (
+	// RefreshAsync checks the DID against its anchor origin in the background. The locally stored version
+	// of the document is returned immediately.
+	RefreshAsync RefreshMode = "async"
+	// RefreshSync checks the DID against its anchor origin before the resolution result is returned so that the
+	// freshness in the metadata is always up to date. (If a newer version is found then the new version is still
+	// retrieved in the background.)
+	RefreshSync RefreshMode = "sync"
+)
+
+// ParseRefreshMode parses the given string into a RefreshMode.
+func ParseRefreshMode(mode string) (RefreshMode, error) {
+	switch m := RefreshMode(strings.ToLower(mode)); m {
+	case RefreshAsync, RefreshSync:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid DID refresh mode [%s] - valid modes are %s and %s",
+			mode, RefreshAsync, RefreshSync)
+	}
+}
+
+// FreshnessProperty is the document metadata property that contains the freshness of the canonical ID (and
+// equivalent IDs) of a DID that was anchored by another domain.
+const FreshnessProperty = "freshness"
+
+// FreshnessStatus indicates whether the locally stored version of a DID is the latest version.
+type FreshnessStatus string
+
+const (
+	// FreshnessUnknown indicates that the DID has not (yet) been checked against its anchor origin or that the
+	// last check failed.
+	FreshnessUnknown FreshnessStatus = "unknown"
+	// FreshnessCurrent indicates that the latest anchor at the anchor origin matches the canonical ID.
+	FreshnessCurrent FreshnessStatus = "current"
+	// FreshnessStale indicates that the anchor origin has a newer anchor for the DID. Discovery of the newer
+	// version has been requested.
+	FreshnessStale FreshnessStatus = "stale"
+)
+
+const freshnessCacheSize = 10000
+
+// Freshness contains the result of the last check of a DID against its anchor origin.
+type Freshness struct {
+	Status FreshnessStatus `json:"status"`
+	// LastChecked is the time (RFC3339) at which the DID was last checked against its anchor origin.
+	LastChecked string `json:"lastChecked,omitempty"`
+	// LatestAnchor is the latest anchor at the anchor origin. It's only set if the status is 'stale'.
+	LatestAnchor string `json:"latestAnchor,omitempty"`
+}
+
+type endpointClient interface {
+	GetEndpointFromAnchorOrigin(did string) (*models.Endpoint, error)
+}
+
+// WithStaleDIDRefresh enables the freshness policy for DIDs that were anchored by another domain (i.e. DIDs whose
+// anchor origin is not the given local origin). Each of these DIDs is checked against its anchor origin at most
+// once per the given TTL and discovery is requested if the anchor origin has a newer anchor for the DID.
+func WithStaleDIDRefresh(client endpointClient, localOrigin string, ttl time.Duration, mode RefreshMode) Option {
+	return func(opts *ResolveHandler) {
+		opts.refresher = &refresher{
+			client:    client,
+			localHost: getHost(localOrigin),
+			ttl:       ttl,
+			mode:      mode,
+			checked:   gcache.New(freshnessCacheSize).LRU().Build(),
+		}
+	}
+}
+
+type refresher struct {
+	client    endpointClient
+	localHost string
+	ttl       time.Duration
+	mode      RefreshMode
+	checked   gcache.Cache
+}
+
+type freshnessEntry struct {
+	checkedTime time.Time
+	freshness   *Freshness
+}
+
+// refresh checks the resolved DID against its anchor origin (if required by the freshness policy) and adds the
+// freshness of the DID to the document metadata.
+func (r *ResolveHandler) refresh(rr *document.ResolutionResult) {
+	canonicalID, ok := rr.DocumentMetadata[document.CanonicalIDProperty].(string)
+	if !ok {
+		// The document hasn't been published.
+		return
+	}
+
+	if !r.refresher.isOutOfSystem(rr) {
+		return
+	}
+
+	var freshness *Freshness
+
+	value, err := r.refresher.checked.Get(canonicalID)
+	if err == nil && time.Since(value.(*freshnessEntry).checkedTime) < r.refresher.ttl {
+		freshness = value.(*freshnessEntry).freshness
+	} else {
+		freshness = r.refreshDID(canonicalID, value)
+	}
+
+	rr.DocumentMetadata[FreshnessProperty] = freshness
+}
+
+func (r *ResolveHandler) refreshDID(canonicalID string, previous interface{}) *Freshness {
+	if r.refresher.mode == RefreshSync {
+		return r.checkFreshness(canonicalID)
+	}
+
+	freshness := &Freshness{Status: FreshnessUnknown}
+
+	if previous != nil {
+		freshness = previous.(*freshnessEntry).freshness
+	}
+
+	// Mark the DID as checked so that only one background check is performed per TTL.
+	r.refresher.setChecked(canonicalID, freshness)
+
+	go r.checkFreshness(canonicalID)
+
+	return freshness
+}
+
+func (r *ResolveHandler) checkFreshness(canonicalID string) *Freshness {
+	freshness, err := r.getFreshness(canonicalID)
+	if err != nil {
+		logger.Warnf("Error checking freshness of DID [%s]: %s", canonicalID, err)
+
+		freshness = &Freshness{Status: FreshnessUnknown}
+	}
+
+	r.refresher.setChecked(canonicalID, freshness)
+
+	if freshness.Status == FreshnessStale {
+		logger.Infof("DID [%s] is stale - latest anchor at anchor origin: %s", canonicalID, freshness.LatestAnchor)
+
+		r.requestDiscovery(canonicalID)
+	}
+
+	return freshness
+}
+
+func (r *ResolveHandler) getFreshness(canonicalID string) (*Freshness, error) {
+	endpoint, err := r.refresher.client.GetEndpointFromAnchorOrigin(canonicalID)
+	if err != nil {
+		return nil, fmt.Errorf("get endpoint from anchor origin: %w", err)
+	}
+
+	latestCID := endpoint.AnchorURI
+
+	if strings.HasPrefix(latestCID, hashlink.HLPrefix) {
+		hlInfo, e := r.hl.ParseHashLink(latestCID)
+		if e != nil {
+			return nil, fmt.Errorf("parse latest anchor [%s]: %w", latestCID, e)
+		}
+
+		latestCID = hlInfo.ResourceHash
+	}
+
+	cid, _, err := r.getCIDAndSuffix(canonicalID)
+	if err != nil {
+		return nil, err
+	}
+
+	freshness := &Freshness{
+		Status:      FreshnessCurrent,
+		LastChecked: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if latestCID != cid {
+		freshness.Status = FreshnessStale
+		freshness.LatestAnchor = endpoint.AnchorURI
+	}
+
+	return freshness, nil
+}
+
+func (r *refresher) setChecked(canonicalID string, freshness *Freshness) {
+	err := r.checked.Set(canonicalID, &freshnessEntry{checkedTime: time.Now(), freshness: freshness})
+	if err != nil {
+		// Should never happen.
+		logger.Warnf("Error caching freshness of DID [%s]: %s", canonicalID, err)
+	}
+}
+
+// isOutOfSystem returns true if the anchor origin of the resolved DID is not the local origin.
+func (r *refresher) isOutOfSystem(rr *document.ResolutionResult) bool {
+	var anchorOrigin interface{}
+
+	switch method := rr.DocumentMetadata[document.MethodProperty].(type) {
+	case document.Metadata:
+		anchorOrigin = method[document.AnchorOriginProperty]
+	case map[string]interface{}:
+		anchorOrigin = method[document.AnchorOriginProperty]
+	}
+
+	origin, ok := anchorOrigin.(string)
+	if !ok || origin == "" {
+		return false
+	}
+
+	return getHost(origin) != r.localHost
+}
+
+func getHost(origin string) string {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return origin
+	}
+
+	return u.Host
+}
@@ -0,0 +1,224 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolvehandler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	didmocks "github.com/trustbloc/orb/pkg/discovery/did/mocks"
+	"github.com/trustbloc/orb/pkg/discovery/endpoint/client/models"
+	"github.com/trustbloc/orb/pkg/document/resolvehandler/mocks"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+)
+
+const (
+	localOrigin  = "https://orb.domain1.com"
+	remoteOrigin = "https://orb.domain2.com"
+
+	canonicalDID = "did:orb:" + anchor1 + ":suffix"
+)
+
+func TestResolveHandler_Refresh(t *testing.T) {
+	t.Run("sync - current", func(t *testing.T) {
+		endpointClient := &didmocks.EndpointClient{}
+		endpointClient.GetEndpointFromAnchorOriginReturns(&models.Endpoint{AnchorURI: "hl:" + anchor1}, nil)
+
+		discovery := &mocks.Discovery{}
+
+		handler := newRefreshTestHandler(remoteOrigin, discovery,
+			WithStaleDIDRefresh(endpointClient, localOrigin, time.Minute, RefreshSync))
+
+		rr, err := handler.ResolveDocument(canonicalDID)
+		require.NoError(t, err)
+
+		freshness, ok := rr.DocumentMetadata[FreshnessProperty].(*Freshness)
+		require.True(t, ok)
+		require.Equal(t, FreshnessCurrent, freshness.Status)
+		require.NotEmpty(t, freshness.LastChecked)
+		require.Empty(t, freshness.LatestAnchor)
+		require.Equal(t, canonicalDID, endpointClient.GetEndpointFromAnchorOriginArgsForCall(0))
+
+		// The DID isn't checked again until the TTL expires.
+		rr, err = handler.ResolveDocument(canonicalDID)
+		require.NoError(t, err)
+		require.Equal(t, freshness, rr.DocumentMetadata[FreshnessProperty])
+		require.Equal(t, 1, endpointClient.GetEndpointFromAnchorOriginCallCount())
+		require.Equal(t, 0, discovery.RequestDiscoveryCallCount())
+	})
+
+	t.Run("sync - stale", func(t *testing.T) {
+		endpointClient := &didmocks.EndpointClient{}
+		endpointClient.GetEndpointFromAnchorOriginReturns(&models.Endpoint{AnchorURI: "hl:" + anchor2}, nil)
+
+		discovery := &mocks.Discovery{}
+
+		handler := newRefreshTestHandler(remoteOrigin, discovery,
+			WithStaleDIDRefresh(endpointClient, localOrigin, time.Minute, RefreshSync))
+
+		rr, err := handler.ResolveDocument(canonicalDID)
+		require.NoError(t, err)
+
+		freshness, ok := rr.DocumentMetadata[FreshnessProperty].(*Freshness)
+		require.True(t, ok)
+		require.Equal(t, FreshnessStale, freshness.Status)
+		require.Equal(t, "hl:"+anchor2, freshness.LatestAnchor)
+		require.Equal(t, 1, discovery.RequestDiscoveryCallCount())
+		require.Equal(t, canonicalDID, discovery.RequestDiscoveryArgsForCall(0))
+	})
+
+	t.Run("sync - TTL expired", func(t *testing.T) {
+		endpointClient := &didmocks.EndpointClient{}
+		endpointClient.GetEndpointFromAnchorOriginReturns(&models.Endpoint{AnchorURI: anchor1}, nil)
+
+		handler := newRefreshTestHandler(remoteOrigin, &mocks.Discovery{},
+			WithStaleDIDRefresh(endpointClient, localOrigin, time.Nanosecond, RefreshSync))
+
+		for i := 0; i < 2; i++ {
+			rr, err := handler.ResolveDocument(canonicalDID)
+			require.NoError(t, err)
+			require.Equal(t, FreshnessCurrent, rr.DocumentMetadata[FreshnessProperty].(*Freshness).Status)
+		}
+
+		require.Equal(t, 2, endpointClient.GetEndpointFromAnchorOriginCallCount())
+	})
+
+	t.Run("async", func(t *testing.T) {
+		endpointClient := &didmocks.EndpointClient{}
+		endpointClient.GetEndpointFromAnchorOriginReturns(&models.Endpoint{AnchorURI: "hl:" + anchor2}, nil)
+
+		discovery := &mocks.Discovery{}
+
+		handler := newRefreshTestHandler(remoteOrigin, discovery,
+			WithStaleDIDRefresh(endpointClient, localOrigin, time.Minute, RefreshAsync))
+
+		rr, err := handler.ResolveDocument(canonicalDID)
+		require.NoError(t, err)
+		require.Equal(t, FreshnessUnknown, rr.DocumentMetadata[FreshnessProperty].(*Freshness).Status)
+
+		require.Eventually(t, func() bool {
+			return discovery.RequestDiscoveryCallCount() == 1
+		}, time.Second, 10*time.Millisecond)
+
+		rr, err = handler.ResolveDocument(canonicalDID)
+		require.NoError(t, err)
+		require.Equal(t, FreshnessStale, rr.DocumentMetadata[FreshnessProperty].(*Freshness).Status)
+		require.Equal(t, 1, endpointClient.GetEndpointFromAnchorOriginCallCount())
+	})
+
+	t.Run("local DID", func(t *testing.T) {
+		endpointClient := &didmocks.EndpointClient{}
+
+		handler := newRefreshTestHandler(localOrigin+"/services/orb", &mocks.Discovery{},
+			WithStaleDIDRefresh(endpointClient, localOrigin, time.Minute, RefreshSync))
+
+		rr, err := handler.ResolveDocument(canonicalDID)
+		require.NoError(t, err)
+		require.NotContains(t, rr.DocumentMetadata, FreshnessProperty)
+		require.Equal(t, 0, endpointClient.GetEndpointFromAnchorOriginCallCount())
+	})
+
+	t.Run("unpublished DID", func(t *testing.T) {
+		coreResolver := &mocks.Resolver{}
+		coreResolver.ResolveDocumentReturns(&document.ResolutionResult{}, nil)
+
+		endpointClient := &didmocks.EndpointClient{}
+
+		handler := NewResolveHandler(testNS, coreResolver, &mocks.Discovery{}, &orbmocks.AnchorGraph{},
+			&orbmocks.MetricsProvider{}, WithUnpublishedDIDLabel(testLabel),
+			WithStaleDIDRefresh(endpointClient, localOrigin, time.Minute, RefreshSync))
+
+		rr, err := handler.ResolveDocument(canonicalDID)
+		require.NoError(t, err)
+		require.NotContains(t, rr.DocumentMetadata, FreshnessProperty)
+		require.Equal(t, 0, endpointClient.GetEndpointFromAnchorOriginCallCount())
+	})
+
+	t.Run("endpoint client error", func(t *testing.T) {
+		endpointClient := &didmocks.EndpointClient{}
+		endpointClient.GetEndpointFromAnchorOriginReturns(nil, errors.New("injected error"))
+
+		discovery := &mocks.Discovery{}
+
+		handler := newRefreshTestHandler(remoteOrigin, discovery,
+			WithStaleDIDRefresh(endpointClient, localOrigin, time.Minute, RefreshSync))
+
+		rr, err := handler.ResolveDocument(canonicalDID)
+		require.NoError(t, err)
+		require.Equal(t, FreshnessUnknown, rr.DocumentMetadata[FreshnessProperty].(*Freshness).Status)
+		require.Equal(t, 0, discovery.RequestDiscoveryCallCount())
+	})
+
+	t.Run("invalid latest anchor", func(t *testing.T) {
+		endpointClient := &didmocks.EndpointClient{}
+		endpointClient.GetEndpointFromAnchorOriginReturns(&models.Endpoint{AnchorURI: "hl:xxx"}, nil)
+
+		handler := newRefreshTestHandler(remoteOrigin, &mocks.Discovery{},
+			WithStaleDIDRefresh(endpointClient, localOrigin, time.Minute, RefreshSync))
+
+		rr, err := handler.ResolveDocument(canonicalDID)
+		require.NoError(t, err)
+		require.Equal(t, FreshnessUnknown, rr.DocumentMetadata[FreshnessProperty].(*Freshness).Status)
+	})
+
+	t.Run("method metadata from create document store format", func(t *testing.T) {
+		endpointClient := &didmocks.EndpointClient{}
+		endpointClient.GetEndpointFromAnchorOriginReturns(&models.Endpoint{AnchorURI: anchor1}, nil)
+
+		handler := NewResolveHandler(testNS, newMetadataResolver(map[string]interface{}{
+			document.AnchorOriginProperty: remoteOrigin,
+		}), &mocks.Discovery{}, &orbmocks.AnchorGraph{}, &orbmocks.MetricsProvider{},
+			WithUnpublishedDIDLabel(testLabel),
+			WithStaleDIDRefresh(endpointClient, localOrigin, time.Minute, RefreshSync))
+
+		rr, err := handler.ResolveDocument(canonicalDID)
+		require.NoError(t, err)
+		require.Equal(t, FreshnessCurrent, rr.DocumentMetadata[FreshnessProperty].(*Freshness).Status)
+	})
+}
+
+func TestParseRefreshMode(t *testing.T) {
+	for _, m := range []RefreshMode{RefreshAsync, RefreshSync} {
+		mode, err := ParseRefreshMode(string(m))
+		require.NoError(t, err)
+		require.Equal(t, m, mode)
+	}
+
+	mode, err := ParseRefreshMode("SYNC")
+	require.NoError(t, err)
+	require.Equal(t, RefreshSync, mode)
+
+	_, err = ParseRefreshMode("xxx")
+	require.EqualError(t, err, "invalid DID refresh mode [xxx] - valid modes are async and sync")
+}
+
+func newRefreshTestHandler(anchorOrigin string, discovery *mocks.Discovery, opts ...Option) *ResolveHandler {
+	return NewResolveHandler(testNS, newMetadataResolver(document.Metadata{
+		document.AnchorOriginProperty: anchorOrigin,
+	}), discovery, &orbmocks.AnchorGraph{}, &orbmocks.MetricsProvider{},
+		append([]Option{WithUnpublishedDIDLabel(testLabel)}, opts...)...)
+}
+
+// newMetadataResolver returns a resolver that returns a new published resolution result (with the given method
+// metadata) on each call.
+func newMetadataResolver(methodMetadata interface{}) *mocks.Resolver {
+	coreResolver := &mocks.Resolver{}
+	coreResolver.ResolveDocumentStub = func(string) (*document.ResolutionResult, error) {
+		return &document.ResolutionResult{
+			DocumentMetadata: document.Metadata{
+				document.CanonicalIDProperty: canonicalDID,
+				document.MethodProperty:      methodMetadata,
+			},
+		}, nil
+	}
+
+	return coreResolver
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/trustbloc/edge-core/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/processor"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
 
 	"github.com/trustbloc/orb/pkg/context/common"
@@ -42,6 +43,11 @@ type ResolveHandler struct {
 
 	enableCreateDocumentStore bool
 
+	opStore                 processor.OperationStoreClient
+	versionResolverProvider VersionResolverProvider
+
+	refresher *refresher
+
 	hl *hashlink.HashLink
 }
 
@@ -146,6 +152,10 @@ func (r *ResolveHandler) ResolveDocument(id string) (*document.ResolutionResult,
 		if err != nil {
 			return nil, err
 		}
+
+		if r.refresher != nil {
+			r.refresh(response)
+		}
 	}
 
 	return response, nil
@@ -0,0 +1,114 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolvehandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const idPathVariable = "id"
+
+type versionResolver interface {
+	ResolveDocumentVersion(id, versionID, versionTime string) (*document.ResolutionResult, error)
+}
+
+// RESTHandler resolves DID documents. It replaces the Sidetree resolve handler in order to support the 'versionId'
+// and 'versionTime' resolution parameters. The parameters may be passed either as query parameters or as DID URL
+// parameters (i.e. as part of the ID, for example did:orb:uAAA:suffix?versionId=1).
+type RESTHandler struct {
+	path     string
+	resolver versionResolver
+}
+
+// NewRESTHandler returns a new DID document resolve handler.
+func NewRESTHandler(basePath string, resolver versionResolver) *RESTHandler {
+	return &RESTHandler{
+		path:     fmt.Sprintf("%s/{%s}", basePath, idPathVariable),
+		resolver: resolver,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the resolve handler.
+func (h *RESTHandler) Path() string {
+	return h.path
+}
+
+// Method returns the HTTP REST method for the resolve handler.
+func (h *RESTHandler) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the resolve handler.
+func (h *RESTHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *RESTHandler) handle(rw http.ResponseWriter, req *http.Request) {
+	id, params, err := getIDAndParams(req)
+	if err != nil {
+		common.WriteError(rw, http.StatusBadRequest, err)
+
+		return
+	}
+
+	logger.Debugf("Resolving DID document for ID [%s], params: %s", id, params)
+
+	rr, err := h.resolver.ResolveDocumentVersion(id, params.Get(VersionIDParam), params.Get(VersionTimeParam))
+	if err != nil {
+		switch {
+		case orberrors.IsBadRequest(err) || strings.Contains(err.Error(), "bad request"):
+			common.WriteError(rw, http.StatusBadRequest, err)
+		case strings.Contains(err.Error(), "not found"):
+			common.WriteError(rw, http.StatusNotFound, errors.New("document not found"))
+		default:
+			logger.Errorf("Error resolving DID document for ID [%s]: %s", id, err)
+
+			common.WriteError(rw, http.StatusInternalServerError, err)
+		}
+
+		return
+	}
+
+	logger.Debugf("... resolved DID document for ID [%s]: %s", id, rr.Document)
+
+	common.WriteResponse(rw, http.StatusOK, rr)
+}
+
+// getIDAndParams returns the ID (without DID URL parameters) along with the resolution parameters, which are
+// merged from the DID URL and the query parameters of the request.
+func getIDAndParams(req *http.Request) (string, url.Values, error) {
+	id := mux.Vars(req)[idPathVariable]
+	params := req.URL.Query()
+
+	pos := strings.Index(id, "?")
+	if pos == -1 {
+		return id, params, nil
+	}
+
+	didURLParams, err := url.ParseQuery(id[pos+1:])
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid DID URL parameters in [%s]: %w", id, err)
+	}
+
+	for name, values := range didURLParams {
+		for _, value := range values {
+			params.Add(name, value)
+		}
+	}
+
+	return id[:pos], params, nil
+}
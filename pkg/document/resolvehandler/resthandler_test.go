@@ -0,0 +1,115 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolvehandler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+func TestRESTHandler(t *testing.T) {
+	h := NewRESTHandler("/sidetree/v1/identifiers", &mockVersionedResolver{})
+	require.Equal(t, "/sidetree/v1/identifiers/{id}", h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+	require.NotNil(t, h.Handler())
+
+	t.Run("success - latest version", func(t *testing.T) {
+		resolver := &mockVersionedResolver{}
+
+		rw := handleResolve(t, NewRESTHandler("", resolver), testDID, "")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Contains(t, rw.Body.String(), testDID)
+		require.Equal(t, testDID, resolver.id)
+		require.Empty(t, resolver.versionID)
+		require.Empty(t, resolver.versionTime)
+	})
+
+	t.Run("success - query parameters", func(t *testing.T) {
+		resolver := &mockVersionedResolver{}
+
+		rw := handleResolve(t, NewRESTHandler("", resolver), testDID, "versionId=1")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, testDID, resolver.id)
+		require.Equal(t, "1", resolver.versionID)
+
+		rw = handleResolve(t, NewRESTHandler("", resolver), testDID, "versionTime=2021-08-01T10%3A00%3A00Z")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "2021-08-01T10:00:00Z", resolver.versionTime)
+	})
+
+	t.Run("success - DID URL parameters", func(t *testing.T) {
+		resolver := &mockVersionedResolver{}
+
+		rw := handleResolve(t, NewRESTHandler("", resolver), testDID+"?versionTime=2021-08-01T10%3A00%3A00Z", "")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, testDID, resolver.id)
+		require.Equal(t, "2021-08-01T10:00:00Z", resolver.versionTime)
+	})
+
+	t.Run("invalid DID URL parameters", func(t *testing.T) {
+		rw := handleResolve(t, NewRESTHandler("", &mockVersionedResolver{}), testDID+"?versionId=%zz", "")
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+
+	t.Run("resolver errors", func(t *testing.T) {
+		for err, status := range map[error]int{
+			orberrors.NewBadRequest(errors.New("invalid version")): http.StatusBadRequest,
+			errors.New("bad request: invalid DID"):                 http.StatusBadRequest,
+			errors.New("version [3] not found"):                    http.StatusNotFound,
+			errors.New("injected error"):                           http.StatusInternalServerError,
+		} {
+			rw := handleResolve(t, NewRESTHandler("", &mockVersionedResolver{err: err}), testDID, "versionId=3")
+			require.Equal(t, status, rw.Code)
+		}
+	})
+}
+
+func handleResolve(t *testing.T, h *RESTHandler, id, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	target := "/identifiers/" + id
+	if query != "" {
+		target += "?" + query
+	}
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req = mux.SetURLVars(req, map[string]string{idPathVariable: id})
+
+	rw := httptest.NewRecorder()
+
+	h.handle(rw, req)
+
+	return rw
+}
+
+type mockVersionedResolver struct {
+	id          string
+	versionID   string
+	versionTime string
+	err         error
+}
+
+func (m *mockVersionedResolver) ResolveDocumentVersion(id, versionID,
+	versionTime string) (*document.ResolutionResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.id = id
+	m.versionID = versionID
+	m.versionTime = versionTime
+
+	return &document.ResolutionResult{Document: document.Document{"id": id}}, nil
+}
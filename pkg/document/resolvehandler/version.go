@@ -0,0 +1,233 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolvehandler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/processor"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+const (
+	// VersionIDParam is the DID resolution parameter that specifies the version of the document to resolve. The
+	// value is either the index of the operation (starting at 0 for the 'create' operation) or the hashlink (or CID)
+	// of the anchor that contains the operation.
+	VersionIDParam = "versionId"
+	// VersionTimeParam is the DID resolution parameter that specifies the time (RFC3339) at which to resolve the
+	// document. The document is resolved using all of the operations that were anchored at or before the given time.
+	VersionTimeParam = "versionTime"
+
+	// VersionIDProperty is the document metadata property that contains the version ID of the resolved document.
+	VersionIDProperty = "versionId"
+	// NextVersionIDProperty is the document metadata property that contains the version ID of the next version
+	// of the resolved document. The property is omitted if the resolved version is the latest version.
+	NextVersionIDProperty = "nextVersionId"
+	// NextUpdateProperty is the document metadata property that contains the time (RFC3339) of the next update
+	// of the resolved document. The property is omitted if the resolved version is the latest version.
+	NextUpdateProperty = "nextUpdate"
+)
+
+// VersionResolverProvider returns a resolver that resolves documents using the operations in the given store.
+type VersionResolverProvider func(opStore processor.OperationStoreClient) dochandler.Resolver
+
+// WithVersionResolution enables resolution of previous versions of a document using the 'versionId' and
+// 'versionTime' resolution parameters. The operations of the document are loaded from the given operation
+// store and the provider is invoked to create a resolver that only sees the operations up to the requested
+// version.
+func WithVersionResolution(opStore processor.OperationStoreClient, provider VersionResolverProvider) Option {
+	return func(opts *ResolveHandler) {
+		opts.opStore = opStore
+		opts.versionResolverProvider = provider
+	}
+}
+
+// ResolveDocumentVersion resolves the version of the document specified by either versionID or versionTime. If both
+// are empty then the latest version of the document is resolved. The returned document metadata contains the
+// version ID of the resolved document and, if a later version exists, the ID and time of the next version.
+func (r *ResolveHandler) ResolveDocumentVersion(id, versionID, versionTime string) (*document.ResolutionResult, error) {
+	if versionID == "" && versionTime == "" {
+		return r.ResolveDocument(id)
+	}
+
+	if r.versionResolverProvider == nil {
+		return nil, orberrors.NewBadRequest(errors.New("version resolution is not supported"))
+	}
+
+	filter, err := r.newVersionFilter(versionID, versionTime)
+	if err != nil {
+		return nil, orberrors.NewBadRequest(err)
+	}
+
+	// Resolve the latest version first in order to make sure that the ID is valid (i.e. that the CID in the ID
+	// belongs to the document) and to trigger discovery if the document isn't found.
+	if _, err = r.ResolveDocument(id); err != nil {
+		return nil, err
+	}
+
+	opStore := &versionedOperationStore{store: r.opStore, filter: filter}
+
+	rr, err := r.versionResolverProvider(opStore).ResolveDocument(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if rr.DocumentMetadata == nil {
+		rr.DocumentMetadata = make(document.Metadata)
+	}
+
+	rr.DocumentMetadata[VersionIDProperty] = strconv.Itoa(opStore.index)
+
+	if opStore.next != nil {
+		rr.DocumentMetadata[NextVersionIDProperty] = strconv.Itoa(opStore.index + 1)
+		rr.DocumentMetadata[NextUpdateProperty] = formatTransactionTime(opStore.next.TransactionTime)
+	}
+
+	return rr, nil
+}
+
+// versionFilter returns the number of operations (from the start of the given sorted list of operations) that make
+// up the requested version.
+type versionFilter func(ops []*operation.AnchoredOperation) (int, error)
+
+func (r *ResolveHandler) newVersionFilter(versionID, versionTime string) (versionFilter, error) {
+	switch {
+	case versionID != "" && versionTime != "":
+		return nil, fmt.Errorf("only one of %s and %s may be specified", VersionIDParam, VersionTimeParam)
+	case versionTime != "":
+		t, err := time.Parse(time.RFC3339, versionTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s [%s]: %w", VersionTimeParam, versionTime, err)
+		}
+
+		return newTimeFilter(t), nil
+	default:
+		return r.newVersionIDFilter(versionID)
+	}
+}
+
+func (r *ResolveHandler) newVersionIDFilter(versionID string) (versionFilter, error) {
+	if index, err := strconv.Atoi(versionID); err == nil {
+		if index < 0 {
+			return nil, fmt.Errorf("invalid %s [%s]", VersionIDParam, versionID)
+		}
+
+		return newIndexFilter(index), nil
+	}
+
+	anchorCID := versionID
+
+	if strings.HasPrefix(versionID, hashlink.HLPrefix) {
+		hlInfo, err := r.hl.ParseHashLink(versionID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s [%s]: %w", VersionIDParam, versionID, err)
+		}
+
+		anchorCID = hlInfo.ResourceHash
+	}
+
+	return newAnchorFilter(anchorCID), nil
+}
+
+func newIndexFilter(index int) versionFilter {
+	return func(ops []*operation.AnchoredOperation) (int, error) {
+		if index >= len(ops) {
+			return 0, fmt.Errorf("version [%d] not found", index)
+		}
+
+		return index + 1, nil
+	}
+}
+
+func newTimeFilter(t time.Time) versionFilter {
+	return func(ops []*operation.AnchoredOperation) (int, error) {
+		n := 0
+
+		for _, op := range ops {
+			if op.TransactionTime > uint64(t.Unix()) {
+				break
+			}
+
+			n++
+		}
+
+		if n == 0 {
+			return 0, fmt.Errorf("version at time [%s] not found", t.Format(time.RFC3339))
+		}
+
+		return n, nil
+	}
+}
+
+func newAnchorFilter(anchorCID string) versionFilter {
+	return func(ops []*operation.AnchoredOperation) (int, error) {
+		for i, op := range ops {
+			if op.CanonicalReference == anchorCID {
+				return i + 1, nil
+			}
+		}
+
+		return 0, fmt.Errorf("version for anchor [%s] not found", anchorCID)
+	}
+}
+
+// versionedOperationStore returns only the operations of a document that belong to the requested version. It also
+// records the index of the last operation of the version along with the operation that follows it (if any).
+type versionedOperationStore struct {
+	store  processor.OperationStoreClient
+	filter versionFilter
+
+	index int
+	next  *operation.AnchoredOperation
+}
+
+func (s *versionedOperationStore) Get(suffix string) ([]*operation.AnchoredOperation, error) {
+	ops, err := s.store.Get(suffix)
+	if err != nil {
+		return nil, err
+	}
+
+	sortOperations(ops)
+
+	n, err := s.filter(ops)
+	if err != nil {
+		return nil, fmt.Errorf("suffix [%s]: %w", suffix, err)
+	}
+
+	s.index = n - 1
+
+	if n < len(ops) {
+		s.next = ops[n]
+	}
+
+	return ops[:n], nil
+}
+
+// sortOperations sorts the operations in the same order as the Sidetree operation processor.
+func sortOperations(ops []*operation.AnchoredOperation) {
+	sort.SliceStable(ops, func(i, j int) bool {
+		if ops[i].TransactionTime != ops[j].TransactionTime {
+			return ops[i].TransactionTime < ops[j].TransactionTime
+		}
+
+		return ops[i].TransactionNumber < ops[j].TransactionNumber
+	})
+}
+
+func formatTransactionTime(transactionTime uint64) string {
+	return time.Unix(int64(transactionTime), 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolvehandler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/processor"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+
+	"github.com/trustbloc/orb/pkg/document/resolvehandler/mocks"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+)
+
+const (
+	anchor1 = "uEiAK4KusHyrEyiNE2fdYuOJQG8t55w6XqFdloCdKW-0jnA"
+	anchor2 = "uEiDahaOGH-liLLdDtTxEAdc8i-cfCz-WUcQdRJheMVNn3A"
+	anchor3 = "uEiBsdo4Gkx7wJPpnNMNbybP_FqZwS3vSSP6iN3A4ym1ILA"
+
+	// time1, time2 and time3 are the transaction times of the three test operations.
+	time1 = 1628000000
+	time2 = 1628100000
+	time3 = 1628200000
+)
+
+func TestResolveHandler_ResolveDocumentVersion(t *testing.T) {
+	opStore := orbmocks.NewMockOperationStore()
+
+	// Add the operations out of order to ensure that they're sorted.
+	require.NoError(t, opStore.Put([]*operation.AnchoredOperation{
+		{Type: operation.TypeUpdate, UniqueSuffix: "suffix", TransactionTime: time3, CanonicalReference: anchor3},
+		{Type: operation.TypeCreate, UniqueSuffix: "suffix", TransactionTime: time1, CanonicalReference: anchor1},
+		{Type: operation.TypeUpdate, UniqueSuffix: "suffix", TransactionTime: time2, CanonicalReference: anchor2},
+	}))
+
+	coreResolver := &mocks.Resolver{}
+	coreResolver.ResolveDocumentReturns(&document.ResolutionResult{}, nil)
+
+	handler := NewResolveHandler(testNS, coreResolver, &mocks.Discovery{}, &orbmocks.AnchorGraph{},
+		&orbmocks.MetricsProvider{}, WithUnpublishedDIDLabel(testLabel),
+		WithVersionResolution(opStore, newMockVersionResolver))
+
+	t.Run("latest version", func(t *testing.T) {
+		rr, err := handler.ResolveDocumentVersion(testDID, "", "")
+		require.NoError(t, err)
+		require.NotContains(t, rr.DocumentMetadata, VersionIDProperty)
+	})
+
+	t.Run("version ID - operation index", func(t *testing.T) {
+		rr, err := handler.ResolveDocumentVersion(testDID, "1", "")
+		require.NoError(t, err)
+		require.Equal(t, []string{anchor1, anchor2}, rr.Document["anchors"])
+		require.Equal(t, "1", rr.DocumentMetadata[VersionIDProperty])
+		require.Equal(t, "2", rr.DocumentMetadata[NextVersionIDProperty])
+		require.Equal(t, "2021-08-05T21:46:40Z", rr.DocumentMetadata[NextUpdateProperty])
+
+		rr, err = handler.ResolveDocumentVersion(testDID, "2", "")
+		require.NoError(t, err)
+		require.Equal(t, []string{anchor1, anchor2, anchor3}, rr.Document["anchors"])
+		require.Equal(t, "2", rr.DocumentMetadata[VersionIDProperty])
+		require.NotContains(t, rr.DocumentMetadata, NextVersionIDProperty)
+		require.NotContains(t, rr.DocumentMetadata, NextUpdateProperty)
+	})
+
+	t.Run("version ID - anchor", func(t *testing.T) {
+		rr, err := handler.ResolveDocumentVersion(testDID, anchor1, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{anchor1}, rr.Document["anchors"])
+		require.Equal(t, "0", rr.DocumentMetadata[VersionIDProperty])
+		require.Equal(t, "1", rr.DocumentMetadata[NextVersionIDProperty])
+
+		rr, err = handler.ResolveDocumentVersion(testDID, "hl:"+anchor2, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{anchor1, anchor2}, rr.Document["anchors"])
+		require.Equal(t, "1", rr.DocumentMetadata[VersionIDProperty])
+	})
+
+	t.Run("version time", func(t *testing.T) {
+		rr, err := handler.ResolveDocumentVersion(testDID, "", formatTransactionTime(time2+1))
+		require.NoError(t, err)
+		require.Equal(t, []string{anchor1, anchor2}, rr.Document["anchors"])
+		require.Equal(t, "1", rr.DocumentMetadata[VersionIDProperty])
+		require.Equal(t, formatTransactionTime(time3), rr.DocumentMetadata[NextUpdateProperty])
+
+		rr, err = handler.ResolveDocumentVersion(testDID, "", formatTransactionTime(time2))
+		require.NoError(t, err)
+		require.Equal(t, "1", rr.DocumentMetadata[VersionIDProperty])
+
+		rr, err = handler.ResolveDocumentVersion(testDID, "", time.Now().Format(time.RFC3339))
+		require.NoError(t, err)
+		require.Equal(t, "2", rr.DocumentMetadata[VersionIDProperty])
+	})
+
+	t.Run("version not found", func(t *testing.T) {
+		_, err := handler.ResolveDocumentVersion(testDID, "3", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "version [3] not found")
+
+		_, err = handler.ResolveDocumentVersion(testDID, anchor1+"x", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+
+		_, err = handler.ResolveDocumentVersion(testDID, "", formatTransactionTime(time1-1))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		_, err := handler.ResolveDocumentVersion(testDID, "1", formatTransactionTime(time1))
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "only one of versionId and versionTime may be specified")
+
+		_, err = handler.ResolveDocumentVersion(testDID, "-1", "")
+		require.True(t, orberrors.IsBadRequest(err))
+
+		_, err = handler.ResolveDocumentVersion(testDID, "hl:xxx:yyy", "")
+		require.True(t, orberrors.IsBadRequest(err))
+
+		_, err = handler.ResolveDocumentVersion(testDID, "", "2021-08-01")
+		require.True(t, orberrors.IsBadRequest(err))
+	})
+
+	t.Run("version resolution not supported", func(t *testing.T) {
+		handler := NewResolveHandler(testNS, coreResolver, &mocks.Discovery{}, &orbmocks.AnchorGraph{},
+			&orbmocks.MetricsProvider{})
+
+		_, err := handler.ResolveDocumentVersion(testDID, "1", "")
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "version resolution is not supported")
+	})
+
+	t.Run("latest version not found", func(t *testing.T) {
+		coreResolver := &mocks.Resolver{}
+		coreResolver.ResolveDocumentReturns(nil, errors.New("not found"))
+
+		handler := NewResolveHandler(testNS, coreResolver, &mocks.Discovery{}, &orbmocks.AnchorGraph{},
+			&orbmocks.MetricsProvider{}, WithVersionResolution(opStore, newMockVersionResolver))
+
+		_, err := handler.ResolveDocumentVersion(testDID, "1", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("operation store error", func(t *testing.T) {
+		handler := NewResolveHandler(testNS, coreResolver, &mocks.Discovery{}, &orbmocks.AnchorGraph{},
+			&orbmocks.MetricsProvider{}, WithVersionResolution(orbmocks.NewMockOperationStore(), newMockVersionResolver))
+
+		_, err := handler.ResolveDocumentVersion(testDID, "1", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "uniqueSuffix not found in the store")
+	})
+}
+
+// mockVersionResolver returns a document that contains the anchors of the operations returned by the store.
+type mockVersionResolver struct {
+	opStore processor.OperationStoreClient
+}
+
+func newMockVersionResolver(opStore processor.OperationStoreClient) dochandler.Resolver {
+	return &mockVersionResolver{opStore: opStore}
+}
+
+func (m *mockVersionResolver) ResolveDocument(string) (*document.ResolutionResult, error) {
+	ops, err := m.opStore.Get("suffix")
+	if err != nil {
+		return nil, err
+	}
+
+	var anchors []string
+
+	for _, op := range ops {
+		anchors = append(anchors, op.CanonicalReference)
+	}
+
+	return &document.ResolutionResult{Document: document.Document{"anchors": anchors}}, nil
+}
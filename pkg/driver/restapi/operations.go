@@ -6,17 +6,25 @@ SPDX-License-Identifier: Apache-2.0
 package restapi
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/trustbloc/edge-core/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	discoveryclient "github.com/trustbloc/orb/pkg/discovery/endpoint/client"
 )
 
 const (
 	resolveDIDEndpoint = "/resolveDID"
 	didLDJson          = "application/did+ld+json"
+
+	versionIDParam   = "versionId"
+	versionTimeParam = "versionTime"
 )
 
 var logger = log.New("driver")
@@ -28,19 +36,35 @@ type Handler interface {
 	Handle() http.HandlerFunc
 }
 
+type consensusResolver interface {
+	ResolveDIDWithConsensus(domain, didURI string) (*did.DocResolution, error)
+}
+
 // Operation defines handlers.
 type Operation struct {
-	orbVDR vdr.VDR
+	orbVDR            vdr.VDR
+	consensusResolver consensusResolver
+	domain            string
 }
 
 // Config defines configuration for driver operations.
 type Config struct {
 	OrbVDR vdr.VDR
+	// ConsensusResolver, if set, is used to resolve DIDs instead of OrbVDR. The DID is resolved at multiple
+	// resolution endpoints and the result is only returned if the endpoints agree.
+	ConsensusResolver consensusResolver
+	// Domain is the discovery domain from which the resolution endpoints are discovered. If empty then the
+	// resolution endpoints are discovered from the anchor origin of the DID.
+	Domain string
 }
 
 // New returns driver operation instance.
 func New(config *Config) *Operation {
-	return &Operation{orbVDR: config.OrbVDR}
+	return &Operation{
+		orbVDR:            config.OrbVDR,
+		consensusResolver: config.ConsensusResolver,
+		domain:            config.Domain,
+	}
 }
 
 func (o *Operation) resolveDIDHandler(rw http.ResponseWriter, req *http.Request) {
@@ -52,8 +76,17 @@ func (o *Operation) resolveDIDHandler(rw http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	DocResolution, err := o.orbVDR.Read(didParam[0])
+	DocResolution, err := o.resolve(didParam[0], req.URL.Query())
 	if err != nil {
+		var consensusErr *discoveryclient.ConsensusError
+
+		if errors.As(err, &consensusErr) {
+			o.writeErrorResponse(rw, http.StatusConflict,
+				fmt.Sprintf("failed to resolve did: %s", err.Error()))
+
+			return
+		}
+
 		o.writeErrorResponse(rw, http.StatusBadRequest,
 			fmt.Sprintf("failed to resolve did: %s", err.Error()))
 
@@ -76,6 +109,42 @@ func (o *Operation) resolveDIDHandler(rw http.ResponseWriter, req *http.Request)
 	}
 }
 
+func (o *Operation) resolve(didID string, query url.Values) (*did.DocResolution, error) {
+	didURL := getDIDURL(didID, query)
+
+	if o.consensusResolver != nil {
+		// The consensus resolver discovers the resolution endpoints from the DID alone.
+		return o.consensusResolver.ResolveDIDWithConsensus(o.domain, didURL)
+	}
+
+	// The VDR discovers the resolution endpoints from the DID URL that it's given, which only works for a DID URL
+	// with parameters if the endpoints are discovered from the domain.
+	if didURL != didID && o.domain == "" {
+		return nil, fmt.Errorf("the '%s' and '%s' parameters are only supported if a discovery domain is configured",
+			versionIDParam, versionTimeParam)
+	}
+
+	return o.orbVDR.Read(didURL)
+}
+
+// getDIDURL appends the 'versionId' and 'versionTime' resolution parameters (if provided in the request) to the DID
+// as DID URL parameters so that they're passed through to the resolution endpoint.
+func getDIDURL(didID string, query url.Values) string {
+	params := url.Values{}
+
+	for _, param := range []string{versionIDParam, versionTimeParam} {
+		if value := query.Get(param); value != "" {
+			params.Set(param, value)
+		}
+	}
+
+	if len(params) == 0 {
+		return didID
+	}
+
+	return didID + "?" + params.Encode()
+}
+
 // writeErrorResponse writes interface value to response.
 func (o *Operation) writeErrorResponse(rw http.ResponseWriter, status int, msg string) {
 	rw.WriteHeader(status)
@@ -7,9 +7,11 @@ package restapi_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/gorilla/mux"
@@ -19,6 +21,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 
+	discoveryclient "github.com/trustbloc/orb/pkg/discovery/endpoint/client"
+	discoveryrest "github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
 	"github.com/trustbloc/orb/pkg/driver/restapi"
 )
 
@@ -67,6 +71,214 @@ func TestDIDResolve(t *testing.T) {
 		require.Equal(t, http.StatusOK, rr.Code)
 		require.Contains(t, rr.Body.String(), "did1")
 	})
+
+	t.Run("test success - version parameters", func(t *testing.T) {
+		var resolvedDID string
+
+		c := restapi.New(&restapi.Config{
+			OrbVDR: &mockvdr.MockVDR{
+				ReadFunc: func(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+					resolvedDID = didID
+
+					return &did.DocResolution{DIDDocument: &did.Doc{ID: "did1"}}, nil
+				},
+			},
+			Domain: "https://orb.domain1.com",
+		})
+
+		handler := getHandler(t, c, resolveDIDEndpoint)
+
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?did=did1&versionId=2", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "did1?versionId=2", resolvedDID)
+
+		rr = serveHTTP(t, handler.Handler(), http.MethodGet,
+			resolveDIDEndpoint+"?did=did1&versionTime=2021-08-01T10:00:00Z", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "did1?versionTime=2021-08-01T10%3A00%3A00Z", resolvedDID)
+	})
+
+	t.Run("test version parameters without discovery domain", func(t *testing.T) {
+		c := restapi.New(&restapi.Config{OrbVDR: &mockvdr.MockVDR{
+			ReadFunc: func(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+				return nil, fmt.Errorf("VDR should not be called")
+			},
+		}})
+
+		handler := getHandler(t, c, resolveDIDEndpoint)
+
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?did=did1&versionId=2", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "only supported if a discovery domain is configured")
+	})
+}
+
+func TestDIDResolveWithConsensus(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		resolver := &mockConsensusResolver{resolution: &did.DocResolution{DIDDocument: &did.Doc{ID: "did1"}}}
+
+		c := restapi.New(&restapi.Config{
+			OrbVDR: &mockvdr.MockVDR{
+				ReadFunc: func(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+					return nil, fmt.Errorf("VDR should not be called")
+				},
+			},
+			ConsensusResolver: resolver,
+			Domain:            "https://orb.domain1.com",
+		})
+
+		handler := getHandler(t, c, resolveDIDEndpoint)
+
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?did=did1", nil, nil)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Contains(t, rr.Body.String(), "did1")
+		require.Equal(t, "https://orb.domain1.com", resolver.domain)
+	})
+
+	t.Run("test success - version parameters", func(t *testing.T) {
+		resolver := &mockConsensusResolver{resolution: &did.DocResolution{DIDDocument: &did.Doc{ID: "did1"}}}
+
+		c := restapi.New(&restapi.Config{ConsensusResolver: resolver})
+
+		handler := getHandler(t, c, resolveDIDEndpoint)
+
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?did=did1&versionId=1", nil, nil)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "did1?versionId=1", resolver.didURI)
+	})
+
+	t.Run("test success - anchor origin discovery with version parameters", func(t *testing.T) {
+		const testDID = "did:orb:uAAA:EiA329wd6Aj36YRmp7NGkeB5ADnVt8ARdMZMPzfXsjwTJA"
+
+		var (
+			mutex          sync.Mutex
+			webFingerURIs  []string
+			resolutionURIs []string
+		)
+
+		var serverURL string
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			var resp interface{}
+
+			switch {
+			case r.URL.Path == "/.well-known/host-meta.json":
+				resp = discoveryrest.JRD{Links: []discoveryrest.Link{{
+					Rel:      "self",
+					Type:     "application/jrd+json",
+					Template: serverURL + "/.well-known/webfinger?resource={uri}",
+				}}}
+			case r.URL.Path == "/.well-known/webfinger":
+				resource := r.URL.Query().Get("resource")
+
+				webFingerURIs = append(webFingerURIs, resource)
+
+				resp = discoveryrest.JRD{
+					Properties: map[string]interface{}{
+						"https://trustbloc.dev/ns/min-resolvers": 2,
+						"https://trustbloc.dev/ns/anchor-origin": serverURL,
+					},
+					Links: []discoveryrest.Link{
+						{Rel: "self", Type: "application/did+ld+json", Href: serverURL + "/resolve1/" + resource},
+						{Rel: "alternate", Type: "application/did+ld+json", Href: serverURL + "/resolve2/" + resource},
+					},
+				}
+			default:
+				resolutionURIs = append(resolutionURIs, r.URL.RequestURI())
+
+				resp = map[string]interface{}{
+					"didDocument": map[string]interface{}{
+						"@context": "https://www.w3.org/ns/did/v1",
+						"id":       testDID,
+					},
+				}
+			}
+
+			respBytes, err := json.Marshal(resp)
+			require.NoError(t, err)
+
+			_, err = w.Write(respBytes)
+			require.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		serverURL = srv.URL
+
+		orbClient := &mockOrbClient{anchorOrigin: srv.URL}
+
+		discoveryClient, err := discoveryclient.New(nil, nil,
+			discoveryclient.WithHTTPClient(srv.Client()),
+			discoveryclient.WithOrbClient(orbClient))
+		require.NoError(t, err)
+
+		c := restapi.New(&restapi.Config{ConsensusResolver: discoveryClient})
+
+		handler := getHandler(t, c, resolveDIDEndpoint)
+
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet,
+			resolveDIDEndpoint+"?did="+testDID+"&versionId=1", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Contains(t, rr.Body.String(), testDID)
+
+		rr = serveHTTP(t, handler.Handler(), http.MethodGet,
+			resolveDIDEndpoint+"?did="+testDID+"&versionId=2", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		// The endpoints are discovered from the DID without the parameters.
+		for _, suffix := range orbClient.suffixes {
+			require.Equal(t, "EiA329wd6Aj36YRmp7NGkeB5ADnVt8ARdMZMPzfXsjwTJA", suffix)
+		}
+
+		for _, uri := range webFingerURIs {
+			require.Equal(t, testDID, uri)
+		}
+
+		require.ElementsMatch(t, []string{
+			"/resolve1/" + testDID + "?versionId=1",
+			"/resolve2/" + testDID + "?versionId=1",
+			"/resolve1/" + testDID + "?versionId=2",
+			"/resolve2/" + testDID + "?versionId=2",
+		}, resolutionURIs)
+	})
+
+	t.Run("test consensus error", func(t *testing.T) {
+		c := restapi.New(&restapi.Config{ConsensusResolver: &mockConsensusResolver{
+			err: &discoveryclient.ConsensusError{
+				DID:          "did1",
+				MinResolvers: 2,
+				Agreed:       1,
+				Divergent:    []string{"https://orb.domain2.com/sidetree/v1/identifiers"},
+			},
+		}})
+
+		handler := getHandler(t, c, resolveDIDEndpoint)
+
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?did=did1", nil, nil)
+
+		require.Equal(t, http.StatusConflict, rr.Code)
+		require.Contains(t, rr.Body.String(), "divergent nodes: [https://orb.domain2.com/sidetree/v1/identifiers]")
+	})
+
+	t.Run("test resolve error", func(t *testing.T) {
+		c := restapi.New(&restapi.Config{ConsensusResolver: &mockConsensusResolver{
+			err: fmt.Errorf("injected error"),
+		}})
+
+		handler := getHandler(t, c, resolveDIDEndpoint)
+
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?did=did1", nil, nil)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "injected error")
+	})
 }
 
 func serveHTTP(t *testing.T, handler common.HTTPRequestHandler, method, path string,
@@ -116,3 +328,28 @@ func handlerLookup(t *testing.T, op *restapi.Operation, lookup string) common.HT
 
 	return nil
 }
+
+type mockConsensusResolver struct {
+	resolution *did.DocResolution
+	err        error
+	domain     string
+	didURI     string
+}
+
+func (m *mockConsensusResolver) ResolveDIDWithConsensus(domain, didURI string) (*did.DocResolution, error) {
+	m.domain = domain
+	m.didURI = didURI
+
+	return m.resolution, m.err
+}
+
+type mockOrbClient struct {
+	anchorOrigin string
+	suffixes     []string
+}
+
+func (m *mockOrbClient) GetAnchorOrigin(cid, suffix string) (interface{}, error) {
+	m.suffixes = append(m.suffixes, suffix)
+
+	return m.anchorOrigin, nil
+}
@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package hashlink
 
 import (
+	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -30,6 +32,16 @@ const (
 	HLPrefix = hl + separator
 )
 
+// ErrHashMismatch indicates that the hash of the content doesn't match the resource hash.
+var ErrHashMismatch = errors.New("content hash mismatch")
+
+// supportedMultihashCodes contains the multihash codes of the hash algorithms that may be used in a resource hash.
+var supportedMultihashCodes = map[uint64]struct{}{
+	multihash.SHA2_256: {},
+	multihash.SHA2_512: {},
+	multihash.SHA3_256: {},
+}
+
 // Encoder defines encoding function.
 type Encoder func(content []byte) string
 
@@ -68,25 +80,26 @@ type HashLink struct {
 
 // CreateHashLink will create hashlink for the supplied content and links.
 func (hl *HashLink) CreateHashLink(content []byte, links []string) (string, error) {
-	hashLink, err := hl.CreateResourceHash(content)
+	return hl.CreateHashLinkWithMetadata(content, &Metadata{Links: links})
+}
+
+// CreateHashLinkWithMetadata will create hashlink for the supplied content and metadata.
+func (hl *HashLink) CreateHashLinkWithMetadata(content []byte, md *Metadata) (string, error) {
+	resourceHash, err := hl.CreateResourceHash(content)
 	if err != nil {
 		return "", fmt.Errorf("failed to create resource hash from content[%s]: %w", string(content), err)
 	}
 
-	// hash link without metadata
-	hashLink = HLPrefix + hashLink
-
-	if len(links) > 0 {
-		metadata, err := hl.CreateMetadataFromLinks(links)
-		if err != nil {
-			return "", fmt.Errorf("failed to create hashlink metadata for links[%+v]: %w", links, err)
-		}
+	if md == nil || md.isEmpty() {
+		return GetHashLinkFromResourceHash(resourceHash), nil
+	}
 
-		// add metadata to hashlink
-		hashLink = hashLink + separator + metadata
+	metadata, err := hl.CreateMetadata(md)
+	if err != nil {
+		return "", fmt.Errorf("failed to create hashlink metadata: %w", err)
 	}
 
-	return hashLink, nil
+	return GetHashLink(resourceHash, metadata), nil
 }
 
 // ParseHashLink will parse hash link into resource hash and metadata.
@@ -111,26 +124,51 @@ func (hl *HashLink) ParseHashLink(hashLink string) (*Info, error) {
 	info := &Info{ResourceHash: rh}
 
 	if len(parts) > minHLParts {
-		links, err := hl.GetLinksFromMetadata(parts[2])
+		md, err := hl.GetMetadata(parts[2])
 		if err != nil {
 			return nil, fmt.Errorf("failed to get links from metadata: %w", err)
 		}
 
-		info.Links = links
+		info.Links = md.Links
+		info.ContentType = md.ContentType
+		info.Experimental = md.Experimental
 	}
 
 	return info, nil
 }
 
-// Info contains hashlink information: resource hash and links.
+// Info contains hashlink information: resource hash, links and (optional) metadata fields.
 type Info struct {
 	ResourceHash string
 	Links        []string
+	ContentType  string
+	Experimental map[string]interface{}
+}
+
+// Metadata contains the hashlink metadata fields that are defined by the hashlink specification.
+type Metadata struct {
+	// Links contains the URLs at which the content may be retrieved.
+	Links []string
+	// ContentType is the media type of the content.
+	ContentType string
+	// Experimental contains experimental metadata keys and values. The values must be CBOR-encodable.
+	Experimental map[string]interface{}
+}
+
+func (md *Metadata) isEmpty() bool {
+	return len(md.Links) == 0 && md.ContentType == "" && len(md.Experimental) == 0
+}
+
+// metadataCBOR is the CBOR representation of the hashlink metadata. The fields are in ascending key order.
+type metadataCBOR struct {
+	Experimental map[string]interface{} `cbor:"13,keyasint,omitempty"`
+	ContentType  string                 `cbor:"14,keyasint,omitempty"`
+	URLs         []string               `cbor:"15,keyasint,omitempty"`
 }
 
 // CreateResourceHash will create resource hash for the supplied content.
 func (hl *HashLink) CreateResourceHash(content []byte) (string, error) {
-	mh, err := hashing.ComputeMultihash(hl.multihashCode, content)
+	mh, err := computeMultihash(hl.multihashCode, content)
 	if err != nil {
 		return "", fmt.Errorf("failed to compute multihash for code[%d]: %w", hl.multihashCode, err)
 	}
@@ -138,24 +176,102 @@ func (hl *HashLink) CreateResourceHash(content []byte) (string, error) {
 	return hl.encoder(mh), nil
 }
 
+// Verify verifies that the resource hash of the given hashlink matches the supplied content. The hash algorithm
+// is determined from the multihash code of the resource hash. ErrHashMismatch is returned if the content doesn't
+// match.
+func (hl *HashLink) Verify(hashLink string, content []byte) error {
+	resourceHash, err := GetResourceHashFromHashLink(hashLink)
+	if err != nil {
+		return err
+	}
+
+	return hl.VerifyResourceHash(resourceHash, content)
+}
+
+// VerifyResourceHash verifies that the given resource hash matches the supplied content. The hash algorithm
+// is determined from the multihash code of the resource hash. ErrHashMismatch is returned if the content doesn't
+// match.
+func (hl *HashLink) VerifyResourceHash(resourceHash string, content []byte) error {
+	if resourceHash == "" {
+		return errors.New("resource hash is empty")
+	}
+
+	multihashBytes, err := hl.decoder(resourceHash)
+	if err != nil {
+		return fmt.Errorf("failed to decode resource hash[%s]: %w", resourceHash, err)
+	}
+
+	mh, err := multihash.Decode(multihashBytes)
+	if err != nil {
+		return fmt.Errorf("failed to decode multihash for resource hash[%s]: %w", resourceHash, err)
+	}
+
+	if _, ok := supportedMultihashCodes[mh.Code]; !ok {
+		return fmt.Errorf("multihash code[%d] of resource hash[%s] is not supported", mh.Code, resourceHash)
+	}
+
+	contentMultihash, err := computeMultihash(uint(mh.Code), content)
+	if err != nil {
+		return fmt.Errorf("failed to compute multihash for code[%d]: %w", mh.Code, err)
+	}
+
+	if !bytes.Equal(contentMultihash, multihashBytes) {
+		return fmt.Errorf("%w: content hash[%s] doesn't match resource hash[%s]",
+			ErrHashMismatch, hl.encoder(contentMultihash), resourceHash)
+	}
+
+	return nil
+}
+
 // CreateMetadataFromLinks will create metadata for the supplied links.
 func (hl *HashLink) CreateMetadataFromLinks(links []string) (string, error) {
 	if len(links) == 0 {
 		return "", fmt.Errorf("links not provided")
 	}
 
-	// generate the encoded metadata
-	metadata := make(map[int]interface{})
-	metadata[linksKey] = links
+	return hl.CreateMetadata(&Metadata{Links: links})
+}
 
-	bytes, err := cbor.Marshal(metadata)
+// CreateMetadata will create encoded metadata for the supplied metadata fields.
+func (hl *HashLink) CreateMetadata(md *Metadata) (string, error) {
+	if md.isEmpty() {
+		return "", fmt.Errorf("metadata not provided")
+	}
+
+	bytes, err := cbor.Marshal(&metadataCBOR{
+		Experimental: md.Experimental,
+		ContentType:  md.ContentType,
+		URLs:         md.Links,
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to cbor.marshal links[%+v]: %w", links, err)
+		return "", fmt.Errorf("failed to cbor.marshal metadata: %w", err)
 	}
 
 	return hl.encoder(bytes), nil
 }
 
+// GetMetadata will decode the metadata fields from the encoded metadata. Unlike GetLinksFromMetadata, links are
+// optional.
+func (hl *HashLink) GetMetadata(enc string) (*Metadata, error) {
+	metadataBytes, err := hl.decoder(enc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+
+	md := &metadataCBOR{}
+
+	err = cbor.Unmarshal(metadataBytes, md)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cbor.unmarshal metadata: %w", err)
+	}
+
+	return &Metadata{
+		Links:        md.URLs,
+		ContentType:  md.ContentType,
+		Experimental: md.Experimental,
+	}, nil
+}
+
 // GetLinksFromMetadata will create links from metadata.
 func (hl *HashLink) GetLinksFromMetadata(enc string) ([]string, error) {
 	metadataBytes, err := hl.decoder(enc)
@@ -274,9 +390,18 @@ func (hl *HashLink) isValidMultihash(encodedMultihash string) error {
 		return fmt.Errorf("failed to decode multihash: %w", err)
 	}
 
-	if mh.Code != uint64(hl.multihashCode) {
-		return fmt.Errorf("resource multihash code[%d] is not supported code[%d]", mh.Code, hl.multihashCode)
+	if _, ok := supportedMultihashCodes[mh.Code]; !ok && mh.Code != uint64(hl.multihashCode) {
+		return fmt.Errorf("resource multihash code[%d] is not supported", mh.Code)
 	}
 
 	return nil
 }
+
+// computeMultihash computes the multihash of the content using the hash algorithm of the given multihash code.
+func computeMultihash(multihashCode uint, content []byte) ([]byte, error) {
+	if multihashCode == multihash.SHA3_256 {
+		return multihash.Sum(content, multihash.SHA3_256, -1)
+	}
+
+	return hashing.ComputeMultihash(multihashCode, content)
+}
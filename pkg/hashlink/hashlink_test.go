@@ -7,11 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package hashlink
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/btcsuite/btcutil/base58"
 	cbor "github.com/fxamacker/cbor/v2"
+	"github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/require"
 )
 
@@ -201,6 +204,151 @@ func TestHashLink_CreateResourceHash(t *testing.T) {
 	})
 }
 
+func TestHashLink_CreateHashLinkWithMetadata(t *testing.T) {
+	t.Run("success - links only (same as CreateHashLink)", func(t *testing.T) {
+		hl := New()
+
+		links := []string{
+			"https://example.com/cas/uEiAsiwjaXOYDmOHxmvDl3Mx0TfJ0uCar5YXqumjFJUNIBg",
+			"ipfs://QmUB9Nr7RpqNYQpyh4W9r3RQNttiPQ6BQ9iQLkw9LztJFz",
+		}
+
+		expected, err := hl.CreateHashLink([]byte(exampleContent), links)
+		require.NoError(t, err)
+
+		hash, err := hl.CreateHashLinkWithMetadata([]byte(exampleContent), &Metadata{Links: links})
+		require.NoError(t, err)
+		require.Equal(t, expected, hash)
+	})
+
+	t.Run("success - no metadata", func(t *testing.T) {
+		hl := New()
+
+		hash, err := hl.CreateHashLinkWithMetadata([]byte(exampleContent), nil)
+		require.NoError(t, err)
+		require.Equal(t, "hl:uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", hash)
+
+		hash, err = hl.CreateHashLinkWithMetadata([]byte(exampleContent), &Metadata{})
+		require.NoError(t, err)
+		require.Equal(t, "hl:uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", hash)
+	})
+
+	t.Run("success - content type and experimental", func(t *testing.T) {
+		hl := New()
+
+		hash, err := hl.CreateHashLinkWithMetadata([]byte(exampleContent), &Metadata{
+			ContentType:  "application/json",
+			Experimental: map[string]interface{}{"foo": "bar"},
+		})
+		require.NoError(t, err)
+
+		info, err := hl.ParseHashLink(hash)
+		require.NoError(t, err)
+		require.Empty(t, info.Links)
+		require.Equal(t, "application/json", info.ContentType)
+		require.Equal(t, map[string]interface{}{"foo": "bar"}, info.Experimental)
+	})
+
+	t.Run("error - failed to create resource hash", func(t *testing.T) {
+		hl := New(WithMultihashCode(invalidMultihashCode))
+
+		hash, err := hl.CreateHashLinkWithMetadata([]byte(exampleContent), nil)
+		require.Error(t, err)
+		require.Empty(t, hash)
+		require.Contains(t, err.Error(), "failed to compute multihash for code[55]")
+	})
+
+	t.Run("error - marshal metadata", func(t *testing.T) {
+		hl := New()
+
+		hash, err := hl.CreateHashLinkWithMetadata([]byte(exampleContent), &Metadata{
+			Experimental: map[string]interface{}{"foo": make(chan int)},
+		})
+		require.Error(t, err)
+		require.Empty(t, hash)
+		require.Contains(t, err.Error(), "failed to cbor.marshal metadata")
+	})
+}
+
+func TestHashLink_GetMetadata(t *testing.T) {
+	hl := New()
+
+	t.Run("error - decode", func(t *testing.T) {
+		md, err := hl.GetMetadata("u!")
+		require.Error(t, err)
+		require.Nil(t, md)
+		require.Contains(t, err.Error(), "failed to decode metadata")
+	})
+
+	t.Run("error - unmarshal", func(t *testing.T) {
+		md, err := hl.GetMetadata("uYWJj")
+		require.Error(t, err)
+		require.Nil(t, md)
+		require.Contains(t, err.Error(), "failed to cbor.unmarshal metadata")
+	})
+}
+
+func TestHashLink_Verify(t *testing.T) {
+	for _, code := range []uint{multihash.SHA2_256, multihash.SHA2_512, multihash.SHA3_256} {
+		code := code
+
+		t.Run(fmt.Sprintf("success - %s", multihash.Codes[uint64(code)]), func(t *testing.T) {
+			hash, err := New(WithMultihashCode(code)).CreateHashLink([]byte(exampleContent), []string{exampleURL})
+			require.NoError(t, err)
+
+			// The algorithm is determined from the resource hash and not from the configured multihash code.
+			hl := New()
+
+			require.NoError(t, hl.Verify(hash, []byte(exampleContent)))
+
+			err = hl.Verify(hash, []byte("other content"))
+			require.Error(t, err)
+			require.True(t, errors.Is(err, ErrHashMismatch))
+		})
+	}
+
+	t.Run("success - base58", func(t *testing.T) {
+		hl := New(WithEncoder(base58Encoder), WithDecoder(base58Decoder))
+
+		require.NoError(t, hl.Verify("hl:zQmWvQxTqbG2Z9HPJgG57jjwR154cKhbtJenbyYTWkjgF3e", []byte(exampleContent)))
+	})
+
+	hl := New()
+
+	t.Run("error - invalid hashlink", func(t *testing.T) {
+		err := hl.Verify("uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", []byte(exampleContent))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "must start with 'hl:' prefix")
+	})
+
+	t.Run("error - empty resource hash", func(t *testing.T) {
+		err := hl.VerifyResourceHash("", []byte(exampleContent))
+		require.EqualError(t, err, "resource hash is empty")
+	})
+
+	t.Run("error - decode resource hash", func(t *testing.T) {
+		err := hl.VerifyResourceHash("u!", []byte(exampleContent))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decode resource hash[u!]")
+	})
+
+	t.Run("error - invalid multihash", func(t *testing.T) {
+		err := hl.VerifyResourceHash("uYWJj", []byte(exampleContent))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decode multihash for resource hash[uYWJj]")
+	})
+
+	t.Run("error - algorithm not supported", func(t *testing.T) {
+		mh, err := multihash.Sum([]byte(exampleContent), multihash.MD5, -1)
+		require.NoError(t, err)
+
+		err = hl.VerifyResourceHash("u"+base64.RawURLEncoding.EncodeToString(mh), []byte(exampleContent))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "multihash code[213]")
+		require.Contains(t, err.Error(), "is not supported")
+	})
+}
+
 func TestHashLink_CreateMetadataFromLinks(t *testing.T) {
 	t.Run("success - with links", func(t *testing.T) {
 		links := []string{
@@ -297,14 +445,43 @@ func TestHashLink_ParseHashLink(t *testing.T) {
 			"resource hash[abc] for hashlink[hl:abc] is not a valid multihash: failed to decode multihash")
 	})
 
-	t.Run("error - multi hash not supported", func(t *testing.T) {
-		hl := New(WithMultihashCode(0))
+	t.Run("success - supported algorithm other than the configured one", func(t *testing.T) {
+		hl := New(WithMultihashCode(multihash.SHA2_512))
 
 		hlInfo, err := hl.ParseHashLink("hl:uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ")
+		require.NoError(t, err)
+		require.Equal(t, "uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", hlInfo.ResourceHash)
+	})
+
+	t.Run("success - with metadata", func(t *testing.T) {
+		hl := New()
+
+		hash, err := hl.CreateHashLinkWithMetadata([]byte(exampleContent), &Metadata{
+			Links:        []string{exampleURL},
+			ContentType:  "text/plain",
+			Experimental: map[string]interface{}{"key": "value"},
+		})
+		require.NoError(t, err)
+
+		hlInfo, err := hl.ParseHashLink(hash)
+		require.NoError(t, err)
+		require.Equal(t, "uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", hlInfo.ResourceHash)
+		require.Equal(t, []string{exampleURL}, hlInfo.Links)
+		require.Equal(t, "text/plain", hlInfo.ContentType)
+		require.Equal(t, map[string]interface{}{"key": "value"}, hlInfo.Experimental)
+	})
+
+	t.Run("error - multi hash not supported", func(t *testing.T) {
+		hl := New()
+
+		mh, err := multihash.Sum([]byte(exampleContent), multihash.MD5, -1)
+		require.NoError(t, err)
+
+		hlInfo, err := hl.ParseHashLink(HLPrefix + "u" + base64.RawURLEncoding.EncodeToString(mh))
 		require.Error(t, err)
 		require.Nil(t, hlInfo)
 		require.Contains(t, err.Error(),
-			"resource multihash code[18] is not supported code[0]")
+			"resource multihash code[213] is not supported")
 	})
 
 	t.Run("error - parse metadata error", func(t *testing.T) {
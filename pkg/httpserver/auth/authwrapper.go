@@ -52,12 +52,17 @@ func NewHandlerWrapper(cfg Config, handler common.HTTPHandler) *HandlerWrapper {
 // Handler returns the 'wrapper' handler.
 func (h *HandlerWrapper) Handler() common.HTTPRequestHandler {
 	return func(w http.ResponseWriter, req *http.Request) {
-		if !h.verifier.Verify(req) {
+		tokenID, ok := h.verifier.Identify(req)
+		if !ok {
 			h.writeResponse(w, http.StatusUnauthorized, []byte(unauthorizedResponse))
 
 			return
 		}
 
+		if tokenID != "" {
+			req = req.WithContext(ContextWithTokenID(req.Context(), tokenID))
+		}
+
 		h.handleRequest(w, req)
 	}
 }
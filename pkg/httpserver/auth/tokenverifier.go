@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package auth
 
 import (
+	"context"
 	"crypto/subtle"
 	"fmt"
 	"net/http"
@@ -39,32 +40,50 @@ type Config struct {
 type TokenVerifier struct {
 	Config
 
-	endpoint   string
-	authTokens []string
+	endpoint     string
+	authTokens   []string
+	authTokenIDs []string
 }
 
+type tokenIDKey struct{}
+
 // NewTokenVerifier returns a verifier that performs bearer token authorization.
 func NewTokenVerifier(cfg Config, endpoint, method string) *TokenVerifier {
-	authTokens, err := resolveAuthTokens(endpoint, method, cfg.AuthTokensDef, cfg.AuthTokens)
+	authTokenIDs, authTokens, err := resolveAuthTokens(endpoint, method, cfg.AuthTokensDef, cfg.AuthTokens)
 	if err != nil {
 		// This would occur on startup due to bad configuration, so it's better to panic.
 		panic(fmt.Errorf("resolve authorization tokens: %w", err))
 	}
 
 	return &TokenVerifier{
-		Config:     cfg,
-		endpoint:   endpoint,
-		authTokens: authTokens,
+		Config:       cfg,
+		endpoint:     endpoint,
+		authTokens:   authTokens,
+		authTokenIDs: authTokenIDs,
 	}
 }
 
+// Required returns true if the endpoint requires a bearer token.
+func (h *TokenVerifier) Required() bool {
+	return len(h.authTokens) > 0
+}
+
 // Verify verifies that the request has the required bearer token. If not, false is returned.
 func (h *TokenVerifier) Verify(req *http.Request) bool {
+	_, ok := h.Identify(req)
+
+	return ok
+}
+
+// Identify verifies that the request has the required bearer token and returns the ID of the token
+// (as defined in the auth token configuration). If not authorized then false is returned. If the endpoint
+// does not require authorization then an empty token ID and true are returned.
+func (h *TokenVerifier) Identify(req *http.Request) (string, bool) {
 	if len(h.authTokens) == 0 {
 		// Open access.
 		logger.Debugf("[%s] No auth token required.", h.endpoint)
 
-		return true
+		return "", true
 	}
 
 	logger.Debugf("[%s] Auth tokens required: %s", h.endpoint, h.authTokens)
@@ -73,31 +92,47 @@ func (h *TokenVerifier) Verify(req *http.Request) bool {
 	if actHdr == "" {
 		logger.Debugf("[%s] Bearer token not found in header", h.endpoint)
 
-		return false
+		return "", false
 	}
 
 	// Compare the header against all tokens. If any match then we allow the request.
-	for _, token := range h.authTokens {
+	for i, token := range h.authTokens {
 		logger.Debugf("[%s] Checking token %s", h.endpoint, token)
 
 		if subtle.ConstantTimeCompare([]byte(actHdr), []byte(tokenPrefix+token)) == 1 {
 			logger.Debugf("[%s] Found token %s", h.endpoint, token)
 
-			return true
+			return h.authTokenIDs[i], true
 		}
 	}
 
-	return false
+	return "", false
+}
+
+// ContextWithTokenID returns a copy of the given context which holds the ID of the token that authorized the request.
+func ContextWithTokenID(ctx context.Context, tokenID string) context.Context {
+	return context.WithValue(ctx, tokenIDKey{}, tokenID)
+}
+
+// TokenIDFromContext returns the ID of the token that authorized the request, or an empty string
+// if the request did not require authorization.
+func TokenIDFromContext(ctx context.Context) string {
+	tokenID, ok := ctx.Value(tokenIDKey{}).(string)
+	if !ok {
+		return ""
+	}
+
+	return tokenID
 }
 
 func resolveAuthTokens(endpoint, method string, authTokensDef []*TokenDef,
-	authTokenMap map[string]string) ([]string, error) {
-	var authTokens []string
+	authTokenMap map[string]string) ([]string, []string, error) {
+	var authTokenIDs, authTokens []string
 
 	for _, def := range authTokensDef {
 		ok, err := endpointMatches(endpoint, def.EndpointExpression)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		if !ok {
@@ -115,9 +150,10 @@ func resolveAuthTokens(endpoint, method string, authTokensDef []*TokenDef,
 		for _, tokenID := range tokens {
 			token, ok := authTokenMap[tokenID]
 			if !ok {
-				return nil, fmt.Errorf("token not found: %s", tokenID)
+				return nil, nil, fmt.Errorf("token not found: %s", tokenID)
 			}
 
+			authTokenIDs = append(authTokenIDs, tokenID)
 			authTokens = append(authTokens, token)
 		}
 
@@ -126,7 +162,7 @@ func resolveAuthTokens(endpoint, method string, authTokensDef []*TokenDef,
 
 	logger.Debugf("[%s] Authorization tokens: %s", endpoint, authTokens)
 
-	return authTokens, nil
+	return authTokenIDs, authTokens, nil
 }
 
 func endpointMatches(endpoint, pattern string) (bool, error) {
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package auth
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -42,6 +43,11 @@ func TestTokenVerifier(t *testing.T) {
 		require.NotNil(t, v2)
 	})
 
+	t.Run("Required", func(t *testing.T) {
+		require.True(t, NewTokenVerifier(cfg, "/services/orb/outbox", http.MethodGet).Required())
+		require.False(t, NewTokenVerifier(cfg, "/services/orb/followers", http.MethodGet).Required())
+	})
+
 	t.Run("Token not found -> panic", func(t *testing.T) {
 		c := Config{
 			AuthTokensDef: []*TokenDef{
@@ -65,6 +71,10 @@ func TestTokenVerifier(t *testing.T) {
 		req.Header[authHeader] = []string{tokenPrefix + "ADMIN_TOKEN"}
 
 		require.True(t, v.Verify(req))
+
+		tokenID, ok := v.Identify(req)
+		require.True(t, ok)
+		require.Equal(t, "admin", tokenID)
 	})
 
 	t.Run("GET with no auth token -> unauthorized", func(t *testing.T) {
@@ -94,4 +104,9 @@ func TestTokenVerifier(t *testing.T) {
 
 		require.True(t, v.Verify(req))
 	})
+
+	t.Run("Token ID in context", func(t *testing.T) {
+		require.Empty(t, TokenIDFromContext(context.Background()))
+		require.Equal(t, "admin", TokenIDFromContext(ContextWithTokenID(context.Background(), "admin")))
+	})
 }
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package metrics
 
 import (
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -40,6 +42,13 @@ const (
 	anchorWriteSignLocalWitnessLogTimeMetric       = "write_sign_local_witness_log_seconds"
 	anchorWriteSignLocalStoreTimeMetric            = "write_sign_local_store_seconds"
 	anchorWriteSignLocalWatchTimeMetric            = "write_sign_local_watch_seconds"
+	anchorOriginRejectedCounterMetric              = "origin_rejected_count"
+
+	// maxAnchorOriginLabels is the maximum number of distinct origins that are recorded in the rejected anchor
+	// origin metric. Since the origin is supplied by the client, rejections for any further origins are recorded
+	// under otherAnchorOriginLabel.
+	maxAnchorOriginLabels  = 100
+	otherAnchorOriginLabel = "other"
 
 	// Operation queue.
 	operationQueue                 = "opqueue"
@@ -62,6 +71,16 @@ const (
 	casCacheHitCountMetric = "cache_hit_count"
 	casReadTimeMetric      = "read_seconds"
 
+	casResolveSourceTimeMetric = "resolve_source_seconds"
+
+	casReplicationCountMetric    = "replication_count"
+	casReplicationPassTimeMetric = "replication_pass_seconds"
+
+	// DID discovery.
+	discovery               = "discovery"
+	didDiscoveryCountMetric = "did_count"
+	didDiscoveryQueryMetric = "did_query_count"
+
 	// Document handler.
 	document                  = "document"
 	docCreateUpdateTimeMetric = "create_update_seconds"
@@ -121,6 +140,9 @@ type Metrics struct {
 	anchorWriteSignLocalWitnessLogTime       prometheus.Histogram
 	anchorWriteSignLocalStoreTime            prometheus.Histogram
 	anchorWriteSignLocalWatchTime            prometheus.Histogram
+	anchorOriginRejectedCounts               *prometheus.CounterVec
+	anchorOriginLabels                       map[string]struct{}
+	anchorOriginMutex                        sync.Mutex
 
 	opqueueAddOperationTime  prometheus.Histogram
 	opqueueBatchCutTime      prometheus.Histogram
@@ -137,6 +159,14 @@ type Metrics struct {
 	casCacheHitCount prometheus.Counter
 	casReadTimes     map[string]prometheus.Histogram
 
+	casResolveSourceTimes *prometheus.HistogramVec
+
+	casReplicationCounts   *prometheus.CounterVec
+	casReplicationPassTime prometheus.Histogram
+
+	didDiscoveryCounts      *prometheus.CounterVec
+	didDiscoveryQueryCounts *prometheus.CounterVec
+
 	docCreateUpdateTime prometheus.Histogram
 	docResolveTime      prometheus.Histogram
 
@@ -189,6 +219,8 @@ func newMetrics() *Metrics { //nolint:funlen
 		anchorWriteSignLocalWitnessLogTime:       newAnchorWriteSignLocalWitnessLogTime(),
 		anchorWriteSignLocalStoreTime:            newAnchorWriteSignLocalStoreTime(),
 		anchorWriteSignLocalWatchTime:            newAnchorWriteSignLocalWatchTime(),
+		anchorOriginRejectedCounts:               newAnchorOriginRejectedCounts(),
+		anchorOriginLabels:                       make(map[string]struct{}),
 		opqueueAddOperationTime:                  newOpQueueAddOperationTime(),
 		opqueueBatchCutTime:                      newOpQueueBatchCutTime(),
 		opqueueBatchRollbackTime:                 newOpQueueBatchRollbackTime(),
@@ -201,6 +233,11 @@ func newMetrics() *Metrics { //nolint:funlen
 		casResolveTime:                           newCASResolveTime(),
 		casReadTimes:                             newCASReadTimes(),
 		casCacheHitCount:                         newCASCacheHitCount(),
+		casResolveSourceTimes:                    newCASResolveSourceTimes(),
+		casReplicationCounts:                     newCASReplicationCounts(),
+		casReplicationPassTime:                   newCASReplicationPassTime(),
+		didDiscoveryCounts:                       newDIDDiscoveryCounts(),
+		didDiscoveryQueryCounts:                  newDIDDiscoveryQueryCounts(),
 		docCreateUpdateTime:                      newDocCreateUpdateTime(),
 		docResolveTime:                           newDocResolveTime(),
 		apInboxHandlerTimes:                      newInboxHandlerTimes(activityTypes),
@@ -229,11 +266,13 @@ func newMetrics() *Metrics { //nolint:funlen
 		m.anchorWriteGetWitnessesTime, m.anchorWriteSignCredTime, m.anchorWritePostOfferActivityTime,
 		m.anchorWriteGetPreviousAnchorsGetBulkTime, m.anchorWriteGetPreviousAnchorsTime,
 		m.anchorWriteSignWithLocalWitnessTime, m.anchorWriteSignWithServerKeyTime, m.anchorWriteSignLocalWitnessLogTime,
-		m.anchorWriteSignLocalStoreTime, m.anchorWriteSignLocalWatchTime,
+		m.anchorWriteSignLocalStoreTime, m.anchorWriteSignLocalWatchTime, m.anchorOriginRejectedCounts,
 		m.opqueueAddOperationTime, m.opqueueBatchCutTime, m.opqueueBatchRollbackTime,
 		m.opqueueBatchAckTime, m.opqueueBatchNackTime, m.opqueueBatchSize,
 		m.observerProcessAnchorTime, m.observerProcessDIDTime,
-		m.casWriteTime, m.casResolveTime, m.casCacheHitCount,
+		m.casWriteTime, m.casResolveTime, m.casCacheHitCount, m.casResolveSourceTimes,
+		m.casReplicationCounts, m.casReplicationPassTime,
+		m.didDiscoveryCounts, m.didDiscoveryQueryCounts,
 		m.docCreateUpdateTime, m.docResolveTime,
 		m.vctWitnessAddProofVCTNilTimes, m.vctWitnessAddVCTimes, m.vctWitnessAddProofTimes,
 		m.vctWitnessAddWebFingerTimes, m.vctWitnessVerifyVCTimes, m.vctAddProofParseCredentialTimes,
@@ -402,6 +441,38 @@ func (m *Metrics) ProcessWitnessedAnchorCredentialTime(value time.Duration) {
 	logger.Debugf("ProcessWitnessedAnchorCredential time: %s", value)
 }
 
+// AnchorOriginRejected increments the number of operations that were rejected because the given anchor origin
+// is not allowed. The origin is recorded as its (lower case) host and, in order to bound the cardinality of the
+// label, only the first 100 distinct hosts are recorded; any others are recorded as "other".
+func (m *Metrics) AnchorOriginRejected(origin string) {
+	m.anchorOriginRejectedCounts.WithLabelValues(m.anchorOriginLabel(origin)).Inc()
+}
+
+func (m *Metrics) anchorOriginLabel(origin string) string {
+	host := origin
+
+	if u, err := url.Parse(origin); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	host = strings.ToLower(host)
+
+	m.anchorOriginMutex.Lock()
+	defer m.anchorOriginMutex.Unlock()
+
+	if _, ok := m.anchorOriginLabels[host]; ok {
+		return host
+	}
+
+	if host == "" || len(m.anchorOriginLabels) >= maxAnchorOriginLabels {
+		return otherAnchorOriginLabel
+	}
+
+	m.anchorOriginLabels[host] = struct{}{}
+
+	return host
+}
+
 // AddOperationTime records the time it takes to add an operation to the queue.
 func (m *Metrics) AddOperationTime(value time.Duration) {
 	m.opqueueAddOperationTime.Observe(value.Seconds())
@@ -487,6 +558,51 @@ func (m *Metrics) CASReadTime(casType string, value time.Duration) {
 	}
 }
 
+// CASResolveSourceTime records the time it takes to retrieve a document from a remote CAS source of the given
+// type (webcas, ipfs or ipfs-gateway) and whether or not the retrieval succeeded.
+func (m *Metrics) CASResolveSourceTime(source string, success bool, value time.Duration) {
+	result := "success"
+	if !success {
+		result = "failure"
+	}
+
+	m.casResolveSourceTimes.WithLabelValues(source, result).Observe(value.Seconds())
+
+	logger.Debugf("CASResolveSource time for [%s] (%s): %s", source, result, value)
+}
+
+// CASReplicationIncrementCount increments the number of local CAS resources processed by the IPFS replication
+// job with the given result (e.g. "verified", "backfilled" or "failed").
+func (m *Metrics) CASReplicationIncrementCount(result string) {
+	m.casReplicationCounts.WithLabelValues(result).Inc()
+}
+
+// CASReplicationPassTime records the time it takes for the IPFS replication job to complete a pass over the
+// local CAS.
+func (m *Metrics) CASReplicationPassTime(value time.Duration) {
+	m.casReplicationPassTime.Observe(value.Seconds())
+
+	logger.Debugf("CASReplicationPass time: %s", value)
+}
+
+// DIDDiscoveryIncrementCount increments the number of DID discovery requests with the given result
+// (e.g. "published" or "failed").
+func (m *Metrics) DIDDiscoveryIncrementCount(result string) {
+	m.didDiscoveryCounts.WithLabelValues(result).Inc()
+}
+
+// DIDDiscoveryQueryIncrementCount increments the number of queries for the latest anchor of a DID that were
+// sent to the given type of source (e.g. "anchor-origin", "discovery-domain" or "followed-service") and
+// whether or not the query succeeded.
+func (m *Metrics) DIDDiscoveryQueryIncrementCount(source string, success bool) {
+	result := "success"
+	if !success {
+		result = "failure"
+	}
+
+	m.didDiscoveryQueryCounts.WithLabelValues(source, result).Inc()
+}
+
 // DocumentCreateUpdateTime records the time it takes the REST handler to process a create/update operation.
 func (m *Metrics) DocumentCreateUpdateTime(value time.Duration) {
 	m.docCreateUpdateTime.Observe(value.Seconds())
@@ -813,6 +929,19 @@ func newAnchorWriteSignLocalWatchTime() prometheus.Histogram {
 	)
 }
 
+func newAnchorOriginRejectedCounts() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: anchor,
+			Name:      anchorOriginRejectedCounterMetric,
+			Help: "The number of operations that were rejected because the anchor origin is not allowed. The " +
+				"origin label is the host of the anchor origin (or 'other' if too many distinct origins were rejected).",
+		},
+		[]string{"origin"},
+	)
+}
+
 func newOpQueueAddOperationTime() prometheus.Histogram {
 	return newHistogram(
 		operationQueue, opQueueAddOperationTimeMetric,
@@ -916,6 +1045,68 @@ func newCASReadTimes() map[string]prometheus.Histogram {
 	return times
 }
 
+func newCASResolveSourceTimes() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: cas,
+			Name:      casResolveSourceTimeMetric,
+			Help: "The time (in seconds) that it takes to retrieve a document from a remote CAS source. " +
+				"The source label is the type of the source (webcas, ipfs or ipfs-gateway) and the result label " +
+				"indicates whether or not the retrieval succeeded.",
+		},
+		[]string{"source", "result"},
+	)
+}
+
+func newCASReplicationCounts() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: cas,
+			Name:      casReplicationCountMetric,
+			Help: "The number of local CAS resources processed by the IPFS replication job. The result label " +
+				"indicates whether the resource was already in IPFS (verified), was added to IPFS (backfilled), " +
+				"or could not be replicated (failed).",
+		},
+		[]string{"result"},
+	)
+}
+
+func newCASReplicationPassTime() prometheus.Histogram {
+	return newHistogram(
+		cas, casReplicationPassTimeMetric,
+		"The time (in seconds) that it takes for the IPFS replication job to complete a pass over the local CAS.",
+		nil,
+	)
+}
+
+func newDIDDiscoveryCounts() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: discovery,
+			Name:      didDiscoveryCountMetric,
+			Help: "The number of DID discovery requests. The result label indicates whether the latest anchor " +
+				"of the DID was published to the observer (published) or could not be discovered (failed).",
+		},
+		[]string{"result"},
+	)
+}
+
+func newDIDDiscoveryQueryCounts() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: discovery,
+			Name:      didDiscoveryQueryMetric,
+			Help: "The number of queries for the latest anchor of a DID. The source label indicates whether " +
+				"the anchor origin, a configured discovery domain or a followed service was queried.",
+		},
+		[]string{"source", "result"},
+	)
+}
+
 func newDocCreateUpdateTime() prometheus.Histogram {
 	return newHistogram(
 		document, docCreateUpdateTimeMetric,
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package metrics
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -37,6 +38,7 @@ func TestMetrics(t *testing.T) {
 		require.NotPanics(t, func() { m.WriteAnchorSignLocalStoreTime(time.Second) })
 		require.NotPanics(t, func() { m.WriteAnchorSignLocalWatchTime(time.Second) })
 		require.NotPanics(t, func() { m.ProcessWitnessedAnchorCredentialTime(time.Second) })
+		require.NotPanics(t, func() { m.AnchorOriginRejected("https://orb.domain1.com") })
 		require.NotPanics(t, func() { m.AddOperationTime(time.Second) })
 		require.NotPanics(t, func() { m.BatchCutTime(time.Second) })
 		require.NotPanics(t, func() { m.BatchRollbackTime(time.Second) })
@@ -49,6 +51,13 @@ func TestMetrics(t *testing.T) {
 		require.NotPanics(t, func() { m.CASResolveTime(time.Second) })
 		require.NotPanics(t, func() { m.CASIncrementCacheHitCount() })
 		require.NotPanics(t, func() { m.CASReadTime("local", time.Second) })
+		require.NotPanics(t, func() { m.CASResolveSourceTime("orb.domain1.com", true, time.Second) })
+		require.NotPanics(t, func() { m.CASResolveSourceTime("ipfs", false, time.Second) })
+		require.NotPanics(t, func() { m.CASReplicationIncrementCount("backfilled") })
+		require.NotPanics(t, func() { m.CASReplicationPassTime(time.Second) })
+		require.NotPanics(t, func() { m.DIDDiscoveryIncrementCount("published") })
+		require.NotPanics(t, func() { m.DIDDiscoveryQueryIncrementCount("anchor-origin", true) })
+		require.NotPanics(t, func() { m.DIDDiscoveryQueryIncrementCount("discovery-domain", false) })
 		require.NotPanics(t, func() { m.DocumentCreateUpdateTime(time.Second) })
 		require.NotPanics(t, func() { m.DocumentResolveTime(time.Second) })
 		require.NotPanics(t, func() { m.OutboxIncrementActivityCount("Create") })
@@ -86,3 +95,21 @@ func TestNewHistogram(t *testing.T) {
 func TestNewGuage(t *testing.T) {
 	require.NotNil(t, newGauge("activityPub", "metric_name", "Some help"))
 }
+
+func TestAnchorOriginLabel(t *testing.T) {
+	m := &Metrics{anchorOriginLabels: make(map[string]struct{})}
+
+	require.Equal(t, "orb.domain1.com", m.anchorOriginLabel("https://ORB.domain1.com"))
+	require.Equal(t, "orb.domain1.com", m.anchorOriginLabel("ipns://orb.domain1.com/path"))
+	require.Equal(t, "orb.domain2.com", m.anchorOriginLabel("orb.domain2.com"))
+	require.Equal(t, otherAnchorOriginLabel, m.anchorOriginLabel(""))
+
+	for i := len(m.anchorOriginLabels); i < maxAnchorOriginLabels; i++ {
+		m.anchorOriginLabel(fmt.Sprintf("https://orb.domain%d.com", i+10))
+	}
+
+	require.Len(t, m.anchorOriginLabels, maxAnchorOriginLabels)
+	require.Equal(t, otherAnchorOriginLabel, m.anchorOriginLabel("https://orb.new.com"))
+	require.Equal(t, "orb.domain1.com", m.anchorOriginLabel("https://orb.domain1.com"))
+	require.Len(t, m.anchorOriginLabels, maxAnchorOriginLabels)
+}
@@ -104,6 +104,26 @@ func (m *MetricsProvider) CASWriteTime(value time.Duration) {
 func (m *MetricsProvider) CASResolveTime(value time.Duration) {
 }
 
+// CASResolveSourceTime records the time it takes to retrieve a document from a remote CAS source.
+func (m *MetricsProvider) CASResolveSourceTime(source string, success bool, value time.Duration) {
+}
+
+// CASReplicationIncrementCount increments the number of local CAS resources processed by the IPFS replication job.
+func (m *MetricsProvider) CASReplicationIncrementCount(result string) {
+}
+
+// CASReplicationPassTime records the time it takes for the IPFS replication job to complete a pass.
+func (m *MetricsProvider) CASReplicationPassTime(value time.Duration) {
+}
+
+// DIDDiscoveryIncrementCount increments the number of DID discovery requests with the given result.
+func (m *MetricsProvider) DIDDiscoveryIncrementCount(result string) {
+}
+
+// DIDDiscoveryQueryIncrementCount increments the number of queries for the latest anchor of a DID.
+func (m *MetricsProvider) DIDDiscoveryQueryIncrementCount(source string, success bool) {
+}
+
 // BatchAckTime records the time to acknowledge all of the operations that are removed from the queue.
 func (m *MetricsProvider) BatchAckTime(value time.Duration) {
 }
@@ -23,6 +23,7 @@ import (
 
 	"github.com/trustbloc/orb/pkg/config"
 	ctxcommon "github.com/trustbloc/orb/pkg/context/common"
+	"github.com/trustbloc/orb/pkg/metrics"
 	vcommon "github.com/trustbloc/orb/pkg/protocolversion/versions/common"
 	protocolcfg "github.com/trustbloc/orb/pkg/protocolversion/versions/v1_0/config"
 	orboperationparser "github.com/trustbloc/orb/pkg/versions/1_0/operationparser"
@@ -47,7 +48,13 @@ func (v *Factory) Create(version string, casClient cas.Client, casResolver ctxco
 
 	opParser := operationparser.New(p,
 		operationparser.WithAnchorTimeValidator(anchortime.New(p.MaxOperationTimeDelta)),
-		operationparser.WithAnchorOriginValidator(anchororigin.New(sidetreeCfg.AnchorOrigins)))
+		operationparser.WithAnchorOriginValidator(
+			anchororigin.New(nil,
+				anchororigin.WithAllowedOriginsProvider(sidetreeCfg.AllowedOrigins),
+				anchororigin.WithMetrics(metrics.Get()),
+			),
+		),
+	)
 
 	orbParser := orboperationparser.New(opParser)
 
@@ -103,6 +110,10 @@ func (h *operationProviderWrapper) GetTxnOperations(transaction *txn.SidetreeTxn
 		resolver: h.casResolver,
 	}
 
+	if resolver, ok := h.casResolver.(batchResolver); ok {
+		h.prefetch(resolver, transaction)
+	}
+
 	// TODO: no need for wrapper any more (issue-671)
 	op := txnprovider.NewOperationProvider(*h.Protocol, h.parser, casClient, h.dp)
 
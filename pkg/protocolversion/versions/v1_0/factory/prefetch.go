@@ -0,0 +1,181 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+var logger = log.New("protocol-factory")
+
+var errNotPrefetched = errors.New("not retrieved in a batch")
+
+// batchResolver is implemented by CAS resolvers that are able to retrieve multiple CIDs in a single round trip.
+type batchResolver interface {
+	PrefetchBatch(hashesWithPossibleHints []string) ([][]byte, error)
+}
+
+// prefetch retrieves the Sidetree files that are referenced by the anchor string in batches (one batch for each
+// level of the file chain) so that they're in the local CAS by the time the operation provider reads them one at
+// a time. The CIDs are hinted with the domain of the anchor's origin (or the other equivalent references of the
+// transaction) so that the WebCAS batch endpoint of that domain may be used. Prefetching is opportunistic, so errors
+// are only logged and files that can't be retrieved in a batch are left for the operation provider to resolve.
+func (h *operationProviderWrapper) prefetch(resolver batchResolver, transaction *txn.SidetreeTxn) {
+	if err := h.prefetchFiles(resolver, transaction); err != nil {
+		logger.Debugf("Unable to prefetch Sidetree files for anchor [%s]: %s", transaction.AnchorString, err)
+	}
+}
+
+func (h *operationProviderWrapper) prefetchFiles(resolver batchResolver, transaction *txn.SidetreeTxn) error {
+	anchorData, err := txnprovider.ParseAnchorData(transaction.AnchorString)
+	if err != nil {
+		return err
+	}
+
+	hint := casHint(transaction.EquivalentReferences)
+
+	contents, err := resolver.PrefetchBatch(withHint(hint, anchorData.CoreIndexFileURI))
+	if err != nil {
+		return fmt.Errorf("resolve core index file: %w", err)
+	}
+
+	content, err := h.decompress(contents[0], h.MaxCoreIndexFileSize)
+	if err != nil {
+		return fmt.Errorf("core index file: %w", err)
+	}
+
+	cif, err := models.ParseCoreIndexFile(content)
+	if err != nil {
+		return fmt.Errorf("parse core index file: %w", err)
+	}
+
+	uris := nonEmpty(cif.CoreProofFileURI, cif.ProvisionalIndexFileURI)
+	if len(uris) == 0 {
+		return nil
+	}
+
+	contents, err = resolver.PrefetchBatch(withHint(hint, uris...))
+	if err != nil {
+		return fmt.Errorf("resolve core proof and provisional index files: %w", err)
+	}
+
+	if cif.ProvisionalIndexFileURI == "" {
+		return nil
+	}
+
+	// The provisional index file is always the last one.
+	content, err = h.decompress(contents[len(contents)-1], h.MaxProvisionalIndexFileSize)
+	if err != nil {
+		return fmt.Errorf("provisional index file: %w", err)
+	}
+
+	pif, err := models.ParseProvisionalIndexFile(content)
+	if err != nil {
+		return fmt.Errorf("parse provisional index file: %w", err)
+	}
+
+	uris = nonEmpty(pif.ProvisionalProofFileURI)
+
+	for _, chunk := range pif.Chunks {
+		uris = append(uris, chunk.ChunkFileURI)
+	}
+
+	if len(uris) == 0 {
+		return nil
+	}
+
+	_, err = resolver.PrefetchBatch(withHint(hint, uris...))
+	if err != nil {
+		return fmt.Errorf("resolve provisional proof and chunk files: %w", err)
+	}
+
+	return nil
+}
+
+func (h *operationProviderWrapper) decompress(content []byte, maxSize uint) ([]byte, error) {
+	if content == nil {
+		return nil, errNotPrefetched
+	}
+
+	if len(content) > int(maxSize) {
+		return nil, fmt.Errorf("content size %d exceeded maximum size %d", len(content), maxSize)
+	}
+
+	return h.dp.Decompress(h.CompressionAlgorithm, content)
+}
+
+func nonEmpty(values ...string) []string {
+	var result []string
+
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// casHint returns the CAS hint (with a trailing colon) for the Sidetree files of a transaction. The domain of the
+// first WebCAS link in the anchor hashlink (i.e. the anchor's origin) is preferred. Otherwise the domain of the
+// first "https" equivalent reference is used. An empty string is returned if neither is available.
+func casHint(equivalentReferences []string) string {
+	hl := hashlink.New()
+
+	for _, ref := range equivalentReferences {
+		if !strings.HasPrefix(ref, hashlink.HLPrefix) {
+			continue
+		}
+
+		info, err := hl.ParseHashLink(ref)
+		if err != nil {
+			logger.Debugf("Ignoring invalid equivalent reference [%s]: %s", ref, err)
+
+			continue
+		}
+
+		for _, link := range info.Links {
+			u, err := url.Parse(link)
+			if err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "" {
+				return "https:" + u.Host + ":"
+			}
+		}
+	}
+
+	for _, ref := range equivalentReferences {
+		if strings.HasPrefix(ref, "https:") {
+			return ref[:strings.LastIndex(ref, ":")+1]
+		}
+	}
+
+	return ""
+}
+
+// withHint prefixes the given CIDs with the hint. CIDs that already have a hint are left unchanged.
+func withHint(hint string, cids ...string) []string {
+	result := make([]string, len(cids))
+
+	for i, cid := range cids {
+		if hint == "" || strings.Contains(cid, ":") {
+			result[i] = cid
+		} else {
+			result[i] = hint + cid
+		}
+	}
+
+	return result
+}
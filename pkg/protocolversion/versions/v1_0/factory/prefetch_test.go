@@ -0,0 +1,333 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationparser"
+
+	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
+	apresthandler "github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	apmocks "github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	casresolver "github.com/trustbloc/orb/pkg/cas/resolver"
+	"github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/store/cas"
+	"github.com/trustbloc/orb/pkg/webcas"
+	webfingerclient "github.com/trustbloc/orb/pkg/webfinger/client"
+)
+
+const compressionAlgorithm = "GZIP"
+
+func TestOperationProviderWrapper_Prefetch(t *testing.T) {
+	cp := compression.New(compression.WithDefaultAlgorithms())
+
+	compress := func(t *testing.T, content string) []byte {
+		t.Helper()
+
+		compressed, err := cp.Compress(compressionAlgorithm, []byte(content))
+		require.NoError(t, err)
+
+		return compressed
+	}
+
+	p := &protocol.Protocol{
+		CompressionAlgorithm:        compressionAlgorithm,
+		MaxCoreIndexFileSize:        1000,
+		MaxProvisionalIndexFileSize: 1000,
+	}
+
+	newWrapper := func(r *mockBatchResolver) *operationProviderWrapper {
+		return &operationProviderWrapper{Protocol: p, parser: operationparser.New(*p), casResolver: r, dp: cp}
+	}
+
+	coreIndex := compress(t, `{"coreProofFileUri":"cp","provisionalIndexFileUri":"pi"}`)
+	provisionalIndex := compress(t, `{"provisionalProofFileUri":"pp","chunks":[{"chunkFileUri":"ch"}]}`)
+
+	t.Run("Success", func(t *testing.T) {
+		r := &mockBatchResolver{contents: map[string][]byte{"ci": coreIndex, "pi": provisionalIndex}}
+
+		newWrapper(r).prefetch(r, &txn.SidetreeTxn{AnchorString: "1.ci"})
+
+		require.Equal(t, [][]string{{"ci"}, {"cp", "pi"}, {"pp", "ch"}}, r.batches)
+	})
+
+	t.Run("Called from GetTxnOperations", func(t *testing.T) {
+		r := &mockBatchResolver{contents: map[string][]byte{"ci": coreIndex, "pi": provisionalIndex}}
+
+		_, err := newWrapper(r).GetTxnOperations(&txn.SidetreeTxn{AnchorString: "1.ci"})
+		require.Error(t, err)
+
+		require.Equal(t, [][]string{{"ci"}, {"cp", "pi"}, {"pp", "ch"}}, r.batches)
+	})
+
+	t.Run("Core proof file only", func(t *testing.T) {
+		r := &mockBatchResolver{contents: map[string][]byte{"ci": compress(t, `{"coreProofFileUri":"cp"}`)}}
+
+		newWrapper(r).prefetch(r, &txn.SidetreeTxn{AnchorString: "1.ci"})
+
+		require.Equal(t, [][]string{{"ci"}, {"cp"}}, r.batches)
+	})
+
+	t.Run("No referenced files", func(t *testing.T) {
+		r := &mockBatchResolver{contents: map[string][]byte{"ci": compress(t, `{}`)}}
+
+		newWrapper(r).prefetch(r, &txn.SidetreeTxn{AnchorString: "1.ci"})
+
+		require.Equal(t, [][]string{{"ci"}}, r.batches)
+	})
+
+	t.Run("No provisional proof or chunk files", func(t *testing.T) {
+		r := &mockBatchResolver{contents: map[string][]byte{
+			"ci": compress(t, `{"provisionalIndexFileUri":"pi"}`),
+			"pi": compress(t, `{}`),
+		}}
+
+		newWrapper(r).prefetch(r, &txn.SidetreeTxn{AnchorString: "1.ci"})
+
+		require.Equal(t, [][]string{{"ci"}, {"pi"}}, r.batches)
+	})
+
+	t.Run("Hinted with anchor origin", func(t *testing.T) {
+		r := &mockBatchResolver{contents: map[string][]byte{
+			"https:orb.domain1.com:ci": coreIndex,
+			"https:orb.domain1.com:pi": provisionalIndex,
+		}}
+
+		anchorHL, err := hashlink.New().CreateHashLink([]byte("anchor"),
+			[]string{"ipfs://anchor", "https://orb.domain1.com/cas/anchor"})
+		require.NoError(t, err)
+
+		newWrapper(r).prefetch(r, &txn.SidetreeTxn{
+			AnchorString:         "1.ci",
+			EquivalentReferences: []string{anchorHL, "https:orb.domain2.com:anchor"},
+		})
+
+		require.Equal(t, [][]string{
+			{"https:orb.domain1.com:ci"},
+			{"https:orb.domain1.com:cp", "https:orb.domain1.com:pi"},
+			{"https:orb.domain1.com:pp", "https:orb.domain1.com:ch"},
+		}, r.batches)
+	})
+
+	t.Run("Hinted with equivalent reference", func(t *testing.T) {
+		r := &mockBatchResolver{contents: map[string][]byte{"https:orb.domain2.com:8443:ci": compress(t, `{}`)}}
+
+		newWrapper(r).prefetch(r, &txn.SidetreeTxn{
+			AnchorString:         "1.ci",
+			EquivalentReferences: []string{"hl:invalid", "https:orb.domain2.com:8443:anchor"},
+		})
+
+		require.Equal(t, [][]string{{"https:orb.domain2.com:8443:ci"}}, r.batches)
+	})
+
+	t.Run("Invalid anchor string", func(t *testing.T) {
+		r := &mockBatchResolver{}
+
+		newWrapper(r).prefetch(r, &txn.SidetreeTxn{AnchorString: "ci"})
+
+		require.Empty(t, r.batches)
+	})
+
+	t.Run("Resolve errors", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			r := &mockBatchResolver{
+				contents: map[string][]byte{"ci": coreIndex, "pi": provisionalIndex},
+				err:      errors.New("injected resolve error"),
+				errAt:    i + 1,
+			}
+
+			err := newWrapper(r).prefetchFiles(r, &txn.SidetreeTxn{AnchorString: "1.ci"})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "injected resolve error")
+			require.Len(t, r.batches, i+1)
+		}
+	})
+
+	t.Run("Invalid files", func(t *testing.T) {
+		for _, contents := range []map[string][]byte{
+			{"ci": []byte("not compressed")},
+			{"ci": compress(t, "{")},
+			{"ci": make([]byte, p.MaxCoreIndexFileSize+1)},
+			{"ci": coreIndex, "pi": []byte("not compressed")},
+			{"ci": coreIndex, "pi": compress(t, "{")},
+			{"ci": coreIndex},
+			{},
+		} {
+			r := &mockBatchResolver{contents: contents}
+
+			require.Error(t, newWrapper(r).prefetchFiles(r, &txn.SidetreeTxn{AnchorString: "1.ci"}))
+		}
+	})
+}
+
+func TestOperationProviderWrapper_PrefetchFromAnchorOrigin(t *testing.T) {
+	cp := compression.New(compression.WithDefaultAlgorithms())
+
+	p := &protocol.Protocol{
+		CompressionAlgorithm:        compressionAlgorithm,
+		MaxCoreIndexFileSize:        1000,
+		MaxProvisionalIndexFileSize: 1000,
+	}
+
+	origin := newOriginServer(t)
+	defer origin.Close()
+
+	write := func(content string) string {
+		compressed, err := cp.Compress(compressionAlgorithm, []byte(content))
+		require.NoError(t, err)
+
+		hl, err := origin.casClient.Write(compressed)
+		require.NoError(t, err)
+
+		rh, err := hashlink.GetResourceHashFromHashLink(hl)
+		require.NoError(t, err)
+
+		return rh
+	}
+
+	cp1, pp, ch := write("core proof"), write("provisional proof"), write("chunk")
+	pi := write(fmt.Sprintf(`{"provisionalProofFileUri":"%s","chunks":[{"chunkFileUri":"%s"}]}`, pp, ch))
+	ci := write(fmt.Sprintf(`{"coreProofFileUri":"%s","provisionalIndexFileUri":"%s"}`, cp1, pi))
+
+	// The links in the anchor hashlink point to the WebCAS endpoint of the anchor's origin.
+	anchorHL, err := origin.casClient.Write([]byte("anchor"))
+	require.NoError(t, err)
+
+	localCAS, err := cas.New(mem.NewProvider(), "https://domain.com/cas", nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	resolver := casresolver.New(localCAS, nil,
+		casresolver.NewWebCASResolver(
+			transport.New(&http.Client{}, testutil.MustParseURL("https://example.com/keys/public-key"),
+				transport.DefaultSigner(), transport.DefaultSigner()),
+			webfingerclient.New(), "http"), &orbmocks.MetricsProvider{})
+
+	w := &operationProviderWrapper{Protocol: p, parser: operationparser.New(*p), casResolver: resolver, dp: cp}
+
+	w.prefetch(resolver, &txn.SidetreeTxn{AnchorString: "1." + ci, EquivalentReferences: []string{anchorHL}})
+
+	// One batch POST for each level of the file chain and no individual GETs.
+	require.Equal(t, 3, origin.count(webcas.BatchPath))
+	require.Equal(t, 0, origin.count("/cas/"))
+
+	for _, rh := range []string{ci, cp1, pi, pp, ch} {
+		_, err := localCAS.Read(rh)
+		require.NoError(t, err)
+	}
+}
+
+type originServer struct {
+	*httptest.Server
+
+	casClient *cas.CAS
+	mutex     sync.Mutex
+	requests  map[string]int
+}
+
+// newOriginServer returns a test server with host-meta and WebCAS (including batch) endpoints.
+func newOriginServer(t *testing.T) *originServer {
+	t.Helper()
+
+	s := &originServer{requests: make(map[string]int)}
+
+	router := mux.NewRouter()
+
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.mutex.Lock()
+
+			if strings.HasPrefix(r.URL.Path, webcas.BatchPath) {
+				s.requests[webcas.BatchPath]++
+			} else if strings.HasPrefix(r.URL.Path, "/cas/") {
+				s.requests["/cas/"]++
+			}
+
+			s.mutex.Unlock()
+
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	s.Server = httptest.NewServer(router)
+
+	casClient, err := cas.New(mem.NewProvider(), s.URL+"/cas", nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	s.casClient = casClient
+
+	operations, err := restapi.New(&restapi.Config{BaseURL: s.URL, WebCASPath: "/cas"})
+	require.NoError(t, err)
+
+	for _, h := range operations.GetRESTHandlers() {
+		router.HandleFunc(h.Path(), h.Handler())
+	}
+
+	b := webcas.NewBatch(&apresthandler.Config{}, memstore.New(""), &apmocks.SignatureVerifier{}, casClient)
+	router.HandleFunc(b.Path(), b.Handler()).Methods(b.Method())
+
+	wc := webcas.New(&apresthandler.Config{}, memstore.New(""), &apmocks.SignatureVerifier{}, casClient)
+	router.HandleFunc(wc.Path(), wc.Handler()).Methods(wc.Method())
+
+	return s
+}
+
+func (s *originServer) count(path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests[path]
+}
+
+type mockBatchResolver struct {
+	contents map[string][]byte
+	batches  [][]string
+	err      error
+	errAt    int
+}
+
+func (m *mockBatchResolver) Resolve(_ *url.URL, cid string, _ []byte) ([]byte, error) {
+	content, ok := m.contents[cid]
+	if !ok {
+		return nil, fmt.Errorf("content not found for [%s]", cid)
+	}
+
+	return content, nil
+}
+
+func (m *mockBatchResolver) PrefetchBatch(cids []string) ([][]byte, error) {
+	m.batches = append(m.batches, cids)
+
+	if len(m.batches) == m.errAt {
+		return nil, m.err
+	}
+
+	results := make([][]byte, len(cids))
+
+	for i, cid := range cids {
+		results[i] = m.contents[cid]
+	}
+
+	return results, nil
+}
@@ -0,0 +1,381 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/cenkalti/backoff"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/pubsub/redelivery"
+	"github.com/trustbloc/orb/pkg/pubsub/spi"
+)
+
+var logger = log.New("pubsub")
+
+const (
+	// URIScheme is the scheme of a Kafka message queue URI, e.g. kafka://broker1:9092,broker2:9092.
+	URIScheme = "kafka://"
+
+	defaultGroupID               = "orb"
+	defaultMaxConnectRetries     = 25
+	defaultMaxConnectInterval    = 5 * time.Second
+	defaultMaxConnectElapsedTime = 3 * time.Minute
+	defaultConsumeRetryInterval  = time.Second
+
+	headerUUID    = "_orb_message_uuid"
+	metadataTopic = "_orb_topic"
+)
+
+// Config holds the configuration for the publisher/subscriber.
+type Config struct {
+	// URI contains the comma-separated list of brokers, e.g. kafka://broker1:9092,broker2:9092.
+	URI string
+
+	// GroupID is the prefix of the consumer group IDs. The consumer group for a topic is <GroupID>-<topic>,
+	// so all server instances that use the same GroupID share the messages published to a topic.
+	GroupID string
+
+	MaxConnectRetries uint64
+
+	// RedeliveryConfig holds the configuration for redelivering messages that are nacked. If a message
+	// cannot be redelivered after the maximum number of retries then it is published to the undeliverable topic.
+	RedeliveryConfig *redelivery.Config
+}
+
+type producer interface {
+	SendMessages(msgs []*sarama.ProducerMessage) error
+	Close() error
+}
+
+type consumerGroup interface {
+	Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error
+	Close() error
+}
+
+type producerFactory = func() (producer, error)
+
+type consumerGroupFactory = func(groupID string) (consumerGroup, error)
+
+type redeliveryService interface {
+	Start()
+	Stop()
+	Add(msg *message.Message) (time.Time, error)
+}
+
+// PubSub implements a publisher/subscriber that connects to a Kafka-compatible message broker.
+type PubSub struct {
+	*lifecycle.Lifecycle
+	Config
+
+	brokers              []string
+	producer             producer
+	createProducer       producerFactory
+	createConsumerGroup  consumerGroupFactory
+	subscriptions        []*subscription
+	mutex                sync.RWMutex
+	redeliveryService    redeliveryService
+	redeliveryChan       chan *message.Message
+	consumeRetryInterval time.Duration
+}
+
+// New returns a new Kafka publisher/subscriber.
+func New(cfg Config) *PubSub {
+	p := newPubSub(cfg)
+
+	saramaConfig := newSaramaConfig()
+
+	p.createProducer = func() (producer, error) {
+		return sarama.NewSyncProducer(p.brokers, saramaConfig)
+	}
+
+	p.createConsumerGroup = func(groupID string) (consumerGroup, error) {
+		return sarama.NewConsumerGroup(p.brokers, groupID, saramaConfig)
+	}
+
+	// Start the service immediately.
+	p.Start()
+
+	return p
+}
+
+func newPubSub(cfg Config) *PubSub {
+	if cfg.GroupID == "" {
+		cfg.GroupID = defaultGroupID
+	}
+
+	redeliveryChan := make(chan *message.Message)
+
+	p := &PubSub{
+		Config:               cfg,
+		brokers:              parseBrokers(cfg.URI),
+		redeliveryChan:       redeliveryChan,
+		redeliveryService:    redelivery.NewService("kafka", cfg.RedeliveryConfig, redeliveryChan),
+		consumeRetryInterval: defaultConsumeRetryInterval,
+	}
+
+	p.Lifecycle = lifecycle.New("kafka",
+		lifecycle.WithStart(p.start),
+		lifecycle.WithStop(p.stop))
+
+	return p
+}
+
+// Subscribe subscribes to a topic and returns the Go channel over which messages
+// are sent. The returned channel will be closed when Close() is called on this struct.
+func (p *PubSub) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	return p.SubscribeWithOpts(ctx, topic)
+}
+
+// SubscribeWithOpts subscribes to a topic using the given options, and returns the Go channel over which messages
+// are sent. If a pool size is specified then the given number of consumers are created, each of which is a member
+// of the topic's consumer group. (Note that the number of consumers which receive messages concurrently is limited
+// by the number of partitions of the topic.) The returned channel will be closed when Close() is called on this
+// struct.
+func (p *PubSub) SubscribeWithOpts(ctx context.Context, topic string,
+	opts ...spi.Option) (<-chan *message.Message, error) {
+	if p.State() != lifecycle.StateStarted {
+		return nil, lifecycle.ErrNotStarted
+	}
+
+	options := &spi.Options{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	size := options.PoolSize
+	if size == 0 {
+		size = 1
+	}
+
+	logger.Debugf("Subscribing to topic [%s] with %d consumer(s)", topic, size)
+
+	s, err := p.newSubscription(ctx, topic, int(size))
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	p.subscriptions = append(p.subscriptions, s)
+	p.mutex.Unlock()
+
+	s.start()
+
+	return s.msgChan, nil
+}
+
+// Publish publishes the given messages to the given topic.
+func (p *PubSub) Publish(topic string, messages ...*message.Message) error {
+	if p.State() != lifecycle.StateStarted {
+		return lifecycle.ErrNotStarted
+	}
+
+	logger.Debugf("Publishing messages to topic [%s]", topic)
+
+	return p.publish(topic, messages...)
+}
+
+// Close stops the publisher/subscriber.
+func (p *PubSub) Close() error {
+	p.Stop()
+
+	return nil
+}
+
+func (p *PubSub) publish(topic string, messages ...*message.Message) error {
+	producerMessages := make([]*sarama.ProducerMessage, len(messages))
+
+	for i, msg := range messages {
+		producerMessages[i] = newProducerMessage(topic, msg)
+	}
+
+	if err := p.producer.SendMessages(producerMessages); err != nil {
+		return errors.NewTransient(fmt.Errorf("send messages to topic [%s]: %w", topic, err))
+	}
+
+	return nil
+}
+
+func (p *PubSub) start() {
+	logger.Infof("Connecting to Kafka brokers %s", p.brokers)
+
+	maxRetries := p.MaxConnectRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxConnectRetries
+	}
+
+	err := backoff.RetryNotify(
+		func() error {
+			pub, err := p.createProducer()
+			if err != nil {
+				return err
+			}
+
+			p.producer = pub
+
+			return nil
+		},
+		backoff.WithMaxRetries(newBackOff(), maxRetries),
+		func(err error, duration time.Duration) {
+			logger.Infof("Error connecting to Kafka brokers %s after %s: %s", p.brokers, duration, err)
+		},
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to connect to Kafka brokers after %d attempts", maxRetries))
+	}
+
+	go p.redeliver()
+
+	p.redeliveryService.Start()
+
+	logger.Infof("Successfully connected to Kafka brokers: %s", p.brokers)
+}
+
+func (p *PubSub) stop() {
+	logger.Debugf("Closing subscriptions...")
+
+	p.mutex.RLock()
+
+	for _, s := range p.subscriptions {
+		s.stop()
+	}
+
+	p.mutex.RUnlock()
+
+	logger.Debugf("Stopping redelivery service...")
+
+	p.redeliveryService.Stop()
+
+	close(p.redeliveryChan)
+
+	logger.Debugf("Closing producer...")
+
+	if err := p.producer.Close(); err != nil {
+		logger.Warnf("Error closing producer: %s", err)
+	}
+}
+
+// handleNack schedules the given message for redelivery. If the message has already been redelivered the maximum
+// number of times then it is published to the undeliverable topic.
+func (p *PubSub) handleNack(topic string, msg *message.Message) {
+	msg.Metadata.Set(metadataTopic, topic)
+
+	redeliveryTime, err := p.redeliveryService.Add(msg)
+	if err == nil {
+		logger.Debugf("[%s] Message [%s] will be redelivered at %s", topic, msg.UUID, redeliveryTime)
+
+		return
+	}
+
+	logger.Warnf("[%s] Message [%s] could not be redelivered. Posting to undeliverable topic: %s",
+		topic, msg.UUID, err)
+
+	if err := p.publish(spi.UndeliverableTopic, msg); err != nil {
+		logger.Errorf("[%s] Error posting message [%s] to undeliverable topic: %s", topic, msg.UUID, err)
+	}
+}
+
+func (p *PubSub) redeliver() {
+	for msg := range p.redeliveryChan {
+		topic := msg.Metadata.Get(metadataTopic)
+
+		logger.Debugf("[%s] Redelivering message [%s]", topic, msg.UUID)
+
+		if err := p.publish(topic, msg); err != nil {
+			logger.Errorf("[%s] Error redelivering message [%s]: %s", topic, msg.UUID, err)
+		}
+	}
+}
+
+func newProducerMessage(topic string, msg *message.Message) *sarama.ProducerMessage {
+	headers := []sarama.RecordHeader{{Key: []byte(headerUUID), Value: []byte(msg.UUID)}}
+
+	for k, v := range msg.Metadata {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+	}
+
+	return &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(msg.Payload),
+		Headers: headers,
+	}
+}
+
+func newMessage(consumerMsg *sarama.ConsumerMessage) *message.Message {
+	var msgID string
+
+	metadata := make(message.Metadata)
+
+	for _, header := range consumerMsg.Headers {
+		if string(header.Key) == headerUUID {
+			msgID = string(header.Value)
+
+			continue
+		}
+
+		metadata.Set(string(header.Key), string(header.Value))
+	}
+
+	if msgID == "" {
+		msgID = watermill.NewUUID()
+	}
+
+	msg := message.NewMessage(msgID, consumerMsg.Value)
+	msg.Metadata = metadata
+
+	return msg
+}
+
+func newSaramaConfig() *sarama.Config {
+	cfg := sarama.NewConfig()
+
+	// Consumer groups require at least version 0.10.2.
+	cfg.Version = sarama.V2_0_0_0
+	cfg.Producer.RequiredAcks = sarama.WaitForAll
+	cfg.Producer.Return.Successes = true
+	cfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	return cfg
+}
+
+func parseBrokers(uri string) []string {
+	var brokers []string
+
+	for _, broker := range strings.Split(strings.TrimPrefix(uri, URIScheme), ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+
+	return brokers
+}
+
+func newBackOff() backoff.BackOff {
+	b := &backoff.ExponentialBackOff{
+		InitialInterval:     backoff.DefaultInitialInterval,
+		RandomizationFactor: backoff.DefaultRandomizationFactor,
+		Multiplier:          backoff.DefaultMultiplier,
+		MaxInterval:         defaultMaxConnectInterval,
+		MaxElapsedTime:      defaultMaxConnectElapsedTime,
+		Clock:               backoff.SystemClock,
+	}
+
+	b.Reset()
+
+	return b
+}
@@ -0,0 +1,294 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/require"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/pubsub/redelivery"
+	"github.com/trustbloc/orb/pkg/pubsub/spi"
+)
+
+func TestKafka(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		const topic = "some-topic"
+
+		broker := newStubBroker()
+
+		p := newTestPubSub(t, Config{}, broker)
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("some payload"))
+		msg.Metadata.Set("some-key", "some-value")
+
+		require.NoError(t, p.Publish(topic, msg))
+
+		select {
+		case m := <-msgChan:
+			require.Equal(t, msg.UUID, m.UUID)
+			require.Equal(t, msg.Payload, m.Payload)
+			require.Equal(t, "some-value", m.Metadata.Get("some-key"))
+
+			m.Ack()
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for message")
+		}
+
+		require.Eventually(t, func() bool { return broker.markedOffset(topic) == 1 }, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, p.Close())
+
+		_, ok := <-msgChan
+		require.False(t, ok)
+
+		_, err = p.Subscribe(context.Background(), topic)
+		require.True(t, errors.Is(err, lifecycle.ErrNotStarted))
+		require.True(t, errors.Is(p.Publish(topic, msg), lifecycle.ErrNotStarted))
+	})
+
+	t.Run("Connection failure", func(t *testing.T) {
+		require.Panics(t, func() {
+			p := New(Config{URI: "kafka://localhost:9999", MaxConnectRetries: 1})
+			require.NotNil(t, p)
+		})
+	})
+
+	t.Run("Pooled subscriber -> success", func(t *testing.T) {
+		const (
+			n     = 100
+			topic = "pooled"
+		)
+
+		p := newTestPubSub(t, Config{}, newStubBroker())
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err := p.SubscribeWithOpts(context.Background(), topic, spi.WithPool(5))
+		require.NoError(t, err)
+
+		publishedMessages := &sync.Map{}
+		receivedMessages := &sync.Map{}
+
+		var wg sync.WaitGroup
+
+		wg.Add(n)
+
+		go func() {
+			for m := range msgChan {
+				receivedMessages.Store(m.UUID, m)
+
+				m.Ack()
+
+				wg.Done()
+			}
+		}()
+
+		for i := 0; i < n; i++ {
+			msg := message.NewMessage(watermill.NewUUID(), []byte(fmt.Sprintf("payload %d", i)))
+			publishedMessages.Store(msg.UUID, msg)
+
+			require.NoError(t, p.Publish(topic, msg))
+		}
+
+		wg.Wait()
+
+		publishedMessages.Range(func(msgID, _ interface{}) bool {
+			_, ok := receivedMessages.Load(msgID)
+			require.Truef(t, ok, "message not received: %s", msgID)
+
+			return true
+		})
+	})
+
+	t.Run("Nack -> redelivery and undeliverable", func(t *testing.T) {
+		const (
+			topic      = "nacked"
+			maxRetries = 2
+		)
+
+		p := newTestPubSub(t, Config{
+			RedeliveryConfig: &redelivery.Config{
+				MaxRetries:     maxRetries,
+				InitialBackoff: 10 * time.Millisecond,
+				MaxBackoff:     50 * time.Millisecond,
+				BackoffFactor:  1.5,
+				MaxMessages:    10,
+			},
+		}, newStubBroker())
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		undeliverableChan, err := p.Subscribe(context.Background(), spi.UndeliverableTopic)
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("some payload"))
+
+		require.NoError(t, p.Publish(topic, msg))
+
+		for i := 0; i <= maxRetries; i++ {
+			select {
+			case m := <-msgChan:
+				require.Equal(t, msg.UUID, m.UUID)
+
+				m.Nack()
+			case <-time.After(time.Second):
+				t.Fatalf("timed out waiting for delivery %d", i)
+			}
+		}
+
+		select {
+		case m := <-undeliverableChan:
+			require.Equal(t, msg.UUID, m.UUID)
+			require.Equal(t, topic, m.Metadata.Get(metadataTopic))
+
+			m.Ack()
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for undeliverable message")
+		}
+	})
+
+	t.Run("Publish error", func(t *testing.T) {
+		errExpected := errors.New("injected send error")
+
+		broker := newStubBroker()
+		broker.errSend = errExpected
+
+		p := newTestPubSub(t, Config{}, broker)
+		defer func() { require.NoError(t, p.Close()) }()
+
+		err := p.Publish("some-topic", message.NewMessage(watermill.NewUUID(), []byte("some payload")))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, errExpected))
+		require.True(t, orberrors.IsTransient(err))
+	})
+
+	t.Run("Create consumer group error", func(t *testing.T) {
+		errExpected := errors.New("injected consumer group error")
+
+		p := newTestPubSub(t, Config{}, newStubBroker())
+		defer func() { require.NoError(t, p.Close()) }()
+
+		p.createConsumerGroup = func(string) (consumerGroup, error) {
+			return nil, errExpected
+		}
+
+		_, err := p.SubscribeWithOpts(context.Background(), "some-topic", spi.WithPool(2))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Consume error -> retry", func(t *testing.T) {
+		const topic = "some-topic"
+
+		broker := newStubBroker()
+		broker.errConsume = errors.New("injected consume error")
+
+		p := newTestPubSub(t, Config{}, broker)
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("some payload"))
+
+		require.NoError(t, p.Publish(topic, msg))
+
+		select {
+		case m := <-msgChan:
+			require.Equal(t, msg.UUID, m.UUID)
+
+			m.Ack()
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for message")
+		}
+	})
+
+	t.Run("Close without ack", func(t *testing.T) {
+		const topic = "some-topic"
+
+		broker := newStubBroker()
+
+		p := newTestPubSub(t, Config{}, broker)
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		require.NoError(t, p.Publish(topic, message.NewMessage(watermill.NewUUID(), []byte("some payload"))))
+
+		select {
+		case <-msgChan:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for message")
+		}
+
+		require.NoError(t, p.Close())
+
+		// The message was neither acked nor nacked so the offset should not have been committed.
+		require.Zero(t, broker.markedOffset(topic))
+	})
+}
+
+func TestNewMessage(t *testing.T) {
+	t.Run("With UUID", func(t *testing.T) {
+		msg := message.NewMessage(watermill.NewUUID(), []byte("some payload"))
+		msg.Metadata.Set("some-key", "some-value")
+
+		pm := newProducerMessage("some-topic", msg)
+		require.Equal(t, "some-topic", pm.Topic)
+
+		headers := make([]*sarama.RecordHeader, len(pm.Headers))
+
+		for i := range pm.Headers {
+			headers[i] = &pm.Headers[i]
+		}
+
+		m := newMessage(&sarama.ConsumerMessage{Value: msg.Payload, Headers: headers})
+		require.Equal(t, msg.UUID, m.UUID)
+		require.Equal(t, msg.Payload, m.Payload)
+		require.Equal(t, msg.Metadata, m.Metadata)
+	})
+
+	t.Run("Without UUID", func(t *testing.T) {
+		m := newMessage(&sarama.ConsumerMessage{Value: []byte("some payload")})
+		require.NotEmpty(t, m.UUID)
+		require.Empty(t, m.Metadata)
+	})
+}
+
+func TestParseBrokers(t *testing.T) {
+	require.Equal(t, []string{"localhost:9092"}, parseBrokers("kafka://localhost:9092"))
+	require.Equal(t, []string{"broker1:9092", "broker2:9092"}, parseBrokers("kafka://broker1:9092, broker2:9092,"))
+	require.Empty(t, parseBrokers("kafka://"))
+}
+
+func newTestPubSub(t *testing.T, cfg Config, broker *stubBroker) *PubSub {
+	t.Helper()
+
+	p := newPubSub(cfg)
+	p.consumeRetryInterval = 10 * time.Millisecond
+	p.createProducer = func() (producer, error) { return broker, nil }
+	p.createConsumerGroup = broker.newConsumerGroup
+
+	p.Start()
+
+	return p
+}
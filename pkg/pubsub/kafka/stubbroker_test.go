@@ -0,0 +1,192 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Shopify/sarama"
+)
+
+// stubBroker is an in-process broker which implements the producer and consumer group interfaces. Each topic
+// has a single partition whose messages are shared by all members of a consumer group.
+type stubBroker struct {
+	mutex      sync.Mutex
+	topics     map[string]*stubTopic
+	errSend    error
+	errConsume error
+}
+
+type stubTopic struct {
+	msgChan chan *sarama.ConsumerMessage
+	offset  int64
+	marked  int64
+}
+
+func newStubBroker() *stubBroker {
+	return &stubBroker{
+		topics: make(map[string]*stubTopic),
+	}
+}
+
+func (b *stubBroker) topic(name string) *stubTopic {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	t, ok := b.topics[name]
+	if !ok {
+		t = &stubTopic{msgChan: make(chan *sarama.ConsumerMessage, 1000)}
+
+		b.topics[name] = t
+	}
+
+	return t
+}
+
+func (b *stubBroker) markedOffset(topic string) int64 {
+	t := b.topic(topic)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return t.marked
+}
+
+func (b *stubBroker) SendMessages(msgs []*sarama.ProducerMessage) error {
+	if b.errSend != nil {
+		return b.errSend
+	}
+
+	for _, msg := range msgs {
+		value, err := msg.Value.Encode()
+		if err != nil {
+			return err
+		}
+
+		headers := make([]*sarama.RecordHeader, len(msg.Headers))
+
+		for i := range msg.Headers {
+			headers[i] = &msg.Headers[i]
+		}
+
+		t := b.topic(msg.Topic)
+
+		b.mutex.Lock()
+		t.offset++
+		offset := t.offset
+		b.mutex.Unlock()
+
+		t.msgChan <- &sarama.ConsumerMessage{
+			Topic:   msg.Topic,
+			Offset:  offset,
+			Value:   value,
+			Headers: headers,
+		}
+	}
+
+	return nil
+}
+
+func (b *stubBroker) Close() error {
+	return nil
+}
+
+func (b *stubBroker) newConsumerGroup(string) (consumerGroup, error) {
+	return &stubConsumerGroup{
+		broker: b,
+		closed: make(chan struct{}),
+	}, nil
+}
+
+type stubConsumerGroup struct {
+	broker    *stubBroker
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (g *stubConsumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	select {
+	case <-g.closed:
+		return sarama.ErrClosedConsumerGroup
+	default:
+	}
+
+	g.broker.mutex.Lock()
+	errConsume := g.broker.errConsume
+	g.broker.errConsume = nil
+	g.broker.mutex.Unlock()
+
+	if errConsume != nil {
+		return errConsume
+	}
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-g.closed:
+			cancel()
+		case <-sessionCtx.Done():
+		}
+	}()
+
+	session := &stubSession{ctx: sessionCtx, broker: g.broker}
+
+	if err := handler.Setup(session); err != nil {
+		return err
+	}
+
+	err := handler.ConsumeClaim(session, &stubClaim{msgChan: g.broker.topic(topics[0]).msgChan})
+
+	if e := handler.Cleanup(session); e != nil {
+		return e
+	}
+
+	return err
+}
+
+func (g *stubConsumerGroup) Close() error {
+	g.closeOnce.Do(func() {
+		close(g.closed)
+	})
+
+	return nil
+}
+
+type stubSession struct {
+	sarama.ConsumerGroupSession
+
+	ctx    context.Context
+	broker *stubBroker
+}
+
+func (s *stubSession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *stubSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	t := s.broker.topic(msg.Topic)
+
+	s.broker.mutex.Lock()
+	defer s.broker.mutex.Unlock()
+
+	if msg.Offset > t.marked {
+		t.marked = msg.Offset
+	}
+}
+
+type stubClaim struct {
+	sarama.ConsumerGroupClaim
+
+	msgChan chan *sarama.ConsumerMessage
+}
+
+func (c *stubClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.msgChan
+}
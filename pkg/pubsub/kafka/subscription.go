@@ -0,0 +1,181 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type nackHandler func(topic string, msg *message.Message)
+
+// subscription consumes messages from a topic using one or more members of the topic's consumer group.
+// Messages received by all of the members are sent over a single channel.
+type subscription struct {
+	topic         string
+	groups        []consumerGroup
+	msgChan       chan *message.Message
+	handleNack    nackHandler
+	ctx           context.Context
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+	retryInterval time.Duration
+}
+
+func (p *PubSub) newSubscription(ctx context.Context, topic string, size int) (*subscription, error) {
+	groupID := fmt.Sprintf("%s-%s", p.GroupID, topic)
+
+	groups := make([]consumerGroup, size)
+
+	for i := 0; i < size; i++ {
+		group, err := p.createConsumerGroup(groupID)
+		if err != nil {
+			closeGroups(groups[0:i])
+
+			return nil, fmt.Errorf("create consumer group [%s]: %w", groupID, err)
+		}
+
+		groups[i] = group
+	}
+
+	s := &subscription{
+		topic:         topic,
+		groups:        groups,
+		msgChan:       make(chan *message.Message, size),
+		handleNack:    p.handleNack,
+		retryInterval: p.consumeRetryInterval,
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	return s, nil
+}
+
+func (s *subscription) start() {
+	for i, group := range s.groups {
+		s.wg.Add(1)
+
+		go s.consume(i, group)
+	}
+}
+
+func (s *subscription) stop() {
+	logger.Debugf("[%s] Stopping subscription...", s.topic)
+
+	s.cancel()
+
+	closeGroups(s.groups)
+
+	s.wg.Wait()
+
+	close(s.msgChan)
+
+	logger.Debugf("[%s] ... subscription stopped.", s.topic)
+}
+
+// consume joins the consumer group and consumes messages until the subscription is stopped. Consume returns
+// whenever the group is rebalanced, in which case it is invoked again in order to rejoin the group.
+func (s *subscription) consume(i int, group consumerGroup) {
+	defer s.wg.Done()
+
+	logger.Debugf("[%s-%d] Starting consumer", s.topic, i)
+
+	for {
+		err := group.Consume(s.ctx, []string{s.topic}, s)
+
+		if s.ctx.Err() != nil || errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			logger.Debugf("[%s-%d] Consumer stopped", s.topic, i)
+
+			return
+		}
+
+		if err != nil {
+			logger.Warnf("[%s-%d] Error consuming messages. Retrying in %s: %s", s.topic, i, s.retryInterval, err)
+
+			select {
+			case <-time.After(s.retryInterval):
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Setup is invoked by the consumer group at the beginning of a new session.
+func (s *subscription) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// Cleanup is invoked by the consumer group at the end of a session.
+func (s *subscription) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim processes the messages of the claimed partition. Each message is sent to the subscriber and the
+// offset of the message is committed after the message is either acked or nacked. A message that is nacked is
+// scheduled for redelivery (or posted to the undeliverable topic).
+func (s *subscription) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case consumerMsg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+
+			if !s.process(session, consumerMsg) {
+				return nil
+			}
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// process sends the message to the subscriber and waits for an ack or nack. False is returned if the session
+// ended before the message was processed, in which case the offset is not committed and the message will be
+// consumed again by whichever member is assigned the partition.
+func (s *subscription) process(session sarama.ConsumerGroupSession, consumerMsg *sarama.ConsumerMessage) bool {
+	msg := newMessage(consumerMsg)
+
+	logger.Debugf("[%s] Received message [%s] - Partition: %d, Offset: %d",
+		s.topic, msg.UUID, consumerMsg.Partition, consumerMsg.Offset)
+
+	select {
+	case s.msgChan <- msg:
+	case <-session.Context().Done():
+		return false
+	}
+
+	select {
+	case <-msg.Acked():
+		logger.Debugf("[%s] Message was acked [%s]", s.topic, msg.UUID)
+	case <-msg.Nacked():
+		logger.Debugf("[%s] Message was nacked [%s]", s.topic, msg.UUID)
+
+		s.handleNack(s.topic, msg)
+	case <-session.Context().Done():
+		return false
+	}
+
+	session.MarkMessage(consumerMsg, "")
+
+	return true
+}
+
+func closeGroups(groups []consumerGroup) {
+	for _, group := range groups {
+		if err := group.Close(); err != nil {
+			logger.Warnf("Error closing consumer group: %s", err)
+		}
+	}
+}
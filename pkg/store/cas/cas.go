@@ -9,6 +9,7 @@ package cas
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/bluele/gcache"
@@ -24,6 +25,13 @@ import (
 var logger = log.New("cas-store")
 
 const (
+	// StoreName is the name of the underlying store that holds the CAS content.
+	StoreName = "cas_store"
+
+	// CreatedTagName is the name of the tag whose value is the time (in Unix seconds) at which the content was
+	// last written. Content written before this tag was introduced is not tagged.
+	CreatedTagName = "created"
+
 	defaultCacheSize = 1000
 	casType          = "local"
 )
@@ -50,11 +58,16 @@ type CAS struct {
 // If no CID version is specified, then v1 will be used by default.
 func New(provider ariesstorage.Provider, casLink string, ipfsClient *ipfs.Client,
 	metrics metricsProvider, cacheSize int, opts ...extendedcasclient.CIDFormatOption) (*CAS, error) {
-	cas, err := provider.OpenStore("cas_store")
+	cas, err := provider.OpenStore(StoreName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open store in underlying storage provider: %w", err)
 	}
 
+	err = provider.SetStoreConfig(StoreName, ariesstorage.StoreConfiguration{TagNames: []string{CreatedTagName}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration: %w", err)
+	}
+
 	if cacheSize == 0 {
 		cacheSize = defaultCacheSize
 	}
@@ -102,7 +115,8 @@ func (p *CAS) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFo
 		return "", fmt.Errorf("failed to create resource hash from content: %w", err)
 	}
 
-	err = p.cas.Put(resourceHash, content)
+	err = p.cas.Put(resourceHash, content,
+		ariesstorage.Tag{Name: CreatedTagName, Value: strconv.FormatInt(time.Now().Unix(), 10)})
 	if err != nil {
 		return "", orberrors.NewTransient(fmt.Errorf("failed to put content into underlying storage provider: %w", err))
 	}
@@ -136,6 +150,82 @@ func (p *CAS) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFo
 	return hashlink.GetHashLink(resourceHash, metadata), nil
 }
 
+// WriteWithResourceHash writes the given content (as for Write) and also stores it under the given resource hash
+// if it differs from the resource hash computed by this CAS (e.g. if it was created with a different hash algorithm),
+// so that the content may be read using the resource hash that was requested. The content must match the given
+// resource hash. Returns the address of the content.
+func (p *CAS) WriteWithResourceHash(content []byte, resourceHash string) (string, error) {
+	if err := p.hl.VerifyResourceHash(resourceHash, content); err != nil {
+		return "", fmt.Errorf("verify content against resource hash [%s]: %w", resourceHash, err)
+	}
+
+	hl, err := p.Write(content)
+	if err != nil {
+		return "", err
+	}
+
+	localResourceHash, err := hashlink.GetResourceHashFromHashLink(hl)
+	if err != nil {
+		return "", fmt.Errorf("get resource hash from hashlink [%s]: %w", hl, err)
+	}
+
+	if localResourceHash == resourceHash {
+		return hl, nil
+	}
+
+	err = p.cas.Put(resourceHash, content,
+		ariesstorage.Tag{Name: CreatedTagName, Value: strconv.FormatInt(time.Now().Unix(), 10)})
+	if err != nil {
+		return "", orberrors.NewTransient(fmt.Errorf("failed to put content into underlying storage provider: %w", err))
+	}
+
+	if err = p.cache.Set(resourceHash, content); err != nil {
+		// This shouldn't be possible.
+		logger.Warnf("Error caching content for resource hash[%s]: %s", resourceHash, err)
+	}
+
+	logger.Debugf("Stored content for resource hash [%s] which was also stored as [%s]",
+		resourceHash, localResourceHash)
+
+	return hl, nil
+}
+
+// Delete deletes the content with the given resource hashes from the underlying local CAS provider and
+// evicts the content from the cache. (Content that was also written to IPFS is not deleted from IPFS.)
+func (p *CAS) Delete(resourceHashes ...string) error {
+	if len(resourceHashes) == 0 {
+		return nil
+	}
+
+	operations := make([]ariesstorage.Operation, len(resourceHashes))
+
+	for i, resourceHash := range resourceHashes {
+		operations[i] = ariesstorage.Operation{Key: resourceHash}
+	}
+
+	// Evict the content both before and after deleting it so that a concurrent read doesn't leave stale content
+	// in the cache.
+	p.evict(resourceHashes)
+
+	err := p.cas.Batch(operations)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to delete %d resources from the local CAS provider: %w",
+			len(operations), err))
+	}
+
+	p.evict(resourceHashes)
+
+	logger.Debugf("Deleted %d resources from the local CAS provider", len(resourceHashes))
+
+	return nil
+}
+
+func (p *CAS) evict(resourceHashes []string) {
+	for _, resourceHash := range resourceHashes {
+		p.cache.Remove(resourceHash)
+	}
+}
+
 // GetPrimaryWriterType returns primary writer type.
 func (p *CAS) GetPrimaryWriterType() string {
 	return "local"
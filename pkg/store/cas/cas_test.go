@@ -15,6 +15,7 @@ import (
 	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mock"
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/multiformats/go-multihash"
 	dctest "github.com/ory/dockertest/v3"
 	dc "github.com/ory/dockertest/v3/docker"
 	"github.com/stretchr/testify/require"
@@ -44,6 +45,15 @@ func TestNew(t *testing.T) {
 		require.EqualError(t, err, "failed to open store in underlying storage provider: open store error")
 		require.Nil(t, provider)
 	})
+	t.Run("Fail to set store configuration", func(t *testing.T) {
+		provider, err := localcas.New(&ariesmockstorage.Provider{
+			OpenStoreReturn:   &ariesmockstorage.Store{},
+			ErrSetStoreConfig: errors.New("set store config error"),
+		}, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+
+		require.EqualError(t, err, "failed to set store configuration: set store config error")
+		require.Nil(t, provider)
+	})
 }
 
 func TestProvider_Write_Read(t *testing.T) {
@@ -207,3 +217,118 @@ func startIPFSDockerContainer(t *testing.T) (*dctest.Pool, *dctest.Resource) {
 
 	return pool, ipfsResource
 }
+
+func TestCAS_WriteWithResourceHash(t *testing.T) {
+	content := []byte("content")
+
+	t.Run("Different hash algorithm", func(t *testing.T) {
+		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		rh, err := hashlink.New(hashlink.WithMultihashCode(multihash.SHA2_512)).CreateResourceHash(content)
+		require.NoError(t, err)
+
+		hl, err := provider.WriteWithResourceHash(content, rh)
+		require.NoError(t, err)
+
+		localRH, err := hashlink.GetResourceHashFromHashLink(hl)
+		require.NoError(t, err)
+		require.NotEqual(t, rh, localRH)
+
+		for _, address := range []string{rh, localRH} {
+			c, e := provider.Read(address)
+			require.NoError(t, e)
+			require.Equal(t, content, c)
+		}
+	})
+
+	t.Run("Same hash algorithm", func(t *testing.T) {
+		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		rh, err := hashlink.New().CreateResourceHash(content)
+		require.NoError(t, err)
+
+		hl, err := provider.WriteWithResourceHash(content, rh)
+		require.NoError(t, err)
+
+		localRH, err := hashlink.GetResourceHashFromHashLink(hl)
+		require.NoError(t, err)
+		require.Equal(t, rh, localRH)
+	})
+
+	t.Run("Hash mismatch", func(t *testing.T) {
+		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		rh, err := hashlink.New().CreateResourceHash([]byte("other content"))
+		require.NoError(t, err)
+
+		_, err = provider.WriteWithResourceHash(content, rh)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, hashlink.ErrHashMismatch))
+
+		_, err = provider.Read(rh)
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+	})
+
+	t.Run("Put error", func(t *testing.T) {
+		provider, err := localcas.New(&ariesmockstorage.Provider{
+			OpenStoreReturn: &ariesmockstorage.Store{ErrPut: errors.New("put error")},
+		}, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		rh, err := hashlink.New(hashlink.WithMultihashCode(multihash.SHA2_512)).CreateResourceHash(content)
+		require.NoError(t, err)
+
+		_, err = provider.WriteWithResourceHash(content, rh)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "put error")
+	})
+}
+
+func TestCAS_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		hl1, err := provider.Write([]byte("content1"))
+		require.NoError(t, err)
+
+		hl2, err := provider.Write([]byte("content2"))
+		require.NoError(t, err)
+
+		rh1, err := hashlink.GetResourceHashFromHashLink(hl1)
+		require.NoError(t, err)
+
+		rh2, err := hashlink.GetResourceHashFromHashLink(hl2)
+		require.NoError(t, err)
+
+		// Read the content so that it's cached.
+		_, err = provider.Read(rh1)
+		require.NoError(t, err)
+
+		require.NoError(t, provider.Delete(rh1))
+		require.NoError(t, provider.Delete())
+
+		// The deleted content must not be served from the cache.
+		_, err = provider.Read(rh1)
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+
+		content, err := provider.Read(rh2)
+		require.NoError(t, err)
+		require.Equal(t, []byte("content2"), content)
+	})
+
+	t.Run("Batch error", func(t *testing.T) {
+		provider, err := localcas.New(&ariesmockstorage.Provider{
+			OpenStoreReturn: &ariesmockstorage.Store{ErrBatch: errors.New("batch error")},
+		}, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		err = provider.Delete("rh")
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "batch error")
+	})
+}
@@ -17,7 +17,12 @@ import (
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 )
 
-const nameSpace = "didanchor"
+const (
+	nameSpace = "didanchor"
+
+	// anchorTagName is the tag that is added to each entry so that all of the latest anchors may be queried.
+	anchorTagName = "anchor"
+)
 
 var logger = log.New("didanchor-store")
 
@@ -28,6 +33,11 @@ func New(provider storage.Provider) (*Store, error) {
 		return nil, fmt.Errorf("failed to open did anchor store: %w", err)
 	}
 
+	err = provider.SetStoreConfig(nameSpace, storage.StoreConfiguration{TagNames: []string{anchorTagName}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration: %w", err)
+	}
+
 	return &Store{
 		store: store,
 	}, nil
@@ -46,6 +56,7 @@ func (s *Store) PutBulk(suffixes []string, cid string) error {
 		op := storage.Operation{
 			Key:   suffix,
 			Value: []byte(cid),
+			Tags:  []storage.Tag{{Name: anchorTagName}},
 		}
 
 		operations[i] = op
@@ -100,3 +111,92 @@ func (s *Store) Get(suffix string) (string, error) {
 
 	return anchor, nil
 }
+
+// GetAllAnchors returns the unique set of latest anchors for all suffixes. Entries that were written before
+// tagging was introduced are not included.
+func (s *Store) GetAllAnchors() ([]string, error) {
+	iter, err := s.store.Query(anchorTagName)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query anchors: %w", err))
+	}
+
+	defer func() {
+		if e := iter.Close(); e != nil {
+			logger.Warnf("Failed to close iterator: %s", e)
+		}
+	}()
+
+	var anchors []string
+
+	added := make(map[string]struct{})
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get next anchor: %w", err))
+	}
+
+	for ok {
+		value, e := iter.Value()
+		if e != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get anchor value: %w", e))
+		}
+
+		anchor := string(value)
+
+		if _, exists := added[anchor]; !exists {
+			added[anchor] = struct{}{}
+
+			anchors = append(anchors, anchor)
+		}
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get next anchor: %w", err))
+		}
+	}
+
+	logger.Debugf("retrieved %d latest anchors", len(anchors))
+
+	return anchors, nil
+}
+
+// TagAnchors adds the anchor tag to the entries of the given suffixes so that they are included in
+// GetAllAnchors. Entries that were written before tagging was introduced are not returned by GetAllAnchors
+// until they have been tagged. Suffixes that have no entry are ignored. The number of tagged entries is returned.
+func (s *Store) TagAnchors(suffixes []string) (int, error) {
+	if len(suffixes) == 0 {
+		return 0, nil
+	}
+
+	anchorBytes, err := s.store.GetBulk(suffixes...)
+	if err != nil {
+		return 0, orberrors.NewTransient(fmt.Errorf("failed to get did anchor references: %w", err))
+	}
+
+	var operations []storage.Operation
+
+	for i, a := range anchorBytes {
+		if a == nil {
+			continue
+		}
+
+		operations = append(operations, storage.Operation{
+			Key:   suffixes[i],
+			Value: a,
+			Tags:  []storage.Tag{{Name: anchorTagName}},
+		})
+	}
+
+	if len(operations) == 0 {
+		return 0, nil
+	}
+
+	err = s.store.Batch(operations)
+	if err != nil {
+		return 0, orberrors.NewTransient(fmt.Errorf("failed to tag did anchor references: %w", err))
+	}
+
+	logger.Debugf("tagged %d latest anchors", len(operations))
+
+	return len(operations), nil
+}
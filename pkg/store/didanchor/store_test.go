@@ -39,6 +39,17 @@ func TestNew(t *testing.T) {
 		require.Contains(t, err.Error(), "failed to open did anchor store: open store error")
 		require.Nil(t, s)
 	})
+
+	t.Run("error - set store config fails", func(t *testing.T) {
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(&mocks.Store{}, nil)
+		provider.SetStoreConfigReturns(fmt.Errorf("set config error"))
+
+		s, err := New(provider)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to set store configuration: set config error")
+		require.Nil(t, s)
+	})
 }
 
 func TestStore_PutAll(t *testing.T) {
@@ -159,3 +170,148 @@ func TestStore_Get(t *testing.T) {
 		require.Contains(t, err.Error(), "store error")
 	})
 }
+
+func TestStore_GetAllAnchors(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		anchors, err := s.GetAllAnchors()
+		require.NoError(t, err)
+		require.Empty(t, anchors)
+
+		require.NoError(t, s.PutBulk([]string{"suffix-1", "suffix-2"}, "cid-1"))
+		require.NoError(t, s.PutBulk([]string{"suffix-3"}, "cid-2"))
+		require.NoError(t, s.PutBulk([]string{"suffix-2"}, "cid-3"))
+
+		anchors, err = s.GetAllAnchors()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"cid-1", "cid-2", "cid-3"}, anchors)
+	})
+
+	t.Run("error - query error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.QueryReturns(nil, fmt.Errorf("query error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		anchors, err := s.GetAllAnchors()
+		require.Error(t, err)
+		require.Nil(t, anchors)
+		require.Contains(t, err.Error(), "query error")
+	})
+
+	t.Run("error - iterator next error", func(t *testing.T) {
+		iter := &mocks.Iterator{}
+		iter.NextReturns(false, fmt.Errorf("next error"))
+
+		store := &mocks.Store{}
+		store.QueryReturns(iter, nil)
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		anchors, err := s.GetAllAnchors()
+		require.Error(t, err)
+		require.Nil(t, anchors)
+		require.Contains(t, err.Error(), "next error")
+	})
+
+	t.Run("error - iterator value error", func(t *testing.T) {
+		iter := &mocks.Iterator{}
+		iter.NextReturns(true, nil)
+		iter.ValueReturns(nil, fmt.Errorf("value error"))
+
+		store := &mocks.Store{}
+		store.QueryReturns(iter, nil)
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		anchors, err := s.GetAllAnchors()
+		require.Error(t, err)
+		require.Nil(t, anchors)
+		require.Contains(t, err.Error(), "value error")
+	})
+}
+
+func TestStore_TagAnchors(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		n, err := s.TagAnchors(nil)
+		require.NoError(t, err)
+		require.Zero(t, n)
+
+		// Write legacy (untagged) entries.
+		store, err := provider.OpenStore(nameSpace)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("suffix-1", []byte("cid-1")))
+		require.NoError(t, store.Put("suffix-2", []byte("cid-2")))
+
+		anchors, err := s.GetAllAnchors()
+		require.NoError(t, err)
+		require.Empty(t, anchors)
+
+		n, err = s.TagAnchors([]string{"suffix-1", "suffix-2", "suffix-3"})
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+
+		anchors, err = s.GetAllAnchors()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"cid-1", "cid-2"}, anchors)
+
+		n, err = s.TagAnchors([]string{"suffix-3"})
+		require.NoError(t, err)
+		require.Zero(t, n)
+	})
+
+	t.Run("error - get bulk error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.GetBulkReturns(nil, fmt.Errorf("get bulk error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		n, err := s.TagAnchors([]string{"suffix-1"})
+		require.Error(t, err)
+		require.Zero(t, n)
+		require.Contains(t, err.Error(), "get bulk error")
+	})
+
+	t.Run("error - batch error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.GetBulkReturns([][]byte{[]byte("cid-1")}, nil)
+		store.BatchReturns(fmt.Errorf("batch error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		n, err := s.TagAnchors([]string{"suffix-1"})
+		require.Error(t, err)
+		require.Zero(t, n)
+		require.Contains(t, err.Error(), "batch error")
+	})
+}
@@ -135,3 +135,54 @@ func (s *Store) Get(suffix string) ([]*operation.AnchoredOperation, error) {
 
 	return ops, nil
 }
+
+// GetSuffixes returns the unique set of suffixes for which operations are stored.
+func (s *Store) GetSuffixes() ([]string, error) {
+	iter, err := s.store.Query(index)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query suffixes: %w", err))
+	}
+
+	defer func() {
+		if e := iter.Close(); e != nil {
+			logger.Warnf("Failed to close iterator: %s", e)
+		}
+	}()
+
+	var suffixes []string
+
+	added := make(map[string]struct{})
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get next suffix: %w", err))
+	}
+
+	for ok {
+		tags, e := iter.Tags()
+		if e != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator tags: %w", e))
+		}
+
+		for _, tag := range tags {
+			if tag.Name != index {
+				continue
+			}
+
+			if _, exists := added[tag.Value]; !exists {
+				added[tag.Value] = struct{}{}
+
+				suffixes = append(suffixes, tag.Value)
+			}
+		}
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get next suffix: %w", err))
+		}
+	}
+
+	logger.Debugf("retrieved %d suffixes", len(suffixes))
+
+	return suffixes, nil
+}
@@ -187,6 +187,86 @@ func TestStore_Get(t *testing.T) {
 	})
 }
 
+func TestStore_GetSuffixes(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		suffixes, err := s.GetSuffixes()
+		require.NoError(t, err)
+		require.Empty(t, suffixes)
+
+		require.NoError(t, s.Put([]*operation.AnchoredOperation{
+			{Type: operation.TypeCreate, UniqueSuffix: "suffix-1"},
+			{Type: operation.TypeCreate, UniqueSuffix: "suffix-2"},
+		}))
+		require.NoError(t, s.Put([]*operation.AnchoredOperation{
+			{Type: operation.TypeUpdate, UniqueSuffix: "suffix-1"},
+		}))
+
+		suffixes, err = s.GetSuffixes()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"suffix-1", "suffix-2"}, suffixes)
+	})
+
+	t.Run("error - query error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.QueryReturns(nil, fmt.Errorf("query error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		suffixes, err := s.GetSuffixes()
+		require.Error(t, err)
+		require.Nil(t, suffixes)
+		require.Contains(t, err.Error(), "query error")
+	})
+
+	t.Run("error - iterator next() error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturns(false, fmt.Errorf("iterator next() error"))
+
+		store := &mocks.Store{}
+		store.QueryReturns(iterator, nil)
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		suffixes, err := s.GetSuffixes()
+		require.Error(t, err)
+		require.Nil(t, suffixes)
+		require.Contains(t, err.Error(), "iterator next() error")
+	})
+
+	t.Run("error - iterator tags() error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturns(true, nil)
+		iterator.TagsReturns(nil, fmt.Errorf("iterator tags() error"))
+
+		store := &mocks.Store{}
+		store.QueryReturns(iterator, nil)
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		suffixes, err := s.GetSuffixes()
+		require.Error(t, err)
+		require.Nil(t, suffixes)
+		require.Contains(t, err.Error(), "iterator tags() error")
+	})
+}
+
 func getTestOperation() *operation.AnchoredOperation {
 	return &operation.AnchoredOperation{
 		Type:         operation.TypeCreate,
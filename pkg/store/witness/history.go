@@ -0,0 +1,261 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package witness
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	historyNamespace = "witness-history"
+	completedIndex   = "completed"
+
+	// DefaultHistorySize is the default number of completed anchor credentials kept in the history.
+	DefaultHistorySize = 1000
+
+	// maxPruneInterval is the maximum number of entries that are added to the history between prunes.
+	maxPruneInterval = 100
+)
+
+// CompletedAnchor contains the witness proofs of an anchor credential that completed.
+type CompletedAnchor struct {
+	VCID      string                `json:"vcID"`
+	Completed time.Time             `json:"completed"`
+	Witnesses []*proof.WitnessProof `json:"witnesses"`
+}
+
+// History stores the witness proofs of completed anchor credentials so that they may be replayed
+// (for example, when evaluating a candidate witness policy). Only the most recent anchor credentials are kept.
+// The history is pruned periodically (rather than on every Put) so it may temporarily hold more entries.
+type History struct {
+	store         storage.Store
+	maxItems      int
+	pruneInterval int
+	puts          int
+	mutex         sync.Mutex
+}
+
+type historyKey struct {
+	vcID      string
+	completed int64
+}
+
+// NewHistory creates a new completed anchor credential history which keeps at most maxItems entries.
+// If maxItems is not greater than zero then DefaultHistorySize is used.
+func NewHistory(provider storage.Provider, maxItems int) (*History, error) {
+	store, err := provider.OpenStore(historyNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open witness history store: %w", err)
+	}
+
+	err = provider.SetStoreConfig(historyNamespace, storage.StoreConfiguration{TagNames: []string{completedIndex}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration: %w", err)
+	}
+
+	if maxItems <= 0 {
+		maxItems = DefaultHistorySize
+	}
+
+	pruneInterval := maxPruneInterval
+	if maxItems < pruneInterval {
+		pruneInterval = maxItems
+	}
+
+	return &History{
+		store:         store,
+		maxItems:      maxItems,
+		pruneInterval: pruneInterval,
+	}, nil
+}
+
+// Put adds the witness proofs of a completed anchor credential to the history. The oldest entries are
+// removed from the history (every few puts) if the maximum size is exceeded.
+func (h *History) Put(vcID string, witnesses []*proof.WitnessProof, completed time.Time) error {
+	value, err := json.Marshal(&CompletedAnchor{
+		VCID:      vcID,
+		Completed: completed,
+		Witnesses: witnesses,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal completed anchor credential[%s]: %w", vcID, err)
+	}
+
+	err = h.store.Put(vcID, value, storage.Tag{
+		Name:  completedIndex,
+		Value: strconv.FormatInt(completed.UnixNano(), 10),
+	})
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to store completed anchor credential[%s]: %w", vcID, err))
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.puts++
+
+	if h.puts < h.pruneInterval {
+		return nil
+	}
+
+	h.puts = 0
+
+	return h.prune()
+}
+
+// GetRecent returns the most recently completed anchor credentials, newest first. At most maxItems entries
+// (or the maximum size of the history if maxItems is not greater than zero) are returned.
+func (h *History) GetRecent(maxItems int) ([]*CompletedAnchor, error) {
+	if maxItems <= 0 || maxItems > h.maxItems {
+		maxItems = h.maxItems
+	}
+
+	keys, err := h.getKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) > maxItems {
+		keys = keys[:maxItems]
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	vcIDs := make([]string, len(keys))
+
+	for i, key := range keys {
+		vcIDs[i] = key.vcID
+	}
+
+	values, err := h.store.GetBulk(vcIDs...)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get completed anchor credentials: %w", err))
+	}
+
+	var entries []*CompletedAnchor
+
+	for _, value := range values {
+		if value == nil {
+			// The entry was pruned after the keys were read.
+			continue
+		}
+
+		entry := &CompletedAnchor{}
+
+		err = json.Unmarshal(value, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal completed anchor credential: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	logger.Debugf("retrieved %d completed anchor credentials from witness history", len(entries))
+
+	return entries, nil
+}
+
+func (h *History) prune() error {
+	keys, err := h.getKeys()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) <= h.maxItems {
+		return nil
+	}
+
+	var operations []storage.Operation
+
+	for _, key := range keys[h.maxItems:] {
+		operations = append(operations, storage.Operation{Key: key.vcID})
+	}
+
+	err = h.store.Batch(operations)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to prune witness history: %w", err))
+	}
+
+	logger.Debugf("pruned %d completed anchor credentials from witness history", len(operations))
+
+	return nil
+}
+
+// getKeys returns the keys of all entries in the history, newest first. Only the keys and tags are read
+// so that the entries don't have to be unmarshalled.
+func (h *History) getKeys() ([]*historyKey, error) {
+	var err error
+
+	iter, err := h.store.Query(completedIndex)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query witness history: %w", err))
+	}
+
+	defer func() {
+		err = iter.Close()
+		if err != nil {
+			logger.Errorf("failed to close iterator: %s", err.Error())
+		}
+	}()
+
+	var keys []*historyKey
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+	}
+
+	for ok {
+		key := &historyKey{}
+
+		key.vcID, err = iter.Key()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator key: %w", err))
+		}
+
+		tags, e := iter.Tags()
+		if e != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator tags: %w", e))
+		}
+
+		for _, tag := range tags {
+			if tag.Name == completedIndex {
+				// An invalid completion time puts the entry at the end of the history.
+				key.completed, _ = strconv.ParseInt(tag.Value, 10, 64)
+			}
+		}
+
+		keys = append(keys, key)
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].completed == keys[j].completed {
+			return keys[i].vcID < keys[j].vcID
+		}
+
+		return keys[i].completed > keys[j].completed
+	})
+
+	return keys, nil
+}
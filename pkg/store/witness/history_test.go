@@ -0,0 +1,282 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package witness
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/store/mocks"
+)
+
+func TestNewHistory(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		h, err := NewHistory(mem.NewProvider(), 0)
+		require.NoError(t, err)
+		require.NotNil(t, h)
+		require.Equal(t, DefaultHistorySize, h.maxItems)
+	})
+
+	t.Run("error - open store fails", func(t *testing.T) {
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(nil, fmt.Errorf("open store error"))
+
+		h, err := NewHistory(provider, 10)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open witness history store: open store error")
+		require.Nil(t, h)
+	})
+
+	t.Run("error - set store config fails", func(t *testing.T) {
+		provider := &mocks.Provider{}
+		provider.SetStoreConfigReturns(fmt.Errorf("set store config error"))
+
+		h, err := NewHistory(provider, 10)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to set store configuration: set store config error")
+		require.Nil(t, h)
+	})
+}
+
+func TestHistory(t *testing.T) {
+	witnesses := []*proof.WitnessProof{
+		{Type: proof.WitnessTypeBatch, Witness: witness, Proof: []byte("proof")},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		h, err := NewHistory(mem.NewProvider(), 3)
+		require.NoError(t, err)
+
+		entries, err := h.GetRecent(0)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+
+		now := time.Now()
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, h.Put(fmt.Sprintf("vc-%d", i), witnesses, now.Add(time.Duration(i)*time.Second)))
+		}
+
+		// Only the three most recent entries are kept, newest first.
+		entries, err = h.GetRecent(0)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		require.Equal(t, "vc-4", entries[0].VCID)
+		require.Equal(t, "vc-3", entries[1].VCID)
+		require.Equal(t, "vc-2", entries[2].VCID)
+		require.Len(t, entries[0].Witnesses, 1)
+		require.Equal(t, witness, entries[0].Witnesses[0].Witness)
+
+		entries, err = h.GetRecent(2)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, "vc-4", entries[0].VCID)
+	})
+
+	t.Run("pruned periodically", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		h, err := NewHistory(provider, 200)
+		require.NoError(t, err)
+		require.Equal(t, maxPruneInterval, h.pruneInterval)
+
+		now := time.Now()
+
+		for i := 0; i < 250; i++ {
+			require.NoError(t, h.Put(fmt.Sprintf("vc-%d", i), witnesses, now.Add(time.Duration(i)*time.Second)))
+		}
+
+		// The history was last pruned after 200 puts so it holds more than the maximum number of entries.
+		keys, err := h.getKeys()
+		require.NoError(t, err)
+		require.Len(t, keys, 250)
+
+		// GetRecent never returns more than the maximum number of entries.
+		entries, err := h.GetRecent(0)
+		require.NoError(t, err)
+		require.Len(t, entries, 200)
+		require.Equal(t, "vc-249", entries[0].VCID)
+		require.Equal(t, "vc-50", entries[199].VCID)
+
+		for i := 250; i < 300; i++ {
+			require.NoError(t, h.Put(fmt.Sprintf("vc-%d", i), witnesses, now.Add(time.Duration(i)*time.Second)))
+		}
+
+		keys, err = h.getKeys()
+		require.NoError(t, err)
+		require.Len(t, keys, 200)
+		require.Equal(t, "vc-299", keys[0].vcID)
+	})
+
+	t.Run("error - put error", func(t *testing.T) {
+		s := &mocks.Store{}
+		s.PutReturns(fmt.Errorf("put error"))
+
+		p := &mocks.Provider{}
+		p.OpenStoreReturns(s, nil)
+
+		h, err := NewHistory(p, 10)
+		require.NoError(t, err)
+
+		err = h.Put(vcID, witnesses, time.Now())
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "put error")
+	})
+
+	t.Run("error - query error", func(t *testing.T) {
+		s := &mocks.Store{}
+		s.QueryReturns(nil, fmt.Errorf("query error"))
+
+		p := &mocks.Provider{}
+		p.OpenStoreReturns(s, nil)
+
+		h, err := NewHistory(p, 1)
+		require.NoError(t, err)
+
+		_, err = h.GetRecent(0)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "query error")
+
+		err = h.Put(vcID, witnesses, time.Now())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query error")
+	})
+
+	t.Run("error - iterator error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturns(false, fmt.Errorf("iterator next() error"))
+
+		s := &mocks.Store{}
+		s.QueryReturns(iterator, nil)
+
+		p := &mocks.Provider{}
+		p.OpenStoreReturns(s, nil)
+
+		h, err := NewHistory(p, 10)
+		require.NoError(t, err)
+
+		_, err = h.GetRecent(0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "iterator next() error")
+	})
+
+	t.Run("error - iterator key error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturns(true, nil)
+		iterator.KeyReturns("", fmt.Errorf("iterator key() error"))
+
+		s := &mocks.Store{}
+		s.QueryReturns(iterator, nil)
+
+		p := &mocks.Provider{}
+		p.OpenStoreReturns(s, nil)
+
+		h, err := NewHistory(p, 10)
+		require.NoError(t, err)
+
+		_, err = h.GetRecent(0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "iterator key() error")
+	})
+
+	t.Run("error - iterator tags error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturns(true, nil)
+		iterator.KeyReturns(vcID, nil)
+		iterator.TagsReturns(nil, fmt.Errorf("iterator tags() error"))
+
+		s := &mocks.Store{}
+		s.QueryReturns(iterator, nil)
+
+		p := &mocks.Provider{}
+		p.OpenStoreReturns(s, nil)
+
+		h, err := NewHistory(p, 10)
+		require.NoError(t, err)
+
+		_, err = h.GetRecent(0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "iterator tags() error")
+	})
+
+	t.Run("error - get bulk error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturnsOnCall(0, true, nil)
+		iterator.KeyReturns(vcID, nil)
+
+		s := &mocks.Store{}
+		s.QueryReturns(iterator, nil)
+		s.GetBulkReturns(nil, fmt.Errorf("get bulk error"))
+
+		p := &mocks.Provider{}
+		p.OpenStoreReturns(s, nil)
+
+		h, err := NewHistory(p, 10)
+		require.NoError(t, err)
+
+		_, err = h.GetRecent(0)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "get bulk error")
+	})
+
+	t.Run("error - unmarshal error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturnsOnCall(0, true, nil)
+		iterator.KeyReturns(vcID, nil)
+
+		s := &mocks.Store{}
+		s.QueryReturns(iterator, nil)
+		s.GetBulkReturns([][]byte{[]byte("{")}, nil)
+
+		p := &mocks.Provider{}
+		p.OpenStoreReturns(s, nil)
+
+		h, err := NewHistory(p, 10)
+		require.NoError(t, err)
+
+		_, err = h.GetRecent(0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal completed anchor credential")
+	})
+
+	t.Run("error - prune error", func(t *testing.T) {
+		s, err := mem.NewProvider().OpenStore(historyNamespace)
+		require.NoError(t, err)
+
+		errStore := &batchErrStore{Store: s}
+
+		p := &mocks.Provider{}
+		p.OpenStoreReturns(errStore, nil)
+
+		h, err := NewHistory(p, 1)
+		require.NoError(t, err)
+
+		require.NoError(t, h.Put("vc-1", witnesses, time.Now()))
+
+		err = h.Put("vc-2", witnesses, time.Now())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to prune witness history: batch error")
+	})
+}
+
+type batchErrStore struct {
+	storage.Store
+}
+
+func (s *batchErrStore) Batch([]storage.Operation) error {
+	return fmt.Errorf("batch error")
+}
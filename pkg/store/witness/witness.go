@@ -205,7 +205,7 @@ func (s *Store) Get(vcID string) ([]*proof.WitnessProof, error) {
 	logger.Debugf("retrieved %d witnesses for vcID[%s]", len(witnesses), vcID)
 
 	if len(witnesses) == 0 {
-		return nil, fmt.Errorf("vcID[%s] not found in the store", vcID)
+		return nil, fmt.Errorf("vcID[%s] not found in the store: %w", vcID, orberrors.ErrContentNotFound)
 	}
 
 	return witnesses, nil
@@ -291,3 +291,63 @@ func (s *Store) AddProof(vcID, witness string, p []byte) error { //nolint:funlen
 
 	return nil
 }
+
+// GetVCIDs returns the IDs of the anchor credentials which have witnesses in the store. If maxItems
+// is greater than zero then at most maxItems IDs are returned.
+func (s *Store) GetVCIDs(maxItems int) ([]string, error) {
+	var err error
+
+	iter, err := s.store.Query(vcIndex)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query witnesses: %w", err))
+	}
+
+	defer func() {
+		err = iter.Close()
+		if err != nil {
+			logger.Errorf("failed to close iterator: %s", err.Error())
+		}
+	}()
+
+	var vcIDs []string
+
+	exists := make(map[string]bool)
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+	}
+
+	for ok && (maxItems <= 0 || len(vcIDs) < maxItems) {
+		var tags []storage.Tag
+
+		tags, err = iter.Tags()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator tags: %w", err))
+		}
+
+		for _, tag := range tags {
+			if tag.Name != vcIndex || exists[tag.Value] {
+				continue
+			}
+
+			id, e := base64.RawURLEncoding.DecodeString(tag.Value)
+			if e != nil {
+				return nil, fmt.Errorf("failed to decode vcID[%s]: %w", tag.Value, e)
+			}
+
+			exists[tag.Value] = true
+
+			vcIDs = append(vcIDs, string(id))
+		}
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+		}
+	}
+
+	logger.Debugf("retrieved %d vcIDs from witness store", len(vcIDs))
+
+	return vcIDs, nil
+}
@@ -9,6 +9,7 @@ package witness
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/orb/pkg/anchor/proof"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/store/mocks"
 )
 
@@ -208,6 +210,7 @@ func TestStore_Delete(t *testing.T) {
 		require.Error(t, err)
 		require.Nil(t, ops)
 		require.Contains(t, err.Error(), "vcID[vcID] not found in the store")
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
 	})
 
 	t.Run("success - no witnesses found for VC ID", func(t *testing.T) {
@@ -527,6 +530,73 @@ func TestStore_AddProof(t *testing.T) {
 	})
 }
 
+func TestStore_GetVCIDs(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		err = s.Put("vc-1", []*proof.WitnessProof{getTestWitness(), getTestWitness()})
+		require.NoError(t, err)
+
+		err = s.Put("vc-2", []*proof.WitnessProof{getTestWitness()})
+		require.NoError(t, err)
+
+		vcIDs, err := s.GetVCIDs(0)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"vc-1", "vc-2"}, vcIDs)
+
+		vcIDs, err = s.GetVCIDs(1)
+		require.NoError(t, err)
+		require.Len(t, vcIDs, 1)
+	})
+
+	t.Run("success - no witnesses", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		vcIDs, err := s.GetVCIDs(0)
+		require.NoError(t, err)
+		require.Empty(t, vcIDs)
+	})
+
+	t.Run("error - query error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.QueryReturns(nil, fmt.Errorf("query error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		vcIDs, err := s.GetVCIDs(0)
+		require.Error(t, err)
+		require.Nil(t, vcIDs)
+		require.Contains(t, err.Error(), "query error")
+	})
+
+	t.Run("error - iterator error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturns(false, fmt.Errorf("iterator next() error"))
+
+		store := &mocks.Store{}
+		store.QueryReturns(iterator, nil)
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		vcIDs, err := s.GetVCIDs(0)
+		require.Error(t, err)
+		require.Nil(t, vcIDs)
+		require.Contains(t, err.Error(), "iterator next() error")
+	})
+}
+
 func getTestWitness() *proof.WitnessProof {
 	return &proof.WitnessProof{
 		Type:    proof.WitnessTypeBatch,
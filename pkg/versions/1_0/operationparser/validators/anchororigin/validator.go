@@ -6,16 +6,71 @@ SPDX-License-Identifier: Apache-2.0
 
 package anchororigin
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+)
+
+var logger = log.New("anchor-origin-validator")
+
+const (
+	wildcard       = "*"
+	domainWildcard = "*."
+	schemeSuffix   = "://"
+)
+
+type allowedOriginsProvider interface {
+	IsAllowed(origin string) (bool, error)
+}
+
+type metricsProvider interface {
+	AnchorOriginRejected(origin string)
+}
+
+// Option is an anchor origin validator option.
+type Option func(v *Validator)
+
+// WithAllowedOriginsProvider sets the provider that determines whether or not an anchor origin is allowed.
+// If set then the static list of allowed origins passed to New is ignored.
+func WithAllowedOriginsProvider(p allowedOriginsProvider) Option {
+	return func(v *Validator) {
+		if p != nil {
+			v.allowed = p
+		}
+	}
+}
+
+// WithMetrics sets the metrics provider which records the number of operations that were rejected (per origin)
+// because the anchor origin is not allowed.
+func WithMetrics(m metricsProvider) Option {
+	return func(v *Validator) {
+		if m != nil {
+			v.metrics = m
+		}
+	}
+}
 
 // New creates anchor origin validator.
-func New(allowed []string) *Validator {
-	return &Validator{allowed: sliceToMap(allowed)}
+func New(allowed []string, opts ...Option) *Validator {
+	v := &Validator{
+		allowed: StaticOrigins(allowed),
+		metrics: &noopMetrics{},
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
 }
 
 // Validator is anchor origin validator.
 type Validator struct {
-	allowed map[string]bool
+	allowed allowedOriginsProvider
+	metrics metricsProvider
 }
 
 // Validate validates anchor origin object.
@@ -24,12 +79,6 @@ func (v *Validator) Validate(obj interface{}) error {
 		return fmt.Errorf("anchor origin must be specified")
 	}
 
-	// if allowed origins contains wild-card '*' any origin is allowed
-	_, ok := v.allowed["*"]
-	if ok {
-		return nil
-	}
-
 	var val string
 
 	switch t := obj.(type) {
@@ -39,20 +88,102 @@ func (v *Validator) Validate(obj interface{}) error {
 		return fmt.Errorf("anchor origin type not supported %T", t)
 	}
 
-	_, ok = v.allowed[val]
-	if !ok {
+	allowed, err := v.allowed.IsAllowed(val)
+	if err != nil {
+		return fmt.Errorf("check allowed origin %s: %w", val, err)
+	}
+
+	if !allowed {
+		logger.Infof("Rejected operation with anchor origin [%s] which is not allowed", val)
+
+		v.metrics.AnchorOriginRejected(val)
+
 		return fmt.Errorf("origin %s is not supported", val)
 	}
 
 	return nil
 }
 
-func sliceToMap(ids []string) map[string]bool {
-	// convert slice to map
-	values := make(map[string]bool)
-	for _, id := range ids {
-		values[id] = true
+// StaticOrigins is a fixed list of allowed anchor origins.
+type StaticOrigins []string
+
+// IsAllowed returns true if the given origin matches one of the allowed origins.
+func (s StaticOrigins) IsAllowed(origin string) (bool, error) {
+	for _, pattern := range s {
+		if Matches(pattern, origin) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Matches returns true if the given anchor origin matches the given pattern. The pattern may be one of:
+// - '*': matches any origin
+// - an exact origin, e.g. https://orb.domain1.com or ipns://k51qzi5uqu5dl3ua2aal8vdw82j4i8s112p495j1spfkd2blqyg
+// - a domain wildcard, e.g. *.domain1.com, which matches any origin whose host is a sub-domain of domain1.com
+// - a domain wildcard with a scheme, e.g. https://*.domain1.com, which also requires the scheme to match.
+func Matches(pattern, origin string) bool {
+	if pattern == wildcard || pattern == origin {
+		return true
+	}
+
+	scheme, domainPattern := splitScheme(pattern)
+	if !strings.HasPrefix(domainPattern, domainWildcard) {
+		return false
+	}
+
+	originURL, err := url.Parse(origin)
+	if err != nil || originURL.Host == "" {
+		return false
+	}
+
+	if scheme != "" && !strings.EqualFold(scheme, originURL.Scheme) {
+		return false
+	}
+
+	return strings.HasSuffix(strings.ToLower(originURL.Hostname()),
+		strings.ToLower(strings.TrimPrefix(domainPattern, wildcard)))
+}
+
+// ValidatePattern returns an error if the given allowed origin pattern is invalid.
+func ValidatePattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("empty origin")
+	}
+
+	if pattern == wildcard {
+		return nil
+	}
+
+	_, domainPattern := splitScheme(pattern)
+
+	if strings.HasPrefix(domainPattern, domainWildcard) {
+		domainPattern = strings.TrimPrefix(domainPattern, domainWildcard)
+
+		if domainPattern == "" || strings.ContainsAny(domainPattern, "*/:") {
+			return fmt.Errorf("invalid domain wildcard in origin [%s]", pattern)
+		}
+
+		return nil
+	}
+
+	if strings.Contains(pattern, wildcard) {
+		return fmt.Errorf("wildcard is only supported as the first label of the domain in origin [%s]", pattern)
+	}
+
+	return nil
+}
+
+func splitScheme(pattern string) (string, string) {
+	i := strings.Index(pattern, schemeSuffix)
+	if i < 0 {
+		return "", pattern
 	}
 
-	return values
+	return pattern[:i], pattern[i+len(schemeSuffix):]
 }
+
+type noopMetrics struct{}
+
+func (m *noopMetrics) AnchorOriginRejected(string) {}
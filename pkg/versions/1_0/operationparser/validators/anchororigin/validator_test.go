@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package anchororigin
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -39,3 +40,76 @@ func TestValidator_Validate(t *testing.T) {
 		require.Contains(t, err.Error(), "origin not-allowed is not supported")
 	})
 }
+
+func TestValidator_AllowedOriginsProvider(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		m := &mockMetrics{}
+
+		validator := New(nil, WithAllowedOriginsProvider(StaticOrigins{"*.domain1.com"}), WithMetrics(m))
+		require.NoError(t, validator.Validate("https://orb.domain1.com"))
+
+		err := validator.Validate("https://orb.domain2.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "origin https://orb.domain2.com is not supported")
+		require.Equal(t, []string{"https://orb.domain2.com"}, m.rejected)
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		errExpected := errors.New("injected provider error")
+
+		validator := New(nil, WithAllowedOriginsProvider(&mockProvider{err: errExpected}))
+
+		err := validator.Validate("https://orb.domain1.com")
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("error - unsupported type", func(t *testing.T) {
+		err := New([]string{"*"}).Validate(100)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "anchor origin type not supported")
+	})
+}
+
+func TestMatches(t *testing.T) {
+	require.True(t, Matches("*", "https://orb.domain1.com"))
+	require.True(t, Matches("https://orb.domain1.com", "https://orb.domain1.com"))
+	require.True(t, Matches("ipns://k51qzi5uqu5dl3ua2aal8vdw82j4i8s112p495j1spfkd2blqyg",
+		"ipns://k51qzi5uqu5dl3ua2aal8vdw82j4i8s112p495j1spfkd2blqyg"))
+	require.True(t, Matches("*.domain1.com", "https://orb.domain1.com"))
+	require.True(t, Matches("*.domain1.com", "http://orb.sub.DOMAIN1.com:8080"))
+	require.True(t, Matches("https://*.domain1.com", "https://orb.domain1.com"))
+	require.False(t, Matches("https://*.domain1.com", "http://orb.domain1.com"))
+	require.False(t, Matches("*.domain1.com", "https://domain1.com"))
+	require.False(t, Matches("*.domain1.com", "https://orb.domain2.com"))
+	require.False(t, Matches("*.domain1.com", "orb.domain1.com"))
+	require.False(t, Matches("https://orb.domain1.com", "https://orb.domain2.com"))
+}
+
+func TestValidatePattern(t *testing.T) {
+	require.NoError(t, ValidatePattern("*"))
+	require.NoError(t, ValidatePattern("https://orb.domain1.com"))
+	require.NoError(t, ValidatePattern("*.domain1.com"))
+	require.NoError(t, ValidatePattern("https://*.domain1.com"))
+
+	require.EqualError(t, ValidatePattern(""), "empty origin")
+	require.Error(t, ValidatePattern("*."))
+	require.Error(t, ValidatePattern("*.domain1.com/path"))
+	require.Error(t, ValidatePattern("*.*.domain1.com"))
+	require.Error(t, ValidatePattern("https://orb.*.com"))
+}
+
+type mockMetrics struct {
+	rejected []string
+}
+
+func (m *mockMetrics) AnchorOriginRejected(origin string) {
+	m.rejected = append(m.rejected, origin)
+}
+
+type mockProvider struct {
+	err error
+}
+
+func (m *mockProvider) IsAllowed(string) (bool, error) {
+	return false, m.err
+}
@@ -0,0 +1,193 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webcas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+	casapi "github.com/trustbloc/sidetree-core-go/pkg/api/cas"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	// BatchPath is the path of the WebCAS batch endpoint.
+	BatchPath = "/cas/batch"
+
+	// MaxBatchSize is the maximum number of CIDs that may be requested in a single batch request.
+	MaxBatchSize = 100
+
+	// JSONLinesContentType is the content type of the batch response. Each line contains a BatchItem.
+	JSONLinesContentType = "application/x-ndjson"
+
+	// ContentNotFound is the error returned in a BatchItem if the content was not found.
+	ContentNotFound = "not found"
+
+	// ContentUnavailable is the error returned in a BatchItem if the content could not be read due to
+	// an internal error. The details of the error are only logged.
+	ContentUnavailable = "content unavailable"
+
+	// maxBatchRequestSize is the maximum size (in bytes) of a batch request body, which is plenty for
+	// MaxBatchSize CIDs.
+	maxBatchRequestSize = 64 * 1024
+)
+
+// BatchRequest is the request body of the WebCAS batch endpoint.
+type BatchRequest struct {
+	CIDs []string `json:"cids"`
+}
+
+// BatchItem is a single line of the batch response. Either Content or Error is set.
+type BatchItem struct {
+	CID     string `json:"cid"`
+	Content []byte `json:"content,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Batch implements the WebCAS batch endpoint, which returns the content of multiple CIDs in a single
+// JSON-lines response. The content is written as it is read so that the client may start processing
+// the response before all of the content has been read.
+type Batch struct {
+	*resthandler.AuthHandler
+
+	casClient casapi.Client
+	logger    logger
+}
+
+// NewBatch returns a new WebCAS batch handler.
+func NewBatch(authCfg *resthandler.Config, s spi.Store, verifier signatureVerifier, casClient casapi.Client) *Batch {
+	h := &Batch{
+		casClient: casClient,
+		logger:    log.New("webcas"),
+	}
+
+	// The batch endpoint only reads content so it's authorized in the same way as a GET request, even
+	// though the CIDs are posted.
+	h.AuthHandler = resthandler.NewAuthHandler(authCfg, BatchPath, http.MethodGet, s, verifier,
+		func(actorIRI *url.URL) (bool, error) {
+			// Let all actors through, as with the WebCAS GET endpoint.
+			h.logger.Debugf("[%s] Authorized actor [%s]", BatchPath, actorIRI)
+
+			return true, nil
+		})
+
+	return h
+}
+
+// Path returns the HTTP REST endpoint for the WebCAS batch service.
+func (b *Batch) Path() string {
+	return BatchPath
+}
+
+// Method returns the HTTP REST method for the WebCAS batch service.
+func (b *Batch) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handler for the WebCAS batch service.
+func (b *Batch) Handler() common.HTTPRequestHandler {
+	return b.handler
+}
+
+func (b *Batch) handler(rw http.ResponseWriter, req *http.Request) {
+	if !authorize(b.AuthHandler, b.logger, rw, req) {
+		return
+	}
+
+	cids, err := parseBatchRequest(rw, req)
+	if err != nil {
+		b.logger.Infof("[%s] Invalid batch request: %s", BatchPath, err)
+
+		rw.WriteHeader(http.StatusBadRequest)
+
+		if _, errWrite := rw.Write([]byte(fmt.Sprintf("Bad Request. %s\n", err))); errWrite != nil {
+			b.logger.Errorf("Unable to write response: %s", errWrite)
+		}
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", JSONLinesContentType)
+
+	rw.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(rw)
+
+	for _, cid := range cids {
+		if e := encoder.Encode(b.read(cid)); e != nil {
+			b.logger.Errorf("failed to write batch response: %s", e)
+
+			return
+		}
+	}
+}
+
+func (b *Batch) read(cid string) *BatchItem {
+	content, err := b.casClient.Read(cid)
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			return &BatchItem{CID: cid, Error: ContentNotFound}
+		}
+
+		b.logger.Errorf("[%s] Failure while finding content at %s: %s", BatchPath, cid, err)
+
+		return &BatchItem{CID: cid, Error: ContentUnavailable}
+	}
+
+	return &BatchItem{CID: cid, Content: content}
+}
+
+// parseBatchRequest returns the unique CIDs in the request. An error is returned if the request body
+// is larger than maxBatchRequestSize.
+func parseBatchRequest(rw http.ResponseWriter, req *http.Request) ([]string, error) {
+	reqBytes, err := ioutil.ReadAll(http.MaxBytesReader(rw, req.Body, maxBatchRequestSize))
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+
+	request := &BatchRequest{}
+
+	if e := json.Unmarshal(reqBytes, request); e != nil {
+		return nil, fmt.Errorf("unmarshal request: %w", e)
+	}
+
+	if len(request.CIDs) == 0 {
+		return nil, errors.New("no CIDs were specified")
+	}
+
+	var cids []string
+
+	unique := make(map[string]struct{})
+
+	for _, cid := range request.CIDs {
+		if cid == "" {
+			return nil, errors.New("empty CID")
+		}
+
+		if _, ok := unique[cid]; ok {
+			continue
+		}
+
+		unique[cid] = struct{}{}
+		cids = append(cids, cid)
+	}
+
+	if len(cids) > MaxBatchSize {
+		return nil, fmt.Errorf("the number of CIDs exceeds the maximum of %d", MaxBatchSize)
+	}
+
+	return cids, nil
+}
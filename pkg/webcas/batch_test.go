@@ -0,0 +1,272 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webcas_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/store/cas"
+	"github.com/trustbloc/orb/pkg/webcas"
+)
+
+const unknownCID = "QmeKWPxUJP9M3WJgBuj8ykLtGU37iqur5gZ8cDCi49WJVG"
+
+func TestNewBatch(t *testing.T) {
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	b := webcas.NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
+	require.NotNil(t, b)
+	require.Equal(t, "/cas/batch", b.Path())
+	require.Equal(t, http.MethodPost, b.Method())
+	require.NotNil(t, b.Handler())
+}
+
+func TestBatch_Handler(t *testing.T) {
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	content1 := []byte(sampleAnchorCredential)
+	content2 := []byte("some content")
+
+	cid1 := writeContent(t, casClient, content1)
+	cid2 := writeContent(t, casClient, content2)
+
+	b := webcas.NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
+
+	testServer := newBatchServer(b)
+	defer testServer.Close()
+
+	t.Run("Success", func(t *testing.T) {
+		status, header, body := postBatch(t, testServer.URL, &webcas.BatchRequest{
+			CIDs: []string{cid1, unknownCID, cid2, cid1},
+		})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, webcas.JSONLinesContentType, header.Get("Content-Type"))
+
+		items := parseBatchResponse(t, body)
+		require.Len(t, items, 3)
+
+		require.Equal(t, cid1, items[0].CID)
+		require.Equal(t, content1, items[0].Content)
+		require.Empty(t, items[0].Error)
+
+		require.Equal(t, unknownCID, items[1].CID)
+		require.Empty(t, items[1].Content)
+		require.Equal(t, webcas.ContentNotFound, items[1].Error)
+
+		require.Equal(t, cid2, items[2].CID)
+		require.Equal(t, content2, items[2].Content)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		resp, err := http.DefaultClient.Post(testServer.URL+webcas.BatchPath, "application/json",
+			bytes.NewReader([]byte("{")))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("Request too large", func(t *testing.T) {
+		reqBytes, err := json.Marshal(&webcas.BatchRequest{CIDs: []string{strings.Repeat("x", 65*1024)}})
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Post(testServer.URL+webcas.BatchPath, "application/json",
+			bytes.NewReader(reqBytes))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(body), "too large")
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("No CIDs", func(t *testing.T) {
+		status, _, body := postBatch(t, testServer.URL, &webcas.BatchRequest{})
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, string(body), "no CIDs were specified")
+	})
+
+	t.Run("Empty CID", func(t *testing.T) {
+		status, _, body := postBatch(t, testServer.URL, &webcas.BatchRequest{CIDs: []string{cid1, ""}})
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, string(body), "empty CID")
+	})
+
+	t.Run("Too many CIDs", func(t *testing.T) {
+		cids := make([]string, webcas.MaxBatchSize+1)
+
+		for i := range cids {
+			cids[i] = fmt.Sprintf("cid%d", i)
+		}
+
+		status, _, body := postBatch(t, testServer.URL, &webcas.BatchRequest{CIDs: cids})
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, string(body), "exceeds the maximum")
+	})
+
+	t.Run("CAS error", func(t *testing.T) {
+		errExpected := errors.New("injected get error")
+
+		casClient, err := cas.New(&mock.Provider{OpenStoreReturn: &mock.Store{ErrGet: errExpected}},
+			casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		testServer := newBatchServer(
+			webcas.NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient),
+		)
+		defer testServer.Close()
+
+		status, _, body := postBatch(t, testServer.URL, &webcas.BatchRequest{CIDs: []string{cid1}})
+		require.Equal(t, http.StatusOK, status)
+
+		items := parseBatchResponse(t, body)
+		require.Len(t, items, 1)
+		require.Equal(t, webcas.ContentUnavailable, items[0].Error)
+		require.NotContains(t, string(body), errExpected.Error())
+	})
+}
+
+func TestBatch_Authorization(t *testing.T) {
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	cfg := &resthandler.Config{
+		Config: auth.Config{
+			AuthTokensDef: []*auth.TokenDef{
+				{
+					EndpointExpression: "/cas",
+					ReadTokens:         []string{"read"},
+					WriteTokens:        []string{"admin"},
+				},
+			},
+			AuthTokens: map[string]string{
+				"read":  "READ_TOKEN",
+				"admin": "ADMIN_TOKEN",
+			},
+		},
+	}
+
+	request := &webcas.BatchRequest{CIDs: []string{unknownCID}}
+
+	t.Run("Read token -> authorized", func(t *testing.T) {
+		testServer := newBatchServer(webcas.NewBatch(cfg, memstore.New(""), &mocks.SignatureVerifier{}, casClient))
+		defer testServer.Close()
+
+		reqBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, testServer.URL+webcas.BatchPath, bytes.NewReader(reqBytes))
+		require.NoError(t, err)
+
+		req.Header.Set("Authorization", "Bearer READ_TOKEN")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		testServer := newBatchServer(webcas.NewBatch(cfg, memstore.New(""), &mocks.SignatureVerifier{}, casClient))
+		defer testServer.Close()
+
+		status, _, _ := postBatch(t, testServer.URL, request)
+		require.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("Authorization error", func(t *testing.T) {
+		sigVerifier := &mocks.SignatureVerifier{}
+		sigVerifier.VerifyRequestReturns(false, nil, errors.New("injected authorization error"))
+
+		testServer := newBatchServer(webcas.NewBatch(cfg, memstore.New(""), sigVerifier, casClient))
+		defer testServer.Close()
+
+		status, _, _ := postBatch(t, testServer.URL, request)
+		require.Equal(t, http.StatusInternalServerError, status)
+	})
+}
+
+func newBatchServer(b *webcas.Batch) *httptest.Server {
+	router := mux.NewRouter()
+
+	router.HandleFunc(b.Path(), b.Handler()).Methods(b.Method())
+
+	return httptest.NewServer(router)
+}
+
+func writeContent(t *testing.T, casClient *cas.CAS, content []byte) string {
+	t.Helper()
+
+	hl, err := casClient.Write(content)
+	require.NoError(t, err)
+
+	cid, err := hashlink.GetResourceHashFromHashLink(hl)
+	require.NoError(t, err)
+
+	return cid
+}
+
+func postBatch(t *testing.T, serverURL string, request *webcas.BatchRequest) (int, http.Header, []byte) {
+	t.Helper()
+
+	reqBytes, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Post(serverURL+webcas.BatchPath, "application/json", bytes.NewReader(reqBytes))
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, resp.Body.Close())
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, resp.Header, body
+}
+
+func parseBatchResponse(t *testing.T, body []byte) []*webcas.BatchItem {
+	t.Helper()
+
+	var items []*webcas.BatchItem
+
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	scanner.Buffer(nil, len(body)+1)
+
+	for scanner.Scan() {
+		item := &webcas.BatchItem{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), item))
+
+		items = append(items, item)
+	}
+
+	require.NoError(t, scanner.Err())
+
+	return items
+}
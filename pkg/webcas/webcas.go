@@ -7,10 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package webcas
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/trustbloc/edge-core/pkg/log"
@@ -22,7 +26,17 @@ import (
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 )
 
-const cidPathVariable = "cid"
+const (
+	cidPathVariable = "cid"
+
+	// CAS content is immutable so it may be cached indefinitely (a year being the maximum recommended age).
+	publicCacheControl = "public, max-age=31536000, immutable"
+
+	// If the endpoint requires authorization then shared caches must not store the content.
+	privateCacheControl = "private, max-age=31536000, immutable"
+
+	jsonContentType = "application/json"
+)
 
 type logger interface {
 	Errorf(msg string, args ...interface{})
@@ -38,8 +52,10 @@ type signatureVerifier interface {
 type WebCAS struct {
 	*resthandler.AuthHandler
 
-	casClient casapi.Client
-	logger    logger
+	casClient    casapi.Client
+	logger       logger
+	method       string
+	cacheControl string
 }
 
 // Path returns the HTTP REST endpoint for the WebCAS service.
@@ -49,7 +65,7 @@ func (w *WebCAS) Path() string {
 
 // Method returns the HTTP REST method for the WebCAS service.
 func (w *WebCAS) Method() string {
-	return http.MethodGet
+	return w.method
 }
 
 // Handler returns the HTTP REST handler for the WebCAS service.
@@ -59,13 +75,28 @@ func (w *WebCAS) Handler() common.HTTPRequestHandler {
 
 // New returns a new WebCAS, which contains a REST handler that implements WebCAS as defined in
 // https://trustbloc.github.io/did-method-orb/#webcas.
+// The content is served with a strong ETag (derived from the resource hash) and immutable caching headers. The
+// content is marked as private (i.e. it may not be stored by shared caches) if the endpoint requires authorization.
+// Conditional (If-None-Match) and byte-range requests are supported.
 func New(authCfg *resthandler.Config, s spi.Store, verifier signatureVerifier, casClient casapi.Client) *WebCAS {
+	return newWebCAS(http.MethodGet, authCfg, s, verifier, casClient)
+}
+
+// NewHead returns a new WebCAS handler for HEAD requests, which may be used to check whether or not
+// content exists without retrieving it.
+func NewHead(authCfg *resthandler.Config, s spi.Store, verifier signatureVerifier, casClient casapi.Client) *WebCAS {
+	return newWebCAS(http.MethodHead, authCfg, s, verifier, casClient)
+}
+
+func newWebCAS(method string, authCfg *resthandler.Config, s spi.Store, verifier signatureVerifier,
+	casClient casapi.Client) *WebCAS {
 	h := &WebCAS{
 		casClient: casClient,
 		logger:    log.New("webcas"),
+		method:    method,
 	}
 
-	h.AuthHandler = resthandler.NewAuthHandler(authCfg, "/cas/{%s}", http.MethodGet, s, verifier,
+	h.AuthHandler = resthandler.NewAuthHandler(authCfg, "/cas/{%s}", method, s, verifier,
 		func(actorIRI *url.URL) (bool, error) {
 			// TODO: Does the actor need to be authorized? If so, how? A witness needs access to the /cas endpoint
 			// but does not need to be part of an actor's 'followers' or 'witnessing' collections (e.g. the case where
@@ -77,38 +108,22 @@ func New(authCfg *resthandler.Config, s spi.Store, verifier signatureVerifier, c
 			return true, nil
 		})
 
-	return h
-}
-
-func (w *WebCAS) handler(rw http.ResponseWriter, req *http.Request) {
-	ok, _, err := w.Authorize(req)
-	if err != nil {
-		w.logger.Errorf("Error authorizing request from %s: %s", req.URL, err)
-
-		rw.WriteHeader(http.StatusInternalServerError)
-
-		if _, errWrite := rw.Write([]byte("Internal Server Error.\n")); errWrite != nil {
-			w.logger.Errorf("Unable to write response: %s", errWrite)
-		}
+	h.cacheControl = publicCacheControl
 
-		return
+	if h.AuthRequired() {
+		h.cacheControl = privateCacheControl
 	}
 
-	if !ok {
-		w.logger.Infof("Request from %s is unauthorized", req.URL)
-
-		rw.WriteHeader(http.StatusUnauthorized)
-
-		if _, errWrite := rw.Write([]byte("Unauthorized.\n")); errWrite != nil {
-			w.logger.Errorf("Unable to write response: %s", errWrite)
-		}
+	return h
+}
 
+func (w *WebCAS) handler(rw http.ResponseWriter, req *http.Request) {
+	if !authorize(w.AuthHandler, w.logger, rw, req) {
 		return
 	}
 
-	w.logger.Debugf("Request from %s is authorized", req.URL)
-
 	cid := mux.Vars(req)[cidPathVariable]
+	etag := fmt.Sprintf("%q", cid)
 
 	content, err := w.casClient.Read(cid)
 	if err != nil {
@@ -135,8 +150,103 @@ func (w *WebCAS) handler(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	_, err = rw.Write(content)
+	w.setCacheHeaders(rw, etag)
+
+	// The content must exist (checked above) before responding that it wasn't modified, otherwise
+	// a client could be told that it has valid content which this server doesn't have.
+	if etagMatches(req.Header.Get("If-None-Match"), etag) {
+		rw.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", contentType(content))
+
+	writer := &responseWriter{ResponseWriter: rw}
+
+	// ServeContent handles HEAD, Range and If-Range requests.
+	http.ServeContent(writer, req, "", time.Time{}, bytes.NewReader(content))
+
+	if writer.err != nil {
+		w.logger.Errorf("failed to write success response: %s", writer.err.Error())
+	}
+}
+
+// authorize authorizes the request and, if the request isn't authorized, writes the error response.
+func authorize(h *resthandler.AuthHandler, l logger, rw http.ResponseWriter, req *http.Request) bool {
+	ok, _, err := h.Authorize(req)
 	if err != nil {
-		w.logger.Errorf("failed to write success response: %s", err.Error())
+		l.Errorf("Error authorizing request from %s: %s", req.URL, err)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+
+		if _, errWrite := rw.Write([]byte("Internal Server Error.\n")); errWrite != nil {
+			l.Errorf("Unable to write response: %s", errWrite)
+		}
+
+		return false
+	}
+
+	if !ok {
+		l.Infof("Request from %s is unauthorized", req.URL)
+
+		rw.WriteHeader(http.StatusUnauthorized)
+
+		if _, errWrite := rw.Write([]byte("Unauthorized.\n")); errWrite != nil {
+			l.Errorf("Unable to write response: %s", errWrite)
+		}
+
+		return false
 	}
+
+	l.Debugf("Request from %s is authorized", req.URL)
+
+	return true
+}
+
+// responseWriter records the first write error since http.ServeContent doesn't return it.
+type responseWriter struct {
+	http.ResponseWriter
+
+	err error
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+
+	return n, err
+}
+
+func (w *WebCAS) setCacheHeaders(rw http.ResponseWriter, etag string) {
+	rw.Header().Set("ETag", etag)
+	rw.Header().Set("Cache-Control", w.cacheControl)
+}
+
+func contentType(content []byte) string {
+	if json.Valid(content) {
+		return jsonContentType
+	}
+
+	return http.DetectContentType(content)
+}
+
+// etagMatches returns true if the given If-None-Match header value matches the ETag. Weak comparison is
+// used, as specified in RFC 7232.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, value := range strings.Split(ifNoneMatch, ",") {
+		value = strings.TrimSpace(value)
+
+		if value == "*" || strings.TrimPrefix(value, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
 }
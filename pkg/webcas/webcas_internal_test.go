@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
@@ -27,10 +28,16 @@ import (
 
 const casLink = "https://domain.com/cas"
 
-type failingResponseWriter struct{}
+type failingResponseWriter struct {
+	header http.Header
+}
 
 func (f *failingResponseWriter) Header() http.Header {
-	return nil
+	if f.header == nil {
+		f.header = make(http.Header)
+	}
+
+	return f.header
 }
 
 func (f *failingResponseWriter) Write([]byte) (int, error) {
@@ -98,8 +105,8 @@ func TestWriteResponseFailures(t *testing.T) {
 		})
 	})
 	t.Run("Fail to write success response", func(t *testing.T) {
-		casClient, err := cas.New(&mock.Provider{OpenStoreReturn: &mock.Store{}}, casLink, nil,
-			&orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(&mock.Provider{OpenStoreReturn: &mock.Store{GetReturn: []byte("content")}},
+			casLink, nil, &orbmocks.MetricsProvider{}, 0)
 		require.NoError(t, err)
 
 		testLogger := &stringLogger{}
@@ -114,4 +121,54 @@ func TestWriteResponseFailures(t *testing.T) {
 
 		require.Equal(t, "failed to write success response: response write failure", testLogger.log)
 	})
+	t.Run("Fail to write batch response", func(t *testing.T) {
+		casClient, err := cas.New(&mock.Provider{OpenStoreReturn: &mock.Store{GetReturn: []byte("content")}},
+			casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		testLogger := &stringLogger{}
+
+		b := NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
+		b.logger = testLogger
+
+		rw := &failingResponseWriter{}
+		req := httptest.NewRequest(http.MethodPost, BatchPath, strings.NewReader(`{"cids":["cid1","cid2"]}`))
+
+		b.Handler()(rw, req)
+
+		require.Equal(t, "failed to write batch response: response write failure", testLogger.log)
+	})
+	t.Run("Fail to write batch error response", func(t *testing.T) {
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		testLogger := &stringLogger{}
+
+		b := NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
+		b.logger = testLogger
+
+		rw := &failingResponseWriter{}
+		req := httptest.NewRequest(http.MethodPost, BatchPath, strings.NewReader(`{}`))
+
+		b.Handler()(rw, req)
+
+		require.Equal(t, "Unable to write response: response write failure", testLogger.log)
+	})
+}
+
+func TestEtagMatches(t *testing.T) {
+	const etag = `"uEiAbc"`
+
+	require.False(t, etagMatches("", etag))
+	require.False(t, etagMatches(`"uEiXyz"`, etag))
+	require.True(t, etagMatches(`"uEiAbc"`, etag))
+	require.True(t, etagMatches(`W/"uEiAbc"`, etag))
+	require.True(t, etagMatches(`"uEiXyz", "uEiAbc"`, etag))
+	require.True(t, etagMatches("*", etag))
+}
+
+func TestContentType(t *testing.T) {
+	require.Equal(t, "application/json", contentType([]byte(`{"field":"value"}`)))
+	require.Equal(t, "application/x-gzip", contentType([]byte{0x1f, 0x8b, 0x08, 0x00}))
+	require.Equal(t, "text/plain; charset=utf-8", contentType([]byte("some text")))
 }
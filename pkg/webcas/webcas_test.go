@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/gorilla/mux"
@@ -89,6 +90,12 @@ func TestNew(t *testing.T) {
 	require.Equal(t, "/cas/{cid}", webCAS.Path())
 	require.Equal(t, http.MethodGet, webCAS.Method())
 	require.NotNil(t, webCAS.Handler())
+
+	headCAS := webcas.NewHead(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
+	require.NotNil(t, headCAS)
+	require.Equal(t, "/cas/{cid}", headCAS.Path())
+	require.Equal(t, http.MethodHead, headCAS.Method())
+	require.NotNil(t, headCAS.Handler())
 }
 
 func TestHandler(t *testing.T) {
@@ -236,3 +243,140 @@ func TestHandler(t *testing.T) {
 		})
 	})
 }
+
+func TestHandler_Caching(t *testing.T) {
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	hl, err := casClient.Write([]byte(sampleAnchorCredential))
+	require.NoError(t, err)
+
+	rh, err := hashlink.GetResourceHashFromHashLink(hl)
+	require.NoError(t, err)
+
+	webCAS := webcas.New(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
+	headCAS := webcas.NewHead(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
+
+	router := mux.NewRouter()
+
+	router.HandleFunc(webCAS.Path(), webCAS.Handler()).Methods(webCAS.Method())
+	router.HandleFunc(headCAS.Path(), headCAS.Handler()).Methods(headCAS.Method())
+
+	testServer := httptest.NewServer(router)
+	defer testServer.Close()
+
+	contentURL := testServer.URL + "/cas/" + rh
+	etag := `"` + rh + `"`
+
+	t.Run("Caching headers", func(t *testing.T) {
+		status, header, body := doRequest(t, http.MethodGet, contentURL, nil)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, sampleAnchorCredential, string(body))
+		require.Equal(t, etag, header.Get("ETag"))
+		require.Equal(t, "public, max-age=31536000, immutable", header.Get("Cache-Control"))
+		require.Equal(t, "application/json", header.Get("Content-Type"))
+		require.Equal(t, "bytes", header.Get("Accept-Ranges"))
+	})
+
+	t.Run("Conditional GET", func(t *testing.T) {
+		status, header, body := doRequest(t, http.MethodGet, contentURL, map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusNotModified, status)
+		require.Empty(t, body)
+		require.Equal(t, etag, header.Get("ETag"))
+
+		status, _, body = doRequest(t, http.MethodGet, contentURL, map[string]string{"If-None-Match": `"other"`})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, sampleAnchorCredential, string(body))
+
+		// Not modified is only returned if the content exists.
+		status, _, _ = doRequest(t, http.MethodGet, testServer.URL+"/cas/uEiDnotfound",
+			map[string]string{"If-None-Match": `"uEiDnotfound"`})
+		require.Equal(t, http.StatusNotFound, status)
+
+		status, _, _ = doRequest(t, http.MethodGet, testServer.URL+"/cas/uEiDnotfound",
+			map[string]string{"If-None-Match": "*"})
+		require.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		status, header, body := doRequest(t, http.MethodGet, contentURL, map[string]string{"Range": "bytes=10-19"})
+		require.Equal(t, http.StatusPartialContent, status)
+		require.Equal(t, sampleAnchorCredential[10:20], string(body))
+		require.Equal(t, etag, header.Get("ETag"))
+
+		status, _, body = doRequest(t, http.MethodGet, contentURL,
+			map[string]string{"Range": "bytes=10-", "If-Range": `"other"`})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, sampleAnchorCredential, string(body))
+
+		status, _, _ = doRequest(t, http.MethodGet, contentURL,
+			map[string]string{"Range": "bytes=100000-"})
+		require.Equal(t, http.StatusRequestedRangeNotSatisfiable, status)
+	})
+
+	t.Run("HEAD", func(t *testing.T) {
+		status, header, body := doRequest(t, http.MethodHead, contentURL, nil)
+		require.Equal(t, http.StatusOK, status)
+		require.Empty(t, body)
+		require.Equal(t, etag, header.Get("ETag"))
+		require.Equal(t, strconv.Itoa(len(sampleAnchorCredential)), header.Get("Content-Length"))
+
+		status, _, _ = doRequest(t, http.MethodHead, testServer.URL+"/cas/uEiDnotfound", nil)
+		require.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("Authorization required -> private", func(t *testing.T) {
+		cfg := &resthandler.Config{
+			Config: auth.Config{
+				AuthTokensDef: []*auth.TokenDef{
+					{
+						EndpointExpression: "/cas",
+						ReadTokens:         []string{"read"},
+					},
+				},
+				AuthTokens: map[string]string{
+					"read": "READ_TOKEN",
+				},
+			},
+		}
+
+		webCAS := webcas.New(cfg, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
+
+		router := mux.NewRouter()
+
+		router.HandleFunc(webCAS.Path(), webCAS.Handler()).Methods(webCAS.Method())
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		status, header, body := doRequest(t, http.MethodGet, testServer.URL+"/cas/"+rh,
+			map[string]string{"Authorization": "Bearer READ_TOKEN"})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, sampleAnchorCredential, string(body))
+		require.Equal(t, etag, header.Get("ETag"))
+		require.Equal(t, "private, max-age=31536000, immutable", header.Get("Cache-Control"))
+	})
+}
+
+func doRequest(t *testing.T, method, url string, headers map[string]string) (int, http.Header, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	response, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, response.Body.Close())
+	}()
+
+	body, err := ioutil.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	return response.StatusCode, response.Header, body
+}
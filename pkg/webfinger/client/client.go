@@ -43,6 +43,7 @@ type Client struct {
 	cacheSize     int
 
 	ledgerTypeCache gcache.Cache
+	hostMetaCache   gcache.Cache
 }
 
 // New creates new webfinger client.
@@ -63,6 +64,12 @@ func New(opts ...Option) *Client {
 			return client.getLedgerType(key.(string))
 		}).Build()
 
+	client.hostMetaCache = gcache.New(client.cacheSize).
+		Expiration(client.cacheLifetime).
+		LoaderFunc(func(key interface{}) (interface{}, error) {
+			return client.ResolveHostMeta(key.(string))
+		}).Build()
+
 	return client
 }
 
@@ -115,23 +122,30 @@ func (c *Client) HasSupportedLedgerType(domain string) (bool, error) {
 
 // ResolveWebFingerResource attempts to resolve the given WebFinger resource from domainWithScheme.
 func (c *Client) ResolveWebFingerResource(domainWithScheme, resource string) (restapi.JRD, error) {
-	webFingerURL := fmt.Sprintf("%s/.well-known/webfinger?resource=%s", domainWithScheme, resource)
+	return c.resolveJRD("WebFinger", fmt.Sprintf("%s/.well-known/webfinger?resource=%s", domainWithScheme, resource))
+}
 
-	req, err := http.NewRequest(http.MethodGet, webFingerURL, nil)
+// ResolveHostMeta returns the host-meta document of domainWithScheme.
+func (c *Client) ResolveHostMeta(domainWithScheme string) (restapi.JRD, error) {
+	return c.resolveJRD("host-meta", fmt.Sprintf("%s%s", domainWithScheme, restapi.HostMetaJSONEndpoint))
+}
+
+func (c *Client) resolveJRD(docType, docURL string) (restapi.JRD, error) {
+	req, err := http.NewRequest(http.MethodGet, docURL, nil)
 	if err != nil {
 		return restapi.JRD{},
-			fmt.Errorf("failed to create new request for WebFinger URL [%s]: %w", webFingerURL, err)
+			fmt.Errorf("failed to create new request for %s URL [%s]: %w", docType, docURL, err)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return restapi.JRD{}, fmt.Errorf("failed to get response (URL: %s): %w", webFingerURL, err)
+		return restapi.JRD{}, fmt.Errorf("failed to get response (URL: %s): %w", docURL, err)
 	}
 
 	defer func() {
 		err = resp.Body.Close()
 		if err != nil {
-			logger.Errorf("failed to close response body after getting WebFinger response: %s", err.Error())
+			logger.Errorf("failed to close response body after getting %s response: %s", docType, err.Error())
 		}
 	}()
 
@@ -144,17 +158,17 @@ func (c *Client) ResolveWebFingerResource(domainWithScheme, resource string) (re
 		return restapi.JRD{}, model.ErrResourceNotFound
 	} else if resp.StatusCode != http.StatusOK {
 		return restapi.JRD{}, fmt.Errorf("received unexpected status code. URL [%s], "+
-			"status code [%d], response body [%s]", webFingerURL, resp.StatusCode, string(respBytes))
+			"status code [%d], response body [%s]", docURL, resp.StatusCode, string(respBytes))
 	}
 
-	webFingerResponse := restapi.JRD{}
+	jrd := restapi.JRD{}
 
-	err = json.Unmarshal(respBytes, &webFingerResponse)
+	err = json.Unmarshal(respBytes, &jrd)
 	if err != nil {
-		return restapi.JRD{}, fmt.Errorf("failed to unmarshal WebFinger response: %w", err)
+		return restapi.JRD{}, fmt.Errorf("failed to unmarshal %s response: %w", docType, err)
 	}
 
-	return webFingerResponse, nil
+	return jrd, nil
 }
 
 // GetWebCASURL gets the WebCAS URL for cid from domainWithScheme using WebFinger.
@@ -183,6 +197,30 @@ func (c *Client) GetWebCASURL(domainWithScheme, cid string) (*url.URL, error) {
 	return webCASURL, nil
 }
 
+// GetWebCASBatchURL returns the URL of the WebCAS batch endpoint that's advertised in the host-meta document
+// of domainWithScheme. ErrResourceNotFound is returned if the domain doesn't support batch reads.
+func (c *Client) GetWebCASBatchURL(domainWithScheme string) (*url.URL, error) {
+	hostMetaObj, err := c.hostMetaCache.Get(domainWithScheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key[%s] from host-meta cache: %w", domainWithScheme, err)
+	}
+
+	for _, link := range hostMetaObj.(restapi.JRD).Links {
+		if link.Rel != restapi.WebCASBatchRelation {
+			continue
+		}
+
+		batchURL, e := url.Parse(link.Href)
+		if e != nil {
+			return nil, fmt.Errorf("failed to parse WebCAS batch URL: %w", e)
+		}
+
+		return batchURL, nil
+	}
+
+	return nil, model.ErrResourceNotFound
+}
+
 // Option is a webfinger client instance option.
 type Option func(opts *Client)
 
@@ -9,6 +9,7 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -20,6 +21,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	discoveryrest "github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
+	"github.com/trustbloc/orb/pkg/webfinger/model"
 )
 
 func TestNew(t *testing.T) {
@@ -369,6 +371,90 @@ func TestGetWebCASURL(t *testing.T) {
 	})
 }
 
+func TestGetWebCASBatchURL(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		router := mux.NewRouter()
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		operations, err := discoveryrest.New(&discoveryrest.Config{BaseURL: testServer.URL, WebCASPath: "/cas"})
+		require.NoError(t, err)
+
+		router.HandleFunc(operations.GetRESTHandlers()[3].Path(), operations.GetRESTHandlers()[3].Handler())
+
+		webFingerClient := New()
+
+		batchURL, err := webFingerClient.GetWebCASBatchURL(testServer.URL)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("%s/cas/batch", testServer.URL), batchURL.String())
+	})
+	t.Run("Batch endpoint not advertised", func(t *testing.T) {
+		requests := 0
+
+		httpClient := httpMock(func(req *http.Request) (*http.Response, error) {
+			requests++
+
+			require.Equal(t, "https://orb.domain.com/.well-known/host-meta.json", req.URL.String())
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"links":[{"rel":"self"}]}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		webFingerClient := New(WithHTTPClient(httpClient))
+
+		batchURL, err := webFingerClient.GetWebCASBatchURL("https://orb.domain.com")
+		require.True(t, errors.Is(err, model.ErrResourceNotFound))
+		require.Nil(t, batchURL)
+
+		// The host-meta document should be cached.
+		_, err = webFingerClient.GetWebCASBatchURL("https://orb.domain.com")
+		require.True(t, errors.Is(err, model.ErrResourceNotFound))
+		require.Equal(t, 1, requests)
+	})
+	t.Run("Host-meta not found", func(t *testing.T) {
+		httpClient := httpMock(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString("not found")),
+				StatusCode: http.StatusNotFound,
+			}, nil
+		})
+
+		batchURL, err := New(WithHTTPClient(httpClient)).GetWebCASBatchURL("https://orb.domain.com")
+		require.True(t, errors.Is(err, model.ErrResourceNotFound))
+		require.Nil(t, batchURL)
+	})
+	t.Run("Invalid host-meta document", func(t *testing.T) {
+		httpClient := httpMock(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString("{")),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		batchURL, err := New(WithHTTPClient(httpClient)).GetWebCASBatchURL("https://orb.domain.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal host-meta response")
+		require.Nil(t, batchURL)
+	})
+	t.Run("Invalid batch URL", func(t *testing.T) {
+		httpClient := httpMock(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				Body: ioutil.NopCloser(bytes.NewBufferString(
+					`{"links":[{"rel":"https://trustbloc.dev/ns/webcas-batch","href":"%"}]}`),
+				),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		batchURL, err := New(WithHTTPClient(httpClient)).GetWebCASBatchURL("https://orb.domain.com")
+		require.EqualError(t, err, `failed to parse WebCAS batch URL: parse "%": invalid URL escape "%"`)
+		require.Nil(t, batchURL)
+	})
+}
+
 type httpMock func(req *http.Request) (*http.Response, error)
 
 func (m httpMock) Do(req *http.Request) (*http.Response, error) {